@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/UnitVectorY-Labs/mcp-shopping-list-firestore/pkg/shoppinglist"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// -----------------------------------------------------------------------------
+// Activity log
+// -----------------------------------------------------------------------------
+
+const (
+	defaultActivityLimit = 20
+	maxActivityLimit     = 200
+)
+
+// parseSince reads an RFC 3339 time or a duration before now, e.g. "24h".
+func parseSince(s string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(s); err == nil {
+		if d <= 0 {
+			return time.Time{}, fmt.Errorf("'since' duration must be positive")
+		}
+		return now.Add(-d), nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("'since' must be an RFC 3339 time or a duration such as 24h")
+	}
+	return t, nil
+}
+
+func registerActivityTools(srv *server.MCPServer, service *shoppinglist.Service) {
+	// recent_activity
+	recentActivityTool := mcp.NewTool(
+		"recent_activity",
+		mcp.WithDescription("Summarize recent changes to the list: who added, removed, updated, or checked off which items and when, with a one-sentence digest to open a session with."),
+		mcp.WithTitleAnnotation("Recent List Activity"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithNumber("limit", mcp.Description(fmt.Sprintf("Most recent changes to include (optional, default %d, at most %d)", defaultActivityLimit, maxActivityLimit))),
+		mcp.WithString("since", mcp.Description("Only changes at or after this RFC 3339 time, or within this duration, e.g. '24h' (optional)")),
+		listArg,
+	)
+	srv.AddTool(recentActivityTool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := req.GetArguments()
+
+		// Extract optional fields
+		limit := defaultActivityLimit
+		if v, ok := args["limit"].(float64); ok {
+			if v < 1 || v > maxActivityLimit {
+				return mcp.NewToolResultError(fmt.Sprintf("'limit' must be between 1 and %d", maxActivityLimit)), nil
+			}
+			limit = int(v)
+		}
+		var since *time.Time
+		if v, ok := args["since"].(string); ok && v != "" {
+			t, err := parseSince(v, service.Now())
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			since = &t
+		}
+
+		toolCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+
+		svc, err := listFromArgs(toolCtx, service, args)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to resolve list: %v", err)), nil
+		}
+
+		var from time.Time
+		if since != nil {
+			from = *since
+		}
+		entries, err := svc.RecentActivity(toolCtx, limit, from)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get activity: %v", err)), nil
+		}
+		actors := shoppinglist.SummarizeActivity(entries)
+		return jsonResult(shoppinglist.ActivityResponse{Since: since, Digest: shoppinglist.ActivityDigest(actors, since), Actors: actors, Entries: entries})
+	})
+}
@@ -0,0 +1,21 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2025, 8, 12, 9, 0, 0, 0, time.UTC)
+	if got, err := parseSince("24h", now); err != nil || !got.Equal(now.Add(-24*time.Hour)) {
+		t.Fatalf("parseSince(24h) = %v, %v", got, err)
+	}
+	if got, err := parseSince("2025-08-01T00:00:00Z", now); err != nil || got.Day() != 1 {
+		t.Fatalf("parseSince(RFC 3339) = %v, %v", got, err)
+	}
+	for _, bad := range []string{"-1h", "yesterday"} {
+		if _, err := parseSince(bad, now); err == nil {
+			t.Errorf("expected an error for %q", bad)
+		}
+	}
+}
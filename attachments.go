@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/UnitVectorY-Labs/mcp-shopping-list-firestore/pkg/shoppinglist"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// -----------------------------------------------------------------------------
+// Attachments
+// -----------------------------------------------------------------------------
+
+func registerAttachmentTools(srv *server.MCPServer, service *shoppinglist.Service, store shoppinglist.AttachmentStore, maxBytes int64) {
+	// add_attachment
+	addAttachmentTool := mcp.NewTool(
+		"add_attachment",
+		mcp.WithDescription(fmt.Sprintf("Attach a small file (receipt photo, product label) to an item. Returns a signed URL, valid for %d minutes, to PUT the file to, and the upload_headers the PUT must send exactly as given: the same Content-Type and the size limit the URL is signed with.", int(shoppinglist.SignedURLTTL.Minutes()))),
+		mcp.WithTitleAnnotation("Add Attachment"),
+		mcp.WithString("id", mcp.Description("ID of the item"), mcp.Required()),
+		mcp.WithString("file_name", mcp.Description("File name, e.g. 'receipt.jpg'"), mcp.Required()),
+		mcp.WithString("content_type", mcp.Description("MIME type: an image, application/pdf, or text/plain"), mcp.Required()),
+		mcp.WithNumber("size_bytes", mcp.Description(fmt.Sprintf("File size in bytes, at most %d", maxBytes)), mcp.Required()),
+		listArg,
+	)
+	srv.AddTool(addAttachmentTool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := req.GetArguments()
+
+		// Extract required fields
+		id, ok := args["id"].(string)
+		if !ok || id == "" {
+			return mcp.NewToolResultError("invalid or missing 'id'"), nil
+		}
+		fileName, ok := args["file_name"].(string)
+		if !ok || fileName == "" {
+			return mcp.NewToolResultError("invalid or missing 'file_name'"), nil
+		}
+		contentType, ok := args["content_type"].(string)
+		if !ok || contentType == "" {
+			return mcp.NewToolResultError("invalid or missing 'content_type'"), nil
+		}
+		size, ok := args["size_bytes"].(float64)
+		if !ok {
+			return mcp.NewToolResultError("invalid or missing 'size_bytes'"), nil
+		}
+
+		toolCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
+		defer cancel()
+
+		svc, err := listFromArgs(toolCtx, service, args)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to resolve list: %v", err)), nil
+		}
+
+		attachment, err := svc.NewAttachment(id, fileName, contentType, int64(size), maxBytes)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		uploadURL, headers, err := store.UploadURL(toolCtx, attachment.Object, attachment.ContentType, attachment.Size)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to sign upload URL: %v", err)), nil
+		}
+		if err := svc.AddAttachment(toolCtx, id, attachment); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to add attachment: %v", err)), nil
+		}
+		return jsonResult(shoppinglist.AttachmentResponse{Attachment: attachment, UploadURL: uploadURL, UploadHeaders: headers})
+	})
+
+	// list_attachments
+	listAttachmentsTool := mcp.NewTool(
+		"list_attachments",
+		mcp.WithDescription(fmt.Sprintf("List an item's attachments with signed download URLs valid for %d minutes.", int(shoppinglist.SignedURLTTL.Minutes()))),
+		mcp.WithTitleAnnotation("List Attachments"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("id", mcp.Description("ID of the item"), mcp.Required()),
+		listArg,
+	)
+	srv.AddTool(listAttachmentsTool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := req.GetArguments()
+
+		// Extract required id field
+		id, ok := args["id"].(string)
+		if !ok || id == "" {
+			return mcp.NewToolResultError("invalid or missing 'id'"), nil
+		}
+
+		toolCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+
+		svc, err := listFromArgs(toolCtx, service, args)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to resolve list: %v", err)), nil
+		}
+
+		item, err := svc.GetItem(toolCtx, id)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get item: %v", err)), nil
+		}
+		resp := shoppinglist.AttachmentsResponse{Attachments: []shoppinglist.Attachment{}}
+		for _, a := range item.Attachments {
+			url, err := store.DownloadURL(toolCtx, a.Object)
+			if err != nil {
+				resp.Warn(shoppinglist.WarnSigningFailed, id, "%s: %v", a.FileName, err)
+			}
+			a.DownloadURL = url
+			resp.Attachments = append(resp.Attachments, a)
+		}
+		return jsonResult(resp)
+	})
+
+	// delete_attachment
+	deleteAttachmentTool := mcp.NewTool(
+		"delete_attachment",
+		mcp.WithDescription("Delete an attachment from an item, including the stored file."),
+		mcp.WithTitleAnnotation("Delete Attachment"),
+		mcp.WithString("id", mcp.Description("ID of the item"), mcp.Required()),
+		mcp.WithString("attachment_id", mcp.Description("ID of the attachment"), mcp.Required()),
+		listArg,
+	)
+	srv.AddTool(deleteAttachmentTool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := req.GetArguments()
+
+		// Extract required fields
+		id, ok := args["id"].(string)
+		if !ok || id == "" {
+			return mcp.NewToolResultError("invalid or missing 'id'"), nil
+		}
+		attachmentID, ok := args["attachment_id"].(string)
+		if !ok || attachmentID == "" {
+			return mcp.NewToolResultError("invalid or missing 'attachment_id'"), nil
+		}
+
+		toolCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
+		defer cancel()
+
+		svc, err := listFromArgs(toolCtx, service, args)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to resolve list: %v", err)), nil
+		}
+
+		removed, err := svc.RemoveAttachment(toolCtx, id, attachmentID)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to delete attachment: %v", err)), nil
+		}
+		var resp shoppinglist.AttachmentResponse
+		resp.Attachment = removed
+		if err := store.Delete(toolCtx, removed.Object); err != nil {
+			resp.Warn(shoppinglist.WarnStorageCleanup, id, "attachment removed from the item, but deleting %s failed: %v", removed.Object, err)
+		}
+		return jsonResult(resp)
+	})
+}
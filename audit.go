@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/UnitVectorY-Labs/mcp-shopping-list-firestore/pkg/shoppinglist"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// -----------------------------------------------------------------------------
+// Audit trail
+// -----------------------------------------------------------------------------
+
+const (
+	defaultHistoryLimit = 50
+	maxHistoryLimit     = 500
+)
+
+func registerAuditTools(srv *server.MCPServer, service *shoppinglist.Service) {
+	// get_item_history
+	getItemHistoryTool := mcp.NewTool(
+		"get_item_history",
+		mcp.WithDescription("Show how an item evolved: every time it was added, changed, checked off, removed, or restored, newest first, with who made the change, through which client, and each changed field's old and new values. Works for removed items too."),
+		mcp.WithTitleAnnotation("Get Item History"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("id", mcp.Description("ID of the item"), mcp.Required()),
+		mcp.WithNumber("limit", mcp.Description(fmt.Sprintf("Most recent changes to include (optional, default %d, at most %d)", defaultHistoryLimit, maxHistoryLimit))),
+		listArg,
+	)
+	srv.AddTool(getItemHistoryTool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := req.GetArguments()
+
+		// Extract required id field
+		id, ok := args["id"].(string)
+		if !ok || id == "" {
+			return mcp.NewToolResultError("invalid or missing 'id'"), nil
+		}
+
+		// Extract optional limit field
+		limit := defaultHistoryLimit
+		if v, ok := args["limit"].(float64); ok {
+			if v < 1 || v > maxHistoryLimit {
+				return mcp.NewToolResultError(fmt.Sprintf("'limit' must be between 1 and %d", maxHistoryLimit)), nil
+			}
+			limit = int(v)
+		}
+
+		toolCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+
+		svc, err := listFromArgs(toolCtx, service, args)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to resolve list: %v", err)), nil
+		}
+
+		entries, err := svc.ItemHistory(toolCtx, id, limit)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get item history: %v", err)), nil
+		}
+		return jsonResult(shoppinglist.ItemHistoryResponse{ItemID: id, Entries: entries})
+	})
+}
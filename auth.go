@@ -0,0 +1,294 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/UnitVectorY-Labs/mcp-shopping-list-firestore/pkg/shoppinglist"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// -----------------------------------------------------------------------------
+// Token authentication
+// -----------------------------------------------------------------------------
+
+// tokenTools manage tokens, or like tool_telemetry, the feature flag tools,
+// and repoint_list act on the server's storage as a whole, and need an owner
+// token.
+var tokenTools = map[string]bool{"create_token": true, "list_tokens": true, "revoke_token": true, "tool_telemetry": true, "list_feature_flags": true, "set_feature_flag": true, "repoint_list": true}
+
+// addOnlyTools only create new items, so add-scoped tokens may call them.
+// add_recipe and import_items are not among them: recipes merge amounts into
+// existing items, and imports write progress records that resuming replaces.
+var addOnlyTools = map[string]bool{"bulk_add_items": true}
+
+type tokenKey struct{}
+
+// withToken returns a context whose tool calls are limited to tok.
+func withToken(ctx context.Context, tok *shoppinglist.APIToken) context.Context {
+	return context.WithValue(ctx, tokenKey{}, tok)
+}
+
+// tokenFromContext is the token the request authenticated with, or nil when
+// authentication is off (stdio, or HTTP without OWNER_TOKEN).
+func tokenFromContext(ctx context.Context) *shoppinglist.APIToken {
+	tok, _ := ctx.Value(tokenKey{}).(*shoppinglist.APIToken)
+	return tok
+}
+
+// requireToken authenticates /mcp requests with a bearer token: the owner
+// token, or one issued with create_token.
+func requireToken(service *shoppinglist.Service, ownerToken *secret, next http.Handler) http.Handler {
+	owner := &shoppinglist.APIToken{Name: "owner", Scope: shoppinglist.ScopeOwner}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		secret, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || secret == "" {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="mcp"`)
+			http.Error(w, "missing bearer token", http.StatusUnauthorized)
+			return
+		}
+		if subtle.ConstantTimeCompare([]byte(secret), []byte(ownerToken.Value())) == 1 {
+			next.ServeHTTP(w, r.WithContext(withToken(r.Context(), owner)))
+			return
+		}
+		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+		tok, err := service.Authenticate(ctx, secret)
+		cancel()
+		switch {
+		case errors.Is(err, shoppinglist.ErrInvalidToken):
+			w.Header().Set("WWW-Authenticate", `Bearer realm="mcp", error="invalid_token"`)
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		case err != nil:
+			log.Printf("warn: authenticate: %v", err)
+			http.Error(w, "token lookup failed", http.StatusServiceUnavailable)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(withToken(r.Context(), tok)))
+	})
+}
+
+// toolScope is the scope a call of tool needs. With derived item IDs an
+// upsert_item without an id updates the item already named so, so it needs
+// write access.
+func toolScope(tool *server.ServerTool, args map[string]any, derivedIDs bool) string {
+	name := tool.Tool.Name
+	switch {
+	case tokenTools[name]:
+		return shoppinglist.ScopeOwner
+	case tool.Tool.Annotations.ReadOnlyHint != nil && *tool.Tool.Annotations.ReadOnlyHint:
+		return shoppinglist.ScopeRead
+	case addOnlyTools[name]:
+		return shoppinglist.ScopeAdd
+	case name == "upsert_item":
+		if id, _ := args["id"].(string); id == "" && !derivedIDs {
+			return shoppinglist.ScopeAdd
+		}
+	case name == "find_stale_items":
+		if remove, _ := args["delete"].(bool); !remove {
+			return shoppinglist.ScopeRead
+		}
+	}
+	return shoppinglist.ScopeWrite
+}
+
+// checkAccess reports why tok may not use what, a tool or resource, with
+// scope on lists, or returns nil. Tool calls and resource reads and
+// subscriptions all go through it, so no way of reaching a list skips the
+// token. A nil token, when authentication is off, allows everything.
+func checkAccess(tok *shoppinglist.APIToken, what, scope string, lists ...shoppinglist.ListInfo) error {
+	if tok == nil {
+		return nil
+	}
+	if !tok.Allows(scope) {
+		return fmt.Errorf("token %q has %s access; %s needs %s", tok.Name, tok.Scope, what, scope)
+	}
+	for _, list := range lists {
+		if !tok.AllowsList(list.ID) {
+			return fmt.Errorf("token %q may not be used on list %q", tok.Name, list.Slug)
+		}
+	}
+	return nil
+}
+
+// checkToken reports why tok may not make the call, or returns nil.
+func checkToken(ctx context.Context, service *shoppinglist.Service, tok *shoppinglist.APIToken, tool *server.ServerTool, args map[string]any) error {
+	scope := toolScope(tool, args, service.DerivesItemIDs())
+	if err := checkAccess(tok, tool.Tool.Name, scope); err != nil {
+		return err
+	}
+	if len(tok.Lists) == 0 {
+		return nil
+	}
+	if _, ok := tool.Tool.InputSchema.Properties["list"]; !ok {
+		return fmt.Errorf("token %q is limited to specific lists, and %s is not scoped to one", tok.Name, tool.Tool.Name)
+	}
+	refs := []string{"list"}
+	if tool.Tool.Name == "move_item" {
+		// Moving writes to the destination list as well.
+		refs = append(refs, "to")
+	}
+	for _, arg := range refs {
+		list := service.DefaultList()
+		if ref, _ := args[arg].(string); ref != "" {
+			var err error
+			if list, err = service.ResolveList(ctx, ref); err != nil {
+				return fmt.Errorf("failed to resolve list: %v", err)
+			}
+		}
+		if err := checkAccess(tok, tool.Tool.Name, scope, list); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// subscribedURI is the resource a resources/subscribe message subscribes to,
+// or "" for any other message.
+func subscribedURI(message any) string {
+	raw, ok := message.(json.RawMessage)
+	if !ok {
+		return ""
+	}
+	var req mcp.SubscribeRequest
+	if err := json.Unmarshal(raw, &req); err != nil || req.Method != string(mcp.MethodResourcesSubscribe) {
+		return ""
+	}
+	return req.Params.URI
+}
+
+// refuseSubscriptions rejects resources/subscribe requests for which check
+// returns an error, before the subscription is acknowledged. Subscribe hooks
+// cannot fail a request, so the check runs as the request is initialized.
+func refuseSubscriptions(hooks *server.Hooks, check func(ctx context.Context, uri string) error) {
+	hooks.AddOnRequestInitialization(func(ctx context.Context, id any, message any) error {
+		if uri := subscribedURI(message); uri != "" {
+			return check(ctx, uri)
+		}
+		return nil
+	})
+}
+
+// withTokenScope refuses tool calls the request's token does not allow.
+func withTokenScope(service *shoppinglist.Service) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			tok := tokenFromContext(ctx)
+			if tok == nil {
+				return next(ctx, req)
+			}
+			srv := server.ServerFromContext(ctx)
+			if srv == nil {
+				return next(ctx, req)
+			}
+			tool := srv.GetTool(req.Params.Name)
+			if tool == nil {
+				return next(ctx, req)
+			}
+			if err := checkToken(ctx, service, tok, tool, req.GetArguments()); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			return next(ctx, req)
+		}
+	}
+}
+
+func registerTokenTools(srv *server.MCPServer, service *shoppinglist.Service) {
+	// create_token
+	createTokenTool := mcp.NewTool(
+		"create_token",
+		mcp.WithDescription("Issue an API token for the HTTP endpoint, limited to an access scope and optionally to specific lists, e.g. a read-only token for a kitchen display or an add-only token for a voice assistant. The secret is returned once and only its hash is stored. Requires an owner token."),
+		mcp.WithTitleAnnotation("Create API Token"),
+		mcp.WithString("name", mcp.Description("Who or what the token is for, e.g. 'kitchen display'"), mcp.Required()),
+		mcp.WithString("scope", mcp.Description("read: only tools that change nothing; add: also adding new items; write: every list operation; owner: also managing tokens"), mcp.Required(), mcp.Enum(shoppinglist.TokenScopes...)),
+		mcp.WithArray("lists", mcp.Description("IDs or slugs of the lists the token may be used on (optional, defaults to every list)"), mcp.WithStringItems()),
+	)
+	srv.AddTool(createTokenTool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := req.GetArguments()
+
+		// Extract required fields
+		name, ok := args["name"].(string)
+		if !ok || strings.TrimSpace(name) == "" {
+			return mcp.NewToolResultError("invalid or missing 'name'"), nil
+		}
+		scope, ok := args["scope"].(string)
+		if !ok || scope == "" {
+			return mcp.NewToolResultError("invalid or missing 'scope'"), nil
+		}
+
+		toolCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+
+		// Extract optional lists field
+		var lists []string
+		if raw, ok := args["lists"].([]any); ok {
+			for _, r := range raw {
+				ref, _ := r.(string)
+				list, err := service.ResolveList(toolCtx, ref)
+				if err != nil {
+					return mcp.NewToolResultError(fmt.Sprintf("failed to resolve list: %v", err)), nil
+				}
+				lists = append(lists, list.ID)
+			}
+		}
+
+		tok, secret, err := service.CreateToken(toolCtx, name, scope, lists)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to create token: %v", err)), nil
+		}
+		return jsonResult(shoppinglist.TokenResponse{Token: tok, Secret: secret})
+	})
+
+	// list_tokens
+	listTokensTool := mcp.NewTool(
+		"list_tokens",
+		mcp.WithDescription("Show the issued API tokens with their scopes, lists, and whether they are revoked. Secrets are never shown. Requires an owner token."),
+		mcp.WithTitleAnnotation("List API Tokens"),
+		mcp.WithReadOnlyHintAnnotation(true),
+	)
+	srv.AddTool(listTokensTool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		toolCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+
+		tokens, err := service.ListTokens(toolCtx)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to list tokens: %v", err)), nil
+		}
+		return jsonResult(shoppinglist.TokensResponse{Tokens: tokens})
+	})
+
+	// revoke_token
+	revokeTokenTool := mcp.NewTool(
+		"revoke_token",
+		mcp.WithDescription("Revoke an API token by ID so it no longer authenticates. Requires an owner token."),
+		mcp.WithTitleAnnotation("Revoke API Token"),
+		mcp.WithIdempotentHintAnnotation(true),
+		mcp.WithString("id", mcp.Description("ID of the token, from list_tokens"), mcp.Required()),
+	)
+	srv.AddTool(revokeTokenTool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := req.GetArguments()
+
+		// Extract required id field
+		id, ok := args["id"].(string)
+		if !ok || id == "" {
+			return mcp.NewToolResultError("invalid or missing 'id'"), nil
+		}
+
+		toolCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+
+		tok, err := service.RevokeToken(toolCtx, id)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to revoke token: %v", err)), nil
+		}
+		return jsonResult(shoppinglist.TokenResponse{Token: tok})
+	})
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/UnitVectorY-Labs/mcp-shopping-list-firestore/pkg/shoppinglist"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func TestToolScope(t *testing.T) {
+	for _, tc := range []struct {
+		tool    *server.ServerTool
+		args    map[string]any
+		derived bool
+		want    string
+	}{
+		{&server.ServerTool{Tool: mcp.NewTool("list_items", mcp.WithReadOnlyHintAnnotation(true))}, nil, false, shoppinglist.ScopeRead},
+		{&server.ServerTool{Tool: mcp.NewTool("list_tokens", mcp.WithReadOnlyHintAnnotation(true))}, nil, false, shoppinglist.ScopeOwner},
+		{&server.ServerTool{Tool: mcp.NewTool("upsert_item")}, map[string]any{"name": "milk"}, false, shoppinglist.ScopeAdd},
+		{&server.ServerTool{Tool: mcp.NewTool("upsert_item")}, map[string]any{"id": "x", "name": "milk"}, false, shoppinglist.ScopeWrite},
+		{&server.ServerTool{Tool: mcp.NewTool("upsert_item")}, map[string]any{"name": "milk"}, true, shoppinglist.ScopeWrite},
+		{&server.ServerTool{Tool: mcp.NewTool("bulk_add_items")}, nil, true, shoppinglist.ScopeAdd},
+		{&server.ServerTool{Tool: mcp.NewTool("bulk_add_items")}, nil, false, shoppinglist.ScopeAdd},
+		{&server.ServerTool{Tool: mcp.NewTool("add_recipe")}, nil, false, shoppinglist.ScopeWrite},
+		{&server.ServerTool{Tool: mcp.NewTool("import_items")}, nil, false, shoppinglist.ScopeWrite},
+		{&server.ServerTool{Tool: mcp.NewTool("remove_item")}, nil, false, shoppinglist.ScopeWrite},
+		{&server.ServerTool{Tool: mcp.NewTool("find_stale_items")}, map[string]any{"days": 90.0}, false, shoppinglist.ScopeRead},
+		{&server.ServerTool{Tool: mcp.NewTool("find_stale_items")}, map[string]any{"days": 90.0, "delete": true}, false, shoppinglist.ScopeWrite},
+	} {
+		if got := toolScope(tc.tool, tc.args, tc.derived); got != tc.want {
+			t.Errorf("%s %v (derived %v): scope %q, want %q", tc.tool.Tool.Name, tc.args, tc.derived, got, tc.want)
+		}
+	}
+}
+
+func TestCheckTokenRefusesInsufficientScope(t *testing.T) {
+	tok := &shoppinglist.APIToken{Name: "kitchen", Scope: shoppinglist.ScopeRead}
+	tool := &server.ServerTool{Tool: mcp.NewTool("remove_item", listArg)}
+	if err := checkToken(t.Context(), nil, tok, tool, nil); err == nil {
+		t.Fatal("expected a read token to be refused remove_item")
+	}
+}
+
+func TestCheckTokenRefusesAddTokenUpsertsWithDerivedIDs(t *testing.T) {
+	t.Setenv("FIRESTORE_EMULATOR_HOST", "127.0.0.1:1")
+	service, err := shoppinglist.NewService(t.Context(), "test-project", "(default)", "shopping", "", shoppinglist.WithDerivedItemIDs())
+	if err != nil {
+		t.Fatalf("NewService: %v", err)
+	}
+	defer service.Close()
+
+	tok := &shoppinglist.APIToken{Name: "voice", Scope: shoppinglist.ScopeAdd}
+	tool := &server.ServerTool{Tool: mcp.NewTool("upsert_item", listArg)}
+	if err := checkToken(t.Context(), service, tok, tool, map[string]any{"name": "milk"}); err == nil {
+		t.Fatal("expected an add token to be refused an upsert that may update an existing item")
+	}
+	if err := checkToken(t.Context(), nil, tok, tool, map[string]any{"name": "milk"}); err != nil {
+		t.Fatalf("expected an add token to add with minted IDs, got %v", err)
+	}
+}
+
+func TestCheckTokenRefusesCrossListToolsForListTokens(t *testing.T) {
+	tok := &shoppinglist.APIToken{Name: "party", Scope: shoppinglist.ScopeWrite, Lists: []string{"abc"}}
+	tool := &server.ServerTool{Tool: mcp.NewTool("create_list")}
+	if err := checkToken(t.Context(), nil, tok, tool, nil); err == nil {
+		t.Fatal("expected a list-limited token to be refused a tool without a list")
+	}
+}
+
+func TestCheckAccess(t *testing.T) {
+	main := shoppinglist.ListInfo{ID: "main", Slug: "groceries"}
+	if err := checkAccess(nil, "shoppinglist://list", shoppinglist.ScopeOwner, main); err != nil {
+		t.Fatalf("expected no token to allow everything, got %v", err)
+	}
+	tok := &shoppinglist.APIToken{Name: "party", Scope: shoppinglist.ScopeRead, Lists: []string{"abc"}}
+	if err := checkAccess(tok, "shoppinglist://list", shoppinglist.ScopeRead, shoppinglist.ListInfo{ID: "abc"}); err != nil {
+		t.Fatalf("expected the token's own list to be readable, got %v", err)
+	}
+	if err := checkAccess(tok, "shoppinglist://list", shoppinglist.ScopeRead, main); err == nil {
+		t.Fatal("expected another list to be refused")
+	}
+	if err := checkAccess(tok, "upsert_item", shoppinglist.ScopeAdd); err == nil {
+		t.Fatal("expected a read token to be refused adding")
+	}
+}
+
+func TestSubscribedURI(t *testing.T) {
+	msg := json.RawMessage(`{"jsonrpc":"2.0","id":1,"method":"resources/subscribe","params":{"uri":"shoppinglist://list"}}`)
+	if got := subscribedURI(msg); got != "shoppinglist://list" {
+		t.Fatalf("subscribedURI = %q", got)
+	}
+	read := json.RawMessage(`{"jsonrpc":"2.0","id":2,"method":"resources/read","params":{"uri":"shoppinglist://list"}}`)
+	if got := subscribedURI(read); got != "" {
+		t.Fatalf("expected no subscription for a read, got %q", got)
+	}
+}
+
+func TestRequireToken(t *testing.T) {
+	var got *shoppinglist.APIToken
+	h := requireToken(nil, plainSecret("owner-secret"), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = tokenFromContext(r.Context())
+	}))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/mcp", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("without a token: status %d, want 401", rec.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+	req.Header.Set("Authorization", "Bearer owner-secret")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || got == nil || got.Scope != shoppinglist.ScopeOwner {
+		t.Errorf("with the owner token: status %d, token %+v", rec.Code, got)
+	}
+}
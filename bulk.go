@@ -0,0 +1,236 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/UnitVectorY-Labs/mcp-shopping-list-firestore/pkg/shoppinglist"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// -----------------------------------------------------------------------------
+// Bulk adds
+// -----------------------------------------------------------------------------
+
+// clearListTimeout bounds one clear_list call; a list too large to clear in
+// time is finished by calling again.
+const clearListTimeout = 2 * time.Minute
+
+// bulkItemsFromArgs decodes the 'items' array argument, warning about names
+// it trims and quantities it cannot read as one amount.
+func bulkItemsFromArgs(args map[string]any, currency string, warnings *shoppinglist.ResponseWarnings) ([]shoppinglist.ItemInput, error) {
+	raw, ok := args["items"].([]any)
+	if !ok || len(raw) == 0 {
+		return nil, errors.New("invalid or missing 'items'")
+	}
+	inputs := make([]shoppinglist.ItemInput, 0, len(raw))
+	for i, r := range raw {
+		m, ok := r.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("item %d: expected an object", i)
+		}
+		given, _ := m["name"].(string)
+		name, coerced := shoppinglist.NormalizeItemName(given)
+		if name == "" {
+			return nil, fmt.Errorf("item %d: invalid or missing 'name'", i)
+		}
+		if coerced {
+			warnings.Warn(shoppinglist.WarnValidationCoerced, "", "name %q was trimmed to %q", given, name)
+		}
+		input := shoppinglist.ItemInput{Name: name}
+
+		if quantity, ok := m["quantity"].(string); ok && quantity != "" {
+			if msg := shoppinglist.QuantityAmbiguity(quantity); msg != "" {
+				warnings.Warn(shoppinglist.WarnQuantityAmbiguous, "", "%s", msg)
+			}
+			input.Quantity = &quantity
+		}
+		if category, ok := m["category"].(string); ok {
+			category = shoppinglist.NormalizeCategory(category)
+			input.Category = &category
+		}
+		if v, ok := m["needed_by"].(string); ok && v != "" {
+			neededBy, err := parseNeededBy(v)
+			if err != nil {
+				return nil, fmt.Errorf("item %d: %w", i, err)
+			}
+			input.NeededBy = &neededBy
+		}
+		if raw, ok := m["tags"]; ok && raw != nil {
+			tags, err := shoppinglist.ParseTags("tags", raw)
+			if err != nil {
+				return nil, fmt.Errorf("item %d: %w", i, err)
+			}
+			input.Tags = tags
+		}
+		if v, ok := m["priority"].(string); ok {
+			priority, err := shoppinglist.ParsePriority(v)
+			if err != nil {
+				return nil, fmt.Errorf("item %d: %w", i, err)
+			}
+			input.Priority = &priority
+		}
+		if v, ok := m["price"].(float64); ok {
+			if v < 0 {
+				return nil, fmt.Errorf("item %d: 'price' cannot be negative", i)
+			}
+			input.Price, input.Currency = &v, currency
+		}
+		if staple, ok := m["staple"].(bool); ok {
+			input.Staple = &staple
+		}
+		inputs = append(inputs, input)
+	}
+	return inputs, nil
+}
+
+// idsFromArgs decodes the 'ids' array argument.
+func idsFromArgs(args map[string]any) ([]string, error) {
+	raw, ok := args["ids"].([]any)
+	if !ok || len(raw) == 0 {
+		return nil, errors.New("invalid or missing 'ids'")
+	}
+	ids := make([]string, 0, len(raw))
+	for i, r := range raw {
+		id, ok := r.(string)
+		if !ok || id == "" {
+			return nil, fmt.Errorf("id %d: expected a non-empty string", i)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// itemInputSchema is the JSON schema of one entry of an 'items' argument.
+func itemInputSchema(currency string) map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"name":      map[string]any{"type": "string", "description": "Name of the item"},
+			"quantity":  map[string]any{"type": "string", "description": "Quantity, e.g. '500 g' or '2' (optional)"},
+			"category":  map[string]any{"type": "string", "description": "Store section such as produce or dairy (optional)"},
+			"needed_by": map[string]any{"type": "string", "description": "Date needed by, as YYYY-MM-DD or an RFC 3339 time (optional)"},
+			"tags":      map[string]any{"type": "array", "items": map[string]any{"type": "string"}, "description": "Labels such as party or urgent (optional)"},
+			"priority":  map[string]any{"type": "string", "enum": []string{shoppinglist.PriorityLow, shoppinglist.PriorityNormal, shoppinglist.PriorityHigh}, "description": "high, normal, or low (optional)"},
+			"price":     map[string]any{"type": "number", "description": fmt.Sprintf("Expected price in %s (optional)", currency)},
+			"staple":    map[string]any{"type": "boolean", "description": "Put the item back on the list each week after it is purchased (optional)"},
+		},
+		"required": []string{"name"},
+	}
+}
+
+func registerBulkTools(srv *server.MCPServer, service *shoppinglist.Service, currency string) {
+	// bulk_add_items
+	bulkAddItemsTool := mcp.NewTool(
+		"bulk_add_items",
+		mcp.WithDescription("Add many new items in one call, e.g. all the ingredients of a recipe, instead of calling upsert_item for each. Returns a result per item, in order; one failing item does not stop the others."),
+		mcp.WithTitleAnnotation("Bulk Add Shopping Items"),
+		mcp.WithArray("items",
+			mcp.Description("Items to add"),
+			mcp.Required(),
+			mcp.Items(itemInputSchema(currency)),
+		),
+		listArg,
+	)
+	srv.AddTool(bulkAddItemsTool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := req.GetArguments()
+
+		// Extract required items field
+		var warnings shoppinglist.ResponseWarnings
+		inputs, err := bulkItemsFromArgs(args, currency, &warnings)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		toolCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
+		defer cancel()
+
+		svc, err := listFromArgs(toolCtx, service, args)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to resolve list: %v", err)), nil
+		}
+
+		results, err := svc.BulkAddItems(toolCtx, inputs)
+		if err != nil {
+			var frozen *shoppinglist.ListFrozenError
+			if errors.As(err, &frozen) {
+				return mcp.NewToolResultError(frozen.Error()), nil
+			}
+			return mcp.NewToolResultError(fmt.Sprintf("failed to add items: %v", err)), nil
+		}
+		resp := shoppinglist.NewBulkAddResponse(results)
+		resp.ResponseWarnings = warnings
+		return jsonResult(resp)
+	})
+
+	// bulk_remove_items
+	bulkRemoveItemsTool := mcp.NewTool(
+		"bulk_remove_items",
+		mcp.WithDescription("Remove several items by ID in one write, instead of calling remove_item for each. Reports which IDs were deleted and which were not found. Removed items go to the trash, where restore_item can bring them back."),
+		mcp.WithTitleAnnotation("Bulk Remove Shopping Items"),
+		mcp.WithArray("ids", mcp.Description("IDs of the items to remove"), mcp.Required(), mcp.WithStringItems()),
+		mcp.WithBoolean("cascade", mcp.Description("Also remove the items' nested children; otherwise they become top-level items (optional)")),
+		listArg,
+	)
+	srv.AddTool(bulkRemoveItemsTool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := req.GetArguments()
+
+		// Extract required ids field
+		ids, err := idsFromArgs(args)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		// Extract optional cascade field
+		cascade, _ := args["cascade"].(bool)
+
+		toolCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
+		defer cancel()
+
+		svc, err := listFromArgs(toolCtx, service, args)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to resolve list: %v", err)), nil
+		}
+
+		resp, err := svc.BulkRemoveItems(toolCtx, ids, cascade)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to remove items: %v", err)), nil
+		}
+		return jsonResult(resp)
+	})
+
+	// clear_list
+	clearListTool := mcp.NewTool(
+		"clear_list",
+		mcp.WithDescription("Remove every item on the list, purchased or not, e.g. to start over. Large lists are cleared in pages. Items go to the trash, where restore_item can bring them back one by one until purge_deleted empties it; 'confirm' must be true, so ask the user before calling it."),
+		mcp.WithTitleAnnotation("Clear Shopping List"),
+		mcp.WithDestructiveHintAnnotation(true),
+		mcp.WithBoolean("confirm", mcp.Description("Must be true to confirm that every item should be deleted"), mcp.Required()),
+		listArg,
+	)
+	srv.AddTool(clearListTool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := req.GetArguments()
+
+		// Extract required confirm field
+		if confirm, _ := args["confirm"].(bool); !confirm {
+			return mcp.NewToolResultError("refusing to clear the list without 'confirm': true"), nil
+		}
+
+		toolCtx, cancel := context.WithTimeout(ctx, clearListTimeout)
+		defer cancel()
+
+		svc, err := listFromArgs(toolCtx, service, args)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to resolve list: %v", err)), nil
+		}
+
+		resp, err := svc.ClearList(toolCtx)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to clear list: %v; %d items were removed, call again to remove the rest", err, resp.Removed)), nil
+		}
+		return jsonResult(resp)
+	})
+}
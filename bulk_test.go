@@ -0,0 +1,48 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/UnitVectorY-Labs/mcp-shopping-list-firestore/pkg/shoppinglist"
+)
+
+func TestBulkItemsFromArgs(t *testing.T) {
+	var warnings shoppinglist.ResponseWarnings
+	inputs, err := bulkItemsFromArgs(map[string]any{"items": []any{
+		map[string]any{"name": "  flour ", "quantity": "500 g", "price": 1.2},
+		map[string]any{"name": "eggs", "tags": []any{"Baking"}},
+	}}, "EUR", &warnings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(inputs) != 2 || inputs[0].Name != "flour" || *inputs[0].Quantity != "500 g" || inputs[0].Currency != "EUR" {
+		t.Fatalf("inputs = %+v", inputs)
+	}
+	if len(inputs[1].Tags) != 1 || inputs[1].Tags[0] != "baking" {
+		t.Errorf("tags = %v", inputs[1].Tags)
+	}
+	if len(warnings.Warnings) != 1 || warnings.Warnings[0].Code != shoppinglist.WarnValidationCoerced {
+		t.Errorf("warnings = %+v", warnings.Warnings)
+	}
+}
+
+func TestBulkItemsFromArgsRejectsUnnamed(t *testing.T) {
+	var warnings shoppinglist.ResponseWarnings
+	_, err := bulkItemsFromArgs(map[string]any{"items": []any{
+		map[string]any{"name": "milk"},
+		map[string]any{"quantity": "2"},
+	}}, "EUR", &warnings)
+	if err == nil || err.Error() != "item 1: invalid or missing 'name'" {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestIDsFromArgs(t *testing.T) {
+	ids, err := idsFromArgs(map[string]any{"ids": []any{"a", "b"}})
+	if err != nil || len(ids) != 2 {
+		t.Fatalf("ids = %v, err = %v", ids, err)
+	}
+	if _, err := idsFromArgs(map[string]any{"ids": []any{"a", 3.0}}); err == nil {
+		t.Error("expected a non-string id to be rejected")
+	}
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// -----------------------------------------------------------------------------
+// Client capability negotiation
+// -----------------------------------------------------------------------------
+
+// richContentVersion is the first protocol version with structured tool
+// output and resource links.
+const richContentVersion = "2025-06-18"
+
+// clientFeatures is what the client behind a session can take.
+type clientFeatures struct {
+	StructuredContent bool
+	ResourceLinks     bool
+	Sampling          bool
+	Elicitation       bool
+}
+
+// allFeatures is assumed for callers that never initialized, such as the
+// call subcommand and tests, which read results in-process.
+var allFeatures = clientFeatures{StructuredContent: true, ResourceLinks: true, Sampling: true, Elicitation: true}
+
+// negotiateFeatures derives clientFeatures from the protocol version agreed
+// at initialize and the capabilities the client advertised.
+func negotiateFeatures(protocolVersion string, caps mcp.ClientCapabilities) clientFeatures {
+	// Protocol versions are dates, so they compare as strings.
+	rich := protocolVersion >= richContentVersion
+	return clientFeatures{
+		StructuredContent: rich,
+		ResourceLinks:     rich,
+		Sampling:          caps.Sampling != nil,
+		Elicitation:       caps.Elicitation != nil,
+	}
+}
+
+// featureStore keeps the negotiated features of each connected session.
+type featureStore struct {
+	mu       sync.RWMutex
+	features map[string]clientFeatures
+}
+
+var sessionFeatures = &featureStore{features: map[string]clientFeatures{}}
+
+// Get returns the features of the session behind ctx, or allFeatures when it
+// never initialized.
+func (f *featureStore) Get(ctx context.Context) clientFeatures {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if features, ok := f.features[sessionKey(ctx)]; ok {
+		return features
+	}
+	return allFeatures
+}
+
+// Set records the features of the session behind ctx.
+func (f *featureStore) Set(ctx context.Context, features clientFeatures) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.features[sessionKey(ctx)] = features
+}
+
+// Forget drops the features of a closed session.
+func (f *featureStore) Forget(sessionID string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.features, sessionID)
+}
+
+// hasText reports whether content includes a text block reading text.
+func hasText(content []mcp.Content, text string) bool {
+	for _, c := range content {
+		if t, ok := c.(mcp.TextContent); ok && t.Text == text {
+			return true
+		}
+	}
+	return false
+}
+
+// downgradeResult rewrites res for a client without features: structured
+// content becomes a JSON text block and resource links become text naming
+// their URI, so nothing is lost to clients that would drop them.
+func downgradeResult(res *mcp.CallToolResult, features clientFeatures) *mcp.CallToolResult {
+	if res == nil || (features.StructuredContent && features.ResourceLinks) {
+		return res
+	}
+	out := *res
+	out.Content = make([]mcp.Content, 0, len(res.Content)+1)
+	for _, c := range res.Content {
+		if link, ok := c.(mcp.ResourceLink); ok && !features.ResourceLinks {
+			c = mcp.NewTextContent(fmt.Sprintf("Resource %s (%s): read it with resources/read.", link.URI, link.MIMEType))
+		}
+		out.Content = append(out.Content, c)
+	}
+	if res.StructuredContent != nil && !features.StructuredContent {
+		// Results whose text is already their JSON lose nothing.
+		if b, err := json.Marshal(res.StructuredContent); err == nil && !hasText(res.Content, string(b)) {
+			out.Content = append(out.Content, mcp.NewTextContent(string(b)))
+		}
+		out.StructuredContent = nil
+	}
+	return &out
+}
+
+// withClientFeatures adapts every tool result to what the calling client
+// negotiated.
+func withClientFeatures(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		res, err := next(ctx, req)
+		return downgradeResult(res, sessionFeatures.Get(ctx)), err
+	}
+}
+
+func registerCapabilityHooks(hooks *server.Hooks) {
+	hooks.AddAfterInitialize(func(ctx context.Context, id any, req *mcp.InitializeRequest, result *mcp.InitializeResult) {
+		sessionFeatures.Set(ctx, negotiateFeatures(result.ProtocolVersion, req.Params.Capabilities))
+	})
+	hooks.AddOnUnregisterSession(func(ctx context.Context, session server.ClientSession) {
+		sessionFeatures.Forget(session.SessionID())
+	})
+}
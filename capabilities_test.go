@@ -0,0 +1,50 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestNegotiateFeatures(t *testing.T) {
+	old := negotiateFeatures("2025-03-26", mcp.ClientCapabilities{})
+	if old.StructuredContent || old.ResourceLinks || old.Sampling || old.Elicitation {
+		t.Fatalf("expected no rich features for an older client, got %+v", old)
+	}
+	current := negotiateFeatures(mcp.LATEST_PROTOCOL_VERSION, mcp.ClientCapabilities{Sampling: &mcp.SamplingCapability{}})
+	if !current.StructuredContent || !current.ResourceLinks || !current.Sampling || current.Elicitation {
+		t.Fatalf("unexpected features %+v", current)
+	}
+}
+
+func TestDowngradeResult(t *testing.T) {
+	res := &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.NewTextContent("export ready"),
+			mcp.NewResourceLink("shopping-list://exports/abc", "shopping-list.csv", "export", "text/csv"),
+		},
+		StructuredContent: map[string]any{"total": 2},
+	}
+	if got := downgradeResult(res, allFeatures); got != res {
+		t.Fatal("expected a capable client's result to pass through unchanged")
+	}
+
+	got := downgradeResult(res, clientFeatures{})
+	if got.StructuredContent != nil {
+		t.Fatal("expected structured content to be dropped")
+	}
+	if len(got.Content) != 3 {
+		t.Fatalf("expected 3 text blocks, got %d", len(got.Content))
+	}
+	for _, c := range got.Content {
+		if _, ok := c.(mcp.TextContent); !ok {
+			t.Fatalf("expected only text content, got %T", c)
+		}
+	}
+	if text := got.Content[2].(mcp.TextContent).Text; text != `{"total":2}` {
+		t.Fatalf("unexpected JSON fallback %q", text)
+	}
+	if len(res.Content) != 2 || res.StructuredContent == nil {
+		t.Fatal("expected the original result to be left alone")
+	}
+}
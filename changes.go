@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/UnitVectorY-Labs/mcp-shopping-list-firestore/pkg/shoppinglist"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// -----------------------------------------------------------------------------
+// Incremental changes
+// -----------------------------------------------------------------------------
+
+func registerChangeTools(srv *server.MCPServer, service *shoppinglist.Service) {
+	// get_changes
+	getChangesTool := mcp.NewTool(
+		"get_changes",
+		mcp.WithDescription(fmt.Sprintf("Return only the items created, updated, or deleted since a time, so a client keeping its own copy of the list can sync without downloading all of it. Pass the returned 'as_of' as 'since' on the next call. When 'resync' is set (more than %d changes, or deletions older than the tombstone retention), fetch the whole list with list_items instead.", shoppinglist.MaxChanges)),
+		mcp.WithTitleAnnotation("Get Shopping List Changes"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("since", mcp.Description("Changes at or after this RFC 3339 time, usually the 'as_of' of the previous call, or within this duration, e.g. '1h'"), mcp.Required()),
+		listArg,
+	)
+	srv.AddTool(getChangesTool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := req.GetArguments()
+
+		// Extract required since field
+		v, _ := args["since"].(string)
+		if v == "" {
+			return mcp.NewToolResultError("'since' is required"), nil
+		}
+		since, err := parseSince(v, service.Now())
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		toolCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
+		defer cancel()
+
+		svc, err := listFromArgs(toolCtx, service, args)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to resolve list: %v", err)), nil
+		}
+		resp, err := svc.Changes(toolCtx, since)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get changes: %v", err)), nil
+		}
+		return jsonResult(resp)
+	})
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// -----------------------------------------------------------------------------
+// One-shot CLI calls
+// -----------------------------------------------------------------------------
+
+// runCall invokes a single tool from the command line, e.g.
+// `call upsert_item --args '{"name":"milk"}'`, writing its JSON result to w.
+// A tool error is returned so the process exits non-zero.
+func runCall(ctx context.Context, srv *server.MCPServer, args []string, w io.Writer) error {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return errors.New("usage: call <tool> [--args '{...}']")
+	}
+	name := args[0]
+
+	fs := flag.NewFlagSet("call", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	rawArgs := fs.String("args", "{}", "tool arguments as a JSON object")
+	if err := fs.Parse(args[1:]); err != nil {
+		return fmt.Errorf("parse call flags: %w", err)
+	}
+
+	if srv.GetTool(name) == nil {
+		return fmt.Errorf("unknown tool %q", name)
+	}
+	var toolArgs map[string]any
+	if err := json.Unmarshal([]byte(*rawArgs), &toolArgs); err != nil {
+		return fmt.Errorf("--args must be a JSON object: %w", err)
+	}
+
+	// Go through the server rather than the tool's handler, so the call passes
+	// the same middleware as one from a client: explain, feature flags, list
+	// moves, telemetry, and field naming.
+	var req mcp.CallToolRequest
+	req.Params.Name = name
+	req.Params.Arguments = toolArgs
+	raw, err := json.Marshal(mcp.JSONRPCRequest{
+		JSONRPC: mcp.JSONRPC_VERSION,
+		ID:      mcp.NewRequestId(1),
+		Request: mcp.Request{Method: string(mcp.MethodToolsCall)},
+		Params:  req.Params,
+	})
+	if err != nil {
+		return fmt.Errorf("encode call: %w", err)
+	}
+	var result *mcp.CallToolResult
+	switch resp := srv.HandleMessage(ctx, raw).(type) {
+	case mcp.JSONRPCResponse:
+		var ok bool
+		if result, ok = resp.Result.(*mcp.CallToolResult); !ok {
+			return fmt.Errorf("call %s: unexpected result %T", name, resp.Result)
+		}
+	case mcp.JSONRPCError:
+		return fmt.Errorf("call %s: %s", name, resp.Error.Message)
+	default:
+		return fmt.Errorf("call %s: unexpected response %T", name, resp)
+	}
+
+	text := resultText(result)
+	if result.IsError {
+		return fmt.Errorf("%s: %s", name, text)
+	}
+	_, err = fmt.Fprintln(w, text)
+	return err
+}
+
+// resultText joins the text content of a tool result.
+func resultText(result *mcp.CallToolResult) string {
+	var parts []string
+	for _, c := range result.Content {
+		switch c := c.(type) {
+		case mcp.TextContent:
+			parts = append(parts, c.Text)
+		case mcp.ResourceLink:
+			parts = append(parts, c.URI)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/UnitVectorY-Labs/mcp-shopping-list-firestore/pkg/shoppinglist"
+)
+
+func TestRunCallPrintsToolResult(t *testing.T) {
+	srv := newMCPServer(nil, shoppinglist.LocalEmbedder{Dims: 256}, serverConfig{currency: "USD"})
+
+	var out bytes.Buffer
+	err := runCall(context.Background(), srv, []string{"set_preferences", "--args", `{"sort_by":"name"}`}, &out)
+	if err != nil {
+		t.Fatalf("runCall returned error: %v", err)
+	}
+	if !strings.Contains(out.String(), `"sort_by":"name"`) {
+		t.Fatalf("unexpected output: %q", out.String())
+	}
+	sessionPrefs.Forget("")
+}
+
+func TestRunCallReportsToolErrors(t *testing.T) {
+	srv := newMCPServer(nil, shoppinglist.LocalEmbedder{Dims: 256}, serverConfig{currency: "USD"})
+
+	err := runCall(context.Background(), srv, []string{"set_preferences", "--args", `{"sort_by":"price"}`}, &bytes.Buffer{})
+	if err == nil || !strings.Contains(err.Error(), "unsupported sort_by") {
+		t.Fatalf("expected tool error, got %v", err)
+	}
+}
+
+func TestRunCallExplainsWithoutWriting(t *testing.T) {
+	srv := newMCPServer(nil, shoppinglist.LocalEmbedder{Dims: 256}, serverConfig{currency: "USD"})
+
+	var out bytes.Buffer
+	err := runCall(context.Background(), srv, []string{"set_preferences", "--args", `{"sort_by":"name","explain":true}`}, &out)
+	if err != nil {
+		t.Fatalf("runCall returned error: %v", err)
+	}
+	if !strings.Contains(out.String(), `"not_run"`) {
+		t.Fatalf("expected an explanation, got %q", out.String())
+	}
+	if prefs := sessionPrefs.Get(context.Background()); prefs.SortBy != "" {
+		t.Fatalf("expected the explained call to change nothing, got %+v", prefs)
+	}
+}
+
+func TestRunCallRefusesDisabledTools(t *testing.T) {
+	// The emulator address lets the client be built without credentials; the
+	// refusal comes before any RPC.
+	t.Setenv("FIRESTORE_EMULATOR_HOST", "127.0.0.1:1")
+	service, err := shoppinglist.NewService(context.Background(), "test-project", "(default)", "shopping", "")
+	if err != nil {
+		t.Fatalf("NewService: %v", err)
+	}
+	defer service.Close()
+	service.Flags().Put(shoppinglist.FeatureFlag{Name: "prefs-beta", Tools: []string{"set_preferences"}})
+	srv := newMCPServer(service, shoppinglist.LocalEmbedder{Dims: 256}, serverConfig{currency: "USD"})
+
+	err = runCall(context.Background(), srv, []string{"set_preferences", "--args", `{"sort_by":"name"}`}, &bytes.Buffer{})
+	// A flag on for no list or tenant hides the tool altogether.
+	if err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Fatalf("expected the disabled tool to be refused, got %v", err)
+	}
+	if prefs := sessionPrefs.Get(context.Background()); prefs.SortBy != "" {
+		t.Fatalf("expected the refused call to change nothing, got %+v", prefs)
+	}
+}
+
+func TestRunCallRejectsBadInput(t *testing.T) {
+	srv := newMCPServer(nil, shoppinglist.LocalEmbedder{Dims: 256}, serverConfig{currency: "USD"})
+
+	for _, args := range [][]string{nil, {"nope"}, {"get_preferences", "--args", "[1]"}} {
+		if err := runCall(context.Background(), srv, args, &bytes.Buffer{}); err == nil {
+			t.Errorf("expected error for %q", args)
+		}
+	}
+}
+
+func TestClearListRequiresConfirm(t *testing.T) {
+	srv := newMCPServer(nil, shoppinglist.LocalEmbedder{Dims: 256}, serverConfig{currency: "USD"})
+
+	for _, args := range []string{`{}`, `{"confirm":false}`} {
+		err := runCall(context.Background(), srv, []string{"clear_list", "--args", args}, &bytes.Buffer{})
+		if err == nil || !strings.Contains(err.Error(), "without 'confirm'") {
+			t.Errorf("%s: expected a refusal, got %v", args, err)
+		}
+	}
+}
+
+func TestTogglePurchasedNeedsAnItem(t *testing.T) {
+	srv := newMCPServer(nil, shoppinglist.LocalEmbedder{Dims: 256}, serverConfig{currency: "USD"})
+
+	err := runCall(context.Background(), srv, []string{"toggle_purchased", "--args", `{"name":"  "}`}, &bytes.Buffer{})
+	if err == nil || !strings.Contains(err.Error(), "either 'id' or 'name'") {
+		t.Fatalf("expected a refusal, got %v", err)
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"maps"
+	"regexp"
+	"strings"
+
+	"github.com/UnitVectorY-Labs/mcp-shopping-list-firestore/pkg/shoppinglist"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// -----------------------------------------------------------------------------
+// Extra collections
+// -----------------------------------------------------------------------------
+
+// CollectionConfig is an extra household list defined in the config file,
+// e.g. chores or a wishlist, which gets its own list, upsert, and remove
+// tools.
+type CollectionConfig struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// collectionName is what an extra collection may be called: it becomes part
+// of tool and Firestore collection names.
+var collectionName = regexp.MustCompile(`^[a-z][a-z0-9_]{0,29}$`)
+
+// validateCollections checks the names of the extra collections.
+func validateCollections(collections []CollectionConfig) error {
+	seen := map[string]bool{}
+	for _, c := range collections {
+		if !collectionName.MatchString(c.Name) {
+			return fmt.Errorf("collection name %q must be lowercase letters, digits, and underscores, starting with a letter, at most 30 characters", c.Name)
+		}
+		if seen[c.Name] {
+			return fmt.Errorf("collection %q is defined twice", c.Name)
+		}
+		seen[c.Name] = true
+	}
+	return nil
+}
+
+// collectionTools are the tools generated for each extra collection, from
+// the item tools they reuse.
+var collectionTools = []struct {
+	verb, base, title string
+}{
+	{"list", "list_items", "List %s"},
+	{"upsert", "upsert_item", "Upsert %s Item"},
+	{"remove", "remove_item", "Remove %s Item"},
+	{"restore", "restore_item", "Restore %s Item"},
+}
+
+type collectionKey struct{}
+
+// withCollection returns a context whose item tools run on svc.
+func withCollection(ctx context.Context, svc *shoppinglist.Service) context.Context {
+	return context.WithValue(ctx, collectionKey{}, svc)
+}
+
+// collectionFromContext is the extra collection a generated tool runs on, or
+// nil.
+func collectionFromContext(ctx context.Context) *shoppinglist.Service {
+	svc, _ := ctx.Value(collectionKey{}).(*shoppinglist.Service)
+	return svc
+}
+
+// registerCollectionTools adds list_<name>, upsert_<name>, remove_<name>, and
+// restore_<name> for each extra collection. They are the item tools with the 'list'
+// argument taken away, running on <collection>_list_<name>, so validation,
+// rules, history, and token scopes apply as on a shopping list. Names that
+// would clash with an existing tool are skipped.
+func registerCollectionTools(srv *server.MCPServer, service *shoppinglist.Service, collections []CollectionConfig) {
+	for _, c := range collections {
+		for _, ct := range collectionTools {
+			name := ct.verb + "_" + c.Name
+			base := srv.GetTool(ct.base)
+			if base == nil {
+				continue
+			}
+			if srv.GetTool(name) != nil {
+				log.Printf("warn: collection %q: tool %s already exists; skipping it", c.Name, name)
+				continue
+			}
+
+			tool := base.Tool
+			tool.Name = name
+			tool.Description = strings.ReplaceAll(tool.Description, "the shopping list", "the "+c.Name+" list")
+			tool.Description = strings.ReplaceAll(tool.Description, "restore_item", "restore_"+c.Name)
+			if c.Description != "" {
+				tool.Description += " The " + c.Name + " list: " + c.Description
+			}
+			tool.Annotations.Title = fmt.Sprintf(ct.title, c.Name)
+			tool.InputSchema.Properties = maps.Clone(tool.InputSchema.Properties)
+			delete(tool.InputSchema.Properties, "list")
+
+			collection, handler := c.Name, base.Handler
+			srv.AddTool(tool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return handler(withCollection(ctx, service.ForCollection(collection)), req)
+			})
+		}
+	}
+}
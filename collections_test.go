@@ -0,0 +1,43 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/UnitVectorY-Labs/mcp-shopping-list-firestore/pkg/shoppinglist"
+)
+
+func TestCollectionToolsDropTheListArgument(t *testing.T) {
+	collections := []CollectionConfig{{Name: "chores", Description: "Household chores to do."}, {Name: "items"}}
+	srv := newMCPServer(nil, shoppinglist.LocalEmbedder{Dims: 256}, serverConfig{currency: "USD", collections: collections})
+
+	for _, name := range []string{"list_chores", "upsert_chores", "remove_chores", "restore_chores"} {
+		tool := srv.GetTool(name)
+		if tool == nil {
+			t.Fatalf("expected %s to be generated", name)
+		}
+		if _, ok := tool.Tool.InputSchema.Properties["list"]; ok {
+			t.Fatalf("expected %s without a list argument", name)
+		}
+	}
+	if _, ok := srv.GetTool("list_items").Tool.InputSchema.Properties["list"]; !ok {
+		t.Fatal("expected list_items to keep its list argument")
+	}
+	if got := srv.GetTool("remove_chores").Tool.Description; got != "Remove an item from the chores list by its ID, moving it to the trash, where restore_chores can bring it back. An unknown ID gives an error with code 'not_found'. The chores list: Household chores to do." {
+		t.Fatalf("unexpected description %q", got)
+	}
+}
+
+func TestValidateCollectionsRefusesBadNames(t *testing.T) {
+	for _, collections := range [][]CollectionConfig{
+		{{Name: "Chores"}},
+		{{Name: "wish-list"}},
+		{{Name: "chores"}, {Name: "chores"}},
+	} {
+		if err := validateCollections(collections); err == nil {
+			t.Fatalf("expected %v to be refused", collections)
+		}
+	}
+	if err := validateCollections([]CollectionConfig{{Name: "chores"}, {Name: "wishlist"}}); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// -----------------------------------------------------------------------------
+// Field naming compatibility
+// -----------------------------------------------------------------------------
+
+// Field naming styles of tool result JSON.
+const (
+	fieldNamesSnake = "snake_case"
+	fieldNamesCamel = "camelCase"
+)
+
+// dataMapFields hold objects keyed by data, such as category names or item
+// field paths, rather than by field names, so their keys are kept as they are.
+var dataMapFields = map[string]bool{
+	"base":         true,
+	"by_category":  true,
+	"by_group":     true,
+	"categories":   true,
+	"changes":      true,
+	"counts":       true,
+	"priorities":   true,
+	"reservations": true,
+}
+
+// fieldNaming rewrites the field names of JSON tool results for consumers
+// expecting camelCase. With legacy set the snake_case names are kept next to
+// the camelCase ones, so consumers can move over one field at a time.
+type fieldNaming struct {
+	camel  bool
+	legacy bool
+}
+
+// camelCase turns a snake_case field name into camelCase.
+func camelCase(name string) string {
+	parts := strings.Split(name, "_")
+	for i := 1; i < len(parts); i++ {
+		if parts[i] != "" {
+			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
+
+// rename returns v with the field names of every object in it rewritten.
+// The keys of data maps are kept, though the objects under them are renamed.
+func (n fieldNaming) rename(v any, data bool) any {
+	switch v := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for k, e := range v {
+			e = n.rename(e, !data && dataMapFields[k])
+			if data {
+				out[k] = e
+				continue
+			}
+			out[camelCase(k)] = e
+			if n.legacy {
+				out[k] = e
+			}
+		}
+		return out
+	case []any:
+		for i, e := range v {
+			v[i] = n.rename(e, false)
+		}
+		return v
+	}
+	return v
+}
+
+// renameText rewrites the field names in a JSON text, returning text as it
+// is when it is not JSON.
+func (n fieldNaming) renameText(text string) string {
+	dec := json.NewDecoder(strings.NewReader(text))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil || dec.More() {
+		return text
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(n.rename(v, false)); err != nil {
+		return text
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// middleware rewrites the JSON text and structured content of every tool
+// result, errors included.
+func (n fieldNaming) middleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		res, err := next(ctx, req)
+		if err != nil || res == nil {
+			return res, err
+		}
+		for i, c := range res.Content {
+			if text, ok := c.(mcp.TextContent); ok {
+				text.Text = n.renameText(text.Text)
+				res.Content[i] = text
+			}
+		}
+		if res.StructuredContent != nil {
+			if b, err := json.Marshal(res.StructuredContent); err == nil {
+				var v any
+				if json.Unmarshal(b, &v) == nil {
+					res.StructuredContent = n.rename(v, false)
+				}
+			}
+		}
+		return res, nil
+	}
+}
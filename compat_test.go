@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestFieldNamingRenamesFieldsButNotDataKeys(t *testing.T) {
+	n := fieldNaming{camel: true}
+	text := `{"items":[{"id":"1","created_at":"2025-08-12T12:00:00Z","reservations":{"pat_smith":2}}],"summary":{"by_category":{"personal_care":1}},"next_cursor":"abc"}`
+
+	var got map[string]any
+	if err := json.Unmarshal([]byte(n.renameText(text)), &got); err != nil {
+		t.Fatal(err)
+	}
+	item := got["items"].([]any)[0].(map[string]any)
+	if _, ok := item["createdAt"]; !ok {
+		t.Fatalf("expected createdAt, got %v", item)
+	}
+	if _, ok := item["reservations"].(map[string]any)["pat_smith"]; !ok {
+		t.Fatalf("expected member names kept, got %v", item["reservations"])
+	}
+	if _, ok := got["summary"].(map[string]any)["byCategory"].(map[string]any)["personal_care"]; !ok {
+		t.Fatalf("expected category names kept, got %v", got["summary"])
+	}
+	if _, ok := got["next_cursor"]; ok {
+		t.Fatal("expected snake_case names dropped without legacy fields")
+	}
+}
+
+func TestFieldNamingLegacyKeepsSnakeCase(t *testing.T) {
+	n := fieldNaming{camel: true, legacy: true}
+
+	var got map[string]any
+	if err := json.Unmarshal([]byte(n.renameText(`{"stale_as_of":"x","amount":1.50}`)), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got["staleAsOf"] != "x" || got["stale_as_of"] != "x" {
+		t.Fatalf("expected both spellings, got %v", got)
+	}
+	if got["amount"] != 1.5 {
+		t.Fatalf("expected amount kept, got %v", got)
+	}
+}
+
+func TestFieldNamingMiddlewareLeavesPlainText(t *testing.T) {
+	n := fieldNaming{camel: true}
+	handler := n.middleware(func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultError("invalid or missing 'id'"), nil
+	})
+
+	res, err := handler(context.Background(), mcp.CallToolRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text := res.Content[0].(mcp.TextContent).Text; text != "invalid or missing 'id'" {
+		t.Fatalf("expected plain text untouched, got %q", text)
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/UnitVectorY-Labs/mcp-shopping-list-firestore/pkg/shoppinglist"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// conflictCode is the tool error code of a write refused because the item
+// changed since the caller read it.
+const conflictCode = "conflict"
+
+// preconditionFromArgs reads the optional if_unmodified_since and
+// if_revision arguments of a write.
+func preconditionFromArgs(args map[string]any) (shoppinglist.Precondition, error) {
+	var pre shoppinglist.Precondition
+	if v, ok := args["if_unmodified_since"].(string); ok && v != "" {
+		t, err := time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			return pre, fmt.Errorf("invalid 'if_unmodified_since' %q (expected the item's updated_at, an RFC 3339 time)", v)
+		}
+		pre.UnmodifiedSince = &t
+	}
+	if v, ok := args["if_revision"].(float64); ok {
+		if v < 1 || v != math.Trunc(v) {
+			return pre, fmt.Errorf("'if_revision' must be a whole number of at least 1")
+		}
+		rev := int64(v)
+		pre.Revision = &rev
+	}
+	return pre, nil
+}
+
+// conflictResult reports err, wrapping shoppinglist.ErrItemConflict, as a
+// tool error asking the caller to read the item again.
+func conflictResult(err error, id string) *mcp.CallToolResult {
+	return errorResult(toolError{Code: conflictCode, Error: err.Error() + "; read the item again and retry", ID: id})
+}
@@ -0,0 +1,21 @@
+package main
+
+import "testing"
+
+func TestPreconditionFromArgs(t *testing.T) {
+	pre, err := preconditionFromArgs(map[string]any{"if_unmodified_since": "2024-05-01T09:00:00.123456Z", "if_revision": float64(3)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pre.UnmodifiedSince == nil || pre.UnmodifiedSince.Nanosecond() != 123456000 || pre.Revision == nil || *pre.Revision != 3 {
+		t.Fatalf("unexpected precondition: %+v", pre)
+	}
+	if pre, err := preconditionFromArgs(map[string]any{}); err != nil || !pre.IsZero() {
+		t.Fatalf("expected no precondition, got %+v, %v", pre, err)
+	}
+	for _, args := range []map[string]any{{"if_unmodified_since": "yesterday"}, {"if_revision": float64(0)}, {"if_revision": 1.5}} {
+		if _, err := preconditionFromArgs(args); err == nil {
+			t.Fatalf("expected %v to be rejected", args)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/UnitVectorY-Labs/mcp-shopping-list-firestore/pkg/shoppinglist"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// -----------------------------------------------------------------------------
+// Quantity modes
+// -----------------------------------------------------------------------------
+
+func registerQuantityModeTools(srv *server.MCPServer, service *shoppinglist.Service) {
+	// set_quantity_mode
+	setQuantityModeTool := mcp.NewTool(
+		"set_quantity_mode",
+		mcp.WithDescription("Choose what quantities mean on a list. 'amount' (the default): the quantity is how much to buy and checking an item off marks all of it bought. 'count': the quantity is the number still needed, and mark_purchased with a count decrements it until none remain, e.g. 6 AA batteries, bought 4, 2 remain."),
+		mcp.WithTitleAnnotation("Set Quantity Mode"),
+		mcp.WithIdempotentHintAnnotation(true),
+		mcp.WithString("mode", mcp.Description("amount or count"), mcp.Required(), mcp.Enum(shoppinglist.QuantityModeAmount, shoppinglist.QuantityModeCount)),
+		listArg,
+	)
+	srv.AddTool(setQuantityModeTool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := req.GetArguments()
+
+		// Extract required fields
+		mode, ok := args["mode"].(string)
+		if !ok || mode == "" {
+			return mcp.NewToolResultError("invalid or missing 'mode'"), nil
+		}
+
+		toolCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+
+		svc, err := listFromArgs(toolCtx, service, args)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to resolve list: %v", err)), nil
+		}
+
+		settings, err := svc.SetQuantityMode(toolCtx, mode)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to set quantity mode: %v", err)), nil
+		}
+		return jsonResult(shoppinglist.SettingsResponse{Settings: settings})
+	})
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/UnitVectorY-Labs/mcp-shopping-list-firestore/pkg/shoppinglist"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// -----------------------------------------------------------------------------
+// Aggregated counts
+// -----------------------------------------------------------------------------
+
+func registerCountTools(srv *server.MCPServer, service *shoppinglist.Service) {
+	// count_items
+	countItemsTool := mcp.NewTool(
+		"count_items",
+		mcp.WithDescription("Count the items on the list, how many are still to buy and checked off, and optionally how many are in given store sections, without reading the items. Much cheaper than list_items when only counts are needed, e.g. \"how many things are left?\"."),
+		mcp.WithTitleAnnotation("Count Shopping Items"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithArray("categories", mcp.Description(fmt.Sprintf("Store sections to count, e.g. [\"produce\", \"dairy\"], or 'uncategorized' for items without one; at most %d (optional)", shoppinglist.MaxCountCategories)), mcp.WithStringItems()),
+		listArg,
+	)
+	srv.AddTool(countItemsTool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := req.GetArguments()
+
+		// Extract optional categories field
+		var categories []string
+		if raw, ok := args["categories"]; ok && raw != nil {
+			var err error
+			if categories, err = shoppinglist.ParseCountCategories(raw); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+		}
+
+		toolCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+
+		svc, err := listFromArgs(toolCtx, service, args)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to resolve list: %v", err)), nil
+		}
+
+		counts, err := svc.CountItems(toolCtx, categories)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to count items: %v", err)), nil
+		}
+		return jsonResult(counts)
+	})
+}
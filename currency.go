@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/UnitVectorY-Labs/mcp-shopping-list-firestore/pkg/shoppinglist"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// -----------------------------------------------------------------------------
+// Currencies and exchange rates
+// -----------------------------------------------------------------------------
+
+func registerCurrencyTools(srv *server.MCPServer, service *shoppinglist.Service, rates shoppinglist.ExchangeRateSource, currency string, budget float64) {
+	// price_report
+	priceReportTool := mcp.NewTool(
+		"price_report",
+		mcp.WithDescription("Total the shopping list from recorded package prices, converted into the household's preferred currency."),
+		mcp.WithTitleAnnotation("Shopping List Price Report"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("currency", mcp.Description(fmt.Sprintf("ISO 4217 currency to report in (optional, defaults to %s)", currency))),
+		listArg,
+	)
+	srv.AddTool(priceReportTool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := req.GetArguments()
+
+		// Extract optional currency field
+		target := currency
+		if c, ok := args["currency"].(string); ok && c != "" {
+			normalized, err := shoppinglist.NormalizeCurrency(c)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			target = normalized
+		}
+
+		toolCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
+		defer cancel()
+
+		svc, err := listFromArgs(toolCtx, service, args)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to resolve list: %v", err)), nil
+		}
+
+		view, err := svc.View(toolCtx)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to list items: %v", err)), nil
+		}
+		report := shoppinglist.BuildPriceReport(toolCtx, rates, target, view.Items)
+		shoppinglist.CheckBudget(toolCtx, &report, rates, budget, currency)
+		return jsonResult(report)
+	})
+}
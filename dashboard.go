@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/UnitVectorY-Labs/mcp-shopping-list-firestore/pkg/shoppinglist"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// -----------------------------------------------------------------------------
+// Dashboard
+// -----------------------------------------------------------------------------
+
+// dashboardURI is the resource summarizing every list.
+const dashboardURI = "shoppinglist://dashboard"
+
+// dashboardRefresh is how often the dashboard is rebuilt while any session is
+// subscribed to it.
+const dashboardRefresh = 30 * time.Second
+
+// dashboardWatch rebuilds the dashboard while sessions are subscribed to it
+// and tells them when it changes.
+type dashboardWatch struct {
+	srv     *server.MCPServer
+	builder shoppinglist.DashboardBuilder
+
+	mu          sync.Mutex
+	subscribers map[string]bool
+	running     bool
+	last        []byte
+}
+
+func (w *dashboardWatch) subscribe(sessionID string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.subscribers[sessionID] = true
+	if !w.running {
+		w.running = true
+		go w.run()
+	}
+}
+
+func (w *dashboardWatch) unsubscribe(sessionID string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	delete(w.subscribers, sessionID)
+}
+
+// run refreshes the dashboard until the last subscriber leaves.
+func (w *dashboardWatch) run() {
+	ticker := time.NewTicker(dashboardRefresh)
+	defer ticker.Stop()
+	for range ticker.C {
+		w.mu.Lock()
+		if len(w.subscribers) == 0 {
+			w.running, w.last = false, nil
+			w.mu.Unlock()
+			return
+		}
+		w.mu.Unlock()
+		w.refresh()
+	}
+}
+
+// refresh rebuilds the dashboard and notifies subscribers when the lists
+// changed since the last build.
+func (w *dashboardWatch) refresh() {
+	ctx, cancel := context.WithTimeout(context.Background(), dashboardRefresh)
+	defer cancel()
+	dash, err := w.builder.Build(ctx, nil)
+	if err != nil {
+		log.Printf("warn: refresh dashboard: %v", err)
+		return
+	}
+	current, _ := json.Marshal(dash.Lists)
+
+	w.mu.Lock()
+	changed := w.last != nil && !bytes.Equal(current, w.last)
+	w.last = current
+	sessions := make([]string, 0, len(w.subscribers))
+	for id := range w.subscribers {
+		sessions = append(sessions, id)
+	}
+	w.mu.Unlock()
+
+	if !changed {
+		return
+	}
+	for _, id := range sessions {
+		err := w.srv.SendNotificationToSpecificClient(id, "notifications/resources/updated", map[string]any{"uri": dashboardURI})
+		if err != nil {
+			w.unsubscribe(id)
+		}
+	}
+}
+
+func registerDashboardResources(srv *server.MCPServer, hooks *server.Hooks, service *shoppinglist.Service, cfg serverConfig) {
+	builder := shoppinglist.DashboardBuilder{Service: service, Rates: cfg.rates, Currency: cfg.currency, Budget: cfg.budget}
+	watch := &dashboardWatch{srv: srv, builder: builder, subscribers: map[string]bool{}}
+
+	hooks.AddAfterSubscribe(func(ctx context.Context, id any, req *mcp.SubscribeRequest, result *mcp.EmptyResult) {
+		if req.Params.URI == dashboardURI {
+			watch.subscribe(sessionKey(ctx))
+		}
+	})
+	hooks.AddAfterUnsubscribe(func(ctx context.Context, id any, req *mcp.UnsubscribeRequest, result *mcp.EmptyResult) {
+		if req.Params.URI == dashboardURI {
+			watch.unsubscribe(sessionKey(ctx))
+		}
+	})
+	hooks.AddOnUnregisterSession(func(ctx context.Context, session server.ClientSession) {
+		watch.unsubscribe(session.SessionID())
+	})
+
+	srv.AddResource(
+		mcp.NewResource(
+			dashboardURI,
+			"Shopping dashboard",
+			mcp.WithResourceDescription("Every list at a glance: item counts, estimated totals against the budget, and the next needed-by date. Subscribe to be notified when it changes."),
+			mcp.WithMIMEType("application/json"),
+		),
+		func(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+			readCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+			defer cancel()
+
+			// Only the lists the request's token may read are summarized.
+			tok := tokenFromContext(ctx)
+			dash, err := builder.Build(readCtx, func(list shoppinglist.ListInfo) bool {
+				return checkAccess(tok, dashboardURI, shoppinglist.ScopeRead, list) == nil
+			})
+			if err != nil {
+				return nil, fmt.Errorf("build dashboard: %w", err)
+			}
+			b, err := json.MarshalIndent(dash, "", "  ")
+			if err != nil {
+				return nil, err
+			}
+			return []mcp.ResourceContents{
+				mcp.TextResourceContents{URI: dashboardURI, MIMEType: "application/json", Text: string(b)},
+			}, nil
+		},
+	)
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// -----------------------------------------------------------------------------
+// Shutdown drain
+// -----------------------------------------------------------------------------
+
+// drainRetryAfter is how long clients are told to wait before retrying a tool
+// call refused while draining, by which time another instance should serve it.
+const drainRetryAfter = 5 * time.Second
+
+// drainErrorCode is the JSON-RPC error code of a call refused while draining,
+// from the range reserved for server errors.
+const drainErrorCode = -32001
+
+// drainer refuses new tool calls once shutdown begins and tracks the calls
+// still in flight, so they can finish before the server stops.
+type drainer struct {
+	mu       sync.Mutex
+	draining bool
+	inflight sync.WaitGroup
+}
+
+// begin counts a tool call in flight, or reports false once draining.
+func (d *drainer) begin() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.draining {
+		return false
+	}
+	d.inflight.Add(1)
+	return true
+}
+
+// drainMaxBytes caps the size of a JSON-RPC message or batch the drainer
+// reads to look for tool calls.
+const drainMaxBytes = 4 << 20
+
+// rpcMessage is the part of a JSON-RPC message the drainer inspects.
+type rpcMessage struct {
+	ID     any    `json:"id,omitempty"`
+	Method string `json:"method"`
+}
+
+// rpcMessages returns the messages in a JSON-RPC message or batch, and
+// whether it was a batch.
+func rpcMessages(body []byte) ([]rpcMessage, bool) {
+	var batch []rpcMessage
+	if err := json.Unmarshal(body, &batch); err == nil {
+		return batch, true
+	}
+	var msg rpcMessage
+	if json.Unmarshal(body, &msg) != nil {
+		return nil, false
+	}
+	return []rpcMessage{msg}, false
+}
+
+// toolCalls returns the tools/call requests among msgs.
+func toolCalls(msgs []rpcMessage) []rpcMessage {
+	var calls []rpcMessage
+	for _, m := range msgs {
+		if m.Method == string(mcp.MethodToolsCall) {
+			calls = append(calls, m)
+		}
+	}
+	return calls
+}
+
+// wrap passes requests to next. Tool calls are counted while they run, and once
+// draining they are refused with 503 and Retry-After, along with every other
+// request in the same batch; everything else, including responses to calls
+// already running, still goes through.
+func (d *drainer) wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Body == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, drainMaxBytes))
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+				return
+			}
+			http.Error(w, "read request body", http.StatusBadRequest)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		msgs, batch := rpcMessages(body)
+		if len(toolCalls(msgs)) == 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if !d.begin() {
+			writeDraining(w, msgs, batch)
+			return
+		}
+		defer d.inflight.Done()
+		next.ServeHTTP(w, r)
+	})
+}
+
+// writeDraining refuses the requests among msgs with a retriable JSON-RPC
+// error each, as a batch when they came in one.
+func writeDraining(w http.ResponseWriter, msgs []rpcMessage, batch bool) {
+	seconds := int(drainRetryAfter / time.Second)
+	refusals := []map[string]any{}
+	for _, m := range msgs {
+		// Notifications have no ID and get no response.
+		if m.ID == nil {
+			continue
+		}
+		refusals = append(refusals, map[string]any{
+			"jsonrpc": mcp.JSONRPC_VERSION,
+			"id":      m.ID,
+			"error": map[string]any{
+				"code":    drainErrorCode,
+				"message": "server draining; retry the call",
+				"data":    map[string]any{"retryable": true, "retry_after_seconds": seconds},
+			},
+		})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Retry-After", strconv.Itoa(seconds))
+	w.WriteHeader(http.StatusServiceUnavailable)
+	switch {
+	case batch:
+		_ = json.NewEncoder(w).Encode(refusals)
+	case len(refusals) == 1:
+		_ = json.NewEncoder(w).Encode(refusals[0])
+	}
+}
+
+// Drain stops accepting tool calls and waits for those in flight to finish,
+// or for ctx to be done. It reports whether every call finished.
+func (d *drainer) Drain(ctx context.Context) bool {
+	d.mu.Lock()
+	d.draining = true
+	d.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		d.inflight.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// shutdownHTTP drains tool calls and then shuts the HTTP server down, giving
+// the whole sequence up to timeout.
+func shutdownHTTP(d *drainer, shutdown func(context.Context) error, timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	log.Printf("draining: refusing new tool calls and waiting up to %s for those in flight", timeout)
+	if !d.Drain(ctx) {
+		log.Printf("warn: drain timed out with tool calls still in flight")
+	}
+	if err := shutdown(ctx); err != nil {
+		log.Printf("warn: HTTP shutdown: %v", err)
+	}
+}
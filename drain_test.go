@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestToolCalls(t *testing.T) {
+	msgs, batch := rpcMessages([]byte(`{"jsonrpc":"2.0","id":7,"method":"tools/call"}`))
+	if calls := toolCalls(msgs); batch || len(calls) != 1 || calls[0].ID != float64(7) {
+		t.Fatalf("expected one call with id 7, got %+v (batch %v)", calls, batch)
+	}
+	msgs, batch = rpcMessages([]byte(`[{"jsonrpc":"2.0","id":1,"method":"tools/list"},{"jsonrpc":"2.0","id":2,"method":"tools/call"}]`))
+	if calls := toolCalls(msgs); !batch || len(calls) != 1 || calls[0].ID != float64(2) {
+		t.Fatalf("expected the batch's tool call, got %+v (batch %v)", calls, batch)
+	}
+	msgs, _ = rpcMessages([]byte(`{"jsonrpc":"2.0","method":"notifications/initialized"}`))
+	if calls := toolCalls(msgs); len(calls) != 0 {
+		t.Fatalf("expected no tool calls, got %+v", calls)
+	}
+}
+
+func TestDrainerRefusesNewToolCallsAndFinishesInFlight(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	d := &drainer{}
+	h := d.wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "slow") {
+			close(started)
+			<-release
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	call := func(path, method string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		body := `{"jsonrpc":"2.0","id":1,"method":"` + method + `"}`
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, path, strings.NewReader(body)))
+		return rec
+	}
+
+	inflight := make(chan int)
+	go func() { inflight <- call("/slow", "tools/call").Code }()
+	<-started
+
+	drained := make(chan bool)
+	go func() { drained <- d.Drain(context.Background()) }()
+
+	// Wait for Drain to flip the state before probing it.
+	for !func() bool { d.mu.Lock(); defer d.mu.Unlock(); return d.draining }() {
+		time.Sleep(time.Millisecond)
+	}
+
+	rec := call("/mcp", "tools/call")
+	if rec.Code != http.StatusServiceUnavailable || rec.Header().Get("Retry-After") != "5" {
+		t.Fatalf("expected 503 with Retry-After, got %d %q", rec.Code, rec.Header().Get("Retry-After"))
+	}
+	var resp struct {
+		Error struct {
+			Code int `json:"code"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil || resp.Error.Code != drainErrorCode {
+		t.Fatalf("expected a draining JSON-RPC error, got %s (%v)", rec.Body.String(), err)
+	}
+	if rec := call("/mcp", "ping"); rec.Code != http.StatusOK {
+		t.Fatalf("expected other requests to pass while draining, got %d", rec.Code)
+	}
+
+	select {
+	case <-drained:
+		t.Fatal("drain finished while a call was still in flight")
+	default:
+	}
+	close(release)
+	if code := <-inflight; code != http.StatusOK {
+		t.Fatalf("expected the in-flight call to finish, got %d", code)
+	}
+	if !<-drained {
+		t.Fatal("expected the drain to report every call finished")
+	}
+}
+
+func TestDrainTimesOut(t *testing.T) {
+	d := &drainer{}
+	if !d.begin() {
+		t.Fatal("expected a call to start before draining")
+	}
+	defer d.inflight.Done()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if d.Drain(ctx) {
+		t.Fatal("expected the drain to time out")
+	}
+	if d.begin() {
+		t.Fatal("expected calls to be refused after draining")
+	}
+}
+
+func TestDrainerRefusesWholeBatches(t *testing.T) {
+	d := &drainer{}
+	h := d.wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	d.Drain(context.Background())
+
+	batch := `[{"jsonrpc":"2.0","id":1,"method":"tools/list"},{"jsonrpc":"2.0","method":"notifications/progress"},{"jsonrpc":"2.0","id":2,"method":"tools/call"},{"jsonrpc":"2.0","id":3,"method":"tools/call"}]`
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(batch)))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503, got %d", rec.Code)
+	}
+	var resps []struct {
+		ID    float64 `json:"id"`
+		Error struct {
+			Code int `json:"code"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resps); err != nil {
+		t.Fatalf("expected a batch of errors, got %s (%v)", rec.Body.String(), err)
+	}
+	if len(resps) != 3 {
+		t.Fatalf("expected every request in the batch refused, got %s", rec.Body.String())
+	}
+	for i, resp := range resps {
+		if resp.ID != float64(i+1) || resp.Error.Code != drainErrorCode {
+			t.Fatalf("expected request %d refused as draining, got %+v", i+1, resp)
+		}
+	}
+}
+
+func TestDrainerCapsTheBody(t *testing.T) {
+	d := &drainer{}
+	h := d.wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	rec := httptest.NewRecorder()
+	body := strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"x":"` + strings.Repeat("a", drainMaxBytes) + `"}}`)
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/mcp", body))
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected 413 for an oversized body, got %d", rec.Code)
+	}
+}
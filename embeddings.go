@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/UnitVectorY-Labs/mcp-shopping-list-firestore/pkg/shoppinglist"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// -----------------------------------------------------------------------------
+// Embeddings
+// -----------------------------------------------------------------------------
+
+func registerSimilarityTools(srv *server.MCPServer, service *shoppinglist.Service, embedder shoppinglist.Embedder) {
+	// find_similar_items
+	findSimilarItemsTool := mcp.NewTool(
+		"find_similar_items",
+		mcp.WithDescription("Find items already on the shopping list that are semantically similar to a name, to avoid adding duplicates."),
+		mcp.WithTitleAnnotation("Find Similar Shopping Items"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("name", mcp.Description("Name to compare against the list"), mcp.Required()),
+		mcp.WithNumber("threshold", mcp.Description("Minimum similarity score between 0 and 1 (optional, defaults to 0.8)")),
+		listArg,
+	)
+	srv.AddTool(findSimilarItemsTool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := req.GetArguments()
+
+		// Extract required name field
+		name, ok := args["name"].(string)
+		if !ok || name == "" {
+			return mcp.NewToolResultError("invalid or missing 'name'"), nil
+		}
+
+		// Extract optional threshold field
+		threshold := 0.8
+		if t, ok := args["threshold"].(float64); ok {
+			if t < 0 || t > 1 {
+				return mcp.NewToolResultError("'threshold' must be between 0 and 1"), nil
+			}
+			threshold = t
+		}
+
+		toolCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
+		defer cancel()
+
+		svc, err := listFromArgs(toolCtx, service, args)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to resolve list: %v", err)), nil
+		}
+
+		items, err := svc.ListItems(toolCtx)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to list items: %v", err)), nil
+		}
+		matches, err := shoppinglist.FindSimilarItems(toolCtx, embedder, name, items, threshold)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to compare items: %v", err)), nil
+		}
+		return jsonResult(shoppinglist.SimilarItemsResponse{Provider: embedder.Name(), Matches: matches})
+	})
+}
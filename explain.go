@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"maps"
+
+	"github.com/UnitVectorY-Labs/mcp-shopping-list-firestore/pkg/shoppinglist"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// -----------------------------------------------------------------------------
+// Explain mode
+// -----------------------------------------------------------------------------
+
+// explainOffline are tools whose effects lie outside Firestore, where
+// explain mode cannot hold them back, with what they would change.
+var explainOffline = map[string]string{
+	"set_preferences":   "it changes this session's preferences, which are held in memory",
+	"delete_attachment": "it deletes the attachment from Cloud Storage",
+}
+
+// explainArg is the argument every tool takes to be explained instead of run.
+var explainArg = map[string]any{
+	"type":        "boolean",
+	"description": "Instead of running the call, report the documents it reads, the filters its queries apply, the writes it would make, and any validation it would fail, without changing anything (optional)",
+}
+
+// addExplainArg adds the explain argument to every registered tool.
+func addExplainArg(srv *server.MCPServer) {
+	for _, st := range srv.ListTools() {
+		tool := st.Tool
+		tool.InputSchema.Properties = maps.Clone(tool.InputSchema.Properties)
+		if tool.InputSchema.Properties == nil {
+			tool.InputSchema.Properties = map[string]any{}
+		}
+		tool.InputSchema.Properties["explain"] = explainArg
+		srv.AddTool(tool, st.Handler)
+	}
+}
+
+// explaining reports whether args ask for the call to be explained.
+func explaining(args map[string]any) bool {
+	explain, _ := args["explain"].(bool)
+	return explain
+}
+
+// withExplain runs calls made with explain against a context that records
+// their Firestore reads and holds back their writes, and returns what was
+// recorded in place of the result. Reads are made, so the explanation shows
+// the documents the call would act on and the writes it would make to them;
+// the call stops at its first write, so writes that would follow it, such as
+// activity entries, are not shown.
+func withExplain(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if !explaining(req.GetArguments()) {
+			return next(ctx, req)
+		}
+		resp := shoppinglist.ExplainResponse{Tool: req.Params.Name, Reads: []shoppinglist.ExplainedRead{}, Writes: []shoppinglist.ExplainedWrite{}}
+		if reason, ok := explainOffline[req.Params.Name]; ok {
+			resp.NotRun = "explain cannot hold this call back: " + reason
+			return structuredResult(resp)
+		}
+
+		ctx, x := shoppinglist.WithExplain(ctx)
+		ctx, tally := shoppinglist.WithOperationTally(ctx)
+		res, err := next(ctx, req)
+		resp.DocumentsRead = tally.Counts().Reads
+		resp.Reads, resp.Writes = x.Reads(), x.Writes()
+		switch {
+		case x.Stopped():
+		case err != nil:
+			resp.WouldFail = err.Error()
+		case res != nil && res.IsError:
+			resp.WouldFail = resultText(res)
+		}
+		return structuredResult(resp)
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/UnitVectorY-Labs/mcp-shopping-list-firestore/pkg/shoppinglist"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func TestAddExplainArg(t *testing.T) {
+	srv := server.NewMCPServer("test", "0")
+	srv.AddTool(mcp.NewTool("ping"), func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("pong"), nil
+	})
+	srv.AddTool(mcp.NewTool("echo", mcp.WithString("text")), func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("echo"), nil
+	})
+	addExplainArg(srv)
+	for _, name := range []string{"ping", "echo"} {
+		tool := srv.GetTool(name)
+		if _, ok := tool.Tool.InputSchema.Properties["explain"]; !ok || tool.Handler == nil {
+			t.Fatalf("expected %s to take explain", name)
+		}
+	}
+	if _, ok := srv.GetTool("echo").Tool.InputSchema.Properties["text"]; !ok {
+		t.Fatal("expected the other arguments to be kept")
+	}
+}
+
+func TestWithExplain(t *testing.T) {
+	ran := 0
+	handler := withExplain(func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ran++
+		return mcp.NewToolResultError("name must not be empty"), nil
+	})
+	call := func(name string, args map[string]any) shoppinglist.ExplainResponse {
+		t.Helper()
+		req := mcp.CallToolRequest{}
+		req.Params.Name, req.Params.Arguments = name, args
+		res, err := handler(context.Background(), req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var resp shoppinglist.ExplainResponse
+		if err := json.Unmarshal([]byte(resultText(res)), &resp); err != nil {
+			t.Fatalf("expected an explanation, got %q", resultText(res))
+		}
+		return resp
+	}
+
+	if resp := call("upsert_item", map[string]any{"explain": true}); resp.Tool != "upsert_item" || resp.WouldFail != "name must not be empty" || ran != 1 {
+		t.Fatalf("expected the validation failure to be explained, got %+v", resp)
+	}
+	if resp := call("set_preferences", map[string]any{"explain": true}); resp.NotRun == "" || ran != 1 {
+		t.Fatalf("expected set_preferences not to run, got %+v", resp)
+	}
+
+	req := mcp.CallToolRequest{}
+	req.Params.Name = "upsert_item"
+	if res, _ := handler(context.Background(), req); !res.IsError || ran != 2 {
+		t.Fatal("expected calls without explain to run as usual")
+	}
+}
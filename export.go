@@ -0,0 +1,219 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/UnitVectorY-Labs/mcp-shopping-list-firestore/pkg/shoppinglist"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// -----------------------------------------------------------------------------
+// Exports
+// -----------------------------------------------------------------------------
+
+// exportFormats maps supported export formats to their MIME types.
+var exportFormats = map[string]string{
+	"csv":      "text/csv",
+	"json":     "application/json",
+	"markdown": "text/markdown",
+}
+
+// exportsPath is where exports are downloaded from in HTTP mode.
+const exportsPath = "/exports/"
+
+// exportChunk is how much of an export is written before it is flushed to
+// an HTTP client.
+const exportChunk = 32 << 10
+
+// errExportTooLarge stops an export that will not fit inline.
+var errExportTooLarge = errors.New("export is larger than the inline limit")
+
+// cappedBuffer collects an export until it grows past limit.
+type cappedBuffer struct {
+	b     strings.Builder
+	limit int
+}
+
+func (c *cappedBuffer) Write(p []byte) (int, error) {
+	if c.b.Len()+len(p) > c.limit {
+		return 0, errExportTooLarge
+	}
+	return c.b.Write(p)
+}
+
+func (c *cappedBuffer) String() string { return c.b.String() }
+
+// flushWriter flushes an HTTP response after every write, so each chunk of an
+// export reaches the client as it is written.
+type flushWriter struct {
+	w  io.Writer
+	rc *http.ResponseController
+}
+
+func (f flushWriter) Write(p []byte) (int, error) {
+	n, err := f.w.Write(p)
+	if err == nil {
+		err = f.rc.Flush()
+	}
+	return n, err
+}
+
+// exportHandler streams stored exports over chunked HTTP: the export is
+// written as Firestore returns its items and flushed every exportChunk bytes,
+// so a slow client slows the read rather than the server buffering it.
+func exportHandler(service *shoppinglist.Service, store *shoppinglist.ExportStore) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		exp, ok := store.Get(shoppinglist.ExportURIPrefix+strings.TrimPrefix(r.URL.Path, exportsPath), service.Now())
+		if !ok {
+			http.Error(w, "export not found or expired", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", exp.MimeType)
+		buf := bufio.NewWriterSize(flushWriter{w: w, rc: http.NewResponseController(w)}, exportChunk)
+		err := exp.Write(r.Context(), buf)
+		if err == nil {
+			err = buf.Flush()
+		}
+		if err != nil {
+			// The status is sent with the first chunk, so the export is cut
+			// short instead.
+			log.Printf("warn: stream export %s: %v", r.URL.Path, err)
+		}
+	})
+}
+
+// runExport writes a list to w as it is read, e.g. `export --format csv`, for
+// lists too large to hold in memory.
+func runExport(ctx context.Context, service *shoppinglist.Service, args []string, w io.Writer) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	format := fs.String("format", "json", "export format: csv, json, or markdown")
+	list := fs.String("list", "", "list ID or slug (defaults to the main list)")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("parse export flags: %w", err)
+	}
+	if _, ok := exportFormats[*format]; !ok {
+		return fmt.Errorf("unsupported format %q (expected csv, json, or markdown)", *format)
+	}
+	svc := service
+	if *list != "" {
+		info, err := service.ResolveList(ctx, *list)
+		if err != nil {
+			return err
+		}
+		if svc, err = service.ForList(info); err != nil {
+			return err
+		}
+	}
+	buf := bufio.NewWriterSize(w, exportChunk)
+	if _, _, err := svc.StreamExport(ctx, buf, *format, time.Time{}); err != nil {
+		return err
+	}
+	if err := buf.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintln(w)
+	return err
+}
+
+func registerExportTools(srv *server.MCPServer, service *shoppinglist.Service, store *shoppinglist.ExportStore, inlineLimit int, overHTTP bool) {
+	srv.AddResourceTemplate(
+		mcp.NewResourceTemplate(
+			shoppinglist.ExportURIPrefix+"{id}",
+			"Shopping list export",
+			mcp.WithTemplateDescription("A shopping list export produced by export_list, as the list was when exported, available for one hour."),
+		),
+		func(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+			exp, ok := store.Get(req.Params.URI, service.Now())
+			if !ok {
+				return nil, fmt.Errorf("export %q not found or expired", req.Params.URI)
+			}
+			// A resource is read in one message, so the export is held in
+			// memory for the read; HTTP downloads are streamed instead.
+			var b strings.Builder
+			if err := exp.Write(ctx, &b); err != nil {
+				return nil, err
+			}
+			return []mcp.ResourceContents{
+				mcp.TextResourceContents{URI: req.Params.URI, MIMEType: exp.MimeType, Text: b.String()},
+			}, nil
+		},
+	)
+
+	// export_list
+	exportListTool := mcp.NewTool(
+		"export_list",
+		mcp.WithDescription("Export the shopping list as CSV, JSON, or Markdown. Small exports are returned inline; large ones are returned as a link to a temporary resource."),
+		mcp.WithTitleAnnotation("Export Shopping List"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("format", mcp.Description("Export format (optional, defaults to json)"), mcp.Enum(shoppinglist.ExportFormats...)),
+		listArg,
+	)
+	srv.AddTool(exportListTool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := req.GetArguments()
+
+		// Extract optional format field
+		format := "json"
+		if f, ok := args["format"].(string); ok && f != "" {
+			format = f
+		}
+		mimeType, ok := exportFormats[format]
+		if !ok {
+			return mcp.NewToolResultError(fmt.Sprintf("unsupported format %q (expected csv, json, or markdown)", format)), nil
+		}
+
+		toolCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		defer cancel()
+
+		svc, err := listFromArgs(toolCtx, service, args)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to resolve list: %v", err)), nil
+		}
+
+		// Stream the export inline until it outgrows the limit; past that
+		// only the read time is kept, so the link exports the same data.
+		buf := &cappedBuffer{limit: inlineLimit}
+		_, readAt, err := svc.StreamExport(toolCtx, buf, format, time.Time{})
+		if err == nil {
+			return mcp.NewToolResultText(buf.String()), nil
+		}
+		if !errors.Is(err, errExportTooLarge) {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to export items: %v", err)), nil
+		}
+
+		// An explained call only reports the reads; no export is kept.
+		if explaining(args) {
+			return mcp.NewToolResultText(fmt.Sprintf("The %s export is larger than %d bytes.", format, inlineLimit)), nil
+		}
+
+		uri := store.Put(func(ctx context.Context, w io.Writer) error {
+			_, _, err := svc.StreamExport(ctx, w, format, readAt)
+			return err
+		}, mimeType, service.Now())
+		text := fmt.Sprintf("The %s export is larger than %d bytes; read the linked resource within the hour to download it.", format, inlineLimit)
+		if overHTTP {
+			text += fmt.Sprintf(" It can also be streamed with GET %s%s.", exportsPath, strings.TrimPrefix(uri, shoppinglist.ExportURIPrefix))
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.NewTextContent(text),
+				mcp.NewResourceLink(uri, "shopping-list."+format, fmt.Sprintf("Shopping list export (%s)", format), mimeType),
+			},
+		}, nil
+	})
+}
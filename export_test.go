@@ -0,0 +1,20 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestCappedBufferStopsPastLimit(t *testing.T) {
+	buf := &cappedBuffer{limit: 8}
+	if _, err := io.WriteString(buf, "12345"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.WriteString(buf, "6789"); !errors.Is(err, errExportTooLarge) {
+		t.Fatalf("expected errExportTooLarge, got %v", err)
+	}
+	if buf.String() != "12345" {
+		t.Fatalf("expected the writes that fit, got %q", buf.String())
+	}
+}
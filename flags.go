@@ -0,0 +1,201 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/UnitVectorY-Labs/mcp-shopping-list-firestore/pkg/shoppinglist"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// -----------------------------------------------------------------------------
+// Feature flags
+// -----------------------------------------------------------------------------
+
+// featureDisabledCode is the tool error code of a call to a tool its feature
+// flag has not been rolled out to.
+const featureDisabledCode = "feature_disabled"
+
+// flagTools manage feature flags and so cannot be gated by one.
+var flagTools = map[string]bool{"list_feature_flags": true, "set_feature_flag": true}
+
+// tenantOf is the tenant feature flags are rolled out to for ctx: the name of
+// its API token, or none.
+func tenantOf(ctx context.Context) string {
+	if tok := tokenFromContext(ctx); tok != nil {
+		return tok.Name
+	}
+	return ""
+}
+
+// withFeatureFlags refuses calls to tools gated by a feature flag that is not
+// on for the call's list and tenant.
+func withFeatureFlags(service *shoppinglist.Service) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			flags := service.Flags().ForTool(req.Params.Name)
+			if len(flags) == 0 {
+				return next(ctx, req)
+			}
+			list := service.DefaultList()
+			if ref, _ := req.GetArguments()["list"].(string); ref != "" {
+				var err error
+				if list, err = service.ResolveList(ctx, ref); err != nil {
+					return mcp.NewToolResultError(fmt.Sprintf("failed to resolve list: %v", err)), nil
+				}
+			}
+			tenant := tenantOf(ctx)
+			for _, f := range flags {
+				if !f.EnabledFor(list, tenant) {
+					msg := fmt.Sprintf("%s is not enabled on list %q yet (feature flag %q)", req.Params.Name, list.Slug, f.Name)
+					return errorResult(toolError{Code: featureDisabledCode, Error: msg}), nil
+				}
+			}
+			return next(ctx, req)
+		}
+	}
+}
+
+// featureToolFilter leaves tools out of tools/list while a flag gating them
+// is dormant for the caller's tenant, so agents are not offered them.
+func featureToolFilter(service *shoppinglist.Service) server.ToolFilterFunc {
+	return func(ctx context.Context, tools []mcp.Tool) []mcp.Tool {
+		tenant := tenantOf(ctx)
+		out := make([]mcp.Tool, 0, len(tools))
+	next:
+		for _, t := range tools {
+			for _, f := range service.Flags().ForTool(t.Name) {
+				if f.Dormant(tenant) {
+					continue next
+				}
+			}
+			out = append(out, t)
+		}
+		return out
+	}
+}
+
+// stringsArg reads an optional array of strings argument.
+func stringsArg(args map[string]any, name string) ([]string, error) {
+	raw, ok := args[name]
+	if !ok || raw == nil {
+		return nil, nil
+	}
+	items, ok := raw.([]any)
+	if !ok {
+		return nil, fmt.Errorf("'%s' must be an array of strings", name)
+	}
+	out := make([]string, 0, len(items))
+	for _, it := range items {
+		s, ok := it.(string)
+		if !ok || strings.TrimSpace(s) == "" {
+			return nil, fmt.Errorf("'%s' must be an array of non-empty strings", name)
+		}
+		out = append(out, strings.TrimSpace(s))
+	}
+	return out, nil
+}
+
+func registerFlagTools(srv *server.MCPServer, service *shoppinglist.Service) {
+	// list_feature_flags
+	listFlagsTool := mcp.NewTool(
+		"list_feature_flags",
+		mcp.WithDescription("Show the feature flags gating tools on this server, with the lists, tenants (API token names), and percentage each is rolled out to. Requires an owner token."),
+		mcp.WithTitleAnnotation("List Feature Flags"),
+		mcp.WithReadOnlyHintAnnotation(true),
+	)
+	srv.AddTool(listFlagsTool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		toolCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+
+		if err := service.LoadFlags(toolCtx); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to list feature flags: %v", err)), nil
+		}
+		return jsonResult(shoppinglist.FlagsResponse{Flags: service.Flags().All()})
+	})
+
+	// set_feature_flag
+	setFlagTool := mcp.NewTool(
+		"set_feature_flag",
+		mcp.WithDescription("Create or replace a feature flag that gates tools, so a new capability can be rolled out to some lists or tenants first, then a percentage of the rest, then everyone. Calls to a gated tool where its flag is off fail with code 'feature_disabled'. Requires an owner token."),
+		mcp.WithTitleAnnotation("Set Feature Flag"),
+		mcp.WithIdempotentHintAnnotation(true),
+		mcp.WithString("name", mcp.Description("Name of the flag, e.g. 'changes-feed'"), mcp.Required()),
+		mcp.WithString("description", mcp.Description("What the flag rolls out (optional)")),
+		mcp.WithArray("tools", mcp.Description("Tools the flag gates, e.g. [\"get_changes\"] (optional)"), mcp.WithStringItems()),
+		mcp.WithBoolean("enabled", mcp.Description("On everywhere, completing the rollout (optional, defaults to false)")),
+		mcp.WithArray("lists", mcp.Description("IDs or slugs of lists the flag is on for (optional)"), mcp.WithStringItems()),
+		mcp.WithArray("tenants", mcp.Description("Names of API tokens the flag is on for, e.g. [\"beta household\"] (optional)"), mcp.WithStringItems()),
+		mcp.WithNumber("percent", mcp.Description("Percentage of the other list and tenant pairs the flag is on for, from 0 to 100 (optional)")),
+		mcp.WithBoolean("delete", mcp.Description("Delete the flag instead, ungating its tools (optional)")),
+	)
+	srv.AddTool(setFlagTool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := req.GetArguments()
+
+		// Extract required name field
+		name, _ := args["name"].(string)
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return mcp.NewToolResultError("invalid or missing 'name'"), nil
+		}
+
+		toolCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+
+		// Extract optional delete field
+		if remove, _ := args["delete"].(bool); remove {
+			if err := service.DeleteFlag(toolCtx, name); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			_ = service.LoadFlags(toolCtx)
+			return jsonResult(shoppinglist.FlagResponse{Deleted: name})
+		}
+
+		flag := shoppinglist.FeatureFlag{Name: name}
+		flag.Description, _ = args["description"].(string)
+		flag.Enabled, _ = args["enabled"].(bool)
+		if v, ok := args["percent"].(float64); ok {
+			flag.Percent = int(v)
+		}
+
+		// Extract optional tools, lists, and tenants fields
+		var err error
+		if flag.Tools, err = stringsArg(args, "tools"); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		for _, t := range flag.Tools {
+			switch {
+			case flagTools[t]:
+				return mcp.NewToolResultError(fmt.Sprintf("%s manages feature flags and cannot be gated", t)), nil
+			case srv.GetTool(t) == nil:
+				return mcp.NewToolResultError(fmt.Sprintf("unknown tool %q", t)), nil
+			}
+		}
+		refs, err := stringsArg(args, "lists")
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		for _, ref := range refs {
+			list, err := service.ResolveList(toolCtx, ref)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("failed to resolve list: %v", err)), nil
+			}
+			flag.Lists = append(flag.Lists, list.ID)
+		}
+		if flag.Tenants, err = stringsArg(args, "tenants"); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		flag, err = service.SetFlag(toolCtx, flag)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		// The listener picks the change up too; loading now makes it take
+		// effect on this instance at once, and in one-shot calls.
+		_ = service.LoadFlags(toolCtx)
+		return jsonResult(shoppinglist.FlagResponse{Flag: &flag})
+	})
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/UnitVectorY-Labs/mcp-shopping-list-firestore/pkg/shoppinglist"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// -----------------------------------------------------------------------------
+// List freeze
+// -----------------------------------------------------------------------------
+
+func registerFreezeTools(srv *server.MCPServer, service *shoppinglist.Service) {
+	// freeze_list
+	freezeListTool := mcp.NewTool(
+		"freeze_list",
+		mcp.WithDescription("Mark the shopping list as final so no new items can be added until the trip is complete (unfreeze_list) or the freeze expires."),
+		mcp.WithTitleAnnotation("Freeze Shopping List"),
+		mcp.WithNumber("duration_minutes", mcp.Description("How long the freeze lasts in minutes (optional, defaults to 120)")),
+		mcp.WithString("reason", mcp.Description("Why the list is frozen, shown to anyone trying to add items (optional)")),
+		listArg,
+	)
+	srv.AddTool(freezeListTool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := req.GetArguments()
+
+		// Extract optional duration_minutes field
+		duration := 120 * time.Minute
+		if minutes, ok := args["duration_minutes"].(float64); ok {
+			if minutes <= 0 {
+				return mcp.NewToolResultError("'duration_minutes' must be positive"), nil
+			}
+			duration = time.Duration(minutes * float64(time.Minute))
+		}
+
+		// Extract optional reason field
+		reason, _ := args["reason"].(string)
+
+		toolCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
+		defer cancel()
+
+		svc, err := listFromArgs(toolCtx, service, args)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to resolve list: %v", err)), nil
+		}
+
+		freeze, err := svc.FreezeList(toolCtx, service.Now().Add(duration), reason)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to freeze list: %v", err)), nil
+		}
+		return jsonResult(shoppinglist.FreezeStatusResponse{Frozen: true, Freeze: freeze})
+	})
+
+	// unfreeze_list
+	unfreezeListTool := mcp.NewTool(
+		"unfreeze_list",
+		mcp.WithDescription("Lift the freeze on the shopping list, typically once the shopping trip is complete."),
+		mcp.WithTitleAnnotation("Unfreeze Shopping List"),
+		mcp.WithIdempotentHintAnnotation(true),
+		listArg,
+	)
+	srv.AddTool(unfreezeListTool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := req.GetArguments()
+
+		toolCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
+		defer cancel()
+
+		svc, err := listFromArgs(toolCtx, service, args)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to resolve list: %v", err)), nil
+		}
+
+		if err := svc.UnfreezeList(toolCtx); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to unfreeze list: %v", err)), nil
+		}
+		return jsonResult(shoppinglist.FreezeStatusResponse{Frozen: false})
+	})
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/UnitVectorY-Labs/mcp-shopping-list-firestore/pkg/shoppinglist"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// -----------------------------------------------------------------------------
+// Resumable imports
+// -----------------------------------------------------------------------------
+
+// importTimeout bounds one import_items call; a longer import fails partway
+// and is resumed by calling again.
+const importTimeout = 2 * time.Minute
+
+// importProgressNotifier sends an MCP progress notification after each
+// committed chunk when the caller asked for progress, or returns nil.
+func importProgressNotifier(ctx context.Context, req mcp.CallToolRequest) shoppinglist.ImportProgressFunc {
+	if req.Params.Meta == nil || req.Params.Meta.ProgressToken == nil {
+		return nil
+	}
+	srv := server.ServerFromContext(ctx)
+	if srv == nil {
+		return nil
+	}
+	token := req.Params.Meta.ProgressToken
+	return func(p shoppinglist.ImportProgress) {
+		err := srv.SendNotificationToClient(ctx, "notifications/progress", map[string]any{
+			"progressToken": token,
+			"progress":      p.Committed,
+			"total":         p.Total,
+			"message":       fmt.Sprintf("imported %d of %d items", p.Committed, p.Total),
+		})
+		if err != nil {
+			log.Printf("warn: import %s progress: %v", p.ID, err)
+		}
+	}
+}
+
+func registerImportTools(srv *server.MCPServer, service *shoppinglist.Service, currency string) {
+	// import_items
+	importItemsTool := mcp.NewTool(
+		"import_items",
+		mcp.WithDescription("Import a large set of new items, e.g. hundreds from another app. Items are written in chunks within Firestore's commit limits, with progress saved after each chunk and reported as progress notifications. If the import fails partway, call again with the returned import_id and the same items to resume from the last committed chunk; nothing is added twice."),
+		mcp.WithTitleAnnotation("Import Shopping Items"),
+		mcp.WithArray("items",
+			mcp.Description("Items to import, in order"),
+			mcp.Required(),
+			mcp.Items(itemInputSchema(currency)),
+		),
+		mcp.WithString("import_id", mcp.Description("ID of an earlier import to resume (optional, starts a new import when omitted)")),
+		listArg,
+	)
+	srv.AddTool(importItemsTool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := req.GetArguments()
+
+		// Extract required items field
+		var warnings shoppinglist.ResponseWarnings
+		inputs, err := bulkItemsFromArgs(args, currency, &warnings)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		// Extract optional import_id field
+		importID, _ := args["import_id"].(string)
+
+		toolCtx, cancel := context.WithTimeout(ctx, importTimeout)
+		defer cancel()
+
+		svc, err := listFromArgs(toolCtx, service, args)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to resolve list: %v", err)), nil
+		}
+
+		progress, err := svc.ImportItems(toolCtx, importID, inputs, importProgressNotifier(ctx, req))
+		if err != nil {
+			var frozen *shoppinglist.ListFrozenError
+			if errors.As(err, &frozen) {
+				return mcp.NewToolResultError(frozen.Error()), nil
+			}
+			if progress.ID != "" && progress.Status == shoppinglist.ImportFailed {
+				return mcp.NewToolResultError(fmt.Sprintf("failed to import items: %v; %d of %d were committed, call again with import_id %q and the same items to resume", err, progress.Committed, progress.Total, progress.ID)), nil
+			}
+			return mcp.NewToolResultError(fmt.Sprintf("failed to import items: %v", err)), nil
+		}
+		return jsonResult(shoppinglist.ImportResponse{Import: progress, ResponseWarnings: warnings})
+	})
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/UnitVectorY-Labs/mcp-shopping-list-firestore/pkg/shoppinglist"
+)
+
+// -----------------------------------------------------------------------------
+// Inbound webhook
+// -----------------------------------------------------------------------------
+
+// inboundAuthorized checks the token given as a bearer token, a basic auth
+// password (which gateways can embed in the webhook URL), or a token query
+// parameter.
+func inboundAuthorized(r *http.Request, token string) bool {
+	var given string
+	if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+		given = strings.TrimPrefix(auth, "Bearer ")
+	} else if _, password, ok := r.BasicAuth(); ok {
+		given = password
+	} else {
+		given = r.URL.Query().Get("token")
+	}
+	return given != "" && subtle.ConstantTimeCompare([]byte(given), []byte(token)) == 1
+}
+
+// inboundHandler serves /inbound: an authenticated POST whose message, as
+// JSON, a form, or plain text, is parsed into items added to the list.
+func inboundHandler(service *shoppinglist.Service, token *secret) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			writeInboundError(w, http.StatusMethodNotAllowed, "use POST")
+			return
+		}
+		if !inboundAuthorized(r, token.Value()) {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="inbound"`)
+			writeInboundError(w, http.StatusUnauthorized, "missing or invalid token")
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, shoppinglist.InboundMaxBytes)
+
+		items, ref, err := shoppinglist.ReadInbound(r, service.Profile())
+		if err != nil {
+			writeInboundError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if len(items) == 0 {
+			writeInboundError(w, http.StatusUnprocessableEntity, "no items found in the message")
+			return
+		}
+		ref = firstNonEmpty(ref, r.URL.Query().Get("list"))
+
+		ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+		defer cancel()
+
+		list := service.DefaultList()
+		svc, err := service.Main()
+		if ref != "" {
+			if list, err = service.ResolveList(ctx, ref); err != nil {
+				writeInboundError(w, http.StatusNotFound, fmt.Sprintf("failed to resolve list: %v", err))
+				return
+			}
+			svc, err = service.ForList(list)
+		}
+		if err != nil {
+			writeInboundError(w, http.StatusInternalServerError, fmt.Sprintf("failed to open list: %v", err))
+			return
+		}
+		if service.Moving(list.ID) {
+			w.Header().Set("Retry-After", strconv.Itoa(listMovingRetryAfter))
+			writeInboundError(w, http.StatusServiceUnavailable, "the list is being moved to another collection; retry shortly")
+			return
+		}
+		res, err := svc.AddInbound(ctx, items)
+		if err != nil {
+			var frozen *shoppinglist.ListFrozenError
+			if errors.As(err, &frozen) {
+				writeInboundError(w, http.StatusConflict, err.Error())
+				return
+			}
+			var violation *shoppinglist.RuleViolationError
+			if errors.As(err, &violation) {
+				writeInboundError(w, http.StatusUnprocessableEntity, violation.Error())
+				return
+			}
+			log.Printf("warn: inbound: %v", err)
+			writeInboundError(w, http.StatusInternalServerError, "failed to add items")
+			return
+		}
+		res.List = list.ID
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(res)
+	})
+}
+
+// writeInboundError replies with a JSON error.
+func writeInboundError(w http.ResponseWriter, code int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": msg})
+}
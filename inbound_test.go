@@ -0,0 +1,53 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/UnitVectorY-Labs/mcp-shopping-list-firestore/pkg/shoppinglist"
+)
+
+func TestInboundAuthorized(t *testing.T) {
+	bearer := httptest.NewRequest(http.MethodPost, "/inbound", nil)
+	bearer.Header.Set("Authorization", "Bearer s3cret")
+	basic := httptest.NewRequest(http.MethodPost, "/inbound", nil)
+	basic.SetBasicAuth("gateway", "s3cret")
+	query := httptest.NewRequest(http.MethodPost, "/inbound?token=s3cret", nil)
+	wrong := httptest.NewRequest(http.MethodPost, "/inbound?token=guess", nil)
+	none := httptest.NewRequest(http.MethodPost, "/inbound", nil)
+
+	for name, r := range map[string]*http.Request{"bearer": bearer, "basic": basic, "query": query} {
+		if !inboundAuthorized(r, "s3cret") {
+			t.Errorf("%s token rejected", name)
+		}
+	}
+	for name, r := range map[string]*http.Request{"wrong": wrong, "none": none} {
+		if inboundAuthorized(r, "s3cret") {
+			t.Errorf("%s token accepted", name)
+		}
+	}
+}
+
+func TestInboundHandlerRejects(t *testing.T) {
+	h := inboundHandler(&shoppinglist.Service{}, plainSecret("s3cret"))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/inbound?token=s3cret", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET status = %d, want 405", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/inbound", strings.NewReader("milk")))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("unauthenticated status = %d, want 401", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/inbound?token=s3cret", strings.NewReader("> add cake\n")))
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("empty message status = %d, want 422", rec.Code)
+	}
+}
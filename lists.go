@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/UnitVectorY-Labs/mcp-shopping-list-firestore/pkg/shoppinglist"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// -----------------------------------------------------------------------------
+// Lists
+// -----------------------------------------------------------------------------
+
+// listArg is the optional list selector shared by item tools.
+var listArg = mcp.WithString("list", mcp.Description("ID, slug, or former slug of the list (optional, defaults to the main list)"))
+
+// listFromArgs resolves the optional 'list' argument to a service scoped to that list.
+// Tools generated for an extra collection have no 'list' argument and run on
+// that collection instead.
+func listFromArgs(ctx context.Context, service *shoppinglist.Service, args map[string]any) (*shoppinglist.Service, error) {
+	if svc := collectionFromContext(ctx); svc != nil {
+		return svc, nil
+	}
+	ref, _ := args["list"].(string)
+	if ref == "" {
+		return service.Main()
+	}
+	list, err := service.ResolveList(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	return service.ForList(list)
+}
+
+func registerListTools(srv *server.MCPServer, service *shoppinglist.Service) {
+	// list_lists
+	listListsTool := mcp.NewTool(
+		"list_lists",
+		mcp.WithDescription("Retrieve all shopping lists with their stable IDs, slugs, and former slugs."),
+		mcp.WithTitleAnnotation("List Shopping Lists"),
+		mcp.WithReadOnlyHintAnnotation(true),
+	)
+	srv.AddTool(listListsTool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		toolCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+
+		lists, err := service.Lists(toolCtx)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to list lists: %v", err)), nil
+		}
+		return jsonResult(shoppinglist.ListsResponse{Lists: lists})
+	})
+
+	// create_list
+	createListTool := mcp.NewTool(
+		"create_list",
+		mcp.WithDescription("Create a new shopping list. Its ID never changes; its slug is derived from the name."),
+		mcp.WithTitleAnnotation("Create Shopping List"),
+		mcp.WithString("name", mcp.Description("Name of the list"), mcp.Required()),
+	)
+	srv.AddTool(createListTool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := req.GetArguments()
+
+		// Extract required name field
+		name, ok := args["name"].(string)
+		if !ok || strings.TrimSpace(name) == "" {
+			return mcp.NewToolResultError("invalid or missing 'name'"), nil
+		}
+
+		toolCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
+		defer cancel()
+
+		list, err := service.CreateList(toolCtx, strings.TrimSpace(name))
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to create list: %v", err)), nil
+		}
+		return jsonResult(shoppinglist.ListResponse{List: list})
+	})
+
+	// rename_list
+	renameListTool := mcp.NewTool(
+		"rename_list",
+		mcp.WithDescription("Rename a shopping list. The ID is unchanged and the previous slug keeps resolving as an alias."),
+		mcp.WithTitleAnnotation("Rename Shopping List"),
+		mcp.WithString("list", mcp.Description("ID, slug, or former slug of the list to rename"), mcp.Required()),
+		mcp.WithString("name", mcp.Description("New name of the list"), mcp.Required()),
+	)
+	srv.AddTool(renameListTool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := req.GetArguments()
+
+		// Extract required fields
+		ref, ok := args["list"].(string)
+		if !ok || ref == "" {
+			return mcp.NewToolResultError("invalid or missing 'list'"), nil
+		}
+		name, ok := args["name"].(string)
+		if !ok || strings.TrimSpace(name) == "" {
+			return mcp.NewToolResultError("invalid or missing 'name'"), nil
+		}
+
+		toolCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
+		defer cancel()
+
+		list, err := service.RenameList(toolCtx, ref, strings.TrimSpace(name))
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to rename list: %v", err)), nil
+		}
+		return jsonResult(shoppinglist.ListResponse{List: list})
+	})
+}
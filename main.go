@@ -1,6 +1,8 @@
 package main
 
 import (
+	"bufio"
+	"cmp"
 	"context"
 	"encoding/json"
 	"errors"
@@ -8,15 +10,19 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"math"
+	"net/http"
 	"os"
+	"os/signal"
 	"regexp"
 	"runtime"
 	"runtime/debug"
+	"slices"
 	"strings"
+	"syscall"
 	"time"
 
-	"cloud.google.com/go/firestore"
-	"github.com/google/uuid"
+	"github.com/UnitVectorY-Labs/mcp-shopping-list-firestore/pkg/shoppinglist"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 	"google.golang.org/api/option"
@@ -41,229 +47,786 @@ func writeVersion(w io.Writer, projectName, version string) error {
 }
 
 // -----------------------------------------------------------------------------
-// Domain types
+// MCP server wiring
 // -----------------------------------------------------------------------------
 
-// Item is a shopping list entry.
-type Item struct {
-	ID        string    `json:"id" firestore:"id"`
-	Name      string    `json:"name" firestore:"name"`
-	Quantity  *string   `json:"quantity,omitempty" firestore:"quantity,omitempty"`
-	CreatedAt time.Time `json:"created_at" firestore:"created_at"`
-}
+func main() {
+	// Set the build version from the build info if not set by the build system
+	if Version == "dev" || Version == "" {
+		if bi, ok := debug.ReadBuildInfo(); ok {
+			if bi.Main.Version != "" && bi.Main.Version != "(devel)" {
+				Version = bi.Main.Version
+			}
+		}
+	}
 
-// ItemInput is the user-facing upsert payload.
-type ItemInput struct {
-	ID       *string `json:"id,omitempty"`
-	Name     string  `json:"name"`
-	Quantity *string `json:"quantity,omitempty"`
-}
+	log.SetFlags(log.LstdFlags | log.Lmsgprefix)
+	log.SetPrefix("mcp-shopping-list-firestore: ")
 
-// ListItemsResponse wraps a list response.
-type ListItemsResponse struct {
-	Items []Item `json:"items"`
-}
+	var (
+		httpAddr            string
+		projectID           string
+		credentialsPath     string
+		defaultCollection   = "shopping"
+		showVersion         bool
+		embeddings          string
+		vertexLocation      string
+		vertexModel         string
+		notifyWebhook       string
+		maxAddsPerMinute    int
+		maxDeletesPerMinute int
+		maintenanceWindow   string
+		exportInlineLimit   int
+		currency            string
+		exchangeRates       string
+		budget              float64
+		staleFallback       time.Duration
+		requireLocation     string
+		normalization       string
+		synonymsPath        string
+		attachmentsBucket   string
+		attachmentMaxBytes  int64
+		rolloverSpec        string
+		rolloverTemplate    string
+		locale              string
+		units               string
+		retentionSpec       string
+		freezeTime          string
+		drainTimeout        time.Duration
+		configPath          string
+		shadowDatabase      string
+		shadowCollection    string
+		shadowSweep         time.Duration
+		rulesPath           string
+		instanceID          string
+		denylistPath        string
+		denylistMode        string
+		itemIDs             string
+		fieldNames          string
+		legacyFields        bool
+		telemetry           bool
+		latencyBudgets      string
+		secretRefresh       time.Duration
+		retryAttempts       int
+		cacheItems          bool
+		returns             string
+	)
 
-// UpsertItemRequest is the tool request for creating/updating a single item.
-type UpsertItemRequest struct {
-	ID       *string `json:"id,omitempty"`
-	Name     string  `json:"name"`
-	Quantity *string `json:"quantity,omitempty"`
-}
+	flag.StringVar(&httpAddr, "http", "", "run Streaming HTTP transport on the given address, e.g. 8080 (defaults to stdio if empty)")
+	flag.StringVar(&credentialsPath, "credentials", "", "path to Google Cloud credentials JSON file (optional; uses default auth if not provided)")
+	flag.BoolVar(&showVersion, "version", false, "print version and exit")
+	flag.StringVar(&embeddings, "embeddings", "local", "embeddings provider for similarity checks: local (offline) or vertex")
+	flag.StringVar(&vertexLocation, "vertex-location", "us-central1", "Vertex AI location used when -embeddings=vertex")
+	flag.StringVar(&vertexModel, "vertex-model", "text-embedding-005", "Vertex AI embeddings model used when -embeddings=vertex")
+	flag.StringVar(&notifyWebhook, "notify-webhook", "", "URL receiving JSON notifications such as anomaly alerts (optional; logs them if empty)")
+	flag.IntVar(&maxAddsPerMinute, "anomaly-max-adds", 100, "items added within one minute before an incident is raised (0 disables)")
+	flag.IntVar(&maxDeletesPerMinute, "anomaly-max-deletes", 25, "items deleted within one minute before an incident is raised (0 disables)")
+	flag.StringVar(&maintenanceWindow, "maintenance-window", "", "daily UTC window, e.g. 02:00-04:00, during which mass deletions are not reported")
+	flag.IntVar(&exportInlineLimit, "export-inline-limit", 16384, "largest export in bytes returned inline; larger exports are returned as resource links")
+	flag.StringVar(&currency, "currency", "USD", "household's preferred ISO 4217 currency for totals and comparisons")
+	flag.StringVar(&exchangeRates, "exchange-rates", "", "exchange rate source: ecb, or static:CODE=RATE,... giving the value of one unit in -currency (optional)")
+	flag.Float64Var(&budget, "budget", 0, "trip budget in -currency; price reports warn when exceeded (0 disables)")
+	flag.DurationVar(&staleFallback, "stale-fallback", 0, "when Firestore reads fail, serve the last list read within this age, e.g. 10m (0 disables)")
+	flag.StringVar(&requireLocation, "require-location", "", "comma-separated Firestore locations the database must be in, e.g. europe-west1 or eur3; startup fails otherwise (optional)")
+	flag.StringVar(&normalization, "normalize", shoppinglist.DefaultNormalization, "name matching pipeline: comma-separated trim, lowercase, strip-emoji, singular[:en|es|fr], synonyms")
+	flag.StringVar(&synonymsPath, "synonyms", "", "file of 'canonical: alias, alias' lines used by the synonyms normalization step (optional)")
+	flag.StringVar(&attachmentsBucket, "attachments-bucket", "", "Cloud Storage bucket for item attachments; enables the attachment tools (optional)")
+	flag.Int64Var(&attachmentMaxBytes, "attachment-max-bytes", 10<<20, "largest attachment accepted, in bytes")
+	flag.StringVar(&rolloverSpec, "rollover", "", "weekly UTC rollover time, e.g. 'sun 18:00', archiving the completed trip and starting a new week (optional)")
+	flag.StringVar(&rolloverTemplate, "rollover-template", "", "JSON file of items added at each rollover when not already on the list (optional)")
+	flag.StringVar(&locale, "locale", "", "household BCP 47 locale for reading and writing quantities, e.g. de-DE for decimal commas (optional)")
+	flag.StringVar(&units, "units", "", "household measurement system, metric or imperial; recipe amounts and unit prices are converted to it (optional)")
+	flag.StringVar(&retentionSpec, "retention", "", "days to keep history, e.g. activity=90,purchases=365,trips=365,incidents=30; older entries are deleted daily (optional)")
+	flag.StringVar(&freezeTime, "freeze-time", "", "test mode: stop the clock at this RFC 3339 time and mint sequential IDs so runs against the emulator are repeatable (optional)")
+	flag.DurationVar(&drainTimeout, "drain-timeout", 30*time.Second, "in HTTP mode, how long a shutdown signal waits for in-flight tool calls while refusing new ones")
+	flag.StringVar(&configPath, "config", defaultConfigPath(), "config file written by `init` with the project, database, and collection; environment variables and flags take precedence")
+	flag.StringVar(&shadowDatabase, "shadow-database", "", "migration target: also copy every write to the lists to this Firestore database (optional)")
+	flag.StringVar(&shadowCollection, "shadow-collection", "", "collection the default list is mirrored to in the shadow target, e.g. shopping_v2 (defaults to the primary collection)")
+	flag.DurationVar(&shadowSweep, "shadow-sweep", 5*time.Minute, "how often every list is compared with the shadow target and the differences written, catching copies that failed")
+	flag.StringVar(&rulesPath, "rules", "", "JSON file of CEL validation and transformation rules applied to item fields on every write (optional)")
+	flag.StringVar(&instanceID, "instance-id", "", "name of this instance when running several; background jobs (rollover, retention, shadow sweep) then run only on the instance holding a lease in Firestore (optional, every instance runs them when empty)")
+	flag.StringVar(&denylistPath, "denylist", "", "file of words or phrases, one per line, blocked in item names, categories, and tags, e.g. for lists children's agents can write to (optional)")
+	flag.StringVar(&denylistMode, "denylist-mode", shoppinglist.FilterReject, "what to do with denylisted words: reject the write, or mask them with asterisks")
+	flag.StringVar(&itemIDs, "item-ids", "random", "how new item IDs are chosen: random, or derived from the list and normalized name (UUIDv5) so repeated adds of a name update one item")
+	flag.StringVar(&fieldNames, "field-names", fieldNamesSnake, "field naming of tool result JSON: snake_case, or camelCase for consumers expecting it")
+	flag.BoolVar(&legacyFields, "legacy-fields", false, "with -field-names camelCase, also keep the snake_case field names so existing consumers keep working")
+	flag.BoolVar(&telemetry, "telemetry", false, "record each tool call's duration, Firestore operations, and result size in <collection>_telemetry for tool_telemetry")
+	flag.StringVar(&latencyBudgets, "latency-budgets", "", "with -telemetry, how long tools may take before calls are flagged, e.g. list_items=500ms,default=2s (optional)")
+	flag.DurationVar(&secretRefresh, "secret-refresh", 0, "how often OWNER_TOKEN and INBOUND_TOKEN given as sm:// Secret Manager references are re-read, e.g. 1h (0 reads them once at startup)")
+	flag.IntVar(&retryAttempts, "retry-attempts", shoppinglist.DefaultRetryPolicy.Attempts, "times a Firestore read or transaction is tried when it fails with Unavailable, DeadlineExceeded, or ResourceExhausted, backing off with jitter between tries (1 disables retries)")
+	flag.StringVar(&returns, "return", returnList, "what upsert_item and remove_item return unless a call says otherwise: list, or item for only the item changed")
+	flag.BoolVar(&cacheItems, "cache-items", false, "serve whole-list reads from an in-memory copy of each list kept current by a Firestore snapshot listener")
+	flag.Parse()
 
-// -----------------------------------------------------------------------------
-// Firestore service
-// -----------------------------------------------------------------------------
+	if showVersion {
+		if err := writeVersion(os.Stdout, "mcp-shopping-list-firestore", Version); err != nil {
+			fatal("write version: %v", err)
+		}
+		return
+	}
 
-// ShoppingListService encapsulates Firestore operations.
-type ShoppingListService struct {
-	client     *firestore.Client
-	database   string
-	collection string
-}
+	configSet := false
+	flag.Visit(func(f *flag.Flag) { configSet = configSet || f.Name == "config" })
+	fileCfg, err := LoadConfig(configPath, configSet)
+	if err != nil {
+		fatal("%v", err)
+	}
+	credentialsPath = firstNonEmpty(credentialsPath, fileCfg.Credentials)
+	defaultCollection = firstNonEmpty(fileCfg.Collection, defaultCollection)
 
-// NewShoppingListService initializes a Firestore client and returns the service.
-func NewShoppingListService(ctx context.Context, projectID, database, collection string, credentialsPath string) (*ShoppingListService, error) {
-	if projectID == "" {
-		return nil, errors.New("projectID is required")
+	if flag.Arg(0) == "init" {
+		if configPath == "" {
+			fatal("no config directory found; pass --config")
+		}
+		var opts []option.ClientOption
+		if credentialsPath != "" {
+			opts = append(opts, option.WithCredentialsFile(credentialsPath))
+		}
+		fileCfg.Credentials = credentialsPath
+		w := &wizard{in: bufio.NewReader(os.Stdin), out: os.Stdout, cloud: gcpSetup{opts: opts}}
+		if err := w.Run(context.Background(), configPath, fileCfg); err != nil {
+			fatal("init: %v", err)
+		}
+		return
 	}
-	if database == "" {
-		return nil, errors.New("database is required")
+
+	currency, err = shoppinglist.NormalizeCurrency(currency)
+	if err != nil {
+		fatal("%v", err)
 	}
-	if collection == "" {
-		return nil, errors.New("collection is required")
+	var (
+		clock shoppinglist.Clock       = shoppinglist.SystemClock{}
+		ids   shoppinglist.IDGenerator = shoppinglist.RandomIDs{}
+	)
+	if freezeTime != "" {
+		t, err := time.Parse(time.RFC3339, freezeTime)
+		if err != nil {
+			fatal("invalid -freeze-time: %v", err)
+		}
+		clock, ids = shoppinglist.NewFrozenClock(t), &shoppinglist.SequentialIDs{}
+		log.Printf("test mode: clock frozen at %s with sequential IDs", t.UTC().Format(time.RFC3339))
 	}
 
-	var opts []option.ClientOption
-	if credentialsPath != "" {
-		if _, err := os.Stat(credentialsPath); err != nil {
-			return nil, fmt.Errorf("credentials file: %w", err)
-		}
-		opts = append(opts, option.WithCredentialsFile(credentialsPath))
+	rates, err := shoppinglist.NewExchangeRateSource(exchangeRates, currency, clock)
+	if err != nil {
+		fatal("%v", err)
 	}
 
-	client, err := firestore.NewClientWithDatabase(ctx, projectID, database, opts...)
+	maintenance, err := shoppinglist.ParseMaintenanceWindow(maintenanceWindow)
 	if err != nil {
-		return nil, fmt.Errorf("create firestore client: %w", err)
+		fatal("%v", err)
+	}
+	retention, err := shoppinglist.ParseRetention(retentionSpec)
+	if err != nil {
+		fatal("%v", err)
 	}
 
-	return &ShoppingListService{
-		client:     client,
-		database:   database,
-		collection: collection,
-	}, nil
-}
+	normalizer, err := shoppinglist.ParseNormalizer(normalization, synonymsPath)
+	if err != nil {
+		fatal("%v", err)
+	}
 
-// Close releases Firestore resources.
-func (s *ShoppingListService) Close() error { return s.client.Close() }
+	profile, err := shoppinglist.ParseHouseholdProfile(locale, units)
+	if err != nil {
+		fatal("%v", err)
+	}
 
-// ListItems returns all items in the collection.
-func (s *ShoppingListService) ListItems(ctx context.Context) ([]Item, error) {
-	docs, err := s.client.Collection(s.collection).Documents(ctx).GetAll()
+	rollover, err := shoppinglist.ParseRolloverSchedule(rolloverSpec)
+	if err != nil {
+		fatal("%v", err)
+	}
+	template, err := shoppinglist.LoadTemplate(rolloverTemplate)
 	if err != nil {
-		return nil, fmt.Errorf("retrieve items: %w", err)
+		fatal("%v", err)
+	}
+	rules, err := shoppinglist.LoadRules(rulesPath)
+	if err != nil {
+		fatal("%v", err)
+	}
+	filter, err := shoppinglist.LoadContentFilter(denylistPath, denylistMode)
+	if err != nil {
+		fatal("%v", err)
+	}
+
+	cfg := serverConfig{
+		exportInlineLimit: exportInlineLimit,
+		exports:           shoppinglist.NewExportStore(time.Hour, ids),
+		exportsOverHTTP:   httpAddr != "",
+		rates:             rates,
+		currency:          currency,
+		budget:            budget,
+		template:          template,
+		collections:       fileCfg.Collections,
+		instructions:      strings.TrimSpace(fileCfg.Instructions),
+		returns:           returns,
+	}
+	if returns != returnList && returns != returnItem {
+		fatal("invalid -return %q (expected %s or %s)", returns, returnList, returnItem)
+	}
+	switch fieldNames {
+	case fieldNamesSnake:
+		if legacyFields {
+			fatal("-legacy-fields needs -field-names %s", fieldNamesCamel)
+		}
+	case fieldNamesCamel:
+		cfg.naming = fieldNaming{camel: true, legacy: legacyFields}
+	default:
+		fatal("invalid -field-names %q (expected %s or %s)", fieldNames, fieldNamesSnake, fieldNamesCamel)
+	}
+	if latencyBudgets != "" && !telemetry {
+		fatal("-latency-budgets needs -telemetry")
+	}
+	cfg.telemetry = telemetry
+	if cfg.latencyBudgets, err = shoppinglist.ParseLatencyBudgets(latencyBudgets); err != nil {
+		fatal("%v", err)
 	}
 
-	items := make([]Item, 0, len(docs))
-	for _, d := range docs {
-		var it Item
-		if err := d.DataTo(&it); err != nil {
-			log.Printf("warn: unmarshal item %q: %v", d.Ref.ID, err)
-			continue
+	if flag.Arg(0) == "schema" {
+		if err := writeSchemaBundle(os.Stdout, newMCPServer(nil, shoppinglist.LocalEmbedder{Dims: 256}, cfg), Version); err != nil {
+			fatal("write schema: %v", err)
 		}
-		items = append(items, it)
+		return
 	}
-	return items, nil
-}
 
-// UpsertItem creates a new item (if ID is empty) or updates an existing one.
-func (s *ShoppingListService) UpsertItem(ctx context.Context, input ItemInput) ([]Item, error) {
-	now := time.Now().UTC()
+	// Resolve project ID.
+	projectID = firstNonEmpty(os.Getenv("GOOGLE_CLOUD_PROJECT"), fileCfg.Project)
 
-	if input.ID == nil || *input.ID == "" {
-		// create
-		id := uuid.New().String()
-		item := Item{
-			ID:        id,
-			Name:      input.Name,
-			Quantity:  input.Quantity,
-			CreatedAt: now,
-		}
-		_, err := s.client.Collection(s.collection).Doc(id).Create(ctx, item)
+	if projectID == "" {
+		fatal("Google Cloud Project ID is required; run `mcp-shopping-list-firestore init` or set the GOOGLE_CLOUD_PROJECT environment variable")
+	}
+
+	// Resolve Firestore database.
+	firestoreDatabase := firstNonEmpty(os.Getenv("FIRESTORE_DATABASE"), fileCfg.Database)
+	if firestoreDatabase == "" {
+		fatal("Firestore database name is required; run `mcp-shopping-list-firestore init` or set FIRESTORE_DATABASE")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	allowed := parseLocations(requireLocation)
+	if len(allowed) > 0 {
+		location, err := shoppinglist.VerifyDatabaseLocation(ctx, projectID, firestoreDatabase, credentialsPath, allowed)
 		if err != nil {
-			return nil, fmt.Errorf("create item: %w", err)
+			fatal("verify data residency: %v", err)
+		}
+		log.Printf("Firestore database %s is in %s", firestoreDatabase, location)
+	}
+
+	// Secrets may be given as sm:// Secret Manager references.
+	ownerToken, err := newSecret(os.Getenv("OWNER_TOKEN"), projectID)
+	if err != nil {
+		fatal("OWNER_TOKEN: %v", err)
+	}
+	inboundToken, err := newSecret(os.Getenv("INBOUND_TOKEN"), projectID)
+	if err != nil {
+		fatal("INBOUND_TOKEN: %v", err)
+	}
+	webhook, err := newSecret(notifyWebhook, projectID)
+	if err != nil {
+		fatal("-notify-webhook: %v", err)
+	}
+	secrets, err := newSecretManager(ctx, credentialsPath, ownerToken, inboundToken, webhook)
+	if err != nil {
+		fatal("%v", err)
+	}
+	if secrets != nil {
+		if err := secrets.Resolve(ctx, ownerToken, inboundToken, webhook); err != nil {
+			fatal("resolve secrets: %v", err)
+		}
+		if secretRefresh > 0 {
+			go secrets.RunRefresh(ctx, secretRefresh, ownerToken, inboundToken)
 		}
-	} else {
-		// update
-		updates := []firestore.Update{
-			{Path: "name", Value: input.Name},
+	}
+
+	retry := shoppinglist.DefaultRetryPolicy
+	retry.Attempts = retryAttempts
+	serviceOpts := []shoppinglist.ServiceOption{
+		shoppinglist.WithAnomalyDetector(shoppinglist.NewAnomalyDetector(time.Minute, maxAddsPerMinute, maxDeletesPerMinute, maintenance)),
+		shoppinglist.WithNotifier(shoppinglist.NewNotifier(webhook.Value())),
+		shoppinglist.WithStaleFallback(staleFallback),
+		shoppinglist.WithNormalizer(normalizer),
+		shoppinglist.WithHouseholdProfile(profile),
+		shoppinglist.WithRetention(retention),
+		shoppinglist.WithClock(clock),
+		shoppinglist.WithIDGenerator(ids),
+		shoppinglist.WithRules(rules),
+		shoppinglist.WithContentFilter(filter),
+		shoppinglist.WithRetryPolicy(retry),
+		shoppinglist.WithRequiredLocations(allowed),
+	}
+	switch itemIDs {
+	case "random":
+	case "derived":
+		serviceOpts = append(serviceOpts, shoppinglist.WithDerivedItemIDs())
+	default:
+		fatal("invalid -item-ids %q (expected random or derived)", itemIDs)
+	}
+	if instanceID != "" {
+		serviceOpts = append(serviceOpts, shoppinglist.WithInstanceID(instanceID))
+	}
+	if cacheItems {
+		serviceOpts = append(serviceOpts, shoppinglist.WithItemCache())
+	}
+	if shadowDatabase != "" || shadowCollection != "" {
+		shadowDatabase = firstNonEmpty(shadowDatabase, firestoreDatabase)
+		shadowCollection = firstNonEmpty(shadowCollection, defaultCollection)
+		if shadowDatabase == firestoreDatabase && shadowCollection == defaultCollection {
+			fatal("the shadow target must differ from the primary; set -shadow-database or -shadow-collection")
 		}
-		if input.Quantity != nil {
-			updates = append(updates, firestore.Update{Path: "quantity", Value: *input.Quantity})
+		// Every list is mirrored to the shadow database, so it must be in an
+		// allowed location too.
+		if len(allowed) > 0 && shadowDatabase != firestoreDatabase {
+			location, err := shoppinglist.VerifyDatabaseLocation(ctx, projectID, shadowDatabase, credentialsPath, allowed)
+			if err != nil {
+				fatal("verify data residency of the shadow database: %v", err)
+			}
+			log.Printf("shadow Firestore database %s is in %s", shadowDatabase, location)
 		}
-		_, err := s.client.Collection(s.collection).Doc(*input.ID).Update(ctx, updates)
+		shadow, err := shoppinglist.NewShadowTarget(ctx, projectID, shadowDatabase, shadowCollection, credentialsPath)
 		if err != nil {
-			return nil, fmt.Errorf("update item: %w", err)
+			fatal("initialize shadow: %v", err)
 		}
+		defer func() {
+			if err := shadow.Close(); err != nil {
+				log.Printf("warn: closing shadow Firestore: %v", err)
+			}
+		}()
+		serviceOpts = append(serviceOpts, shoppinglist.WithShadow(shadow))
+		log.Printf("shadow mode: mirroring lists to %s/%s", shadowDatabase, shadowCollection)
 	}
+	service, err := shoppinglist.NewService(ctx, projectID, firestoreDatabase, defaultCollection, credentialsPath, serviceOpts...)
+	if err != nil {
+		fatal("initialize Firestore: %v", err)
+	}
+	defer func() {
+		if err := service.Close(); err != nil {
+			log.Printf("warn: closing Firestore: %v", err)
+		}
+	}()
 
-	return s.ListItems(ctx)
-}
-
-// RemoveItem deletes a document by ID and returns the remaining list.
-func (s *ShoppingListService) RemoveItem(ctx context.Context, id string) ([]Item, error) {
-	_, err := s.client.Collection(s.collection).Doc(id).Delete(ctx)
+	embedder, err := shoppinglist.NewEmbedder(ctx, embeddings, projectID, vertexLocation, vertexModel, credentialsPath)
 	if err != nil {
-		return nil, fmt.Errorf("delete item: %w", err)
+		fatal("initialize embeddings: %v", err)
 	}
-	return s.ListItems(ctx)
-}
+	embedder = shoppinglist.NormalizeEmbeddings(embedder, normalizer)
 
-// -----------------------------------------------------------------------------
-// MCP server wiring
-// -----------------------------------------------------------------------------
+	if attachmentsBucket != "" {
+		cfg.attachments, err = shoppinglist.NewGCSAttachments(ctx, attachmentsBucket, credentialsPath, clock)
+		if err != nil {
+			fatal("initialize attachments: %v", err)
+		}
+		cfg.attachmentMax = attachmentMaxBytes
+	}
 
-func main() {
-	// Set the build version from the build info if not set by the build system
-	if Version == "dev" || Version == "" {
-		if bi, ok := debug.ReadBuildInfo(); ok {
-			if bi.Main.Version != "" && bi.Main.Version != "(devel)" {
-				Version = bi.Main.Version
-			}
+	// One-shot calls cannot serve export links after exiting, so exports are
+	// always returned inline.
+	if flag.Arg(0) == "call" {
+		cfg.exportInlineLimit = math.MaxInt
+	}
+	// One-shot calls do not start the listeners, so the flags and lists are
+	// loaded up front for the server's middleware to gate them by flag and
+	// hold them back from a list being re-pointed, as for any other call.
+	if err := service.LoadFlags(ctx); err != nil {
+		log.Printf("warn: %v", err)
+	}
+	if err := service.LoadLists(ctx); err != nil {
+		log.Printf("warn: %v", err)
+	}
+	srv := newMCPServer(service, embedder, cfg)
+
+	if flag.Arg(0) == "export" {
+		if err := runExport(ctx, service, flag.Args()[1:], os.Stdout); err != nil {
+			fatal("%v", err)
+		}
+		return
+	}
+	if flag.Arg(0) == "call" {
+		if err := runCall(ctx, srv, flag.Args()[1:], os.Stdout); err != nil {
+			fatal("%v", err)
 		}
+		return
 	}
 
-	log.SetFlags(log.LstdFlags | log.Lmsgprefix)
-	log.SetPrefix("mcp-shopping-list-firestore: ")
+	go shoppinglist.RunFlagListener(ctx, service)
+	go shoppinglist.RunListListener(ctx, service)
+	service.StartItemCache(ctx)
+	if instanceID != "" && (rollover != nil || len(retention) > 0 || service.Shadow() != nil) {
+		lease, err := service.RenewLease(ctx)
+		switch {
+		case err != nil:
+			log.Printf("warn: %v", err)
+		case service.Leading():
+			log.Printf("instance %s runs background jobs", instanceID)
+		default:
+			log.Printf("instance %s: background jobs run on %s", instanceID, lease.Holder)
+		}
+		go shoppinglist.RunLeaseRenewal(ctx, service)
+	}
+	if rollover != nil {
+		go shoppinglist.RunRolloverSchedule(ctx, service, rollover, template)
+	}
+	if len(retention) > 0 {
+		go shoppinglist.RunRetention(ctx, service, maintenance)
+	}
+	if service.Shadow() != nil && shadowSweep > 0 {
+		go shoppinglist.RunShadowSweep(ctx, service, shadowSweep)
+	}
 
-	var (
-		httpAddr          string
-		projectID         string
-		credentialsPath   string
-		defaultCollection = "shopping"
-		showVersion       bool
-	)
+	// Transport ----------------------------------------------------------------
 
-	flag.StringVar(&httpAddr, "http", "", "run Streaming HTTP transport on the given address, e.g. 8080 (defaults to stdio if empty)")
-	flag.StringVar(&credentialsPath, "credentials", "", "path to Google Cloud credentials JSON file (optional; uses default auth if not provided)")
-	flag.BoolVar(&showVersion, "version", false, "print version and exit")
-	flag.Parse()
+	if httpAddr != "" {
+		fmt.Printf("Starting MCP server using Streamable HTTP transport on %s\n", httpAddr)
+		fmt.Printf("Project: %s | Database: %s | Collection: %s\n", projectID, firestoreDatabase, defaultCollection)
 
-	if showVersion {
-		if err := writeVersion(os.Stdout, "mcp-shopping-list-firestore", Version); err != nil {
-			fatal("write version: %v", err)
+		// Create HTTP server; tool calls go through the drainer so a shutdown
+		// signal refuses new calls while those in flight finish.
+		drain := &drainer{}
+		hs := &http.Server{Addr: ":" + httpAddr}
+		httpServer := server.NewStreamableHTTPServer(srv, server.WithStreamableHTTPServer(hs))
+		mux := http.NewServeMux()
+		var mcpHandler http.Handler = drain.wrap(httpServer)
+		if ownerToken.Value() != "" {
+			mcpHandler = requireToken(service, ownerToken, mcpHandler)
 		}
-		return
-	}
+		mux.Handle("/mcp", mcpHandler)
+		var exportsHandler http.Handler = exportHandler(service, cfg.exports)
+		if ownerToken.Value() != "" {
+			exportsHandler = requireToken(service, ownerToken, exportsHandler)
+		}
+		mux.Handle(exportsPath, exportsHandler)
+		if inboundToken.Value() != "" {
+			mux.Handle("/inbound", inboundHandler(service, inboundToken))
+		}
+		hs.Handler = mux
 
-	// Resolve project ID.
-	projectID = os.Getenv("GOOGLE_CLOUD_PROJECT")
+		fmt.Printf("Streamable HTTP Endpoint: http://localhost:%s/mcp\n", httpAddr)
+		if inboundToken.Value() != "" {
+			fmt.Printf("Inbound Endpoint: http://localhost:%s/inbound\n", httpAddr)
+		}
 
-	if projectID == "" {
-		fatal("Google Cloud Project ID is required; set the GOOGLE_CLOUD_PROJECT environment variable")
+		stopped := make(chan struct{})
+		go func() {
+			defer close(stopped)
+			signals := make(chan os.Signal, 1)
+			signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+			<-signals
+			cancel()
+			shutdownHTTP(drain, httpServer.Shutdown, drainTimeout)
+		}()
+
+		// Start the server
+		if err := httpServer.Start(":" + httpAddr); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fatal("Streamable HTTP server failed to start: %v", err)
+		}
+		<-stopped
+		return
 	}
 
-	// Resolve Firestore database.
-	firestoreDatabase := os.Getenv("FIRESTORE_DATABASE")
-	if firestoreDatabase == "" {
-		fatal("Firestore database name is required; set FIRESTORE_DATABASE")
+	// stdio mode
+	if err := server.ServeStdio(srv); err != nil {
+		fatal("MCP stdio terminated: %v", err)
 	}
+}
 
-	ctx := context.Background()
+// serverConfig carries the flag values the tools depend on.
+type serverConfig struct {
+	exportInlineLimit int
+	exports           *shoppinglist.ExportStore
+	exportsOverHTTP   bool
+	rates             shoppinglist.ExchangeRateSource
+	currency          string
+	budget            float64
+	attachments       shoppinglist.AttachmentStore
+	attachmentMax     int64
+	template          []shoppinglist.TemplateItem
+	naming            fieldNaming
+	telemetry         bool
+	latencyBudgets    shoppinglist.LatencyBudgets
+	collections       []CollectionConfig
+	instructions      string
+	returns           string
+}
 
-	service, err := NewShoppingListService(ctx, projectID, firestoreDatabase, defaultCollection, credentialsPath)
+// parseNeededBy reads the needed_by argument of upsert_item and
+// bulk_add_items: a YYYY-MM-DD date (as midnight UTC) or an RFC 3339 time.
+func parseNeededBy(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if t, err := time.Parse(time.DateOnly, s); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
 	if err != nil {
-		fatal("initialize Firestore: %v", err)
+		return time.Time{}, fmt.Errorf("invalid needed_by %q (expected YYYY-MM-DD or an RFC 3339 time)", s)
 	}
-	defer func() {
-		if err := service.Close(); err != nil {
-			log.Printf("warn: closing Firestore: %v", err)
-		}
-	}()
+	return t.UTC(), nil
+}
 
-	// Create MCP server.
-	srv := server.NewMCPServer("mcp-shopping-list-firestore", Version)
+// newMCPServer creates the MCP server and registers every tool. service may be
+// nil when the server is only built to describe its tools.
+func newMCPServer(service *shoppinglist.Service, embedder shoppinglist.Embedder, cfg serverConfig) *server.MCPServer {
+	hooks := &server.Hooks{}
+	opts := []server.ServerOption{
+		server.WithHooks(hooks),
+		server.WithResourceCapabilities(true, false),
+	}
+	if cfg.instructions != "" {
+		opts = append(opts, server.WithInstructions(cfg.instructions))
+	}
+	if cfg.telemetry {
+		// Outermost, so the recorded time and size cover the whole call.
+		opts = append(opts, server.WithToolHandlerMiddleware(withTelemetry(service, cfg.latencyBudgets)))
+	}
+	opts = append(opts,
+		server.WithToolHandlerMiddleware(withClientFeatures),
+		server.WithToolHandlerMiddleware(withRetryHints),
+		server.WithToolHandlerMiddleware(withSessionActor),
+		server.WithToolHandlerMiddleware(withTokenScope(service)),
+		server.WithToolHandlerMiddleware(withFeatureFlags(service)),
+		server.WithToolHandlerMiddleware(withExplain),
+		server.WithToolHandlerMiddleware(withListMoves(service)),
+		server.WithToolFilter(featureToolFilter(service)),
+	)
+	if cfg.naming.camel {
+		opts = append(opts, server.WithToolHandlerMiddleware(cfg.naming.middleware))
+	}
+	srv := server.NewMCPServer("mcp-shopping-list-firestore", Version, opts...)
 
 	// Tools --------------------------------------------------------------------
 
 	// list_items
 	listItemsTool := mcp.NewTool(
 		"list_items",
-		mcp.WithDescription("Retrieve all items from the shopping list."),
+		mcp.WithDescription("Retrieve all items from the shopping list. Removed items in the trash are left out unless 'include_deleted' is set; list_deleted lists only them."),
 		mcp.WithTitleAnnotation("List Shopping Items"),
 		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithBoolean("nested", mcp.Description("Return items grouped under their parent items (optional)")),
+		mcp.WithString("group_by", mcp.Description("Return items grouped by this field, e.g. 'category' to walk the store section by section (optional)"), mcp.Enum("category")),
+		mcp.WithBoolean("summary", mcp.Description("Return only the top items (still to buy, newest first) in compact form, counts for the whole list, and a next_cursor to the rest, for small-context clients (optional)")),
+		mcp.WithNumber("max_items", mcp.Description(fmt.Sprintf("Items per summary page (optional, default %d)", defaultSummaryItems))),
+		mcp.WithNumber("limit", mcp.Description(fmt.Sprintf("Return at most this many items, in the order they were added, with a next_cursor to the rest, or with order_by the top this many in that order; at most %d (optional)", shoppinglist.MaxPageSize))),
+		mcp.WithString("cursor", mcp.Description("next_cursor from an earlier page or summary; returns the following page (optional)")),
+		mcp.WithArray("tags", mcp.Description("Only items carrying every one of these tags, e.g. [\"party\", \"urgent\"] (optional)"), mcp.WithStringItems()),
+		mcp.WithString("order_by", mcp.Description("Order items by this field, overriding the session's sort_by; 'priority' puts high-priority items first, and 'category' puts uncategorized items last (optional)"), mcp.Enum(shoppinglist.OrderFields...)),
+		mcp.WithString("direction", mcp.Description("Direction of 'order_by' (optional, defaults to asc)"), mcp.Enum("asc", "desc")),
+		mcp.WithString("category", mcp.Description("Only items in this store section, e.g. 'produce', or 'uncategorized' for items without one (optional)")),
+		mcp.WithString("status", mcp.Description("Only items still to buy (pending) or checked off (purchased), or every item (all), e.g. 'pending' for \"what's left to buy?\" (optional)"), mcp.Enum(shoppinglist.Statuses...)),
+		mcp.WithString("format", mcp.Description("'markdown' returns a checklist grouped by store section, ready to show to the user, with the JSON as structured content (optional, defaults to json)"), mcp.Enum("json", "markdown")),
+		mcp.WithArray("fields", mcp.Description("Return only these fields of each item, plus id, e.g. [\"name\"] for a light listing of a big list; only they are read from Firestore. Combines with 'limit' and 'cursor' only (optional)"), mcp.WithStringEnumItems(shoppinglist.ProjectableFields)),
+		mcp.WithBoolean("include_deleted", mcp.Description("Also return removed items still in the trash, each with its deleted_at; not combined with 'summary', 'limit', 'cursor', 'fields', or the filters (optional)")),
+		listArg,
 	)
 	srv.AddTool(listItemsTool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := req.GetArguments()
+
 		toolCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
 		defer cancel()
 
-		items, err := service.ListItems(toolCtx)
+		svc, err := listFromArgs(toolCtx, service, args)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to resolve list: %v", err)), nil
+		}
+
+		// Extract optional tags field
+		var tags []string
+		if raw, ok := args["tags"]; ok && raw != nil {
+			tags, err = shoppinglist.ParseTags("tags", raw)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+		}
+
+		// Extract optional order_by field
+		orderBy, _ := args["order_by"].(string)
+		if orderBy != "" && !slices.Contains(shoppinglist.OrderFields, orderBy) {
+			return mcp.NewToolResultError(fmt.Sprintf("unsupported order_by %q", orderBy)), nil
+		}
+
+		// Extract optional direction field
+		direction, _ := args["direction"].(string)
+		if direction != "" && direction != "asc" && direction != "desc" {
+			return mcp.NewToolResultError(fmt.Sprintf("unsupported direction %q", direction)), nil
+		}
+		if direction != "" && orderBy == "" {
+			return mcp.NewToolResultError("'direction' needs an 'order_by'"), nil
+		}
+
+		// Extract optional format field
+		format, _ := args["format"].(string)
+		if format != "" && format != "json" && format != "markdown" {
+			return mcp.NewToolResultError(fmt.Sprintf("unsupported format %q (expected json or markdown)", format)), nil
+		}
+		if summary, _ := args["summary"].(bool); summary && format == "markdown" {
+			return mcp.NewToolResultError("'format' markdown cannot be combined with 'summary'"), nil
+		}
+
+		// Extract optional status field
+		status, _ := args["status"].(string)
+		if status != "" && !slices.Contains(shoppinglist.Statuses, status) {
+			return mcp.NewToolResultError(fmt.Sprintf("unsupported status %q", status)), nil
+		}
+		if summary, _ := args["summary"].(bool); summary && status != "" {
+			return mcp.NewToolResultError("'status' cannot be combined with 'summary', which already lists the items still to buy first"), nil
+		}
+
+		// Extract optional category field
+		category, _ := args["category"].(string)
+		category = shoppinglist.NormalizeCategory(category)
+		if summary, _ := args["summary"].(bool); summary && category != "" {
+			return mcp.NewToolResultError("'category' cannot be combined with 'summary'; use 'group_by' for a section-by-section view"), nil
+		}
+
+		// Extract optional limit field
+		limit := 0
+		if v, ok := args["limit"].(float64); ok {
+			if v < 1 || v > shoppinglist.MaxPageSize {
+				return mcp.NewToolResultError(fmt.Sprintf("'limit' must be between 1 and %d", shoppinglist.MaxPageSize)), nil
+			}
+			limit = int(v)
+		}
+
+		// Continue a page, or a summary, from its cursor
+		var after *shoppinglist.PageCursor
+		if raw, ok := args["cursor"].(string); ok && raw != "" {
+			if page, err := shoppinglist.DecodePageCursor(raw); err == nil {
+				after = &page
+			}
+		}
+		// With order_by, limit keeps the top items instead of paging.
+		paged := (limit > 0 && orderBy == "") || after != nil
+		if paged {
+			summary, _ := args["summary"].(bool)
+			nested, _ := args["nested"].(bool)
+			groupBy, _ := args["group_by"].(string)
+			if summary || nested || groupBy != "" || len(tags) > 0 || orderBy != "" || status != "" || category != "" {
+				return mcp.NewToolResultError("'limit' and page cursors cannot be combined with 'summary', 'nested', 'group_by', 'tags', 'order_by', 'status', or 'category'"), nil
+			}
+		}
+		if summary, _ := args["summary"].(bool); summary && limit > 0 {
+			return mcp.NewToolResultError("'limit' cannot be combined with 'summary'; use 'max_items'"), nil
+		}
+
+		// Extract optional include_deleted field
+		includeDeleted, _ := args["include_deleted"].(bool)
+		if includeDeleted {
+			summary, _ := args["summary"].(bool)
+			if summary || limit > 0 || args["fields"] != nil || args["cursor"] != nil || len(tags) > 0 || orderBy != "" || status != "" || category != "" {
+				return mcp.NewToolResultError("'include_deleted' cannot be combined with 'summary', 'limit', 'cursor', 'fields', 'tags', 'order_by', 'status', or 'category'"), nil
+			}
+		}
+		if raw, ok := args["fields"]; ok && raw != nil {
+			return listProjected(toolCtx, svc, args, raw, limit, after)
+		}
+		if raw, ok := args["cursor"].(string); ok && raw != "" && !paged {
+			cursor, err := shoppinglist.DecodeCursor(raw)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			items, err := svc.ItemsAt(toolCtx, cursor.ReadTime)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("failed to list items (the cursor may have expired; request a new summary): %v", err)), nil
+			}
+			page, next := shoppinglist.SummaryPage(shoppinglist.FilterTagged(items, cursor.Tags), cursor)
+			return structuredResult(shoppinglist.ListSummaryResponse{Items: page, NextCursor: next, ReadTime: &cursor.ReadTime})
+		}
+
+		var (
+			view       shoppinglist.ListView
+			staleAsOf  *time.Time
+			nextCursor string
+		)
+		switch {
+		case paged:
+			view, nextCursor, err = svc.ViewPage(toolCtx, cmp.Or(limit, defaultPageSize), after)
+		case status != "":
+			// Other filters and orderings are applied after the read, as
+			// combining them with the where clause needs composite indexes.
+			view, err = svc.ViewStatus(toolCtx, status)
+			if category != "" {
+				view.Items = shoppinglist.FilterCategory(view.Items, category)
+			}
+			view.Items = shoppinglist.SortItems(shoppinglist.FilterTagged(view.Items, tags), orderBy, direction)
+		case category != "":
+			view, err = svc.ViewCategory(toolCtx, category)
+			view.Items = shoppinglist.SortItems(shoppinglist.FilterTagged(view.Items, tags), orderBy, direction)
+		case len(tags) > 0:
+			view, err = svc.ViewTagged(toolCtx, tags)
+			view.Items = shoppinglist.SortItems(view.Items, orderBy, direction)
+		case orderBy != "":
+			view, err = svc.ViewOrdered(toolCtx, orderBy, direction, limit)
+		case includeDeleted:
+			view, err = svc.ViewWithDeleted(toolCtx)
+		default:
+			view, staleAsOf, err = svc.ViewOrStale(toolCtx)
+		}
 		if err != nil {
 			return mcp.NewToolResultError(fmt.Sprintf("failed to list items: %v", err)), nil
 		}
-		return jsonResult(ListItemsResponse{Items: items})
+		if limit > 0 && !paged {
+			view.Items = view.Items[:min(len(view.Items), limit)]
+		}
+		if summary, ok := args["summary"].(bool); ok && summary {
+			n := defaultSummaryItems
+			if v, ok := args["max_items"].(float64); ok {
+				if v < 1 {
+					return mcp.NewToolResultError("'max_items' must be at least 1"), nil
+				}
+				n = int(v)
+			}
+			resp := shoppinglist.Summarize(view.Items, n, view.ReadTime, tags)
+			if staleAsOf != nil {
+				resp.StaleAsOf = staleAsOf
+				resp.Warn(shoppinglist.WarnStaleData, "", "Firestore is unavailable; showing the list as of %s", staleAsOf.Format(time.RFC3339))
+			} else {
+				resp.ReadTime = &view.ReadTime
+			}
+			if view.Freeze != nil {
+				resp.FrozenUntil = &view.Freeze.Until
+			}
+			return structuredResult(resp)
+		}
+		prefs := sessionPrefs.Get(ctx)
+		present := prefs
+		if orderBy != "" {
+			// The view is already in the requested order.
+			present.SortBy, present.Direction = "", ""
+			prefs.SortBy, prefs.Direction = orderBy, direction
+		}
+		resp := shoppinglist.ListItemsResponse{Items: shoppinglist.ApplyPreferences(view.Items, present), NextCursor: nextCursor, StaleAsOf: staleAsOf}
+		if prefs.Display {
+			resp.Display = shoppinglist.NewDisplay(view.Items, prefs)
+		}
+		// A page's total, or the top items', would cover only those items.
+		if estimate := shoppinglist.EstimateTotal(ctx, cfg.rates, cfg.currency, view.Items); len(estimate.Lines) > 0 && !paged && limit == 0 {
+			resp.EstimatedTotal, resp.Currency, resp.Unpriced = &estimate.Total, estimate.Currency, len(estimate.Unpriced)
+			resp.Warnings = append(resp.Warnings, estimate.Warnings...)
+		}
+		if staleAsOf != nil {
+			resp.Warn(shoppinglist.WarnStaleData, "", "Firestore is unavailable; showing the list as of %s", staleAsOf.Format(time.RFC3339))
+		} else {
+			resp.ReadTime = &view.ReadTime
+		}
+		if view.Freeze != nil {
+			resp.FrozenUntil = &view.Freeze.Until
+		}
+		var out any = resp
+		if groupBy, ok := args["group_by"].(string); ok && groupBy != "" {
+			if groupBy != "category" {
+				return mcp.NewToolResultError(fmt.Sprintf("unsupported group_by %q", groupBy)), nil
+			}
+			out = shoppinglist.CategorizedItemsResponse{Categories: shoppinglist.GroupByCategory(resp.Items), ReadTime: resp.ReadTime, Display: resp.Display, ResponseWarnings: resp.ResponseWarnings}
+		} else if nested, ok := args["nested"].(bool); ok && nested {
+			out = shoppinglist.GroupedItemsResponse{Groups: shoppinglist.GroupItems(resp.Items), ReadTime: resp.ReadTime, Display: resp.Display, ResponseWarnings: resp.ResponseWarnings}
+		}
+		if format == "markdown" {
+			return mcp.NewToolResultStructured(out, shoppinglist.RenderChecklist(resp.Items)), nil
+		}
+		return structuredResult(out)
 	})
 
+	// upsert_item and remove_item return the list unless -return says not to.
+	defaultReturn := cmp.Or(cfg.returns, returnList)
+
 	// upsert_item
 	upsertItemTool := mcp.NewTool(
 		"upsert_item",
@@ -271,11 +834,26 @@ func main() {
 		mcp.WithTitleAnnotation("Upsert Shopping Item"),
 		mcp.WithString("name", mcp.Description("Name of the item"), mcp.Required()),
 		mcp.WithString("id", mcp.Description("ID of the item (optional, if not provided a new item will be created)")),
-		mcp.WithString("quantity", mcp.Description("Quantity of the item (optional)")),
+		mcp.WithString("quantity", mcp.Description("Quantity of the item, e.g. '500 g' or '2'; an amount and unit are parsed from it (optional)")),
+		mcp.WithNumber("amount", mcp.Description("Numeric amount to set instead of 'quantity', e.g. 500 (optional)")),
+		mcp.WithString("unit", mcp.Description("Unit of 'amount', e.g. 'g' (optional)")),
+		mcp.WithString("package_size", mcp.Description("Package size to buy with unit, e.g. '500 g' or '6 ct' (optional)")),
+		mcp.WithString("parent_id", mcp.Description("ID of the item to nest this one under, e.g. a 'Taco night' group (optional)")),
+		mcp.WithString("category", mcp.Description("Store section such as produce, dairy, or bakery; an empty string clears it (optional)")),
+		mcp.WithString("needed_by", mcp.Description("Date the item is needed by, as YYYY-MM-DD or an RFC 3339 time; an empty string clears it (optional)")),
+		mcp.WithArray("tags", mcp.Description("Labels such as party or urgent, replacing the item's tags; an empty array clears them (optional)"), mcp.WithStringItems()),
+		mcp.WithNumber("price", mcp.Description("Expected price of the item as listed, used for estimated_total; 0 clears it (optional)")),
+		mcp.WithString("currency", mcp.Description(fmt.Sprintf("ISO 4217 currency of 'price' (optional, defaults to %s)", cfg.currency))),
+		mcp.WithString("priority", mcp.Description("How much the item matters: high for must-buy items, normal (the default), or low (optional)"), mcp.Enum(shoppinglist.PriorityLow, shoppinglist.PriorityNormal, shoppinglist.PriorityHigh)),
+		mcp.WithBoolean("staple", mcp.Description("Put the item back on the list each week after it is purchased (optional)")),
+		mcp.WithString("if_unmodified_since", mcp.Description("With 'id', the item's updated_at as last read; the update fails with code 'conflict' if it has changed since (optional)")),
+		mcp.WithNumber("if_revision", mcp.Description("With 'id', the item's revision as last read; the update fails with code 'conflict' if it is no longer at it (optional)")),
+		returnArg(defaultReturn),
+		listArg,
 	)
 	srv.AddTool(upsertItemTool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		args := req.GetArguments()
-		var itemReq UpsertItemRequest
+		var itemReq shoppinglist.UpsertItemRequest
 
 		// Extract required name field
 		if name, ok := args["name"].(string); ok {
@@ -294,31 +872,277 @@ func main() {
 			itemReq.Quantity = &quantity
 		}
 
+		// Extract optional amount and unit fields
+		if amount, ok := args["amount"].(float64); ok {
+			if itemReq.Quantity != nil {
+				return mcp.NewToolResultError("give either 'quantity' or 'amount', not both"), nil
+			}
+			unit, _ := args["unit"].(string)
+			quantity, err := shoppinglist.QuantityFromAmount(amount, unit, service.Profile())
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			itemReq.Quantity = &quantity
+		} else if _, ok := args["unit"].(string); ok {
+			return mcp.NewToolResultError("'unit' needs an 'amount'"), nil
+		}
+
+		// Extract optional package_size field
+		if size, ok := args["package_size"].(string); ok && size != "" {
+			if _, _, err := shoppinglist.ParsePackageSize(size, service.Profile()); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			itemReq.PackageSize = &size
+		}
+
+		// Extract optional parent_id field
+		if parentID, ok := args["parent_id"].(string); ok && parentID != "" {
+			itemReq.ParentID = &parentID
+		}
+
+		// Extract optional category field
+		if category, ok := args["category"].(string); ok {
+			category = shoppinglist.NormalizeCategory(category)
+			itemReq.Category = &category
+		}
+
+		// Extract optional needed_by field
+		if v, ok := args["needed_by"].(string); ok {
+			var neededBy time.Time
+			if v != "" {
+				t, err := parseNeededBy(v)
+				if err != nil {
+					return mcp.NewToolResultError(err.Error()), nil
+				}
+				neededBy = t
+			}
+			itemReq.NeededBy = &neededBy
+		}
+
+		// Extract optional tags field
+		if raw, ok := args["tags"]; ok && raw != nil {
+			tags, err := shoppinglist.ParseTags("tags", raw)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			itemReq.Tags = tags
+		}
+
+		// Extract optional price and currency fields
+		if v, ok := args["price"].(float64); ok {
+			if v < 0 {
+				return mcp.NewToolResultError("'price' cannot be negative"), nil
+			}
+			itemReq.Price = &v
+			itemReq.Currency = cfg.currency
+			if c, ok := args["currency"].(string); ok && c != "" {
+				normalized, err := shoppinglist.NormalizeCurrency(c)
+				if err != nil {
+					return mcp.NewToolResultError(err.Error()), nil
+				}
+				itemReq.Currency = normalized
+			}
+		}
+
+		// Extract optional priority field
+		if v, ok := args["priority"].(string); ok {
+			priority, err := shoppinglist.ParsePriority(v)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			itemReq.Priority = &priority
+		}
+
+		// Extract optional staple field
+		if staple, ok := args["staple"].(bool); ok {
+			itemReq.Staple = &staple
+		}
+
+		// Extract optional precondition fields
+		pre, err := preconditionFromArgs(args)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		if !pre.IsZero() && itemReq.ID == nil {
+			return mcp.NewToolResultError("'if_unmodified_since' and 'if_revision' need an 'id'"), nil
+		}
+
+		// Extract optional return field
+		returns, err := returnFromArgs(args, defaultReturn)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
 		// Validate required fields
+		var warnings shoppinglist.ResponseWarnings
+		if name, coerced := shoppinglist.NormalizeItemName(itemReq.Name); coerced {
+			if name != "" {
+				warnings.Warn(shoppinglist.WarnValidationCoerced, "", "name %q was trimmed to %q", itemReq.Name, name)
+			}
+			itemReq.Name = name
+		}
 		if itemReq.Name == "" {
 			return mcp.NewToolResultError("'name' is required"), nil
 		}
+		if itemReq.Quantity != nil {
+			if msg := shoppinglist.QuantityAmbiguity(*itemReq.Quantity); msg != "" {
+				warnings.Warn(shoppinglist.WarnQuantityAmbiguous, "", "%s", msg)
+			}
+		}
 
 		toolCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
 		defer cancel()
 
-		items, err := service.UpsertItem(toolCtx, ItemInput{
-			ID:       itemReq.ID,
-			Name:     itemReq.Name,
-			Quantity: itemReq.Quantity,
-		})
+		svc, err := listFromArgs(toolCtx, service, args)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to resolve list: %v", err)), nil
+		}
+
+		input := shoppinglist.ItemInput{
+			ID:           itemReq.ID,
+			Name:         itemReq.Name,
+			Quantity:     itemReq.Quantity,
+			PackageSize:  itemReq.PackageSize,
+			ParentID:     itemReq.ParentID,
+			Category:     itemReq.Category,
+			NeededBy:     itemReq.NeededBy,
+			Tags:         itemReq.Tags,
+			Priority:     itemReq.Priority,
+			Price:        itemReq.Price,
+			Currency:     itemReq.Currency,
+			Staple:       itemReq.Staple,
+			Precondition: pre,
+		}
+		var (
+			change   shoppinglist.ItemChange
+			item     *shoppinglist.Item
+			items    []shoppinglist.Item
+			readBack shoppinglist.ResponseWarnings
+		)
+		if returns == returnItem {
+			change, item, readBack, err = svc.UpsertItemOnly(toolCtx, input)
+		} else {
+			change, items, err = svc.UpsertItem(toolCtx, input)
+		}
 		if err != nil {
+			if errors.Is(err, shoppinglist.ErrItemConflict) {
+				// A precondition needs an id, so one was given.
+				return conflictResult(err, *itemReq.ID), nil
+			}
+			var frozen *shoppinglist.ListFrozenError
+			if errors.As(err, &frozen) {
+				return mcp.NewToolResultError(frozen.Error()), nil
+			}
+			var violation *shoppinglist.RuleViolationError
+			if errors.As(err, &violation) {
+				return mcp.NewToolResultError(violation.Error()), nil
+			}
 			return mcp.NewToolResultError(fmt.Sprintf("failed to upsert item: %v", err)), nil
 		}
-		return jsonResult(ListItemsResponse{Items: items})
+		if returns == returnItem {
+			// Near duplicates are found in the list, which was not read.
+			warnings.Warnings = append(warnings.Warnings, readBack.Warnings...)
+			return structuredResult(shoppinglist.MutationResponse{Item: item, Changes: []shoppinglist.ItemChange{change}, ResponseWarnings: warnings})
+		}
+		shoppinglist.WarnNearDuplicates(toolCtx, &warnings, embedder, change.ID, itemReq.Name, items)
+		return structuredResult(shoppinglist.ListItemsResponse{Items: presentItems(ctx, items), Changes: []shoppinglist.ItemChange{change}, Display: displayFor(ctx, items), ResponseWarnings: warnings})
+	})
+
+	// get_item
+	getItemTool := mcp.NewTool(
+		"get_item",
+		mcp.WithDescription("Get one item by its ID, without listing the whole shopping list. An unknown ID gives an error with code 'not_found'."),
+		mcp.WithTitleAnnotation("Get Shopping Item"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("id", mcp.Description("ID of the item"), mcp.Required()),
+		listArg,
+	)
+	srv.AddTool(getItemTool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := req.GetArguments()
+
+		// Extract required id field
+		id, ok := args["id"].(string)
+		if !ok || id == "" {
+			return mcp.NewToolResultError("invalid or missing 'id'"), nil
+		}
+
+		toolCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+
+		svc, err := listFromArgs(toolCtx, service, args)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to resolve list: %v", err)), nil
+		}
+
+		item, err := svc.GetItem(toolCtx, id)
+		if errors.Is(err, shoppinglist.ErrItemNotFound) {
+			return errorResult(toolError{Code: "not_found", Error: err.Error(), ID: id}), nil
+		}
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get item: %v", err)), nil
+		}
+		return jsonResult(shoppinglist.ItemResponse{Item: item})
+	})
+
+	// rename_item
+	renameItemTool := mcp.NewTool(
+		"rename_item",
+		mcp.WithDescription("Change only the name of an existing item, leaving its quantity and every other field as they are."),
+		mcp.WithTitleAnnotation("Rename Shopping Item"),
+		mcp.WithIdempotentHintAnnotation(true),
+		mcp.WithString("id", mcp.Description("ID of the item"), mcp.Required()),
+		mcp.WithString("name", mcp.Description("New name of the item"), mcp.Required()),
+		listArg,
+	)
+	srv.AddTool(renameItemTool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := req.GetArguments()
+
+		// Extract required fields
+		id, ok := args["id"].(string)
+		if !ok || id == "" {
+			return mcp.NewToolResultError("invalid or missing 'id'"), nil
+		}
+		raw, ok := args["name"].(string)
+		if !ok {
+			return mcp.NewToolResultError("invalid or missing 'name'"), nil
+		}
+
+		// Validate required fields
+		var warnings shoppinglist.ResponseWarnings
+		name, coerced := shoppinglist.NormalizeItemName(raw)
+		if name == "" {
+			return mcp.NewToolResultError("'name' is required"), nil
+		}
+		if coerced {
+			warnings.Warn(shoppinglist.WarnValidationCoerced, "", "name %q was trimmed to %q", raw, name)
+		}
+
+		toolCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
+		defer cancel()
+
+		svc, err := listFromArgs(toolCtx, service, args)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to resolve list: %v", err)), nil
+		}
+
+		item, err := svc.RenameItem(toolCtx, id, name)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to rename item: %v", err)), nil
+		}
+		return jsonResult(shoppinglist.ItemResponse{Item: item, ResponseWarnings: warnings})
 	})
 
 	// remove_item
 	removeItemTool := mcp.NewTool(
 		"remove_item",
-		mcp.WithDescription("Remove an item from the shopping list by its ID."),
+		mcp.WithDescription("Remove an item from the shopping list by its ID, moving it to the trash, where restore_item can bring it back. An unknown ID gives an error with code 'not_found'."),
 		mcp.WithTitleAnnotation("Remove Shopping Item"),
 		mcp.WithString("id", mcp.Description("ID of the item to remove from the shopping list."), mcp.Required()),
+		mcp.WithBoolean("cascade", mcp.Description("Also remove the item's nested children; otherwise they become top-level items (optional)")),
+		mcp.WithString("if_unmodified_since", mcp.Description("The item's updated_at as last read; nothing is removed, with code 'conflict', if it has changed since (optional)")),
+		mcp.WithNumber("if_revision", mcp.Description("The item's revision as last read; nothing is removed, with code 'conflict', if it is no longer at it (optional)")),
+		returnArg(defaultReturn),
+		listArg,
 	)
 	srv.AddTool(removeItemTool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		args := req.GetArguments()
@@ -332,35 +1156,143 @@ func main() {
 		toolCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
 		defer cancel()
 
-		items, err := service.RemoveItem(toolCtx, id)
+		svc, err := listFromArgs(toolCtx, service, args)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("failed to remove item: %v", err)), nil
+			return mcp.NewToolResultError(fmt.Sprintf("failed to resolve list: %v", err)), nil
+		}
+
+		// Extract optional cascade field
+		cascade, _ := args["cascade"].(bool)
+
+		// Extract optional precondition fields
+		pre, err := preconditionFromArgs(args)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
 		}
-		return jsonResult(ListItemsResponse{Items: items})
+
+		// Extract optional return field
+		returns, err := returnFromArgs(args, defaultReturn)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		var (
+			changes []shoppinglist.ItemChange
+			items   []shoppinglist.Item
+		)
+		if returns == returnItem {
+			changes, err = svc.RemoveItemOnly(toolCtx, id, cascade, pre)
+		} else {
+			changes, items, err = svc.RemoveItemIf(toolCtx, id, cascade, pre)
+		}
+		if err != nil {
+			return removeErrorResult(err, id), nil
+		}
+		if returns == returnItem {
+			return structuredResult(shoppinglist.MutationResponse{Changes: changes})
+		}
+		return structuredResult(shoppinglist.ListItemsResponse{Items: presentItems(ctx, items), Changes: changes, Display: displayFor(ctx, items)})
 	})
 
-	// Transport ----------------------------------------------------------------
+	// remove_item_by_name
+	removeItemByNameTool := mcp.NewTool(
+		"remove_item_by_name",
+		mcp.WithDescription("Remove an item by name when its ID is not known. Names match ignoring case, or failing that by similarity. If more than one item matches, nothing is removed and the candidates are returned; ask the user which one they meant and remove it with remove_item."),
+		mcp.WithTitleAnnotation("Remove Shopping Item by Name"),
+		mcp.WithString("name", mcp.Description("Name of the item to remove"), mcp.Required()),
+		mcp.WithNumber("threshold", mcp.Description("Minimum similarity between 0 and 1 for a name that does not match exactly (optional, defaults to 0.8)")),
+		mcp.WithBoolean("cascade", mcp.Description("Also remove the item's nested children; otherwise they become top-level items (optional)")),
+		listArg,
+	)
+	srv.AddTool(removeItemByNameTool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := req.GetArguments()
 
-	if httpAddr != "" {
-		fmt.Printf("Starting MCP server using Streamable HTTP transport on %s\n", httpAddr)
-		fmt.Printf("Project: %s | Database: %s | Collection: %s\n", projectID, firestoreDatabase, defaultCollection)
+		// Extract required name field
+		name, ok := args["name"].(string)
+		if !ok || strings.TrimSpace(name) == "" {
+			return mcp.NewToolResultError("invalid or missing 'name'"), nil
+		}
 
-		// Create HTTP server
-		httpServer := server.NewStreamableHTTPServer(srv)
+		// Extract optional fields
+		threshold := 0.8
+		if t, ok := args["threshold"].(float64); ok {
+			if t < 0 || t > 1 {
+				return mcp.NewToolResultError("'threshold' must be between 0 and 1"), nil
+			}
+			threshold = t
+		}
+		cascade, _ := args["cascade"].(bool)
 
-		fmt.Printf("Streamable HTTP Endpoint: http://localhost:%s/mcp\n", httpAddr)
+		toolCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
+		defer cancel()
 
-		// Start the server
-		if err := httpServer.Start(":" + httpAddr); err != nil {
-			fatal("Streamable HTTP server failed to start: %v", err)
+		svc, err := listFromArgs(toolCtx, service, args)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to resolve list: %v", err)), nil
 		}
-		return
-	}
 
-	// stdio mode
-	if err := server.ServeStdio(srv); err != nil {
-		fatal("MCP stdio terminated: %v", err)
+		resp, err := svc.RemoveItemByName(toolCtx, embedder, name, threshold, cascade)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to remove item: %v", err)), nil
+		}
+		resp.Items = presentItems(ctx, resp.Items)
+		return jsonResult(resp)
+	})
+
+	registerFreezeTools(srv, service)
+	registerSimilarityTools(srv, service, embedder)
+	registerExportTools(srv, service, cfg.exports, cfg.exportInlineLimit, cfg.exportsOverHTTP)
+	registerPackageTools(srv, service, cfg.rates, cfg.currency)
+	registerCurrencyTools(srv, service, cfg.rates, cfg.currency, cfg.budget)
+	registerPreferenceTools(srv, hooks)
+	registerCapabilityHooks(hooks)
+	registerListTools(srv, service)
+	registerTokenTools(srv, service)
+	registerRecipeTools(srv, service)
+	registerReceiptTools(srv, service, embedder, cfg.currency)
+	registerSummarizeTools(srv, service, cfg)
+	registerRolloverTools(srv, service, cfg.template)
+	registerMergeTools(srv, service)
+	registerActivityTools(srv, service)
+	registerPurchasedTools(srv, service, embedder, cfg.rates, cfg.currency)
+	registerShoppingSessionTools(srv, service, cfg.rates, cfg.currency)
+	registerQuantityModeTools(srv, service)
+	registerQuantityTools(srv, service)
+	registerBulkTools(srv, service, cfg.currency)
+	registerImportTools(srv, service, cfg.currency)
+	registerSearchTools(srv, service)
+	registerCountTools(srv, service)
+	registerChangeTools(srv, service)
+	registerFlagTools(srv, service)
+	registerRepointTools(srv, service)
+	registerStaleItemTools(srv, service)
+	registerOrderTools(srv, service)
+	registerMoveTools(srv, service)
+	registerTrashTools(srv, service)
+	registerAuditTools(srv, service)
+	registerUsageTools(srv, service)
+	registerTelemetryTools(srv, service)
+	registerDashboardResources(srv, hooks, service, cfg)
+	registerListResources(srv, hooks, service)
+	registerItemResources(srv, service)
+	if cfg.attachments != nil {
+		registerAttachmentTools(srv, service, cfg.attachments, cfg.attachmentMax)
 	}
+	if service != nil && service.Shadow() != nil {
+		registerShadowTools(srv, service)
+	}
+	// Output schemas describe snake_case fields, so they are left off when
+	// results are renamed.
+	if !cfg.naming.camel {
+		addOutputSchemas(srv)
+	}
+	// Last, so the item tools they reuse are registered.
+	registerCollectionTools(srv, service, cfg.collections)
+	registerSchemaTools(srv)
+	// Last, so every tool takes it.
+	addExplainArg(srv)
+
+	return srv
 }
 
 // -----------------------------------------------------------------------------
@@ -372,6 +1304,36 @@ func fatal(format string, a ...any) {
 	os.Exit(1)
 }
 
+// toolError is the JSON body of a tool error clients are expected to handle,
+// identified by Code.
+type toolError struct {
+	Code         string `json:"code"`
+	Error        string `json:"error"`
+	ID           string `json:"id,omitempty"`
+	RetryAfterMS int64  `json:"retry_after_ms,omitempty"`
+}
+
+// errorResult returns e as an MCP tool error.
+func errorResult(e toolError) *mcp.CallToolResult {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return mcp.NewToolResultError(e.Error)
+	}
+	return mcp.NewToolResultError(string(b))
+}
+
+// removeErrorResult returns the tool error of a failed removal of the item
+// id: a conflict or an unknown ID with its code, and anything else as text.
+func removeErrorResult(err error, id string) *mcp.CallToolResult {
+	switch {
+	case errors.Is(err, shoppinglist.ErrItemConflict):
+		return conflictResult(err, id)
+	case errors.Is(err, shoppinglist.ErrItemNotFound):
+		return errorResult(toolError{Code: "not_found", Error: err.Error(), ID: id})
+	}
+	return mcp.NewToolResultError(fmt.Sprintf("failed to remove item: %v", err))
+}
+
 // jsonResult marshals v as JSON into an MCP text result.
 func jsonResult(v any) (*mcp.CallToolResult, error) {
 	b, err := json.Marshal(v)
@@ -2,9 +2,14 @@ package main
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"runtime"
 	"testing"
+	"time"
+
+	"github.com/UnitVectorY-Labs/mcp-shopping-list-firestore/pkg/shoppinglist"
+	"github.com/mark3labs/mcp-go/mcp"
 )
 
 func TestVersionVariableIsNotEmpty(t *testing.T) {
@@ -61,3 +66,45 @@ func TestWriteVersionWritesTrailingNewline(t *testing.T) {
 		t.Fatalf("unexpected written version output: got %q, want %q", buf.String(), want)
 	}
 }
+
+func TestErrorResultIsStructuredToolError(t *testing.T) {
+	res := errorResult(toolError{Code: "not_found", Error: `item not found: "x"`, ID: "x"})
+	if !res.IsError {
+		t.Fatal("expected a tool error")
+	}
+	var body map[string]string
+	if err := json.Unmarshal([]byte(res.Content[0].(mcp.TextContent).Text), &body); err != nil {
+		t.Fatalf("error body is not JSON: %v", err)
+	}
+	if body["code"] != "not_found" || body["id"] != "x" || body["error"] == "" {
+		t.Errorf("unexpected error body %v", body)
+	}
+}
+
+func TestRemoveErrorResultReportsNotFound(t *testing.T) {
+	res := removeErrorResult(fmt.Errorf("%w: %q", shoppinglist.ErrItemNotFound, "tacos"), "tacos")
+	var body map[string]string
+	if err := json.Unmarshal([]byte(res.Content[0].(mcp.TextContent).Text), &body); err != nil {
+		t.Fatalf("error body is not JSON: %v", err)
+	}
+	if !res.IsError || body["code"] != "not_found" || body["id"] != "tacos" {
+		t.Errorf("expected a not_found error for the missing item, got %v", body)
+	}
+
+	res = removeErrorResult(fmt.Errorf("%w: %q was removed", shoppinglist.ErrItemConflict, "tacos"), "tacos")
+	if err := json.Unmarshal([]byte(res.Content[0].(mcp.TextContent).Text), &body); err != nil || body["code"] != conflictCode {
+		t.Errorf("expected a conflict, got %v (%v)", body, err)
+	}
+}
+
+func TestParseNeededBy(t *testing.T) {
+	if got, err := parseNeededBy("2025-08-15"); err != nil || !got.Equal(time.Date(2025, 8, 15, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("parseNeededBy(date) = %v, %v", got, err)
+	}
+	if got, err := parseNeededBy("2025-08-15T18:00:00+02:00"); err != nil || got.Hour() != 16 {
+		t.Fatalf("parseNeededBy(RFC 3339) = %v, %v", got, err)
+	}
+	if _, err := parseNeededBy("Friday"); err == nil {
+		t.Fatal("expected an error for a weekday name")
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/UnitVectorY-Labs/mcp-shopping-list-firestore/pkg/shoppinglist"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// -----------------------------------------------------------------------------
+// Revisions and offline merges
+// -----------------------------------------------------------------------------
+
+func registerMergeTools(srv *server.MCPServer, service *shoppinglist.Service) {
+	// merge_changes
+	mergeChangesTool := mcp.NewTool(
+		"merge_changes",
+		mcp.WithDescription("Sync edits a client made offline. Each edit names an item, the revision it was based on, the client's base values for the fields it changed, and the new values. Fields the server also changed to something else are reported as conflicts instead of being overwritten; everything else is merged. Edits without an id create items, and delete removes an item unchanged since the base revision."),
+		mcp.WithTitleAnnotation("Merge Offline Changes"),
+		mcp.WithArray("edits",
+			mcp.Description("Offline edits to merge, in order"),
+			mcp.Required(),
+			mcp.Items(map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"id":            map[string]any{"type": "string", "description": "Item ID; omit to create an item"},
+					"client_ref":    map[string]any{"type": "string", "description": "Client-side reference echoed back in the result"},
+					"base_revision": map[string]any{"type": "integer", "description": "Item revision the edit was based on"},
+					"base":          map[string]any{"type": "object", "description": "Client's values, at base_revision, of the fields in changes"},
+					"changes":       map[string]any{"type": "object", "description": "New values for name, quantity, package_size, category, staple, or purchased"},
+					"delete":        map[string]any{"type": "boolean", "description": "Remove the item"},
+				},
+			}),
+		),
+		listArg,
+	)
+	srv.AddTool(mergeChangesTool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := req.GetArguments()
+
+		// Extract required fields
+		raw, ok := args["edits"].([]any)
+		if !ok || len(raw) == 0 {
+			return mcp.NewToolResultError("invalid or missing 'edits'"), nil
+		}
+		b, err := json.Marshal(raw)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("invalid 'edits': %v", err)), nil
+		}
+		var edits []shoppinglist.ItemEdit
+		if err := json.Unmarshal(b, &edits); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("invalid 'edits': %v", err)), nil
+		}
+
+		toolCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		defer cancel()
+
+		svc, err := listFromArgs(toolCtx, service, args)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to resolve list: %v", err)), nil
+		}
+
+		results, err := svc.MergeEdits(toolCtx, edits)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to merge changes: %v", err)), nil
+		}
+		resp := shoppinglist.MergeResponse{Results: results}
+		for _, r := range results {
+			if r.Status == shoppinglist.MergeConflict {
+				resp.Conflicts++
+			}
+		}
+		return jsonResult(resp)
+	})
+}
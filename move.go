@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/UnitVectorY-Labs/mcp-shopping-list-firestore/pkg/shoppinglist"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// -----------------------------------------------------------------------------
+// Moving items between lists
+// -----------------------------------------------------------------------------
+
+func registerMoveTools(srv *server.MCPServer, service *shoppinglist.Service) {
+	// move_item
+	moveItemTool := mcp.NewTool(
+		"move_item",
+		mcp.WithDescription("Move an item, with any items nested under it, to another list in one transaction, keeping its quantity, category, price, tags, and other fields. It becomes a top-level item at the end of the other list's order."),
+		mcp.WithTitleAnnotation("Move Shopping Item"),
+		mcp.WithString("id", mcp.Description("ID of the item to move"), mcp.Required()),
+		mcp.WithString("to", mcp.Description("ID, slug, or former slug of the list to move it to; an empty string is the main list"), mcp.Required()),
+		listArg,
+	)
+	srv.AddTool(moveItemTool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := req.GetArguments()
+
+		// Extract required id and to fields
+		id, ok := args["id"].(string)
+		if !ok || id == "" {
+			return mcp.NewToolResultError("invalid or missing 'id'"), nil
+		}
+		to, ok := args["to"].(string)
+		if !ok {
+			return mcp.NewToolResultError("invalid or missing 'to'"), nil
+		}
+
+		toolCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
+		defer cancel()
+
+		svc, err := listFromArgs(toolCtx, service, args)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to resolve list: %v", err)), nil
+		}
+
+		resp, err := svc.MoveItem(toolCtx, id, to)
+		if errors.Is(err, shoppinglist.ErrItemNotFound) {
+			return errorResult(toolError{Code: "not_found", Error: err.Error(), ID: id}), nil
+		}
+		if err != nil {
+			var frozen *shoppinglist.ListFrozenError
+			if errors.As(err, &frozen) {
+				return mcp.NewToolResultError(frozen.Error()), nil
+			}
+			return mcp.NewToolResultError(fmt.Sprintf("failed to move item: %v", err)), nil
+		}
+		return jsonResult(resp)
+	})
+}
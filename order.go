@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/UnitVectorY-Labs/mcp-shopping-list-firestore/pkg/shoppinglist"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// -----------------------------------------------------------------------------
+// Manual ordering
+// -----------------------------------------------------------------------------
+
+func registerOrderTools(srv *server.MCPServer, service *shoppinglist.Service) {
+	// reorder_items
+	reorderItemsTool := mcp.NewTool(
+		"reorder_items",
+		mcp.WithDescription("Put items in a new order, e.g. the order of the aisles in a store. The given IDs move, in that order, to the top of the list, and the other items follow in their current order. list_items returns items in this order unless a sort preference is set."),
+		mcp.WithTitleAnnotation("Reorder Shopping Items"),
+		mcp.WithIdempotentHintAnnotation(true),
+		mcp.WithArray("ids", mcp.Description("IDs of the items in their new order"), mcp.Required(), mcp.WithStringItems()),
+		listArg,
+	)
+	srv.AddTool(reorderItemsTool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := req.GetArguments()
+
+		// Extract required ids field
+		ids, err := idsFromArgs(args)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		toolCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
+		defer cancel()
+
+		svc, err := listFromArgs(toolCtx, service, args)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to resolve list: %v", err)), nil
+		}
+
+		items, err := svc.ReorderItems(toolCtx, ids)
+		if errors.Is(err, shoppinglist.ErrItemNotFound) {
+			return errorResult(toolError{Code: "not_found", Error: err.Error()}), nil
+		}
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to reorder items: %v", err)), nil
+		}
+		return jsonResult(shoppinglist.ListItemsResponse{Items: presentItems(ctx, items), Display: displayFor(ctx, items)})
+	})
+}
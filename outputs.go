@@ -0,0 +1,76 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"reflect"
+
+	"github.com/UnitVectorY-Labs/mcp-shopping-list-firestore/pkg/shoppinglist"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// -----------------------------------------------------------------------------
+// Tool output schemas
+// -----------------------------------------------------------------------------
+
+// toolOutputs are the responses of the tools that declare an output schema,
+// one for each shape their structured content can take. Explain mode's report
+// is added to every one.
+var toolOutputs = map[string][]any{
+	"list_items": {
+		shoppinglist.ListItemsResponse{},
+		shoppinglist.ListSummaryResponse{},
+		shoppinglist.CategorizedItemsResponse{},
+		shoppinglist.GroupedItemsResponse{},
+		shoppinglist.ProjectedItemsResponse{},
+	},
+	"upsert_item": {shoppinglist.ListItemsResponse{}, shoppinglist.MutationResponse{}},
+	"remove_item": {shoppinglist.ListItemsResponse{}, shoppinglist.MutationResponse{}},
+}
+
+// outputSchema is the JSON Schema of structured content taking any of the
+// shapes of responses, inferred from their JSON field tags.
+func outputSchema(responses []any) (json.RawMessage, error) {
+	shapes := make([]*jsonschema.Schema, 0, len(responses)+1)
+	for _, r := range append(responses, shoppinglist.ExplainResponse{}) {
+		s, err := jsonschema.ForType(reflect.TypeOf(r), &jsonschema.ForOptions{IgnoreInvalidTypes: true})
+		if err != nil {
+			return nil, fmt.Errorf("infer schema of %T: %w", r, err)
+		}
+		shapes = append(shapes, s)
+	}
+	return json.Marshal(&jsonschema.Schema{Type: "object", AnyOf: shapes})
+}
+
+// addOutputSchemas declares the output schemas of the tools in toolOutputs,
+// so clients can validate their structured content. Tools that are not
+// registered, such as those gated off, are skipped.
+func addOutputSchemas(srv *server.MCPServer) {
+	for name, responses := range toolOutputs {
+		st := srv.GetTool(name)
+		if st == nil {
+			continue
+		}
+		schema, err := outputSchema(responses)
+		if err != nil {
+			log.Printf("warn: output schema of %s: %v", name, err)
+			continue
+		}
+		tool := st.Tool
+		tool.RawOutputSchema = schema
+		srv.AddTool(tool, st.Handler)
+	}
+}
+
+// structuredResult returns v as the structured content of an MCP result,
+// with its JSON as the text for clients that read text only.
+func structuredResult(v any) (*mcp.CallToolResult, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("encode response: %v", err)), nil
+	}
+	return mcp.NewToolResultStructured(v, string(b)), nil
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/UnitVectorY-Labs/mcp-shopping-list-firestore/pkg/shoppinglist"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// validateOutput checks v, as JSON, against the output schema of tool.
+func validateOutput(t *testing.T, tool string, v any) error {
+	t.Helper()
+	raw, err := outputSchema(toolOutputs[tool])
+	if err != nil {
+		t.Fatalf("outputSchema(%s): %v", tool, err)
+	}
+	var schema jsonschema.Schema
+	if err := json.Unmarshal(raw, &schema); err != nil {
+		t.Fatal(err)
+	}
+	resolved, err := schema.Resolve(nil)
+	if err != nil {
+		t.Fatalf("resolve %s output schema: %v", tool, err)
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var instance any
+	if err := json.Unmarshal(b, &instance); err != nil {
+		t.Fatal(err)
+	}
+	return resolved.Validate(instance)
+}
+
+func TestOutputSchemasAcceptResponses(t *testing.T) {
+	at := time.Date(2025, 8, 12, 9, 0, 0, 0, time.UTC)
+	qty := "2"
+	items := []shoppinglist.Item{{ID: "a", Name: "Milk", Quantity: &qty, CreatedAt: at, UpdatedAt: &at, Revision: 1}}
+	change := shoppinglist.ItemChange{ID: "a", Name: "Milk", Action: shoppinglist.ActionAdded, Fields: []shoppinglist.FieldChange{{Field: "name", New: "Milk"}}}
+	var warnings shoppinglist.ResponseWarnings
+	warnings.Warn(shoppinglist.WarnStaleData, "", "stale")
+
+	for _, tc := range []struct {
+		tool string
+		resp any
+	}{
+		{"list_items", shoppinglist.ListItemsResponse{Items: items, ReadTime: &at, ResponseWarnings: warnings}},
+		{"list_items", shoppinglist.GroupedItemsResponse{Groups: shoppinglist.GroupItems(items), ReadTime: &at}},
+		{"list_items", shoppinglist.CategorizedItemsResponse{Categories: shoppinglist.GroupByCategory(items)}},
+		{"list_items", shoppinglist.ProjectedItemsResponse{Fields: []string{"id", "name"}, Items: []map[string]any{{"id": "a", "name": "Milk"}}}},
+		{"upsert_item", shoppinglist.ListItemsResponse{Items: items, Changes: []shoppinglist.ItemChange{change}}},
+		{"upsert_item", shoppinglist.MutationResponse{Item: &items[0], Changes: []shoppinglist.ItemChange{change}}},
+		{"remove_item", shoppinglist.MutationResponse{Changes: []shoppinglist.ItemChange{change}}},
+		{"remove_item", shoppinglist.ExplainResponse{Tool: "remove_item", Reads: []shoppinglist.ExplainedRead{}, Writes: []shoppinglist.ExplainedWrite{}}},
+	} {
+		if err := validateOutput(t, tc.tool, tc.resp); err != nil {
+			t.Errorf("%s rejected %T: %v", tc.tool, tc.resp, err)
+		}
+	}
+}
+
+func TestOutputSchemasRejectOtherShapes(t *testing.T) {
+	if err := validateOutput(t, "remove_item", map[string]any{"removed": 1}); err == nil {
+		t.Fatal("expected an unknown shape to be rejected")
+	}
+}
+
+func TestAddOutputSchemas(t *testing.T) {
+	srv := newMCPServer(nil, shoppinglist.LocalEmbedder{Dims: 256}, serverConfig{currency: "USD", collections: []CollectionConfig{{Name: "chores"}}})
+	for _, name := range []string{"list_items", "upsert_item", "remove_item", "list_chores"} {
+		if st := srv.GetTool(name); st == nil || len(st.Tool.RawOutputSchema) == 0 {
+			t.Fatalf("expected %s to declare an output schema", name)
+		}
+	}
+	if st := srv.GetTool("get_item"); len(st.Tool.RawOutputSchema) != 0 {
+		t.Fatal("expected get_item to declare no output schema")
+	}
+
+	camel := newMCPServer(nil, shoppinglist.LocalEmbedder{Dims: 256}, serverConfig{currency: "USD", naming: fieldNaming{camel: true}})
+	if st := camel.GetTool("list_items"); len(st.Tool.RawOutputSchema) != 0 {
+		t.Fatal("expected no output schema for camelCase results")
+	}
+}
+
+func TestStructuredResultKeepsJSONText(t *testing.T) {
+	res, err := structuredResult(shoppinglist.MutationResponse{Changes: []shoppinglist.ItemChange{}})
+	if err != nil || res.StructuredContent == nil {
+		t.Fatalf("expected structured content, got %+v, %v", res, err)
+	}
+	want := `{"changes":[]}`
+	if text := res.Content[0].(mcp.TextContent).Text; text != want {
+		t.Fatalf("text = %q, want %q", text, want)
+	}
+	if got := downgradeResult(res, clientFeatures{}); len(got.Content) != 1 || got.StructuredContent != nil {
+		t.Fatalf("expected an older client to get the JSON text once, got %+v", got.Content)
+	}
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/UnitVectorY-Labs/mcp-shopping-list-firestore/pkg/shoppinglist"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// -----------------------------------------------------------------------------
+// Package sizes and unit prices
+// -----------------------------------------------------------------------------
+
+// rankPackageOptions sorts options cheapest per unit first. When options use
+// different bases they cannot be compared, no best is chosen, and the reason
+// is returned.
+func rankPackageOptions(options []shoppinglist.PackageOption) ([]shoppinglist.PackageOption, *shoppinglist.PackageOption, string) {
+	ranked := append([]shoppinglist.PackageOption(nil), options...)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].UnitBasis != ranked[j].UnitBasis {
+			return ranked[i].UnitBasis < ranked[j].UnitBasis
+		}
+		return ranked[i].UnitPrice < ranked[j].UnitPrice
+	})
+	if len(ranked) == 0 {
+		return ranked, nil, "no package options recorded"
+	}
+
+	bases := map[string]bool{}
+	for _, o := range ranked {
+		bases[o.UnitBasis] = true
+	}
+	if len(bases) > 1 {
+		return ranked, nil, "options use different units (weight, volume, count) and cannot be compared directly"
+	}
+	best := ranked[0]
+	return ranked, &best, ""
+}
+
+func registerPackageTools(srv *server.MCPServer, service *shoppinglist.Service, rates shoppinglist.ExchangeRateSource, currency string) {
+	// add_package_option
+	addPackageOptionTool := mcp.NewTool(
+		"add_package_option",
+		mcp.WithDescription("Record a package size and price for an item (e.g. 500 g for 3.49) so package options can be compared by unit price."),
+		mcp.WithTitleAnnotation("Add Package Option"),
+		mcp.WithString("id", mcp.Description("ID of the item"), mcp.Required()),
+		mcp.WithString("size", mcp.Description("Package size with unit, e.g. '500 g', '1.5 kg', '12 fl oz', '6 ct'"), mcp.Required()),
+		mcp.WithNumber("price", mcp.Description("Price of the package"), mcp.Required()),
+		mcp.WithString("currency", mcp.Description(fmt.Sprintf("ISO 4217 currency of the price (optional, defaults to %s)", currency))),
+		mcp.WithString("label", mcp.Description("Label for this option, e.g. brand or store (optional)")),
+		listArg,
+	)
+	srv.AddTool(addPackageOptionTool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := req.GetArguments()
+
+		// Extract required fields
+		id, ok := args["id"].(string)
+		if !ok || id == "" {
+			return mcp.NewToolResultError("invalid or missing 'id'"), nil
+		}
+		size, ok := args["size"].(string)
+		if !ok || size == "" {
+			return mcp.NewToolResultError("invalid or missing 'size'"), nil
+		}
+		price, ok := args["price"].(float64)
+		if !ok {
+			return mcp.NewToolResultError("invalid or missing 'price'"), nil
+		}
+
+		// Extract optional currency field
+		optCurrency := currency
+		if c, ok := args["currency"].(string); ok && c != "" {
+			normalized, err := shoppinglist.NormalizeCurrency(c)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			optCurrency = normalized
+		}
+
+		// Extract optional label field
+		label, _ := args["label"].(string)
+
+		option, err := shoppinglist.NewPackageOption(size, price, optCurrency, label, service.Profile())
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		toolCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
+		defer cancel()
+
+		svc, err := listFromArgs(toolCtx, service, args)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to resolve list: %v", err)), nil
+		}
+
+		item, err := svc.AddPackageOption(toolCtx, id, option)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to add package option: %v", err)), nil
+		}
+		return jsonResult(shoppinglist.ItemResponse{Item: item})
+	})
+
+	// best_value
+	bestValueTool := mcp.NewTool(
+		"best_value",
+		mcp.WithDescription("Compare an item's recorded package options by unit price, in the household's preferred currency, and report the best value."),
+		mcp.WithTitleAnnotation("Best Value Package"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("id", mcp.Description("ID of the item"), mcp.Required()),
+		listArg,
+	)
+	srv.AddTool(bestValueTool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := req.GetArguments()
+
+		// Extract required id field
+		id, ok := args["id"].(string)
+		if !ok || id == "" {
+			return mcp.NewToolResultError("invalid or missing 'id'"), nil
+		}
+
+		toolCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+
+		svc, err := listFromArgs(toolCtx, service, args)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to resolve list: %v", err)), nil
+		}
+
+		item, err := svc.GetItem(toolCtx, id)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get item: %v", err)), nil
+		}
+		options, err := shoppinglist.ConvertPackageOptions(toolCtx, rates, currency, item.PackageOptions)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to convert prices: %v", err)), nil
+		}
+		ranked, best, note := rankPackageOptions(options)
+		for i := range ranked {
+			ranked[i] = svc.Profile().LocalizeOption(ranked[i])
+		}
+		if best != nil {
+			localized := svc.Profile().LocalizeOption(*best)
+			best = &localized
+		}
+		resp := shoppinglist.BestValueResponse{ItemID: item.ID, Name: item.Name, Best: best, Options: ranked}
+		if note != "" {
+			resp.Warn(shoppinglist.WarnIncomparable, item.ID, "%s", note)
+		}
+		return jsonResult(resp)
+	})
+}
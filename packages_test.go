@@ -0,0 +1,33 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/UnitVectorY-Labs/mcp-shopping-list-firestore/pkg/shoppinglist"
+)
+
+func TestRankPackageOptionsPicksCheapestPerUnit(t *testing.T) {
+	small, _ := shoppinglist.NewPackageOption("500 g", 2.50, "USD", "small", shoppinglist.HouseholdProfile{})
+	big, _ := shoppinglist.NewPackageOption("1 kg", 4.00, "USD", "big", shoppinglist.HouseholdProfile{})
+
+	ranked, best, note := rankPackageOptions([]shoppinglist.PackageOption{small, big})
+	if note != "" {
+		t.Fatalf("unexpected note %q", note)
+	}
+	if best == nil || best.Label != "big" {
+		t.Fatalf("expected big package to be best, got %+v", best)
+	}
+	if ranked[0].UnitPrice != 0.4 || ranked[0].UnitBasis != "100 g" {
+		t.Fatalf("unexpected unit price %v per %s", ranked[0].UnitPrice, ranked[0].UnitBasis)
+	}
+}
+
+func TestRankPackageOptionsRefusesMixedUnits(t *testing.T) {
+	weight, _ := shoppinglist.NewPackageOption("500 g", 2.50, "USD", "", shoppinglist.HouseholdProfile{})
+	count, _ := shoppinglist.NewPackageOption("6 ct", 3.00, "USD", "", shoppinglist.HouseholdProfile{})
+
+	_, best, note := rankPackageOptions([]shoppinglist.PackageOption{weight, count})
+	if best != nil || note == "" {
+		t.Fatal("expected mixed units to be reported as incomparable")
+	}
+}
@@ -0,0 +1,207 @@
+package shoppinglist
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/firestore"
+)
+
+// -----------------------------------------------------------------------------
+// Activity log
+// -----------------------------------------------------------------------------
+
+// Actions recorded in the activity log.
+const (
+	ActionAdded     = "added"
+	ActionUpdated   = "updated"
+	ActionRemoved   = "removed"
+	ActionChecked   = "checked"
+	ActionUnchecked = "unchecked"
+	ActionRestored  = "restored"
+)
+
+// actionOrder is the order actions are described in a digest.
+var actionOrder = []string{ActionAdded, ActionChecked, ActionUnchecked, ActionUpdated, ActionRemoved, ActionRestored}
+
+// actionVerbs phrase each action for a digest.
+var actionVerbs = map[string]string{
+	ActionAdded:     "added",
+	ActionUpdated:   "updated",
+	ActionRemoved:   "removed",
+	ActionChecked:   "checked off",
+	ActionUnchecked: "unchecked",
+	ActionRestored:  "restored",
+}
+
+// ActivityEntry is one mutation of one item.
+type ActivityEntry struct {
+	ID       string    `json:"id" firestore:"id"`
+	Action   string    `json:"action" firestore:"action"`
+	ItemID   string    `json:"item_id" firestore:"item_id"`
+	ItemName string    `json:"item_name,omitempty" firestore:"item_name,omitempty"`
+	Actor    string    `json:"actor,omitempty" firestore:"actor,omitempty"`
+	At       time.Time `json:"at" firestore:"at"`
+
+	ExpireAt *time.Time `json:"-" firestore:"expire_at,omitempty"`
+}
+
+// ActorActivity counts one actor's mutations by action.
+type ActorActivity struct {
+	Actor   string         `json:"actor"`
+	Counts  map[string]int `json:"counts"`
+	Last    time.Time      `json:"last_at"`
+	entries int
+}
+
+// ActivityResponse is the recent activity on a list, newest first.
+type ActivityResponse struct {
+	Since   *time.Time      `json:"since,omitempty"`
+	Digest  string          `json:"digest"`
+	Actors  []ActorActivity `json:"actors"`
+	Entries []ActivityEntry `json:"entries"`
+	ResponseWarnings
+}
+
+// activityCollection holds the list's activity log.
+func (s *Service) activityCollection() *firestore.CollectionRef {
+	return s.client.Collection(s.collection + "_activity")
+}
+
+type actorKey struct{}
+
+// WithActor returns a context whose changes are logged as made by actor, such
+// as a household member or the name of the calling client.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// actorFromContext names who is behind ctx, as set by WithActor.
+func actorFromContext(ctx context.Context) string {
+	actor, _ := ctx.Value(actorKey{}).(string)
+	return actor
+}
+
+// recordActivity logs an action on each item. Failures are logged rather than
+// failing the mutation.
+func (s *Service) recordActivity(ctx context.Context, action string, items ...Item) {
+	if len(items) == 0 {
+		return
+	}
+	actor, now := actorFromContext(ctx), s.Now()
+	// Entries go out together, so a bulk add or an import costs one
+	// flush rather than a round trip per item.
+	bw := s.client.BulkWriter(ctx)
+	jobs := make([]*firestore.BulkWriterJob, len(items))
+	for i, it := range items {
+		entry := ActivityEntry{ID: s.NewID(), Action: action, ItemID: it.ID, ItemName: it.Name, Actor: actor, At: now, ExpireAt: s.retention.expireAt("activity", now)}
+		job, err := bw.Set(s.activityCollection().Doc(entry.ID), entry)
+		if err != nil {
+			log.Printf("warn: record activity on %q: %v", it.ID, err)
+			continue
+		}
+		jobs[i] = job
+	}
+	bw.End()
+	for i, job := range jobs {
+		if job == nil {
+			continue
+		}
+		if _, err := job.Results(); err != nil {
+			log.Printf("warn: record activity on %q: %v", items[i].ID, err)
+		}
+	}
+}
+
+// RecentActivity returns up to limit entries, newest first, at or after since
+// when it is set.
+func (s *Service) RecentActivity(ctx context.Context, limit int, since time.Time) ([]ActivityEntry, error) {
+	q := s.activityCollection().OrderBy("at", firestore.Desc).Limit(limit)
+	if !since.IsZero() {
+		q = q.Where("at", ">=", since)
+	}
+	docs, err := q.Documents(ctx).GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("retrieve activity: %w", err)
+	}
+	entries := make([]ActivityEntry, 0, len(docs))
+	for _, d := range docs {
+		var e ActivityEntry
+		if err := d.DataTo(&e); err != nil {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// SummarizeActivity counts entries per actor, most active first.
+func SummarizeActivity(entries []ActivityEntry) []ActorActivity {
+	index := map[string]int{}
+	var actors []ActorActivity
+	for _, e := range entries {
+		i, ok := index[e.Actor]
+		if !ok {
+			i = len(actors)
+			index[e.Actor] = i
+			actors = append(actors, ActorActivity{Actor: e.Actor, Counts: map[string]int{}})
+		}
+		a := &actors[i]
+		a.Counts[e.Action]++
+		a.entries++
+		if e.At.After(a.Last) {
+			a.Last = e.At
+		}
+	}
+	sort.SliceStable(actors, func(i, j int) bool { return actors[i].entries > actors[j].entries })
+	return actors
+}
+
+// ActivityDigest describes the activity in a sentence, e.g. "Since 2025-08-11
+// 09:00 UTC, Alex added 4 items and checked off 6."
+func ActivityDigest(actors []ActorActivity, since *time.Time) string {
+	lead := "Recently"
+	if since != nil {
+		lead = "Since " + since.UTC().Format("2006-01-02 15:04 UTC")
+	}
+	if len(actors) == 0 {
+		return lead + ", nothing changed on the list."
+	}
+
+	clauses := make([]string, 0, len(actors))
+	for _, a := range actors {
+		var parts []string
+		for _, action := range actionOrder {
+			n := a.Counts[action]
+			if n == 0 {
+				continue
+			}
+			part := fmt.Sprintf("%s %d", actionVerbs[action], n)
+			if len(parts) == 0 {
+				part += " item"
+				if n != 1 {
+					part += "s"
+				}
+			}
+			parts = append(parts, part)
+		}
+		who := a.Actor
+		if who == "" {
+			who = "someone"
+		}
+		clauses = append(clauses, who+" "+joinAnd(parts))
+	}
+	return lead + ", " + strings.Join(clauses, "; ") + "."
+}
+
+// joinAnd joins parts as "a, b and c".
+func joinAnd(parts []string) string {
+	if len(parts) <= 1 {
+		return strings.Join(parts, "")
+	}
+	return strings.Join(parts[:len(parts)-1], ", ") + " and " + parts[len(parts)-1]
+}
@@ -0,0 +1,43 @@
+package shoppinglist
+
+import (
+	"testing"
+	"time"
+)
+
+func TestActivityDigest(t *testing.T) {
+	at := time.Date(2025, 8, 12, 9, 0, 0, 0, time.UTC)
+	var entries []ActivityEntry
+	for i := 0; i < 4; i++ {
+		entries = append(entries, ActivityEntry{Action: ActionAdded, Actor: "Alex", At: at})
+	}
+	for i := 0; i < 6; i++ {
+		entries = append(entries, ActivityEntry{Action: ActionChecked, Actor: "Alex", At: at.Add(time.Hour)})
+	}
+	entries = append(entries, ActivityEntry{Action: ActionRemoved, Actor: "Sam", At: at})
+
+	actors := SummarizeActivity(entries)
+	if len(actors) != 2 || actors[0].Actor != "Alex" || actors[0].Counts[ActionChecked] != 6 {
+		t.Fatalf("unexpected summary: %+v", actors)
+	}
+	if !actors[0].Last.Equal(at.Add(time.Hour)) {
+		t.Fatalf("expected Alex's last change at %v, got %v", at.Add(time.Hour), actors[0].Last)
+	}
+
+	since := at.Add(-24 * time.Hour)
+	got := ActivityDigest(actors, &since)
+	want := "Since 2025-08-11 09:00 UTC, Alex added 4 items and checked off 6; Sam removed 1 item."
+	if got != want {
+		t.Fatalf("activityDigest =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestActivityDigestEmptyAndAnonymous(t *testing.T) {
+	if got := ActivityDigest(nil, nil); got != "Recently, nothing changed on the list." {
+		t.Fatalf("unexpected empty digest %q", got)
+	}
+	got := ActivityDigest(SummarizeActivity([]ActivityEntry{{Action: ActionUpdated}}), nil)
+	if got != "Recently, someone updated 1 item." {
+		t.Fatalf("unexpected digest %q", got)
+	}
+}
@@ -0,0 +1,175 @@
+package shoppinglist
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+// -----------------------------------------------------------------------------
+// Anomaly detection
+// -----------------------------------------------------------------------------
+
+// Activity kinds observed by the anomaly detector.
+const (
+	activityAdd    = "add"
+	activityDelete = "delete"
+)
+
+// Incident records unusual activity on the list.
+type Incident struct {
+	ID         string    `json:"id" firestore:"id"`
+	Kind       string    `json:"kind" firestore:"kind"`
+	Count      int       `json:"count" firestore:"count"`
+	Window     string    `json:"window" firestore:"window"`
+	Message    string    `json:"message" firestore:"message"`
+	DetectedAt time.Time `json:"detected_at" firestore:"detected_at"`
+
+	ExpireAt *time.Time `json:"-" firestore:"expire_at,omitempty"`
+}
+
+// MaintenanceWindow is a daily UTC time range, e.g. 02:00-04:00, during which
+// mass deletions are expected and not reported.
+type MaintenanceWindow struct {
+	Start, End time.Duration // offsets from midnight UTC
+}
+
+// ParseMaintenanceWindow parses "HH:MM-HH:MM". An empty string yields nil.
+func ParseMaintenanceWindow(s string) (*MaintenanceWindow, error) {
+	if s == "" {
+		return nil, nil
+	}
+	from, to, ok := strings.Cut(s, "-")
+	if !ok {
+		return nil, fmt.Errorf("maintenance window %q: expected HH:MM-HH:MM", s)
+	}
+	start, err := parseClock(from)
+	if err != nil {
+		return nil, fmt.Errorf("maintenance window %q: %w", s, err)
+	}
+	end, err := parseClock(to)
+	if err != nil {
+		return nil, fmt.Errorf("maintenance window %q: %w", s, err)
+	}
+	return &MaintenanceWindow{Start: start, End: end}, nil
+}
+
+func parseClock(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q", s)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// Contains reports whether t falls inside the window. Windows may wrap past midnight.
+func (w *MaintenanceWindow) Contains(t time.Time) bool {
+	if w == nil {
+		return false
+	}
+	t = t.UTC()
+	offset := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+	if w.Start <= w.End {
+		return offset >= w.Start && offset < w.End
+	}
+	return offset >= w.Start || offset < w.End
+}
+
+// AnomalyDetector counts mutations in a sliding window and flags bursts.
+type AnomalyDetector struct {
+	mu          sync.Mutex
+	window      time.Duration
+	limits      map[string]int
+	maintenance *MaintenanceWindow
+	events      map[string][]time.Time
+	lastAlert   map[string]time.Time
+}
+
+// NewAnomalyDetector returns a detector flagging more than maxAdds additions or
+// maxDeletes deletions within window. A limit of zero disables that check.
+func NewAnomalyDetector(window time.Duration, maxAdds, maxDeletes int, maintenance *MaintenanceWindow) *AnomalyDetector {
+	return &AnomalyDetector{
+		window:      window,
+		limits:      map[string]int{activityAdd: maxAdds, activityDelete: maxDeletes},
+		maintenance: maintenance,
+		events:      map[string][]time.Time{},
+		lastAlert:   map[string]time.Time{},
+	}
+}
+
+// Observe records n events of kind at time at and returns an incident, still
+// without an ID, when the limit is exceeded. At most one incident per kind is raised per window.
+func (d *AnomalyDetector) Observe(kind string, n int, at time.Time) *Incident {
+	if d == nil {
+		return nil
+	}
+	limit := d.limits[kind]
+	if limit <= 0 {
+		return nil
+	}
+	if kind == activityDelete && d.maintenance.Contains(at) {
+		return nil
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	cutoff := at.Add(-d.window)
+	kept := d.events[kind][:0]
+	for _, t := range d.events[kind] {
+		if t.After(cutoff) {
+			kept = append(kept, t)
+		}
+	}
+	for i := 0; i < n; i++ {
+		kept = append(kept, at)
+	}
+	d.events[kind] = kept
+
+	if len(kept) <= limit {
+		return nil
+	}
+	if last, ok := d.lastAlert[kind]; ok && at.Sub(last) < d.window {
+		return nil
+	}
+	d.lastAlert[kind] = at
+
+	return &Incident{
+		Kind:       kind,
+		Count:      len(kept),
+		Window:     d.window.String(),
+		Message:    fmt.Sprintf("%d %s operations within %s exceeds the limit of %d", len(kept), kind, d.window, limit),
+		DetectedAt: at.UTC(),
+	}
+}
+
+// observe feeds mutations to the anomaly detector, persisting and announcing
+// any incident. Failures are logged rather than failing the mutation.
+func (s *Service) observe(ctx context.Context, kind string, n int) {
+	incident := s.anomalies.Observe(kind, n, s.Now())
+	if incident == nil {
+		return
+	}
+
+	incident.ID = s.NewID()
+	incident.ExpireAt = s.retention.expireAt("incidents", incident.DetectedAt)
+	if _, err := s.client.Collection(s.collection+"_incidents").Doc(incident.ID).Set(ctx, incident); err != nil {
+		log.Printf("warn: record incident %q: %v", incident.ID, err)
+	}
+	if s.notifier == nil {
+		return
+	}
+	err := s.notifier.Notify(ctx, Notification{
+		Kind:    "incident",
+		Title:   "Unusual shopping list activity",
+		Message: incident.Message,
+		Time:    incident.DetectedAt,
+		Data:    incident,
+	})
+	if err != nil {
+		log.Printf("warn: notify incident %q: %v", incident.ID, err)
+	}
+}
@@ -0,0 +1,62 @@
+package shoppinglist
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAnomalyDetectorRaisesOncePerWindow(t *testing.T) {
+	d := NewAnomalyDetector(time.Minute, 3, 0, nil)
+	now := time.Date(2025, 8, 12, 12, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 3; i++ {
+		if inc := d.Observe(activityAdd, 1, now); inc != nil {
+			t.Fatalf("unexpected incident after %d adds", i+1)
+		}
+	}
+	inc := d.Observe(activityAdd, 1, now.Add(time.Second))
+	if inc == nil {
+		t.Fatal("expected incident once the limit is exceeded")
+	}
+	if inc.Count != 4 || inc.Kind != activityAdd {
+		t.Fatalf("unexpected incident: %+v", inc)
+	}
+	if again := d.Observe(activityAdd, 1, now.Add(2*time.Second)); again != nil {
+		t.Fatal("expected no second incident within the same window")
+	}
+}
+
+func TestAnomalyDetectorForgetsOldEvents(t *testing.T) {
+	d := NewAnomalyDetector(time.Minute, 2, 0, nil)
+	now := time.Date(2025, 8, 12, 12, 0, 0, 0, time.UTC)
+
+	d.Observe(activityAdd, 2, now)
+	if inc := d.Observe(activityAdd, 1, now.Add(2*time.Minute)); inc != nil {
+		t.Fatal("expected events outside the window to be dropped")
+	}
+}
+
+func TestAnomalyDetectorIgnoresDeletesDuringMaintenance(t *testing.T) {
+	window, err := ParseMaintenanceWindow("23:00-01:00")
+	if err != nil {
+		t.Fatalf("ParseMaintenanceWindow returned error: %v", err)
+	}
+	d := NewAnomalyDetector(time.Minute, 0, 1, window)
+
+	inside := time.Date(2025, 8, 12, 0, 30, 0, 0, time.UTC)
+	if inc := d.Observe(activityDelete, 10, inside); inc != nil {
+		t.Fatal("expected deletions inside the maintenance window to be ignored")
+	}
+	outside := time.Date(2025, 8, 12, 12, 0, 0, 0, time.UTC)
+	if inc := d.Observe(activityDelete, 10, outside); inc == nil {
+		t.Fatal("expected deletions outside the maintenance window to be reported")
+	}
+}
+
+func TestParseMaintenanceWindowRejectsMalformedInput(t *testing.T) {
+	for _, in := range []string{"02:00", "2am-4am", "02:00-25:00"} {
+		if _, err := ParseMaintenanceWindow(in); err == nil {
+			t.Fatalf("expected error for %q", in)
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package shoppinglist
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"cloud.google.com/go/storage"
+	"google.golang.org/api/option"
+)
+
+// -----------------------------------------------------------------------------
+// Attachments
+// -----------------------------------------------------------------------------
+
+// SignedURLTTL is how long upload and download URLs stay valid.
+const SignedURLTTL = 15 * time.Minute
+
+// attachmentTypes are the content types accepted for attachments.
+var attachmentTypes = []string{"image/", "application/pdf", "text/plain"}
+
+// Attachment is a small file, such as a receipt photo or product label,
+// stored in Cloud Storage and referenced from an item.
+type Attachment struct {
+	ID          string    `json:"id" firestore:"id"`
+	FileName    string    `json:"file_name" firestore:"file_name"`
+	ContentType string    `json:"content_type" firestore:"content_type"`
+	Size        int64     `json:"size_bytes" firestore:"size_bytes"`
+	Object      string    `json:"-" firestore:"object"`
+	CreatedAt   time.Time `json:"created_at" firestore:"created_at"`
+	DownloadURL string    `json:"download_url,omitempty" firestore:"-"`
+}
+
+// AttachmentStore issues signed URLs for attachment objects and deletes them.
+// UploadURL also returns the headers the upload must send, which the URL is
+// signed with.
+type AttachmentStore interface {
+	UploadURL(ctx context.Context, object, contentType string, size int64) (string, map[string]string, error)
+	DownloadURL(ctx context.Context, object string) (string, error)
+	Delete(ctx context.Context, object string) error
+}
+
+// gcsAttachments stores attachments in a Cloud Storage bucket.
+type gcsAttachments struct {
+	client *storage.Client
+	bucket string
+	clock  Clock
+}
+
+// NewGCSAttachments returns a store for the given bucket whose signed URLs
+// expire SignedURLTTL after clock's time. Signing uses the service account
+// key in credentialsPath, or the IAM signBlob API otherwise.
+func NewGCSAttachments(ctx context.Context, bucket, credentialsPath string, clock Clock) (AttachmentStore, error) {
+	var opts []option.ClientOption
+	if credentialsPath != "" {
+		opts = append(opts, option.WithCredentialsFile(credentialsPath))
+	}
+	client, err := storage.NewClient(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("create storage client: %w", err)
+	}
+	return &gcsAttachments{client: client, bucket: bucket, clock: clock}, nil
+}
+
+// uploadHeaders are the headers a PUT of an attachment must send: its
+// content type, and a length range Cloud Storage enforces so the upload
+// cannot exceed the declared size.
+func uploadHeaders(contentType string, size int64) map[string]string {
+	return map[string]string{
+		"Content-Type":                contentType,
+		"x-goog-content-length-range": fmt.Sprintf("0,%d", size),
+	}
+}
+
+func (g *gcsAttachments) UploadURL(_ context.Context, object, contentType string, size int64) (string, map[string]string, error) {
+	headers := uploadHeaders(contentType, size)
+	url, err := g.client.Bucket(g.bucket).SignedURL(object, &storage.SignedURLOptions{
+		Scheme:      storage.SigningSchemeV4,
+		Method:      "PUT",
+		ContentType: contentType,
+		Headers:     []string{"x-goog-content-length-range:" + headers["x-goog-content-length-range"]},
+		Expires:     g.clock.Now().Add(SignedURLTTL),
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	return url, headers, nil
+}
+
+func (g *gcsAttachments) DownloadURL(_ context.Context, object string) (string, error) {
+	return g.client.Bucket(g.bucket).SignedURL(object, &storage.SignedURLOptions{
+		Scheme:  storage.SigningSchemeV4,
+		Method:  "GET",
+		Expires: g.clock.Now().Add(SignedURLTTL),
+	})
+}
+
+func (g *gcsAttachments) Delete(ctx context.Context, object string) error {
+	err := g.client.Bucket(g.bucket).Object(object).Delete(ctx)
+	if err == storage.ErrObjectNotExist {
+		return nil
+	}
+	return err
+}
+
+// NewAttachment validates an attachment request for an item of the list and
+// assigns its ID and object path.
+func (s *Service) NewAttachment(itemID, fileName, contentType string, size, maxBytes int64) (Attachment, error) {
+	base := path.Base(strings.ReplaceAll(strings.TrimSpace(fileName), "\\", "/"))
+	if base == "" || base == "." || base == "/" {
+		return Attachment{}, fmt.Errorf("invalid file name %q", fileName)
+	}
+	if size <= 0 || size > maxBytes {
+		return Attachment{}, fmt.Errorf("attachments must be between 1 and %d bytes, got %d", maxBytes, size)
+	}
+	contentType = strings.ToLower(strings.TrimSpace(contentType))
+	allowed := false
+	for _, t := range attachmentTypes {
+		if strings.HasSuffix(t, "/") && strings.HasPrefix(contentType, t) || contentType == t {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return Attachment{}, fmt.Errorf("unsupported content type %q (expected an image, PDF, or plain text)", contentType)
+	}
+
+	id := s.NewID()
+	return Attachment{
+		ID:          id,
+		FileName:    base,
+		ContentType: contentType,
+		Size:        size,
+		Object:      path.Join(s.collection, itemID, id, base),
+		CreatedAt:   s.Now(),
+	}, nil
+}
+
+// AddAttachment records attachment metadata on an item. Items in the trash
+// are refused as not found.
+func (s *Service) AddAttachment(ctx context.Context, id string, a Attachment) error {
+	ref := s.client.Collection(s.collection).Doc(id)
+	err := s.runTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		if _, err := untrashed(tx.Get(ref)); err != nil {
+			return err
+		}
+		return tx.Update(ref, withRevision([]firestore.Update{{Path: "attachments", Value: firestore.ArrayUnion(a)}}, s.writeTime()))
+	})
+	if err != nil {
+		return fmt.Errorf("add attachment: %w", err)
+	}
+	return nil
+}
+
+// RemoveAttachment drops an attachment from an item and returns it.
+func (s *Service) RemoveAttachment(ctx context.Context, id, attachmentID string) (Attachment, error) {
+	ref := s.client.Collection(s.collection).Doc(id)
+	var removed Attachment
+	err := s.runTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := untrashed(tx.Get(ref))
+		if err != nil {
+			return err
+		}
+		var it Item
+		if err := doc.DataTo(&it); err != nil {
+			return err
+		}
+		kept := make([]Attachment, 0, len(it.Attachments))
+		found := false
+		for _, a := range it.Attachments {
+			if a.ID == attachmentID {
+				removed, found = a, true
+				continue
+			}
+			kept = append(kept, a)
+		}
+		if !found {
+			return fmt.Errorf("item %q has no attachment %q", id, attachmentID)
+		}
+		return tx.Update(ref, withRevision([]firestore.Update{{Path: "attachments", Value: kept}}, s.writeTime()))
+	})
+	if err != nil {
+		return Attachment{}, fmt.Errorf("remove attachment: %w", err)
+	}
+	return removed, nil
+}
+
+// AttachmentResponse wraps a new attachment, the URL to upload it to, and
+// the headers the upload must send.
+type AttachmentResponse struct {
+	Attachment    Attachment        `json:"attachment"`
+	UploadURL     string            `json:"upload_url,omitempty"`
+	UploadHeaders map[string]string `json:"upload_headers,omitempty"`
+	ResponseWarnings
+}
+
+// AttachmentsResponse wraps an item's attachments.
+type AttachmentsResponse struct {
+	Attachments []Attachment `json:"attachments"`
+	ResponseWarnings
+}
@@ -0,0 +1,57 @@
+package shoppinglist
+
+import (
+	"strings"
+	"testing"
+)
+
+// attachmentService is a service for the list in collection shopping.
+func attachmentService() *Service {
+	return &Service{collection: "shopping", clock: SystemClock{}, ids: RandomIDs{}}
+}
+
+func TestNewAttachmentBuildsObjectPath(t *testing.T) {
+	a, err := attachmentService().NewAttachment("item-1", "../photos/Receipt.JPG", "Image/JPEG", 2048, 1<<20)
+	if err != nil {
+		t.Fatalf("newAttachment returned error: %v", err)
+	}
+	if a.FileName != "Receipt.JPG" || a.ContentType != "image/jpeg" {
+		t.Fatalf("unexpected attachment: %+v", a)
+	}
+	want := "shopping/item-1/" + a.ID + "/Receipt.JPG"
+	if a.Object != want {
+		t.Fatalf("expected object %q, got %q", want, a.Object)
+	}
+}
+
+func TestNewAttachmentValidates(t *testing.T) {
+	cases := []struct {
+		name, fileName, contentType string
+		size                        int64
+		wantErr                     string
+	}{
+		{"too large", "label.png", "image/png", 2 << 20, "between 1 and"},
+		{"empty", "label.png", "image/png", 0, "between 1 and"},
+		{"bad type", "run.sh", "application/x-sh", 10, "unsupported content type"},
+		{"no name", " ", "text/plain", 10, "invalid file name"},
+	}
+	for _, c := range cases {
+		_, err := attachmentService().NewAttachment("item-1", c.fileName, c.contentType, c.size, 1<<20)
+		if err == nil || !strings.Contains(err.Error(), c.wantErr) {
+			t.Errorf("%s: expected error containing %q, got %v", c.name, c.wantErr, err)
+		}
+	}
+}
+
+func TestNewAttachmentAcceptsPDF(t *testing.T) {
+	if _, err := attachmentService().NewAttachment("item-1", "receipt.pdf", "application/pdf", 10, 1<<20); err != nil {
+		t.Fatalf("expected PDF to be accepted, got %v", err)
+	}
+}
+
+func TestUploadHeadersCapTheDeclaredSize(t *testing.T) {
+	h := uploadHeaders("image/jpeg", 2048)
+	if h["Content-Type"] != "image/jpeg" || h["x-goog-content-length-range"] != "0,2048" || len(h) != 2 {
+		t.Fatalf("unexpected upload headers %v", h)
+	}
+}
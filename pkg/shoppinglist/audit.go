@@ -0,0 +1,149 @@
+package shoppinglist
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"cloud.google.com/go/firestore"
+)
+
+// -----------------------------------------------------------------------------
+// Audit trail
+// -----------------------------------------------------------------------------
+
+// AuditEntry is one write to one item: who made it, through which client,
+// and the values of the fields it changed.
+type AuditEntry struct {
+	ID       string        `json:"id" firestore:"id"`
+	ItemID   string        `json:"item_id" firestore:"item_id"`
+	ItemName string        `json:"item_name,omitempty" firestore:"item_name,omitempty"`
+	Action   string        `json:"action" firestore:"action"`
+	Fields   []FieldChange `json:"fields,omitempty" firestore:"fields,omitempty"`
+	Actor    string        `json:"actor,omitempty" firestore:"actor,omitempty"`
+	Client   string        `json:"client,omitempty" firestore:"client,omitempty"`
+	At       time.Time     `json:"at" firestore:"at"`
+
+	ExpireAt *time.Time `json:"-" firestore:"expire_at,omitempty"`
+}
+
+// ItemHistoryResponse is an item's audit trail, newest first.
+type ItemHistoryResponse struct {
+	ItemID  string       `json:"item_id"`
+	Entries []AuditEntry `json:"entries"`
+	ResponseWarnings
+}
+
+// auditCollection holds the list's audit trail.
+func (s *Service) auditCollection() *firestore.CollectionRef {
+	return s.client.Collection(s.collection + "_audit")
+}
+
+type clientKey struct{}
+
+// WithClient returns a context whose changes are audited as made through
+// client, such as the name and version the MCP client reported.
+func WithClient(ctx context.Context, client string) context.Context {
+	return context.WithValue(ctx, clientKey{}, client)
+}
+
+// clientFromContext names the client behind ctx, as set by WithClient.
+func clientFromContext(ctx context.Context) string {
+	client, _ := ctx.Value(clientKey{}).(string)
+	return client
+}
+
+// itemChanges describes adding items, or removing them, for the audit trail.
+func itemChanges(action string, items []Item) []ItemChange {
+	changes := make([]ItemChange, len(items))
+	for i := range items {
+		if action == ActionRemoved {
+			changes[i] = newItemChange(action, &items[i], nil)
+		} else {
+			changes[i] = newItemChange(action, nil, &items[i])
+		}
+	}
+	return changes
+}
+
+// updateChanges describes the updates that turned the items in before, by ID,
+// into after.
+func updateChanges(before map[string]Item, after []Item) []ItemChange {
+	changes := make([]ItemChange, 0, len(after))
+	for i := range after {
+		was, ok := before[after[i].ID]
+		if !ok {
+			continue
+		}
+		changes = append(changes, newItemChange(ActionUpdated, &was, &after[i]))
+	}
+	return changes
+}
+
+// itemsByID indexes items by their IDs.
+func itemsByID(items []Item) map[string]Item {
+	byID := make(map[string]Item, len(items))
+	for _, it := range items {
+		byID[it.ID] = it
+	}
+	return byID
+}
+
+// recordAudit writes an audit entry for each change. Like recordActivity, it
+// logs failures rather than failing the mutation.
+func (s *Service) recordAudit(ctx context.Context, changes ...ItemChange) {
+	if len(changes) == 0 {
+		return
+	}
+	actor, client, now := actorFromContext(ctx), clientFromContext(ctx), s.Now()
+	bw := s.client.BulkWriter(ctx)
+	jobs := make([]*firestore.BulkWriterJob, len(changes))
+	for i, c := range changes {
+		entry := AuditEntry{ID: s.NewID(), ItemID: c.ID, ItemName: c.Name, Action: c.Action, Fields: c.Fields, Actor: actor, Client: client, At: now, ExpireAt: s.retention.expireAt("audit", now)}
+		job, err := bw.Set(s.auditCollection().Doc(entry.ID), entry)
+		if err != nil {
+			log.Printf("warn: record audit of %q: %v", c.ID, err)
+			continue
+		}
+		jobs[i] = job
+	}
+	bw.End()
+	for i, job := range jobs {
+		if job == nil {
+			continue
+		}
+		if _, err := job.Results(); err != nil {
+			log.Printf("warn: record audit of %q: %v", changes[i].ID, err)
+		}
+	}
+}
+
+// ItemHistory returns up to limit of the item's audit entries, newest first.
+// An item's entries are found by an equality query and ordered after the
+// read, so no composite index is needed.
+func (s *Service) ItemHistory(ctx context.Context, id string, limit int) ([]AuditEntry, error) {
+	var docs []*firestore.DocumentSnapshot
+	err := s.retry(ctx, func() (err error) {
+		docs, err = s.auditCollection().Where("item_id", "==", id).Documents(ctx).GetAll()
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("retrieve item history: %w", err)
+	}
+	entries := make([]AuditEntry, 0, len(docs))
+	for _, d := range docs {
+		var e AuditEntry
+		if err := d.DataTo(&e); err != nil {
+			log.Printf("warn: unmarshal audit entry %q: %v", d.Ref.ID, err)
+			continue
+		}
+		entries = append(entries, e)
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].At.After(entries[j].At) })
+	if len(entries) > limit {
+		entries = entries[:limit]
+	}
+	return entries, nil
+}
@@ -0,0 +1,47 @@
+package shoppinglist
+
+import (
+	"context"
+	"testing"
+)
+
+func TestItemChangesForAudit(t *testing.T) {
+	items := []Item{{ID: "a", Name: "Milk"}, {ID: "b", Name: "Eggs", Purchased: true}}
+
+	added := itemChanges(ActionAdded, items)
+	if len(added) != 2 || added[0].ID != "a" || added[0].Action != ActionAdded {
+		t.Fatalf("unexpected added changes: %+v", added)
+	}
+	if f := added[1].Fields; len(f) != 2 || f[0].Field != "name" || f[0].Old != nil || f[1].Field != "purchased" || f[1].New != true {
+		t.Fatalf("expected added fields without old values, got %+v", f)
+	}
+
+	removed := itemChanges(ActionRemoved, items)
+	if f := removed[0].Fields; len(f) != 1 || f[0].Old != "Milk" || f[0].New != nil {
+		t.Fatalf("expected removed fields without new values, got %+v", f)
+	}
+}
+
+func TestUpdateChanges(t *testing.T) {
+	before := itemsByID([]Item{{ID: "a", Name: "Milk"}, {ID: "b", Name: "Eggs"}})
+	after := []Item{{ID: "a", Name: "Oat milk"}, {ID: "c", Name: "Bread"}}
+
+	changes := updateChanges(before, after)
+	if len(changes) != 1 {
+		t.Fatalf("expected only the item that existed before, got %+v", changes)
+	}
+	c := changes[0]
+	if c.ID != "a" || c.Action != ActionUpdated || len(c.Fields) != 1 || c.Fields[0].Old != "Milk" || c.Fields[0].New != "Oat milk" {
+		t.Fatalf("unexpected change %+v", c)
+	}
+}
+
+func TestClientFromContext(t *testing.T) {
+	if got := clientFromContext(context.Background()); got != "" {
+		t.Fatalf("expected no client, got %q", got)
+	}
+	ctx := WithClient(context.Background(), "claude-desktop 1.2.0")
+	if got := clientFromContext(ctx); got != "claude-desktop 1.2.0" {
+		t.Fatalf("clientFromContext = %q", got)
+	}
+}
@@ -0,0 +1,128 @@
+package shoppinglist
+
+import (
+	"context"
+
+	"cloud.google.com/go/firestore"
+)
+
+// -----------------------------------------------------------------------------
+// Batched writes
+// -----------------------------------------------------------------------------
+
+// maxBatchWrites is the most writes Firestore accepts in one commit.
+const maxBatchWrites = 500
+
+// batchWrite is one step of a writeBatch and the number of Firestore writes
+// it makes, so a deletion and its tombstone always land in the same commit.
+type batchWrite struct {
+	writes int
+	op     func(tx *firestore.Transaction) error
+}
+
+// writeBatch collects the writes of a multi-document mutation, such as
+// clearing purchased items, so they go out in as few commits as fit rather
+// than a round trip each, and mutations too big for one commit still go
+// through. Each commit is atomic; a mutation split over several is not.
+type writeBatch struct {
+	s      *Service
+	writes []batchWrite
+}
+
+// newBatch starts a batch of writes to the list.
+func (s *Service) newBatch() *writeBatch { return &writeBatch{s: s} }
+
+// Create adds the creation of the document at ref.
+func (b *writeBatch) Create(ref *firestore.DocumentRef, data any) {
+	b.add(1, func(tx *firestore.Transaction) error { return tx.Create(ref, data) })
+}
+
+// Set adds the replacement of the document at ref.
+func (b *writeBatch) Set(ref *firestore.DocumentRef, data any) {
+	b.add(1, func(tx *firestore.Transaction) error { return tx.Set(ref, data) })
+}
+
+// Update adds an update of the document at ref.
+func (b *writeBatch) Update(ref *firestore.DocumentRef, updates []firestore.Update) {
+	b.add(1, func(tx *firestore.Transaction) error { return tx.Update(ref, updates) })
+}
+
+// Delete adds the deletion of the document at ref, subject to preconds.
+func (b *writeBatch) Delete(ref *firestore.DocumentRef, preconds ...firestore.Precondition) {
+	b.add(1, func(tx *firestore.Transaction) error { return tx.Delete(ref, preconds...) })
+}
+
+// DeleteItem adds the deletion of the item at ref, with its tombstone.
+func (b *writeBatch) DeleteItem(ref *firestore.DocumentRef) {
+	b.add(2, func(tx *firestore.Transaction) error { return b.s.deleteItem(tx, ref) })
+}
+
+// TrashItem adds the move of the item at ref to the trash, with its
+// tombstone.
+func (b *writeBatch) TrashItem(ref *firestore.DocumentRef) {
+	b.add(2, func(tx *firestore.Transaction) error { return b.s.trashItem(tx, ref) })
+}
+
+func (b *writeBatch) add(writes int, op func(tx *firestore.Transaction) error) {
+	b.writes = append(b.writes, batchWrite{writes: writes, op: op})
+}
+
+// Apply makes as many of the batch's writes as fit in one commit in tx, the
+// transaction that read what they are based on, and returns a batch of the
+// rest for Commit to make once tx has committed. Writes are made in the order
+// they were added, so those that must be atomic with tx's reads go first.
+func (b *writeBatch) Apply(tx *firestore.Transaction) (*writeBatch, error) {
+	chunks := splitBatch(b.writes, maxBatchWrites)
+	if len(chunks) == 0 {
+		return nil, nil
+	}
+	for _, w := range chunks[0] {
+		if err := w.op(tx); err != nil {
+			return nil, err
+		}
+	}
+	return &writeBatch{s: b.s, writes: b.writes[len(chunks[0]):]}, nil
+}
+
+// Commit makes the batch's writes, in commits of at most maxBatchWrites
+// writes. When one fails, those before it stay made. A nil batch has none.
+func (b *writeBatch) Commit(ctx context.Context) error {
+	if b == nil {
+		return nil
+	}
+	for _, chunk := range splitBatch(b.writes, maxBatchWrites) {
+		err := b.s.runTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+			for _, w := range chunk {
+				if err := w.op(tx); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// splitBatch splits writes, in order, into chunks of at most max Firestore
+// writes each.
+func splitBatch(writes []batchWrite, max int) [][]batchWrite {
+	var (
+		chunks [][]batchWrite
+		start  int
+		n      int
+	)
+	for i, w := range writes {
+		if n+w.writes > max && i > start {
+			chunks = append(chunks, writes[start:i])
+			start, n = i, 0
+		}
+		n += w.writes
+	}
+	if start < len(writes) {
+		chunks = append(chunks, writes[start:])
+	}
+	return chunks
+}
@@ -0,0 +1,53 @@
+package shoppinglist
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSplitBatch(t *testing.T) {
+	writes := func(sizes ...int) []batchWrite {
+		out := make([]batchWrite, len(sizes))
+		for i, n := range sizes {
+			out[i].writes = n
+		}
+		return out
+	}
+	sizes := func(chunks [][]batchWrite) [][]int {
+		var out [][]int
+		for _, c := range chunks {
+			var s []int
+			for _, w := range c {
+				s = append(s, w.writes)
+			}
+			out = append(out, s)
+		}
+		return out
+	}
+
+	for _, tc := range []struct {
+		in   []batchWrite
+		want [][]int
+	}{
+		{nil, nil},
+		{writes(1, 2, 1), [][]int{{1, 2, 1}}},
+		{writes(1, 2, 2), [][]int{{1, 2}, {2}}},
+		{writes(2, 2, 2, 1, 1), [][]int{{2, 2}, {2, 1, 1}}},
+		{writes(5, 1), [][]int{{5}, {1}}},
+	} {
+		got := sizes(splitBatch(tc.in, 4))
+		if !slices.EqualFunc(got, tc.want, slices.Equal) {
+			t.Errorf("splitBatch(%v) = %v, want %v", sizes([][]batchWrite{tc.in}), got, tc.want)
+		}
+	}
+
+	// A page of items for ClearList, each moved to the trash with its
+	// tombstone, fits one commit.
+	page := make([]batchWrite, clearListPage)
+	for i := range page {
+		page[i].writes = 2
+	}
+	if n := len(splitBatch(page, maxBatchWrites)); n != 1 {
+		t.Fatalf("expected a page to fit one commit, got %d", n)
+	}
+}
@@ -0,0 +1,251 @@
+package shoppinglist
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+
+	"cloud.google.com/go/firestore"
+)
+
+// -----------------------------------------------------------------------------
+// Bulk adds
+// -----------------------------------------------------------------------------
+
+// BulkAddResult is the outcome for one input of a bulk add, by its position
+// in the request.
+type BulkAddResult struct {
+	Index int    `json:"index"`
+	Item  *Item  `json:"item,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// BulkAddResponse reports each input of a bulk add.
+type BulkAddResponse struct {
+	Results []BulkAddResult `json:"results"`
+	Added   int             `json:"added"`
+	Failed  int             `json:"failed"`
+	ResponseWarnings
+}
+
+// BulkAddItems creates an item for each input in one BulkWriter flush rather
+// than a round trip, and a list read, per item. A failed write does not stop
+// the others; its result carries the error instead of the item.
+func (s *Service) BulkAddItems(ctx context.Context, inputs []ItemInput) ([]BulkAddResult, error) {
+	if err := s.checkNotFrozen(ctx); err != nil {
+		return nil, err
+	}
+	col := s.client.Collection(s.collection)
+	results := make([]BulkAddResult, len(inputs))
+	items := make([]Item, len(inputs))
+	jobs := make([]*firestore.BulkWriterJob, len(inputs))
+
+	bw := s.client.BulkWriter(ctx)
+	for i, input := range inputs {
+		results[i].Index = i
+		if err := s.checkInput(&input); err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+		if input.ParentID != nil {
+			if err := s.validateParent(ctx, "", *input.ParentID); err != nil {
+				results[i].Error = err.Error()
+				continue
+			}
+		}
+		items[i] = s.newItem(input, s.writeTime())
+		ref := col.Doc(items[i].ID)
+		replace, err := s.overTrash(ctx, ref)
+		if err != nil {
+			results[i].Error = fmt.Sprintf("create item: %v", err)
+			continue
+		}
+		var job *firestore.BulkWriterJob
+		if replace {
+			job, err = bw.Set(ref, itemDoc(items[i]))
+		} else {
+			job, err = bw.Create(ref, itemDoc(items[i]))
+		}
+		if err != nil {
+			results[i].Error = fmt.Sprintf("create item: %v", err)
+			continue
+		}
+		jobs[i] = job
+	}
+	bw.End()
+
+	var added []Item
+	for i, job := range jobs {
+		if job == nil {
+			continue
+		}
+		written, err := job.Results()
+		if err != nil {
+			results[i].Error = fmt.Sprintf("create item: %v", err)
+			continue
+		}
+		s.stamped(&items[i], written.UpdateTime)
+		results[i].Item = &items[i]
+		added = append(added, items[i])
+	}
+	if len(added) > 0 {
+		s.observe(ctx, activityAdd, len(added))
+		s.recordActivity(ctx, ActionAdded, added...)
+		s.recordAudit(ctx, itemChanges(ActionAdded, added)...)
+	}
+	return results, nil
+}
+
+// NewBulkAddResponse counts the results of a bulk add.
+func NewBulkAddResponse(results []BulkAddResult) BulkAddResponse {
+	resp := BulkAddResponse{Results: results}
+	for _, r := range results {
+		if r.Error != "" {
+			resp.Failed++
+		} else {
+			resp.Added++
+		}
+	}
+	return resp
+}
+
+// bulkRemoveMax keeps a bulk removal of items without children within one
+// Firestore commit.
+const bulkRemoveMax = 250
+
+// BulkRemoveResponse reports which IDs a bulk removal deleted.
+type BulkRemoveResponse struct {
+	Deleted  []string `json:"deleted"`
+	NotFound []string `json:"not_found,omitempty"`
+	ResponseWarnings
+}
+
+// planBulkRemove works out which of items a bulk removal of ids deletes and
+// which children it promotes to top-level items.
+func planBulkRemove(items []Item, ids []string, cascade bool) (resp BulkRemoveResponse, removed []Item, promote []string) {
+	byID := make(map[string]Item, len(items))
+	for _, it := range items {
+		byID[it.ID] = it
+	}
+	deleting := map[string]bool{}
+	for _, id := range ids {
+		if it, ok := byID[id]; ok {
+			deleting[id] = true
+			removed = append(removed, it)
+			resp.Deleted = append(resp.Deleted, id)
+		} else {
+			resp.NotFound = append(resp.NotFound, id)
+		}
+	}
+	for _, it := range items {
+		if it.ParentID == nil || !deleting[*it.ParentID] || deleting[it.ID] {
+			continue
+		}
+		if cascade {
+			removed = append(removed, it)
+		} else {
+			promote = append(promote, it.ID)
+		}
+	}
+	return resp, removed, promote
+}
+
+// BulkRemoveItems deletes the items with the given IDs in one transaction and
+// reports which were deleted and which did not exist. Children of deleted
+// items are deleted too when cascade is set, and otherwise become top-level;
+// when they take the writes past one commit, the rest follow the transaction.
+func (s *Service) BulkRemoveItems(ctx context.Context, ids []string, cascade bool) (BulkRemoveResponse, error) {
+	ids = slices.Compact(slices.Sorted(slices.Values(ids)))
+	if len(ids) > bulkRemoveMax {
+		return BulkRemoveResponse{}, fmt.Errorf("at most %d items can be removed at once, got %d", bulkRemoveMax, len(ids))
+	}
+	col := s.client.Collection(s.collection)
+	var resp BulkRemoveResponse
+	var removed []Item
+	var rest *writeBatch
+	err := s.runTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		docs, err := tx.Documents(col).GetAll()
+		if err != nil {
+			return err
+		}
+		var promote []string
+		resp, removed, promote = planBulkRemove(decodeItems(docs), ids, cascade)
+		b := s.newBatch()
+		for _, id := range promote {
+			b.Update(col.Doc(id), withRevision([]firestore.Update{{Path: "parent_id", Value: firestore.Delete}}, s.writeTime()))
+		}
+		for _, it := range removed {
+			b.TrashItem(col.Doc(it.ID))
+		}
+		rest, err = b.Apply(tx)
+		return err
+	})
+	if err == nil {
+		err = rest.Commit(ctx)
+	}
+	if err != nil {
+		return BulkRemoveResponse{}, fmt.Errorf("remove items: %w", err)
+	}
+	if len(removed) > 0 {
+		s.observe(ctx, activityDelete, len(removed))
+		s.recordActivity(ctx, ActionRemoved, removed...)
+		s.recordAudit(ctx, itemChanges(ActionRemoved, removed)...)
+	}
+	return resp, nil
+}
+
+// clearListPage is how many items ClearList reads and deletes at a time, so
+// lists of any size are cleared without loading them whole. A page moved to
+// the trash, with its tombstones, fits one commit.
+const clearListPage = maxBatchWrites / 2
+
+// ClearListResponse reports how many items clearing a list deleted.
+type ClearListResponse struct {
+	Removed int `json:"removed"`
+	ResponseWarnings
+}
+
+// ClearList moves every item on the list to the trash a page at a time,
+// leaving tombstones so changes report them. It is not atomic: when it fails partway,
+// the response counts the items already deleted, and calling again removes
+// the rest.
+func (s *Service) ClearList(ctx context.Context) (ClearListResponse, error) {
+	// trashed items stay in the collection, so each round reads on from the
+	// last page by ID
+	q := s.client.Collection(s.collection).OrderBy(firestore.DocumentID, firestore.Asc).Limit(clearListPage)
+	var resp ClearListResponse
+	for {
+		docs, err := q.Documents(ctx).GetAll()
+		if err != nil {
+			return resp, fmt.Errorf("clear list: %w", err)
+		}
+		b := s.newBatch()
+		n := 0
+		for _, d := range docs {
+			if trashed(d) {
+				continue
+			}
+			var it Item
+			if err := d.DataTo(&it); err != nil {
+				log.Printf("warn: unmarshal item %q: %v", d.Ref.ID, err)
+				b.DeleteItem(d.Ref)
+			} else {
+				b.TrashItem(d.Ref)
+			}
+			n++
+		}
+		if err := b.Commit(ctx); err != nil {
+			return resp, fmt.Errorf("clear list: %w", err)
+		}
+		resp.Removed += n
+		if len(docs) < clearListPage {
+			break
+		}
+		q = q.StartAfter(docs[len(docs)-1])
+	}
+	if resp.Removed > 0 {
+		s.observe(ctx, activityDelete, resp.Removed)
+	}
+	return resp, nil
+}
@@ -0,0 +1,55 @@
+package shoppinglist
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestNewBulkAddResponseCounts(t *testing.T) {
+	resp := NewBulkAddResponse([]BulkAddResult{
+		{Index: 0, Item: &Item{ID: "a"}},
+		{Index: 1, Error: "create item: already exists"},
+		{Index: 2, Item: &Item{ID: "b"}},
+	})
+	if resp.Added != 2 || resp.Failed != 1 {
+		t.Errorf("added %d, failed %d; want 2 and 1", resp.Added, resp.Failed)
+	}
+}
+
+func TestNewItemFromInput(t *testing.T) {
+	now := time.Date(2025, 8, 12, 9, 0, 0, 0, time.UTC)
+	s := &Service{ids: &SequentialIDs{}}
+	qty, category, price := "500 g", "", 2.5
+	it := s.newItem(ItemInput{Name: "flour", Quantity: &qty, Category: &category, Price: &price, Currency: "EUR"}, now)
+	if it.ID != "00000000-0000-4000-8000-000000000001" || it.Revision != 1 || !it.CreatedAt.Equal(now) {
+		t.Errorf("item = %+v", it)
+	}
+	if it.Amount == nil || *it.Amount != 500 || it.Unit != "g" {
+		t.Errorf("amount = %v %q, want 500 g", it.Amount, it.Unit)
+	}
+	if it.Category != nil {
+		t.Errorf("empty category stored as %q", *it.Category)
+	}
+	if it.PriceCurrency != "EUR" {
+		t.Errorf("price currency = %q", it.PriceCurrency)
+	}
+}
+
+func TestPlanBulkRemove(t *testing.T) {
+	parent := "group"
+	items := []Item{{ID: "group"}, {ID: "salsa", ParentID: &parent}, {ID: "milk"}}
+
+	resp, removed, promote := planBulkRemove(items, []string{"gone", "group"}, false)
+	if !slices.Equal(resp.Deleted, []string{"group"}) || !slices.Equal(resp.NotFound, []string{"gone"}) {
+		t.Errorf("deleted %v, not found %v", resp.Deleted, resp.NotFound)
+	}
+	if len(removed) != 1 || !slices.Equal(promote, []string{"salsa"}) {
+		t.Errorf("removed %v, promoted %v; want the group removed and salsa promoted", removed, promote)
+	}
+
+	_, removed, promote = planBulkRemove(items, []string{"group"}, true)
+	if len(removed) != 2 || len(promote) != 0 {
+		t.Errorf("cascade removed %v, promoted %v; want the group and salsa removed", removed, promote)
+	}
+}
@@ -0,0 +1,106 @@
+package shoppinglist
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+)
+
+// -----------------------------------------------------------------------------
+// Categories
+// -----------------------------------------------------------------------------
+
+// uncategorized labels items without a category when grouping.
+const uncategorized = "uncategorized"
+
+// CategoryGroup is the items in one store section.
+type CategoryGroup struct {
+	Category string `json:"category"`
+	Items    []Item `json:"items"`
+}
+
+// CategorizedItemsResponse wraps a list grouped by category.
+type CategorizedItemsResponse struct {
+	Categories []CategoryGroup `json:"categories"`
+	ReadTime   *time.Time      `json:"read_time,omitempty"`
+	Display    *Display        `json:"display,omitempty"`
+	ResponseWarnings
+}
+
+// NormalizeCategory lower-cases a category and collapses its whitespace, so
+// "Dairy " and "dairy" land in the same group.
+func NormalizeCategory(s string) string {
+	return strings.ToLower(strings.Join(strings.Fields(s), " "))
+}
+
+// nonEmpty returns nil for a nil or empty string.
+func nonEmpty(p *string) *string {
+	if p == nil || *p == "" {
+		return nil
+	}
+	return p
+}
+
+// FilterCategory keeps the items in category, compared normalized;
+// "uncategorized" keeps the items without one.
+func FilterCategory(items []Item, category string) []Item {
+	category = NormalizeCategory(category)
+	out := make([]Item, 0, len(items))
+	for _, it := range items {
+		if categoryOf(it) == category {
+			out = append(out, it)
+		}
+	}
+	return out
+}
+
+// ViewCategory reads like View only the items in category, selected by a
+// Firestore equality query on the normalized name. Items without a category
+// are not indexed on the field, so "uncategorized" reads the whole list and
+// keeps them.
+func (s *Service) ViewCategory(ctx context.Context, category string) (ListView, error) {
+	category = NormalizeCategory(category)
+	if category == uncategorized {
+		view, err := s.View(ctx)
+		view.Items = FilterCategory(view.Items, category)
+		return view, err
+	}
+	return s.viewOf(ctx, s.client.Collection(s.collection).Where("category", "==", category), 0)
+}
+
+// categoryOf returns the item's category, or uncategorized.
+func categoryOf(it Item) string {
+	if it.Category == nil || *it.Category == "" {
+		return uncategorized
+	}
+	return *it.Category
+}
+
+// GroupByCategory groups items by category in alphabetical order, with
+// uncategorized items last. Items keep their order within a group.
+func GroupByCategory(items []Item) []CategoryGroup {
+	index := map[string]int{}
+	var groups []CategoryGroup
+	for _, it := range items {
+		c := categoryOf(it)
+		i, ok := index[c]
+		if !ok {
+			i = len(groups)
+			index[c] = i
+			groups = append(groups, CategoryGroup{Category: c})
+		}
+		groups[i].Items = append(groups[i].Items, it)
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		a, b := groups[i].Category, groups[j].Category
+		if (a == uncategorized) != (b == uncategorized) {
+			return b == uncategorized
+		}
+		return a < b
+	})
+	if groups == nil {
+		groups = []CategoryGroup{}
+	}
+	return groups
+}
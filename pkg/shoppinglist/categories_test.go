@@ -0,0 +1,60 @@
+package shoppinglist
+
+import "testing"
+
+func TestGroupByCategory(t *testing.T) {
+	dairy, produce := "dairy", "produce"
+	items := []Item{
+		{ID: "1", Name: "milk", Category: &dairy},
+		{ID: "2", Name: "foil"},
+		{ID: "3", Name: "apples", Category: &produce},
+		{ID: "4", Name: "butter", Category: &dairy},
+	}
+	groups := GroupByCategory(items)
+	if len(groups) != 3 {
+		t.Fatalf("expected 3 groups, got %+v", groups)
+	}
+	want := []struct {
+		category string
+		ids      []string
+	}{
+		{"dairy", []string{"1", "4"}},
+		{"produce", []string{"3"}},
+		{uncategorized, []string{"2"}},
+	}
+	for i, w := range want {
+		g := groups[i]
+		if g.Category != w.category || len(g.Items) != len(w.ids) {
+			t.Fatalf("group %d = %+v, want %s with %v", i, g, w.category, w.ids)
+		}
+		for j, id := range w.ids {
+			if g.Items[j].ID != id {
+				t.Fatalf("group %s item %d = %s, want %s", g.Category, j, g.Items[j].ID, id)
+			}
+		}
+	}
+}
+
+func TestGroupByCategoryEmpty(t *testing.T) {
+	if groups := GroupByCategory(nil); groups == nil || len(groups) != 0 {
+		t.Fatalf("expected an empty, non-nil slice, got %#v", groups)
+	}
+}
+
+func TestNormalizeCategory(t *testing.T) {
+	if got := NormalizeCategory("  Frozen   Foods "); got != "frozen foods" {
+		t.Fatalf("normalizeCategory = %q", got)
+	}
+}
+
+func TestFilterCategoryMatchesNormalizedAndUncategorized(t *testing.T) {
+	produce := "produce"
+	items := []Item{{ID: "a", Category: &produce}, {ID: "b"}, {ID: "c", Category: &produce}}
+
+	if got := FilterCategory(items, " Produce"); len(got) != 2 || got[1].ID != "c" {
+		t.Fatalf("expected both produce items, got %v", got)
+	}
+	if got := FilterCategory(items, "uncategorized"); len(got) != 1 || got[0].ID != "b" {
+		t.Fatalf("expected the uncategorized item, got %v", got)
+	}
+}
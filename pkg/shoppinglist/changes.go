@@ -0,0 +1,136 @@
+package shoppinglist
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"cloud.google.com/go/firestore"
+)
+
+// -----------------------------------------------------------------------------
+// Incremental changes
+// -----------------------------------------------------------------------------
+
+// MaxChanges is the most changed items, and separately the most deletions,
+// one changes response returns before it asks for a resync.
+const MaxChanges = 500
+
+// Tombstone marks an item deleted from a list, so clients that synced before
+// the deletion can drop their copy.
+type Tombstone struct {
+	ID        string    `json:"id" firestore:"id"`
+	DeletedAt time.Time `json:"deleted_at" firestore:"deleted_at"`
+
+	ExpireAt *time.Time `json:"-" firestore:"expire_at,omitempty"`
+}
+
+// ChangesResponse is what happened to a list's items since a time. When
+// Resync is set the changes are incomplete and the client should fetch the
+// whole list instead; AsOf is the time to pass as since on the next call.
+type ChangesResponse struct {
+	Since   time.Time   `json:"since"`
+	AsOf    time.Time   `json:"as_of"`
+	Created []Item      `json:"created"`
+	Updated []Item      `json:"updated"`
+	Deleted []Tombstone `json:"deleted"`
+	Resync  bool        `json:"resync,omitempty"`
+	Reason  string      `json:"resync_reason,omitempty"`
+	ResponseWarnings
+}
+
+// tombstonesOf holds the tombstones of the items in col.
+func (s *Service) tombstonesOf(col *firestore.CollectionRef) *firestore.CollectionRef {
+	return s.client.Collection(col.ID + "_tombstones")
+}
+
+// deleteItem deletes the item at ref in tx, subject to preconds, and leaves a
+// tombstone in its place. Every item deletion goes through it so changes can
+// report it.
+func (s *Service) deleteItem(tx *firestore.Transaction, ref *firestore.DocumentRef, preconds ...firestore.Precondition) error {
+	if err := tx.Delete(ref, preconds...); err != nil {
+		return err
+	}
+	return s.leaveTombstone(tx, ref)
+}
+
+// leaveTombstone records in tx that the item at ref was deleted.
+func (s *Service) leaveTombstone(tx *firestore.Transaction, ref *firestore.DocumentRef) error {
+	t := Tombstone{ID: ref.ID, DeletedAt: s.writeTime(), ExpireAt: s.retention.expireAt("tombstones", s.Now())}
+	return tx.Set(s.tombstonesOf(ref.Parent).Doc(ref.ID), tombstoneDoc(t))
+}
+
+// Changes returns the items created or updated at or after since and the
+// items deleted since, read at one point in time. Items last written before
+// they were stamped with updated_at are not reported until written again.
+func (s *Service) Changes(ctx context.Context, since time.Time) (ChangesResponse, error) {
+	resp := ChangesResponse{Since: since, AsOf: s.Now(), Created: []Item{}, Updated: []Item{}, Deleted: []Tombstone{}}
+	if days, ok := s.retention["tombstones"]; ok && since.Before(resp.AsOf.AddDate(0, 0, -days)) {
+		resp.Resync, resp.Reason = true, fmt.Sprintf("deletions are only kept for %d days", days)
+		return resp, nil
+	}
+
+	col := s.client.Collection(s.collection)
+	var (
+		items      []Item
+		tombstones []Tombstone
+		read       []*firestore.DocumentSnapshot
+	)
+	err := s.readConsistent(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		docs, err := tx.Documents(col.Where("updated_at", ">=", since).OrderBy("updated_at", firestore.Asc).Limit(MaxChanges + 1)).GetAll()
+		if err != nil {
+			return err
+		}
+		items, read = decodeItems(docs), docs
+
+		docs, err = tx.Documents(s.tombstonesOf(col).Where("deleted_at", ">=", since).OrderBy("deleted_at", firestore.Asc).Limit(MaxChanges + 1)).GetAll()
+		if err != nil {
+			return err
+		}
+		read = append(read, docs...)
+		tombstones = make([]Tombstone, 0, len(docs))
+		for _, d := range docs {
+			var t Tombstone
+			if err := d.DataTo(&t); err != nil {
+				return fmt.Errorf("decode tombstone %s: %w", d.Ref.ID, err)
+			}
+			tombstones = append(tombstones, t)
+		}
+		return nil
+	})
+	if err != nil {
+		return ChangesResponse{}, fmt.Errorf("retrieve changes: %w", err)
+	}
+	if s.writeTime().IsZero() {
+		// Items are stamped by Firestore, so the next call picks up from
+		// its clock rather than the host's.
+		resp.AsOf = s.readTimeOf(read)
+	}
+	if len(items) > MaxChanges || len(tombstones) > MaxChanges {
+		resp.Resync, resp.Reason = true, fmt.Sprintf("more than %d items changed", MaxChanges)
+		return resp, nil
+	}
+	splitChanges(&resp, items, tombstones)
+	return resp, nil
+}
+
+// splitChanges sorts items into created and updated by whether they were
+// created at or after resp.Since, and keeps the tombstones of items that
+// have not been added back since.
+func splitChanges(resp *ChangesResponse, items []Item, tombstones []Tombstone) {
+	for _, it := range items {
+		if it.CreatedAt.Before(resp.Since) {
+			resp.Updated = append(resp.Updated, it)
+		} else {
+			resp.Created = append(resp.Created, it)
+		}
+	}
+	for _, t := range tombstones {
+		// An item written after its deletion was added back, e.g. moved
+		// away and back again, and is reported as it is now.
+		if !slices.ContainsFunc(items, func(it Item) bool { return it.ID == t.ID }) {
+			resp.Deleted = append(resp.Deleted, t)
+		}
+	}
+}
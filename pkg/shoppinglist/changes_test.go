@@ -0,0 +1,30 @@
+package shoppinglist
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSplitChanges(t *testing.T) {
+	since := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	items := []Item{
+		{ID: "milk", CreatedAt: since.Add(-time.Hour)},
+		{ID: "eggs", CreatedAt: since.Add(time.Minute)},
+	}
+	tombstones := []Tombstone{
+		{ID: "bread", DeletedAt: since.Add(time.Minute)},
+		{ID: "eggs", DeletedAt: since},
+	}
+
+	resp := ChangesResponse{Since: since}
+	splitChanges(&resp, items, tombstones)
+	if len(resp.Updated) != 1 || resp.Updated[0].ID != "milk" {
+		t.Fatalf("expected milk to be updated, got %+v", resp.Updated)
+	}
+	if len(resp.Created) != 1 || resp.Created[0].ID != "eggs" {
+		t.Fatalf("expected eggs to be created, got %+v", resp.Created)
+	}
+	if len(resp.Deleted) != 1 || resp.Deleted[0].ID != "bread" {
+		t.Fatalf("expected only bread to be reported deleted, got %+v", resp.Deleted)
+	}
+}
@@ -0,0 +1,55 @@
+package shoppinglist
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// -----------------------------------------------------------------------------
+// Markdown checklist
+// -----------------------------------------------------------------------------
+
+// RenderChecklist writes items as a Markdown checklist with a heading per
+// store section, uncategorized items last under "Other", and checked-off
+// items ticked, for chat clients to show as is.
+func RenderChecklist(items []Item) string {
+	if len(items) == 0 {
+		return "_The list is empty._\n"
+	}
+	var b strings.Builder
+	for i, g := range GroupByCategory(items) {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "## %s\n\n", sectionTitle(g.Category))
+		for _, it := range g.Items {
+			box := "[ ]"
+			if it.Purchased {
+				box = "[x]"
+			}
+			fmt.Fprintf(&b, "- %s %s", box, it.Name)
+			if q := deref(it.Quantity); q != "" {
+				fmt.Fprintf(&b, " (%s)", q)
+			}
+			if priorityOf(it) == PriorityHigh {
+				b.WriteString(" **!**")
+			}
+			if it.NeededBy != nil {
+				fmt.Fprintf(&b, " — by %s", it.NeededBy.Format("Jan 2"))
+			}
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+// sectionTitle capitalizes a category for a heading.
+func sectionTitle(category string) string {
+	if category == uncategorized {
+		return "Other"
+	}
+	r, n := utf8.DecodeRuneInString(category)
+	return string(unicode.ToUpper(r)) + category[n:]
+}
@@ -0,0 +1,19 @@
+package shoppinglist
+
+import "testing"
+
+func TestRenderChecklistGroupsBySection(t *testing.T) {
+	produce, two, high := "produce", "2", PriorityHigh
+	items := []Item{
+		{Name: "batteries"},
+		{Name: "apples", Quantity: &two, Category: &produce, Priority: &high},
+		{Name: "pears", Category: &produce, Purchased: true},
+	}
+	want := "## Produce\n\n- [ ] apples (2) **!**\n- [x] pears\n\n## Other\n\n- [ ] batteries\n"
+	if got := RenderChecklist(items); got != want {
+		t.Fatalf("got:\n%s\nwant:\n%s", got, want)
+	}
+	if got := RenderChecklist(nil); got != "_The list is empty._\n" {
+		t.Fatalf("unexpected empty checklist %q", got)
+	}
+}
@@ -0,0 +1,82 @@
+package shoppinglist
+
+import (
+	"context"
+	"time"
+)
+
+// -----------------------------------------------------------------------------
+// Client interface
+// -----------------------------------------------------------------------------
+
+// Client is the typed interface to a household's shopping lists. *Service
+// implements it against Firestore; programs can depend on Client and
+// substitute a fake in their tests.
+type Client interface {
+	// Lists returns all lists, the main list first.
+	Lists(ctx context.Context) ([]ListInfo, error)
+	// List returns a client for the list with the given ID, slug, or former
+	// slug; an empty ref is the main list.
+	List(ctx context.Context, ref string) (Client, error)
+	CreateList(ctx context.Context, name string) (ListInfo, error)
+	RenameList(ctx context.Context, ref, name string) (ListInfo, error)
+
+	ListItems(ctx context.Context) ([]Item, error)
+	GetItem(ctx context.Context, id string) (*Item, error)
+	SearchItems(ctx context.Context, query string, limit int) (SearchItemsResponse, error)
+	UpsertItem(ctx context.Context, input ItemInput) (ItemChange, []Item, error)
+	UpsertItemOnly(ctx context.Context, input ItemInput) (ItemChange, *Item, ResponseWarnings, error)
+	BulkAddItems(ctx context.Context, inputs []ItemInput) ([]BulkAddResult, error)
+	ImportItems(ctx context.Context, id string, inputs []ItemInput, progress ImportProgressFunc) (ImportProgress, error)
+	RenameItem(ctx context.Context, id, name string) (*Item, error)
+	RemoveItem(ctx context.Context, id string, cascade bool) ([]ItemChange, []Item, error)
+	RemoveItemIf(ctx context.Context, id string, cascade bool, pre Precondition) ([]ItemChange, []Item, error)
+	RemoveItemOnly(ctx context.Context, id string, cascade bool, pre Precondition) ([]ItemChange, error)
+	RemoveItemByName(ctx context.Context, embedder Embedder, name string, threshold float64, cascade bool) (RemoveByNameResponse, error)
+	BulkRemoveItems(ctx context.Context, ids []string, cascade bool) (BulkRemoveResponse, error)
+	ReorderItems(ctx context.Context, ids []string) ([]Item, error)
+	MoveItem(ctx context.Context, id, to string) (MoveItemResponse, error)
+	MarkPurchased(ctx context.Context, id string, purchased *bool, count *float64) (*Item, error)
+	TogglePurchased(ctx context.Context, id string) (ToggleResponse, error)
+	ClearPurchased(ctx context.Context, archive bool) (ClearPurchasedResponse, error)
+	ClearList(ctx context.Context) (ClearListResponse, error)
+	StartShopping(ctx context.Context, length time.Duration, store string) (*ShoppingSession, error)
+	ShoppingSession(ctx context.Context) (*ShoppingSession, error)
+	FinishShopping(ctx context.Context, rates ExchangeRateSource, currency string) (FinishShoppingResponse, error)
+	AdjustQuantity(ctx context.Context, id string, delta float64) (*Item, error)
+	AddInbound(ctx context.Context, items []InboundItem) (InboundResult, error)
+	AddRecipe(ctx context.Context, recipe string, ingredients []RecipeIngredient) (RecipeResponse, error)
+	RemoveRecipe(ctx context.Context, recipe string) (RecipeResponse, error)
+	MergeEdits(ctx context.Context, edits []ItemEdit) ([]MergeResult, error)
+
+	FreezeList(ctx context.Context, until time.Time, reason string) (*ListFreeze, error)
+	UnfreezeList(ctx context.Context) error
+	SetQuantityMode(ctx context.Context, mode string) (ListSettings, error)
+	RecentActivity(ctx context.Context, limit int, since time.Time) ([]ActivityEntry, error)
+	Rollover(ctx context.Context, template []TemplateItem, scheduledFor time.Time) (RolloverSummary, error)
+	UsageReport(ctx context.Context) (UsageReport, error)
+
+	// Close releases the underlying connections. Clients returned by List
+	// share them with the client they came from, so closing one of those
+	// does nothing.
+	Close() error
+}
+
+var _ Client = (*Service)(nil)
+
+// List returns a service scoped to the list with the given ID, slug, or
+// former slug; an empty ref is the main list.
+func (s *Service) List(ctx context.Context, ref string) (Client, error) {
+	if ref == "" {
+		return s.Root(), nil
+	}
+	list, err := s.ResolveList(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	scoped, err := s.ForList(list)
+	if err != nil {
+		return nil, err
+	}
+	return scoped, nil
+}
@@ -0,0 +1,19 @@
+package shoppinglist
+
+import (
+	"context"
+	"testing"
+)
+
+func TestListWithoutRefIsMainList(t *testing.T) {
+	root := &Service{collection: "shopping"}
+	scoped := &Service{collection: "shopping_list_abc", parent: root}
+
+	got, err := scoped.List(context.Background(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != Client(root) {
+		t.Errorf("List(\"\") = %v, want the main list's service", got)
+	}
+}
@@ -0,0 +1,66 @@
+package shoppinglist
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFrozenClock(t *testing.T) {
+	start := time.Date(2025, 8, 12, 9, 0, 0, 0, time.UTC)
+	c := NewFrozenClock(start)
+	if !c.Now().Equal(start) || !c.Now().Equal(c.Now()) {
+		t.Fatalf("expected the clock to stay at %v, got %v", start, c.Now())
+	}
+	c.Advance(90 * time.Minute)
+	if want := start.Add(90 * time.Minute); !c.Now().Equal(want) {
+		t.Fatalf("expected %v after Advance, got %v", want, c.Now())
+	}
+	c.Set(start)
+	if !c.Now().Equal(start) {
+		t.Fatalf("expected %v after Set, got %v", start, c.Now())
+	}
+}
+
+func TestSequentialIDs(t *testing.T) {
+	var a, b SequentialIDs
+	first, second := a.NewID(), a.NewID()
+	if first != "00000000-0000-4000-8000-000000000001" || second != "00000000-0000-4000-8000-000000000002" {
+		t.Fatalf("unexpected IDs %q, %q", first, second)
+	}
+	if b.NewID() != first {
+		t.Fatal("expected a new generator to repeat the sequence")
+	}
+}
+
+func TestStaleSnapshotAgesWithServiceClock(t *testing.T) {
+	start := time.Date(2025, 8, 12, 9, 0, 0, 0, time.UTC)
+	c := NewFrozenClock(start)
+	s := &Service{clock: c, snapshot: &listSnapshot{}}
+	s.snapshot.store([]Item{{ID: "1", Name: "milk"}}, s.Now())
+
+	c.Advance(5 * time.Minute)
+	if _, _, ok := s.snapshot.load(s.Now(), 10*time.Minute); !ok {
+		t.Fatal("expected the snapshot to be served within its max age")
+	}
+	c.Advance(10 * time.Minute)
+	if _, _, ok := s.snapshot.load(s.Now(), 10*time.Minute); ok {
+		t.Fatal("expected the snapshot to be too old")
+	}
+}
+
+func TestDerivedItemID(t *testing.T) {
+	n, err := ParseNormalizer(DefaultNormalization, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	milk := DerivedItemID("shopping", n, "Milk")
+	if milk != DerivedItemID("shopping", n, "  milk ") {
+		t.Fatal("expected names with the same key to get the same ID")
+	}
+	if milk == DerivedItemID("shopping_list_1", n, "milk") {
+		t.Fatal("expected the same name on another list to get another ID")
+	}
+	if milk == DerivedItemID("shopping", n, "eggs") {
+		t.Fatal("expected different names to get different IDs")
+	}
+}
@@ -0,0 +1,74 @@
+package shoppinglist
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// -----------------------------------------------------------------------------
+// Optimistic concurrency
+// -----------------------------------------------------------------------------
+
+// ErrItemConflict is returned when a write's precondition fails because the
+// item was changed or removed since the caller read it.
+var ErrItemConflict = errors.New("item was changed since it was read")
+
+// Precondition guards a write to an item so that two agents editing the same
+// list cannot silently overwrite each other: it only goes ahead when the item
+// is still as the caller last read it. The zero value guards nothing.
+type Precondition struct {
+	// UnmodifiedSince is the item's updated_at as the caller read it.
+	UnmodifiedSince *time.Time
+	// Revision is the item's revision as the caller read it.
+	Revision *int64
+}
+
+// IsZero reports whether p guards nothing.
+func (p Precondition) IsZero() bool { return p.UnmodifiedSince == nil && p.Revision == nil }
+
+// check returns an error wrapping ErrItemConflict unless it, whose document
+// was last written at updateTime, still satisfies p. Items written before
+// they were stamped with updated_at are judged by their update time.
+func (p Precondition) check(it Item, updateTime time.Time) error {
+	if p.Revision != nil && it.Revision != *p.Revision {
+		return fmt.Errorf("%w: %q is at revision %d, not %d", ErrItemConflict, it.ID, it.Revision, *p.Revision)
+	}
+	if p.UnmodifiedSince != nil {
+		modified := updateTime
+		if it.UpdatedAt != nil {
+			modified = *it.UpdatedAt
+		}
+		if modified.After(*p.UnmodifiedSince) {
+			return fmt.Errorf("%w: %q was updated at %s", ErrItemConflict, it.ID, modified.Format(time.RFC3339Nano))
+		}
+	}
+	return nil
+}
+
+// preconditions checks p against the item read as doc and returns the
+// Firestore precondition that keeps the write from landing if the item is
+// written again before it. It returns none when p is zero, so unguarded
+// writes behave as before.
+func (p Precondition) preconditions(it Item, doc *firestore.DocumentSnapshot) ([]firestore.Precondition, error) {
+	if p.IsZero() {
+		return nil, nil
+	}
+	if err := p.check(it, doc.UpdateTime); err != nil {
+		return nil, err
+	}
+	return []firestore.Precondition{firestore.LastUpdateTime(doc.UpdateTime)}, nil
+}
+
+// conflictOnPrecondition reports a failed Firestore precondition, a write
+// that raced another since the item was checked, as ErrItemConflict.
+func conflictOnPrecondition(id string, err error) error {
+	if status.Code(err) == codes.FailedPrecondition {
+		return fmt.Errorf("%w: %q was written by someone else at the same time", ErrItemConflict, id)
+	}
+	return err
+}
@@ -0,0 +1,40 @@
+package shoppinglist
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPreconditionCheck(t *testing.T) {
+	read := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	later := read.Add(time.Second)
+	rev := int64(3)
+	it := Item{ID: "milk", Revision: 3, UpdatedAt: &read}
+
+	if err := (Precondition{UnmodifiedSince: &read, Revision: &rev}).check(it, later); err != nil {
+		t.Fatalf("expected an unchanged item to pass, got %v", err)
+	}
+
+	changed := it
+	changed.UpdatedAt, changed.Revision = &later, 4
+	if err := (Precondition{UnmodifiedSince: &read}).check(changed, later); !errors.Is(err, ErrItemConflict) {
+		t.Fatalf("expected a conflict for a later updated_at, got %v", err)
+	}
+	if err := (Precondition{Revision: &rev}).check(changed, later); !errors.Is(err, ErrItemConflict) {
+		t.Fatalf("expected a conflict for another revision, got %v", err)
+	}
+
+	// Items not yet stamped with updated_at are judged by their update time.
+	legacy := Item{ID: "eggs", Revision: 3}
+	if err := (Precondition{UnmodifiedSince: &read}).check(legacy, later); !errors.Is(err, ErrItemConflict) {
+		t.Fatalf("expected a conflict from the update time, got %v", err)
+	}
+	if err := (Precondition{UnmodifiedSince: &later}).check(legacy, later); err != nil {
+		t.Fatalf("expected an item unchanged since its update time to pass, got %v", err)
+	}
+
+	if !(Precondition{}).IsZero() || (Precondition{Revision: &rev}).IsZero() {
+		t.Fatal("unexpected IsZero")
+	}
+}
@@ -0,0 +1,158 @@
+package shoppinglist
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"cloud.google.com/go/firestore"
+)
+
+// -----------------------------------------------------------------------------
+// Consistent reads
+// -----------------------------------------------------------------------------
+
+// ListView is the list as read at a single point in time: its items and the
+// freeze in effect come from the same read-only transaction, so they agree
+// even while writes land.
+type ListView struct {
+	Items    []Item
+	Freeze   *ListFreeze
+	ReadTime time.Time
+}
+
+// readConsistent runs fn in a read-only transaction, so every query it issues
+// observes the same snapshot of the database.
+func (s *Service) readConsistent(ctx context.Context, fn func(ctx context.Context, tx *firestore.Transaction) error) error {
+	return s.runTransaction(ctx, fn, firestore.ReadOnly)
+}
+
+// View reads the items and freeze state of the list at one read time, from
+// the cache when the list is cached.
+func (s *Service) View(ctx context.Context) (ListView, error) {
+	if view, ok := s.cachedView(ctx); ok {
+		s.snapshot.store(view.Items, view.ReadTime)
+		return view, nil
+	}
+	view, err := s.viewOf(ctx, s.client.Collection(s.collection).Query, 0)
+	if err != nil {
+		return ListView{}, err
+	}
+	s.snapshot.store(view.Items, view.ReadTime)
+	return view, nil
+}
+
+// viewOf reads the items q selects and the freeze state at one read time, in
+// list order.
+func (s *Service) viewOf(ctx context.Context, q firestore.Query, limit int) (ListView, error) {
+	view, err := s.viewQuery(ctx, q, limit)
+	if err != nil {
+		return ListView{}, err
+	}
+	sortItems(view.Items)
+	return view, nil
+}
+
+// viewQuery reads the items q selects and the freeze state at one read time,
+// in the order of q. A limit above zero keeps the first limit items of q,
+// which must then be ordered, not counting those in the trash.
+func (s *Service) viewQuery(ctx context.Context, q firestore.Query, limit int) (ListView, error) {
+	var (
+		view   ListView
+		legacy []legacyItem
+	)
+	err := s.readConsistent(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		var (
+			docs []*firestore.DocumentSnapshot
+			err  error
+		)
+		if limit > 0 {
+			docs, err = liveDocuments(ctx, tx, q, limit)
+		} else {
+			docs, err = tx.Documents(q).GetAll()
+		}
+		if err != nil {
+			return fmt.Errorf("retrieve items: %w", err)
+		}
+		freeze, err := decodeFreeze(tx.Get(s.metaCollection().Doc(freezeDocID)))
+		if err != nil {
+			return err
+		}
+		view = ListView{Items: decodeItems(docs), Freeze: freeze, ReadTime: s.readTimeOf(docs)}
+		legacy = s.upgradeQuantities(docs, view.Items)
+		return nil
+	})
+	if err != nil {
+		return ListView{}, err
+	}
+	s.writeBackQuantities(ctx, legacy)
+	if !view.Freeze.Active(view.ReadTime) {
+		view.Freeze = nil
+	}
+	return view, nil
+}
+
+// readTimeOf returns the time the documents were read, or now for an empty read.
+func (s *Service) readTimeOf(docs []*firestore.DocumentSnapshot) time.Time {
+	for _, d := range docs {
+		if !d.ReadTime.IsZero() {
+			return d.ReadTime.UTC()
+		}
+	}
+	return s.Now()
+}
+
+// ItemsAt reads the items as they were at readTime, so callers paging through
+// an earlier view see the same data. Firestore serves reads up to an hour old;
+// a cached view's items are served from the cache while they are unchanged.
+func (s *Service) ItemsAt(ctx context.Context, readTime time.Time) ([]Item, error) {
+	if items, ok := s.cache.at(readTime); ok {
+		return items, nil
+	}
+	var docs []*firestore.DocumentSnapshot
+	err := s.retry(ctx, func() (err error) {
+		docs, err = s.client.Collection(s.collection).WithReadOptions(firestore.ReadTime(readTime)).Documents(ctx).GetAll()
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("retrieve items at %s: %w", readTime.Format(time.RFC3339Nano), err)
+	}
+	return decodeItems(docs), nil
+}
+
+// OrderFields are the fields ViewOrdered sorts by.
+var OrderFields = []string{"name", "created_at", "priority", "category"}
+
+// ViewOrdered reads the list like View, sorted by field in direction asc
+// (the default) or desc. Names and creation times are ordered by Firestore
+// with an OrderBy clause, names comparing as stored so capitalized names
+// sort first. Items without a priority or category are left out of a
+// Firestore ordering on that field, and priorities do not rank
+// alphabetically, so those two are sorted after the read. A limit above zero
+// keeps only the first limit items, read with a Firestore Limit clause for
+// names and creation times so the rest of the list is not read.
+func (s *Service) ViewOrdered(ctx context.Context, field, direction string, limit int) (ListView, error) {
+	if !slices.Contains(OrderFields, field) {
+		return ListView{}, fmt.Errorf("unsupported order_by %q", field)
+	}
+	switch field {
+	case "name", "created_at":
+		dir := firestore.Asc
+		if direction == "desc" {
+			dir = firestore.Desc
+		}
+		q := s.client.Collection(s.collection).OrderBy(field, dir).OrderBy(firestore.DocumentID, dir)
+		return s.viewQuery(ctx, q, limit)
+	default:
+		view, err := s.View(ctx)
+		if err != nil {
+			return ListView{}, err
+		}
+		view.Items = SortItems(view.Items, field, direction)
+		if limit > 0 {
+			view.Items = view.Items[:min(len(view.Items), limit)]
+		}
+		return view, nil
+	}
+}
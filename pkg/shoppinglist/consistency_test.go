@@ -0,0 +1,137 @@
+package shoppinglist
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/firestore"
+)
+
+func TestReadTimeOfUsesDocumentReadTime(t *testing.T) {
+	at := time.Date(2025, 8, 12, 14, 31, 42, 0, time.UTC)
+	docs := []*firestore.DocumentSnapshot{{ReadTime: at}, {ReadTime: at}}
+
+	s := &Service{clock: SystemClock{}}
+	if got := s.readTimeOf(docs); !got.Equal(at) {
+		t.Fatalf("expected %v, got %v", at, got)
+	}
+}
+
+func TestReadTimeOfEmptyReadIsNow(t *testing.T) {
+	now := time.Date(2025, 8, 12, 9, 0, 0, 0, time.UTC)
+	s := &Service{clock: NewFrozenClock(now)}
+	if got := s.readTimeOf(nil); !got.Equal(now) {
+		t.Fatalf("expected the service's time %v, got %v", now, got)
+	}
+}
+
+func TestItemsAfterReadsAtCommitTime(t *testing.T) {
+	commit := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	caching := &itemCaching{commits: map[string]time.Time{}}
+	s := &Service{clock: SystemClock{}, snapshot: &listSnapshot{}, caching: caching, cache: newItemCache(caching)}
+	// Only a read at the commit time is served without Firestore, which this
+	// service has no client for.
+	s.cache.setItems([]Item{{ID: "b", CreatedAt: commit}, {ID: "a", CreatedAt: commit.Add(-time.Hour)}}, commit)
+
+	items, err := s.itemsAfter(context.Background(), &firestore.WriteResult{UpdateTime: commit})
+	if err != nil {
+		t.Fatalf("itemsAfter returned error: %v", err)
+	}
+	if len(items) != 2 || items[0].ID != "a" || items[1].ID != "b" {
+		t.Fatalf("expected the items at the commit time in list order, got %+v", items)
+	}
+	if _, at, ok := s.snapshot.load(commit, time.Minute); !ok || !at.Equal(commit) {
+		t.Fatalf("expected the snapshot to be taken at the commit time, got %v", at)
+	}
+}
+
+func TestViewOrderedLimitKeepsTopItems(t *testing.T) {
+	// The list is served from the cache, without a listener or Firestore;
+	// the client only names collections.
+	t.Setenv("FIRESTORE_EMULATOR_HOST", "127.0.0.1:1")
+	client, err := firestore.NewClient(context.Background(), "test-project")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	at := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	caching := &itemCaching{ctx: context.Background(), commits: map[string]time.Time{}}
+	s := &Service{client: client, collection: "shopping", clock: NewFrozenClock(at), snapshot: &listSnapshot{}, caching: caching, cache: newItemCache(caching)}
+	s.cache.start.Do(func() {})
+	low, normal, high := PriorityLow, PriorityNormal, PriorityHigh
+	s.cache.setItems([]Item{
+		{ID: "a", Name: "Tape", Priority: &low},
+		{ID: "b", Name: "Milk", Priority: &high},
+		{ID: "c", Name: "Bread", Priority: &low},
+		{ID: "d", Name: "Eggs", Priority: &normal},
+	}, at)
+	s.cache.setFreeze(nil, at)
+
+	view, err := s.ViewOrdered(context.Background(), "priority", "", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(view.Items) != 2 || view.Items[0].ID != "b" || view.Items[1].ID != "d" {
+		t.Fatalf("expected the two highest priorities, got %+v", view.Items)
+	}
+	if view, _ := s.ViewOrdered(context.Background(), "priority", "", 0); len(view.Items) != 4 {
+		t.Fatalf("expected no limit to keep every item, got %+v", view.Items)
+	}
+}
+
+func TestWrittenItemReadsUpdatesBack(t *testing.T) {
+	before := &Item{ID: "a", Name: "Milk"}
+	change := ItemChange{ID: "a", Name: "Oat milk", Action: ActionUpdated}
+
+	change, after, warnings := writtenItem(change, before, nil, nil, func() (*Item, error) { return &Item{ID: "a", Name: "Oat milk"}, nil })
+	if after == nil || after.Name != "Oat milk" || len(warnings.Warnings) != 0 {
+		t.Fatalf("expected the item read back, got %+v, %+v", after, warnings)
+	}
+	if len(change.Fields) != 1 || change.Fields[0].Field != "name" {
+		t.Fatalf("expected the change to carry the diff, got %+v", change)
+	}
+
+	created := &Item{ID: "b", Name: "Bread"}
+	if _, after, _ := writtenItem(ItemChange{ID: "b", Action: ActionAdded}, nil, created, nil, func() (*Item, error) {
+		t.Fatal("expected a created item not to be read back")
+		return nil, nil
+	}); after != created {
+		t.Fatalf("expected the created item, got %+v", after)
+	}
+}
+
+func TestWrittenItemWarnsWhenReadBackFails(t *testing.T) {
+	change := ItemChange{ID: "a", Name: "Oat milk", Action: ActionUpdated}
+	before := &Item{ID: "a", Name: "Milk", Revision: 3}
+	landed := landedItem(*before, ItemInput{Name: "Oat milk"}, false, HouseholdProfile{})
+	got, after, warnings := writtenItem(change, before, nil, &landed, func() (*Item, error) { return nil, errors.New("unavailable") })
+	if after != &landed || got.ID != "a" || got.Action != ActionUpdated {
+		t.Fatalf("expected the landed item with the change, got %+v, %+v", got, after)
+	}
+	if len(got.Fields) != 1 || got.Fields[0].Field != "name" {
+		t.Fatalf("expected the change to carry the landed diff, got %+v", got)
+	}
+	if len(warnings.Warnings) != 1 || warnings.Warnings[0].Code != WarnReadBackFailed || warnings.Warnings[0].ItemID != "a" {
+		t.Fatalf("expected a read_back_failed warning, got %+v", warnings)
+	}
+}
+
+func TestLandedItemAppliesTheUpdate(t *testing.T) {
+	bought := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	category, was, price := "Dairy", 2.5, 0.0
+	before := Item{ID: "a", Name: "Milk", Category: &category, Tags: []string{"party"}, Price: &was, PriceCurrency: "USD", Purchased: true, PurchasedAt: &bought, Revision: 3}
+	empty, quantity := "", "2 l"
+	got := landedItem(before, ItemInput{Name: "Oat milk", Quantity: &quantity, Category: &empty, Tags: []string{}, Price: &price}, true, HouseholdProfile{})
+
+	if got.Name != "Oat milk" || deref(got.Quantity) != "2 l" || got.Category != nil || got.Tags != nil || got.Price != nil || got.PriceCurrency != "" {
+		t.Fatalf("expected the input applied and emptied fields cleared, got %+v", got)
+	}
+	if got.Purchased || got.PurchasedAt != nil || got.Revision != 4 {
+		t.Fatalf("expected a reopened item one revision on, got %+v", got)
+	}
+	if before.Name != "Milk" || before.Category == nil {
+		t.Fatalf("expected before to be left as it was, got %+v", before)
+	}
+}
@@ -0,0 +1,120 @@
+package shoppinglist
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// -----------------------------------------------------------------------------
+// Quantity modes
+// -----------------------------------------------------------------------------
+
+// settingsDocID is the document in the meta collection holding list settings.
+const settingsDocID = "settings"
+
+// Quantity modes. In amount mode (the default) the quantity is how much to
+// buy and checking an item off marks all of it bought. In count mode the
+// quantity is the number still needed and checking decrements it, so "6 AA
+// batteries" with 4 bought leaves "2 AA batteries".
+const (
+	QuantityModeAmount = "amount"
+	QuantityModeCount  = "count"
+)
+
+// ListSettings are per-list options kept in the meta collection.
+type ListSettings struct {
+	QuantityMode string `json:"quantity_mode" firestore:"quantity_mode,omitempty"`
+}
+
+// SettingsResponse wraps a list's settings.
+type SettingsResponse struct {
+	Settings ListSettings `json:"settings"`
+	ResponseWarnings
+}
+
+// decodeSettings decodes the settings document, treating a missing one as the
+// defaults.
+func decodeSettings(doc *firestore.DocumentSnapshot, err error) (ListSettings, error) {
+	settings := ListSettings{QuantityMode: QuantityModeAmount}
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return settings, nil
+		}
+		return settings, fmt.Errorf("read settings: %w", err)
+	}
+	if err := doc.DataTo(&settings); err != nil {
+		return settings, fmt.Errorf("decode settings: %w", err)
+	}
+	if settings.QuantityMode == "" {
+		settings.QuantityMode = QuantityModeAmount
+	}
+	return settings, nil
+}
+
+// SetQuantityMode switches how the list's quantities behave when checked off.
+func (s *Service) SetQuantityMode(ctx context.Context, mode string) (ListSettings, error) {
+	if mode != QuantityModeAmount && mode != QuantityModeCount {
+		return ListSettings{}, fmt.Errorf("unsupported quantity mode %q (expected amount or count)", mode)
+	}
+	ref := s.metaCollection().Doc(settingsDocID)
+	if _, err := ref.Set(ctx, map[string]any{"quantity_mode": mode}, firestore.MergeAll); err != nil {
+		return ListSettings{}, fmt.Errorf("set quantity mode: %w", err)
+	}
+	return ListSettings{QuantityMode: mode}, nil
+}
+
+// splitCount splits a quantity such as "6 AA batteries" into its count and
+// the rest, keeping the rest as written. An empty quantity is zero.
+func splitCount(q string, p HouseholdProfile) (float64, string, bool) {
+	if strings.TrimSpace(q) == "" {
+		return 0, "", true
+	}
+	m := amountRe.FindStringSubmatch(q)
+	if m == nil {
+		return 0, "", false
+	}
+	v, err := p.ParseNumber(m[1])
+	if err != nil {
+		return 0, "", false
+	}
+	return v, m[2], true
+}
+
+// countOff applies n bought (nil for everything remaining) to an item on a
+// count-mode list, reporting whether nothing is left to buy.
+func countOff(it *Item, n *float64, p HouseholdProfile) (bool, error) {
+	have, rest, ok := splitCount(deref(it.Quantity), p)
+	if !ok {
+		return false, fmt.Errorf("quantity %q of %q is not a count", deref(it.Quantity), it.Name)
+	}
+	remaining := 0.0
+	if n != nil {
+		if *n <= 0 {
+			return false, fmt.Errorf("'count' must be positive")
+		}
+		remaining = max(have-*n, 0)
+	}
+	q := formatAmount(remaining, rest, p)
+	it.Quantity = &q
+	return remaining == 0, nil
+}
+
+// countBack puts n back on an item on a count-mode list, as when a purchase
+// is returned.
+func countBack(it *Item, n float64, p HouseholdProfile) error {
+	have, rest, ok := splitCount(deref(it.Quantity), p)
+	if !ok {
+		return fmt.Errorf("quantity %q of %q is not a count", deref(it.Quantity), it.Name)
+	}
+	if n <= 0 {
+		return fmt.Errorf("'count' must be positive")
+	}
+	q := formatAmount(have+n, rest, p)
+	it.Quantity = &q
+	return nil
+}
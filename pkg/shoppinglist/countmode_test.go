@@ -0,0 +1,48 @@
+package shoppinglist
+
+import "testing"
+
+func TestCountOff(t *testing.T) {
+	q := "6 AA batteries"
+	it := Item{Name: "batteries", Quantity: &q}
+	four := 4.0
+	done, err := countOff(&it, &four, HouseholdProfile{})
+	if err != nil || done || *it.Quantity != "2 AA batteries" {
+		t.Fatalf("after buying 4: %q, done=%v, err=%v", *it.Quantity, done, err)
+	}
+	three := 3.0
+	done, err = countOff(&it, &three, HouseholdProfile{})
+	if err != nil || !done || *it.Quantity != "0 AA batteries" {
+		t.Fatalf("after buying 3 more: %q, done=%v, err=%v", *it.Quantity, done, err)
+	}
+}
+
+func TestCountOffEverythingByDefault(t *testing.T) {
+	q := "12"
+	it := Item{Name: "eggs", Quantity: &q}
+	if done, err := countOff(&it, nil, HouseholdProfile{}); err != nil || !done || *it.Quantity != "0" {
+		t.Fatalf("expected every egg bought, got %q, done=%v, err=%v", *it.Quantity, done, err)
+	}
+}
+
+func TestCountOffRejectsNonCounts(t *testing.T) {
+	q := "a few"
+	it := Item{Name: "apples", Quantity: &q}
+	one := 1.0
+	if _, err := countOff(&it, &one, HouseholdProfile{}); err == nil {
+		t.Fatal("expected an error for a quantity that is not a count")
+	}
+	zero := 0.0
+	q = "3"
+	if _, err := countOff(&it, &zero, HouseholdProfile{}); err == nil {
+		t.Fatal("expected an error for a zero count")
+	}
+}
+
+func TestCountBack(t *testing.T) {
+	q := "0 rolls"
+	it := Item{Name: "tape", Quantity: &q}
+	if err := countBack(&it, 2, HouseholdProfile{}); err != nil || *it.Quantity != "2 rolls" {
+		t.Fatalf("countBack = %q, %v", *it.Quantity, err)
+	}
+}
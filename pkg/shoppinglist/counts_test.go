@@ -0,0 +1,26 @@
+package shoppinglist
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseCountCategoriesNormalizesAndDedupes(t *testing.T) {
+	got, err := ParseCountCategories([]any{"Produce", " produce ", "Dairy", ""})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"produce", "dairy"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	if _, err := ParseCountCategories([]any{"produce", 3}); err == nil {
+		t.Fatal("expected a non-string category to be refused")
+	}
+	many := make([]any, MaxCountCategories+1)
+	for i := range many {
+		many[i] = string(rune('a' + i))
+	}
+	if _, err := ParseCountCategories(many); err == nil {
+		t.Fatal("expected too many categories to be refused")
+	}
+}
@@ -0,0 +1,275 @@
+package shoppinglist
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"math"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// -----------------------------------------------------------------------------
+// Currencies and exchange rates
+// -----------------------------------------------------------------------------
+
+var currencyRe = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// NormalizeCurrency upper-cases and validates an ISO 4217 currency code.
+func NormalizeCurrency(code string) (string, error) {
+	c := strings.ToUpper(strings.TrimSpace(code))
+	if !currencyRe.MatchString(c) {
+		return "", fmt.Errorf("invalid currency code %q (expected an ISO 4217 code such as USD or EUR)", code)
+	}
+	return c, nil
+}
+
+// ExchangeRateSource converts between currencies.
+type ExchangeRateSource interface {
+	// Rate returns how many units of to one unit of from is worth.
+	Rate(ctx context.Context, from, to string) (float64, error)
+}
+
+// NewExchangeRateSource builds a source from its flag value: "" disables
+// conversion, "ecb" uses the European Central Bank daily reference rates, and
+// "static:EUR=1.08,GBP=1.27" fixes rates as the value of one unit in base.
+// Fetched rates are kept for 12 hours by clock.
+func NewExchangeRateSource(spec, base string, clock Clock) (ExchangeRateSource, error) {
+	switch {
+	case spec == "":
+		return RateTable{Base: base, PerBase: map[string]float64{base: 1}}, nil
+	case spec == "ecb":
+		return &ecbRates{client: &http.Client{Timeout: 10 * time.Second}, ttl: 12 * time.Hour, clock: clock}, nil
+	case strings.HasPrefix(spec, "static:"):
+		return parseStaticRates(strings.TrimPrefix(spec, "static:"), base)
+	default:
+		return nil, fmt.Errorf("unknown exchange rate source %q (expected ecb or static:CODE=RATE,...)", spec)
+	}
+}
+
+// RateTable holds rates as units of each currency per one unit of base.
+type RateTable struct {
+	Base    string
+	PerBase map[string]float64
+}
+
+func (t RateTable) Rate(_ context.Context, from, to string) (float64, error) {
+	if from == to {
+		return 1, nil
+	}
+	f, ok := t.PerBase[from]
+	if !ok {
+		return 0, fmt.Errorf("no exchange rate for %s", from)
+	}
+	r, ok := t.PerBase[to]
+	if !ok {
+		return 0, fmt.Errorf("no exchange rate for %s", to)
+	}
+	return r / f, nil
+}
+
+func parseStaticRates(spec, base string) (RateTable, error) {
+	table := RateTable{Base: base, PerBase: map[string]float64{base: 1}}
+	for _, pair := range strings.Split(spec, ",") {
+		code, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok {
+			return RateTable{}, fmt.Errorf("static rate %q: expected CODE=RATE", pair)
+		}
+		c, err := NormalizeCurrency(code)
+		if err != nil {
+			return RateTable{}, err
+		}
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil || v <= 0 {
+			return RateTable{}, fmt.Errorf("static rate %q: invalid rate", pair)
+		}
+		table.PerBase[c] = 1 / v
+	}
+	return table, nil
+}
+
+// ecbRates fetches and caches the ECB euro foreign exchange reference rates.
+type ecbRates struct {
+	client *http.Client
+	ttl    time.Duration
+	clock  Clock
+
+	mu      sync.Mutex
+	table   RateTable
+	fetched time.Time
+}
+
+const ecbDailyURL = "https://www.ecb.europa.eu/stats/eurofxref/eurofxref-daily.xml"
+
+func (e *ecbRates) Rate(ctx context.Context, from, to string) (float64, error) {
+	if from == to {
+		return 1, nil
+	}
+	table, err := e.current(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return table.Rate(ctx, from, to)
+}
+
+func (e *ecbRates) current(ctx context.Context) (RateTable, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.table.PerBase != nil && e.clock.Now().Sub(e.fetched) < e.ttl {
+		return e.table, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ecbDailyURL, nil)
+	if err != nil {
+		return RateTable{}, fmt.Errorf("build ECB request: %w", err)
+	}
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return RateTable{}, fmt.Errorf("fetch ECB rates: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return RateTable{}, fmt.Errorf("fetch ECB rates: %s", resp.Status)
+	}
+
+	var doc struct {
+		Cubes []struct {
+			Currency string  `xml:"currency,attr"`
+			Rate     float64 `xml:"rate,attr"`
+		} `xml:"Cube>Cube>Cube"`
+	}
+	if err := xml.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return RateTable{}, fmt.Errorf("decode ECB rates: %w", err)
+	}
+
+	table := RateTable{Base: "EUR", PerBase: map[string]float64{"EUR": 1}}
+	for _, c := range doc.Cubes {
+		if c.Rate > 0 {
+			table.PerBase[c.Currency] = c.Rate
+		}
+	}
+	e.table, e.fetched = table, e.clock.Now()
+	return table, nil
+}
+
+// convertAmount converts amount between currencies, rounding to cents.
+func convertAmount(ctx context.Context, rates ExchangeRateSource, amount float64, from, to string) (float64, error) {
+	rate, err := rates.Rate(ctx, from, to)
+	if err != nil {
+		return 0, err
+	}
+	return roundCents(amount * rate), nil
+}
+
+func roundCents(v float64) float64 { return math.Round(v*100) / 100 }
+
+// PriceLine is one item's contribution to a price report.
+type PriceLine struct {
+	ID             string  `json:"id"`
+	Name           string  `json:"name"`
+	Size           string  `json:"size"`
+	Price          float64 `json:"price"`
+	Currency       string  `json:"currency"`
+	ConvertedPrice float64 `json:"converted_price"`
+}
+
+// PriceReportResponse totals the list in the preferred currency.
+type PriceReportResponse struct {
+	Currency string      `json:"currency"`
+	Total    float64     `json:"total"`
+	Lines    []PriceLine `json:"lines"`
+	Budget   float64     `json:"budget,omitempty"`
+	Unpriced []string    `json:"unpriced,omitempty"`
+	ResponseWarnings
+}
+
+// chosenPackage returns the option matching the item's package size, falling
+// back to the first recorded option.
+func chosenPackage(it Item) (PackageOption, bool) {
+	if len(it.PackageOptions) == 0 {
+		return PackageOption{}, false
+	}
+	if it.PackageSize != nil {
+		for _, o := range it.PackageOptions {
+			if strings.EqualFold(o.Size, *it.PackageSize) {
+				return o, true
+			}
+		}
+	}
+	return it.PackageOptions[0], true
+}
+
+// itemPrice returns what the item is expected to cost: its own price when one
+// is set, else its chosen package's price.
+func itemPrice(it Item) (PackageOption, bool) {
+	if it.Price != nil {
+		return PackageOption{Size: deref(it.PackageSize), Price: *it.Price, Currency: it.PriceCurrency}, true
+	}
+	return chosenPackage(it)
+}
+
+// BuildPriceReport sums each item's expected price in currency.
+func BuildPriceReport(ctx context.Context, rates ExchangeRateSource, currency string, items []Item) PriceReportResponse {
+	report := PriceReportResponse{Currency: currency, Lines: []PriceLine{}}
+	for _, it := range items {
+		opt, ok := itemPrice(it)
+		if !ok {
+			report.Unpriced = append(report.Unpriced, it.Name)
+			continue
+		}
+		from := opt.Currency
+		if from == "" {
+			from = currency
+		}
+		converted, err := convertAmount(ctx, rates, opt.Price, from, currency)
+		if err != nil {
+			report.Warn(WarnConversionFailed, it.ID, "%s: %v", it.Name, err)
+			report.Unpriced = append(report.Unpriced, it.Name)
+			continue
+		}
+		report.Lines = append(report.Lines, PriceLine{
+			ID:             it.ID,
+			Name:           it.Name,
+			Size:           opt.Size,
+			Price:          opt.Price,
+			Currency:       from,
+			ConvertedPrice: converted,
+		})
+		report.Total += converted
+	}
+	report.Total = roundCents(report.Total)
+	return report
+}
+
+// EstimateTotal totals the items still to buy in currency for a listing.
+func EstimateTotal(ctx context.Context, rates ExchangeRateSource, currency string, items []Item) PriceReportResponse {
+	var toBuy []Item
+	for _, it := range items {
+		if !it.Purchased {
+			toBuy = append(toBuy, it)
+		}
+	}
+	return BuildPriceReport(ctx, rates, currency, toBuy)
+}
+
+// CheckBudget warns when the report total exceeds budget, converting the
+// budget from the household currency when the report uses another.
+func CheckBudget(ctx context.Context, report *PriceReportResponse, rates ExchangeRateSource, budget float64, budgetCurrency string) {
+	if budget <= 0 {
+		return
+	}
+	limit, err := convertAmount(ctx, rates, budget, budgetCurrency, report.Currency)
+	if err != nil {
+		report.Warn(WarnConversionFailed, "", "budget: %v", err)
+		return
+	}
+	report.Budget = limit
+	if report.Total > limit {
+		report.Warn(WarnBudgetExceeded, "", "estimated total %.2f %s exceeds the budget of %.2f %s", report.Total, report.Currency, limit, report.Currency)
+	}
+}
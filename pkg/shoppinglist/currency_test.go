@@ -0,0 +1,80 @@
+package shoppinglist
+
+import (
+	"context"
+	"math"
+	"testing"
+)
+
+func TestNormalizeCurrency(t *testing.T) {
+	got, err := NormalizeCurrency(" eur ")
+	if err != nil || got != "EUR" {
+		t.Fatalf("normalizeCurrency = %q, %v; want EUR", got, err)
+	}
+	if _, err := NormalizeCurrency("euro"); err == nil {
+		t.Fatal("expected error for non-ISO code")
+	}
+}
+
+func TestStaticRatesConvertViaBase(t *testing.T) {
+	rates, err := NewExchangeRateSource("static:EUR=1.25,GBP=1.5", "USD", SystemClock{})
+	if err != nil {
+		t.Fatalf("NewExchangeRateSource returned error: %v", err)
+	}
+	ctx := context.Background()
+
+	if r, _ := rates.Rate(ctx, "EUR", "USD"); math.Abs(r-1.25) > 1e-9 {
+		t.Fatalf("EUR->USD = %v, want 1.25", r)
+	}
+	if r, _ := rates.Rate(ctx, "GBP", "EUR"); math.Abs(r-1.2) > 1e-9 {
+		t.Fatalf("GBP->EUR = %v, want 1.2", r)
+	}
+	if _, err := rates.Rate(ctx, "JPY", "USD"); err == nil {
+		t.Fatal("expected error for unknown currency")
+	}
+}
+
+func TestBuildPriceReportConvertsAndFlagsUnpriced(t *testing.T) {
+	rates, _ := NewExchangeRateSource("static:EUR=2", "USD", SystemClock{})
+	size := "1 kg"
+	items := []Item{
+		{ID: "1", Name: "flour", PackageSize: &size, PackageOptions: []PackageOption{
+			{Size: "500 g", Price: 1, Currency: "USD"},
+			{Size: "1 kg", Price: 1.5, Currency: "EUR"},
+		}},
+		{ID: "2", Name: "salt", PackageOptions: []PackageOption{{Size: "1 kg", Price: 0.99}}},
+		{ID: "3", Name: "basil"},
+	}
+
+	report := BuildPriceReport(context.Background(), rates, "USD", items)
+	if report.Total != 3.99 {
+		t.Fatalf("unexpected total %v", report.Total)
+	}
+	if len(report.Lines) != 2 || report.Lines[0].ConvertedPrice != 3 {
+		t.Fatalf("unexpected lines %+v", report.Lines)
+	}
+	if len(report.Unpriced) != 1 || report.Unpriced[0] != "basil" {
+		t.Fatalf("unexpected unpriced %v", report.Unpriced)
+	}
+}
+
+func TestEstimateTotalUsesItemPricesForItemsToBuy(t *testing.T) {
+	rates, _ := NewExchangeRateSource("static:EUR=2", "USD", SystemClock{})
+	price, eur := 3.0, 1.5
+	items := []Item{
+		{ID: "1", Name: "wine", Price: &price, PackageOptions: []PackageOption{{Size: "750 ml", Price: 9, Currency: "USD"}}},
+		{ID: "2", Name: "cheese", Price: &eur, PriceCurrency: "EUR"},
+		{ID: "3", Name: "bread", Price: &price, Purchased: true},
+		{ID: "4", Name: "olives"},
+	}
+	report := EstimateTotal(context.Background(), rates, "USD", items)
+	if report.Total != 6 {
+		t.Fatalf("expected 3 + 1.5 EUR (3 USD) = 6, got %v", report.Total)
+	}
+	if len(report.Unpriced) != 1 || report.Unpriced[0] != "olives" {
+		t.Fatalf("expected olives unpriced, got %v", report.Unpriced)
+	}
+	if report.Lines[0].Price != 3 || report.Lines[1].Currency != "EUR" {
+		t.Fatalf("unexpected lines %+v", report.Lines)
+	}
+}
@@ -0,0 +1,137 @@
+package shoppinglist
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"time"
+)
+
+// -----------------------------------------------------------------------------
+// Dashboard
+// -----------------------------------------------------------------------------
+
+// ListDashboard summarizes one list.
+type ListDashboard struct {
+	ID             string        `json:"id"`
+	Name           string        `json:"name"`
+	Slug           string        `json:"slug"`
+	Counts         SummaryCounts `json:"counts"`
+	EstimatedTotal float64       `json:"estimated_total"`
+	Currency       string        `json:"currency"`
+	Budget         float64       `json:"budget,omitempty"`
+	OverBudget     bool          `json:"over_budget,omitempty"`
+	Unpriced       int           `json:"unpriced,omitempty"`
+	NextNeededBy   *time.Time    `json:"next_needed_by,omitempty"`
+	NextNeeded     []string      `json:"next_needed,omitempty"`
+	FrozenUntil    *time.Time    `json:"frozen_until,omitempty"`
+}
+
+// Dashboard summarizes every list for the start of an assistant session.
+type Dashboard struct {
+	GeneratedAt time.Time       `json:"generated_at"`
+	Lists       []ListDashboard `json:"lists"`
+	ResponseWarnings
+}
+
+// nonZeroTime returns nil for a nil or zero time.
+func nonZeroTime(t *time.Time) *time.Time {
+	if t == nil || t.IsZero() {
+		return nil
+	}
+	return t
+}
+
+// nextNeeded returns the earliest needed-by date among items still to buy and
+// the names of the items needed then.
+func nextNeeded(items []Item) (*time.Time, []string) {
+	var (
+		next  *time.Time
+		names []string
+	)
+	for _, it := range items {
+		if it.Purchased || it.NeededBy == nil {
+			continue
+		}
+		switch {
+		case next == nil || it.NeededBy.Before(*next):
+			next, names = it.NeededBy, []string{it.Name}
+		case it.NeededBy.Equal(*next):
+			names = append(names, it.Name)
+		}
+	}
+	sort.Strings(names)
+	return next, names
+}
+
+// DashboardBuilder assembles the dashboard from every list.
+type DashboardBuilder struct {
+	Service  *Service
+	Rates    ExchangeRateSource
+	Currency string
+	Budget   float64
+}
+
+// summarizeList builds the dashboard entry for one list. Prices are totalled
+// over the items still to buy.
+func (b DashboardBuilder) summarizeList(ctx context.Context, list ListInfo, view ListView) (ListDashboard, ResponseWarnings) {
+	report := EstimateTotal(ctx, b.Rates, b.Currency, view.Items)
+	CheckBudget(ctx, &report, b.Rates, b.Budget, b.Currency)
+
+	d := ListDashboard{
+		ID:             list.ID,
+		Name:           list.Name,
+		Slug:           list.Slug,
+		Counts:         countItems(view.Items),
+		EstimatedTotal: report.Total,
+		Currency:       report.Currency,
+		Budget:         report.Budget,
+		OverBudget:     report.Budget > 0 && report.Total > report.Budget,
+		Unpriced:       len(report.Unpriced),
+	}
+	d.NextNeededBy, d.NextNeeded = nextNeeded(view.Items)
+	if view.Freeze != nil {
+		d.FrozenUntil = &view.Freeze.Until
+	}
+	return d, report.ResponseWarnings
+}
+
+// visibleLists returns the lists visible allows, or every list when visible
+// is nil.
+func visibleLists(lists []ListInfo, visible func(ListInfo) bool) []ListInfo {
+	if visible == nil {
+		return lists
+	}
+	return slices.DeleteFunc(slices.Clone(lists), func(l ListInfo) bool { return !visible(l) })
+}
+
+// Build reads every list visible allows, or every list when visible is nil,
+// and summarizes it. Lists that cannot be read are reported as warnings rather
+// than failing the whole dashboard.
+func (b DashboardBuilder) Build(ctx context.Context, visible func(ListInfo) bool) (Dashboard, error) {
+	lists, err := b.Service.Lists(ctx)
+	if err != nil {
+		return Dashboard{}, err
+	}
+	lists = visibleLists(lists, visible)
+	dash := Dashboard{GeneratedAt: b.Service.Now(), Lists: make([]ListDashboard, 0, len(lists))}
+	for _, list := range lists {
+		svc, err := b.Service.ForList(list)
+		if err != nil {
+			dash.Warn(WarnStaleData, "", "list %q could not be read: %v", list.Slug, err)
+			continue
+		}
+		view, err := svc.View(ctx)
+		if err != nil {
+			dash.Warn(WarnStaleData, "", "list %q could not be read: %v", list.Slug, err)
+			continue
+		}
+		d, warnings := b.summarizeList(ctx, list, view)
+		for _, w := range warnings.Warnings {
+			w.Message = list.Slug + ": " + w.Message
+			dash.Warnings = append(dash.Warnings, w)
+		}
+		dash.Lists = append(dash.Lists, d)
+	}
+	return dash, nil
+}
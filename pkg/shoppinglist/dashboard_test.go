@@ -0,0 +1,60 @@
+package shoppinglist
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestNextNeeded(t *testing.T) {
+	fri := time.Date(2025, 8, 15, 0, 0, 0, 0, time.UTC)
+	sat := fri.AddDate(0, 0, 1)
+	items := []Item{
+		{Name: "cake", NeededBy: &sat},
+		{Name: "candles", NeededBy: &fri},
+		{Name: "balloons", NeededBy: &fri},
+		{Name: "napkins", NeededBy: &fri, Purchased: true},
+		{Name: "milk"},
+	}
+	next, names := nextNeeded(items)
+	if next == nil || !next.Equal(fri) {
+		t.Fatalf("expected %v, got %v", fri, next)
+	}
+	if len(names) != 2 || names[0] != "balloons" || names[1] != "candles" {
+		t.Fatalf("unexpected items %v", names)
+	}
+	if next, _ := nextNeeded([]Item{{Name: "milk"}}); next != nil {
+		t.Fatalf("expected no date, got %v", next)
+	}
+}
+
+func TestSummarizeListAgainstBudget(t *testing.T) {
+	rates, err := NewExchangeRateSource("", "USD", SystemClock{})
+	if err != nil {
+		t.Fatalf("NewExchangeRateSource returned error: %v", err)
+	}
+	b := DashboardBuilder{Rates: rates, Currency: "USD", Budget: 5}
+	items := []Item{
+		{ID: "1", Name: "coffee", PackageOptions: []PackageOption{{Size: "500 g", Price: 7.5, Currency: "USD"}}},
+		{ID: "2", Name: "tea", PackageOptions: []PackageOption{{Size: "100 g", Price: 3, Currency: "USD"}}, Purchased: true},
+		{ID: "3", Name: "sugar"},
+	}
+	d, _ := b.summarizeList(context.Background(), ListInfo{ID: "default", Name: "shopping", Slug: "shopping"}, ListView{Items: items})
+	if d.EstimatedTotal != 7.5 || !d.OverBudget || d.Budget != 5 || d.Unpriced != 1 {
+		t.Fatalf("unexpected totals: %+v", d)
+	}
+	if d.Counts.Total != 3 || d.Counts.ToBuy != 2 {
+		t.Fatalf("unexpected counts: %+v", d.Counts)
+	}
+}
+
+func TestVisibleLists(t *testing.T) {
+	lists := []ListInfo{{ID: "main"}, {ID: "party"}}
+	if got := visibleLists(lists, nil); len(got) != 2 {
+		t.Fatalf("expected every list without a filter, got %+v", got)
+	}
+	got := visibleLists(lists, func(l ListInfo) bool { return l.ID == "party" })
+	if len(got) != 1 || got[0].ID != "party" || len(lists) != 2 {
+		t.Fatalf("expected only the visible list, got %+v (from %+v)", got, lists)
+	}
+}
@@ -0,0 +1,90 @@
+package shoppinglist
+
+import (
+	"encoding/json"
+	"maps"
+	"reflect"
+	"slices"
+)
+
+// -----------------------------------------------------------------------------
+// Change diffs
+// -----------------------------------------------------------------------------
+
+// FieldChange is one field a write changed, with its values as they appear in
+// responses; a null old value means the field was unset, a null new value that
+// it was cleared.
+type FieldChange struct {
+	Field string `json:"field" firestore:"field"`
+	Old   any    `json:"old" firestore:"old"`
+	New   any    `json:"new" firestore:"new"`
+}
+
+// ItemChange describes what a write did to one item.
+type ItemChange struct {
+	ID     string        `json:"id"`
+	Name   string        `json:"name"`
+	Action string        `json:"action"`
+	Fields []FieldChange `json:"fields,omitempty"`
+}
+
+// diffIgnored are bookkeeping fields, and fields derived from others, that
+// every write touches or that repeat another change.
+var diffIgnored = map[string]bool{"id": true, "revision": true, "created_at": true, "updated_at": true, "amount": true, "unit": true}
+
+// itemFields is it as its JSON fields, or none for nil.
+func itemFields(it *Item) map[string]any {
+	fields := map[string]any{}
+	if it == nil {
+		return fields
+	}
+	b, err := json.Marshal(it)
+	if err != nil {
+		return fields
+	}
+	_ = json.Unmarshal(b, &fields)
+	for k := range diffIgnored {
+		delete(fields, k)
+	}
+	return fields
+}
+
+// DiffItems lists the fields that differ between before and after, in field
+// name order; either may be nil for an added or removed item.
+func DiffItems(before, after *Item) []FieldChange {
+	old, cur := itemFields(before), itemFields(after)
+	keys := slices.Sorted(maps.Keys(old))
+	for k := range cur {
+		if _, ok := old[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	slices.Sort(keys)
+
+	var changes []FieldChange
+	for _, k := range keys {
+		o, oldOK := old[k]
+		n, newOK := cur[k]
+		if reflect.DeepEqual(o, n) {
+			continue
+		}
+		// an unset flag is the same as a false one
+		if (!oldOK && n == false) || (!newOK && o == false) {
+			continue
+		}
+		changes = append(changes, FieldChange{Field: k, Old: o, New: n})
+	}
+	return changes
+}
+
+// newItemChange describes the write that turned before into after.
+func newItemChange(action string, before, after *Item) ItemChange {
+	c := ItemChange{Action: action, Fields: DiffItems(before, after)}
+	for _, it := range []*Item{after, before} {
+		if it != nil {
+			c.ID, c.Name = it.ID, it.Name
+			break
+		}
+	}
+	return c
+}
@@ -0,0 +1,38 @@
+package shoppinglist
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffItemsReportsChangedFields(t *testing.T) {
+	one, two, dairy := "1 L", "2 L", "dairy"
+	before := &Item{ID: "a", Name: "milk", Quantity: &one, Revision: 3}
+	after := &Item{ID: "a", Name: "milk", Quantity: &two, Category: &dairy, Purchased: true, Revision: 4}
+
+	got := DiffItems(before, after)
+	want := []FieldChange{
+		{Field: "category", Old: nil, New: "dairy"},
+		{Field: "purchased", Old: false, New: true},
+		{Field: "quantity", Old: "1 L", New: "2 L"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DiffItems =\n%+v\nwant\n%+v", got, want)
+	}
+	if got := DiffItems(after, after); len(got) != 0 {
+		t.Errorf("expected no changes, got %+v", got)
+	}
+}
+
+func TestNewItemChangeForAddsAndRemovals(t *testing.T) {
+	it := &Item{ID: "a", Name: "milk"}
+	added := newItemChange(ActionAdded, nil, it)
+	want := ItemChange{ID: "a", Name: "milk", Action: ActionAdded, Fields: []FieldChange{{Field: "name", New: "milk"}}}
+	if !reflect.DeepEqual(added, want) {
+		t.Errorf("added = %+v, want %+v", added, want)
+	}
+	removed := newItemChange(ActionRemoved, it, nil)
+	if removed.ID != "a" || len(removed.Fields) != 1 || removed.Fields[0].Old != "milk" || removed.Fields[0].New != nil {
+		t.Errorf("removed = %+v", removed)
+	}
+}
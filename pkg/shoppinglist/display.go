@@ -0,0 +1,106 @@
+package shoppinglist
+
+import (
+	"hash/fnv"
+	"sort"
+)
+
+// -----------------------------------------------------------------------------
+// Display metadata
+// -----------------------------------------------------------------------------
+
+// Display is rendering metadata for the items in a response, so clients
+// showing the list as UI agree on colors, emoji, and order without their own
+// mappings.
+type Display struct {
+	Categories map[string]CategoryStyle `json:"categories,omitempty"`
+	Priorities map[string]string        `json:"priorities,omitempty"`
+	Sort       SortHint                 `json:"sort"`
+}
+
+// CategoryStyle is how a category is drawn: a hex color and an emoji.
+type CategoryStyle struct {
+	Color string `json:"color"`
+	Emoji string `json:"emoji"`
+}
+
+// SortHint is the order the items are in, whether purchased items are best
+// shown after the rest, and the order of category sections.
+type SortHint struct {
+	By            string   `json:"by"`
+	Direction     string   `json:"direction"`
+	PurchasedLast bool     `json:"purchased_last"`
+	CategoryOrder []string `json:"category_order,omitempty"`
+}
+
+// categoryStyles are the styles of common store sections.
+var categoryStyles = map[string]CategoryStyle{
+	"produce":       {Color: "#4caf50", Emoji: "🥦"},
+	"fruit":         {Color: "#ff9800", Emoji: "🍎"},
+	"vegetables":    {Color: "#4caf50", Emoji: "🥕"},
+	"dairy":         {Color: "#90caf9", Emoji: "🥛"},
+	"bakery":        {Color: "#d7a86e", Emoji: "🍞"},
+	"meat":          {Color: "#e53935", Emoji: "🥩"},
+	"seafood":       {Color: "#26c6da", Emoji: "🐟"},
+	"deli":          {Color: "#f48fb1", Emoji: "🧀"},
+	"frozen":        {Color: "#81d4fa", Emoji: "🧊"},
+	"pantry":        {Color: "#a1887f", Emoji: "🥫"},
+	"snacks":        {Color: "#ffb300", Emoji: "🍿"},
+	"beverages":     {Color: "#7e57c2", Emoji: "🥤"},
+	"household":     {Color: "#78909c", Emoji: "🧽"},
+	"personal care": {Color: "#ba68c8", Emoji: "🧴"},
+	"baby":          {Color: "#f8bbd0", Emoji: "🍼"},
+	"pet":           {Color: "#8d6e63", Emoji: "🐾"},
+	uncategorized:   {Color: "#9e9e9e", Emoji: "🛒"},
+}
+
+// categoryPalette colors categories without a built-in style, picked by a
+// hash of the name so a category keeps its color across calls.
+var categoryPalette = []string{"#ef5350", "#ab47bc", "#5c6bc0", "#29b6f6", "#26a69a", "#9ccc65", "#ffca28", "#ff7043"}
+
+// priorityColors are the colors of each priority level.
+var priorityColors = map[string]string{PriorityHigh: "#d32f2f", PriorityNormal: "#757575", PriorityLow: "#bdbdbd"}
+
+// styleOf returns the style of category, falling back to a palette color and
+// the cart emoji.
+func styleOf(category string) CategoryStyle {
+	if s, ok := categoryStyles[category]; ok {
+		return s
+	}
+	h := fnv.New32a()
+	h.Write([]byte(category))
+	return CategoryStyle{Color: categoryPalette[h.Sum32()%uint32(len(categoryPalette))], Emoji: categoryStyles[uncategorized].Emoji}
+}
+
+// NewDisplay describes how to render items listed under prefs: a style for
+// every category and priority among them, and the order they are in.
+func NewDisplay(items []Item, prefs SessionPreferences) *Display {
+	d := &Display{
+		Categories: map[string]CategoryStyle{},
+		Priorities: map[string]string{},
+		Sort:       SortHint{By: "position", Direction: "asc", PurchasedLast: true},
+	}
+	if prefs.SortBy != "" {
+		d.Sort.By = prefs.SortBy
+		if prefs.Direction != "" {
+			d.Sort.Direction = prefs.Direction
+		}
+	}
+	for _, it := range items {
+		c := categoryOf(it)
+		if _, ok := d.Categories[c]; !ok {
+			d.Categories[c] = styleOf(c)
+			d.Sort.CategoryOrder = append(d.Sort.CategoryOrder, c)
+		}
+		p := priorityOf(it)
+		d.Priorities[p] = priorityColors[p]
+	}
+	sort.SliceStable(d.Sort.CategoryOrder, func(i, j int) bool {
+		a, b := d.Sort.CategoryOrder[i], d.Sort.CategoryOrder[j]
+		if (a == uncategorized) != (b == uncategorized) {
+			return b == uncategorized
+		}
+		return a < b
+	})
+	return d
+}
@@ -0,0 +1,32 @@
+package shoppinglist
+
+import "testing"
+
+func TestNewDisplayStylesCategoriesAndPriorities(t *testing.T) {
+	dairy, spices, high := "dairy", "spices", PriorityHigh
+	items := []Item{{Name: "milk", Category: &dairy, Priority: &high}, {Name: "tape"}, {Name: "cumin", Category: &spices}}
+
+	d := NewDisplay(items, SessionPreferences{})
+	if d.Categories["dairy"] != categoryStyles["dairy"] {
+		t.Fatalf("expected built-in dairy style, got %+v", d.Categories["dairy"])
+	}
+	if got := d.Categories["spices"]; got != styleOf("spices") || got.Color == "" {
+		t.Fatalf("expected a stable palette style for spices, got %+v", got)
+	}
+	if len(d.Priorities) != 2 || d.Priorities[PriorityHigh] == "" || d.Priorities[PriorityNormal] == "" {
+		t.Fatalf("unexpected priorities: %v", d.Priorities)
+	}
+	order := d.Sort.CategoryOrder
+	if len(order) != 3 || order[0] != "dairy" || order[1] != "spices" || order[2] != uncategorized {
+		t.Fatalf("unexpected category order: %v", order)
+	}
+}
+
+func TestNewDisplaySortHintFollowsPreferences(t *testing.T) {
+	if d := NewDisplay(nil, SessionPreferences{}); d.Sort.By != "position" || d.Sort.Direction != "asc" {
+		t.Fatalf("unexpected default sort: %+v", d.Sort)
+	}
+	if d := NewDisplay(nil, SessionPreferences{SortBy: "name", Direction: "desc"}); d.Sort.By != "name" || d.Sort.Direction != "desc" {
+		t.Fatalf("unexpected sort: %+v", d.Sort)
+	}
+}
@@ -0,0 +1,20 @@
+// Package shoppinglist is the domain and Firestore service layer behind the
+// mcp-shopping-list-firestore server, for Go programs such as bots and cron
+// jobs that work with the same lists without running the MCP binary.
+//
+//	svc, err := shoppinglist.NewService(ctx, "my-project", "(default)", "shopping", "")
+//	if err != nil {
+//		return err
+//	}
+//	defer svc.Close()
+//
+//	ctx = shoppinglist.WithActor(ctx, "weekly-cron")
+//	qty := "2"
+//	if _, _, err := svc.UpsertItem(ctx, shoppinglist.ItemInput{Name: "milk", Quantity: &qty}); err != nil {
+//		return err
+//	}
+//
+// Writes through the package go through the same validation, revisions, and
+// activity log as tool calls, so the server and other clients see them as
+// they would any other change.
+package shoppinglist
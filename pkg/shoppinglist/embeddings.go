@@ -0,0 +1,312 @@
+package shoppinglist
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+
+	"google.golang.org/api/option"
+	htransport "google.golang.org/api/transport/http"
+)
+
+// -----------------------------------------------------------------------------
+// Embeddings
+// -----------------------------------------------------------------------------
+
+// Embedder turns item names into vectors for semantic comparison.
+type Embedder interface {
+	// Name identifies the provider in logs and responses.
+	Name() string
+	// Embed returns one vector per input text, in the same order.
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// NewEmbedder returns the embeddings provider selected by name.
+func NewEmbedder(ctx context.Context, provider, projectID, location, model, credentialsPath string) (Embedder, error) {
+	switch provider {
+	case "", "local":
+		return LocalEmbedder{Dims: 256}, nil
+	case "vertex":
+		return newVertexEmbedder(ctx, projectID, location, model, credentialsPath)
+	default:
+		return nil, fmt.Errorf("unknown embeddings provider %q (expected local or vertex)", provider)
+	}
+}
+
+// LocalEmbedder is an offline provider hashing character trigrams into a
+// fixed-size vector. It catches spelling variants ("tomatos" vs "tomatoes")
+// but not synonyms.
+type LocalEmbedder struct {
+	Dims int
+}
+
+func (e LocalEmbedder) Name() string { return "local" }
+
+func (e LocalEmbedder) Embed(_ context.Context, texts []string) ([][]float32, error) {
+	out := make([][]float32, len(texts))
+	for i, text := range texts {
+		out[i] = e.embed(text)
+	}
+	return out, nil
+}
+
+func (e LocalEmbedder) embed(text string) []float32 {
+	vec := make([]float32, e.Dims)
+	runes := []rune(" " + strings.ToLower(strings.TrimSpace(text)) + " ")
+	for i := 0; i+3 <= len(runes); i++ {
+		h := fnv.New32a()
+		_, _ = h.Write([]byte(string(runes[i : i+3])))
+		vec[h.Sum32()%uint32(e.Dims)]++
+	}
+	normalize(vec)
+	return vec
+}
+
+// vertexEmbedder calls the Vertex AI text embeddings predict endpoint.
+type vertexEmbedder struct {
+	client   *http.Client
+	endpoint string
+	model    string
+}
+
+func newVertexEmbedder(ctx context.Context, projectID, location, model, credentialsPath string) (*vertexEmbedder, error) {
+	if location == "" {
+		return nil, fmt.Errorf("vertex location is required")
+	}
+	if model == "" {
+		return nil, fmt.Errorf("vertex model is required")
+	}
+
+	opts := []option.ClientOption{option.WithScopes("https://www.googleapis.com/auth/cloud-platform")}
+	if credentialsPath != "" {
+		opts = append(opts, option.WithCredentialsFile(credentialsPath))
+	}
+	client, _, err := htransport.NewClient(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("create vertex client: %w", err)
+	}
+
+	return &vertexEmbedder{
+		client: client,
+		endpoint: fmt.Sprintf("https://%s-aiplatform.googleapis.com/v1/projects/%s/locations/%s/publishers/google/models/%s:predict",
+			location, projectID, location, model),
+		model: model,
+	}, nil
+}
+
+func (e *vertexEmbedder) Name() string { return "vertex:" + e.model }
+
+// vertexBatch is the most instances the Vertex AI predict endpoint accepts
+// in one request for the text embedding models.
+const vertexBatch = 250
+
+// Embed embeds texts in requests of at most vertexBatch instances, so a long
+// list is not refused as a whole.
+func (e *vertexEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	out := make([][]float32, 0, len(texts))
+	for start := 0; start < len(texts); start += vertexBatch {
+		vecs, err := e.embedBatch(ctx, texts[start:min(start+vertexBatch, len(texts))])
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, vecs...)
+	}
+	return out, nil
+}
+
+// embedBatch embeds texts in one predict request.
+func (e *vertexEmbedder) embedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	type instance struct {
+		Content  string `json:"content"`
+		TaskType string `json:"task_type"`
+	}
+	body := struct {
+		Instances []instance `json:"instances"`
+	}{}
+	for _, text := range texts {
+		body.Instances = append(body.Instances, instance{Content: text, TaskType: "SEMANTIC_SIMILARITY"})
+	}
+
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("encode vertex request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("build vertex request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("call vertex: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return nil, fmt.Errorf("vertex returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+
+	var decoded struct {
+		Predictions []struct {
+			Embeddings struct {
+				Values []float32 `json:"values"`
+			} `json:"embeddings"`
+		} `json:"predictions"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&decoded); err != nil {
+		return nil, fmt.Errorf("decode vertex response: %w", err)
+	}
+	if len(decoded.Predictions) != len(texts) {
+		return nil, fmt.Errorf("vertex returned %d embeddings for %d inputs", len(decoded.Predictions), len(texts))
+	}
+
+	out := make([][]float32, len(texts))
+	for i, p := range decoded.Predictions {
+		vec := p.Embeddings.Values
+		normalize(vec)
+		out[i] = vec
+	}
+	return out, nil
+}
+
+// normalize scales vec to unit length in place.
+func normalize(vec []float32) {
+	var sum float64
+	for _, v := range vec {
+		sum += float64(v) * float64(v)
+	}
+	if sum == 0 {
+		return
+	}
+	norm := float32(math.Sqrt(sum))
+	for i := range vec {
+		vec[i] /= norm
+	}
+}
+
+// cosineSimilarity returns the cosine of the angle between two vectors.
+func cosineSimilarity(a, b []float32) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
+
+// SimilarItem is an existing item scored against a candidate name.
+type SimilarItem struct {
+	Item  Item    `json:"item"`
+	Score float64 `json:"score"`
+}
+
+// SimilarItemsResponse wraps the result of a similarity search.
+type SimilarItemsResponse struct {
+	Provider string        `json:"provider"`
+	Matches  []SimilarItem `json:"matches"`
+	ResponseWarnings
+}
+
+// FindSimilarItems scores items against name and returns those at or above
+// threshold, best match first.
+func FindSimilarItems(ctx context.Context, embedder Embedder, name string, items []Item, threshold float64) ([]SimilarItem, error) {
+	if len(items) == 0 {
+		return []SimilarItem{}, nil
+	}
+
+	texts := make([]string, 0, len(items)+1)
+	texts = append(texts, name)
+	for _, it := range items {
+		texts = append(texts, it.Name)
+	}
+	vecs, err := embedder.Embed(ctx, texts)
+	if err != nil {
+		return nil, fmt.Errorf("embed names: %w", err)
+	}
+
+	matches := []SimilarItem{}
+	for i, it := range items {
+		score := cosineSimilarity(vecs[0], vecs[i+1])
+		if score >= threshold {
+			matches = append(matches, SimilarItem{Item: it, Score: score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].Score > matches[j].Score })
+	return matches, nil
+}
+
+// MatchItemName finds the items name refers to: those whose normalized name
+// equals it, scored 1, or failing that those similar to it at or above
+// threshold, best match first.
+func MatchItemName(ctx context.Context, normalizer *NameNormalizer, embedder Embedder, name string, items []Item, threshold float64) ([]SimilarItem, error) {
+	key := normalizer.Key(name)
+	matches := []SimilarItem{}
+	for _, it := range items {
+		if normalizer.Key(it.Name) == key {
+			matches = append(matches, SimilarItem{Item: it, Score: 1})
+		}
+	}
+	if len(matches) > 0 {
+		return matches, nil
+	}
+	return FindSimilarItems(ctx, embedder, name, items, threshold)
+}
+
+// RemoveByNameResponse reports a removal by name: the changes when exactly
+// one item matched, or the candidates to choose from when several did.
+type RemoveByNameResponse struct {
+	Removed    bool          `json:"removed"`
+	Candidates []SimilarItem `json:"candidates,omitempty"`
+	Changes    []ItemChange  `json:"changes,omitempty"`
+	Items      []Item        `json:"items,omitempty"`
+	ResponseWarnings
+}
+
+// RemoveItemByName removes the item name refers to, matched as by
+// MatchItemName. When several items match nothing is removed and they are
+// returned as candidates; when none do it is an error.
+func (s *Service) RemoveItemByName(ctx context.Context, embedder Embedder, name string, threshold float64, cascade bool) (RemoveByNameResponse, error) {
+	items, err := s.ListItems(ctx)
+	if err != nil {
+		return RemoveByNameResponse{}, err
+	}
+	matches, err := MatchItemName(ctx, s.normalizer, embedder, name, items, threshold)
+	if err != nil {
+		return RemoveByNameResponse{}, err
+	}
+	switch len(matches) {
+	case 0:
+		return RemoveByNameResponse{}, fmt.Errorf("no item matches %q", name)
+	case 1:
+	default:
+		return RemoveByNameResponse{Candidates: matches}, nil
+	}
+
+	var resp RemoveByNameResponse
+	match := matches[0]
+	if match.Score < 1 {
+		resp.Warn(WarnFuzzyMatch, match.Item.ID, "removed %q for %q with similarity %.2f", match.Item.Name, name, match.Score)
+	}
+	resp.Changes, resp.Items, err = s.RemoveItem(ctx, match.Item.ID, cascade)
+	if err != nil {
+		return RemoveByNameResponse{}, err
+	}
+	resp.Removed = true
+	return resp, nil
+}
@@ -0,0 +1,129 @@
+package shoppinglist
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestLocalEmbedderScoresSpellingVariantsHigh(t *testing.T) {
+	e := LocalEmbedder{Dims: 256}
+	vecs, err := e.Embed(context.Background(), []string{"tomatoes", "Tomatos", "dish soap"})
+	if err != nil {
+		t.Fatalf("Embed returned error: %v", err)
+	}
+
+	near := cosineSimilarity(vecs[0], vecs[1])
+	far := cosineSimilarity(vecs[0], vecs[2])
+	if near <= far {
+		t.Fatalf("expected variant score %.3f to exceed unrelated score %.3f", near, far)
+	}
+}
+
+func TestNormalizeProducesUnitVector(t *testing.T) {
+	vec := []float32{3, 4}
+	normalize(vec)
+
+	if math.Abs(float64(vec[0])-0.6) > 1e-6 || math.Abs(float64(vec[1])-0.8) > 1e-6 {
+		t.Fatalf("unexpected normalized vector: %v", vec)
+	}
+}
+
+func TestFindSimilarItemsOrdersByScore(t *testing.T) {
+	items := []Item{{ID: "1", Name: "paper towels"}, {ID: "2", Name: "milk"}, {ID: "3", Name: "whole milk"}}
+
+	matches, err := FindSimilarItems(context.Background(), LocalEmbedder{Dims: 256}, "milk", items, 0.5)
+	if err != nil {
+		t.Fatalf("findSimilarItems returned error: %v", err)
+	}
+	if len(matches) != 2 {
+		t.Fatalf("expected 2 matches, got %d", len(matches))
+	}
+	if matches[0].Item.ID != "2" {
+		t.Fatalf("expected exact match first, got %q", matches[0].Item.Name)
+	}
+}
+
+func TestNewEmbedderRejectsUnknownProvider(t *testing.T) {
+	if _, err := NewEmbedder(context.Background(), "nope", "p", "l", "m", ""); err == nil {
+		t.Fatal("expected error for unknown provider")
+	}
+}
+
+func TestMatchItemNamePrefersNormalizedMatches(t *testing.T) {
+	items := []Item{{ID: "1", Name: "Milk"}, {ID: "2", Name: "whole milk"}, {ID: "3", Name: "tomatoes"}}
+	e := LocalEmbedder{Dims: 256}
+
+	matches, err := MatchItemName(context.Background(), nil, e, " milk", items, 0.5)
+	if err != nil {
+		t.Fatalf("MatchItemName returned error: %v", err)
+	}
+	if len(matches) != 1 || matches[0].Item.ID != "1" || matches[0].Score != 1 {
+		t.Fatalf("expected only the case-insensitive match, got %+v", matches)
+	}
+
+	matches, err = MatchItemName(context.Background(), nil, e, "tomatos", items, 0.5)
+	if err != nil {
+		t.Fatalf("MatchItemName returned error: %v", err)
+	}
+	if len(matches) == 0 || matches[0].Item.ID != "3" || matches[0].Score >= 1 {
+		t.Fatalf("expected a fuzzy match on tomatoes, got %+v", matches)
+	}
+}
+
+func TestVertexEmbedderBatchesRequests(t *testing.T) {
+	var sizes []int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Instances []struct {
+				Content string `json:"content"`
+			} `json:"instances"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		sizes = append(sizes, len(body.Instances))
+		type prediction struct {
+			Embeddings struct {
+				Values []float32 `json:"values"`
+			} `json:"embeddings"`
+		}
+		var resp struct {
+			Predictions []prediction `json:"predictions"`
+		}
+		for _, in := range body.Instances {
+			var n int
+			fmt.Sscanf(in.Content, "item %d", &n)
+			var p prediction
+			p.Embeddings.Values = []float32{1, float32(n)}
+			resp.Predictions = append(resp.Predictions, p)
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer srv.Close()
+
+	texts := make([]string, 2*vertexBatch+10)
+	for i := range texts {
+		texts[i] = fmt.Sprintf("item %d", i)
+	}
+	e := &vertexEmbedder{client: srv.Client(), endpoint: srv.URL, model: "test"}
+	vecs, err := e.Embed(context.Background(), texts)
+	if err != nil {
+		t.Fatalf("Embed returned error: %v", err)
+	}
+	if len(sizes) != 3 || sizes[0] != vertexBatch || sizes[1] != vertexBatch || sizes[2] != 10 {
+		t.Fatalf("expected requests of %d, %d, and 10 instances, got %v", vertexBatch, vertexBatch, sizes)
+	}
+	if len(vecs) != len(texts) {
+		t.Fatalf("expected %d embeddings, got %d", len(texts), len(vecs))
+	}
+	for _, i := range []int{1, vertexBatch, len(texts) - 1} {
+		if len(vecs[i]) != 2 || math.Abs(float64(vecs[i][1]/vecs[i][0])-float64(i)) > 1e-3 {
+			t.Fatalf("expected embedding %d in input order, got %v", i, vecs[i])
+		}
+	}
+}
@@ -0,0 +1,348 @@
+package shoppinglist
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	pb "cloud.google.com/go/firestore/apiv1/firestorepb"
+	"google.golang.org/grpc"
+)
+
+// -----------------------------------------------------------------------------
+// Explain mode
+// -----------------------------------------------------------------------------
+
+// ErrExplained is returned in place of sending a write made in explain mode.
+var ErrExplained = errors.New("write not sent in explain mode")
+
+// explainMaxValues is how many elements of an array or map value are spelled
+// out in an explanation before the rest are only counted.
+const explainMaxValues = 8
+
+// ExplainedRead is a Firestore read made by an explained call.
+type ExplainedRead struct {
+	Kind       string   `json:"kind"` // get, query, aggregate, or list
+	Collection string   `json:"collection,omitempty"`
+	Documents  []string `json:"documents,omitempty"`
+	Filters    []string `json:"filters,omitempty"`
+	OrderBy    []string `json:"order_by,omitempty"`
+	Limit      int32    `json:"limit,omitempty"`
+}
+
+// ExplainedWrite is a Firestore write an explained call would have made.
+type ExplainedWrite struct {
+	Op           string            `json:"op"` // create, set, update, or delete
+	Document     string            `json:"document"`
+	Fields       map[string]string `json:"fields,omitempty"`
+	Precondition string            `json:"precondition,omitempty"`
+}
+
+// Explanation records what a call made in explain mode reads and would
+// write. Reads are sent, so the call sees the data it would act on; the
+// first commit is recorded and refused with ErrExplained, so nothing is
+// written.
+type Explanation struct {
+	mu      sync.Mutex
+	reads   []ExplainedRead
+	writes  []ExplainedWrite
+	stopped bool
+}
+
+type explainKey struct{}
+
+// WithExplain returns a context whose Firestore calls are recorded in the
+// returned explanation and whose writes are not sent.
+func WithExplain(ctx context.Context) (context.Context, *Explanation) {
+	x := &Explanation{}
+	return context.WithValue(ctx, explainKey{}, x), x
+}
+
+func explanationFrom(ctx context.Context) *Explanation {
+	x, _ := ctx.Value(explainKey{}).(*Explanation)
+	return x
+}
+
+// Reads returns the reads made so far.
+func (x *Explanation) Reads() []ExplainedRead {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	return append([]ExplainedRead{}, x.reads...)
+}
+
+// Writes returns the writes that were held back.
+func (x *Explanation) Writes() []ExplainedWrite {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	return append([]ExplainedWrite{}, x.writes...)
+}
+
+// Stopped reports whether a write was held back, after which the call could
+// not go on.
+func (x *Explanation) Stopped() bool {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	return x.stopped
+}
+
+func (x *Explanation) noteRead(r ExplainedRead) {
+	x.mu.Lock()
+	x.reads = append(x.reads, r)
+	x.mu.Unlock()
+}
+
+func (x *Explanation) noteWrites(writes []*pb.Write) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	for _, w := range writes {
+		x.writes = append(x.writes, explainWrite(w))
+	}
+	x.stopped = true
+}
+
+// explainUnary records the reads and holds back the writes of unary calls
+// made in explain mode.
+func explainUnary(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	x := explanationFrom(ctx)
+	if x == nil {
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+	switch r := req.(type) {
+	case *pb.CommitRequest:
+		if len(r.GetWrites()) > 0 {
+			x.noteWrites(r.GetWrites())
+			return ErrExplained
+		}
+	case *pb.BatchWriteRequest:
+		x.noteWrites(r.GetWrites())
+		return ErrExplained
+	case *pb.GetDocumentRequest:
+		x.noteRead(ExplainedRead{Kind: "get", Documents: []string{documentPath(r.GetName())}})
+	case *pb.ListDocumentsRequest:
+		x.noteRead(ExplainedRead{Kind: "list", Collection: documentPath(r.GetParent() + "/" + r.GetCollectionId())})
+	}
+	return invoker(ctx, method, req, reply, cc, opts...)
+}
+
+// explainStream records the queries and gets streamed in explain mode.
+func explainStream(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	s, err := streamer(ctx, desc, cc, method, opts...)
+	x := explanationFrom(ctx)
+	if err != nil || x == nil {
+		return s, err
+	}
+	return &explainedStream{ClientStream: s, x: x}, nil
+}
+
+type explainedStream struct {
+	grpc.ClientStream
+	x *Explanation
+}
+
+func (s *explainedStream) SendMsg(msg any) error {
+	switch r := msg.(type) {
+	case *pb.RunQueryRequest:
+		s.x.noteRead(explainQuery("query", r.GetParent(), r.GetStructuredQuery()))
+	case *pb.RunAggregationQueryRequest:
+		s.x.noteRead(explainQuery("aggregate", r.GetParent(), r.GetStructuredAggregationQuery().GetStructuredQuery()))
+	case *pb.BatchGetDocumentsRequest:
+		docs := make([]string, 0, len(r.GetDocuments()))
+		for _, d := range r.GetDocuments() {
+			docs = append(docs, documentPath(d))
+		}
+		s.x.noteRead(ExplainedRead{Kind: "get", Documents: docs})
+	}
+	return s.ClientStream.SendMsg(msg)
+}
+
+// documentPath trims a resource name to the path below the database, e.g.
+// "shopping/abc".
+func documentPath(name string) string {
+	if _, path, ok := strings.Cut(name, "/documents/"); ok {
+		return path
+	}
+	return strings.TrimSuffix(name, "/documents")
+}
+
+func explainQuery(kind, parent string, q *pb.StructuredQuery) ExplainedRead {
+	r := ExplainedRead{Kind: kind, Filters: describeFilter(q.GetWhere()), Limit: q.GetLimit().GetValue()}
+	if from := q.GetFrom(); len(from) > 0 {
+		r.Collection = documentPath(parent + "/" + from[0].GetCollectionId())
+		if from[0].GetAllDescendants() {
+			r.Collection = "**/" + from[0].GetCollectionId()
+		}
+	}
+	for _, o := range q.GetOrderBy() {
+		order := o.GetField().GetFieldPath()
+		if o.GetDirection() == pb.StructuredQuery_DESCENDING {
+			order += " desc"
+		}
+		r.OrderBy = append(r.OrderBy, order)
+	}
+	return r
+}
+
+var filterOps = map[pb.StructuredQuery_FieldFilter_Operator]string{
+	pb.StructuredQuery_FieldFilter_EQUAL:                 "==",
+	pb.StructuredQuery_FieldFilter_NOT_EQUAL:             "!=",
+	pb.StructuredQuery_FieldFilter_LESS_THAN:             "<",
+	pb.StructuredQuery_FieldFilter_LESS_THAN_OR_EQUAL:    "<=",
+	pb.StructuredQuery_FieldFilter_GREATER_THAN:          ">",
+	pb.StructuredQuery_FieldFilter_GREATER_THAN_OR_EQUAL: ">=",
+	pb.StructuredQuery_FieldFilter_ARRAY_CONTAINS:        "array-contains",
+	pb.StructuredQuery_FieldFilter_ARRAY_CONTAINS_ANY:    "array-contains-any",
+	pb.StructuredQuery_FieldFilter_IN:                    "in",
+	pb.StructuredQuery_FieldFilter_NOT_IN:                "not-in",
+}
+
+// describeFilter spells out a query filter, one entry per condition that
+// must hold; alternatives are joined with "or".
+func describeFilter(f *pb.StructuredQuery_Filter) []string {
+	switch {
+	case f.GetCompositeFilter() != nil:
+		var parts []string
+		for _, sub := range f.GetCompositeFilter().GetFilters() {
+			parts = append(parts, describeFilter(sub)...)
+		}
+		if f.GetCompositeFilter().GetOp() == pb.StructuredQuery_CompositeFilter_OR && len(parts) > 1 {
+			return []string{"(" + strings.Join(parts, " or ") + ")"}
+		}
+		return parts
+	case f.GetFieldFilter() != nil:
+		ff := f.GetFieldFilter()
+		op, ok := filterOps[ff.GetOp()]
+		if !ok {
+			op = ff.GetOp().String()
+		}
+		return []string{ff.GetField().GetFieldPath() + " " + op + " " + describeValue(ff.GetValue())}
+	case f.GetUnaryFilter() != nil:
+		uf := f.GetUnaryFilter()
+		return []string{uf.GetField().GetFieldPath() + " " + strings.ToLower(strings.ReplaceAll(uf.GetOp().String(), "_", " "))}
+	}
+	return nil
+}
+
+// describeValue spells out a Firestore value briefly; a missing value is a
+// field being deleted.
+func describeValue(v *pb.Value) string {
+	switch x := v.GetValueType().(type) {
+	case *pb.Value_StringValue:
+		return strconv.Quote(x.StringValue)
+	case *pb.Value_IntegerValue:
+		return strconv.FormatInt(x.IntegerValue, 10)
+	case *pb.Value_DoubleValue:
+		return strconv.FormatFloat(x.DoubleValue, 'g', -1, 64)
+	case *pb.Value_BooleanValue:
+		return strconv.FormatBool(x.BooleanValue)
+	case *pb.Value_TimestampValue:
+		return x.TimestampValue.AsTime().UTC().Format(time.RFC3339Nano)
+	case *pb.Value_ReferenceValue:
+		return documentPath(x.ReferenceValue)
+	case *pb.Value_ArrayValue:
+		values := x.ArrayValue.GetValues()
+		parts := make([]string, 0, min(len(values), explainMaxValues))
+		for _, e := range values[:min(len(values), explainMaxValues)] {
+			parts = append(parts, describeValue(e))
+		}
+		if len(values) > explainMaxValues {
+			parts = append(parts, fmt.Sprintf("… %d more", len(values)-explainMaxValues))
+		}
+		return "[" + strings.Join(parts, ", ") + "]"
+	case *pb.Value_MapValue:
+		fields := x.MapValue.GetFields()
+		keys := make([]string, 0, len(fields))
+		for k := range fields {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		parts := make([]string, 0, min(len(keys), explainMaxValues))
+		for _, k := range keys[:min(len(keys), explainMaxValues)] {
+			parts = append(parts, k+": "+describeValue(fields[k]))
+		}
+		if len(keys) > explainMaxValues {
+			parts = append(parts, fmt.Sprintf("… %d more", len(keys)-explainMaxValues))
+		}
+		return "{" + strings.Join(parts, ", ") + "}"
+	case nil:
+		return "deleted"
+	}
+	return "null"
+}
+
+// explainWrite describes a write held back in explain mode.
+func explainWrite(w *pb.Write) ExplainedWrite {
+	if name := w.GetDelete(); name != "" {
+		return ExplainedWrite{Op: "delete", Document: documentPath(name), Precondition: describePrecondition(w.GetCurrentDocument())}
+	}
+	doc := w.GetUpdate()
+	out := ExplainedWrite{Op: "set", Document: documentPath(doc.GetName()), Fields: map[string]string{}, Precondition: describePrecondition(w.GetCurrentDocument())}
+	if e, ok := w.GetCurrentDocument().GetConditionType().(*pb.Precondition_Exists); ok && !e.Exists {
+		out.Op, out.Precondition = "create", ""
+	} else if w.GetUpdateMask() != nil {
+		out.Op = "update"
+	}
+	if mask := w.GetUpdateMask(); mask != nil {
+		for _, path := range mask.GetFieldPaths() {
+			out.Fields[path] = describeValue(fieldValue(doc.GetFields(), path))
+		}
+	} else {
+		for k, v := range doc.GetFields() {
+			out.Fields[k] = describeValue(v)
+		}
+	}
+	for _, t := range w.GetUpdateTransforms() {
+		switch {
+		case t.GetSetToServerValue() == pb.DocumentTransform_FieldTransform_REQUEST_TIME:
+			out.Fields[t.GetFieldPath()] = "server timestamp"
+		case t.GetIncrement() != nil:
+			out.Fields[t.GetFieldPath()] = "increment by " + describeValue(t.GetIncrement())
+		default:
+			out.Fields[t.GetFieldPath()] = "transform"
+		}
+	}
+	if len(out.Fields) == 0 {
+		out.Fields = nil
+	}
+	return out
+}
+
+// fieldValue looks up a dotted field path in fields, returning nil for a
+// field the write deletes.
+func fieldValue(fields map[string]*pb.Value, path string) *pb.Value {
+	head, rest, nested := strings.Cut(path, ".")
+	v := fields[strings.Trim(head, "`")]
+	if !nested || v == nil {
+		return v
+	}
+	return fieldValue(v.GetMapValue().GetFields(), rest)
+}
+
+func describePrecondition(p *pb.Precondition) string {
+	switch {
+	case p == nil || p.GetConditionType() == nil:
+		return ""
+	case p.GetUpdateTime() != nil:
+		return "unchanged since " + p.GetUpdateTime().AsTime().UTC().Format(time.RFC3339Nano)
+	case p.GetExists():
+		return "exists"
+	}
+	return "does not exist"
+}
+
+// ExplainResponse reports what a tool call made with explain would do.
+type ExplainResponse struct {
+	Tool          string           `json:"tool"`
+	DocumentsRead int64            `json:"documents_read"`
+	Reads         []ExplainedRead  `json:"reads"`
+	Writes        []ExplainedWrite `json:"writes"`
+	// WouldFail is the error the call would return, e.g. a failed validation
+	// rule, when it fails before writing.
+	WouldFail string `json:"would_fail,omitempty"`
+	// NotRun says why the call was not run at all.
+	NotRun string `json:"not_run,omitempty"`
+}
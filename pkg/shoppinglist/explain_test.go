@@ -0,0 +1,117 @@
+package shoppinglist
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	pb "cloud.google.com/go/firestore/apiv1/firestorepb"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+const testDocs = "projects/p/databases/(default)/documents"
+
+func TestExplainUnaryHoldsBackWrites(t *testing.T) {
+	ctx, x := WithExplain(context.Background())
+	sent := 0
+	invoker := func(context.Context, string, any, any, *grpc.ClientConn, ...grpc.CallOption) error {
+		sent++
+		return nil
+	}
+
+	get := &pb.GetDocumentRequest{Name: testDocs + "/shopping/milk"}
+	if err := explainUnary(ctx, "Get", get, nil, nil, invoker); err != nil || sent != 1 {
+		t.Fatalf("expected the read to be sent, got %v after %d", err, sent)
+	}
+	commit := &pb.CommitRequest{Writes: []*pb.Write{
+		{Operation: &pb.Write_Update{Update: &pb.Document{Name: testDocs + "/shopping/eggs", Fields: map[string]*pb.Value{"name": {ValueType: &pb.Value_StringValue{StringValue: "eggs"}}}}},
+			CurrentDocument: &pb.Precondition{ConditionType: &pb.Precondition_Exists{Exists: false}}},
+		{Operation: &pb.Write_Delete{Delete: testDocs + "/shopping/milk"}},
+	}}
+	if err := explainUnary(ctx, "Commit", commit, nil, nil, invoker); !errors.Is(err, ErrExplained) || sent != 1 {
+		t.Fatalf("expected the commit to be held back, got %v after %d sent", err, sent)
+	}
+	// Commits without writes, as read-only transactions end with, go ahead.
+	if err := explainUnary(ctx, "Commit", &pb.CommitRequest{}, nil, nil, invoker); err != nil || sent != 2 {
+		t.Fatalf("expected an empty commit to be sent, got %v", err)
+	}
+
+	if !x.Stopped() {
+		t.Fatal("expected the explanation to note the held back write")
+	}
+	wantReads := []ExplainedRead{{Kind: "get", Documents: []string{"shopping/milk"}}}
+	if got := x.Reads(); !reflect.DeepEqual(got, wantReads) {
+		t.Fatalf("reads = %+v, want %+v", got, wantReads)
+	}
+	wantWrites := []ExplainedWrite{
+		{Op: "create", Document: "shopping/eggs", Fields: map[string]string{"name": `"eggs"`}},
+		{Op: "delete", Document: "shopping/milk"},
+	}
+	if got := x.Writes(); !reflect.DeepEqual(got, wantWrites) {
+		t.Fatalf("writes = %+v, want %+v", got, wantWrites)
+	}
+
+	// Outside explain mode, writes are sent.
+	if err := explainUnary(context.Background(), "Commit", commit, nil, nil, invoker); err != nil || sent != 3 {
+		t.Fatalf("expected the commit to be sent, got %v", err)
+	}
+}
+
+func TestExplainWriteUpdate(t *testing.T) {
+	w := &pb.Write{
+		Operation: &pb.Write_Update{Update: &pb.Document{Name: testDocs + "/shopping/milk", Fields: map[string]*pb.Value{
+			"quantity": {ValueType: &pb.Value_StringValue{StringValue: "2"}},
+			"tags":     {ValueType: &pb.Value_ArrayValue{ArrayValue: &pb.ArrayValue{Values: []*pb.Value{{ValueType: &pb.Value_StringValue{StringValue: "dairy"}}}}}},
+		}}},
+		UpdateMask:       &pb.DocumentMask{FieldPaths: []string{"quantity", "tags", "note"}},
+		UpdateTransforms: []*pb.DocumentTransform_FieldTransform{{FieldPath: "updated_at", TransformType: &pb.DocumentTransform_FieldTransform_SetToServerValue{SetToServerValue: pb.DocumentTransform_FieldTransform_REQUEST_TIME}}},
+		CurrentDocument:  &pb.Precondition{ConditionType: &pb.Precondition_Exists{Exists: true}},
+	}
+	want := ExplainedWrite{Op: "update", Document: "shopping/milk", Precondition: "exists", Fields: map[string]string{
+		"quantity":   `"2"`,
+		"tags":       `["dairy"]`,
+		"note":       "deleted",
+		"updated_at": "server timestamp",
+	}}
+	if got := explainWrite(w); !reflect.DeepEqual(got, want) {
+		t.Fatalf("explainWrite = %+v, want %+v", got, want)
+	}
+}
+
+func TestExplainQuery(t *testing.T) {
+	field := func(path string, op pb.StructuredQuery_FieldFilter_Operator, v *pb.Value) *pb.StructuredQuery_Filter {
+		return &pb.StructuredQuery_Filter{FilterType: &pb.StructuredQuery_Filter_FieldFilter{FieldFilter: &pb.StructuredQuery_FieldFilter{
+			Field: &pb.StructuredQuery_FieldReference{FieldPath: path}, Op: op, Value: v,
+		}}}
+	}
+	q := &pb.StructuredQuery{
+		From: []*pb.StructuredQuery_CollectionSelector{{CollectionId: "shopping"}},
+		Where: &pb.StructuredQuery_Filter{FilterType: &pb.StructuredQuery_Filter_CompositeFilter{CompositeFilter: &pb.StructuredQuery_CompositeFilter{
+			Op: pb.StructuredQuery_CompositeFilter_AND,
+			Filters: []*pb.StructuredQuery_Filter{
+				field("purchased", pb.StructuredQuery_FieldFilter_EQUAL, &pb.Value{ValueType: &pb.Value_BooleanValue{BooleanValue: false}}),
+				{FilterType: &pb.StructuredQuery_Filter_CompositeFilter{CompositeFilter: &pb.StructuredQuery_CompositeFilter{
+					Op: pb.StructuredQuery_CompositeFilter_OR,
+					Filters: []*pb.StructuredQuery_Filter{
+						field("priority", pb.StructuredQuery_FieldFilter_EQUAL, &pb.Value{ValueType: &pb.Value_StringValue{StringValue: "high"}}),
+						field("revision", pb.StructuredQuery_FieldFilter_GREATER_THAN, &pb.Value{ValueType: &pb.Value_IntegerValue{IntegerValue: 3}}),
+					},
+				}}},
+			},
+		}}},
+		OrderBy: []*pb.StructuredQuery_Order{{Field: &pb.StructuredQuery_FieldReference{FieldPath: "created_at"}, Direction: pb.StructuredQuery_DESCENDING}},
+		Limit:   wrapperspb.Int32(50),
+	}
+	want := ExplainedRead{
+		Kind:       "query",
+		Collection: "shopping",
+		Filters:    []string{"purchased == false", `(priority == "high" or revision > 3)`},
+		OrderBy:    []string{"created_at desc"},
+		Limit:      50,
+	}
+	if got := explainQuery("query", testDocs, q); !reflect.DeepEqual(got, want) {
+		t.Fatalf("explainQuery = %+v, want %+v", got, want)
+	}
+}
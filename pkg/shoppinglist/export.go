@@ -0,0 +1,249 @@
+package shoppinglist
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+)
+
+// -----------------------------------------------------------------------------
+// Exports
+// -----------------------------------------------------------------------------
+
+// ExportURIPrefix is the resource URI prefix for stored exports.
+const ExportURIPrefix = "shoppinglist://exports/"
+
+// ExportFormats are the formats exports are written in.
+var ExportFormats = []string{"csv", "json", "markdown"}
+
+// exportEncoder writes an export one item at a time.
+type exportEncoder interface {
+	item(it Item) error
+	close() error
+}
+
+// newExportEncoder returns an encoder writing format to w.
+func newExportEncoder(w io.Writer, format string) (exportEncoder, error) {
+	switch format {
+	case "json":
+		return &jsonExport{w: w}, nil
+	case "csv":
+		cw := csv.NewWriter(w)
+		if err := cw.Write([]string{"id", "name", "quantity", "created_at", "parent_id"}); err != nil {
+			return nil, err
+		}
+		return &csvExport{w: cw}, nil
+	case "markdown":
+		return &markdownExport{w: w}, nil
+	default:
+		return nil, fmt.Errorf("unsupported format %q (expected csv, json, or markdown)", format)
+	}
+}
+
+// jsonExport writes {"items": [...]} as indented JSON.
+type jsonExport struct {
+	w io.Writer
+	n int
+}
+
+func (e *jsonExport) item(it Item) error {
+	b, err := json.MarshalIndent(it, "    ", "  ")
+	if err != nil {
+		return err
+	}
+	sep := ",\n    "
+	if e.n == 0 {
+		sep = "{\n  \"items\": [\n    "
+	}
+	e.n++
+	_, err = fmt.Fprintf(e.w, "%s%s", sep, b)
+	return err
+}
+
+func (e *jsonExport) close() error {
+	if e.n == 0 {
+		_, err := io.WriteString(e.w, "{\n  \"items\": []\n}")
+		return err
+	}
+	_, err := io.WriteString(e.w, "\n  ]\n}")
+	return err
+}
+
+// csvExport writes a row per item.
+type csvExport struct {
+	w *csv.Writer
+}
+
+func (e *csvExport) item(it Item) error {
+	return e.w.Write([]string{it.ID, it.Name, deref(it.Quantity), it.CreatedAt.UTC().Format(time.RFC3339), deref(it.ParentID)})
+}
+
+func (e *csvExport) close() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// markdownExport nests children under their parent, so it holds the items
+// until the export is closed.
+type markdownExport struct {
+	w     io.Writer
+	items []Item
+}
+
+func (e *markdownExport) item(it Item) error {
+	e.items = append(e.items, it)
+	return nil
+}
+
+func (e *markdownExport) close() error {
+	var b strings.Builder
+	b.WriteString("# Shopping list\n\n")
+	for _, g := range GroupItems(e.items) {
+		writeMarkdownItem(&b, "", g.Item)
+		for _, child := range g.Children {
+			writeMarkdownItem(&b, "  ", child)
+		}
+	}
+	_, err := io.WriteString(e.w, b.String())
+	return err
+}
+
+func writeMarkdownItem(b *strings.Builder, indent string, it Item) {
+	b.WriteString(indent)
+	b.WriteString("- [ ] ")
+	b.WriteString(it.Name)
+	if q := deref(it.Quantity); q != "" {
+		fmt.Fprintf(b, " (%s)", q)
+	}
+	b.WriteString("\n")
+}
+
+// RenderExport encodes items in the requested format.
+func RenderExport(format string, items []Item) (string, error) {
+	var b strings.Builder
+	enc, err := newExportEncoder(&b, format)
+	if err != nil {
+		return "", err
+	}
+	for _, it := range items {
+		if err := enc.item(it); err != nil {
+			return "", err
+		}
+	}
+	if err := enc.close(); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// StreamExport writes the list to w in format as Firestore returns its items,
+// in the order they were added, and returns how many it wrote and the time
+// they were read at. The items are read at readTime, or as they are now when
+// it is zero; Firestore serves reads up to an hour old. Each item is encoded as it arrives, so only the
+// current batch of the query is held in memory and a slow w slows the read
+// down rather than buffering ahead of it. Markdown, which nests children under
+// their parent, holds the items until the end.
+func (s *Service) StreamExport(ctx context.Context, w io.Writer, format string, readTime time.Time) (n int, readAt time.Time, err error) {
+	enc, err := newExportEncoder(w, format)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	q := s.client.Collection(s.collection).OrderBy("created_at", firestore.Asc).OrderBy(firestore.DocumentID, firestore.Asc)
+	if !readTime.IsZero() {
+		q = *q.WithReadOptions(firestore.ReadTime(readTime))
+	}
+	docs := q.Documents(ctx)
+	defer docs.Stop()
+	for {
+		doc, err := docs.Next()
+		if errors.Is(err, iterator.Done) {
+			break
+		}
+		if err != nil {
+			return n, readAt, fmt.Errorf("retrieve items: %w", err)
+		}
+		if readAt.IsZero() {
+			readAt = doc.ReadTime
+		}
+		if trashed(doc) {
+			continue
+		}
+		var it Item
+		if err := doc.DataTo(&it); err != nil {
+			log.Printf("warn: unmarshal item %q: %v", doc.Ref.ID, err)
+			continue
+		}
+		if err := enc.item(it); err != nil {
+			return n, readAt, fmt.Errorf("write export: %w", err)
+		}
+		n++
+	}
+	if err := enc.close(); err != nil {
+		return n, readAt, fmt.Errorf("write export: %w", err)
+	}
+	return n, readAt, nil
+}
+
+// ExportWriter writes an export, when it is read.
+type ExportWriter func(ctx context.Context, w io.Writer) error
+
+// storedExport is an export held for retrieval as a resource. It keeps how to
+// write the export rather than its content, so a large export takes no memory
+// until it is read.
+type storedExport struct {
+	Write     ExportWriter
+	MimeType  string
+	expiresAt time.Time
+}
+
+// ExportStore keeps large exports for a limited time.
+type ExportStore struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	ids     IDGenerator
+	exports map[string]storedExport
+}
+
+// NewExportStore returns a store keeping exports for ttl under IDs from ids.
+func NewExportStore(ttl time.Duration, ids IDGenerator) *ExportStore {
+	return &ExportStore{ttl: ttl, ids: ids, exports: map[string]storedExport{}}
+}
+
+// Put stores an export and returns its resource URI.
+func (s *ExportStore) Put(write ExportWriter, mimeType string, now time.Time) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweep(now)
+	id := s.ids.NewID()
+	s.exports[id] = storedExport{Write: write, MimeType: mimeType, expiresAt: now.Add(s.ttl)}
+	return ExportURIPrefix + id
+}
+
+// Get returns the export for uri if it exists and has not expired.
+func (s *ExportStore) Get(uri string, now time.Time) (storedExport, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweep(now)
+	exp, ok := s.exports[strings.TrimPrefix(uri, ExportURIPrefix)]
+	return exp, ok
+}
+
+func (s *ExportStore) sweep(now time.Time) {
+	for id, exp := range s.exports {
+		if !now.Before(exp.expiresAt) {
+			delete(s.exports, id)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package shoppinglist
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRenderExportCSV(t *testing.T) {
+	qty := "4"
+	items := []Item{{ID: "1", Name: "apples", Quantity: &qty, CreatedAt: time.Date(2025, 8, 12, 14, 31, 42, 0, time.UTC)}}
+
+	got, err := RenderExport("csv", items)
+	if err != nil {
+		t.Fatalf("renderExport returned error: %v", err)
+	}
+	want := "id,name,quantity,created_at,parent_id\n1,apples,4,2025-08-12T14:31:42Z,\n"
+	if got != want {
+		t.Fatalf("unexpected csv: got %q, want %q", got, want)
+	}
+}
+
+func TestRenderExportMarkdown(t *testing.T) {
+	qty := "2"
+	items := []Item{{ID: "1", Name: "milk", Quantity: &qty}, {ID: "2", Name: "bread"}}
+
+	got, err := RenderExport("markdown", items)
+	if err != nil {
+		t.Fatalf("renderExport returned error: %v", err)
+	}
+	if !strings.Contains(got, "- [ ] milk (2)\n") || !strings.Contains(got, "- [ ] bread\n") {
+		t.Fatalf("unexpected markdown: %q", got)
+	}
+}
+
+func TestRenderExportJSONMatchesListResponse(t *testing.T) {
+	qty := "1"
+	items := []Item{{ID: "1", Name: "milk", Quantity: &qty}, {ID: "2", Name: "bread"}}
+
+	got, err := RenderExport("json", items)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := json.MarshalIndent(ListItemsResponse{Items: items}, "", "  ")
+	if got != string(want) {
+		t.Fatalf("got %s, want %s", got, want)
+	}
+	if empty, _ := RenderExport("json", nil); empty != "{\n  \"items\": []\n}" {
+		t.Fatalf("unexpected empty export %q", empty)
+	}
+}
+
+func TestRenderExportRejectsUnknownFormat(t *testing.T) {
+	if _, err := RenderExport("xml", nil); err == nil {
+		t.Fatal("expected error for unknown format")
+	}
+}
+
+func TestExportStoreExpiresEntries(t *testing.T) {
+	store := NewExportStore(time.Minute, RandomIDs{})
+	now := time.Date(2025, 8, 12, 12, 0, 0, 0, time.UTC)
+
+	write := func(ctx context.Context, w io.Writer) error {
+		_, err := io.WriteString(w, "payload")
+		return err
+	}
+	uri := store.Put(write, "text/csv", now)
+	if !strings.HasPrefix(uri, ExportURIPrefix) {
+		t.Fatalf("unexpected uri %q", uri)
+	}
+	exp, ok := store.Get(uri, now.Add(30*time.Second))
+	if !ok {
+		t.Fatal("expected export to be retrievable before it expires")
+	}
+	var b strings.Builder
+	if err := exp.Write(context.Background(), &b); err != nil || b.String() != "payload" {
+		t.Fatalf("expected the export written on read, got %q (%v)", b.String(), err)
+	}
+	if _, ok := store.Get(uri, now.Add(time.Minute)); ok {
+		t.Fatal("expected export to expire")
+	}
+}
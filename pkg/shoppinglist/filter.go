@@ -0,0 +1,159 @@
+package shoppinglist
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// -----------------------------------------------------------------------------
+// Content filter
+// -----------------------------------------------------------------------------
+
+// What a ContentFilter does with blocked words.
+const (
+	FilterReject = "reject"
+	FilterMask   = "mask"
+)
+
+// FilterModes lists the supported filter modes.
+var FilterModes = []string{FilterReject, FilterMask}
+
+// ContentFilter blocks words from a denylist in item text, for shared family
+// lists that children's agents can also write to. Words match whole and
+// ignoring case; an entry of several words matches them in sequence.
+type ContentFilter struct {
+	entries [][]string
+	mode    string
+}
+
+// filterWords splits s into lowercase words with their byte offsets.
+func filterWords(s string) (words []string, spans [][2]int) {
+	start := -1
+	for i, r := range s + " " {
+		inWord := unicode.IsLetter(r) || unicode.IsDigit(r)
+		switch {
+		case inWord && start < 0:
+			start = i
+		case !inWord && start >= 0:
+			words = append(words, strings.ToLower(s[start:i]))
+			spans = append(spans, [2]int{start, i})
+			start = -1
+		}
+	}
+	return words, spans
+}
+
+// NewContentFilter builds a filter from denylist entries. Mode is
+// FilterReject to refuse text with a blocked word, or FilterMask to replace
+// it with asterisks.
+func NewContentFilter(entries []string, mode string) (*ContentFilter, error) {
+	if !slices.Contains(FilterModes, mode) {
+		return nil, fmt.Errorf("unsupported filter mode %q (expected one of %v)", mode, FilterModes)
+	}
+	f := &ContentFilter{mode: mode}
+	for _, e := range entries {
+		if words, _ := filterWords(e); len(words) > 0 {
+			f.entries = append(f.entries, words)
+		}
+	}
+	return f, nil
+}
+
+// LoadContentFilter reads a denylist with one word or phrase per line;
+// blank lines and lines starting with # are skipped. An empty path means no
+// filter.
+func LoadContentFilter(path, mode string) (*ContentFilter, error) {
+	if path == "" {
+		return nil, nil
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("read denylist: %w", err)
+	}
+	defer file.Close()
+
+	var entries []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" && !strings.HasPrefix(line, "#") {
+			entries = append(entries, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read denylist %s: %w", path, err)
+	}
+	return NewContentFilter(entries, mode)
+}
+
+// Clean returns text with blocked words masked, or a *RuleViolationError for
+// field when the filter rejects them.
+func (f *ContentFilter) Clean(field, text string) (string, error) {
+	if f == nil {
+		return text, nil
+	}
+	words, spans := filterWords(text)
+	var out strings.Builder
+	last := 0
+	for i := 0; i < len(words); i++ {
+		for _, entry := range f.entries {
+			if i+len(entry) > len(words) || !slices.Equal(words[i:i+len(entry)], entry) {
+				continue
+			}
+			if f.mode == FilterReject {
+				return "", &RuleViolationError{Field: field, Message: "contains a blocked word"}
+			}
+			start, end := spans[i][0], spans[i+len(entry)-1][1]
+			out.WriteString(text[last:start])
+			for _, r := range text[start:end] {
+				if unicode.IsSpace(r) {
+					out.WriteRune(r)
+				} else {
+					out.WriteByte('*')
+				}
+			}
+			last = end
+			i += len(entry) - 1
+			break
+		}
+	}
+	out.WriteString(text[last:])
+	return out.String(), nil
+}
+
+// Apply cleans the free text input sets: its name, category, and tags.
+func (f *ContentFilter) Apply(input *ItemInput) error {
+	if f == nil {
+		return nil
+	}
+	var err error
+	if input.Name, err = f.Clean("name", input.Name); err != nil {
+		return err
+	}
+	if input.Category != nil {
+		category, err := f.Clean("category", *input.Category)
+		if err != nil {
+			return err
+		}
+		input.Category = &category
+	}
+	if input.Tags != nil {
+		tags := make([]string, len(input.Tags))
+		for i, tag := range input.Tags {
+			if tags[i], err = f.Clean("tags", tag); err != nil {
+				return err
+			}
+		}
+		input.Tags = tags
+	}
+	return nil
+}
+
+// WithContentFilter applies a denylist to item text as it is written.
+func WithContentFilter(f *ContentFilter) ServiceOption {
+	return func(s *Service) { s.filter = f }
+}
@@ -0,0 +1,66 @@
+package shoppinglist
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestContentFilterMasksWholeWords(t *testing.T) {
+	f, err := NewContentFilter([]string{"darn", "heck no"}, FilterMask)
+	if err != nil {
+		t.Fatalf("NewContentFilter returned error: %v", err)
+	}
+	for in, want := range map[string]string{
+		"DARN cookies":        "**** cookies",
+		"darned socks stay":   "darned socks stay",
+		"heck no, more chips": "**** **, more chips",
+		"heck yes":            "heck yes",
+	} {
+		got, err := f.Clean("name", in)
+		if err != nil || got != want {
+			t.Errorf("Clean(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+}
+
+func TestContentFilterRejects(t *testing.T) {
+	f, _ := NewContentFilter([]string{"darn"}, FilterReject)
+	input := ItemInput{Name: "milk", Tags: []string{"snacks", "Darn"}}
+	var violation *RuleViolationError
+	if err := f.Apply(&input); !errors.As(err, &violation) || violation.Field != "tags" {
+		t.Fatalf("expected a tags violation, got %v", err)
+	}
+	if input.Tags[1] != "Darn" {
+		t.Error("expected a rejected write to leave the input's tags alone")
+	}
+}
+
+func TestNilContentFilterPassesText(t *testing.T) {
+	var f *ContentFilter
+	if got, err := f.Clean("name", "anything"); err != nil || got != "anything" {
+		t.Errorf("Clean = %q, %v", got, err)
+	}
+}
+
+func TestLoadContentFilter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "denylist.txt")
+	if err := os.WriteFile(path, []byte("# family list\n\ndarn\n  Heck No  \n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	f, err := LoadContentFilter(path, FilterMask)
+	if err != nil {
+		t.Fatalf("LoadContentFilter returned error: %v", err)
+	}
+	if len(f.entries) != 2 || !slices.Equal(f.entries[1], []string{"heck", "no"}) {
+		t.Errorf("entries = %q", f.entries)
+	}
+	if _, err := LoadContentFilter(path, "hide"); err == nil {
+		t.Error("expected an unsupported mode to be refused")
+	}
+	if f, err := LoadContentFilter("", FilterMask); f != nil || err != nil {
+		t.Errorf("expected no filter without a path, got %v, %v", f, err)
+	}
+}
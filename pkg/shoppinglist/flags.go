@@ -0,0 +1,258 @@
+package shoppinglist
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/firestore"
+)
+
+// -----------------------------------------------------------------------------
+// Feature flags
+// -----------------------------------------------------------------------------
+
+// FeatureFlag gates tools, or behaviors that check it, so big new
+// capabilities can be rolled out gradually on shared deployments. It is on
+// for the lists and tenants it names, for Percent of the other list and
+// tenant pairs, and otherwise when Enabled is set. A tenant is the name of the
+// API token a call was made with.
+type FeatureFlag struct {
+	Name        string    `json:"name" firestore:"-"`
+	Description string    `json:"description,omitempty" firestore:"description,omitempty"`
+	Tools       []string  `json:"tools,omitempty" firestore:"tools,omitempty"`
+	Enabled     bool      `json:"enabled" firestore:"enabled"`
+	Lists       []string  `json:"lists,omitempty" firestore:"lists,omitempty"` // list IDs
+	Tenants     []string  `json:"tenants,omitempty" firestore:"tenants,omitempty"`
+	Percent     int       `json:"percent,omitempty" firestore:"percent,omitempty"`
+	UpdatedAt   time.Time `json:"updated_at" firestore:"updated_at"`
+}
+
+// EnabledFor reports whether f is on for calls on list made by tenant.
+func (f FeatureFlag) EnabledFor(list ListInfo, tenant string) bool {
+	switch {
+	case f.Enabled, slices.Contains(f.Lists, list.ID), tenant != "" && slices.Contains(f.Tenants, tenant):
+		return true
+	case f.Percent <= 0:
+		return false
+	}
+	// Hashing the flag with the pair keeps each pair's bucket stable as the
+	// percentage grows, and independent between flags.
+	h := fnv.New32a()
+	h.Write([]byte(f.Name + "\x00" + list.ID + "\x00" + tenant))
+	return int(h.Sum32()%100) < f.Percent
+}
+
+// Dormant reports whether f is off for every list tenant calls on, so the
+// tools it gates can be left out of the tenant's tool list.
+func (f FeatureFlag) Dormant(tenant string) bool {
+	return !f.Enabled && len(f.Lists) == 0 && f.Percent <= 0 && (tenant == "" || !slices.Contains(f.Tenants, tenant))
+}
+
+// validateFlag checks a flag before it is stored.
+func validateFlag(f FeatureFlag) error {
+	if f.Name == "" || strings.ContainsAny(f.Name, "/ ") {
+		return fmt.Errorf("flag name %q must be non-empty without spaces or slashes", f.Name)
+	}
+	if f.Percent < 0 || f.Percent > 100 {
+		return fmt.Errorf("percent must be between 0 and 100")
+	}
+	return nil
+}
+
+// FlagCache holds the feature flags, kept current by RunFlagListener so
+// checks on every tool call cost no reads. A nil cache holds no flags.
+type FlagCache struct {
+	mu    sync.RWMutex
+	flags map[string]FeatureFlag
+}
+
+// Get returns the flag named name.
+func (c *FlagCache) Get(name string) (FeatureFlag, bool) {
+	if c == nil {
+		return FeatureFlag{}, false
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	f, ok := c.flags[name]
+	return f, ok
+}
+
+// Enabled reports whether the flag named name is on for calls on list made
+// by tenant, for behaviors gated in code. An unknown flag is off.
+func (c *FlagCache) Enabled(name string, list ListInfo, tenant string) bool {
+	f, ok := c.Get(name)
+	return ok && f.EnabledFor(list, tenant)
+}
+
+// All returns every flag, by name.
+func (c *FlagCache) All() []FeatureFlag {
+	if c == nil {
+		return nil
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	out := make([]FeatureFlag, 0, len(c.flags))
+	for _, f := range c.flags {
+		out = append(out, f)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// ForTool returns the flags gating tool.
+func (c *FlagCache) ForTool(tool string) []FeatureFlag {
+	if c == nil {
+		return nil
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	var out []FeatureFlag
+	for _, f := range c.flags {
+		if slices.Contains(f.Tools, tool) {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// Put stores f in the cache, replacing any flag of the same name, ahead of
+// the listener delivering it.
+func (c *FlagCache) Put(f FeatureFlag) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.flags == nil {
+		c.flags = map[string]FeatureFlag{}
+	}
+	c.flags[f.Name] = f
+}
+
+// replace swaps in the flags stored in docs.
+func (c *FlagCache) replace(docs []*firestore.DocumentSnapshot) {
+	flags := make(map[string]FeatureFlag, len(docs))
+	for _, d := range docs {
+		var f FeatureFlag
+		if err := d.DataTo(&f); err != nil {
+			log.Printf("warn: decode feature flag %q: %v", d.Ref.ID, err)
+			continue
+		}
+		f.Name = d.Ref.ID
+		flags[f.Name] = f
+	}
+	c.mu.Lock()
+	c.flags = flags
+	c.mu.Unlock()
+}
+
+// flagsCollection holds the feature flags, one document per flag, shared by
+// every list.
+func (s *Service) flagsCollection() *firestore.CollectionRef {
+	r := s.Root()
+	return r.client.Collection(r.collection + "_flags")
+}
+
+// Flags is the cache of feature flags, shared by every list. A nil service,
+// as when only describing the tools, has none.
+func (s *Service) Flags() *FlagCache {
+	if s == nil {
+		return nil
+	}
+	return s.Root().flags
+}
+
+// LoadFlags reads the feature flags into the cache.
+func (s *Service) LoadFlags(ctx context.Context) error {
+	docs, err := s.flagsCollection().Documents(ctx).GetAll()
+	if err != nil {
+		return fmt.Errorf("load feature flags: %w", err)
+	}
+	s.Flags().replace(docs)
+	return nil
+}
+
+// SetFlag stores f, replacing any flag of the same name.
+func (s *Service) SetFlag(ctx context.Context, f FeatureFlag) (FeatureFlag, error) {
+	if err := validateFlag(f); err != nil {
+		return FeatureFlag{}, err
+	}
+	f.UpdatedAt = s.Now()
+	if _, err := s.flagsCollection().Doc(f.Name).Set(ctx, f); err != nil {
+		return FeatureFlag{}, fmt.Errorf("set feature flag: %w", err)
+	}
+	// Gate this instance's calls at once rather than when the listener
+	// catches up, which a one-shot call never does.
+	s.Flags().Put(f)
+	return f, nil
+}
+
+// DeleteFlag removes the flag named name, ungating what it gated.
+func (s *Service) DeleteFlag(ctx context.Context, name string) error {
+	if _, err := s.flagsCollection().Doc(name).Delete(ctx); err != nil {
+		return fmt.Errorf("delete feature flag: %w", err)
+	}
+	return nil
+}
+
+// listenRetry is how long a snapshot listener waits before listening again
+// after it fails.
+const listenRetry = 10 * time.Second
+
+// RunFlagListener keeps the flag cache current with a Firestore snapshot
+// listener until ctx is done, so a flag changed on one instance takes effect
+// on all of them within moments.
+func RunFlagListener(ctx context.Context, service *Service) {
+	listen(ctx, service.flagsCollection().Query, "feature flag", func(docs []*firestore.DocumentSnapshot, _ time.Time) {
+		service.Flags().replace(docs)
+	}, nil)
+}
+
+// listen passes the documents of every snapshot of q, with the time it was
+// read, to apply until ctx is done, listening again after failures. lost,
+// when set, is called on each failure.
+func listen(ctx context.Context, q firestore.Query, what string, apply func([]*firestore.DocumentSnapshot, time.Time), lost func()) {
+	for {
+		it := q.Snapshots(ctx)
+		for {
+			snap, err := it.Next()
+			if err != nil {
+				if ctx.Err() == nil {
+					log.Printf("warn: %s listener: %v", what, err)
+				}
+				break
+			}
+			docs, err := snap.Documents.GetAll()
+			if err != nil {
+				log.Printf("warn: %s listener: %v", what, err)
+				break
+			}
+			apply(docs, snap.ReadTime)
+		}
+		it.Stop()
+		if lost != nil {
+			lost()
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(listenRetry):
+		}
+	}
+}
+
+// FlagsResponse lists the feature flags.
+type FlagsResponse struct {
+	Flags []FeatureFlag `json:"flags"`
+}
+
+// FlagResponse reports a feature flag set or deleted.
+type FlagResponse struct {
+	Flag    *FeatureFlag `json:"flag,omitempty"`
+	Deleted string       `json:"deleted,omitempty"`
+}
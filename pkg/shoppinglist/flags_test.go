@@ -0,0 +1,67 @@
+package shoppinglist
+
+import "testing"
+
+func TestFeatureFlagEnabledFor(t *testing.T) {
+	home := ListInfo{ID: "home"}
+	office := ListInfo{ID: "office"}
+
+	f := FeatureFlag{Name: "changes-feed", Lists: []string{"home"}, Tenants: []string{"beta"}}
+	if !f.EnabledFor(home, "") || !f.EnabledFor(office, "beta") {
+		t.Fatal("expected the flag on for its list and its tenant")
+	}
+	if f.EnabledFor(office, "") || f.EnabledFor(office, "other") {
+		t.Fatal("expected the flag off elsewhere")
+	}
+	if !(FeatureFlag{Enabled: true}).EnabledFor(office, "") {
+		t.Fatal("expected an enabled flag on everywhere")
+	}
+
+	// A pair's bucket is stable, so raising the percentage only adds pairs.
+	on := 0
+	for _, id := range []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j"} {
+		list := ListInfo{ID: id}
+		half := FeatureFlag{Name: "changes-feed", Percent: 50}
+		full := FeatureFlag{Name: "changes-feed", Percent: 100}
+		if half.EnabledFor(list, "") {
+			on++
+			if !full.EnabledFor(list, "") {
+				t.Fatalf("expected %s to stay on as the rollout grows", id)
+			}
+		}
+	}
+	if on == 0 || on == 10 {
+		t.Fatalf("expected a 50%% rollout to cover some lists, got %d of 10", on)
+	}
+}
+
+func TestFeatureFlagDormant(t *testing.T) {
+	f := FeatureFlag{Tenants: []string{"beta"}}
+	if !f.Dormant("") || !f.Dormant("other") {
+		t.Fatal("expected the flag dormant for tenants it does not name")
+	}
+	if f.Dormant("beta") {
+		t.Fatal("expected the flag live for its tenant")
+	}
+	if (FeatureFlag{Lists: []string{"home"}}).Dormant("") || (FeatureFlag{Percent: 5}).Dormant("") {
+		t.Fatal("expected flags on for some lists not to be dormant")
+	}
+}
+
+func TestValidateFlag(t *testing.T) {
+	if err := validateFlag(FeatureFlag{Name: "changes-feed", Percent: 100}); err != nil {
+		t.Fatalf("expected a valid flag, got %v", err)
+	}
+	for _, f := range []FeatureFlag{{}, {Name: "a/b"}, {Name: "two words"}, {Name: "x", Percent: 101}, {Name: "x", Percent: -1}} {
+		if err := validateFlag(f); err == nil {
+			t.Fatalf("expected %+v to be rejected", f)
+		}
+	}
+}
+
+func TestFlagCacheNil(t *testing.T) {
+	var c *FlagCache
+	if _, ok := c.Get("x"); ok || c.All() != nil || c.ForTool("get_changes") != nil || c.Enabled("x", ListInfo{}, "") {
+		t.Fatal("expected a nil cache to hold no flags")
+	}
+}
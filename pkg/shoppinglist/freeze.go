@@ -0,0 +1,119 @@
+package shoppinglist
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// -----------------------------------------------------------------------------
+// List freeze
+// -----------------------------------------------------------------------------
+
+// freezeDocID is the document in the meta collection holding the freeze state.
+const freezeDocID = "freeze"
+
+// ListFreeze records that the list is final and no new items may be added.
+type ListFreeze struct {
+	FrozenAt time.Time `json:"frozen_at" firestore:"frozen_at"`
+	Until    time.Time `json:"until" firestore:"until"`
+	Reason   string    `json:"reason,omitempty" firestore:"reason,omitempty"`
+}
+
+// Active reports whether the freeze is still in effect at now.
+func (f *ListFreeze) Active(now time.Time) bool {
+	return f != nil && now.Before(f.Until)
+}
+
+// ListFrozenError is returned when an addition is attempted on a frozen list.
+type ListFrozenError struct {
+	Until  time.Time
+	Reason string
+}
+
+func (e *ListFrozenError) Error() string {
+	msg := fmt.Sprintf("the list is frozen until %s, so no new items can be added", e.Until.UTC().Format(time.RFC3339))
+	if e.Reason != "" {
+		msg += fmt.Sprintf(" (%s)", e.Reason)
+	}
+	return msg + "; unfreeze the list once the trip is complete to add more"
+}
+
+// metaCollection holds server bookkeeping documents kept apart from the items.
+func (s *Service) metaCollection() *firestore.CollectionRef {
+	return s.client.Collection(s.collection + "_meta")
+}
+
+// FreezeList blocks further additions until the given time.
+func (s *Service) FreezeList(ctx context.Context, until time.Time, reason string) (*ListFreeze, error) {
+	now := s.Now()
+	if !until.After(now) {
+		return nil, fmt.Errorf("freeze end %s is not in the future", until.Format(time.RFC3339))
+	}
+
+	freeze := &ListFreeze{
+		FrozenAt: now,
+		Until:    until.UTC(),
+		Reason:   reason,
+	}
+	if _, err := s.metaCollection().Doc(freezeDocID).Set(ctx, freeze); err != nil {
+		return nil, fmt.Errorf("freeze list: %w", err)
+	}
+	return freeze, nil
+}
+
+// UnfreezeList lifts any freeze on the list.
+func (s *Service) UnfreezeList(ctx context.Context) error {
+	if _, err := s.metaCollection().Doc(freezeDocID).Delete(ctx); err != nil {
+		return fmt.Errorf("unfreeze list: %w", err)
+	}
+	return nil
+}
+
+// ActiveFreeze returns the current freeze, or nil when the list is not frozen.
+func (s *Service) ActiveFreeze(ctx context.Context) (*ListFreeze, error) {
+	freeze, err := decodeFreeze(s.metaCollection().Doc(freezeDocID).Get(ctx))
+	if err != nil || !freeze.Active(s.Now()) {
+		return nil, err
+	}
+	return freeze, nil
+}
+
+// decodeFreeze decodes the freeze document, treating a missing one as no freeze.
+func decodeFreeze(doc *firestore.DocumentSnapshot, err error) (*ListFreeze, error) {
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read freeze: %w", err)
+	}
+
+	var freeze ListFreeze
+	if err := doc.DataTo(&freeze); err != nil {
+		return nil, fmt.Errorf("decode freeze: %w", err)
+	}
+	return &freeze, nil
+}
+
+// checkNotFrozen returns a ListFrozenError while a freeze is active.
+func (s *Service) checkNotFrozen(ctx context.Context) error {
+	freeze, err := s.ActiveFreeze(ctx)
+	if err != nil {
+		return err
+	}
+	if freeze != nil {
+		return &ListFrozenError{Until: freeze.Until, Reason: freeze.Reason}
+	}
+	return nil
+}
+
+// FreezeStatusResponse reports the freeze state of the list.
+type FreezeStatusResponse struct {
+	Frozen bool        `json:"frozen"`
+	Freeze *ListFreeze `json:"freeze,omitempty"`
+	ResponseWarnings
+}
@@ -0,0 +1,37 @@
+package shoppinglist
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestListFreezeActive(t *testing.T) {
+	now := time.Date(2025, 8, 12, 14, 0, 0, 0, time.UTC)
+	freeze := &ListFreeze{FrozenAt: now, Until: now.Add(time.Hour)}
+
+	if !freeze.Active(now.Add(30 * time.Minute)) {
+		t.Fatal("expected freeze to be active before it expires")
+	}
+	if freeze.Active(now.Add(time.Hour)) {
+		t.Fatal("expected freeze to be inactive once it expires")
+	}
+
+	var none *ListFreeze
+	if none.Active(now) {
+		t.Fatal("expected nil freeze to be inactive")
+	}
+}
+
+func TestListFrozenErrorIncludesUnfreezeTime(t *testing.T) {
+	until := time.Date(2025, 8, 12, 16, 30, 0, 0, time.UTC)
+	err := &ListFrozenError{Until: until, Reason: "at the store"}
+
+	msg := err.Error()
+	if !strings.Contains(msg, "2025-08-12T16:30:00Z") {
+		t.Fatalf("expected unfreeze time in message, got %q", msg)
+	}
+	if !strings.Contains(msg, "at the store") {
+		t.Fatalf("expected reason in message, got %q", msg)
+	}
+}
@@ -0,0 +1,146 @@
+package shoppinglist
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// -----------------------------------------------------------------------------
+// Grouped items
+// -----------------------------------------------------------------------------
+
+// ItemGroup is a top-level item with the items nested under it.
+type ItemGroup struct {
+	Item
+	Children []Item `json:"children,omitempty"`
+}
+
+// GroupedItemsResponse wraps a nested list response.
+type GroupedItemsResponse struct {
+	Groups   []ItemGroup `json:"groups"`
+	ReadTime *time.Time  `json:"read_time,omitempty"`
+	Display  *Display    `json:"display,omitempty"`
+	ResponseWarnings
+}
+
+// GroupItems nests children under their parents, preserving order. Items whose
+// parent is missing are treated as top-level.
+func GroupItems(items []Item) []ItemGroup {
+	index := make(map[string]int, len(items))
+	groups := make([]ItemGroup, 0, len(items))
+	for _, it := range items {
+		if it.ParentID == nil || *it.ParentID == "" {
+			index[it.ID] = len(groups)
+			groups = append(groups, ItemGroup{Item: it})
+		}
+	}
+
+	var orphans []ItemGroup
+	for _, it := range items {
+		if it.ParentID == nil || *it.ParentID == "" {
+			continue
+		}
+		if i, ok := index[*it.ParentID]; ok {
+			groups[i].Children = append(groups[i].Children, it)
+			continue
+		}
+		orphans = append(orphans, ItemGroup{Item: it})
+	}
+	return append(groups, orphans...)
+}
+
+// validateParent checks that parentID can hold id as a child: the parent must
+// exist in the same list and items nest only one level deep.
+func (s *Service) validateParent(ctx context.Context, id, parentID string) error {
+	if parentID == id {
+		return errors.New("an item cannot be its own parent")
+	}
+	parent, err := s.GetItem(ctx, parentID)
+	if err != nil {
+		return fmt.Errorf("parent %q: %w", parentID, err)
+	}
+	if parent.ParentID != nil && *parent.ParentID != "" {
+		return fmt.Errorf("parent %q is itself nested; items can only be nested one level deep", parentID)
+	}
+	if id == "" {
+		return nil
+	}
+	children, err := liveDocuments(ctx, nil, s.client.Collection(s.collection).Where("parent_id", "==", id).OrderBy(firestore.DocumentID, firestore.Asc), 1)
+	if err != nil {
+		return fmt.Errorf("check children: %w", err)
+	}
+	if len(children) > 0 {
+		return fmt.Errorf("item %q has children of its own and cannot be nested", id)
+	}
+	return nil
+}
+
+// removalTarget checks the item id, read as doc and err, can be removed
+// under pre, returning it and the preconditions guarding its removal. A
+// missing or trashed item fails with ErrItemNotFound, or with
+// ErrItemConflict when pre expected it to be there.
+func removalTarget(id string, doc *firestore.DocumentSnapshot, err error, pre Precondition) (Item, []firestore.Precondition, error) {
+	it := Item{ID: id}
+	switch {
+	case err == nil:
+		_ = doc.DataTo(&it)
+		preconds, err := pre.preconditions(it, doc)
+		return it, preconds, err
+	case status.Code(err) != codes.NotFound:
+		return it, nil, err
+	case !pre.IsZero():
+		return it, nil, fmt.Errorf("%w: %q was removed", ErrItemConflict, id)
+	default:
+		// Firestore deletes missing documents without complaint.
+		return it, nil, fmt.Errorf("%w: %q", ErrItemNotFound, id)
+	}
+}
+
+// removeWithChildren deletes id and either deletes its children (cascade) or
+// promotes them to top-level items, in one transaction. It returns the items
+// deleted and the children promoted, as they were.
+func (s *Service) removeWithChildren(ctx context.Context, id string, cascade bool, pre Precondition) (removed, promoted []Item, err error) {
+	col := s.client.Collection(s.collection)
+	err = s.runTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := untrashed(tx.Get(col.Doc(id)))
+		it, preconds, err := removalTarget(id, doc, err, pre)
+		if err != nil {
+			return err
+		}
+		removed, promoted = []Item{it}, nil
+		children, err := tx.Documents(col.Where("parent_id", "==", id)).GetAll()
+		if err != nil {
+			return err
+		}
+		for _, child := range children {
+			if trashed(child) {
+				continue
+			}
+			if cascade {
+				kid := decodeItems([]*firestore.DocumentSnapshot{child})
+				if len(kid) == 1 {
+					err = s.trashItem(tx, child.Ref)
+				} else {
+					err = s.deleteItem(tx, child.Ref)
+				}
+				if err != nil {
+					return err
+				}
+				removed = append(removed, kid...)
+				continue
+			}
+			if err := tx.Update(child.Ref, withRevision([]firestore.Update{{Path: "parent_id", Value: firestore.Delete}}, s.writeTime())); err != nil {
+				return err
+			}
+			promoted = append(promoted, decodeItems([]*firestore.DocumentSnapshot{child})...)
+		}
+		return s.trashItem(tx, col.Doc(id), preconds...)
+	})
+	return removed, promoted, err
+}
@@ -0,0 +1,68 @@
+package shoppinglist
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestGroupItemsNestsChildrenUnderParents(t *testing.T) {
+	parent := "tacos"
+	missing := "gone"
+	items := []Item{
+		{ID: "cheese", Name: "cheese", ParentID: &parent},
+		{ID: "tacos", Name: "Taco night"},
+		{ID: "milk", Name: "milk"},
+		{ID: "salsa", Name: "salsa", ParentID: &parent},
+		{ID: "orphan", Name: "orphan", ParentID: &missing},
+	}
+
+	groups := GroupItems(items)
+	if len(groups) != 3 {
+		t.Fatalf("expected 3 groups, got %d", len(groups))
+	}
+	if groups[0].ID != "tacos" || len(groups[0].Children) != 2 || groups[0].Children[0].ID != "cheese" {
+		t.Fatalf("unexpected taco group: %+v", groups[0])
+	}
+	if groups[1].ID != "milk" || len(groups[1].Children) != 0 {
+		t.Fatalf("unexpected second group: %+v", groups[1])
+	}
+	if groups[2].ID != "orphan" {
+		t.Fatalf("expected orphan last, got %q", groups[2].ID)
+	}
+}
+
+func TestRenderExportMarkdownIndentsChildren(t *testing.T) {
+	parent := "tacos"
+	items := []Item{{ID: "tacos", Name: "Taco night"}, {ID: "salsa", Name: "salsa", ParentID: &parent}}
+
+	got, err := RenderExport("markdown", items)
+	if err != nil {
+		t.Fatalf("renderExport returned error: %v", err)
+	}
+	if !strings.Contains(got, "- [ ] Taco night\n  - [ ] salsa\n") {
+		t.Fatalf("unexpected markdown: %q", got)
+	}
+}
+
+func TestRemovalTargetReportsMissingItems(t *testing.T) {
+	missing := status.Error(codes.NotFound, "no such document")
+
+	_, _, err := removalTarget("tacos", nil, missing, Precondition{})
+	if !errors.Is(err, ErrItemNotFound) || !strings.Contains(err.Error(), `"tacos"`) {
+		t.Fatalf("expected a missing grouped item to be not found, got %v", err)
+	}
+
+	rev := int64(3)
+	if _, _, err := removalTarget("tacos", nil, missing, Precondition{Revision: &rev}); !errors.Is(err, ErrItemConflict) {
+		t.Fatalf("expected a conditional removal of a missing item to conflict, got %v", err)
+	}
+
+	unavailable := status.Error(codes.Unavailable, "try again")
+	if _, _, err := removalTarget("tacos", nil, unavailable, Precondition{}); err != unavailable {
+		t.Fatalf("expected other read errors to pass through, got %v", err)
+	}
+}
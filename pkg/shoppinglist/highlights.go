@@ -0,0 +1,120 @@
+package shoppinglist
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// -----------------------------------------------------------------------------
+// List highlights
+// -----------------------------------------------------------------------------
+
+// urgentWindow is how soon an item must be needed to count as urgent.
+const urgentWindow = 48 * time.Hour
+
+// ListHighlights are the facts a list summary is written from: what is left,
+// what is urgent, and what it should cost.
+type ListHighlights struct {
+	Counts         SummaryCounts `json:"counts"`
+	Urgent         []string      `json:"urgent,omitempty"`
+	Overdue        []string      `json:"overdue,omitempty"`
+	NextNeededBy   *time.Time    `json:"next_needed_by,omitempty"`
+	NextNeeded     []string      `json:"next_needed,omitempty"`
+	TopCategories  []string      `json:"top_categories,omitempty"`
+	EstimatedTotal float64       `json:"estimated_total"`
+	Currency       string        `json:"currency"`
+	Budget         float64       `json:"budget,omitempty"`
+	OverBudget     bool          `json:"over_budget,omitempty"`
+	Unpriced       int           `json:"unpriced,omitempty"`
+}
+
+// ListSummaryText is a natural-language summary of a list with the
+// highlights it was written from.
+type ListSummaryText struct {
+	Summary    string         `json:"summary"`
+	Highlights ListHighlights `json:"highlights"`
+	ResponseWarnings
+}
+
+// BuildHighlights picks out the highlights of items at now. Urgent items are
+// those still to buy that are high priority or needed within two days;
+// overdue ones were needed before now. Prices are totalled over the items
+// still to buy in currency and checked against budget.
+func BuildHighlights(ctx context.Context, rates ExchangeRateSource, currency string, budget float64, items []Item, now time.Time) (ListHighlights, ResponseWarnings) {
+	report := EstimateTotal(ctx, rates, currency, items)
+	CheckBudget(ctx, &report, rates, budget, currency)
+	h := ListHighlights{
+		Counts:         countItems(items),
+		EstimatedTotal: report.Total,
+		Currency:       report.Currency,
+		Budget:         report.Budget,
+		OverBudget:     report.Budget > 0 && report.Total > report.Budget,
+		Unpriced:       len(report.Unpriced),
+	}
+	h.NextNeededBy, h.NextNeeded = nextNeeded(items)
+
+	sections := map[string]int{}
+	for _, it := range items {
+		if it.Purchased {
+			continue
+		}
+		sections[categoryOf(it)]++
+		switch {
+		case it.NeededBy != nil && it.NeededBy.Before(now):
+			h.Overdue = append(h.Overdue, it.Name)
+		case priorityOf(it) == PriorityHigh, it.NeededBy != nil && it.NeededBy.Before(now.Add(urgentWindow)):
+			h.Urgent = append(h.Urgent, it.Name)
+		}
+	}
+	sort.Strings(h.Urgent)
+	sort.Strings(h.Overdue)
+	for c := range sections {
+		if c != uncategorized {
+			h.TopCategories = append(h.TopCategories, c)
+		}
+	}
+	sort.Slice(h.TopCategories, func(i, j int) bool {
+		a, b := h.TopCategories[i], h.TopCategories[j]
+		if sections[a] != sections[b] {
+			return sections[a] > sections[b]
+		}
+		return a < b
+	})
+	h.TopCategories = h.TopCategories[:min(len(h.TopCategories), 3)]
+	return h, report.ResponseWarnings
+}
+
+// PlainSummary writes the highlights as a paragraph without a model, for
+// clients that cannot sample.
+func PlainSummary(h ListHighlights) string {
+	c := h.Counts
+	if c.Total == 0 {
+		return "The list is empty."
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d of %d items are still to buy", c.ToBuy, c.Total)
+	if len(h.TopCategories) > 0 {
+		fmt.Fprintf(&b, ", mostly %s", strings.Join(h.TopCategories, ", "))
+	}
+	b.WriteString(".")
+	if len(h.Overdue) > 0 {
+		fmt.Fprintf(&b, " Overdue: %s.", strings.Join(h.Overdue, ", "))
+	}
+	if len(h.Urgent) > 0 {
+		fmt.Fprintf(&b, " Urgent: %s.", strings.Join(h.Urgent, ", "))
+	}
+	if c.ToBuy > 0 {
+		fmt.Fprintf(&b, " The rest should cost about %.2f %s", h.EstimatedTotal, h.Currency)
+		if h.Unpriced > 0 {
+			fmt.Fprintf(&b, ", not counting %d unpriced items", h.Unpriced)
+		}
+		b.WriteString(".")
+	}
+	if h.OverBudget {
+		fmt.Fprintf(&b, " That is over the budget of %.2f %s.", h.Budget, h.Currency)
+	}
+	return b.String()
+}
@@ -0,0 +1,48 @@
+package shoppinglist
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuildHighlightsPicksUrgentAndOverdue(t *testing.T) {
+	now := time.Date(2025, 8, 12, 12, 0, 0, 0, time.UTC)
+	high, produce, dairy := PriorityHigh, "produce", "dairy"
+	yesterday, tomorrow, nextWeek := now.Add(-24*time.Hour), now.Add(24*time.Hour), now.Add(7*24*time.Hour)
+	price := 3.0
+	items := []Item{
+		{ID: "1", Name: "cake", NeededBy: &yesterday},
+		{ID: "2", Name: "milk", Category: &dairy, NeededBy: &tomorrow, Price: &price},
+		{ID: "3", Name: "batteries", Priority: &high},
+		{ID: "4", Name: "apples", Category: &produce, NeededBy: &nextWeek},
+		{ID: "5", Name: "pears", Category: &produce},
+		{ID: "6", Name: "bread", Priority: &high, Purchased: true},
+	}
+
+	h, _ := BuildHighlights(context.Background(), RateTable{}, "USD", 0, items, now)
+	if strings.Join(h.Overdue, ",") != "cake" {
+		t.Fatalf("expected cake overdue, got %v", h.Overdue)
+	}
+	if strings.Join(h.Urgent, ",") != "batteries,milk" {
+		t.Fatalf("expected batteries and milk urgent, got %v", h.Urgent)
+	}
+	if len(h.TopCategories) != 2 || h.TopCategories[0] != "produce" {
+		t.Fatalf("expected produce first, got %v", h.TopCategories)
+	}
+	if h.EstimatedTotal != 3 || h.Unpriced != 4 {
+		t.Fatalf("expected 3.00 with 4 unpriced, got %.2f and %d", h.EstimatedTotal, h.Unpriced)
+	}
+}
+
+func TestPlainSummary(t *testing.T) {
+	if got := PlainSummary(ListHighlights{}); got != "The list is empty." {
+		t.Fatalf("unexpected empty summary %q", got)
+	}
+	h := ListHighlights{Counts: SummaryCounts{Total: 3, ToBuy: 2}, Urgent: []string{"milk"}, EstimatedTotal: 4.5, Currency: "USD"}
+	want := "2 of 3 items are still to buy. Urgent: milk. The rest should cost about 4.50 USD."
+	if got := PlainSummary(h); got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,210 @@
+package shoppinglist
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// -----------------------------------------------------------------------------
+// Resumable imports
+// -----------------------------------------------------------------------------
+
+const (
+	// importChunkWrites leaves room in Firestore's 500 writes per commit for
+	// the progress document written with each chunk.
+	importChunkWrites = 499
+	// importChunkBytes keeps a chunk well under the 10 MiB request limit.
+	importChunkBytes = 8 << 20
+	// importRecordTimeout bounds recording why an import failed.
+	importRecordTimeout = 5 * time.Second
+)
+
+// Import statuses.
+const (
+	ImportRunning = "running"
+	ImportFailed  = "failed"
+	ImportDone    = "done"
+)
+
+// ImportProgress is the state of an import, kept in the meta collection so a
+// failed import can resume from its last committed chunk.
+type ImportProgress struct {
+	ID          string    `json:"import_id" firestore:"id"`
+	Fingerprint string    `json:"-" firestore:"fingerprint"`
+	Total       int       `json:"total" firestore:"total"`
+	Committed   int       `json:"committed" firestore:"committed"`
+	Chunks      int       `json:"chunks" firestore:"chunks"`
+	Status      string    `json:"status" firestore:"status"`
+	Error       string    `json:"error,omitempty" firestore:"error,omitempty"`
+	StartedAt   time.Time `json:"started_at" firestore:"started_at"`
+	UpdatedAt   time.Time `json:"updated_at" firestore:"updated_at"`
+}
+
+// ImportResponse wraps an import's progress.
+type ImportResponse struct {
+	Import ImportProgress `json:"import"`
+	ResponseWarnings
+}
+
+// ImportProgressFunc is told the progress after each committed chunk.
+type ImportProgressFunc func(ImportProgress)
+
+// importDocID is the meta document holding an import's progress.
+func importDocID(id string) string { return "import-" + id }
+
+// importFingerprint identifies the items of an import, so a resume with
+// different items is refused rather than skipping the wrong ones.
+func importFingerprint(inputs []ItemInput) string {
+	b, _ := json.Marshal(inputs)
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// importChunks splits items into consecutive chunks of at most maxWrites
+// items and, where possible, maxBytes of encoded size, returning the end
+// index of each chunk. An item larger than maxBytes gets a chunk of its own.
+func importChunks(sizes []int, maxWrites, maxBytes int) []int {
+	var ends []int
+	n, bytes := 0, 0
+	for i, size := range sizes {
+		if n > 0 && (n == maxWrites || bytes+size > maxBytes) {
+			ends = append(ends, i)
+			n, bytes = 0, 0
+		}
+		n++
+		bytes += size
+	}
+	if n > 0 {
+		ends = append(ends, len(sizes))
+	}
+	return ends
+}
+
+// ImportItems adds a large set of items in chunks that fit Firestore's commit
+// limits. Each chunk is committed atomically with the import's progress, so
+// calling ImportItems again with the same id and items after a failure
+// resumes from the last committed chunk without adding anything twice. An
+// empty id starts a new import.
+func (s *Service) ImportItems(ctx context.Context, id string, inputs []ItemInput, progress ImportProgressFunc) (ImportProgress, error) {
+	if err := s.checkNotFrozen(ctx); err != nil {
+		return ImportProgress{}, err
+	}
+	if id == "" {
+		id = s.NewID()
+	}
+	ref := s.metaCollection().Doc(importDocID(id))
+	fingerprint := importFingerprint(inputs)
+	inputs = slices.Clone(inputs)
+	for i := range inputs {
+		if err := s.checkInput(&inputs[i]); err != nil {
+			return ImportProgress{}, fmt.Errorf("item %d: %w", i, err)
+		}
+	}
+
+	var p ImportProgress
+	doc, err := ref.Get(ctx)
+	switch {
+	case status.Code(err) == codes.NotFound:
+		now := s.Now()
+		p = ImportProgress{ID: id, Fingerprint: fingerprint, Total: len(inputs), Status: ImportRunning, StartedAt: now, UpdatedAt: now}
+		if _, err := ref.Create(ctx, p); err != nil {
+			return p, fmt.Errorf("start import: %w", err)
+		}
+	case err != nil:
+		return p, fmt.Errorf("read import: %w", err)
+	default:
+		if err := doc.DataTo(&p); err != nil {
+			return p, fmt.Errorf("decode import: %w", err)
+		}
+		if p.Fingerprint != fingerprint {
+			return p, fmt.Errorf("import %s was started with different items; resume it with the same items in the same order", id)
+		}
+		if p.Status == ImportDone {
+			return p, nil
+		}
+	}
+
+	items := make([]Item, 0, len(inputs)-p.Committed)
+	sizes := make([]int, 0, cap(items))
+	for _, input := range inputs[p.Committed:] {
+		it := s.newItem(input, s.writeTime())
+		b, _ := json.Marshal(it)
+		items = append(items, it)
+		sizes = append(sizes, len(b))
+	}
+
+	col := s.client.Collection(s.collection)
+	start := 0
+	for _, end := range importChunks(sizes, importChunkWrites, importChunkBytes) {
+		chunk := items[start:end]
+		next := p
+		next.Committed += len(chunk)
+		next.Chunks++
+		next.Error = ""
+		next.Status = ImportRunning
+		if next.Committed == next.Total {
+			next.Status = ImportDone
+		}
+		next.UpdatedAt = s.Now()
+		err := s.runTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+			trash := map[string]bool{}
+			if s.derivedIDs {
+				refs := make([]*firestore.DocumentRef, len(chunk))
+				for i, it := range chunk {
+					refs[i] = col.Doc(it.ID)
+				}
+				docs, err := tx.GetAll(refs)
+				if err != nil {
+					return err
+				}
+				trash = trashedIDs(docs)
+			}
+			for _, it := range chunk {
+				if err := createItem(tx, col.Doc(it.ID), it, trash[it.ID]); err != nil {
+					return err
+				}
+			}
+			return tx.Set(ref, next)
+		})
+		if err != nil {
+			return s.failImport(ctx, p, err), fmt.Errorf("import items: %w", err)
+		}
+		p = next
+		start = end
+		s.observe(ctx, activityAdd, len(chunk))
+		s.recordActivity(ctx, ActionAdded, chunk...)
+		s.recordAudit(ctx, itemChanges(ActionAdded, chunk)...)
+		if progress != nil {
+			progress(p)
+		}
+	}
+	return p, nil
+}
+
+// failImport records why an import stopped, logging rather than masking the
+// original error when the record cannot be written. In explain mode nothing
+// was written, so nothing is recorded either.
+func (s *Service) failImport(ctx context.Context, p ImportProgress, cause error) ImportProgress {
+	p.Status, p.Error, p.UpdatedAt = ImportFailed, cause.Error(), s.Now()
+	if explanationFrom(ctx) != nil {
+		return p
+	}
+	// The request context may be what failed, so record it without its
+	// cancellation.
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), importRecordTimeout)
+	defer cancel()
+	if _, err := s.metaCollection().Doc(importDocID(p.ID)).Set(ctx, p); err != nil {
+		log.Printf("warn: record failed import %s: %v", p.ID, err)
+	}
+	return p
+}
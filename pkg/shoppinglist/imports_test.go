@@ -0,0 +1,44 @@
+package shoppinglist
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestImportChunksRespectsWriteAndByteLimits(t *testing.T) {
+	sizes := []int{10, 10, 10, 10, 10}
+	if got := importChunks(sizes, 2, 1000); !slices.Equal(got, []int{2, 4, 5}) {
+		t.Errorf("by writes: %v", got)
+	}
+	if got := importChunks(sizes, 10, 25); !slices.Equal(got, []int{2, 4, 5}) {
+		t.Errorf("by bytes: %v", got)
+	}
+	if got := importChunks([]int{5, 100, 5}, 10, 50); !slices.Equal(got, []int{1, 2, 3}) {
+		t.Errorf("oversized item: %v", got)
+	}
+	if got := importChunks(nil, 10, 50); len(got) != 0 {
+		t.Errorf("empty: %v", got)
+	}
+}
+
+func TestImportFingerprintDependsOnItemsAndOrder(t *testing.T) {
+	a := []ItemInput{{Name: "milk"}, {Name: "eggs"}}
+	b := []ItemInput{{Name: "eggs"}, {Name: "milk"}}
+	if importFingerprint(a) != importFingerprint([]ItemInput{{Name: "milk"}, {Name: "eggs"}}) {
+		t.Error("expected the same items to give the same fingerprint")
+	}
+	if importFingerprint(a) == importFingerprint(b) {
+		t.Error("expected a different order to give a different fingerprint")
+	}
+}
+
+func TestFailImportWritesNothingWhenExplained(t *testing.T) {
+	// The service has no Firestore client, so any write would panic.
+	s := &Service{clock: SystemClock{}}
+	ctx, _ := WithExplain(context.Background())
+	p := s.failImport(ctx, ImportProgress{ID: "abc", Status: ImportRunning}, ErrExplained)
+	if p.Status != ImportFailed || p.Error != ErrExplained.Error() {
+		t.Fatalf("expected the import to be reported failed, got %+v", p)
+	}
+}
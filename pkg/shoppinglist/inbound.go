@@ -0,0 +1,249 @@
+package shoppinglist
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+
+	"cloud.google.com/go/firestore"
+)
+
+// -----------------------------------------------------------------------------
+// Inbound webhook
+// -----------------------------------------------------------------------------
+
+// InboundMaxBytes caps the size of an inbound message; forwarded emails are
+// the largest expected.
+const InboundMaxBytes = 256 << 10
+
+// InboundItem is an item parsed from, or given in, an inbound message.
+type InboundItem struct {
+	Name     string `json:"name"`
+	Quantity string `json:"quantity,omitempty"`
+}
+
+// InboundResult reports what an inbound message added.
+type InboundResult struct {
+	List    string   `json:"list"`
+	Added   []Item   `json:"added"`
+	Skipped []string `json:"already_listed,omitempty"`
+	ResponseWarnings
+}
+
+var (
+	// inboundCommandRe matches a leading request such as "Add", "please buy",
+	// or "we need".
+	inboundCommandRe = regexp.MustCompile(`(?i)^(?:(?:please|pls|can you|could you)\s+)?(?:add|buy|get|pick up|grab|need|we need|i need)\b:?\s*`)
+	// inboundListRe matches a trailing "to the shopping list".
+	inboundListRe = regexp.MustCompile(`(?i)\s+(?:to|on(?:to)?)\s+(?:the|my|our)?\s*(?:shopping|grocery)?\s*list$`)
+	// inboundSplitRe separates items within a line.
+	inboundSplitRe = regexp.MustCompile(`(?i)\s*(?:[,;+&]|\band\b)\s*`)
+	// inboundCountRe splits "2 milk" or "3x eggs" into the count and the rest.
+	inboundCountRe = regexp.MustCompile(`^(\d(?:[\d.,']*\d)?)\s*(?:x\b)?\s*(.+)$`)
+)
+
+// parseInboundText reads items from a message such as "add milk, 2 bananas
+// and 500 g flour". Each line may hold several items; quoted replies and an
+// email signature are ignored.
+func parseInboundText(text string, p HouseholdProfile) []InboundItem {
+	var items []InboundItem
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "--" {
+			break
+		}
+		if strings.HasPrefix(line, ">") {
+			continue
+		}
+		line = strings.TrimLeft(line, "-*• \t")
+		line = strings.TrimRight(line, ".!? \t")
+		line = inboundListRe.ReplaceAllString(inboundCommandRe.ReplaceAllString(line, ""), "")
+		for _, part := range inboundSplitRe.Split(line, -1) {
+			if it, ok := parseInboundItem(part, p); ok {
+				items = append(items, it)
+			}
+		}
+	}
+	return items
+}
+
+// parseInboundItem reads one item, taking a leading count, and a size unit
+// such as "g" or "l" after it, as its quantity.
+func parseInboundItem(s string, p HouseholdProfile) (InboundItem, bool) {
+	s, _ = NormalizeItemName(strings.Trim(s, `"' `))
+	if rest, ok := strings.CutPrefix(strings.ToLower(s), "some "); ok {
+		s = s[len(s)-len(rest):]
+	}
+	if s == "" {
+		return InboundItem{}, false
+	}
+	m := inboundCountRe.FindStringSubmatch(s)
+	if m == nil {
+		return InboundItem{Name: s}, true
+	}
+	if _, err := p.ParseNumber(m[1]); err != nil {
+		return InboundItem{Name: s}, true
+	}
+	quantity, name := m[1], m[2]
+	if unit, rest, ok := strings.Cut(name, " "); ok {
+		if _, isSize := sizeUnits[unitKey(unit)]; isSize {
+			quantity += " " + unit
+			name = strings.TrimPrefix(strings.TrimSpace(rest), "of ")
+		}
+	}
+	return InboundItem{Name: name, Quantity: quantity}, true
+}
+
+// inboundPayload is a JSON inbound message: explicit items, or text to parse.
+type inboundPayload struct {
+	Items   []InboundItem `json:"items"`
+	Text    string        `json:"text"`
+	Message string        `json:"message"`
+	Subject string        `json:"subject"`
+	List    string        `json:"list"`
+}
+
+// inboundFormFields are the form fields read as message text, covering SMS
+// gateways (Twilio's Body) and email forwarding services (Mailgun's
+// stripped-text and body-plain, SendGrid's text).
+var inboundFormFields = []string{"subject", "stripped-text", "body-plain", "text", "Body", "message"}
+
+// ReadInbound decodes an inbound request into items and the list it names.
+func ReadInbound(r *http.Request, p HouseholdProfile) ([]InboundItem, string, error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case "application/json":
+		var payload inboundPayload
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			return nil, "", fmt.Errorf("decode JSON: %w", err)
+		}
+		var items []InboundItem
+		for _, it := range payload.Items {
+			if it.Name, _ = NormalizeItemName(it.Name); it.Name != "" {
+				items = append(items, it)
+			}
+		}
+		for _, text := range []string{payload.Subject, payload.Text, payload.Message} {
+			items = append(items, parseInboundText(text, p)...)
+		}
+		return items, payload.List, nil
+	case "application/x-www-form-urlencoded", "multipart/form-data":
+		if err := r.ParseMultipartForm(InboundMaxBytes); err != nil && !errors.Is(err, http.ErrNotMultipart) {
+			return nil, "", fmt.Errorf("parse form: %w", err)
+		}
+		var items []InboundItem
+		seen := map[string]bool{}
+		for _, field := range inboundFormFields {
+			text := r.PostFormValue(field)
+			if seen[text] {
+				// body-plain and stripped-text often repeat each other
+				continue
+			}
+			seen[text] = true
+			items = append(items, parseInboundText(text, p)...)
+		}
+		return items, r.PostFormValue("list"), nil
+	default:
+		b, err := io.ReadAll(r.Body)
+		if err != nil {
+			return nil, "", fmt.Errorf("read body: %w", err)
+		}
+		return parseInboundText(string(b), p), "", nil
+	}
+}
+
+// AddInbound adds items that are not already waiting on the list, matching
+// names through the normalizer.
+func (s *Service) AddInbound(ctx context.Context, items []InboundItem) (InboundResult, error) {
+	if err := s.checkNotFrozen(ctx); err != nil {
+		return InboundResult{}, err
+	}
+	items = slices.Clone(items)
+	for i := range items {
+		var err error
+		if items[i].Name, err = s.filter.Clean("name", items[i].Name); err != nil {
+			return InboundResult{}, err
+		}
+	}
+	col := s.client.Collection(s.collection)
+	at := s.writeTime()
+	var (
+		res    InboundResult
+		commit firestore.CommitResponse
+	)
+	err := s.runTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		res = InboundResult{}
+		docs, err := tx.Documents(col).GetAll()
+		if err != nil {
+			return err
+		}
+		onList := map[string]bool{}
+		// With derived IDs a checked-off item holds the ID a new one would
+		// get, so it is put back instead.
+		checked := map[string]Item{}
+		for _, it := range decodeItems(docs) {
+			if !it.Purchased {
+				onList[s.normalizer.Key(it.Name)] = true
+			} else if s.derivedIDs {
+				checked[it.ID] = it
+			}
+		}
+		trash := trashedIDs(docs)
+		for _, in := range items {
+			key := s.normalizer.Key(in.Name)
+			if onList[key] {
+				res.Skipped = append(res.Skipped, in.Name)
+				continue
+			}
+			onList[key] = true
+			it := Item{ID: s.newItemID(in.Name), Name: in.Name, CreatedAt: at, UpdatedAt: &at, Revision: 1}
+			if old, ok := checked[it.ID]; ok {
+				updates := uncheckUpdates()
+				if in.Quantity != "" {
+					updates = append(updates, firestore.Update{Path: "quantity", Value: in.Quantity})
+					updates = append(updates, amountUpdates(in.Quantity, s.profile)...)
+				}
+				if err := tx.Update(col.Doc(old.ID), withRevision(updates, s.writeTime())); err != nil {
+					return err
+				}
+				old.Purchased, old.PurchasedAt = false, nil
+				if in.Quantity != "" {
+					q := in.Quantity
+					old.Quantity = &q
+					withAmount(&old, s.profile)
+				}
+				res.Added = append(res.Added, old)
+				continue
+			}
+			if in.Quantity != "" {
+				q := in.Quantity
+				it.Quantity = &q
+				withAmount(&it, s.profile)
+			}
+			if err := createItem(tx, col.Doc(it.ID), it, trash[it.ID]); err != nil {
+				return err
+			}
+			res.Added = append(res.Added, it)
+		}
+		return nil
+	}, firestore.WithCommitResponseTo(&commit))
+	if err != nil {
+		return InboundResult{}, fmt.Errorf("add inbound items: %w", err)
+	}
+	for i := range res.Added {
+		s.stamped(&res.Added[i], commit.CommitTime())
+	}
+	if len(res.Added) > 0 {
+		s.observe(ctx, activityAdd, len(res.Added))
+		s.recordActivity(ctx, ActionAdded, res.Added...)
+		s.recordAudit(ctx, itemChanges(ActionAdded, res.Added)...)
+	}
+	return res, nil
+}
@@ -0,0 +1,70 @@
+package shoppinglist
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseInboundText(t *testing.T) {
+	tests := []struct {
+		text string
+		want []InboundItem
+	}{
+		{text: "add milk and bananas", want: []InboundItem{{Name: "milk"}, {Name: "bananas"}}},
+		{
+			text: "Please buy 2 avocados, 500 g of flour & 3x eggs to the shopping list.",
+			want: []InboundItem{{Name: "avocados", Quantity: "2"}, {Name: "flour", Quantity: "500 g"}, {Name: "eggs", Quantity: "3"}},
+		},
+		{text: "2 bottles of wine", want: []InboundItem{{Name: "bottles of wine", Quantity: "2"}}},
+		{
+			text: "- coffee\n- some oat milk\n\n> add cake\n--\nSent from my phone, add nothing",
+			want: []InboundItem{{Name: "coffee"}, {Name: "oat milk"}},
+		},
+	}
+	for _, tt := range tests {
+		got := parseInboundText(tt.text, HouseholdProfile{})
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseInboundText(%q) = %+v, want %+v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestReadInbound(t *testing.T) {
+	form := url.Values{"Body": {"get butter"}, "list": {"cabin"}}
+	tests := []struct {
+		name, contentType, body string
+		want                    []InboundItem
+		list                    string
+	}{
+		{
+			name:        "json",
+			contentType: "application/json",
+			body:        `{"items":[{"name":" rice ","quantity":"1 kg"},{"name":""}],"text":"add salt"}`,
+			want:        []InboundItem{{Name: "rice", Quantity: "1 kg"}, {Name: "salt"}},
+		},
+		{
+			name:        "sms form",
+			contentType: "application/x-www-form-urlencoded",
+			body:        form.Encode(),
+			want:        []InboundItem{{Name: "butter"}},
+			list:        "cabin",
+		},
+		{name: "plain text", contentType: "text/plain", body: "tea", want: []InboundItem{{Name: "tea"}}},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodPost, "/inbound", strings.NewReader(tt.body))
+		r.Header.Set("Content-Type", tt.contentType)
+		got, list, err := ReadInbound(r, HouseholdProfile{})
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) || list != tt.list {
+			t.Errorf("%s: got %+v in %q, want %+v in %q", tt.name, got, list, tt.want, tt.list)
+		}
+	}
+}
@@ -0,0 +1,383 @@
+package shoppinglist
+
+import (
+	"context"
+	"log"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	pb "cloud.google.com/go/firestore/apiv1/firestorepb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// -----------------------------------------------------------------------------
+// Item cache
+// -----------------------------------------------------------------------------
+
+// cacheWait is how long a read waits for a list's cache to fill, or to catch
+// up with this instance's writes, before reading Firestore instead.
+const cacheWait = 2 * time.Second
+
+// WithItemCache serves whole-list reads, such as list_items and the list
+// returned after a change, from an in-memory copy of each list kept current
+// by Firestore snapshot listeners, once StartItemCache is called. A list is
+// listened to from its first read, and a read right after this instance
+// wrote to it waits for the copy to include the write.
+func WithItemCache() ServiceOption {
+	return func(s *Service) { s.caching = &itemCaching{commits: map[string]time.Time{}} }
+}
+
+// itemCaching is shared by a service and its list-scoped services: the
+// context their listeners run under, when this instance last wrote to each
+// collection, and how well the caches are serving reads.
+type itemCaching struct {
+	mu        sync.Mutex
+	ctx       context.Context
+	commits   map[string]time.Time
+	hits      int64
+	misses    int64
+	listeners map[string]*CacheListener
+}
+
+// CacheStats reports how the item cache has served reads since the process
+// started, so operators can tell it is healthy rather than quietly falling
+// back to Firestore or serving an old copy.
+type CacheStats struct {
+	// Hits are reads served from memory; Misses are reads of a cached list
+	// that went to Firestore because its copy was missing or behind.
+	Hits     int64   `json:"hits"`
+	Misses   int64   `json:"misses"`
+	HitRatio float64 `json:"hit_ratio"`
+	// Reconnects counts listener failures, each followed by listening again.
+	Reconnects int64           `json:"reconnects"`
+	Listeners  []CacheListener `json:"listeners"`
+}
+
+// CacheListener is the state of one snapshot listener feeding a cache.
+type CacheListener struct {
+	Collection string `json:"collection"`
+	// Live is set while the listener holds a snapshot, and cleared from a
+	// failure until it delivers one again.
+	Live         bool       `json:"live"`
+	Reconnects   int64      `json:"reconnects"`
+	LastSnapshot *time.Time `json:"last_snapshot,omitempty"`
+	// LagMS is how long after its read time the last snapshot arrived.
+	LagMS int64 `json:"lag_ms"`
+}
+
+// served counts a read of a cached list, from memory or not.
+func (c *itemCaching) served(hit bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if hit {
+		c.hits++
+	} else {
+		c.misses++
+	}
+}
+
+// listener returns the state of the listener on collection. c.mu must be
+// held.
+func (c *itemCaching) listener(collection string) *CacheListener {
+	if c.listeners == nil {
+		c.listeners = map[string]*CacheListener{}
+	}
+	l, ok := c.listeners[collection]
+	if !ok {
+		l = &CacheListener{Collection: collection}
+		c.listeners[collection] = l
+	}
+	return l
+}
+
+// delivered notes a snapshot of collection read at readTime arriving at
+// now.
+func (c *itemCaching) delivered(collection string, readTime, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	l := c.listener(collection)
+	l.Live, l.LastSnapshot, l.LagMS = true, &readTime, max(now.Sub(readTime), 0).Milliseconds()
+}
+
+// lost notes a failure of the listener on collection.
+func (c *itemCaching) lost(collection string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	l := c.listener(collection)
+	l.Live = false
+	l.Reconnects++
+}
+
+// stats reports the cache's hits and misses and the state of its listeners,
+// by collection.
+func (c *itemCaching) stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	st := CacheStats{Hits: c.hits, Misses: c.misses, Listeners: []CacheListener{}}
+	if total := c.hits + c.misses; total > 0 {
+		st.HitRatio = float64(c.hits) / float64(total)
+	}
+	for _, l := range c.listeners {
+		st.Reconnects += l.Reconnects
+		st.Listeners = append(st.Listeners, *l)
+	}
+	slices.SortFunc(st.Listeners, func(a, b CacheListener) int { return strings.Compare(a.Collection, b.Collection) })
+	return st
+}
+
+// CacheStats reports how the item cache has served reads, or nil without
+// WithItemCache.
+func (s *Service) CacheStats() *CacheStats {
+	r := s.Root()
+	if r.caching == nil {
+		return nil
+	}
+	st := r.caching.stats()
+	return &st
+}
+
+func (c *itemCaching) listenContext() context.Context {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ctx
+}
+
+// committed notes a write to collection that landed at at.
+func (c *itemCaching) committed(collection string, at time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if at.After(c.commits[collection]) {
+		c.commits[collection] = at
+	}
+}
+
+// lastCommit is when this instance last wrote to collection.
+func (c *itemCaching) lastCommit(collection string) time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.commits[collection]
+}
+
+// unaryInterceptor notes when each successful write landed, so caches can
+// tell whether they already hold it.
+func (c *itemCaching) unaryInterceptor(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	if err := invoker(ctx, method, req, reply, cc, opts...); err != nil {
+		return err
+	}
+	switch r := req.(type) {
+	case *pb.CommitRequest:
+		resp, ok := reply.(*pb.CommitResponse)
+		if !ok || resp.GetCommitTime() == nil {
+			return nil
+		}
+		for _, w := range r.GetWrites() {
+			c.committed(writeCollection(w), resp.GetCommitTime().AsTime())
+		}
+	case *pb.BatchWriteRequest:
+		resp, ok := reply.(*pb.BatchWriteResponse)
+		if !ok {
+			return nil
+		}
+		// Deletes come back without an update time, so they are noted at
+		// the latest one the batch reports, a time the server has reached;
+		// a batch of deletes alone is not noted.
+		var latest time.Time
+		for _, res := range resp.GetWriteResults() {
+			if res.GetUpdateTime() != nil && res.GetUpdateTime().AsTime().After(latest) {
+				latest = res.GetUpdateTime().AsTime()
+			}
+		}
+		for i, w := range r.GetWrites() {
+			if i < len(resp.GetStatus()) && codes.Code(resp.GetStatus()[i].GetCode()) != codes.OK {
+				continue
+			}
+			at := latest
+			if i < len(resp.GetWriteResults()) && resp.GetWriteResults()[i].GetUpdateTime() != nil {
+				at = resp.GetWriteResults()[i].GetUpdateTime().AsTime()
+			}
+			if !at.IsZero() {
+				c.committed(writeCollection(w), at)
+			}
+		}
+	}
+	return nil
+}
+
+// writeDocument is the full name of the document w writes to.
+func writeDocument(w *pb.Write) string {
+	name := w.GetUpdate().GetName()
+	if name == "" {
+		name = w.GetDelete()
+	}
+	if name == "" {
+		name = w.GetTransform().GetDocument()
+	}
+	return name
+}
+
+// writeCollection is the path of the collection w writes to.
+func writeCollection(w *pb.Write) string {
+	path := documentPath(writeDocument(w))
+	if i := strings.LastIndex(path, "/"); i >= 0 {
+		return path[:i]
+	}
+	return ""
+}
+
+// itemCache is the in-memory copy of one list's items, in list order, and
+// freeze. Each half is only served once its listener has delivered a
+// snapshot, and not again after the listener fails until it recovers.
+type itemCache struct {
+	start sync.Once
+
+	mu       sync.Mutex
+	changed  chan struct{}
+	items    []Item
+	itemsAt  time.Time
+	freeze   *ListFreeze
+	freezeAt time.Time
+}
+
+// newItemCache returns a cache for a list when caching is on, else nil.
+func newItemCache(caching *itemCaching) *itemCache {
+	if caching == nil {
+		return nil
+	}
+	return &itemCache{changed: make(chan struct{})}
+}
+
+// update applies fn under the lock and wakes reads waiting on the cache.
+func (c *itemCache) update(fn func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fn()
+	close(c.changed)
+	c.changed = make(chan struct{})
+}
+
+func (c *itemCache) setItems(items []Item, at time.Time) {
+	c.update(func() { c.items, c.itemsAt = items, at })
+}
+
+func (c *itemCache) setFreeze(freeze *ListFreeze, at time.Time) {
+	c.update(func() { c.freeze, c.freezeAt = freeze, at })
+}
+
+func (c *itemCache) lostItems() { c.update(func() { c.items, c.itemsAt = nil, time.Time{} }) }
+
+func (c *itemCache) lostFreeze() { c.update(func() { c.freeze, c.freezeAt = nil, time.Time{} }) }
+
+// view returns a copy of the cached list if it holds the items as of at least
+// itemsSince and the freeze as of freezeSince; otherwise it returns a channel
+// closed on the next change.
+func (c *itemCache) view(itemsSince, freezeSince time.Time) (ListView, <-chan struct{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.itemsAt.IsZero() || c.freezeAt.IsZero() || c.itemsAt.Before(itemsSince) || c.freezeAt.Before(freezeSince) {
+		return ListView{}, c.changed, false
+	}
+	return ListView{Items: append([]Item(nil), c.items...), Freeze: c.freeze, ReadTime: c.itemsAt}, nil, true
+}
+
+// at returns a copy of the cached items if they are the snapshot read at
+// readTime, which nothing has changed since.
+func (c *itemCache) at(readTime time.Time) ([]Item, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.itemsAt.IsZero() || !c.itemsAt.Equal(readTime) {
+		return nil, false
+	}
+	return append([]Item(nil), c.items...), true
+}
+
+// StartItemCache starts keeping cached lists current until ctx is done and
+// fills the main list's cache. It does nothing without WithItemCache.
+func (s *Service) StartItemCache(ctx context.Context) {
+	r := s.Root()
+	if r.caching == nil {
+		return
+	}
+	r.caching.mu.Lock()
+	r.caching.ctx = ctx
+	r.caching.mu.Unlock()
+	main, err := r.Main()
+	if err != nil {
+		log.Printf("warn: item cache: %v", err)
+		return
+	}
+	main.cache.start.Do(func() { go main.listenItems(ctx) })
+}
+
+// listenItems keeps the list's cache current until ctx is done.
+func (s *Service) listenItems(ctx context.Context) {
+	meta := s.metaCollection()
+	go listen(ctx, meta.Where(firestore.DocumentID, "==", meta.Doc(freezeDocID)), "freeze cache", func(docs []*firestore.DocumentSnapshot, at time.Time) {
+		s.caching.delivered(meta.ID, at, s.Now())
+		var freeze *ListFreeze
+		if len(docs) > 0 {
+			var err error
+			if freeze, err = decodeFreeze(docs[0], nil); err != nil {
+				log.Printf("warn: freeze cache: %v", err)
+			}
+		}
+		s.cache.setFreeze(freeze, at)
+	}, func() {
+		s.caching.lost(meta.ID)
+		s.cache.lostFreeze()
+	})
+	listen(ctx, s.client.Collection(s.collection).Query, "item cache", func(docs []*firestore.DocumentSnapshot, at time.Time) {
+		s.caching.delivered(s.collection, at, s.Now())
+		items := decodeItems(docs)
+		s.upgradeQuantities(docs, items)
+		sortItems(items)
+		s.cache.setItems(items, at)
+	}, func() {
+		s.caching.lost(s.collection)
+		s.cache.lostItems()
+	})
+}
+
+// cachedView returns the list from its cache, starting to listen to it on
+// the first call, once the cache holds every write this instance made to it.
+// It reports false when the list is not cached or the cache does not catch
+// up within cacheWait, so the caller reads Firestore.
+func (s *Service) cachedView(ctx context.Context) (ListView, bool) {
+	if s.cache == nil {
+		return ListView{}, false
+	}
+	listenCtx := s.caching.listenContext()
+	if listenCtx == nil {
+		return ListView{}, false
+	}
+	s.cache.start.Do(func() { go s.listenItems(listenCtx) })
+
+	itemsSince, freezeSince := s.caching.lastCommit(s.collection), s.caching.lastCommit(s.metaCollection().ID)
+	timeout := time.NewTimer(cacheWait)
+	defer timeout.Stop()
+	for {
+		view, changed, ok := s.cache.view(itemsSince, freezeSince)
+		if ok {
+			if !view.Freeze.Active(s.Now()) {
+				view.Freeze = nil
+			}
+			s.caching.served(true)
+			return view, true
+		}
+		select {
+		case <-changed:
+		case <-timeout.C:
+			s.caching.served(false)
+			return ListView{}, false
+		case <-ctx.Done():
+			s.caching.served(false)
+			return ListView{}, false
+		}
+	}
+}
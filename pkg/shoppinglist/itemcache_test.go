@@ -0,0 +1,149 @@
+package shoppinglist
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pb "cloud.google.com/go/firestore/apiv1/firestorepb"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestItemCacheView(t *testing.T) {
+	c := newItemCache(&itemCaching{})
+	t0 := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	_, changed, ok := c.view(time.Time{}, time.Time{})
+	if ok {
+		t.Fatal("expected an empty cache not to be served")
+	}
+	c.setItems([]Item{{ID: "a", Name: "Milk"}}, t0)
+	select {
+	case <-changed:
+	default:
+		t.Fatal("expected a change to wake waiting reads")
+	}
+	if _, _, ok := c.view(time.Time{}, time.Time{}); ok {
+		t.Fatal("expected the cache to wait for the freeze too")
+	}
+	c.setFreeze(nil, t0)
+
+	view, _, ok := c.view(t0, t0)
+	if !ok || len(view.Items) != 1 || !view.ReadTime.Equal(t0) {
+		t.Fatalf("expected the cached list, got %+v, %v", view, ok)
+	}
+	view.Items[0].Name = "Bread"
+	if items, ok := c.at(t0); !ok || items[0].Name != "Milk" {
+		t.Fatalf("expected reads to get copies, got %+v, %v", items, ok)
+	}
+	if _, _, ok := c.view(t0.Add(time.Millisecond), t0); ok {
+		t.Fatal("expected a cache behind a write not to be served")
+	}
+	if _, ok := c.at(t0.Add(-time.Second)); ok {
+		t.Fatal("expected reads at another time not to be served")
+	}
+
+	c.lostItems()
+	if _, _, ok := c.view(time.Time{}, time.Time{}); ok {
+		t.Fatal("expected a lost listener's list not to be served")
+	}
+	if _, ok := (*itemCache)(nil).at(t0); ok {
+		t.Fatal("expected no cache to hold nothing")
+	}
+	if newItemCache(nil) != nil {
+		t.Fatal("expected no cache without caching")
+	}
+}
+
+func TestItemCachingCommits(t *testing.T) {
+	c := &itemCaching{commits: map[string]time.Time{}}
+	at := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	doc := func(path string) string { return "projects/p/databases/(default)/documents/" + path }
+	req := &pb.CommitRequest{Writes: []*pb.Write{
+		{Operation: &pb.Write_Update{Update: &pb.Document{Name: doc("shopping/a")}}},
+		{Operation: &pb.Write_Delete{Delete: doc("shopping_meta/freeze")}},
+	}}
+	invoke := func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		reply.(*pb.CommitResponse).CommitTime = timestamppb.New(at)
+		return nil
+	}
+	if err := c.unaryInterceptor(context.Background(), "/google.firestore.v1.Firestore/Commit", req, &pb.CommitResponse{}, nil, invoke); err != nil {
+		t.Fatal(err)
+	}
+	if !c.lastCommit("shopping").Equal(at) || !c.lastCommit("shopping_meta").Equal(at) {
+		t.Fatalf("expected both collections to be noted, got %v", c.commits)
+	}
+	c.committed("shopping", at.Add(-time.Second))
+	if !c.lastCommit("shopping").Equal(at) {
+		t.Fatal("expected an earlier write not to move the last commit back")
+	}
+
+	// A batch's deletes are noted at the latest update time it reports, and
+	// not at all without one.
+	later := at.Add(time.Second)
+	batch := &pb.BatchWriteRequest{Writes: []*pb.Write{
+		{Operation: &pb.Write_Update{Update: &pb.Document{Name: doc("shopping/b")}}},
+		{Operation: &pb.Write_Delete{Delete: doc("shopping_activity/x")}},
+	}}
+	invokeBatch := func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		reply.(*pb.BatchWriteResponse).WriteResults = []*pb.WriteResult{{UpdateTime: timestamppb.New(later)}, {}}
+		return nil
+	}
+	if err := c.unaryInterceptor(context.Background(), "/google.firestore.v1.Firestore/BatchWrite", batch, &pb.BatchWriteResponse{}, nil, invokeBatch); err != nil {
+		t.Fatal(err)
+	}
+	if !c.lastCommit("shopping_activity").Equal(later) {
+		t.Fatalf("expected the delete noted at the batch's update time, got %v", c.lastCommit("shopping_activity"))
+	}
+	deletes := &pb.BatchWriteRequest{Writes: []*pb.Write{{Operation: &pb.Write_Delete{Delete: doc("shopping_trips/y")}}}}
+	noTimes := func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		reply.(*pb.BatchWriteResponse).WriteResults = []*pb.WriteResult{{}}
+		return nil
+	}
+	if err := c.unaryInterceptor(context.Background(), "/google.firestore.v1.Firestore/BatchWrite", deletes, &pb.BatchWriteResponse{}, nil, noTimes); err != nil {
+		t.Fatal(err)
+	}
+	if !c.lastCommit("shopping_trips").IsZero() {
+		t.Fatalf("expected a delete without a server time not to be noted, got %v", c.lastCommit("shopping_trips"))
+	}
+	if got := writeCollection(&pb.Write{Operation: &pb.Write_Update{Update: &pb.Document{Name: doc("lists/x/items/a")}}}); got != "lists/x/items" {
+		t.Fatalf("expected a subcollection path, got %q", got)
+	}
+}
+
+func TestItemCachingStats(t *testing.T) {
+	c := &itemCaching{commits: map[string]time.Time{}}
+	if st := c.stats(); st.HitRatio != 0 || len(st.Listeners) != 0 {
+		t.Fatalf("expected empty stats, got %+v", st)
+	}
+
+	read := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	c.served(true)
+	c.served(true)
+	c.served(true)
+	c.served(false)
+	c.delivered("shopping", read, read.Add(250*time.Millisecond))
+	c.delivered("shopping_meta", read, read)
+	c.lost("shopping")
+	c.lost("shopping")
+
+	st := c.stats()
+	if st.Hits != 3 || st.Misses != 1 || st.HitRatio != 0.75 || st.Reconnects != 2 {
+		t.Fatalf("unexpected totals %+v", st)
+	}
+	if len(st.Listeners) != 2 || st.Listeners[0].Collection != "shopping" || st.Listeners[1].Collection != "shopping_meta" {
+		t.Fatalf("expected listeners by collection, got %+v", st.Listeners)
+	}
+	items := st.Listeners[0]
+	if items.Live || items.Reconnects != 2 || items.LagMS != 250 || !items.LastSnapshot.Equal(read) {
+		t.Fatalf("unexpected item listener %+v", items)
+	}
+	if !st.Listeners[1].Live {
+		t.Fatal("expected the freeze listener to be live")
+	}
+
+	if (&Service{}).CacheStats() != nil {
+		t.Fatal("expected no stats without caching")
+	}
+}
@@ -0,0 +1,171 @@
+package shoppinglist
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// -----------------------------------------------------------------------------
+// Background job leases
+// -----------------------------------------------------------------------------
+
+// LeaseTTL is how long the background jobs lease lasts without renewal. The
+// holder renews it at a third of that, so another instance takes over within
+// LeaseTTL of the holder stopping; it also bounds the clock skew tolerated
+// between instances.
+const LeaseTTL = 30 * time.Second
+
+// leaseDocID names the lease for all background jobs. One lease for all of
+// them keeps each job's runs on a single instance.
+const leaseDocID = "background-jobs"
+
+// Lease records which instance runs the background jobs.
+type Lease struct {
+	Holder     string    `json:"holder" firestore:"holder"`
+	AcquiredAt time.Time `json:"acquired_at" firestore:"acquired_at"`
+	ExpiresAt  time.Time `json:"expires_at" firestore:"expires_at"`
+}
+
+// WithInstanceID names this server instance and runs background jobs only
+// while it holds the lease, so horizontally scaled servers run each job
+// once. Without it every instance runs them.
+func WithInstanceID(id string) ServiceOption {
+	return func(s *Service) {
+		s.instanceID = id
+		s.leading = &atomic.Bool{}
+	}
+}
+
+// Leading reports whether this instance should run background jobs: always
+// without an instance ID, and otherwise while it holds the lease.
+func (s *Service) Leading() bool {
+	r := s.Root()
+	return r.instanceID == "" || r.leading.Load()
+}
+
+// leasesCollection holds leases shared by every list, beside the registry.
+func (s *Service) leasesCollection() *firestore.CollectionRef {
+	r := s.Root()
+	return r.client.Collection(r.collection + "_leases")
+}
+
+// claimLease returns the lease id holds after trying to claim current at now,
+// and whether it changed hands: a lease is free once expired, and renewed when
+// id already holds it.
+func claimLease(current *Lease, id string, now time.Time) (Lease, bool) {
+	if current != nil && current.Holder != id && now.Before(current.ExpiresAt) {
+		return *current, false
+	}
+	next := Lease{Holder: id, AcquiredAt: now, ExpiresAt: now.Add(LeaseTTL)}
+	if current != nil && current.Holder == id {
+		next.AcquiredAt = current.AcquiredAt
+	}
+	return next, true
+}
+
+// RenewLease acquires or renews the lease for this instance and returns the
+// current lease, whoever holds it.
+func (s *Service) RenewLease(ctx context.Context) (Lease, error) {
+	r := s.Root()
+	if r.instanceID == "" {
+		return Lease{}, fmt.Errorf("renew lease: no instance ID")
+	}
+	ref := r.leasesCollection().Doc(leaseDocID)
+	var lease Lease
+	err := r.runTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		var current *Lease
+		doc, err := tx.Get(ref)
+		switch {
+		case status.Code(err) == codes.NotFound:
+		case err != nil:
+			return err
+		default:
+			current = &Lease{}
+			if err := doc.DataTo(current); err != nil {
+				return err
+			}
+		}
+		var claimed bool
+		lease, claimed = claimLease(current, r.instanceID, r.Now())
+		if !claimed {
+			return nil
+		}
+		return tx.Set(ref, lease)
+	})
+	if err != nil {
+		// stop running jobs when the lease may have lapsed unseen
+		r.leading.Store(false)
+		return Lease{}, fmt.Errorf("renew lease: %w", err)
+	}
+	r.leading.Store(lease.Holder == r.instanceID)
+	return lease, nil
+}
+
+// releaseLease gives up the lease if this instance holds it, so another takes
+// over without waiting for it to expire.
+func (s *Service) releaseLease(ctx context.Context) error {
+	r := s.Root()
+	r.leading.Store(false)
+	ref := r.leasesCollection().Doc(leaseDocID)
+	return r.runTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(ref)
+		if status.Code(err) == codes.NotFound {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		var lease Lease
+		if err := doc.DataTo(&lease); err != nil {
+			return err
+		}
+		if lease.Holder != r.instanceID {
+			return nil
+		}
+		return tx.Delete(ref)
+	})
+}
+
+// RunLeaseRenewal keeps renewing the lease until ctx is done, then releases
+// it. Call RenewLease once first so jobs that run at startup know whether
+// they lead.
+func RunLeaseRenewal(ctx context.Context, service *Service) {
+	ticker := time.NewTicker(LeaseTTL / 3)
+	defer ticker.Stop()
+	leading := service.Leading()
+	for {
+		select {
+		case <-ctx.Done():
+			releaseCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+			if err := service.releaseLease(releaseCtx); err != nil {
+				log.Printf("warn: release lease: %v", err)
+			}
+			cancel()
+			return
+		case <-ticker.C:
+		}
+
+		renewCtx, cancel := context.WithTimeout(ctx, LeaseTTL/3)
+		lease, err := service.RenewLease(renewCtx)
+		cancel()
+		if err != nil {
+			log.Printf("warn: %v", err)
+		}
+		if now := service.Leading(); now != leading {
+			leading = now
+			switch {
+			case now:
+				log.Printf("instance %s now runs background jobs", service.instanceID)
+			case err == nil:
+				log.Printf("instance %s stopped running background jobs; %s holds the lease", service.instanceID, lease.Holder)
+			}
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package shoppinglist
+
+import (
+	"testing"
+	"time"
+)
+
+func TestClaimLease(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	lease, ok := claimLease(nil, "a", now)
+	if !ok || lease.Holder != "a" || !lease.ExpiresAt.Equal(now.Add(LeaseTTL)) {
+		t.Fatalf("free lease: got %+v, %v", lease, ok)
+	}
+
+	held := &Lease{Holder: "b", AcquiredAt: now.Add(-time.Minute), ExpiresAt: now.Add(time.Second)}
+	if lease, ok := claimLease(held, "a", now); ok || lease.Holder != "b" {
+		t.Errorf("live lease of another instance: got %+v, %v", lease, ok)
+	}
+	if lease, ok := claimLease(held, "a", now.Add(2*time.Second)); !ok || lease.Holder != "a" || !lease.AcquiredAt.Equal(now.Add(2*time.Second)) {
+		t.Errorf("expired lease: got %+v, %v", lease, ok)
+	}
+	if lease, ok := claimLease(held, "b", now); !ok || !lease.AcquiredAt.Equal(held.AcquiredAt) || !lease.ExpiresAt.Equal(now.Add(LeaseTTL)) {
+		t.Errorf("renewal: got %+v, %v", lease, ok)
+	}
+}
+
+func TestLeading(t *testing.T) {
+	if !(&Service{}).Leading() {
+		t.Error("expected a service without an instance ID to lead")
+	}
+	s := &Service{}
+	WithInstanceID("a")(s)
+	if s.Leading() {
+		t.Error("expected an instance to wait for the lease")
+	}
+	s.leading.Store(true)
+	scoped, err := s.ForList(ListInfo{ID: "x", Collection: "shopping_x"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !scoped.Leading() {
+		t.Error("expected list services to follow the default-list service")
+	}
+}
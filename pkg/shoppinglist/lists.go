@@ -0,0 +1,333 @@
+package shoppinglist
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"cloud.google.com/go/firestore"
+)
+
+// -----------------------------------------------------------------------------
+// Lists
+// -----------------------------------------------------------------------------
+
+// defaultListID is the stable ID of the list stored in the base collection.
+const defaultListID = "default"
+
+// ListInfo describes a shopping list. The ID never changes; the slug follows
+// the name, and earlier slugs are kept as aliases so old references resolve.
+type ListInfo struct {
+	ID         string    `json:"id" firestore:"id"`
+	Name       string    `json:"name" firestore:"name"`
+	Slug       string    `json:"slug" firestore:"slug"`
+	Aliases    []string  `json:"aliases,omitempty" firestore:"aliases,omitempty"`
+	Collection string    `json:"-" firestore:"collection"`
+	CreatedAt  time.Time `json:"created_at" firestore:"created_at"`
+
+	// Database is the Firestore database the list's collections are in,
+	// empty for the server's own.
+	Database string `json:"-" firestore:"database,omitempty"`
+
+	// MovingTo and MovingToDatabase are where the list is being re-pointed
+	// to, while writes to it are paused; Retired are the collections it was
+	// stored in before, kept read-only, prefixed with their database when it
+	// is not the server's.
+	MovingTo         string   `json:"-" firestore:"moving_to,omitempty"`
+	MovingToDatabase string   `json:"-" firestore:"moving_to_database,omitempty"`
+	Retired          []string `json:"-" firestore:"retired,omitempty"`
+}
+
+// ErrListNotFound is returned when a list reference does not resolve.
+var ErrListNotFound = errors.New("list not found")
+
+// slugify lower-cases name and joins its letters and digits with hyphens.
+func slugify(name string) string {
+	var b strings.Builder
+	pendingHyphen := false
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if pendingHyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			pendingHyphen = false
+			continue
+		}
+		pendingHyphen = true
+	}
+	return b.String()
+}
+
+// matches reports whether ref names this list by ID, slug, or alias.
+func (l ListInfo) matches(ref string) bool {
+	return l.ID == ref || l.Slug == ref || slices.Contains(l.Aliases, ref)
+}
+
+// listsCollection is the list registry; the default list appears there only
+// once it has been renamed.
+func (s *Service) listsCollection() *firestore.CollectionRef {
+	r := s.Root()
+	return r.client.Collection(r.collection + "_lists")
+}
+
+// Root returns the service for the default list.
+func (s *Service) Root() *Service {
+	if s.parent != nil {
+		return s.parent
+	}
+	return s
+}
+
+// DefaultList describes the list stored in the base collection before it has
+// been renamed.
+func (s *Service) DefaultList() ListInfo {
+	r := s.Root()
+	return ListInfo{ID: defaultListID, Name: r.collection, Slug: slugify(r.collection), Collection: r.collection}
+}
+
+// ForCollection returns the service for the extra collection name, e.g.
+// "chores", kept beside the lists in <collection>_list_<name>. It is not in
+// the list registry, so list_lists and the list argument do not find it.
+func (s *Service) ForCollection(name string) *Service {
+	r := s.Root()
+	return r.scope(r.client, r.database, r.collection+"_list_"+name)
+}
+
+// Lists returns all lists, the default list first.
+func (s *Service) Lists(ctx context.Context) ([]ListInfo, error) {
+	docs, err := s.listsCollection().Documents(ctx).GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("retrieve lists: %w", err)
+	}
+	return decodeLists(s.DefaultList(), docs), nil
+}
+
+func decodeLists(def ListInfo, docs []*firestore.DocumentSnapshot) []ListInfo {
+	lists := []ListInfo{def}
+	for _, d := range docs {
+		var l ListInfo
+		if err := d.DataTo(&l); err != nil {
+			continue
+		}
+		if l.ID == defaultListID {
+			lists[0] = l
+			continue
+		}
+		lists = append(lists, l)
+	}
+	return lists
+}
+
+// ResolveList finds the list named by ID, slug, or former slug. An empty
+// reference resolves to the default list.
+func (s *Service) ResolveList(ctx context.Context, ref string) (ListInfo, error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		ref = defaultListID
+	}
+
+	lists, err := s.Lists(ctx)
+	if err != nil {
+		return ListInfo{}, err
+	}
+	if l, ok := findList(lists, ref); ok {
+		return l, nil
+	}
+	return ListInfo{}, fmt.Errorf("%w: %q", ErrListNotFound, ref)
+}
+
+// findList returns the list matching ref, preferring ID and current slug
+// matches over aliases.
+func findList(lists []ListInfo, ref string) (ListInfo, bool) {
+	for _, l := range lists {
+		if l.ID == ref || l.Slug == ref {
+			return l, true
+		}
+	}
+	for _, l := range lists {
+		if l.matches(ref) {
+			return l, true
+		}
+	}
+	return ListInfo{}, false
+}
+
+// ForList returns a service operating on the given list's items, opening a
+// client for its database the first time a list stored outside the server's
+// own database is used.
+func (s *Service) ForList(list ListInfo) (*Service, error) {
+	r := s.Root()
+	database := cmp.Or(list.Database, r.database)
+	if database == r.database && (list.Collection == "" || list.Collection == r.collection) {
+		return r, nil
+	}
+	client, err := r.databaseClient(database)
+	if err != nil {
+		return nil, err
+	}
+	return r.scope(client, database, cmp.Or(list.Collection, r.collection)), nil
+}
+
+// scope returns the service for collection in database, derived from the
+// main list's service and kept for later calls.
+func (s *Service) scope(client *firestore.Client, database, collection string) *Service {
+	r := s.Root()
+	key := database + "/" + collection
+	r.scopedMu.Lock()
+	defer r.scopedMu.Unlock()
+	if scoped, ok := r.scoped[key]; ok {
+		return scoped
+	}
+	scoped := &Service{
+		client:      client,
+		database:    database,
+		collection:  collection,
+		anomalies:   r.anomalies,
+		notifier:    r.notifier,
+		normalizer:  r.normalizer,
+		profile:     r.profile,
+		retention:   r.retention,
+		clock:       r.clock,
+		ids:         r.ids,
+		derivedIDs:  r.derivedIDs,
+		shadow:      r.shadow,
+		rules:       r.rules,
+		filter:      r.filter,
+		usage:       r.usage,
+		flags:       r.flags,
+		snapshot:    &listSnapshot{},
+		staleMaxAge: r.staleMaxAge,
+		caching:     r.caching,
+		cache:       newItemCache(r.caching),
+		retryPolicy: r.retryPolicy,
+		parent:      r,
+	}
+	if r.scoped == nil {
+		r.scoped = map[string]*Service{}
+	}
+	r.scoped[key] = scoped
+	return scoped
+}
+
+// databaseClient returns the client for database, opening one with the
+// server's client options the first time it is asked for, so usage and
+// explain mode cover it too.
+func (s *Service) databaseClient(database string) (*firestore.Client, error) {
+	r := s.Root()
+	if database == "" || database == r.database {
+		return r.client, nil
+	}
+	r.scopedMu.Lock()
+	defer r.scopedMu.Unlock()
+	if client, ok := r.databases[database]; ok {
+		return client, nil
+	}
+	client, err := firestore.NewClientWithDatabase(context.Background(), r.projectID, database, r.clientOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("create firestore client for database %s: %w", database, err)
+	}
+	if r.databases == nil {
+		r.databases = map[string]*firestore.Client{}
+	}
+	r.databases[database] = client
+	return client, nil
+}
+
+// CreateList registers a new list with a slug derived from name.
+func (s *Service) CreateList(ctx context.Context, name string) (ListInfo, error) {
+	slug := slugify(name)
+	if slug == "" {
+		return ListInfo{}, errors.New("list name must contain letters or digits")
+	}
+
+	id := s.NewID()
+	list := ListInfo{
+		ID:         id,
+		Name:       name,
+		Slug:       slug,
+		Collection: s.Root().collection + "_list_" + id,
+		CreatedAt:  s.Now(),
+	}
+
+	err := s.Root().runTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		docs, err := tx.Documents(s.listsCollection()).GetAll()
+		if err != nil {
+			return err
+		}
+		if taken, ok := findList(decodeLists(s.DefaultList(), docs), slug); ok {
+			return fmt.Errorf("slug %q is already used by list %q", slug, taken.Name)
+		}
+		return tx.Create(s.listsCollection().Doc(id), list)
+	})
+	if err != nil {
+		return ListInfo{}, fmt.Errorf("create list: %w", err)
+	}
+	return list, nil
+}
+
+// RenameList changes a list's name and slug, keeping the previous slug as an alias.
+func (s *Service) RenameList(ctx context.Context, ref, name string) (ListInfo, error) {
+	slug := slugify(name)
+	if slug == "" {
+		return ListInfo{}, errors.New("list name must contain letters or digits")
+	}
+
+	var renamed ListInfo
+	err := s.Root().runTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		docs, err := tx.Documents(s.listsCollection()).GetAll()
+		if err != nil {
+			return err
+		}
+		lists := decodeLists(s.DefaultList(), docs)
+
+		list, ok := findList(lists, ref)
+		if !ok {
+			return fmt.Errorf("%w: %q", ErrListNotFound, ref)
+		}
+		for _, other := range lists {
+			if other.ID != list.ID && other.matches(slug) {
+				return fmt.Errorf("slug %q is already used by list %q", slug, other.Name)
+			}
+		}
+
+		renamed = applyRename(list, name, slug, s.Now())
+		return tx.Set(s.listsCollection().Doc(renamed.ID), renamed)
+	})
+	if err != nil {
+		return ListInfo{}, fmt.Errorf("rename list: %w", err)
+	}
+	return renamed, nil
+}
+
+// applyRename returns list with the new name and slug, moving the old slug
+// into the aliases.
+func applyRename(list ListInfo, name, slug string, now time.Time) ListInfo {
+	if list.Slug != slug && !slices.Contains(list.Aliases, list.Slug) {
+		list.Aliases = append(list.Aliases, list.Slug)
+	}
+	list.Aliases = slices.DeleteFunc(list.Aliases, func(a string) bool { return a == slug })
+	list.Name = name
+	list.Slug = slug
+	if list.CreatedAt.IsZero() {
+		list.CreatedAt = now
+	}
+	return list
+}
+
+// ListsResponse wraps the set of lists.
+type ListsResponse struct {
+	Lists []ListInfo `json:"lists"`
+	ResponseWarnings
+}
+
+// ListResponse wraps a single list.
+type ListResponse struct {
+	List ListInfo `json:"list"`
+	ResponseWarnings
+}
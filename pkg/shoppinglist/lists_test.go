@@ -0,0 +1,66 @@
+package shoppinglist
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestSlugify(t *testing.T) {
+	cases := map[string]string{
+		"Weekly Groceries":     "weekly-groceries",
+		"  Party -- Supplies!": "party-supplies",
+		"Café & Bäckerei":      "café-bäckerei",
+		"!!!":                  "",
+	}
+	for in, want := range cases {
+		if got := slugify(in); got != want {
+			t.Fatalf("slugify(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestApplyRenameKeepsOldSlugAsAlias(t *testing.T) {
+	list := ListInfo{ID: "abc", Name: "Groceries", Slug: "groceries"}
+	now := time.Date(2025, 8, 12, 9, 0, 0, 0, time.UTC)
+
+	renamed := applyRename(list, "Weekly Groceries", "weekly-groceries", now)
+	if renamed.ID != "abc" || renamed.Slug != "weekly-groceries" || !renamed.CreatedAt.Equal(now) {
+		t.Fatalf("unexpected rename result %+v", renamed)
+	}
+	if !slices.Equal(renamed.Aliases, []string{"groceries"}) {
+		t.Fatalf("unexpected aliases %v", renamed.Aliases)
+	}
+
+	back := applyRename(renamed, "Groceries", "groceries", now.Add(time.Hour))
+	if !slices.Equal(back.Aliases, []string{"weekly-groceries"}) {
+		t.Fatalf("expected current slug to be dropped from aliases, got %v", back.Aliases)
+	}
+}
+
+func TestFindListPrefersCurrentSlugOverAlias(t *testing.T) {
+	lists := []ListInfo{
+		{ID: "default", Slug: "shopping", Aliases: []string{"party"}},
+		{ID: "other", Slug: "party"},
+	}
+
+	got, ok := findList(lists, "party")
+	if !ok || got.ID != "other" {
+		t.Fatalf("expected current slug match, got %+v", got)
+	}
+	got, ok = findList(lists, "default")
+	if !ok || got.ID != "default" {
+		t.Fatalf("expected ID match, got %+v", got)
+	}
+	if _, ok := findList(lists, "missing"); ok {
+		t.Fatal("expected no match")
+	}
+}
+
+func TestClosingScopedListLeavesClientOpen(t *testing.T) {
+	// Without a client, closing the main list's service would panic.
+	scoped := &Service{collection: "shopping_list_abc", parent: &Service{collection: "shopping"}}
+	if err := scoped.Close(); err != nil {
+		t.Fatalf("Close returned error: %v", err)
+	}
+}
@@ -0,0 +1,422 @@
+package shoppinglist
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// -----------------------------------------------------------------------------
+// Revisions and offline merges
+// -----------------------------------------------------------------------------
+
+// Merge outcomes reported per edit.
+const (
+	MergeApplied   = "applied"
+	MergeCreated   = "created"
+	MergeDeleted   = "deleted"
+	MergeUnchanged = "unchanged"
+	MergeConflict  = "conflict"
+	MergeFailed    = "failed"
+)
+
+// mergeFields are the item fields an offline client may edit.
+var mergeFields = map[string]bool{
+	"name":         false,
+	"quantity":     true, // nullable
+	"package_size": true, // nullable
+	"category":     true, // nullable
+	"staple":       false,
+	"purchased":    false,
+}
+
+// withRevision bumps the item's revision and stamps updated_at with at, or
+// Firestore's commit time when at is zero, alongside updates. Every item
+// write goes through it so clients can tell which items changed since they
+// synced.
+func withRevision(updates []firestore.Update, at time.Time) []firestore.Update {
+	return append(updates,
+		firestore.Update{Path: "updated_at", Value: stampValue(at)},
+		firestore.Update{Path: "revision", Value: firestore.Increment(1)},
+	)
+}
+
+// ItemEdit is a change a client made offline. Base holds the client's copy of
+// each changed field as of BaseRevision, so edits can be merged three ways
+// against whatever happened on the server meanwhile. An edit without an ID
+// creates an item.
+type ItemEdit struct {
+	ID           string         `json:"id,omitempty"`
+	ClientRef    string         `json:"client_ref,omitempty"`
+	BaseRevision int64          `json:"base_revision,omitempty"`
+	Base         map[string]any `json:"base,omitempty"`
+	Changes      map[string]any `json:"changes,omitempty"`
+	Delete       bool           `json:"delete,omitempty"`
+}
+
+// FieldConflict is a field both the client and the server changed to
+// different values since the client's base revision.
+type FieldConflict struct {
+	Field  string `json:"field"`
+	Base   any    `json:"base"`
+	Local  any    `json:"local"`
+	Remote any    `json:"remote"`
+}
+
+// MergeResult reports what happened to one edit.
+type MergeResult struct {
+	ID        string          `json:"id"`
+	ClientRef string          `json:"client_ref,omitempty"`
+	Status    string          `json:"status"`
+	Revision  int64           `json:"revision,omitempty"`
+	Applied   []string        `json:"applied,omitempty"`
+	Conflicts []FieldConflict `json:"conflicts,omitempty"`
+	Error     string          `json:"error,omitempty"`
+	Item      *Item           `json:"item,omitempty"`
+}
+
+// MergeResponse wraps the results of a merge.
+type MergeResponse struct {
+	Results   []MergeResult `json:"results"`
+	Conflicts int           `json:"conflicts"`
+	ResponseWarnings
+}
+
+// itemField returns an item's value for a mergeable field, with unset
+// optional strings reported as nil.
+func itemField(it Item, field string) any {
+	switch field {
+	case "name":
+		return it.Name
+	case "quantity":
+		return optionalField(it.Quantity)
+	case "package_size":
+		return optionalField(it.PackageSize)
+	case "category":
+		return optionalField(it.Category)
+	case "staple":
+		return it.Staple
+	case "purchased":
+		return it.Purchased
+	}
+	return nil
+}
+
+func optionalField(p *string) any {
+	if p == nil || *p == "" {
+		return nil
+	}
+	return *p
+}
+
+// normalizeChanges checks each field and value in changes, mapping empty
+// optional strings to nil so they compare equal to unset fields.
+func normalizeChanges(changes map[string]any) (map[string]any, error) {
+	out := make(map[string]any, len(changes))
+	for field, v := range changes {
+		nullable, ok := mergeFields[field]
+		if !ok {
+			return nil, fmt.Errorf("field %q cannot be merged (expected name, quantity, package_size, category, staple, or purchased)", field)
+		}
+		switch field {
+		case "staple", "purchased":
+			b, ok := v.(bool)
+			if !ok {
+				return nil, fmt.Errorf("field %q must be a boolean", field)
+			}
+			out[field] = b
+		default:
+			if v == nil && nullable {
+				out[field] = nil
+				continue
+			}
+			s, ok := v.(string)
+			if !ok {
+				return nil, fmt.Errorf("field %q must be a string", field)
+			}
+			if field == "category" {
+				s = NormalizeCategory(s)
+			}
+			if s = strings.TrimSpace(s); s == "" {
+				if !nullable {
+					return nil, fmt.Errorf("field %q cannot be empty", field)
+				}
+				out[field] = nil
+				continue
+			}
+			out[field] = s
+		}
+	}
+	return out, nil
+}
+
+// mergeItem merges an edit into the server's copy of an item. A field is
+// applied when the server has not changed it since the client's base, and is
+// a conflict when both sides changed it to different values. Changes already
+// matching the server are skipped.
+func mergeItem(remote Item, edit ItemEdit) (applied map[string]any, conflicts []FieldConflict, err error) {
+	changes, err := normalizeChanges(edit.Changes)
+	if err != nil {
+		return nil, nil, err
+	}
+	base, err := normalizeChanges(edit.Base)
+	if err != nil {
+		return nil, nil, fmt.Errorf("base: %w", err)
+	}
+
+	fields := make([]string, 0, len(changes))
+	for f := range changes {
+		fields = append(fields, f)
+	}
+	sort.Strings(fields)
+
+	applied = map[string]any{}
+	for _, f := range fields {
+		local, current := changes[f], itemField(remote, f)
+		if local == current {
+			continue
+		}
+		if remote.Revision == edit.BaseRevision {
+			applied[f] = local
+			continue
+		}
+		if b, ok := base[f]; ok && b == current {
+			applied[f] = local
+			continue
+		}
+		conflicts = append(conflicts, FieldConflict{Field: f, Base: base[f], Local: local, Remote: current})
+	}
+	return applied, conflicts, nil
+}
+
+// mergeUpdates turns merged field values into Firestore updates, checking
+// items off at now and stamping the write at at.
+func mergeUpdates(fields map[string]any, now, at time.Time) []firestore.Update {
+	names := make([]string, 0, len(fields))
+	for f := range fields {
+		names = append(names, f)
+	}
+	sort.Strings(names)
+
+	var updates []firestore.Update
+	for _, f := range names {
+		v := fields[f]
+		if v == nil {
+			v = firestore.Delete
+		}
+		updates = append(updates, firestore.Update{Path: f, Value: v})
+		if f == "purchased" {
+			if fields[f] == true {
+				updates = append(updates, firestore.Update{Path: "purchased_at", Value: now})
+			} else {
+				updates = append(updates, firestore.Update{Path: "purchased_at", Value: firestore.Delete})
+			}
+		}
+	}
+	return withRevision(updates, at)
+}
+
+// MergeEdits applies a client's offline edits, each in its own transaction.
+// Fields with true conflicts are left as the server has them and reported
+// back for the client to resolve. The activity of the whole merge is recorded
+// together once the edits are in.
+func (s *Service) MergeEdits(ctx context.Context, edits []ItemEdit) ([]MergeResult, error) {
+	results := make([]MergeResult, 0, len(edits))
+	var done mergeActivity
+	defer s.recordMergeActivity(ctx, &done)
+	for _, edit := range edits {
+		var (
+			res MergeResult
+			err error
+		)
+		if edit.ID == "" {
+			res, err = s.mergeCreate(ctx, edit, &done)
+		} else {
+			res, err = s.mergeEdit(ctx, edit, &done)
+		}
+		if err != nil {
+			res = MergeResult{ID: edit.ID, Status: MergeFailed, Error: err.Error()}
+			if ctx.Err() != nil {
+				return nil, fmt.Errorf("merge edits: %w", err)
+			}
+		}
+		res.ClientRef = edit.ClientRef
+		results = append(results, res)
+	}
+	return results, nil
+}
+
+// mergeActivity collects what a merge's edits did, to be recorded together.
+type mergeActivity struct {
+	added, removed, updated []Item
+	changes                 []ItemChange
+}
+
+// recordMergeActivity records what a merge's edits did.
+func (s *Service) recordMergeActivity(ctx context.Context, done *mergeActivity) {
+	if len(done.added) > 0 {
+		s.observe(ctx, activityAdd, len(done.added))
+	}
+	if len(done.removed) > 0 {
+		s.observe(ctx, activityDelete, len(done.removed))
+	}
+	s.recordActivity(ctx, ActionAdded, done.added...)
+	s.recordActivity(ctx, ActionRemoved, done.removed...)
+	s.recordActivity(ctx, ActionUpdated, done.updated...)
+	s.recordAudit(ctx, done.changes...)
+}
+
+// mergeCreate creates an item the client added offline.
+func (s *Service) mergeCreate(ctx context.Context, edit ItemEdit, done *mergeActivity) (MergeResult, error) {
+	if edit.Delete {
+		return MergeResult{}, fmt.Errorf("an edit without an id cannot delete")
+	}
+	changes, err := normalizeChanges(edit.Changes)
+	if err != nil {
+		return MergeResult{}, err
+	}
+	name, _ := changes["name"].(string)
+	if name == "" {
+		return MergeResult{}, fmt.Errorf("new items need a name")
+	}
+	if err := s.checkNotFrozen(ctx); err != nil {
+		return MergeResult{}, err
+	}
+
+	now, at := s.Now(), s.writeTime()
+	it := Item{ID: s.newItemID(name), Name: name, CreatedAt: at, UpdatedAt: &at, Revision: 1}
+	if q, ok := changes["quantity"].(string); ok {
+		it.Quantity = &q
+		withAmount(&it, s.profile)
+	}
+	if p, ok := changes["package_size"].(string); ok {
+		it.PackageSize = &p
+	}
+	if c, ok := changes["category"].(string); ok {
+		it.Category = &c
+	}
+	it.Staple, _ = changes["staple"].(bool)
+	if it.Purchased, _ = changes["purchased"].(bool); it.Purchased {
+		it.PurchasedAt = &now
+	}
+	written, err := s.createItemDoc(ctx, s.client.Collection(s.collection).Doc(it.ID), it)
+	if err != nil {
+		return MergeResult{}, fmt.Errorf("create item: %w", err)
+	}
+	s.stamped(&it, written.UpdateTime)
+	done.added = append(done.added, it)
+	done.changes = append(done.changes, newItemChange(ActionAdded, nil, &it))
+	return MergeResult{ID: it.ID, Status: MergeCreated, Revision: it.Revision, Item: &it}, nil
+}
+
+// mergeEdit merges changes to, or the deletion of, an existing item.
+func (s *Service) mergeEdit(ctx context.Context, edit ItemEdit, done *mergeActivity) (MergeResult, error) {
+	col := s.client.Collection(s.collection)
+	ref := col.Doc(edit.ID)
+	var (
+		res     MergeResult
+		remote  Item
+		deleted bool
+	)
+	err := s.runTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		res, remote, deleted = MergeResult{ID: edit.ID}, Item{}, false
+		doc, err := untrashed(tx.Get(ref))
+		if status.Code(err) == codes.NotFound {
+			// Deleted on the server: a local delete agrees, a local edit conflicts.
+			if edit.Delete {
+				res.Status = MergeDeleted
+				return nil
+			}
+			res.Status = MergeConflict
+			res.Error = "item was deleted on the server"
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := doc.DataTo(&remote); err != nil {
+			return err
+		}
+		res.Revision = remote.Revision
+
+		if edit.Delete {
+			if remote.Revision != edit.BaseRevision {
+				res.Status = MergeConflict
+				res.Error = fmt.Sprintf("item changed on the server since revision %d", edit.BaseRevision)
+				res.Item = &remote
+				return nil
+			}
+			children, err := tx.Documents(col.Where("parent_id", "==", edit.ID)).GetAll()
+			if err != nil {
+				return err
+			}
+			for _, child := range children {
+				if trashed(child) {
+					continue
+				}
+				if err := tx.Update(child.Ref, withRevision([]firestore.Update{{Path: "parent_id", Value: firestore.Delete}}, s.writeTime())); err != nil {
+					return err
+				}
+			}
+			res.Status, res.Revision, deleted = MergeDeleted, 0, true
+			return s.trashItem(tx, ref)
+		}
+
+		applied, conflicts, err := mergeItem(remote, edit)
+		if err != nil {
+			return err
+		}
+		res.Conflicts = conflicts
+		if len(applied) > 0 {
+			updates := mergeUpdates(applied, s.Now(), s.writeTime())
+			if q, ok := applied["quantity"]; ok {
+				q, _ := q.(string)
+				updates = append(updates, amountUpdates(q, s.profile)...)
+			}
+			if err := tx.Update(ref, updates); err != nil {
+				return err
+			}
+			for f := range applied {
+				res.Applied = append(res.Applied, f)
+			}
+			sort.Strings(res.Applied)
+			res.Revision++
+		}
+		switch {
+		case len(conflicts) > 0:
+			res.Status = MergeConflict
+		case len(applied) > 0:
+			res.Status = MergeApplied
+		default:
+			res.Status = MergeUnchanged
+		}
+		return nil
+	})
+	if err != nil {
+		return MergeResult{}, fmt.Errorf("merge item %q: %w", edit.ID, err)
+	}
+	if deleted {
+		done.removed = append(done.removed, Item{ID: edit.ID})
+		done.changes = append(done.changes, newItemChange(ActionRemoved, &remote, nil))
+	}
+	if res.Status != MergeDeleted && res.Item == nil && res.Error == "" {
+		if it, err := s.GetItem(ctx, edit.ID); err == nil {
+			res.Item = it
+		}
+	}
+	if len(res.Applied) > 0 {
+		done.updated = append(done.updated, Item{ID: edit.ID})
+		change := ItemChange{ID: edit.ID, Name: remote.Name, Action: ActionUpdated}
+		if res.Item != nil {
+			change = newItemChange(ActionUpdated, &remote, res.Item)
+		}
+		done.changes = append(done.changes, change)
+	}
+	return res, nil
+}
@@ -0,0 +1,90 @@
+package shoppinglist
+
+import (
+	"testing"
+	"time"
+
+	"cloud.google.com/go/firestore"
+)
+
+func TestMergeItemFastForwardsFromCurrentRevision(t *testing.T) {
+	remote := Item{ID: "a", Name: "milk", Revision: 3}
+	applied, conflicts, err := mergeItem(remote, ItemEdit{
+		ID:           "a",
+		BaseRevision: 3,
+		Changes:      map[string]any{"quantity": "2 L", "purchased": true},
+	})
+	if err != nil {
+		t.Fatalf("mergeItem returned error: %v", err)
+	}
+	if len(conflicts) != 0 {
+		t.Fatalf("expected no conflicts, got %+v", conflicts)
+	}
+	if applied["quantity"] != "2 L" || applied["purchased"] != true {
+		t.Fatalf("unexpected applied fields: %v", applied)
+	}
+}
+
+func TestMergeItemThreeWay(t *testing.T) {
+	q := "1"
+	// The server renamed the item and changed its quantity since revision 2.
+	remote := Item{ID: "a", Name: "oat milk", Quantity: &q, Revision: 4}
+	applied, conflicts, err := mergeItem(remote, ItemEdit{
+		ID:           "a",
+		BaseRevision: 2,
+		Base:         map[string]any{"name": "milk", "quantity": "2", "staple": false},
+		Changes:      map[string]any{"name": "oat milk", "quantity": "3", "staple": true},
+	})
+	if err != nil {
+		t.Fatalf("mergeItem returned error: %v", err)
+	}
+	if len(applied) != 1 || applied["staple"] != true {
+		t.Fatalf("expected only staple to apply, got %v", applied)
+	}
+	if len(conflicts) != 1 || conflicts[0].Field != "quantity" || conflicts[0].Local != "3" || conflicts[0].Remote != "1" {
+		t.Fatalf("expected a quantity conflict, got %+v", conflicts)
+	}
+}
+
+func TestMergeItemWithoutBaseValueConflicts(t *testing.T) {
+	remote := Item{ID: "a", Name: "bread", Revision: 5}
+	_, conflicts, err := mergeItem(remote, ItemEdit{ID: "a", BaseRevision: 4, Changes: map[string]any{"name": "rye bread"}})
+	if err != nil {
+		t.Fatalf("mergeItem returned error: %v", err)
+	}
+	if len(conflicts) != 1 {
+		t.Fatalf("expected a conflict when the base value is unknown, got %+v", conflicts)
+	}
+}
+
+func TestMergeItemClearsOptionalFields(t *testing.T) {
+	q := "2"
+	remote := Item{ID: "a", Name: "eggs", Quantity: &q, Revision: 1}
+	applied, _, err := mergeItem(remote, ItemEdit{ID: "a", BaseRevision: 1, Changes: map[string]any{"quantity": ""}})
+	if err != nil {
+		t.Fatalf("mergeItem returned error: %v", err)
+	}
+	if v, ok := applied["quantity"]; !ok || v != nil {
+		t.Fatalf("expected quantity to be cleared, got %v", applied)
+	}
+	updates := mergeUpdates(applied, remote.CreatedAt, time.Time{})
+	if updates[0].Path != "quantity" || updates[0].Value != firestore.Delete {
+		t.Fatalf("expected a delete of quantity, got %+v", updates[0])
+	}
+	if last := updates[len(updates)-1]; last.Path != "revision" {
+		t.Fatalf("expected the revision to be bumped, got %+v", last)
+	}
+}
+
+func TestMergeItemRejectsUnknownFields(t *testing.T) {
+	cases := []map[string]any{
+		{"created_at": "2024-01-01"},
+		{"purchased": "yes"},
+		{"name": ""},
+	}
+	for _, changes := range cases {
+		if _, _, err := mergeItem(Item{}, ItemEdit{Changes: changes}); err == nil {
+			t.Errorf("expected an error for changes %v", changes)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package shoppinglist
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	pb "cloud.google.com/go/firestore/apiv1/firestorepb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// -----------------------------------------------------------------------------
+// Legacy quantity migration
+// -----------------------------------------------------------------------------
+
+// quantityFormatStructured marks items whose amount and unit have been parsed
+// from their quantity. Items written before structured quantities lack it.
+const quantityFormatStructured = 1
+
+// migrateBatch is how many legacy items one read writes back.
+const migrateBatch = 100
+
+// legacyItem is an item read without structured quantities, to be written
+// back with them.
+type legacyItem struct {
+	ref       *firestore.DocumentRef
+	updatedAt time.Time
+	amount    *float64
+	unit      string
+}
+
+// upgradeQuantities parses the quantities of the items read without
+// structured ones, in place, so they are returned in structured form, and
+// returns them to be written back.
+func (s *Service) upgradeQuantities(docs []*firestore.DocumentSnapshot, items []Item) []legacyItem {
+	byID := make(map[string]*firestore.DocumentSnapshot, len(docs))
+	for _, d := range docs {
+		byID[d.Ref.ID] = d
+	}
+	var legacy []legacyItem
+	for i := range items {
+		it := &items[i]
+		d, ok := byID[it.ID]
+		if !ok || it.QuantityFormat >= quantityFormatStructured {
+			continue
+		}
+		withAmount(it, s.profile)
+		legacy = append(legacy, legacyItem{ref: d.Ref, updatedAt: d.UpdateTime, amount: it.Amount, unit: it.Unit})
+	}
+	return legacy
+}
+
+// writeBackQuantities stores the structured quantities of up to migrateBatch
+// legacy items in the background, one migration per list at a time. Each
+// write requires the item to be unchanged since it was read, so it cannot
+// undo a concurrent edit; skipped items are migrated on a later read. The
+// revision is left alone, as the item's content does not change. Reads made
+// in explain mode write nothing back.
+func (s *Service) writeBackQuantities(ctx context.Context, legacy []legacyItem) {
+	if len(legacy) == 0 || explanationFrom(ctx) != nil || !s.migrating.CompareAndSwap(false, true) {
+		return
+	}
+	legacy = legacy[:min(len(legacy), migrateBatch)]
+	// The migration outlives the read, so it keeps the read's context values
+	// but not its cancellation.
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		defer s.migrating.Store(false)
+		ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		defer cancel()
+
+		bw := s.client.BulkWriter(ctx)
+		jobs := make([]*firestore.BulkWriterJob, 0, len(legacy))
+		for _, l := range legacy {
+			var amount, unit any = firestore.Delete, firestore.Delete
+			if l.amount != nil {
+				amount = *l.amount
+			}
+			if l.unit != "" {
+				unit = l.unit
+			}
+			updates := []firestore.Update{
+				{Path: "amount", Value: amount},
+				{Path: "unit", Value: unit},
+				{Path: "quantity_format", Value: quantityFormatStructured},
+			}
+			job, err := bw.Update(l.ref, updates, firestore.LastUpdateTime(l.updatedAt))
+			if err != nil {
+				log.Printf("warn: migrate quantity of %q: %v", l.ref.ID, err)
+				continue
+			}
+			jobs = append(jobs, job)
+		}
+		bw.End()
+		for i, job := range jobs {
+			if _, err := job.Results(); err != nil && status.Code(err) != codes.FailedPrecondition {
+				log.Printf("warn: migrate quantity of %q: %v", legacy[i].ref.ID, err)
+			}
+		}
+	}()
+}
+
+// QuantityMigration is how far a list's items have been moved to structured
+// quantities.
+type QuantityMigration struct {
+	Migrated int64 `json:"migrated"`
+	Pending  int64 `json:"pending"`
+}
+
+// quantityMigration counts the items of col already migrated, out of total.
+func quantityMigration(ctx context.Context, col *firestore.CollectionRef, total int64) (QuantityMigration, error) {
+	q := col.Where("quantity_format", ">=", quantityFormatStructured)
+	res, err := q.NewAggregationQuery().WithCount("n").Get(ctx)
+	if err != nil {
+		return QuantityMigration{}, err
+	}
+	var m QuantityMigration
+	if v, ok := res["n"].(*pb.Value); ok {
+		m.Migrated = v.GetIntegerValue()
+	}
+	m.Pending = max(total-m.Migrated, 0)
+	return m, nil
+}
@@ -0,0 +1,41 @@
+package shoppinglist
+
+import (
+	"context"
+	"testing"
+
+	"cloud.google.com/go/firestore"
+)
+
+func TestUpgradeQuantitiesParsesOnlyLegacyItems(t *testing.T) {
+	s := &Service{profile: HouseholdProfile{Locale: "de-DE"}}
+	legacyQ, currentQ := "1,5 kg", "2"
+	items := []Item{
+		{ID: "old", Quantity: &legacyQ},
+		{ID: "new", Quantity: &currentQ, QuantityFormat: quantityFormatStructured},
+	}
+	docs := []*firestore.DocumentSnapshot{
+		{Ref: &firestore.DocumentRef{ID: "old"}},
+		{Ref: &firestore.DocumentRef{ID: "new"}},
+	}
+
+	legacy := s.upgradeQuantities(docs, items)
+	if len(legacy) != 1 || legacy[0].ref.ID != "old" {
+		t.Fatalf("expected only the legacy item to be written back, got %+v", legacy)
+	}
+	if items[0].Amount == nil || *items[0].Amount != 1.5 || items[0].Unit != "kg" {
+		t.Fatalf("expected the legacy quantity parsed in the household locale, got %v %q", items[0].Amount, items[0].Unit)
+	}
+	if items[1].Amount != nil {
+		t.Fatalf("expected the migrated item left as stored, got %v", *items[1].Amount)
+	}
+}
+
+func TestWriteBackQuantitiesSkippedWhenExplained(t *testing.T) {
+	s := &Service{}
+	ctx, _ := WithExplain(context.Background())
+	s.writeBackQuantities(ctx, []legacyItem{{ref: &firestore.DocumentRef{ID: "old"}}})
+	if s.migrating.Load() {
+		t.Fatal("expected no migration to start for an explained read")
+	}
+}
@@ -0,0 +1,141 @@
+package shoppinglist
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// -----------------------------------------------------------------------------
+// Moving items between lists
+// -----------------------------------------------------------------------------
+
+// MoveItemResponse reports an item moved to another list, with the children
+// that moved with it.
+type MoveItemResponse struct {
+	Item     *Item  `json:"item"`
+	Children []Item `json:"children,omitempty"`
+	ResponseWarnings
+}
+
+// planMove returns item and its children as they are written to the
+// destination list: the item under newID and top-level, its children nested
+// under it, all unplaced so they follow the items placed there, and each with
+// a new revision stamped at at (see writeTime). Every other field is kept.
+func planMove(item Item, children []Item, newID string, at time.Time) (Item, []Item) {
+	item.ID, item.ParentID, item.Position = newID, nil, 0
+	item.Revision++
+	item.UpdatedAt = &at
+	moved := make([]Item, len(children))
+	for i, c := range children {
+		c.ParentID = &newID
+		c.Position = 0
+		c.Revision++
+		c.UpdatedAt = &at
+		moved[i] = c
+	}
+	return item, moved
+}
+
+// MoveItem moves an item, with its nested children, to the list with the
+// given ID, slug, or former slug (the main list when empty). In one
+// transaction each is created there with its fields as they are and deleted
+// here. With derived IDs the item takes the ID its name has on that list.
+func (s *Service) MoveItem(ctx context.Context, id, to string) (MoveItemResponse, error) {
+	dest := s.Root()
+	if to != "" {
+		list, err := s.ResolveList(ctx, to)
+		if err != nil {
+			return MoveItemResponse{}, err
+		}
+		if dest, err = s.ForList(list); err != nil {
+			return MoveItemResponse{}, err
+		}
+	}
+	if dest.collection == s.collection && dest.database == s.database {
+		return MoveItemResponse{}, errors.New("the item is already on that list")
+	}
+	// A transaction cannot span databases, so the move could not be atomic.
+	if dest.database != s.database {
+		return MoveItemResponse{}, errors.New("items cannot be moved between lists stored in different databases")
+	}
+	if err := dest.checkNotFrozen(ctx); err != nil {
+		return MoveItemResponse{}, err
+	}
+
+	src, dst := s.client.Collection(s.collection), dest.client.Collection(dest.collection)
+	var (
+		original []Item
+		item     Item
+		children []Item
+		commit   firestore.CommitResponse
+	)
+	err := s.runTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := untrashed(tx.Get(src.Doc(id)))
+		if status.Code(err) == codes.NotFound {
+			return fmt.Errorf("%w: %q", ErrItemNotFound, id)
+		}
+		if err != nil {
+			return err
+		}
+		var it Item
+		if err := doc.DataTo(&it); err != nil {
+			return err
+		}
+		docs, err := tx.Documents(src.Where("parent_id", "==", id)).GetAll()
+		if err != nil {
+			return err
+		}
+		kids := decodeItems(docs)
+		original = append([]Item{it}, kids...)
+
+		newID := id
+		if dest.derivedIDs {
+			newID = dest.newItemID(it.Name)
+		}
+		item, children = planMove(it, kids, newID, s.writeTime())
+		moved := append([]Item{item}, children...)
+		refs := make([]*firestore.DocumentRef, len(moved))
+		for i, it := range moved {
+			refs[i] = dst.Doc(it.ID)
+		}
+		// The destination may hold a removed item under an ID being moved.
+		there, err := tx.GetAll(refs)
+		if err != nil {
+			return err
+		}
+		trash := trashedIDs(there)
+		for _, it := range moved {
+			if err := createItem(tx, dst.Doc(it.ID), it, trash[it.ID]); err != nil {
+				return err
+			}
+		}
+		for _, it := range original {
+			if err := s.deleteItem(tx, src.Doc(it.ID)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}, firestore.WithCommitResponseTo(&commit))
+	if status.Code(err) == codes.AlreadyExists {
+		return MoveItemResponse{}, fmt.Errorf("move item: %q is already on that list", item.Name)
+	}
+	if err != nil {
+		return MoveItemResponse{}, fmt.Errorf("move item: %w", err)
+	}
+	s.stamped(&item, commit.CommitTime())
+	for i := range children {
+		s.stamped(&children[i], commit.CommitTime())
+	}
+	moved := append([]Item{item}, children...)
+	s.recordActivity(ctx, ActionRemoved, original...)
+	s.recordAudit(ctx, itemChanges(ActionRemoved, original)...)
+	dest.recordActivity(ctx, ActionAdded, moved...)
+	dest.recordAudit(ctx, itemChanges(ActionAdded, moved)...)
+	return MoveItemResponse{Item: &item, Children: children}, nil
+}
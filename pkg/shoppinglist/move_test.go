@@ -0,0 +1,30 @@
+package shoppinglist
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPlanMoveKeepsFieldsAndRenests(t *testing.T) {
+	parent, category, taco := "group", "produce", "taco"
+	item := Item{ID: "taco", Name: "Taco night", ParentID: &parent, Category: &category, Position: 3, Revision: 2}
+	children := []Item{{ID: "salsa", Name: "salsa", ParentID: &taco, Position: 4, Revision: 1}}
+
+	now := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	moved, kids := planMove(item, children, "taco-2", now)
+	if moved.ID != "taco-2" || moved.ParentID != nil || moved.Position != 0 || moved.Revision != 3 {
+		t.Fatalf("unexpected moved item: %+v", moved)
+	}
+	if moved.Category == nil || *moved.Category != "produce" || moved.Name != "Taco night" {
+		t.Fatalf("expected fields to be kept, got %+v", moved)
+	}
+	if len(kids) != 1 || deref(kids[0].ParentID) != "taco-2" || kids[0].Position != 0 || kids[0].Revision != 2 {
+		t.Fatalf("unexpected children: %+v", kids)
+	}
+	if moved.UpdatedAt == nil || !moved.UpdatedAt.Equal(now) || kids[0].UpdatedAt == nil || !kids[0].UpdatedAt.Equal(now) {
+		t.Fatal("expected the moved items to be stamped with the move time")
+	}
+	if deref(children[0].ParentID) != "taco" {
+		t.Fatal("expected the input children to be left untouched")
+	}
+}
@@ -0,0 +1,235 @@
+package shoppinglist
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// -----------------------------------------------------------------------------
+// Name normalization
+// -----------------------------------------------------------------------------
+
+// DefaultNormalization is the pipeline used when none is configured.
+const DefaultNormalization = "trim,lowercase"
+
+// NameNormalizer turns item names into the keys used for matching and
+// de-duplication. Stored names are left as entered.
+type NameNormalizer struct {
+	steps []func(string) string
+}
+
+// Key runs name through each step of the pipeline in order.
+func (n *NameNormalizer) Key(name string) string {
+	if n == nil {
+		return strings.ToLower(strings.Join(strings.Fields(name), " "))
+	}
+	for _, step := range n.steps {
+		name = step(name)
+	}
+	return name
+}
+
+// ParseNormalizer builds a pipeline from a comma-separated list of steps:
+// trim, lowercase, strip-emoji, singular[:en|es|fr], and synonyms. The
+// synonyms step maps names using the entries in synonymsPath.
+func ParseNormalizer(spec, synonymsPath string) (*NameNormalizer, error) {
+	if strings.TrimSpace(spec) == "" {
+		spec = DefaultNormalization
+	}
+
+	n := &NameNormalizer{}
+	for _, raw := range strings.Split(spec, ",") {
+		name, arg, _ := strings.Cut(strings.TrimSpace(raw), ":")
+		switch name {
+		case "trim":
+			n.steps = append(n.steps, func(s string) string { return strings.Join(strings.Fields(s), " ") })
+		case "lowercase":
+			n.steps = append(n.steps, strings.ToLower)
+		case "strip-emoji":
+			n.steps = append(n.steps, stripEmoji)
+		case "singular":
+			rule, err := singularRule(arg)
+			if err != nil {
+				return nil, err
+			}
+			n.steps = append(n.steps, func(s string) string { return mapWords(s, rule) })
+		case "synonyms":
+			if synonymsPath == "" {
+				return nil, fmt.Errorf("normalization step %q needs a synonyms file", name)
+			}
+			synonyms, err := loadSynonyms(synonymsPath)
+			if err != nil {
+				return nil, err
+			}
+			n.steps = append(n.steps, func(s string) string {
+				if canonical, ok := synonyms[strings.ToLower(s)]; ok {
+					return canonical
+				}
+				return s
+			})
+		case "":
+		default:
+			return nil, fmt.Errorf("unknown normalization step %q (expected trim, lowercase, strip-emoji, singular, or synonyms)", name)
+		}
+	}
+	return n, nil
+}
+
+// stripEmoji removes pictographs, joiners, and variation selectors.
+func stripEmoji(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if unicode.Is(unicode.So, r) || r == 0x200D || (r >= 0xFE00 && r <= 0xFE0F) || (r >= 0x1F3FB && r <= 0x1F3FF) {
+			return -1
+		}
+		return r
+	}, s)
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// mapWords applies fn to each word of s.
+func mapWords(s string, fn func(string) string) string {
+	words := strings.Fields(s)
+	for i, w := range words {
+		words[i] = fn(w)
+	}
+	return strings.Join(words, " ")
+}
+
+// singularRule returns the singularization rule for a language.
+func singularRule(lang string) (func(string) string, error) {
+	switch strings.ToLower(lang) {
+	case "", "en":
+		return singularEnglish, nil
+	case "es":
+		return singularSpanish, nil
+	case "fr":
+		return singularFrench, nil
+	default:
+		return nil, fmt.Errorf("no singularization rules for language %q (expected en, es, or fr)", lang)
+	}
+}
+
+var englishIrregulars = map[string]string{
+	"leaves":   "leaf",
+	"loaves":   "loaf",
+	"halves":   "half",
+	"knives":   "knife",
+	"geese":    "goose",
+	"teeth":    "tooth",
+	"mice":     "mouse",
+	"children": "child",
+}
+
+func singularEnglish(w string) string {
+	lower := strings.ToLower(w)
+	if s, ok := englishIrregulars[lower]; ok {
+		return s
+	}
+	switch {
+	case len(w) <= 3:
+		return w
+	case strings.HasSuffix(lower, "ies"):
+		return w[:len(w)-3] + "y"
+	case strings.HasSuffix(lower, "oes"), strings.HasSuffix(lower, "ches"), strings.HasSuffix(lower, "shes"),
+		strings.HasSuffix(lower, "sses"), strings.HasSuffix(lower, "xes"):
+		return w[:len(w)-2]
+	case strings.HasSuffix(lower, "ss"), strings.HasSuffix(lower, "us"), strings.HasSuffix(lower, "is"):
+		return w
+	case strings.HasSuffix(lower, "s"):
+		return w[:len(w)-1]
+	}
+	return w
+}
+
+func singularSpanish(w string) string {
+	lower := strings.ToLower(w)
+	switch {
+	case len(w) <= 3:
+		return w
+	case strings.HasSuffix(lower, "ces"):
+		return w[:len(w)-3] + "z"
+	case strings.HasSuffix(lower, "es") && !isVowel(rune(lower[len(lower)-3])):
+		return w[:len(w)-2]
+	case strings.HasSuffix(lower, "s"):
+		return w[:len(w)-1]
+	}
+	return w
+}
+
+func singularFrench(w string) string {
+	lower := strings.ToLower(w)
+	switch {
+	case len(w) <= 3:
+		return w
+	case strings.HasSuffix(lower, "aux"):
+		return w[:len(w)-3] + "al"
+	case strings.HasSuffix(lower, "s"), strings.HasSuffix(lower, "x"):
+		return w[:len(w)-1]
+	}
+	return w
+}
+
+func isVowel(r rune) bool { return strings.ContainsRune("aeiou", r) }
+
+// loadSynonyms reads lines of the form "canonical: alias, alias" into a map
+// from each alias (and the canonical name itself) to the canonical name.
+func loadSynonyms(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("synonyms file: %w", err)
+	}
+	defer f.Close()
+
+	synonyms := map[string]string{}
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		canonical, aliases, ok := strings.Cut(text, ":")
+		if !ok {
+			return nil, fmt.Errorf("synonyms file line %d: expected 'canonical: alias, alias'", line)
+		}
+		canonical = strings.ToLower(strings.TrimSpace(canonical))
+		synonyms[canonical] = canonical
+		for _, a := range strings.Split(aliases, ",") {
+			if a = strings.ToLower(strings.TrimSpace(a)); a != "" {
+				synonyms[a] = canonical
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("synonyms file: %w", err)
+	}
+	return synonyms, nil
+}
+
+// normalizingEmbedder embeds normalized names so similarity checks see the
+// same keys as exact matching.
+type normalizingEmbedder struct {
+	Embedder
+	normalizer *NameNormalizer
+}
+
+// NormalizeEmbeddings wraps embedder so texts pass through normalizer first.
+func NormalizeEmbeddings(embedder Embedder, normalizer *NameNormalizer) Embedder {
+	return normalizingEmbedder{Embedder: embedder, normalizer: normalizer}
+}
+
+func (e normalizingEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	keys := make([]string, len(texts))
+	for i, t := range texts {
+		keys[i] = e.normalizer.Key(t)
+	}
+	return e.Embedder.Embed(ctx, keys)
+}
+
+// WithNormalizer sets the pipeline used to match item names.
+func WithNormalizer(n *NameNormalizer) ServiceOption {
+	return func(s *Service) { s.normalizer = n }
+}
@@ -0,0 +1,91 @@
+package shoppinglist
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDefaultNormalizerTrimsAndLowercases(t *testing.T) {
+	n, err := ParseNormalizer("", "")
+	if err != nil {
+		t.Fatalf("ParseNormalizer returned error: %v", err)
+	}
+	if got := n.Key("  Green   Onions "); got != "green onions" {
+		t.Fatalf("unexpected key %q", got)
+	}
+}
+
+func TestNormalizerPipeline(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "synonyms.txt")
+	if err := os.WriteFile(path, []byte("# produce\ngreen onion: scallion, spring onion\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := ParseNormalizer("trim,lowercase,strip-emoji,singular:en,synonyms", path)
+	if err != nil {
+		t.Fatalf("ParseNormalizer returned error: %v", err)
+	}
+	cases := map[string]string{
+		"Scallions 🧅":   "green onion",
+		"Spring Onions": "green onion",
+		"Berries":       "berry",
+		"tomatoes":      "tomato",
+		"Peaches":       "peach",
+		"hummus":        "hummus",
+		"Loaves":        "loaf",
+	}
+	for in, want := range cases {
+		if got := n.Key(in); got != want {
+			t.Errorf("Key(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestSingularRulesPerLanguage(t *testing.T) {
+	cases := []struct{ lang, in, want string }{
+		{"es", "manzanas", "manzana"},
+		{"es", "nueces", "nuez"},
+		{"es", "limones", "limon"},
+		{"fr", "pommes", "pomme"},
+		{"fr", "choux", "chou"},
+	}
+	for _, c := range cases {
+		rule, err := singularRule(c.lang)
+		if err != nil {
+			t.Fatalf("singularRule(%q) returned error: %v", c.lang, err)
+		}
+		if got := rule(c.in); got != c.want {
+			t.Errorf("%s: singular(%q) = %q, want %q", c.lang, c.in, got, c.want)
+		}
+	}
+}
+
+func TestParseNormalizerRejectsBadSpecs(t *testing.T) {
+	for _, spec := range []string{"stem", "singular:xx", "synonyms"} {
+		if _, err := ParseNormalizer(spec, ""); err == nil {
+			t.Errorf("expected error for %q", spec)
+		}
+	}
+}
+
+func TestNormalizingEmbedderMatchesSynonyms(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "synonyms.txt")
+	if err := os.WriteFile(path, []byte("green onion: scallion\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	n, err := ParseNormalizer("trim,lowercase,singular,synonyms", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	embedder := NormalizeEmbeddings(LocalEmbedder{Dims: 256}, n)
+	matches, err := FindSimilarItems(context.Background(), embedder, "Scallions", []Item{{ID: "1", Name: "green onion"}}, 0.99)
+	if err != nil {
+		t.Fatalf("findSimilarItems returned error: %v", err)
+	}
+	if len(matches) != 1 {
+		t.Fatalf("expected the synonym to match, got %v", matches)
+	}
+}
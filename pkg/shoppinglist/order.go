@@ -0,0 +1,112 @@
+package shoppinglist
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"cloud.google.com/go/firestore"
+)
+
+// -----------------------------------------------------------------------------
+// Manual ordering
+// -----------------------------------------------------------------------------
+
+// reorderMax is the most items a reorder rewrites, Firestore's limit on
+// writes in one transaction.
+const reorderMax = 500
+
+// compareOrder orders items by position, then those never placed, oldest
+// first.
+func compareOrder(a, b Item) int {
+	switch {
+	case a.Position > 0 && b.Position > 0 && a.Position != b.Position:
+		return cmp.Compare(a.Position, b.Position)
+	case a.Position > 0 && b.Position == 0:
+		return -1
+	case a.Position == 0 && b.Position > 0:
+		return 1
+	}
+	if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+		return c
+	}
+	return strings.Compare(a.ID, b.ID)
+}
+
+// sortItems puts items in list order.
+func sortItems(items []Item) {
+	slices.SortStableFunc(items, compareOrder)
+}
+
+// planReorder works out the position of each item after moving ids, in that
+// order, to the top of the list, keeping the rest in their current order. It
+// returns only the positions that change.
+func planReorder(items []Item, ids []string) (map[string]int64, error) {
+	byID := make(map[string]bool, len(items))
+	for _, it := range items {
+		byID[it.ID] = true
+	}
+	seen := map[string]bool{}
+	for _, id := range ids {
+		if !byID[id] {
+			return nil, fmt.Errorf("%w: %q", ErrItemNotFound, id)
+		}
+		if seen[id] {
+			return nil, fmt.Errorf("item %q is listed twice", id)
+		}
+		seen[id] = true
+	}
+
+	current := slices.Clone(items)
+	sortItems(current)
+	order := slices.Clone(ids)
+	for _, it := range current {
+		if !seen[it.ID] {
+			order = append(order, it.ID)
+		}
+	}
+
+	was := make(map[string]int64, len(items))
+	for _, it := range items {
+		was[it.ID] = it.Position
+	}
+	moves := map[string]int64{}
+	for i, id := range order {
+		if pos := int64(i + 1); was[id] != pos {
+			moves[id] = pos
+		}
+	}
+	return moves, nil
+}
+
+// ReorderItems moves the items with the given IDs, in that order, to the top
+// of the list, followed by the rest in their current order, and returns the
+// reordered list. Positions are rewritten in one transaction.
+func (s *Service) ReorderItems(ctx context.Context, ids []string) ([]Item, error) {
+	col := s.client.Collection(s.collection)
+	err := s.runTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		docs, err := tx.Documents(col).GetAll()
+		if err != nil {
+			return err
+		}
+		moves, err := planReorder(decodeItems(docs), ids)
+		if err != nil {
+			return err
+		}
+		if len(moves) > reorderMax {
+			return fmt.Errorf("at most %d items can be repositioned at once, this order moves %d", reorderMax, len(moves))
+		}
+		for id, pos := range moves {
+			if err := tx.Update(col.Doc(id), withRevision([]firestore.Update{{Path: "position", Value: pos}}, s.writeTime())); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("reorder items: %w", err)
+	}
+	return s.ListItems(ctx)
+}
@@ -0,0 +1,51 @@
+package shoppinglist
+
+import (
+	"errors"
+	"maps"
+	"testing"
+	"time"
+)
+
+func TestSortItemsPutsPositionedItemsFirst(t *testing.T) {
+	t0 := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	items := []Item{
+		{ID: "new", CreatedAt: t0.Add(2 * time.Hour)},
+		{ID: "second", Position: 2},
+		{ID: "old", CreatedAt: t0},
+		{ID: "first", Position: 1, CreatedAt: t0.Add(time.Hour)},
+	}
+	sortItems(items)
+	var got []string
+	for _, it := range items {
+		got = append(got, it.ID)
+	}
+	want := []string{"first", "second", "old", "new"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("order = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestPlanReorder(t *testing.T) {
+	items := []Item{{ID: "a", Position: 1}, {ID: "b", Position: 2}, {ID: "c", Position: 3}, {ID: "d"}}
+	moves, err := planReorder(items, []string{"c", "a"})
+	if err != nil {
+		t.Fatalf("planReorder returned error: %v", err)
+	}
+	want := map[string]int64{"c": 1, "a": 2, "b": 3, "d": 4}
+	if !maps.Equal(moves, want) {
+		t.Errorf("moves = %v, want %v", moves, want)
+	}
+
+	if moves, _ := planReorder(items, []string{"a", "b"}); len(moves) != 1 || moves["d"] != 4 {
+		t.Errorf("expected only the unplaced item to move, got %v", moves)
+	}
+	if _, err := planReorder(items, []string{"a", "x"}); !errors.Is(err, ErrItemNotFound) {
+		t.Errorf("expected ErrItemNotFound, got %v", err)
+	}
+	if _, err := planReorder(items, []string{"a", "a"}); err == nil {
+		t.Error("expected a repeated ID to be refused")
+	}
+}
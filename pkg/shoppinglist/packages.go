@@ -0,0 +1,168 @@
+package shoppinglist
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"unicode"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// -----------------------------------------------------------------------------
+// Package sizes and unit prices
+// -----------------------------------------------------------------------------
+
+// PackageOption is one way of buying an item, e.g. "500 g for 3.49".
+type PackageOption struct {
+	Size      string  `json:"size" firestore:"size"`
+	Price     float64 `json:"price" firestore:"price"`
+	Currency  string  `json:"currency,omitempty" firestore:"currency,omitempty"`
+	Label     string  `json:"label,omitempty" firestore:"label,omitempty"`
+	UnitPrice float64 `json:"unit_price" firestore:"unit_price"`
+	UnitBasis string  `json:"unit_basis" firestore:"unit_basis"`
+}
+
+// sizeUnits maps unit spellings to their base unit and conversion factor.
+var sizeUnits = map[string]struct {
+	base   string
+	factor float64
+}{
+	"mg": {"g", 0.001}, "g": {"g", 1}, "gram": {"g", 1}, "grams": {"g", 1},
+	"kg": {"g", 1000}, "oz": {"g", 28.349523125}, "lb": {"g", 453.59237}, "lbs": {"g", 453.59237},
+	"ml": {"ml", 1}, "cl": {"ml", 10}, "dl": {"ml", 100}, "l": {"ml", 1000}, "liter": {"ml", 1000}, "litre": {"ml", 1000},
+	"floz": {"ml", 29.5735295625}, "gal": {"ml", 3785.411784},
+	"ct": {"ct", 1}, "count": {"ct", 1}, "pack": {"ct", 1}, "pk": {"ct", 1}, "ea": {"ct", 1}, "each": {"ct", 1}, "x": {"ct", 1},
+}
+
+// unitBases names the basis used when reporting unit prices.
+var unitBases = map[string]struct {
+	label string
+	per   float64
+}{
+	"g":  {"100 g", 100},
+	"ml": {"100 ml", 100},
+	"ct": {"1 ct", 1},
+}
+
+// ParsePackageSize parses sizes like "500 g", "1.5kg", "12 fl oz", or "6 ct",
+// with the amount written in the household's locale, into an amount in the
+// base unit (g, ml, or ct).
+func ParsePackageSize(s string, p HouseholdProfile) (float64, string, error) {
+	trimmed := strings.TrimSpace(strings.ToLower(s))
+	end := strings.IndexFunc(trimmed, func(r rune) bool { return !unicode.IsDigit(r) && r != '.' && r != ',' && r != '\'' })
+	if end <= 0 {
+		return 0, "", fmt.Errorf("package size %q: expected an amount followed by a unit", s)
+	}
+
+	amount, err := p.ParseNumber(trimmed[:end])
+	if err != nil || amount <= 0 {
+		return 0, "", fmt.Errorf("package size %q: invalid amount", s)
+	}
+
+	unit := unitKey(trimmed[end:])
+	conv, ok := sizeUnits[unit]
+	if !ok {
+		return 0, "", fmt.Errorf("package size %q: unknown unit %q", s, unit)
+	}
+	return amount * conv.factor, conv.base, nil
+}
+
+// NewPackageOption computes the unit price for a package size and price.
+func NewPackageOption(size string, price float64, currency, label string, p HouseholdProfile) (PackageOption, error) {
+	if price < 0 {
+		return PackageOption{}, fmt.Errorf("price must not be negative")
+	}
+	amount, base, err := ParsePackageSize(size, p)
+	if err != nil {
+		return PackageOption{}, err
+	}
+	basis := unitBases[base]
+	return PackageOption{
+		Size:      size,
+		Price:     price,
+		Currency:  currency,
+		Label:     label,
+		UnitPrice: math.Round(price/amount*basis.per*10000) / 10000,
+		UnitBasis: basis.label,
+	}, nil
+}
+
+// BestValueResponse ranks an item's package options by unit price.
+type BestValueResponse struct {
+	ItemID  string          `json:"item_id"`
+	Name    string          `json:"name"`
+	Best    *PackageOption  `json:"best,omitempty"`
+	Options []PackageOption `json:"options"`
+	ResponseWarnings
+}
+
+// ItemResponse wraps a single item, and the shopping session totals after
+// it was checked off or put back.
+type ItemResponse struct {
+	Item     *Item           `json:"item"`
+	Shopping *ShoppingTotals `json:"shopping,omitempty"`
+	ResponseWarnings
+}
+
+// AddPackageOption records a package option on an item.
+func (s *Service) AddPackageOption(ctx context.Context, id string, option PackageOption) (*Item, error) {
+	ref := s.client.Collection(s.collection).Doc(id)
+	if _, err := ref.Update(ctx, withRevision([]firestore.Update{{Path: "package_options", Value: firestore.ArrayUnion(option)}}, s.writeTime())); err != nil {
+		return nil, fmt.Errorf("add package option: %w", err)
+	}
+	return s.GetItem(ctx, id)
+}
+
+// ErrItemNotFound is returned when no item has the given ID.
+var ErrItemNotFound = errors.New("item not found")
+
+// GetItem returns a single item by ID, or ErrItemNotFound.
+func (s *Service) GetItem(ctx context.Context, id string) (*Item, error) {
+	it, _, err := s.getItemDoc(ctx, id)
+	return it, err
+}
+
+// getItemDoc reads the item with id along with its document.
+func (s *Service) getItemDoc(ctx context.Context, id string) (*Item, *firestore.DocumentSnapshot, error) {
+	var doc *firestore.DocumentSnapshot
+	err := s.retry(ctx, func() (err error) {
+		doc, err = untrashed(s.client.Collection(s.collection).Doc(id).Get(ctx))
+		return err
+	})
+	if status.Code(err) == codes.NotFound {
+		return nil, nil, fmt.Errorf("%w: %q", ErrItemNotFound, id)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("get item: %w", err)
+	}
+	var it Item
+	if err := doc.DataTo(&it); err != nil {
+		return nil, nil, fmt.Errorf("decode item: %w", err)
+	}
+	return &it, doc, nil
+}
+
+// ConvertPackageOptions expresses each option's price and unit price in currency.
+func ConvertPackageOptions(ctx context.Context, rates ExchangeRateSource, currency string, options []PackageOption) ([]PackageOption, error) {
+	out := make([]PackageOption, 0, len(options))
+	for _, o := range options {
+		from := o.Currency
+		if from == "" {
+			from = currency
+		}
+		rate, err := rates.Rate(ctx, from, currency)
+		if err != nil {
+			return nil, err
+		}
+		o.Price = roundCents(o.Price * rate)
+		o.UnitPrice = math.Round(o.UnitPrice*rate*10000) / 10000
+		o.Currency = currency
+		out = append(out, o)
+	}
+	return out, nil
+}
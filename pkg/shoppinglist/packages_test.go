@@ -0,0 +1,38 @@
+package shoppinglist
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParsePackageSizeConvertsToBaseUnits(t *testing.T) {
+	cases := []struct {
+		in     string
+		amount float64
+		base   string
+	}{
+		{"500 g", 500, "g"},
+		{"1.5kg", 1500, "g"},
+		{"2 L", 2000, "ml"},
+		{"12 fl oz", 12 * 29.5735295625, "ml"},
+		{"6 ct", 6, "ct"},
+		{"0,75 l", 750, "ml"},
+	}
+	for _, tc := range cases {
+		amount, base, err := ParsePackageSize(tc.in, HouseholdProfile{})
+		if err != nil {
+			t.Fatalf("parsePackageSize(%q) returned error: %v", tc.in, err)
+		}
+		if math.Abs(amount-tc.amount) > 1e-9 || base != tc.base {
+			t.Fatalf("parsePackageSize(%q) = %v %s, want %v %s", tc.in, amount, base, tc.amount, tc.base)
+		}
+	}
+}
+
+func TestParsePackageSizeRejectsInvalidInput(t *testing.T) {
+	for _, in := range []string{"", "big", "500", "3 bushels", "0 g"} {
+		if _, _, err := ParsePackageSize(in, HouseholdProfile{}); err == nil {
+			t.Fatalf("expected error for %q", in)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package shoppinglist
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/firestore"
+)
+
+// -----------------------------------------------------------------------------
+// Paging
+// -----------------------------------------------------------------------------
+
+// MaxPageSize is the most items one page of list_items returns.
+const MaxPageSize = 500
+
+// PageCursor marks the last item of a page: pages are in the order items were
+// added, so the next one starts after this creation time and ID.
+type PageCursor struct {
+	CreatedAt time.Time `json:"a"`
+	ID        string    `json:"i"`
+}
+
+func encodePageCursor(c PageCursor) string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// DecodePageCursor parses a next_cursor from a page of list_items. Summary
+// cursors are not page cursors and are refused.
+func DecodePageCursor(s string) (PageCursor, error) {
+	var c PageCursor
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err == nil {
+		err = json.Unmarshal(b, &c)
+	}
+	if err != nil || c.ID == "" || c.CreatedAt.IsZero() {
+		return PageCursor{}, fmt.Errorf("invalid cursor %q", s)
+	}
+	return c, nil
+}
+
+// compareCreated orders items by when they were added, then by ID, the order
+// pages are read in.
+func compareCreated(a, b Item) int {
+	if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+		return c
+	}
+	return strings.Compare(a.ID, b.ID)
+}
+
+// ViewPage reads up to limit items, with the freeze state, at one read time.
+// Items are in the order they were added, starting after cursor (from the
+// first item when it is nil), and the returned cursor continues after the
+// last of them; it is empty on the last page. Only the page is read, via a
+// Firestore StartAfter query.
+func (s *Service) ViewPage(ctx context.Context, limit int, cursor *PageCursor) (ListView, string, error) {
+	if limit < 1 || limit > MaxPageSize {
+		return ListView{}, "", fmt.Errorf("page size must be between 1 and %d", MaxPageSize)
+	}
+	q := s.client.Collection(s.collection).
+		OrderBy("created_at", firestore.Asc).
+		OrderBy(firestore.DocumentID, firestore.Asc)
+	if cursor != nil {
+		q = q.StartAfter(cursor.CreatedAt, cursor.ID)
+	}
+	view, err := s.viewOf(ctx, q, limit+1)
+	if err != nil {
+		return ListView{}, "", err
+	}
+	var more bool
+	view.Items, more = pageOf(view.Items, limit)
+	next := ""
+	if more {
+		last := view.Items[len(view.Items)-1]
+		next = encodePageCursor(PageCursor{CreatedAt: last.CreatedAt, ID: last.ID})
+	}
+	return view, next, nil
+}
+
+// pageOf puts items, read one past the page size, back in the order they were
+// added and trims them to limit, reporting whether any were cut.
+func pageOf(items []Item, limit int) ([]Item, bool) {
+	slices.SortStableFunc(items, compareCreated)
+	if len(items) <= limit {
+		return items, false
+	}
+	return items[:limit], true
+}
@@ -0,0 +1,38 @@
+package shoppinglist
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPageOfOrdersByCreationAndTrims(t *testing.T) {
+	base := time.Date(2025, 8, 1, 9, 0, 0, 0, time.UTC)
+	items := []Item{
+		{ID: "b", CreatedAt: base},
+		{ID: "c", CreatedAt: base.Add(time.Minute), Position: 1},
+		{ID: "a", CreatedAt: base},
+	}
+
+	page, more := pageOf(items, 2)
+	if !more || len(page) != 2 || page[0].ID != "a" || page[1].ID != "b" {
+		t.Fatalf("unexpected page %+v (more %v)", page, more)
+	}
+	if page, more := pageOf(page, 2); more || len(page) != 2 {
+		t.Fatalf("expected a full last page without more, got %+v (more %v)", page, more)
+	}
+}
+
+func TestPageCursorRoundTrip(t *testing.T) {
+	at := time.Date(2025, 8, 1, 9, 0, 0, 0, time.UTC)
+	c, err := DecodePageCursor(encodePageCursor(PageCursor{CreatedAt: at, ID: "milk"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !c.CreatedAt.Equal(at) || c.ID != "milk" {
+		t.Fatalf("unexpected cursor %+v", c)
+	}
+	summary := encodeCursor(summaryCursor{ReadTime: at, Offset: 10, Size: 10})
+	if _, err := DecodePageCursor(summary); err == nil {
+		t.Fatal("expected a summary cursor to be refused")
+	}
+}
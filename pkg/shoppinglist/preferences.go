@@ -0,0 +1,86 @@
+package shoppinglist
+
+import (
+	"sort"
+	"strings"
+)
+
+// -----------------------------------------------------------------------------
+// Session preferences
+// -----------------------------------------------------------------------------
+
+// SessionPreferences are per-session defaults applied to tool responses.
+type SessionPreferences struct {
+	SortBy         string `json:"sort_by,omitempty"`
+	Direction      string `json:"direction,omitempty"`
+	Verbosity      string `json:"verbosity,omitempty"`
+	IncludeChecked *bool  `json:"include_checked,omitempty"`
+	Locale         string `json:"locale,omitempty"`
+	Member         string `json:"member,omitempty"`
+	Display        bool   `json:"display,omitempty"`
+}
+
+// PreferencesResponse wraps the preferences applied to a session.
+type PreferencesResponse struct {
+	Preferences SessionPreferences `json:"preferences"`
+	ResponseWarnings
+}
+
+// ApplyPreferences sorts and trims items according to prefs.
+func ApplyPreferences(items []Item, prefs SessionPreferences) []Item {
+	out := SortItems(items, prefs.SortBy, prefs.Direction)
+
+	if prefs.Verbosity == "compact" {
+		for i, it := range out {
+			out[i] = Item{ID: it.ID, Name: it.Name, Quantity: it.Quantity, ParentID: it.ParentID, Purchased: it.Purchased}
+		}
+	}
+	return out
+}
+
+// SortItems returns a copy of items sorted by the field by (name,
+// created_at, priority, or category) in direction asc or desc; any other
+// field leaves them in list order. Names compare ignoring case, priorities
+// ascending put the most urgent first, and uncategorized items come last
+// either way.
+func SortItems(items []Item, by, direction string) []Item {
+	out := append([]Item(nil), items...)
+
+	desc := direction == "desc"
+	switch by {
+	case "name":
+		sort.SliceStable(out, func(i, j int) bool {
+			a, b := strings.ToLower(out[i].Name), strings.ToLower(out[j].Name)
+			if desc {
+				return a > b
+			}
+			return a < b
+		})
+	case "created_at":
+		sort.SliceStable(out, func(i, j int) bool {
+			if desc {
+				return out[i].CreatedAt.After(out[j].CreatedAt)
+			}
+			return out[i].CreatedAt.Before(out[j].CreatedAt)
+		})
+	case "priority":
+		sort.SliceStable(out, func(i, j int) bool {
+			if desc {
+				return comparePriority(out[i], out[j]) > 0
+			}
+			return comparePriority(out[i], out[j]) < 0
+		})
+	case "category":
+		sort.SliceStable(out, func(i, j int) bool {
+			a, b := categoryOf(out[i]), categoryOf(out[j])
+			if (a == uncategorized) != (b == uncategorized) {
+				return b == uncategorized
+			}
+			if desc {
+				return a > b
+			}
+			return a < b
+		})
+	}
+	return out
+}
@@ -0,0 +1,48 @@
+package shoppinglist
+
+import (
+	"testing"
+	"time"
+)
+
+func TestApplyPreferencesSortsByName(t *testing.T) {
+	items := []Item{{ID: "1", Name: "milk"}, {ID: "2", Name: "Apples"}, {ID: "3", Name: "bread"}}
+
+	got := ApplyPreferences(items, SessionPreferences{SortBy: "name"})
+	if got[0].Name != "Apples" || got[1].Name != "bread" || got[2].Name != "milk" {
+		t.Fatalf("unexpected order: %v", got)
+	}
+	if items[0].Name != "milk" {
+		t.Fatal("expected input slice to be left untouched")
+	}
+}
+
+func TestApplyPreferencesSortsByCreatedAtDescending(t *testing.T) {
+	base := time.Date(2025, 8, 12, 12, 0, 0, 0, time.UTC)
+	items := []Item{{ID: "old", CreatedAt: base}, {ID: "new", CreatedAt: base.Add(time.Hour)}}
+
+	got := ApplyPreferences(items, SessionPreferences{SortBy: "created_at", Direction: "desc"})
+	if got[0].ID != "new" {
+		t.Fatalf("expected newest first, got %q", got[0].ID)
+	}
+}
+
+func TestApplyPreferencesCompactDropsDetail(t *testing.T) {
+	size := "1 kg"
+	items := []Item{{ID: "1", Name: "flour", PackageSize: &size, CreatedAt: time.Now()}}
+
+	got := ApplyPreferences(items, SessionPreferences{Verbosity: "compact"})
+	if got[0].PackageSize != nil || !got[0].CreatedAt.IsZero() {
+		t.Fatalf("expected compact item, got %+v", got[0])
+	}
+}
+
+func TestSortItemsByCategoryPutsUncategorizedLast(t *testing.T) {
+	dairy, bakery := "dairy", "bakery"
+	items := []Item{{ID: "none"}, {ID: "d", Category: &dairy}, {ID: "b", Category: &bakery}}
+
+	got := SortItems(items, "category", "desc")
+	if got[0].ID != "d" || got[1].ID != "b" || got[2].ID != "none" {
+		t.Fatalf("unexpected order: %v", got)
+	}
+}
@@ -0,0 +1,47 @@
+package shoppinglist
+
+import (
+	"fmt"
+	"strings"
+)
+
+// -----------------------------------------------------------------------------
+// Priority
+// -----------------------------------------------------------------------------
+
+// Priority levels. Items without a priority are normal.
+const (
+	PriorityLow    = "low"
+	PriorityNormal = "normal"
+	PriorityHigh   = "high"
+)
+
+// priorityRank orders priorities, most urgent first.
+var priorityRank = map[string]int{PriorityHigh: 0, PriorityNormal: 1, PriorityLow: 2}
+
+// ParsePriority validates a priority. Normal and the empty string are returned
+// as "", since items without a priority are normal.
+func ParsePriority(s string) (string, error) {
+	p := strings.ToLower(strings.TrimSpace(s))
+	if p == "" || p == PriorityNormal {
+		return "", nil
+	}
+	if _, ok := priorityRank[p]; !ok {
+		return "", fmt.Errorf("invalid priority %q (expected low, normal, or high)", s)
+	}
+	return p, nil
+}
+
+// priorityOf returns the item's priority, normal when unset.
+func priorityOf(it Item) string {
+	if it.Priority == nil || *it.Priority == "" {
+		return PriorityNormal
+	}
+	return *it.Priority
+}
+
+// comparePriority orders a before b when it is more urgent, returning a
+// negative, zero, or positive number like strings.Compare.
+func comparePriority(a, b Item) int {
+	return priorityRank[priorityOf(a)] - priorityRank[priorityOf(b)]
+}
@@ -0,0 +1,57 @@
+package shoppinglist
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParsePriority(t *testing.T) {
+	for in, want := range map[string]string{"High": "high", " low ": "low", "normal": "", "": ""} {
+		if got, err := ParsePriority(in); err != nil || got != want {
+			t.Errorf("parsePriority(%q) = %q, %v, want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParsePriority("urgent"); err == nil {
+		t.Fatal("expected an error for an unknown priority")
+	}
+}
+
+func TestApplyPreferencesSortsByPriority(t *testing.T) {
+	high, low := PriorityHigh, PriorityLow
+	items := []Item{
+		{ID: "1", Name: "napkins", Priority: &low},
+		{ID: "2", Name: "bread"},
+		{ID: "3", Name: "milk", Priority: &high},
+		{ID: "4", Name: "eggs"},
+	}
+	got := ApplyPreferences(items, SessionPreferences{SortBy: "priority"})
+	if ids := itemIDs(got); ids != "3,2,4,1" {
+		t.Fatalf("expected high, normal (in list order), then low; got %s", ids)
+	}
+	got = ApplyPreferences(items, SessionPreferences{SortBy: "priority", Direction: "desc"})
+	if ids := itemIDs(got); ids != "1,2,4,3" {
+		t.Fatalf("expected low first when descending; got %s", ids)
+	}
+}
+
+func TestSummaryOrderPutsUrgentItemsFirst(t *testing.T) {
+	high := PriorityHigh
+	base := time.Date(2025, 8, 1, 9, 0, 0, 0, time.UTC)
+	items := []Item{
+		{ID: "old-high", CreatedAt: base, Priority: &high},
+		{ID: "new", CreatedAt: base.Add(time.Hour)},
+		{ID: "done-high", CreatedAt: base.Add(2 * time.Hour), Priority: &high, Purchased: true},
+	}
+	if ids := itemIDs(summaryOrder(items)); ids != "old-high,new,done-high" {
+		t.Fatalf("unexpected order %s", ids)
+	}
+}
+
+func itemIDs(items []Item) string {
+	ids := make([]string, len(items))
+	for i, it := range items {
+		ids[i] = it.ID
+	}
+	return strings.Join(ids, ",")
+}
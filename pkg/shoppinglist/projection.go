@@ -0,0 +1,166 @@
+package shoppinglist
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/firestore"
+)
+
+// -----------------------------------------------------------------------------
+// Projections
+// -----------------------------------------------------------------------------
+
+// ProjectableFields are the item fields a projection may ask for. Each is
+// stored under the same name it is returned as.
+var ProjectableFields = []string{
+	"id", "name", "quantity", "amount", "unit", "created_at", "updated_at",
+	"package_size", "package_options", "price", "price_currency", "attachments",
+	"parent_id", "category", "needed_by", "tags", "priority", "staple",
+	"purchased", "purchased_at", "reservations", "position", "revision",
+}
+
+// projectionOrderFields are read with every projection so the items can be
+// put in list order and paged, and those in the trash left out.
+var projectionOrderFields = []string{"id", "position", "created_at", "deleted_at"}
+
+// ProjectedItemsResponse is the list with only the requested fields of each
+// item.
+type ProjectedItemsResponse struct {
+	Fields      []string         `json:"fields"`
+	Items       []map[string]any `json:"items"`
+	ReadTime    *time.Time       `json:"read_time,omitempty"`
+	FrozenUntil *time.Time       `json:"frozen_until,omitempty"`
+	NextCursor  string           `json:"next_cursor,omitempty"`
+	ResponseWarnings
+}
+
+// ParseProjection checks fields against ProjectableFields and returns them
+// without duplicates, with id first so items can be told apart.
+func ParseProjection(fields []string) ([]string, error) {
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("'fields' must name at least one field")
+	}
+	out := []string{"id"}
+	for _, f := range fields {
+		f = strings.TrimSpace(f)
+		if !slices.Contains(ProjectableFields, f) {
+			return nil, fmt.Errorf("unknown field %q (expected %s)", f, strings.Join(ProjectableFields, ", "))
+		}
+		if !slices.Contains(out, f) {
+			out = append(out, f)
+		}
+	}
+	return out, nil
+}
+
+// selectPaths are the document fields read for a projection of fields.
+// Amounts of items stored before structured quantities are parsed from
+// their quantity, so it is read along with them.
+func selectPaths(fields []string) []string {
+	paths := slices.Clone(projectionOrderFields)
+	for _, f := range fields {
+		if (f == "amount" || f == "unit") && !slices.Contains(paths, "quantity") {
+			paths = append(paths, "quantity", "quantity_format")
+		}
+		if !slices.Contains(paths, f) {
+			paths = append(paths, f)
+		}
+	}
+	return paths
+}
+
+// ViewFields reads the list like View, or a page of it like ViewPage when
+// limit is above zero or cursor is set, fetching only the given fields of
+// each item with a Firestore Select so the rest are neither read nor sent.
+// Items are not migrated to structured quantities, as only part of each is
+// read.
+func (s *Service) ViewFields(ctx context.Context, fields []string, limit int, cursor *PageCursor) (ListView, string, error) {
+	paged := limit > 0 || cursor != nil
+	q := s.client.Collection(s.collection).Select(selectPaths(fields)...)
+	if paged {
+		if limit < 1 || limit > MaxPageSize {
+			return ListView{}, "", fmt.Errorf("page size must be between 1 and %d", MaxPageSize)
+		}
+		q = q.OrderBy("created_at", firestore.Asc).OrderBy(firestore.DocumentID, firestore.Asc)
+		if cursor != nil {
+			q = q.StartAfter(cursor.CreatedAt, cursor.ID)
+		}
+	}
+
+	var view ListView
+	err := s.readConsistent(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		var (
+			docs []*firestore.DocumentSnapshot
+			err  error
+		)
+		if paged {
+			docs, err = liveDocuments(ctx, tx, q, limit+1)
+		} else {
+			docs, err = tx.Documents(q).GetAll()
+		}
+		if err != nil {
+			return fmt.Errorf("retrieve items: %w", err)
+		}
+		freeze, err := decodeFreeze(tx.Get(s.metaCollection().Doc(freezeDocID)))
+		if err != nil {
+			return err
+		}
+		view = ListView{Items: decodeItems(docs), Freeze: freeze, ReadTime: s.readTimeOf(docs)}
+		return nil
+	})
+	if err != nil {
+		return ListView{}, "", err
+	}
+	if !view.Freeze.Active(view.ReadTime) {
+		view.Freeze = nil
+	}
+	if slices.Contains(fields, "amount") || slices.Contains(fields, "unit") {
+		for i := range view.Items {
+			if view.Items[i].QuantityFormat < quantityFormatStructured && view.Items[i].Quantity != nil {
+				withAmount(&view.Items[i], s.profile)
+			}
+		}
+	}
+
+	if !paged {
+		sortItems(view.Items)
+		return view, "", nil
+	}
+	var more bool
+	view.Items, more = pageOf(view.Items, limit)
+	next := ""
+	if more {
+		last := view.Items[len(view.Items)-1]
+		next = encodePageCursor(PageCursor{CreatedAt: last.CreatedAt, ID: last.ID})
+	}
+	return view, next, nil
+}
+
+// ProjectItems keeps only fields of each item, leaving out those it has no
+// value for.
+func ProjectItems(items []Item, fields []string) ([]map[string]any, error) {
+	out := make([]map[string]any, 0, len(items))
+	for _, it := range items {
+		b, err := json.Marshal(it)
+		if err != nil {
+			return nil, err
+		}
+		var all map[string]any
+		if err := json.Unmarshal(b, &all); err != nil {
+			return nil, err
+		}
+		m := make(map[string]any, len(fields))
+		for _, f := range fields {
+			if v, ok := all[f]; ok {
+				m[f] = v
+			}
+		}
+		out = append(out, m)
+	}
+	return out, nil
+}
@@ -0,0 +1,42 @@
+package shoppinglist
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseProjection(t *testing.T) {
+	fields, err := ParseProjection([]string{"name", "quantity", "name"})
+	if err != nil {
+		t.Fatalf("ParseProjection returned error: %v", err)
+	}
+	if want := []string{"id", "name", "quantity"}; !reflect.DeepEqual(fields, want) {
+		t.Fatalf("got %v, want %v", fields, want)
+	}
+	for _, bad := range [][]string{nil, {"name", "quantity_format"}, {"expire_at"}} {
+		if _, err := ParseProjection(bad); err == nil {
+			t.Fatalf("expected %v to be refused", bad)
+		}
+	}
+}
+
+func TestSelectPathsReadsQuantityForAmounts(t *testing.T) {
+	got := selectPaths([]string{"id", "amount"})
+	want := []string{"id", "position", "created_at", "deleted_at", "quantity", "quantity_format", "amount"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestProjectItems(t *testing.T) {
+	q := "2 L"
+	items := []Item{{ID: "milk", Name: "milk", Quantity: &q, Revision: 3}, {ID: "eggs", Name: "eggs"}}
+	got, err := ProjectItems(items, []string{"id", "name", "quantity"})
+	if err != nil {
+		t.Fatalf("ProjectItems returned error: %v", err)
+	}
+	want := []map[string]any{{"id": "milk", "name": "milk", "quantity": "2 L"}, {"id": "eggs", "name": "eggs"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
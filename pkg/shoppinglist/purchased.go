@@ -0,0 +1,215 @@
+package shoppinglist
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/firestore"
+)
+
+// -----------------------------------------------------------------------------
+// Checking items off
+// -----------------------------------------------------------------------------
+
+// MarkPurchased sets whether an item has been bought, or flips it when
+// purchased is nil, and returns the updated item. On a count-mode list, count
+// is how many were bought (or put back when unchecking): the quantity goes
+// down by it and the item is checked off once none remain. While a shopping
+// session is active the check is recorded against it.
+func (s *Service) MarkPurchased(ctx context.Context, id string, purchased *bool, count *float64) (*Item, error) {
+	ref := s.client.Collection(s.collection).Doc(id)
+	sessionRef := s.metaCollection().Doc(shoppingSessionDocID)
+	var (
+		it, before Item
+		commit     firestore.CommitResponse
+	)
+	err := s.runTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		settings, err := decodeSettings(tx.Get(s.metaCollection().Doc(settingsDocID)))
+		if err != nil {
+			return err
+		}
+		session, err := decodeShoppingSession(tx.Get(sessionRef))
+		if err != nil {
+			return err
+		}
+		doc, err := untrashed(tx.Get(ref))
+		if err != nil {
+			return err
+		}
+		if err := doc.DataTo(&it); err != nil {
+			return err
+		}
+		before = Item{}
+		if err := doc.DataTo(&before); err != nil {
+			return err
+		}
+		now := s.Now()
+		updates, err := markItem(&it, purchased, count, settings.QuantityMode, now, s.profile)
+		if err != nil {
+			return err
+		}
+		if err := tx.Update(ref, withRevision(updates, s.writeTime())); err != nil {
+			return err
+		}
+		if !session.Active(now) {
+			return nil
+		}
+		return tx.Update(sessionRef, []firestore.Update{sessionCheckUpdate(it, now)})
+	}, firestore.WithCommitResponseTo(&commit))
+	if err != nil {
+		return nil, fmt.Errorf("mark purchased: %w", err)
+	}
+	s.stamped(&it, commit.CommitTime())
+	action := ActionUnchecked
+	switch {
+	case it.Purchased:
+		action = ActionChecked
+	case count != nil && (purchased == nil || *purchased):
+		action = ActionUpdated
+	}
+	s.recordActivity(ctx, action, it)
+	s.recordAudit(ctx, newItemChange(action, &before, &it))
+	return &it, nil
+}
+
+// markItem applies MarkPurchased to it, as of now on a list in the given
+// quantity mode, and returns the updates that write it.
+func markItem(it *Item, purchased *bool, count *float64, mode string, now time.Time, p HouseholdProfile) ([]firestore.Update, error) {
+	target := !it.Purchased
+	if purchased != nil {
+		target = *purchased
+	}
+
+	var updates []firestore.Update
+	switch {
+	case mode != QuantityModeCount:
+		if count != nil {
+			return nil, fmt.Errorf("'count' needs a list in count mode (see set_quantity_mode)")
+		}
+		it.Purchased = target
+	case target:
+		done, err := countOff(it, count, p)
+		if err != nil {
+			return nil, err
+		}
+		it.Purchased = done
+		updates = append(updates, quantityUpdates(it, p)...)
+	default:
+		if count != nil {
+			if err := countBack(it, *count, p); err != nil {
+				return nil, err
+			}
+			updates = append(updates, quantityUpdates(it, p)...)
+		}
+		it.Purchased = false
+	}
+
+	var at any = firestore.Delete
+	it.PurchasedAt = nil
+	if it.Purchased {
+		it.PurchasedAt, at = &now, now
+	}
+	it.Revision++
+	return append(updates,
+		firestore.Update{Path: "purchased", Value: it.Purchased},
+		firestore.Update{Path: "purchased_at", Value: at},
+	), nil
+}
+
+// uncheckUpdates put a checked-off item back on the list.
+func uncheckUpdates() []firestore.Update {
+	return []firestore.Update{{Path: "purchased", Value: false}, {Path: "purchased_at", Value: firestore.Delete}}
+}
+
+// ToggleResponse reports an item's purchased state after toggling it.
+type ToggleResponse struct {
+	Purchased bool            `json:"purchased"`
+	Item      *Item           `json:"item"`
+	Shopping  *ShoppingTotals `json:"shopping,omitempty"`
+	ResponseWarnings
+}
+
+// TogglePurchased flips whether an item has been bought, in a transaction,
+// and returns its new state. On a count-mode list checking it off takes all
+// that remain.
+func (s *Service) TogglePurchased(ctx context.Context, id string) (ToggleResponse, error) {
+	it, err := s.MarkPurchased(ctx, id, nil, nil)
+	if err != nil {
+		return ToggleResponse{}, err
+	}
+	return ToggleResponse{Purchased: it.Purchased, Item: it}, nil
+}
+
+// ClearPurchasedResponse reports what clearing purchased items removed.
+type ClearPurchasedResponse struct {
+	Removed []string `json:"removed"`
+	TripID  string   `json:"trip_id,omitempty"`
+	ResponseWarnings
+}
+
+// planClearPurchased works out which of items clearing purchased items
+// deletes and which unpurchased children it promotes to top-level items.
+func planClearPurchased(items []Item) (removed []Item, promote []string) {
+	var ids []string
+	for _, it := range items {
+		if it.Purchased {
+			ids = append(ids, it.ID)
+		}
+	}
+	_, removed, promote = planBulkRemove(items, ids, false)
+	return removed, promote
+}
+
+// ClearPurchased deletes every purchased item, first archiving them as a trip
+// when archive is set. Unpurchased children of a cleared item become
+// top-level items. It is one transaction unless the writes need more than one
+// commit; then the first commits with the read and the rest follow, and when
+// one of those fails calling again clears what is left.
+func (s *Service) ClearPurchased(ctx context.Context, archive bool) (ClearPurchasedResponse, error) {
+	col := s.client.Collection(s.collection)
+	now := s.Now()
+	var resp ClearPurchasedResponse
+	var removed []Item
+	var rest *writeBatch
+	err := s.runTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		rest = nil
+		resp = ClearPurchasedResponse{Removed: []string{}}
+		docs, err := tx.Documents(col).GetAll()
+		if err != nil {
+			return err
+		}
+		var promote []string
+		removed, promote = planClearPurchased(decodeItems(docs))
+		if len(removed) == 0 {
+			return nil
+		}
+		b := s.newBatch()
+		if archive {
+			resp.TripID = s.NewID()
+			trip := TripArchive{ID: resp.TripID, ArchivedAt: now, Items: removed, ExpireAt: s.retention.expireAt("trips", now)}
+			b.Create(s.client.Collection(s.collection+"_trips").Doc(trip.ID), trip)
+		}
+		for _, id := range promote {
+			b.Update(col.Doc(id), withRevision([]firestore.Update{{Path: "parent_id", Value: firestore.Delete}}, s.writeTime()))
+		}
+		for _, it := range removed {
+			b.DeleteItem(col.Doc(it.ID))
+			resp.Removed = append(resp.Removed, it.Name)
+		}
+		rest, err = b.Apply(tx)
+		return err
+	})
+	if err == nil {
+		err = rest.Commit(ctx)
+	}
+	if err != nil {
+		return ClearPurchasedResponse{}, fmt.Errorf("clear purchased items: %w", err)
+	}
+	if len(removed) > 0 {
+		s.observe(ctx, activityDelete, len(removed))
+		s.recordActivity(ctx, ActionRemoved, removed...)
+		s.recordAudit(ctx, itemChanges(ActionRemoved, removed)...)
+	}
+	return resp, nil
+}
@@ -0,0 +1,63 @@
+package shoppinglist
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMarkItem(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	it := Item{ID: "a", Name: "Milk", Revision: 1}
+
+	// Without purchased the status is toggled.
+	if _, err := markItem(&it, nil, nil, QuantityModeAmount, now, HouseholdProfile{}); err != nil {
+		t.Fatal(err)
+	}
+	if !it.Purchased || it.PurchasedAt == nil || !it.PurchasedAt.Equal(now) || it.Revision != 2 {
+		t.Fatalf("expected the item checked off now, got %+v", it)
+	}
+	yes := true
+	if _, err := markItem(&it, &yes, nil, QuantityModeAmount, now, HouseholdProfile{}); err != nil || !it.Purchased {
+		t.Fatalf("expected checking off a checked item to keep it checked, got %+v, %v", it, err)
+	}
+	if _, err := markItem(&it, nil, nil, QuantityModeAmount, now, HouseholdProfile{}); err != nil || it.Purchased || it.PurchasedAt != nil {
+		t.Fatalf("expected the item back on the list, got %+v, %v", it, err)
+	}
+
+	one := 1.0
+	if _, err := markItem(&it, nil, &one, QuantityModeAmount, now, HouseholdProfile{}); err == nil {
+		t.Fatal("expected a count to be refused outside count mode")
+	}
+
+	q := "3"
+	counted := Item{ID: "b", Name: "Eggs", Quantity: &q}
+	if _, err := markItem(&counted, nil, &one, QuantityModeCount, now, HouseholdProfile{}); err != nil {
+		t.Fatal(err)
+	}
+	if counted.Purchased || *counted.Quantity != "2" {
+		t.Fatalf("expected one of three bought and the item still on the list, got %+v", counted)
+	}
+	if _, err := markItem(&counted, &yes, nil, QuantityModeCount, now, HouseholdProfile{}); err != nil || !counted.Purchased || *counted.Quantity != "0" {
+		t.Fatalf("expected the rest bought and the item checked off, got %+v, %v", counted, err)
+	}
+}
+
+func TestPlanClearPurchased(t *testing.T) {
+	parent := "a"
+	items := []Item{
+		{ID: "a", Name: "Taco night", Purchased: true},
+		{ID: "b", Name: "Shells", ParentID: &parent},
+		{ID: "c", Name: "Salsa", ParentID: &parent, Purchased: true},
+		{ID: "d", Name: "Milk"},
+	}
+	removed, promote := planClearPurchased(items)
+	if len(removed) != 2 || removed[0].ID != "a" || removed[1].ID != "c" {
+		t.Fatalf("expected only the purchased items removed, got %+v", removed)
+	}
+	if len(promote) != 1 || promote[0] != "b" {
+		t.Fatalf("expected the unpurchased child promoted, got %v", promote)
+	}
+	if removed, _ := planClearPurchased(items[3:]); len(removed) != 0 {
+		t.Fatalf("expected nothing to clear, got %+v", removed)
+	}
+}
@@ -0,0 +1,105 @@
+package shoppinglist
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"cloud.google.com/go/firestore"
+)
+
+// -----------------------------------------------------------------------------
+// Structured quantities
+// -----------------------------------------------------------------------------
+
+// structuredAmount reads a quantity such as "500 g" or "1,5 kg" into the
+// amount and lower-cased unit stored beside it. Empty and free-form
+// quantities, like "a handful", have no amount.
+func structuredAmount(q string, p HouseholdProfile) (*float64, string) {
+	v, unit, ok := parseAmount(q, p)
+	if !ok || (v == 0 && unit == "") {
+		return nil, ""
+	}
+	return &v, unit
+}
+
+// withAmount fills in the item's amount and unit from its quantity.
+func withAmount(it *Item, p HouseholdProfile) {
+	it.Amount, it.Unit = structuredAmount(deref(it.Quantity), p)
+	it.QuantityFormat = quantityFormatStructured
+}
+
+// amountUpdates keeps the stored amount and unit in step with a new quantity,
+// deleting them when the quantity has no amount.
+func amountUpdates(q string, p HouseholdProfile) []firestore.Update {
+	var amount, unit any = firestore.Delete, firestore.Delete
+	if v, u := structuredAmount(q, p); v != nil {
+		amount = *v
+		if u != "" {
+			unit = u
+		}
+	}
+	return []firestore.Update{{Path: "amount", Value: amount}, {Path: "unit", Value: unit}, {Path: "quantity_format", Value: quantityFormatStructured}}
+}
+
+// QuantityFromAmount renders an upsert's amount and unit as the quantity
+// string, which stays the item's primary form.
+func QuantityFromAmount(amount float64, unit string, p HouseholdProfile) (string, error) {
+	if amount < 0 {
+		return "", fmt.Errorf("'amount' must not be negative")
+	}
+	return formatAmount(amount, strings.TrimSpace(unit), p), nil
+}
+
+// quantityUpdates writes the item's quantity along with its amount and unit,
+// which it refreshes on the item too.
+func quantityUpdates(it *Item, p HouseholdProfile) []firestore.Update {
+	withAmount(it, p)
+	return append(amountUpdates(deref(it.Quantity), p), firestore.Update{Path: "quantity", Value: deref(it.Quantity)})
+}
+
+// adjustAmount adds delta to the item's quantity, keeping the rest of the
+// quantity as written. An item without a quantity starts from zero.
+func adjustAmount(it *Item, delta float64, p HouseholdProfile) error {
+	have, rest, ok := splitCount(deref(it.Quantity), p)
+	if !ok {
+		return fmt.Errorf("quantity %q of %q has no numeric amount to adjust", deref(it.Quantity), it.Name)
+	}
+	if have+delta < 0 {
+		return fmt.Errorf("cannot take %s from %q with quantity %q", p.FormatNumber(-delta), it.Name, deref(it.Quantity))
+	}
+	q := formatAmount(have+delta, rest, p)
+	it.Quantity = &q
+	return nil
+}
+
+// AdjustQuantity atomically adds delta, which may be negative, to an item's
+// quantity and returns the updated item.
+func (s *Service) AdjustQuantity(ctx context.Context, id string, delta float64) (*Item, error) {
+	ref := s.client.Collection(s.collection).Doc(id)
+	var it, before Item
+	err := s.runTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := untrashed(tx.Get(ref))
+		if err != nil {
+			return err
+		}
+		it, before = Item{}, Item{}
+		if err := doc.DataTo(&it); err != nil {
+			return err
+		}
+		if err := doc.DataTo(&before); err != nil {
+			return err
+		}
+		if err := adjustAmount(&it, delta, s.profile); err != nil {
+			return err
+		}
+		it.Revision++
+		return tx.Update(ref, withRevision(quantityUpdates(&it, s.profile), s.writeTime()))
+	})
+	if err != nil {
+		return nil, fmt.Errorf("adjust quantity: %w", err)
+	}
+	s.recordActivity(ctx, ActionUpdated, it)
+	s.recordAudit(ctx, newItemChange(ActionUpdated, &before, &it))
+	return &it, nil
+}
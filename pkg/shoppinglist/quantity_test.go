@@ -0,0 +1,96 @@
+package shoppinglist
+
+import (
+	"testing"
+
+	"cloud.google.com/go/firestore"
+)
+
+func TestStructuredAmount(t *testing.T) {
+	metric := HouseholdProfile{Locale: "de-DE"}
+	tests := []struct {
+		q      string
+		p      HouseholdProfile
+		amount float64
+		unit   string
+		ok     bool
+	}{
+		{q: "500 g", amount: 500, unit: "g", ok: true},
+		{q: "2", amount: 2, ok: true},
+		{q: "6 AA batteries", amount: 6, unit: "aa batteries", ok: true},
+		{q: "1,5 kg", p: metric, amount: 1.5, unit: "kg", ok: true},
+		{q: "a handful"},
+		{q: ""},
+	}
+	for _, tt := range tests {
+		amount, unit := structuredAmount(tt.q, tt.p)
+		if (amount != nil) != tt.ok {
+			t.Errorf("structuredAmount(%q) amount = %v, want ok %v", tt.q, amount, tt.ok)
+			continue
+		}
+		if amount != nil && (*amount != tt.amount || unit != tt.unit) {
+			t.Errorf("structuredAmount(%q) = %v %q, want %v %q", tt.q, *amount, unit, tt.amount, tt.unit)
+		}
+	}
+}
+
+func TestQuantityUpdates(t *testing.T) {
+	q := "a handful"
+	it := Item{Quantity: &q}
+	for _, u := range quantityUpdates(&it, HouseholdProfile{}) {
+		if u.Path != "quantity" && u.Path != "quantity_format" && u.Value != firestore.Delete {
+			t.Errorf("free-form quantity writes %s = %v, want delete", u.Path, u.Value)
+		}
+	}
+	q = "3 l"
+	updates := quantityUpdates(&it, HouseholdProfile{})
+	if it.Amount == nil || *it.Amount != 3 || it.Unit != "l" {
+		t.Fatalf("item amount = %v %q, want 3 l", it.Amount, it.Unit)
+	}
+	got := map[string]any{}
+	for _, u := range updates {
+		got[u.Path] = u.Value
+	}
+	if got["quantity"] != "3 l" || got["amount"] != 3.0 || got["unit"] != "l" || got["quantity_format"] != quantityFormatStructured {
+		t.Errorf("updates = %v", got)
+	}
+}
+
+func TestQuantityFromAmount(t *testing.T) {
+	if q, err := QuantityFromAmount(2.5, " kg ", HouseholdProfile{}); err != nil || q != "2.5 kg" {
+		t.Errorf("quantityFromAmount = %q, %v, want 2.5 kg", q, err)
+	}
+	if _, err := QuantityFromAmount(-1, "", HouseholdProfile{}); err == nil {
+		t.Error("negative amount accepted")
+	}
+}
+
+func TestAdjustAmount(t *testing.T) {
+	tests := []struct {
+		q     string
+		delta float64
+		want  string
+		err   bool
+	}{
+		{q: "4 apples", delta: 2, want: "6 apples"},
+		{q: "500 g", delta: -1, want: "499 g"},
+		{q: "6 AA batteries", delta: -6, want: "0 AA batteries"},
+		{q: "", delta: 3, want: "3"},
+		{q: "1", delta: -2, err: true},
+		{q: "a handful", delta: 1, err: true},
+	}
+	for _, tt := range tests {
+		q := tt.q
+		it := Item{Name: "x", Quantity: &q}
+		err := adjustAmount(&it, tt.delta, HouseholdProfile{})
+		if tt.err {
+			if err == nil {
+				t.Errorf("adjustAmount(%q, %v) = %q, want error", tt.q, tt.delta, *it.Quantity)
+			}
+			continue
+		}
+		if err != nil || *it.Quantity != tt.want {
+			t.Errorf("adjustAmount(%q, %v) = %q, %v, want %q", tt.q, tt.delta, *it.Quantity, err, tt.want)
+		}
+	}
+}
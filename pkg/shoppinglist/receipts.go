@@ -0,0 +1,146 @@
+package shoppinglist
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/firestore"
+)
+
+// -----------------------------------------------------------------------------
+// Receipt reconciliation
+// -----------------------------------------------------------------------------
+
+// ReceiptLine is one purchased line from a receipt.
+type ReceiptLine struct {
+	Name     string  `json:"name"`
+	Price    float64 `json:"price"`
+	Quantity string  `json:"quantity,omitempty"`
+}
+
+// ReceiptMatch pairs a receipt line with the list item it was bought for.
+type ReceiptMatch struct {
+	Line     ReceiptLine `json:"line"`
+	ItemID   string      `json:"item_id"`
+	ItemName string      `json:"item_name"`
+	Score    float64     `json:"score"`
+}
+
+// PurchaseRecord is an entry in the purchase history.
+type PurchaseRecord struct {
+	ID          string    `json:"id" firestore:"id"`
+	ReceiptID   string    `json:"receipt_id,omitempty" firestore:"receipt_id,omitempty"`
+	SessionID   string    `json:"session_id,omitempty" firestore:"session_id,omitempty"`
+	ItemID      string    `json:"item_id,omitempty" firestore:"item_id,omitempty"`
+	Name        string    `json:"name" firestore:"name"`
+	Quantity    string    `json:"quantity,omitempty" firestore:"quantity,omitempty"`
+	Price       float64   `json:"price" firestore:"price"`
+	Currency    string    `json:"currency" firestore:"currency"`
+	Store       string    `json:"store,omitempty" firestore:"store,omitempty"`
+	PurchasedAt time.Time `json:"purchased_at" firestore:"purchased_at"`
+
+	ExpireAt *time.Time `json:"-" firestore:"expire_at,omitempty"`
+}
+
+// ReconcileResponse reports how a receipt matched the list.
+type ReconcileResponse struct {
+	ReceiptID string         `json:"receipt_id"`
+	Matched   []ReceiptMatch `json:"matched"`
+	Unmatched []ReceiptLine  `json:"unmatched,omitempty"`
+	Remaining []Item         `json:"remaining"`
+	ResponseWarnings
+}
+
+// MatchReceipt assigns each receipt line to at most one unpurchased item:
+// first by normalized name, then by embedding similarity above threshold.
+func MatchReceipt(ctx context.Context, normalizer *NameNormalizer, embedder Embedder, lines []ReceiptLine, items []Item, threshold float64) ([]ReceiptMatch, []ReceiptLine, error) {
+	open := map[string]Item{}
+	byKey := map[string]string{}
+	for _, it := range items {
+		if it.Purchased {
+			continue
+		}
+		open[it.ID] = it
+		if _, taken := byKey[normalizer.Key(it.Name)]; !taken {
+			byKey[normalizer.Key(it.Name)] = it.ID
+		}
+	}
+
+	var matches []ReceiptMatch
+	var pending []ReceiptLine
+	for _, line := range lines {
+		if id, ok := byKey[normalizer.Key(line.Name)]; ok {
+			if it, still := open[id]; still {
+				matches = append(matches, ReceiptMatch{Line: line, ItemID: id, ItemName: it.Name, Score: 1})
+				delete(open, id)
+				continue
+			}
+		}
+		pending = append(pending, line)
+	}
+
+	var unmatched []ReceiptLine
+	for _, line := range pending {
+		candidates := make([]Item, 0, len(open))
+		for _, it := range items {
+			if _, ok := open[it.ID]; ok {
+				candidates = append(candidates, it)
+			}
+		}
+		similar, err := FindSimilarItems(ctx, embedder, line.Name, candidates, threshold)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(similar) == 0 {
+			unmatched = append(unmatched, line)
+			continue
+		}
+		best := similar[0]
+		matches = append(matches, ReceiptMatch{Line: line, ItemID: best.Item.ID, ItemName: best.Item.Name, Score: best.Score})
+		delete(open, best.Item.ID)
+	}
+	return matches, unmatched, nil
+}
+
+// purchasesCollection holds the purchase history.
+func (s *Service) purchasesCollection() *firestore.CollectionRef {
+	return s.client.Collection(s.collection + "_purchases")
+}
+
+// RecordPurchases writes a receipt's lines to the purchase history and checks
+// the matched items off, in one transaction.
+func (s *Service) RecordPurchases(ctx context.Context, records []PurchaseRecord) error {
+	col := s.client.Collection(s.collection)
+	err := s.runTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		for _, r := range records {
+			if r.ItemID != "" {
+				if err := tx.Update(col.Doc(r.ItemID), withRevision([]firestore.Update{
+					{Path: "purchased", Value: true},
+					{Path: "purchased_at", Value: r.PurchasedAt},
+				}, s.writeTime())); err != nil {
+					return err
+				}
+			}
+			r.ExpireAt = s.retention.expireAt("purchases", r.PurchasedAt)
+			if err := tx.Create(s.purchasesCollection().Doc(r.ID), r); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("record purchases: %w", err)
+	}
+	for _, r := range records {
+		if r.ItemID != "" {
+			s.recordActivity(ctx, ActionChecked, Item{ID: r.ItemID, Name: r.Name})
+			// Receipts are only matched to items still to buy.
+			s.recordAudit(ctx, ItemChange{ID: r.ItemID, Name: r.Name, Action: ActionChecked, Fields: []FieldChange{
+				{Field: "purchased", Old: false, New: true},
+				{Field: "purchased_at", New: r.PurchasedAt},
+			}})
+		}
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package shoppinglist
+
+import (
+	"context"
+	"testing"
+)
+
+func TestMatchReceiptPrefersExactNamesAndSkipsPurchased(t *testing.T) {
+	items := []Item{
+		{ID: "1", Name: "Milk"},
+		{ID: "2", Name: "bread", Purchased: true},
+		{ID: "3", Name: "bread"},
+		{ID: "4", Name: "bananas"},
+	}
+	lines := []ReceiptLine{
+		{Name: "milk ", Price: 1.29},
+		{Name: "Bread", Price: 2.5},
+		{Name: "banana", Price: 0.99},
+		{Name: "batteries", Price: 7},
+	}
+
+	n, _ := ParseNormalizer("", "")
+	matched, unmatched, err := MatchReceipt(context.Background(), n, LocalEmbedder{Dims: 256}, lines, items, 0.7)
+	if err != nil {
+		t.Fatalf("matchReceipt returned error: %v", err)
+	}
+	if len(matched) != 3 {
+		t.Fatalf("expected 3 matches, got %+v", matched)
+	}
+	if matched[0].ItemID != "1" || matched[0].Score != 1 {
+		t.Fatalf("expected exact match to milk, got %+v", matched[0])
+	}
+	if matched[1].ItemID != "3" {
+		t.Fatalf("expected purchased bread to be skipped, got %+v", matched[1])
+	}
+	if matched[2].ItemID != "4" || matched[2].Score >= 1 {
+		t.Fatalf("expected fuzzy match to bananas, got %+v", matched[2])
+	}
+	if len(unmatched) != 1 || unmatched[0].Name != "batteries" {
+		t.Fatalf("expected batteries unmatched, got %+v", unmatched)
+	}
+}
+
+func TestMatchReceiptMatchesEachItemOnce(t *testing.T) {
+	items := []Item{{ID: "1", Name: "eggs"}}
+	lines := []ReceiptLine{{Name: "eggs", Price: 3}, {Name: "eggs", Price: 3}}
+
+	matched, unmatched, err := MatchReceipt(context.Background(), nil, LocalEmbedder{Dims: 256}, lines, items, 0.8)
+	if err != nil {
+		t.Fatalf("matchReceipt returned error: %v", err)
+	}
+	if len(matched) != 1 || len(unmatched) != 1 {
+		t.Fatalf("expected one match and one leftover, got %d and %d", len(matched), len(unmatched))
+	}
+}
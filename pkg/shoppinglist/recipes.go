@@ -0,0 +1,274 @@
+package shoppinglist
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/firestore"
+)
+
+// -----------------------------------------------------------------------------
+// Recipe reservations
+// -----------------------------------------------------------------------------
+
+// RecipeIngredient is one ingredient a recipe contributes to the list.
+type RecipeIngredient struct {
+	Name     string  `json:"name"`
+	Quantity float64 `json:"quantity"`
+	Unit     string  `json:"unit,omitempty"`
+}
+
+// RecipeResponse reports the items a recipe added to or released from.
+type RecipeResponse struct {
+	Recipe  string   `json:"recipe"`
+	Items   []Item   `json:"items"`
+	Removed []string `json:"removed,omitempty"`
+	ResponseWarnings
+}
+
+var amountRe = regexp.MustCompile(`^\s*(\d(?:[\d.,']*\d)?)\s*(.*?)\s*$`)
+
+// parseAmount splits a quantity such as "500 g", "1,5 kg", or "4" into its
+// number, read in the household's locale, and unit. An empty quantity is zero
+// with no unit.
+func parseAmount(q string, p HouseholdProfile) (float64, string, bool) {
+	if strings.TrimSpace(q) == "" {
+		return 0, "", true
+	}
+	m := amountRe.FindStringSubmatch(q)
+	if m == nil {
+		return 0, "", false
+	}
+	v, err := p.ParseNumber(m[1])
+	if err != nil {
+		return 0, "", false
+	}
+	return v, strings.ToLower(m[2]), true
+}
+
+// formatAmount renders an amount and unit back into a quantity string.
+func formatAmount(v float64, unit string, p HouseholdProfile) string {
+	s := p.FormatNumber(v)
+	if unit == "" {
+		return s
+	}
+	return s + " " + unit
+}
+
+// reserve adds a recipe's contribution to the item's quantity, converted to
+// the unit the item is measured in. An item without a quantity takes the
+// household's measurement system. Reserving the same recipe again replaces
+// its earlier contribution.
+func reserve(it *Item, recipe string, amount float64, unit string, p HouseholdProfile) error {
+	have, haveUnit, ok := parseAmount(deref(it.Quantity), p)
+	if !ok {
+		return fmt.Errorf("quantity %q of %q has no numeric amount to add to", deref(it.Quantity), it.Name)
+	}
+	unit = strings.ToLower(strings.TrimSpace(unit))
+	if have == 0 && haveUnit == "" {
+		haveUnit = p.preferredUnit(unit)
+	}
+	converted, ok := convertUnit(amount, unit, haveUnit)
+	if !ok {
+		return fmt.Errorf("%q is measured in %q, not %q", it.Name, haveUnit, unit)
+	}
+
+	have -= it.Reservations[recipe]
+	if it.Reservations == nil {
+		it.Reservations = map[string]float64{}
+	}
+	it.Reservations[recipe] = converted
+	q := formatAmount(have+converted, haveUnit, p)
+	it.Quantity = &q
+	return nil
+}
+
+// release subtracts exactly the recipe's contribution from the item and
+// reports whether nothing remains so the item can be removed.
+func release(it *Item, recipe string, p HouseholdProfile) (empty bool, err error) {
+	amount, ok := it.Reservations[recipe]
+	if !ok {
+		return false, nil
+	}
+	have, unit, ok := parseAmount(deref(it.Quantity), p)
+	if !ok {
+		return false, fmt.Errorf("quantity %q of %q has no numeric amount to subtract from", deref(it.Quantity), it.Name)
+	}
+	delete(it.Reservations, recipe)
+
+	left := have - amount
+	if left <= 1e-9 {
+		left = 0
+	}
+	q := formatAmount(left, unit, p)
+	it.Quantity = &q
+	return left == 0 && len(it.Reservations) == 0, nil
+}
+
+// recipeKey is the reservation key for a recipe reference.
+func recipeKey(recipe string) (string, error) {
+	key := slugify(recipe)
+	if key == "" {
+		return "", errors.New("recipe reference must contain letters or digits")
+	}
+	return key, nil
+}
+
+func reservationUpdates(it *Item, p HouseholdProfile, at time.Time) []firestore.Update {
+	var reservations any = firestore.Delete
+	if len(it.Reservations) > 0 {
+		reservations = it.Reservations
+	}
+	return withRevision(append(quantityUpdates(it, p), firestore.Update{Path: "reservations", Value: reservations}), at)
+}
+
+// AddRecipe merges a recipe's ingredients into items with the same normalized
+// name, creating items that do not exist yet, and records each contribution.
+func (s *Service) AddRecipe(ctx context.Context, recipe string, ingredients []RecipeIngredient) (RecipeResponse, error) {
+	key, err := recipeKey(recipe)
+	if err != nil {
+		return RecipeResponse{}, err
+	}
+	if err := s.checkNotFrozen(ctx); err != nil {
+		return RecipeResponse{}, err
+	}
+	ingredients = slices.Clone(ingredients)
+	for i := range ingredients {
+		if ingredients[i].Name, err = s.filter.Clean("name", ingredients[i].Name); err != nil {
+			return RecipeResponse{}, err
+		}
+	}
+
+	col := s.client.Collection(s.collection)
+	var (
+		resp             RecipeResponse
+		created, updated []Item
+		before           map[string]Item
+		commit           firestore.CommitResponse
+	)
+	err = s.runTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		resp, created, updated = RecipeResponse{Recipe: key, Items: []Item{}}, nil, nil
+		docs, err := tx.Documents(col).GetAll()
+		if err != nil {
+			return err
+		}
+		before = itemsByID(decodeItems(docs))
+		byName := map[string]*Item{}
+		for _, it := range decodeItems(docs) {
+			byName[s.normalizer.Key(it.Name)] = &it
+		}
+		trash := trashedIDs(docs)
+
+		type write struct {
+			item   *Item
+			create bool
+		}
+		var writes []write
+		at := s.writeTime()
+		for _, ing := range ingredients {
+			name, _ := NormalizeItemName(ing.Name)
+			it, exists := byName[s.normalizer.Key(name)]
+			if !exists {
+				it = &Item{ID: s.newItemID(name), Name: name, CreatedAt: at, UpdatedAt: &at, Revision: 1}
+			}
+			if err := reserve(it, key, ing.Quantity, ing.Unit, s.profile); err != nil {
+				resp.Warn(WarnIncomparable, it.ID, "skipped %s: %v", name, err)
+				continue
+			}
+			if !exists {
+				byName[s.normalizer.Key(name)] = it
+			}
+			writes = append(writes, write{item: it, create: !exists})
+		}
+
+		for _, w := range writes {
+			if w.create {
+				withAmount(w.item, s.profile)
+				if err := createItem(tx, col.Doc(w.item.ID), *w.item, trash[w.item.ID]); err != nil {
+					return err
+				}
+				created = append(created, *w.item)
+			} else if err := tx.Update(col.Doc(w.item.ID), reservationUpdates(w.item, s.profile, at)); err != nil {
+				return err
+			} else {
+				updated = append(updated, *w.item)
+			}
+			resp.Items = append(resp.Items, *w.item)
+		}
+		return nil
+	}, firestore.WithCommitResponseTo(&commit))
+	if err != nil {
+		return RecipeResponse{}, fmt.Errorf("add recipe: %w", err)
+	}
+	for i := range resp.Items {
+		s.stamped(&resp.Items[i], commit.CommitTime())
+	}
+	s.observe(ctx, activityAdd, len(created))
+	s.recordActivity(ctx, ActionAdded, created...)
+	s.recordAudit(ctx, itemChanges(ActionAdded, created)...)
+	s.recordActivity(ctx, ActionUpdated, updated...)
+	s.recordAudit(ctx, updateChanges(before, updated)...)
+	return resp, nil
+}
+
+// RemoveRecipe subtracts a recipe's recorded contributions from each item,
+// deleting items that only the recipe had asked for.
+func (s *Service) RemoveRecipe(ctx context.Context, recipe string) (RecipeResponse, error) {
+	key, err := recipeKey(recipe)
+	if err != nil {
+		return RecipeResponse{}, err
+	}
+
+	col := s.client.Collection(s.collection)
+	var (
+		resp    RecipeResponse
+		removed []Item
+		before  map[string]Item
+	)
+	err = s.runTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		resp, removed = RecipeResponse{Recipe: key, Items: []Item{}}, nil
+		docs, err := tx.Documents(col.WherePath(firestore.FieldPath{"reservations", key}, ">", 0)).GetAll()
+		if err != nil {
+			return err
+		}
+		before = itemsByID(decodeItems(docs))
+		for _, d := range docs {
+			var it Item
+			if trashed(d) || d.DataTo(&it) != nil {
+				continue
+			}
+			empty, err := release(&it, key, s.profile)
+			if err != nil {
+				resp.Warn(WarnIncomparable, it.ID, "kept %s: %v", it.Name, err)
+				continue
+			}
+			if empty {
+				if err := s.trashItem(tx, d.Ref); err != nil {
+					return err
+				}
+				resp.Removed = append(resp.Removed, it.ID)
+				removed = append(removed, it)
+				continue
+			}
+			if err := tx.Update(d.Ref, reservationUpdates(&it, s.profile, s.writeTime())); err != nil {
+				return err
+			}
+			resp.Items = append(resp.Items, it)
+		}
+		return nil
+	})
+	if err != nil {
+		return RecipeResponse{}, fmt.Errorf("remove recipe: %w", err)
+	}
+	s.observe(ctx, activityDelete, len(resp.Removed))
+	s.recordActivity(ctx, ActionRemoved, removed...)
+	s.recordAudit(ctx, itemChanges(ActionRemoved, removed)...)
+	s.recordActivity(ctx, ActionUpdated, resp.Items...)
+	s.recordAudit(ctx, updateChanges(before, resp.Items)...)
+	return resp, nil
+}
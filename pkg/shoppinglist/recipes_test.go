@@ -0,0 +1,109 @@
+package shoppinglist
+
+import "testing"
+
+func TestParseAmount(t *testing.T) {
+	cases := []struct {
+		in   string
+		v    float64
+		unit string
+		ok   bool
+	}{
+		{"4", 4, "", true},
+		{"500 g", 500, "g", true},
+		{"1.5kg", 1.5, "kg", true},
+		{"", 0, "", true},
+		{"a few", 0, "", false},
+	}
+	for _, c := range cases {
+		v, unit, ok := parseAmount(c.in, HouseholdProfile{})
+		if v != c.v || unit != c.unit || ok != c.ok {
+			t.Errorf("parseAmount(%q) = %v, %q, %v; want %v, %q, %v", c.in, v, unit, ok, c.v, c.unit, c.ok)
+		}
+	}
+}
+
+func TestReserveAndReleaseRoundTrip(t *testing.T) {
+	q := "300 g"
+	it := Item{Name: "cheese", Quantity: &q}
+
+	if err := reserve(&it, "taco-night", 200, "G", HouseholdProfile{}); err != nil {
+		t.Fatalf("reserve returned error: %v", err)
+	}
+	if deref(it.Quantity) != "500 g" {
+		t.Fatalf("expected 500 g after reserving, got %q", deref(it.Quantity))
+	}
+	if err := reserve(&it, "taco-night", 250, "g", HouseholdProfile{}); err != nil {
+		t.Fatalf("re-reserve returned error: %v", err)
+	}
+	if deref(it.Quantity) != "550 g" {
+		t.Fatalf("expected re-reserving to replace the contribution, got %q", deref(it.Quantity))
+	}
+
+	empty, err := release(&it, "taco-night", HouseholdProfile{})
+	if err != nil {
+		t.Fatalf("release returned error: %v", err)
+	}
+	if empty || deref(it.Quantity) != "300 g" || len(it.Reservations) != 0 {
+		t.Fatalf("expected original 300 g to remain, got %q (empty=%v)", deref(it.Quantity), empty)
+	}
+}
+
+func TestReleaseEmptiesRecipeOnlyItem(t *testing.T) {
+	it := Item{Name: "tortillas"}
+	if err := reserve(&it, "taco-night", 8, "", HouseholdProfile{}); err != nil {
+		t.Fatalf("reserve returned error: %v", err)
+	}
+	if err := reserve(&it, "burritos", 4, "", HouseholdProfile{}); err != nil {
+		t.Fatalf("reserve returned error: %v", err)
+	}
+
+	if empty, _ := release(&it, "taco-night", HouseholdProfile{}); empty || deref(it.Quantity) != "4" {
+		t.Fatalf("expected burritos' 4 to remain, got %q", deref(it.Quantity))
+	}
+	if empty, _ := release(&it, "burritos", HouseholdProfile{}); !empty {
+		t.Fatal("expected item to be empty once every recipe is released")
+	}
+}
+
+func TestReserveRejectsMismatchedUnits(t *testing.T) {
+	q := "2 l"
+	it := Item{Name: "milk", Quantity: &q}
+	if err := reserve(&it, "pancakes", 500, "g", HouseholdProfile{}); err == nil {
+		t.Fatal("expected error for mismatched units")
+	}
+	if deref(it.Quantity) != "2 l" || it.Reservations != nil {
+		t.Fatal("expected item to be left untouched")
+	}
+}
+
+func TestReserveConvertsCompatibleUnits(t *testing.T) {
+	q := "2 l"
+	it := Item{Name: "milk", Quantity: &q}
+	if err := reserve(&it, "pancakes", 500, "ml", HouseholdProfile{}); err != nil {
+		t.Fatalf("reserve returned error: %v", err)
+	}
+	if deref(it.Quantity) != "2.5 l" || it.Reservations["pancakes"] != 0.5 {
+		t.Fatalf("expected 500 ml to be added as 0.5 l, got %q %v", deref(it.Quantity), it.Reservations)
+	}
+	if empty, err := release(&it, "pancakes", HouseholdProfile{}); err != nil || empty || deref(it.Quantity) != "2 l" {
+		t.Fatalf("expected release to restore 2 l, got %q (empty %v, err %v)", deref(it.Quantity), empty, err)
+	}
+}
+
+func TestReserveUsesHouseholdUnitsAndDecimals(t *testing.T) {
+	profile := HouseholdProfile{Locale: "de-DE", Units: "imperial"}
+	var it Item
+	if err := reserve(&it, "cake", 250, "g", profile); err != nil {
+		t.Fatalf("reserve returned error: %v", err)
+	}
+	if deref(it.Quantity) != "8,818 oz" {
+		t.Fatalf("expected 250 g as ounces with a decimal comma, got %q", deref(it.Quantity))
+	}
+	if err := reserve(&it, "bread", 1, "lb", profile); err != nil {
+		t.Fatalf("reserve returned error: %v", err)
+	}
+	if deref(it.Quantity) != "24,818 oz" {
+		t.Fatalf("expected 1 lb to be merged as 16 oz, got %q", deref(it.Quantity))
+	}
+}
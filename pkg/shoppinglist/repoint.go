@@ -0,0 +1,370 @@
+package shoppinglist
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/firestore"
+)
+
+// -----------------------------------------------------------------------------
+// Collection re-pointing
+// -----------------------------------------------------------------------------
+
+// repointSettle is how long RepointList waits after pausing writes to a list,
+// so writes already under way on any instance land before the last copy.
+const repointSettle = 10 * time.Second
+
+// listCache holds the list registry, kept current by RunListListener, so
+// calls find where the main list is stored and whether a list is being moved
+// without a read. A nil or unloaded cache knows no lists.
+type listCache struct {
+	mu    sync.RWMutex
+	lists []ListInfo
+}
+
+func (c *listCache) get() []ListInfo {
+	if c == nil {
+		return nil
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.lists
+}
+
+func (c *listCache) set(lists []ListInfo) {
+	c.mu.Lock()
+	c.lists = lists
+	c.mu.Unlock()
+}
+
+// LoadLists reads the list registry into the cache.
+func (s *Service) LoadLists(ctx context.Context) error {
+	lists, err := s.Lists(ctx)
+	if err != nil {
+		return err
+	}
+	s.Root().registry.set(lists)
+	return nil
+}
+
+// RunListListener keeps the list cache current with a Firestore snapshot
+// listener until ctx is done, so a list re-pointed on one instance is
+// followed by all of them within moments.
+func RunListListener(ctx context.Context, service *Service) {
+	r := service.Root()
+	listen(ctx, r.listsCollection().Query, "list registry", func(docs []*firestore.DocumentSnapshot, _ time.Time) {
+		r.registry.set(decodeLists(r.DefaultList(), docs))
+	}, nil)
+}
+
+// Main returns the service for the main list, following it to the
+// collection it was re-pointed to, if any.
+func (s *Service) Main() (*Service, error) {
+	r := s.Root()
+	if lists := r.registry.get(); len(lists) > 0 {
+		return r.ForList(lists[0])
+	}
+	return r, nil
+}
+
+// Moving reports whether the list ref names, the main list when empty, is
+// being re-pointed, so writes to it must wait.
+func (s *Service) Moving(ref string) bool {
+	if s == nil {
+		return false
+	}
+	if ref == "" {
+		ref = defaultListID
+	}
+	l, ok := findList(s.Root().registry.get(), ref)
+	return ok && l.MovingTo != ""
+}
+
+// RepointReport is the result of moving a list to another collection.
+type RepointReport struct {
+	List         ListInfo `json:"list"`
+	From         string   `json:"from"`
+	To           string   `json:"to"`
+	FromDatabase string   `json:"from_database"`
+	ToDatabase   string   `json:"to_database"`
+	Copied       int      `json:"copied"`
+	Verified     int      `json:"verified"`
+	// Retired are the old collections, kept read-only.
+	Retired []string `json:"retired"`
+	ResponseWarnings
+}
+
+// repointPairs returns each collection making up a list stored in from,
+// read through src, with the collection it moves to in to, written through
+// dst.
+func repointPairs(src, dst *firestore.Client, from, to string) [][2]*firestore.CollectionRef {
+	pairs := [][2]*firestore.CollectionRef{{src.Collection(from), dst.Collection(to)}}
+	for _, sfx := range listCollectionSuffixes {
+		pairs = append(pairs, [2]*firestore.CollectionRef{src.Collection(from + sfx), dst.Collection(to + sfx)})
+	}
+	return pairs
+}
+
+// validateRepointTarget checks that a list can be moved to collection in
+// database, empty for the server's own: a plain name the server does not
+// already use there.
+func validateRepointTarget(collection, database, root string, lists []ListInfo) error {
+	if collection == "" || strings.ContainsAny(collection, "/ ") || strings.HasPrefix(collection, "__") {
+		return fmt.Errorf("collection %q must be non-empty without spaces or slashes", collection)
+	}
+	if strings.ContainsAny(database, "/ ") {
+		return fmt.Errorf("database %q must not contain spaces or slashes", database)
+	}
+	if collection == root && database == "" {
+		return fmt.Errorf("collection %q holds the server's shared data", collection)
+	}
+	for _, sfx := range append(listCollectionSuffixes, "_lists", "_tokens", "_flags", "_telemetry", "_leases") {
+		if strings.HasSuffix(collection, sfx) {
+			return fmt.Errorf("collection %q ends in %s, which the server uses beside lists", collection, sfx)
+		}
+	}
+	for _, l := range lists {
+		if (l.Collection == collection && l.Database == database) || (l.MovingTo == collection && l.MovingToDatabase == database) {
+			return fmt.Errorf("collection %q already holds list %q", collection, l.Name)
+		}
+	}
+	return nil
+}
+
+// retiredName names a collection a list was stored in for ListInfo.Retired.
+func retiredName(database, collection string) string {
+	if database == "" {
+		return collection
+	}
+	return database + "/" + collection
+}
+
+// syncCollections copies each pair's first collection over its second,
+// writing only the documents that differ through dst, the client of the
+// second collections, and returns how many it wrote.
+func syncCollections(ctx context.Context, dst *firestore.Client, pairs [][2]*firestore.CollectionRef) (int, error) {
+	bw := dst.BulkWriter(ctx)
+	var jobs []*firestore.BulkWriterJob
+	for _, pair := range pairs {
+		from, err := readDocs(ctx, pair[0])
+		if err != nil {
+			bw.End()
+			return 0, fmt.Errorf("read %s: %w", pair[0].ID, err)
+		}
+		to, err := readDocs(ctx, pair[1])
+		if err != nil {
+			bw.End()
+			return 0, fmt.Errorf("read %s: %w", pair[1].ID, err)
+		}
+		for _, d := range diffDocs(pair[0].ID, from, to) {
+			var job *firestore.BulkWriterJob
+			if d.Kind == ShadowUnexpected {
+				job, err = bw.Delete(pair[1].Doc(d.ID))
+			} else {
+				job, err = bw.Set(pair[1].Doc(d.ID), from[d.ID])
+			}
+			if err != nil {
+				bw.End()
+				return 0, err
+			}
+			jobs = append(jobs, job)
+		}
+	}
+	bw.End()
+	for _, job := range jobs {
+		if _, err := job.Results(); err != nil {
+			return 0, err
+		}
+	}
+	return len(jobs), nil
+}
+
+// verifyCollections compares each pair, returning how many documents were
+// compared and an error when any differ.
+func verifyCollections(ctx context.Context, pairs [][2]*firestore.CollectionRef) (int, error) {
+	compared := 0
+	for _, pair := range pairs {
+		from, err := readDocs(ctx, pair[0])
+		if err != nil {
+			return 0, fmt.Errorf("read %s: %w", pair[0].ID, err)
+		}
+		to, err := readDocs(ctx, pair[1])
+		if err != nil {
+			return 0, fmt.Errorf("read %s: %w", pair[1].ID, err)
+		}
+		if diffs := diffDocs(pair[0].ID, from, to); len(diffs) > 0 {
+			return 0, fmt.Errorf("%d documents of %s differ in %s, e.g. %q (%s)", len(diffs), pair[0].ID, pair[1].ID, diffs[0].ID, diffs[0].Kind)
+		}
+		compared += len(from)
+	}
+	return compared, nil
+}
+
+// setMoving records in the registry that the list with the given ID is
+// moving to collection in database, or with an empty collection that it is
+// not.
+func (s *Service) setMoving(ctx context.Context, id, collection, database string) (ListInfo, error) {
+	var list ListInfo
+	err := s.runTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		docs, err := tx.Documents(s.listsCollection()).GetAll()
+		if err != nil {
+			return err
+		}
+		l, ok := findList(decodeLists(s.DefaultList(), docs), id)
+		if !ok {
+			return fmt.Errorf("%w: %q", ErrListNotFound, id)
+		}
+		if collection != "" && l.MovingTo != "" {
+			return fmt.Errorf("list %q is already being moved to %s", l.Name, l.MovingTo)
+		}
+		if l.CreatedAt.IsZero() {
+			l.CreatedAt = s.Now()
+		}
+		l.MovingTo, l.MovingToDatabase = collection, database
+		list = l
+		return tx.Set(s.listsCollection().Doc(l.ID), l)
+	})
+	return list, err
+}
+
+// RepointList moves the list ref names to collection without stopping the
+// server, in database when it is not empty and otherwise in the database the
+// list is in now. Another database must be in one of the locations set by
+// WithRequiredLocations, when there are any. The list's items and history are
+// copied while it stays in use, then writes to it are paused everywhere while
+// the last changes are copied and the copy is verified, and its registry
+// entry is switched over in one transaction. The old collections are kept, no
+// longer written, so they can be checked against the new ones before they
+// are deleted.
+func (s *Service) RepointList(ctx context.Context, ref, collection, database string) (RepointReport, error) {
+	r := s.Root()
+	lists, err := r.Lists(ctx)
+	if err != nil {
+		return RepointReport{}, err
+	}
+	list, ok := findList(lists, cmp.Or(ref, defaultListID))
+	if !ok {
+		return RepointReport{}, fmt.Errorf("%w: %q", ErrListNotFound, ref)
+	}
+	database = cmp.Or(database, list.Database)
+	if database == r.database {
+		database = ""
+	}
+	if err := validateRepointTarget(collection, database, r.collection, lists); err != nil {
+		return RepointReport{}, err
+	}
+	if database != "" && len(r.locations) > 0 {
+		if _, err := VerifyDatabaseLocation(ctx, r.projectID, database, r.credentialsPath, r.locations); err != nil {
+			return RepointReport{}, err
+		}
+	}
+	src, err := r.databaseClient(list.Database)
+	if err != nil {
+		return RepointReport{}, err
+	}
+	dst, err := r.databaseClient(database)
+	if err != nil {
+		return RepointReport{}, err
+	}
+	from := cmp.Or(list.Collection, r.collection)
+	pairs := repointPairs(src, dst, from, collection)
+	for _, pair := range pairs {
+		docs, err := pair[1].Limit(1).Documents(ctx).GetAll()
+		if err != nil {
+			return RepointReport{}, fmt.Errorf("read %s: %w", pair[1].ID, err)
+		}
+		if len(docs) > 0 {
+			return RepointReport{}, fmt.Errorf("collection %s is not empty", pair[1].ID)
+		}
+	}
+
+	// Copy while the list stays in use, so writes are paused only for what
+	// changes during the copy.
+	copied, err := syncCollections(ctx, dst, pairs)
+	if err != nil {
+		return RepointReport{}, fmt.Errorf("copy list: %w", err)
+	}
+
+	if _, err := r.setMoving(ctx, list.ID, collection, database); err != nil {
+		return RepointReport{}, fmt.Errorf("pause writes: %w", err)
+	}
+	if err := r.LoadLists(ctx); err != nil {
+		log.Printf("warn: %v", err)
+	}
+	report, err := r.finishRepoint(ctx, list, collection, database, dst, pairs)
+	if err != nil {
+		// Resume writes to the old collection; the partial copy is left for
+		// the operator to inspect or remove.
+		rollback, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+		defer cancel()
+		if _, rerr := r.setMoving(rollback, list.ID, "", ""); rerr != nil {
+			err = errors.Join(err, fmt.Errorf("resume writes: %w", rerr))
+		}
+		_ = r.LoadLists(rollback)
+		return RepointReport{}, err
+	}
+	report.Copied += copied
+	return report, nil
+}
+
+// finishRepoint copies and verifies what changed before writes were paused,
+// then switches list over to collection to in database.
+func (s *Service) finishRepoint(ctx context.Context, list ListInfo, to, database string, dst *firestore.Client, pairs [][2]*firestore.CollectionRef) (RepointReport, error) {
+	id, from := list.ID, cmp.Or(list.Collection, s.collection)
+	select {
+	case <-ctx.Done():
+		return RepointReport{}, ctx.Err()
+	case <-time.After(repointSettle):
+	}
+	copied, err := syncCollections(ctx, dst, pairs)
+	if err != nil {
+		return RepointReport{}, fmt.Errorf("copy list: %w", err)
+	}
+	verified, err := verifyCollections(ctx, pairs)
+	if err != nil {
+		return RepointReport{}, fmt.Errorf("verify copy: %w", err)
+	}
+
+	var moved ListInfo
+	err = s.runTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		docs, err := tx.Documents(s.listsCollection()).GetAll()
+		if err != nil {
+			return err
+		}
+		l, ok := findList(decodeLists(s.DefaultList(), docs), id)
+		if !ok || l.MovingTo != to || l.MovingToDatabase != database {
+			return fmt.Errorf("list %q was changed while it was moved", id)
+		}
+		l.Collection, l.Database, l.MovingTo, l.MovingToDatabase = to, database, "", ""
+		l.Retired = append(l.Retired, retiredName(list.Database, from))
+		moved = l
+		return tx.Set(s.listsCollection().Doc(l.ID), l)
+	})
+	if err != nil {
+		return RepointReport{}, fmt.Errorf("switch list: %w", err)
+	}
+	if err := s.LoadLists(ctx); err != nil {
+		log.Printf("warn: %v", err)
+	}
+
+	report := RepointReport{
+		List:         moved,
+		From:         from,
+		To:           to,
+		FromDatabase: cmp.Or(list.Database, s.database),
+		ToDatabase:   cmp.Or(database, s.database),
+		Copied:       copied,
+		Verified:     verified,
+	}
+	for _, pair := range pairs {
+		report.Retired = append(report.Retired, pair[0].ID)
+	}
+	return report, nil
+}
@@ -0,0 +1,75 @@
+package shoppinglist
+
+import (
+	"testing"
+
+	"cloud.google.com/go/firestore"
+)
+
+func TestValidateRepointTarget(t *testing.T) {
+	lists := []ListInfo{
+		{ID: defaultListID, Collection: "shopping"},
+		{ID: "abc", Name: "Hardware", Collection: "shopping_list_abc"},
+		{ID: "def", Name: "Party", Collection: "shopping_list_def", MovingTo: "party_v2"},
+	}
+	if err := validateRepointTarget("shopping_v2", "", "shopping", lists); err != nil {
+		t.Fatalf("expected a fresh collection to be accepted, got %v", err)
+	}
+	for _, name := range []string{"", "a/b", "two words", "__reserved", "shopping", "shopping_v2_meta", "groceries_tokens", "shopping_list_abc", "party_v2"} {
+		if err := validateRepointTarget(name, "", "shopping", lists); err == nil {
+			t.Errorf("expected %q to be rejected", name)
+		}
+	}
+
+	// Another database has none of the server's collections.
+	for _, name := range []string{"shopping", "shopping_list_abc", "party_v2"} {
+		if err := validateRepointTarget(name, "eu", "shopping", lists); err != nil {
+			t.Errorf("expected %q to be accepted in another database, got %v", name, err)
+		}
+	}
+	if err := validateRepointTarget("shopping", "a/b", "shopping", lists); err == nil {
+		t.Error("expected a database name with a slash to be rejected")
+	}
+	lists = append(lists, ListInfo{ID: "ghi", Name: "Garden", Collection: "garden", Database: "eu"})
+	if err := validateRepointTarget("garden", "eu", "shopping", lists); err == nil {
+		t.Error("expected a collection another list holds in that database to be rejected")
+	}
+}
+
+func TestForListOpensListDatabase(t *testing.T) {
+	eu := &firestore.Client{}
+	root := &Service{client: &firestore.Client{}, database: "(default)", collection: "shopping", databases: map[string]*firestore.Client{"eu": eu}}
+
+	main, err := root.ForList(ListInfo{ID: defaultListID, Collection: "shopping"})
+	if err != nil || main != root {
+		t.Fatalf("expected the main list in the server's database to be the root service, got %v", err)
+	}
+	moved, err := root.ForList(ListInfo{ID: defaultListID, Collection: "shopping", Database: "eu"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if moved == root || moved.client != eu || moved.database != "eu" || moved.collection != "shopping" || moved.Root() != root {
+		t.Fatalf("expected a service on the eu database, got %+v", moved)
+	}
+	if again, _ := root.ForList(ListInfo{ID: defaultListID, Collection: "shopping", Database: "eu"}); again != moved {
+		t.Fatal("expected the scoped service to be reused")
+	}
+}
+
+func TestListCacheMoving(t *testing.T) {
+	root := &Service{collection: "shopping", registry: &listCache{}}
+	if main, _ := root.Main(); root.Moving("") || main != root {
+		t.Fatal("expected an unloaded cache to leave the main list in place")
+	}
+
+	root.registry.set([]ListInfo{
+		{ID: defaultListID, Slug: "shopping", Collection: "shopping_v2"},
+		{ID: "abc", Slug: "hardware", Collection: "shopping_list_abc", MovingTo: "hardware_v2"},
+	})
+	if main, _ := root.Main(); main == root || main.collection != "shopping_v2" || main.Root() != root {
+		t.Fatalf("expected the main list to follow its new collection, got %q", main.collection)
+	}
+	if !root.Moving("hardware") || !root.Moving("abc") || root.Moving("") || root.Moving("missing") {
+		t.Fatal("expected only the hardware list to be moving")
+	}
+}
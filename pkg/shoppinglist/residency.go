@@ -0,0 +1,53 @@
+package shoppinglist
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	admin "cloud.google.com/go/firestore/apiv1/admin"
+	"cloud.google.com/go/firestore/apiv1/admin/adminpb"
+	"google.golang.org/api/option"
+)
+
+// -----------------------------------------------------------------------------
+// Data residency
+// -----------------------------------------------------------------------------
+
+// checkLocation reports an error when actual is not among the allowed locations.
+func checkLocation(actual string, allowed []string) error {
+	if len(allowed) == 0 || slices.Contains(allowed, strings.ToLower(actual)) {
+		return nil
+	}
+	return fmt.Errorf("Firestore database is in %q, but the residency policy requires %s", actual, strings.Join(allowed, " or "))
+}
+
+// VerifyDatabaseLocation looks up where the Firestore database is stored and
+// checks it against the allowed locations, returning the location found.
+func VerifyDatabaseLocation(ctx context.Context, projectID, database, credentialsPath string, allowed []string) (string, error) {
+	var opts []option.ClientOption
+	if credentialsPath != "" {
+		opts = append(opts, option.WithCredentialsFile(credentialsPath))
+	}
+
+	client, err := admin.NewFirestoreAdminClient(ctx, opts...)
+	if err != nil {
+		return "", fmt.Errorf("create firestore admin client: %w", err)
+	}
+	defer client.Close()
+
+	db, err := client.GetDatabase(ctx, &adminpb.GetDatabaseRequest{
+		Name: fmt.Sprintf("projects/%s/databases/%s", projectID, database),
+	})
+	if err != nil {
+		return "", fmt.Errorf("look up database location: %w", err)
+	}
+	return db.GetLocationId(), checkLocation(db.GetLocationId(), allowed)
+}
+
+// WithRequiredLocations sets the locations a list may be re-pointed to
+// another database in, as checked at startup for the server's own.
+func WithRequiredLocations(allowed []string) ServiceOption {
+	return func(s *Service) { s.locations = allowed }
+}
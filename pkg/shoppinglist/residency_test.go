@@ -0,0 +1,16 @@
+package shoppinglist
+
+import "testing"
+
+func TestCheckLocation(t *testing.T) {
+	allowed := []string{"europe-west1", "eur3"}
+	if err := checkLocation("EUROPE-WEST1", allowed); err != nil {
+		t.Fatalf("expected allowed location, got %v", err)
+	}
+	if err := checkLocation("us-central1", allowed); err == nil {
+		t.Fatal("expected error for location outside the policy")
+	}
+	if err := checkLocation("us-central1", nil); err != nil {
+		t.Fatalf("expected no policy to allow any location, got %v", err)
+	}
+}
@@ -0,0 +1,212 @@
+package shoppinglist
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/firestore"
+)
+
+// -----------------------------------------------------------------------------
+// Retention
+// -----------------------------------------------------------------------------
+
+// retentionBatch is how many expired documents are deleted per query.
+const retentionBatch = 500
+
+// retentionKind is a history collection kept per list and the field holding
+// each document's time.
+type retentionKind struct {
+	Suffix    string
+	timeField string
+}
+
+// RetentionKinds are the history collections a retention policy can limit.
+// Items in the trash stay in the list's own collection, so "deleted" has no
+// suffix.
+var RetentionKinds = map[string]retentionKind{
+	"activity":   {"_activity", "at"},
+	"purchases":  {"_purchases", "purchased_at"},
+	"trips":      {"_trips", "archived_at"},
+	"incidents":  {"_incidents", "detected_at"},
+	"telemetry":  {"_telemetry", "started_at"},
+	"tombstones": {"_tombstones", "deleted_at"},
+	"deleted":    {"", "deleted_at"},
+	"audit":      {"_audit", "at"},
+}
+
+// RetentionPolicy is how many days each kind of history is kept. Kinds that
+// are missing are kept forever. History documents are stamped with expire_at
+// when written, so a Firestore TTL policy on that field can remove them even
+// without the retention job.
+type RetentionPolicy map[string]int
+
+// ParseRetention parses "activity=90,purchases=365". An empty string keeps
+// everything.
+func ParseRetention(spec string) (RetentionPolicy, error) {
+	policy := RetentionPolicy{}
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		kind, days, ok := strings.Cut(part, "=")
+		kind = strings.ToLower(strings.TrimSpace(kind))
+		if !ok {
+			return nil, fmt.Errorf("retention %q: expected KIND=DAYS", part)
+		}
+		if _, known := RetentionKinds[kind]; !known {
+			return nil, fmt.Errorf("retention %q: unknown kind %q (expected %s)", part, kind, strings.Join(RetentionKindNames(), ", "))
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(days))
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("retention %q: days must be a positive whole number", part)
+		}
+		policy[kind] = n
+	}
+	return policy, nil
+}
+
+func RetentionKindNames() []string {
+	names := make([]string, 0, len(RetentionKinds))
+	for k := range RetentionKinds {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// expireAt returns when a document of kind written at t may be deleted, or
+// nil when that kind is kept forever.
+func (p RetentionPolicy) expireAt(kind string, t time.Time) *time.Time {
+	days, ok := p[kind]
+	if !ok {
+		return nil
+	}
+	at := t.AddDate(0, 0, days)
+	return &at
+}
+
+// EnforceRetention deletes history older than the policy allows and reports
+// how many documents of each kind were removed. Documents are selected by
+// their own time field, so those written before a policy was set are covered.
+func (s *Service) EnforceRetention(ctx context.Context, now time.Time) (map[string]int, error) {
+	removed := map[string]int{}
+	for _, kind := range RetentionKindNames() {
+		days, ok := s.retention[kind]
+		if !ok {
+			continue
+		}
+		k := RetentionKinds[kind]
+		cutoff := now.AddDate(0, 0, -days)
+		q := s.client.Collection(s.collection+k.Suffix).Where(k.timeField, "<", cutoff).Limit(retentionBatch)
+		for {
+			n, err := s.deleteAll(ctx, q)
+			removed[kind] += n
+			if err != nil {
+				return removed, fmt.Errorf("enforce %s retention: %w", kind, err)
+			}
+			if n < retentionBatch {
+				break
+			}
+		}
+	}
+	return removed, nil
+}
+
+// deleteAll deletes the documents q returns and reports how many there were.
+func (s *Service) deleteAll(ctx context.Context, q firestore.Query) (int, error) {
+	docs, err := q.Documents(ctx).GetAll()
+	if err != nil || len(docs) == 0 {
+		return 0, err
+	}
+	bw := s.client.BulkWriter(ctx)
+	jobs := make([]*firestore.BulkWriterJob, 0, len(docs))
+	for _, d := range docs {
+		job, err := bw.Delete(d.Ref)
+		if err != nil {
+			bw.End()
+			return 0, err
+		}
+		jobs = append(jobs, job)
+	}
+	bw.End()
+	for _, job := range jobs {
+		if _, err := job.Results(); err != nil {
+			return 0, err
+		}
+	}
+	return len(docs), nil
+}
+
+// nextRetentionRun returns when the retention job next runs: at the start of
+// the maintenance window when one is set, else a day after now.
+func nextRetentionRun(now time.Time, window *MaintenanceWindow) time.Time {
+	if window == nil {
+		return now.Add(24 * time.Hour)
+	}
+	now = now.UTC()
+	next := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).Add(window.Start)
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+// RunRetention enforces the retention policy on every list once at startup
+// and then daily, during the maintenance window when one is set, until ctx is
+// done. Runs due while another instance leads are skipped.
+func RunRetention(ctx context.Context, service *Service, window *MaintenanceWindow) {
+	due := service.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(due.Sub(service.Now())):
+		}
+		if !service.Leading() {
+			due = nextRetentionRun(service.Now(), window)
+			continue
+		}
+
+		runCtx, cancel := context.WithTimeout(ctx, 10*time.Minute)
+		enforceRetentionOnLists(runCtx, service)
+		cancel()
+		due = nextRetentionRun(service.Now(), window)
+	}
+}
+
+func enforceRetentionOnLists(ctx context.Context, service *Service) {
+	lists, err := service.Lists(ctx)
+	if err != nil {
+		log.Printf("warn: retention: %v", err)
+		return
+	}
+	now := service.Now()
+	for _, list := range lists {
+		svc, err := service.ForList(list)
+		if err != nil {
+			log.Printf("warn: retention on list %q: %v", list.Slug, err)
+			continue
+		}
+		removed, err := svc.EnforceRetention(ctx, now)
+		if err != nil {
+			log.Printf("warn: retention on list %q: %v", list.Slug, err)
+		}
+		for _, kind := range RetentionKindNames() {
+			if n := removed[kind]; n > 0 {
+				log.Printf("retention removed %d %s entries from list %q", n, kind, list.Slug)
+			}
+		}
+	}
+}
+
+// WithRetention sets how long history is kept.
+func WithRetention(p RetentionPolicy) ServiceOption {
+	return func(s *Service) { s.retention = p }
+}
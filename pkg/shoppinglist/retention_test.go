@@ -0,0 +1,50 @@
+package shoppinglist
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseRetention(t *testing.T) {
+	p, err := ParseRetention("activity=90, Purchases=365")
+	if err != nil {
+		t.Fatalf("ParseRetention returned error: %v", err)
+	}
+	if p["activity"] != 90 || p["purchases"] != 365 || len(p) != 2 {
+		t.Fatalf("unexpected policy %v", p)
+	}
+	for _, bad := range []string{"activity", "activity=0", "activity=two", "receipts=30"} {
+		if _, err := ParseRetention(bad); err == nil {
+			t.Errorf("expected an error for %q", bad)
+		}
+	}
+	if p, err := ParseRetention(""); err != nil || len(p) != 0 {
+		t.Fatalf("expected an empty policy, got %v, %v", p, err)
+	}
+}
+
+func TestRetentionExpireAt(t *testing.T) {
+	at := time.Date(2025, 8, 12, 9, 0, 0, 0, time.UTC)
+	p := RetentionPolicy{"trips": 30}
+	if got := p.expireAt("trips", at); got == nil || !got.Equal(at.AddDate(0, 0, 30)) {
+		t.Fatalf("expected trips to expire after 30 days, got %v", got)
+	}
+	if got := p.expireAt("activity", at); got != nil {
+		t.Fatalf("expected activity to be kept forever, got %v", got)
+	}
+}
+
+func TestNextRetentionRun(t *testing.T) {
+	now := time.Date(2025, 8, 12, 9, 0, 0, 0, time.UTC)
+	if got := nextRetentionRun(now, nil); !got.Equal(now.Add(24 * time.Hour)) {
+		t.Fatalf("expected a day later without a window, got %v", got)
+	}
+	window := &MaintenanceWindow{Start: 2 * time.Hour, End: 4 * time.Hour}
+	if got := nextRetentionRun(now, window); !got.Equal(time.Date(2025, 8, 13, 2, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected the next window start, got %v", got)
+	}
+	early := time.Date(2025, 8, 12, 1, 0, 0, 0, time.UTC)
+	if got := nextRetentionRun(early, window); !got.Equal(time.Date(2025, 8, 12, 2, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected today's window start, got %v", got)
+	}
+}
@@ -0,0 +1,140 @@
+package shoppinglist
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math/rand/v2"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// -----------------------------------------------------------------------------
+// Transient error retries
+// -----------------------------------------------------------------------------
+
+// RetryPolicy says how service operations retry transient Firestore errors,
+// so a brief outage does not fail the tool call. The client library already
+// retries single RPCs; this retries whole transactions and reads, which it
+// gives up on.
+type RetryPolicy struct {
+	// Attempts is how many times an operation is tried in all; one or less
+	// disables retries.
+	Attempts int
+	// Initial is the delay before the first retry, doubled for each after.
+	Initial time.Duration
+	// Max caps any one delay.
+	Max time.Duration
+}
+
+// DefaultRetryPolicy tries operations four times over about two seconds.
+var DefaultRetryPolicy = RetryPolicy{Attempts: 4, Initial: 100 * time.Millisecond, Max: 2 * time.Second}
+
+// WithRetryPolicy sets how operations retry transient Firestore errors.
+func WithRetryPolicy(p RetryPolicy) ServiceOption {
+	return func(s *Service) { s.retryPolicy = p }
+}
+
+// delay is how long to wait before retry number attempt, counting from zero,
+// given a jitter in [0, 1). Full jitter spreads out instances that failed
+// together so they do not retry in step.
+func (p RetryPolicy) delay(attempt int, jitter float64) time.Duration {
+	d := p.Max
+	if attempt < 30 {
+		d = min(p.Initial<<attempt, p.Max)
+	}
+	return time.Duration(jitter * float64(d))
+}
+
+// isTransient reports whether err is a Firestore error worth retrying.
+func isTransient(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted:
+		return true
+	}
+	return false
+}
+
+// finalError carries an error op must not be retried after, even though it
+// looks transient.
+type finalError struct{ error }
+
+func (e finalError) Unwrap() error { return e.error }
+
+// retry runs op until it succeeds, fails with an error that is not
+// transient, or runs out of attempts. It never waits past the deadline of
+// ctx, nor less than a ResourceExhausted error asks for; when the wait would
+// not fit, the error is returned for the caller to back off from.
+func (s *Service) retry(ctx context.Context, op func() error) error {
+	p := s.retryPolicy
+	for attempt := 0; ; attempt++ {
+		err := op()
+		var final finalError
+		if errors.As(err, &final) {
+			return final.error
+		}
+		if err == nil || !isTransient(err) || attempt+1 >= p.Attempts || ctx.Err() != nil {
+			return err
+		}
+		wait := p.delay(attempt, rand.Float64())
+		if d, ok := RetryAfter(err, s.Now()); ok && d > wait {
+			if d > p.Max {
+				return err
+			}
+			wait = d
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) <= wait {
+			return err
+		}
+		log.Printf("warn: retrying after transient Firestore error (attempt %d of %d): %v", attempt+2, p.Attempts, err)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(wait):
+		}
+	}
+}
+
+// runTransaction runs fn in a transaction like RunTransaction, retrying it
+// whole on transient errors. A commit that failed with Unavailable or timed
+// out may still have landed, and writes such as revision increments and
+// quantity deltas must not land twice, so once fn has run, only errors that
+// mean the commit was refused are retried.
+func (s *Service) runTransaction(ctx context.Context, fn func(context.Context, *firestore.Transaction) error, opts ...firestore.TransactionOption) error {
+	return s.retry(ctx, func() error {
+		ran := false
+		err := s.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+			ran = false
+			if err := fn(ctx, tx); err != nil {
+				return err
+			}
+			ran = true
+			return nil
+		}, opts...)
+		if ran && commitAmbiguous(err, opts) {
+			return finalError{err}
+		}
+		return err
+	})
+}
+
+// commitAmbiguous reports whether err, returned after fn ran, leaves it
+// unknown whether the commit landed. Only Aborted means Firestore refused it;
+// a read-only transaction has nothing to land.
+func commitAmbiguous(err error, opts []firestore.TransactionOption) bool {
+	return err != nil && status.Code(err) != codes.Aborted && !readOnlyTx(opts)
+}
+
+// readOnlyTx reports whether opts make a read-only transaction, which is safe
+// to retry whatever failed.
+func readOnlyTx(opts []firestore.TransactionOption) bool {
+	for _, o := range opts {
+		if o == firestore.ReadOnly {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,121 @@
+package shoppinglist
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRetryPolicyDelay(t *testing.T) {
+	p := RetryPolicy{Attempts: 4, Initial: 100 * time.Millisecond, Max: time.Second}
+	for attempt, want := range []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second, time.Second} {
+		if got := p.delay(attempt, 0.999999999); got.Round(time.Millisecond) != want {
+			t.Errorf("delay(%d) = %s, want about %s", attempt, got, want)
+		}
+	}
+	if got := p.delay(100, 0.5); got != 500*time.Millisecond {
+		t.Errorf("expected a late attempt to be capped, got %s", got)
+	}
+	if got := p.delay(0, 0); got != 0 {
+		t.Errorf("expected no wait for a zero jitter, got %s", got)
+	}
+}
+
+func TestRetry(t *testing.T) {
+	s := &Service{clock: SystemClock{}, retryPolicy: RetryPolicy{Attempts: 3, Initial: time.Millisecond, Max: 10 * time.Millisecond}}
+	ctx := context.Background()
+
+	calls := 0
+	err := s.retry(ctx, func() error {
+		calls++
+		if calls < 3 {
+			return fmt.Errorf("get item: %w", status.Error(codes.Unavailable, "blip"))
+		}
+		return nil
+	})
+	if err != nil || calls != 3 {
+		t.Fatalf("expected success on the third try, got %v after %d", err, calls)
+	}
+
+	calls = 0
+	err = s.retry(ctx, func() error {
+		calls++
+		return status.Error(codes.DeadlineExceeded, "slow")
+	})
+	if status.Code(err) != codes.DeadlineExceeded || calls != 3 {
+		t.Fatalf("expected the last error after 3 tries, got %v after %d", err, calls)
+	}
+
+	calls = 0
+	for _, fail := range []error{status.Error(codes.NotFound, "gone"), finalError{status.Error(codes.DeadlineExceeded, "commit")}} {
+		err = s.retry(ctx, func() error {
+			calls++
+			return fail
+		})
+		if err == nil || errors.As(err, new(finalError)) {
+			t.Fatalf("expected %v returned unwrapped, got %v", fail, err)
+		}
+	}
+	if calls != 2 {
+		t.Fatalf("expected errors that are not transient to be tried once, got %d calls", calls)
+	}
+
+	// A throttled call asking for a longer wait than the policy allows is
+	// left to the caller to back off from.
+	calls = 0
+	err = s.retry(ctx, func() error {
+		calls++
+		return status.Error(codes.ResourceExhausted, "Quota exceeded for quota metric 'Write requests' per minute")
+	})
+	if calls != 1 || status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("expected one try for a long throttle, got %v after %d", err, calls)
+	}
+
+	// Nor does it wait past the deadline of the context.
+	short, cancel := context.WithTimeout(ctx, time.Microsecond)
+	defer cancel()
+	time.Sleep(time.Millisecond)
+	calls = 0
+	_ = s.retry(short, func() error {
+		calls++
+		return status.Error(codes.Unavailable, "blip")
+	})
+	if calls != 1 {
+		t.Fatalf("expected no retry past the deadline, got %d calls", calls)
+	}
+
+	s.retryPolicy = RetryPolicy{Attempts: 1}
+	calls = 0
+	_ = s.retry(ctx, func() error {
+		calls++
+		return status.Error(codes.Unavailable, "blip")
+	})
+	if calls != 1 {
+		t.Fatalf("expected one attempt to disable retries, got %d calls", calls)
+	}
+}
+
+func TestCommitAmbiguous(t *testing.T) {
+	for _, tc := range []struct {
+		err  error
+		opts []firestore.TransactionOption
+		want bool
+	}{
+		{nil, nil, false},
+		{status.Error(codes.Unavailable, "connection reset"), nil, true},
+		{status.Error(codes.DeadlineExceeded, "slow"), nil, true},
+		{status.Error(codes.ResourceExhausted, "quota"), nil, true},
+		{status.Error(codes.Aborted, "contention"), nil, false},
+		{status.Error(codes.Unavailable, "connection reset"), []firestore.TransactionOption{firestore.ReadOnly}, false},
+	} {
+		if got := commitAmbiguous(tc.err, tc.opts); got != tc.want {
+			t.Errorf("commitAmbiguous(%v, %v) = %v, want %v", tc.err, tc.opts, got, tc.want)
+		}
+	}
+}
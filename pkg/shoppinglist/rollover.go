@@ -0,0 +1,327 @@
+package shoppinglist
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// -----------------------------------------------------------------------------
+// Weekly rollover
+// -----------------------------------------------------------------------------
+
+// rolloverDocID is the document in the meta collection recording the last rollover.
+const rolloverDocID = "rollover"
+
+// RolloverSchedule is a weekly UTC time, e.g. Sunday 18:00, at which the list
+// rolls over to a new week.
+type RolloverSchedule struct {
+	Weekday time.Weekday
+	At      time.Duration // offset from midnight UTC
+}
+
+// ParseRolloverSchedule parses "<weekday> HH:MM", e.g. "sun 18:00". An empty
+// string yields nil.
+func ParseRolloverSchedule(s string) (*RolloverSchedule, error) {
+	if s == "" {
+		return nil, nil
+	}
+	day, clock, ok := strings.Cut(strings.TrimSpace(s), " ")
+	if !ok {
+		return nil, fmt.Errorf("rollover schedule %q: expected '<weekday> HH:MM'", s)
+	}
+	weekday, ok := parseWeekday(day)
+	if !ok {
+		return nil, fmt.Errorf("rollover schedule %q: unknown weekday %q", s, day)
+	}
+	at, err := parseClock(clock)
+	if err != nil {
+		return nil, fmt.Errorf("rollover schedule %q: %w", s, err)
+	}
+	return &RolloverSchedule{Weekday: weekday, At: at}, nil
+}
+
+func parseWeekday(s string) (time.Weekday, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		name := strings.ToLower(d.String())
+		if len(s) >= 3 && strings.HasPrefix(name, s) {
+			return d, true
+		}
+	}
+	return 0, false
+}
+
+// Next returns the first scheduled time strictly after t.
+func (r *RolloverSchedule) Next(t time.Time) time.Time {
+	t = t.UTC()
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	days := (int(r.Weekday) - int(t.Weekday()) + 7) % 7
+	next := midnight.AddDate(0, 0, days).Add(r.At)
+	if !next.After(t) {
+		next = next.AddDate(0, 0, 7)
+	}
+	return next
+}
+
+// Previous returns the latest scheduled time at or before t.
+func (r *RolloverSchedule) Previous(t time.Time) time.Time {
+	return r.Next(t).AddDate(0, 0, -7)
+}
+
+// TemplateItem is an item added to every new week unless already on the list.
+type TemplateItem struct {
+	Name     string `json:"name"`
+	Quantity string `json:"quantity,omitempty"`
+}
+
+// LoadTemplate reads a JSON array of template items.
+func LoadTemplate(path string) ([]TemplateItem, error) {
+	if path == "" {
+		return nil, nil
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("rollover template: %w", err)
+	}
+	var items []TemplateItem
+	if err := json.Unmarshal(b, &items); err != nil {
+		return nil, fmt.Errorf("rollover template: %w", err)
+	}
+	for i, it := range items {
+		if strings.TrimSpace(it.Name) == "" {
+			return nil, fmt.Errorf("rollover template: item %d has no name", i)
+		}
+	}
+	return items, nil
+}
+
+// TripArchive is a completed trip kept after rollover.
+type TripArchive struct {
+	ID         string    `json:"id" firestore:"id"`
+	ArchivedAt time.Time `json:"archived_at" firestore:"archived_at"`
+	Items      []Item    `json:"items" firestore:"items"`
+
+	ExpireAt *time.Time `json:"-" firestore:"expire_at,omitempty"`
+}
+
+// RolloverSummary describes what a rollover changed.
+type RolloverSummary struct {
+	TripID   string   `json:"trip_id,omitempty"`
+	Archived int      `json:"archived"`
+	Restored []string `json:"restored_staples,omitempty"`
+	Added    []string `json:"added_from_template,omitempty"`
+	Carried  []string `json:"carried_over,omitempty"`
+	Skipped  bool     `json:"skipped,omitempty"`
+	ResponseWarnings
+}
+
+// rolloverPlan is the set of changes a rollover makes.
+type rolloverPlan struct {
+	archive []Item
+	remove  []string // purchased items to delete
+	restore []Item   // purchased staples to put back on the list
+	carried []Item   // unpurchased items left as they are
+	add     []TemplateItem
+}
+
+// planRollover archives purchased items, restores purchased staples, keeps
+// unpurchased items, and adds template items not already on the new list.
+func planRollover(items []Item, template []TemplateItem, normalizer *NameNormalizer) rolloverPlan {
+	var plan rolloverPlan
+	onList := map[string]bool{}
+	for _, it := range items {
+		switch {
+		case !it.Purchased:
+			plan.carried = append(plan.carried, it)
+		case it.Staple:
+			plan.archive = append(plan.archive, it)
+			plan.restore = append(plan.restore, it)
+		default:
+			plan.archive = append(plan.archive, it)
+			plan.remove = append(plan.remove, it.ID)
+			continue
+		}
+		onList[normalizer.Key(it.Name)] = true
+	}
+	for _, t := range template {
+		if key := normalizer.Key(t.Name); !onList[key] {
+			onList[key] = true
+			plan.add = append(plan.add, t)
+		}
+	}
+	return plan
+}
+
+// Rollover starts a new week. When scheduledFor is set, a rollover already
+// recorded for that time (for example by another instance) is skipped. Writes
+// that need more than one commit follow the transaction, which records the
+// rollover.
+func (s *Service) Rollover(ctx context.Context, template []TemplateItem, scheduledFor time.Time) (RolloverSummary, error) {
+	col := s.client.Collection(s.collection)
+	meta := s.metaCollection().Doc(rolloverDocID)
+	now := s.Now()
+	if scheduledFor.IsZero() {
+		scheduledFor = now
+	}
+
+	var summary RolloverSummary
+	var rest *writeBatch
+	err := s.runTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		summary = RolloverSummary{}
+		rest = nil
+		doc, err := tx.Get(meta)
+		if err != nil && status.Code(err) != codes.NotFound {
+			return err
+		}
+		if err == nil {
+			if last, err := doc.DataAt("last_run"); err == nil {
+				if t, ok := last.(time.Time); ok && !t.Before(scheduledFor) {
+					summary.Skipped = true
+					return nil
+				}
+			}
+		}
+
+		docs, err := tx.Documents(col).GetAll()
+		if err != nil {
+			return err
+		}
+		plan := planRollover(decodeItems(docs), template, s.normalizer)
+		trash := trashedIDs(docs)
+
+		// Recording the rollover goes first, so it commits with the read. The
+		// finished trip's freeze does not carry over into the new week.
+		b := s.newBatch()
+		b.Set(meta, map[string]any{"last_run": scheduledFor})
+		b.Delete(s.metaCollection().Doc(freezeDocID))
+		if len(plan.archive) > 0 {
+			summary.TripID = s.NewID()
+			trip := TripArchive{ID: summary.TripID, ArchivedAt: now, Items: plan.archive, ExpireAt: s.retention.expireAt("trips", now)}
+			b.Create(s.client.Collection(s.collection+"_trips").Doc(trip.ID), trip)
+		}
+		for _, id := range plan.remove {
+			b.DeleteItem(col.Doc(id))
+		}
+		for _, it := range plan.restore {
+			b.Update(col.Doc(it.ID), withRevision([]firestore.Update{
+				{Path: "purchased", Value: false},
+				{Path: "purchased_at", Value: firestore.Delete},
+			}, s.writeTime()))
+			summary.Restored = append(summary.Restored, it.Name)
+		}
+		for _, t := range plan.add {
+			at := s.writeTime()
+			it := Item{ID: s.newItemID(t.Name), Name: t.Name, CreatedAt: at, UpdatedAt: &at, Revision: 1}
+			if t.Quantity != "" {
+				q := t.Quantity
+				it.Quantity = &q
+			}
+			if trash[it.ID] {
+				b.Set(col.Doc(it.ID), itemDoc(it))
+			} else {
+				b.Create(col.Doc(it.ID), itemDoc(it))
+			}
+			summary.Added = append(summary.Added, t.Name)
+		}
+		for _, it := range plan.carried {
+			summary.Carried = append(summary.Carried, it.Name)
+		}
+		summary.Archived = len(plan.archive)
+		rest, err = b.Apply(tx)
+		return err
+	})
+	if err == nil {
+		err = rest.Commit(ctx)
+	}
+	if err != nil {
+		return RolloverSummary{}, fmt.Errorf("roll over list: %w", err)
+	}
+	if !summary.Skipped {
+		s.notifyRollover(ctx, summary)
+	}
+	return summary, nil
+}
+
+// notifyRollover announces what is on the list for the new week.
+func (s *Service) notifyRollover(ctx context.Context, summary RolloverSummary) {
+	if s.notifier == nil {
+		return
+	}
+	total := len(summary.Restored) + len(summary.Added) + len(summary.Carried)
+	err := s.notifier.Notify(ctx, Notification{
+		Kind:  "rollover",
+		Title: "New shopping week",
+		Message: fmt.Sprintf("Archived %d purchased items. %d items are on for the new week: %d staples, %d from the template, %d carried over.",
+			summary.Archived, total, len(summary.Restored), len(summary.Added), len(summary.Carried)),
+		Time: s.Now(),
+		Data: summary,
+	})
+	if err != nil {
+		log.Printf("warn: notify rollover: %v", err)
+	}
+}
+
+// lastRollover returns when the list last rolled over, or the zero time if never.
+func (s *Service) lastRollover(ctx context.Context) (time.Time, error) {
+	doc, err := s.metaCollection().Doc(rolloverDocID).Get(ctx)
+	if status.Code(err) == codes.NotFound {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("read rollover: %w", err)
+	}
+	last, _ := doc.DataAt("last_run")
+	t, _ := last.(time.Time)
+	return t, nil
+}
+
+// RunRolloverSchedule rolls the list over at each scheduled time until ctx is
+// done. A rollover missed while the server was down runs at startup; the first
+// rollover after enabling a schedule waits for the next scheduled time. Only
+// the leading instance rolls over.
+func RunRolloverSchedule(ctx context.Context, service *Service, schedule *RolloverSchedule, template []TemplateItem) {
+	due := schedule.Next(service.Now())
+	if last, err := service.lastRollover(ctx); err != nil {
+		log.Printf("warn: scheduled rollover: %v", err)
+	} else if prev := schedule.Previous(service.Now()); !last.IsZero() && last.Before(prev) {
+		due = prev
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(due.Sub(service.Now())):
+		}
+		if !service.Leading() {
+			due = schedule.Next(service.Now())
+			continue
+		}
+
+		main, err := service.Main()
+		if err != nil {
+			log.Printf("warn: scheduled rollover: %v", err)
+			due = schedule.Next(service.Now())
+			continue
+		}
+		runCtx, cancel := context.WithTimeout(ctx, time.Minute)
+		summary, err := main.Rollover(runCtx, template, due)
+		cancel()
+		switch {
+		case err != nil:
+			log.Printf("warn: scheduled rollover: %v", err)
+		case !summary.Skipped:
+			log.Printf("rolled the list over for %s: archived %d items", due.Format(time.RFC3339), summary.Archived)
+		}
+		due = schedule.Next(service.Now())
+	}
+}
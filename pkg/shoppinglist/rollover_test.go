@@ -0,0 +1,83 @@
+package shoppinglist
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseRolloverSchedule(t *testing.T) {
+	r, err := ParseRolloverSchedule("Sunday 18:30")
+	if err != nil {
+		t.Fatalf("ParseRolloverSchedule returned error: %v", err)
+	}
+	if r.Weekday != time.Sunday || r.At != 18*time.Hour+30*time.Minute {
+		t.Fatalf("unexpected schedule %+v", r)
+	}
+	for _, bad := range []string{"sun", "su 18:00", "funday 18:00", "sun 25:00"} {
+		if _, err := ParseRolloverSchedule(bad); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+	if r, err := ParseRolloverSchedule(""); r != nil || err != nil {
+		t.Fatalf("expected empty schedule to be disabled, got %v %v", r, err)
+	}
+}
+
+func TestRolloverScheduleNextAndPrevious(t *testing.T) {
+	r := &RolloverSchedule{Weekday: time.Sunday, At: 18 * time.Hour}
+	wed := time.Date(2025, 8, 13, 9, 0, 0, 0, time.UTC)
+
+	next := r.Next(wed)
+	if want := time.Date(2025, 8, 17, 18, 0, 0, 0, time.UTC); !next.Equal(want) {
+		t.Fatalf("expected next %v, got %v", want, next)
+	}
+	if again := r.Next(next); !again.Equal(next.AddDate(0, 0, 7)) {
+		t.Fatalf("expected the following week at the scheduled time, got %v", again)
+	}
+	if prev := r.Previous(wed); !prev.Equal(time.Date(2025, 8, 10, 18, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected previous %v", prev)
+	}
+}
+
+func TestPlanRollover(t *testing.T) {
+	items := []Item{
+		{ID: "1", Name: "milk", Purchased: true, Staple: true},
+		{ID: "2", Name: "cake", Purchased: true},
+		{ID: "3", Name: "batteries"},
+	}
+	template := []TemplateItem{{Name: "Milk"}, {Name: "eggs", Quantity: "12"}, {Name: "cake"}}
+
+	plan := planRollover(items, template, nil)
+	if len(plan.archive) != 2 || len(plan.remove) != 1 || plan.remove[0] != "2" {
+		t.Fatalf("expected both purchased items archived and cake removed, got %+v", plan)
+	}
+	if len(plan.restore) != 1 || plan.restore[0].ID != "1" {
+		t.Fatalf("expected milk restored, got %+v", plan.restore)
+	}
+	if len(plan.carried) != 1 || plan.carried[0].ID != "3" {
+		t.Fatalf("expected batteries carried over, got %+v", plan.carried)
+	}
+	if len(plan.add) != 2 || plan.add[0].Name != "eggs" || plan.add[1].Name != "cake" {
+		t.Fatalf("expected eggs and cake added from the template, got %+v", plan.add)
+	}
+}
+
+func TestLoadTemplate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "template.json")
+	if err := os.WriteFile(path, []byte(`[{"name":"eggs","quantity":"12"}]`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	items, err := LoadTemplate(path)
+	if err != nil || len(items) != 1 || items[0].Quantity != "12" {
+		t.Fatalf("unexpected template %+v (%v)", items, err)
+	}
+
+	if err := os.WriteFile(path, []byte(`[{"quantity":"1"}]`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadTemplate(path); err == nil {
+		t.Fatal("expected error for an item without a name")
+	}
+}
@@ -0,0 +1,210 @@
+package shoppinglist
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/ext"
+)
+
+// -----------------------------------------------------------------------------
+// Validation rules
+// -----------------------------------------------------------------------------
+
+// ruleCostLimit bounds the work one rule may do on one item, so a careless
+// expression cannot stall writes.
+const ruleCostLimit = 10000
+
+// RuleFields are the item fields rules may target.
+var RuleFields = []string{"name", "quantity", "category", "package_size", "priority"}
+
+// Rule is an operator-defined check or rewrite of one field, written in CEL.
+// Both expressions see the field's new value as value and the fields being
+// written as item; Transform runs first and returns the value to store, then
+// Validate must hold or the write is rejected with Message.
+type Rule struct {
+	Field     string `json:"field"`
+	Transform string `json:"transform,omitempty"`
+	Validate  string `json:"validate,omitempty"`
+	Message   string `json:"message,omitempty"`
+
+	transform cel.Program
+	validate  cel.Program
+}
+
+// RuleSet is the compiled rules, applied in order.
+type RuleSet struct {
+	rules []Rule
+}
+
+// RuleViolationError is returned when a write fails a rule.
+type RuleViolationError struct {
+	Field   string
+	Message string
+}
+
+func (e *RuleViolationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+// LoadRules reads and compiles a JSON array of rules. An empty path means no
+// rules.
+func LoadRules(path string) (*RuleSet, error) {
+	if path == "" {
+		return nil, nil
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read rules: %w", err)
+	}
+	var rules []Rule
+	if err := json.Unmarshal(b, &rules); err != nil {
+		return nil, fmt.Errorf("parse rules %s: %w", path, err)
+	}
+	return NewRuleSet(rules)
+}
+
+// NewRuleSet compiles rules, checking that transforms return a string and
+// validations a bool.
+func NewRuleSet(rules []Rule) (*RuleSet, error) {
+	env, err := cel.NewEnv(
+		cel.Variable("value", cel.StringType),
+		cel.Variable("item", cel.MapType(cel.StringType, cel.DynType)),
+		ext.Strings(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("create rule environment: %w", err)
+	}
+	compiled := make([]Rule, 0, len(rules))
+	for i, r := range rules {
+		if !slices.Contains(RuleFields, r.Field) {
+			return nil, fmt.Errorf("rule %d: unsupported field %q (expected one of %v)", i, r.Field, RuleFields)
+		}
+		if r.Transform == "" && r.Validate == "" {
+			return nil, fmt.Errorf("rule %d: needs a transform or validate expression", i)
+		}
+		if r.Transform != "" {
+			if r.transform, err = compileRule(env, r.Transform, cel.StringType); err != nil {
+				return nil, fmt.Errorf("rule %d transform: %w", i, err)
+			}
+		}
+		if r.Validate != "" {
+			if r.validate, err = compileRule(env, r.Validate, cel.BoolType); err != nil {
+				return nil, fmt.Errorf("rule %d validate: %w", i, err)
+			}
+			if r.Message == "" {
+				r.Message = "fails rule " + r.Validate
+			}
+		}
+		compiled = append(compiled, r)
+	}
+	return &RuleSet{rules: compiled}, nil
+}
+
+// compileRule compiles expr, which must evaluate to want.
+func compileRule(env *cel.Env, expr string, want *cel.Type) (cel.Program, error) {
+	ast, iss := env.Compile(expr)
+	if iss.Err() != nil {
+		return nil, iss.Err()
+	}
+	if !ast.OutputType().IsExactType(want) {
+		return nil, fmt.Errorf("returns %s, expected %s", ast.OutputType(), want)
+	}
+	return env.Program(ast, cel.CostLimit(ruleCostLimit))
+}
+
+// ruleTarget is the input field a rule field reads and writes; nil when the
+// write leaves it unchanged.
+func ruleTarget(input *ItemInput, field string) *string {
+	switch field {
+	case "name":
+		return &input.Name
+	case "quantity":
+		return input.Quantity
+	case "category":
+		return input.Category
+	case "package_size":
+		return input.PackageSize
+	case "priority":
+		return input.Priority
+	}
+	return nil
+}
+
+// setRuleTarget stores a rewritten value as a copy, leaving whatever the
+// input pointed at untouched.
+func setRuleTarget(input *ItemInput, field, v string) *string {
+	switch field {
+	case "name":
+		input.Name = v
+		return &input.Name
+	case "quantity":
+		input.Quantity = &v
+	case "category":
+		input.Category = &v
+	case "package_size":
+		input.PackageSize = &v
+	case "priority":
+		input.Priority = &v
+	}
+	return &v
+}
+
+// ruleItem is the item variable: the fields the write sets.
+func ruleItem(input *ItemInput) map[string]any {
+	item := map[string]any{}
+	for _, field := range RuleFields {
+		if v := ruleTarget(input, field); v != nil {
+			item[field] = *v
+		}
+	}
+	if input.Tags != nil {
+		item["tags"] = input.Tags
+	}
+	if input.Price != nil {
+		item["price"] = *input.Price
+	}
+	if input.Staple != nil {
+		item["staple"] = *input.Staple
+	}
+	return item
+}
+
+// Apply runs the rules over the fields input sets, rewriting them in place,
+// and returns a *RuleViolationError for the first validation that fails.
+func (r *RuleSet) Apply(input *ItemInput) error {
+	if r == nil {
+		return nil
+	}
+	for _, rule := range r.rules {
+		target := ruleTarget(input, rule.Field)
+		if target == nil {
+			continue
+		}
+		if rule.transform != nil {
+			out, _, err := rule.transform.Eval(map[string]any{"value": *target, "item": ruleItem(input)})
+			if err != nil {
+				return fmt.Errorf("rule on %s: %w", rule.Field, err)
+			}
+			target = setRuleTarget(input, rule.Field, out.Value().(string))
+		}
+		if rule.validate != nil {
+			out, _, err := rule.validate.Eval(map[string]any{"value": *target, "item": ruleItem(input)})
+			if err != nil {
+				return fmt.Errorf("rule on %s: %w", rule.Field, err)
+			}
+			if ok, _ := out.Value().(bool); !ok {
+				return &RuleViolationError{Field: rule.Field, Message: rule.Message}
+			}
+		}
+	}
+	return nil
+}
+
+// WithRules applies operator-defined rules to items as they are written.
+func WithRules(r *RuleSet) ServiceOption {
+	return func(s *Service) { s.rules = r }
+}
@@ -0,0 +1,74 @@
+package shoppinglist
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRulesTransformAndValidate(t *testing.T) {
+	rules, err := NewRuleSet([]Rule{
+		{Field: "category", Validate: `!(value in ["tobacco", "alcohol"])`, Message: "this household does not shop for that"},
+		{Field: "name", Transform: `value.startsWith("#") ? value.upperAscii() : value`},
+		{Field: "quantity", Validate: `item.name != "bread" || value != ""`},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	category := "produce"
+	input := ItemInput{Name: "#ab12", Category: &category}
+	if err := rules.Apply(&input); err != nil {
+		t.Fatal(err)
+	}
+	if input.Name != "#AB12" {
+		t.Errorf("name = %q, want it upper-cased", input.Name)
+	}
+
+	category = "tobacco"
+	err = rules.Apply(&ItemInput{Name: "cigars", Category: &category})
+	var violation *RuleViolationError
+	if !errors.As(err, &violation) || violation.Field != "category" {
+		t.Fatalf("err = %v, want a category violation", err)
+	}
+
+	// rules on fields the write leaves alone do not run
+	if err := rules.Apply(&ItemInput{Name: "bread"}); err != nil {
+		t.Errorf("unexpected error for a write without a quantity: %v", err)
+	}
+}
+
+func TestRuleTransformDoesNotWriteThroughCallerPointer(t *testing.T) {
+	rules, err := NewRuleSet([]Rule{{Field: "quantity", Transform: `value.trim()`}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	qty := " 2 "
+	input := ItemInput{Name: "milk", Quantity: &qty}
+	if err := rules.Apply(&input); err != nil {
+		t.Fatal(err)
+	}
+	if *input.Quantity != "2" || qty != " 2 " {
+		t.Errorf("quantity = %q, caller's = %q", *input.Quantity, qty)
+	}
+}
+
+func TestNewRuleSetRejectsBadRules(t *testing.T) {
+	for _, r := range []Rule{
+		{Field: "colour", Validate: "true"},
+		{Field: "name"},
+		{Field: "name", Validate: "value"},
+		{Field: "name", Transform: "size(value)"},
+		{Field: "name", Validate: "value ==="},
+	} {
+		if _, err := NewRuleSet([]Rule{r}); err == nil {
+			t.Errorf("expected %+v to be rejected", r)
+		}
+	}
+}
+
+func TestNilRuleSetAppliesNothing(t *testing.T) {
+	var rules *RuleSet
+	if err := rules.Apply(&ItemInput{Name: "milk"}); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,98 @@
+package shoppinglist
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"cloud.google.com/go/firestore"
+)
+
+// -----------------------------------------------------------------------------
+// Name search
+// -----------------------------------------------------------------------------
+
+// How SearchItems found its matches.
+const (
+	SearchPrefix    = "prefix"
+	SearchSubstring = "substring"
+)
+
+// SearchItemsResponse lists the items whose names match a query.
+type SearchItemsResponse struct {
+	Query   string `json:"query"`
+	Match   string `json:"match"`
+	Matches []Item `json:"matches"`
+	ResponseWarnings
+}
+
+// prefixVariants are the casings of query a prefix search tries, since
+// Firestore range queries are case-sensitive: as typed, lowercase, and
+// capitalized.
+func prefixVariants(query string) []string {
+	lower := strings.ToLower(query)
+	r, n := utf8.DecodeRuneInString(lower)
+	variants := []string{query, lower, string(unicode.ToUpper(r)) + lower[n:]}
+	return slices.Compact(slices.Sorted(slices.Values(variants)))
+}
+
+// substringMatches returns up to limit items whose names contain query,
+// ignoring case.
+func substringMatches(items []Item, query string, limit int) []Item {
+	query = strings.ToLower(query)
+	matches := []Item{}
+	for _, it := range items {
+		if len(matches) == limit {
+			break
+		}
+		if strings.Contains(strings.ToLower(it.Name), query) {
+			matches = append(matches, it)
+		}
+	}
+	return matches
+}
+
+// SearchItems returns up to limit items whose names start with query, using
+// Firestore range queries so only matches are read. When no name starts with
+// it, the list is read and searched for names containing query anywhere.
+// Matches are ordered by name.
+func (s *Service) SearchItems(ctx context.Context, query string, limit int) (SearchItemsResponse, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return SearchItemsResponse{}, fmt.Errorf("search query is required")
+	}
+	resp := SearchItemsResponse{Query: query, Match: SearchPrefix, Matches: []Item{}}
+	col := s.client.Collection(s.collection)
+	seen := map[string]bool{}
+	for _, p := range prefixVariants(query) {
+		docs, err := liveDocuments(ctx, nil, col.Where("name", ">=", p).Where("name", "<", p+"\uf8ff").OrderBy("name", firestore.Asc), limit)
+		if err != nil {
+			return SearchItemsResponse{}, fmt.Errorf("search items: %w", err)
+		}
+		for _, it := range decodeItems(docs) {
+			if !seen[it.ID] {
+				seen[it.ID] = true
+				resp.Matches = append(resp.Matches, it)
+			}
+		}
+	}
+
+	if len(resp.Matches) == 0 {
+		items, err := s.ListItems(ctx)
+		if err != nil {
+			return SearchItemsResponse{}, err
+		}
+		resp.Match = SearchSubstring
+		resp.Matches = substringMatches(items, query, limit)
+	}
+	slices.SortStableFunc(resp.Matches, func(a, b Item) int {
+		return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+	})
+	if len(resp.Matches) > limit {
+		resp.Matches = resp.Matches[:limit]
+	}
+	return resp, nil
+}
@@ -0,0 +1,31 @@
+package shoppinglist
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestPrefixVariants(t *testing.T) {
+	got := prefixVariants("mILk")
+	want := []string{"Milk", "mILk", "milk"}
+	if !slices.Equal(got, want) {
+		t.Errorf("prefixVariants = %q, want %q", got, want)
+	}
+	if got := prefixVariants("milk"); !slices.Equal(got, []string{"Milk", "milk"}) {
+		t.Errorf("prefixVariants(milk) = %q", got)
+	}
+}
+
+func TestSubstringMatchesIgnoresCase(t *testing.T) {
+	items := []Item{{ID: "1", Name: "Oat milk"}, {ID: "2", Name: "bread"}, {ID: "3", Name: "MILK chocolate"}, {ID: "4", Name: "buttermilk"}}
+	var ids []string
+	for _, it := range substringMatches(items, "Milk", 2) {
+		ids = append(ids, it.ID)
+	}
+	if !slices.Equal(ids, []string{"1", "3"}) {
+		t.Errorf("matches = %v, want [1 3]", ids)
+	}
+	if got := substringMatches(items, "eggs", 10); got == nil || len(got) != 0 {
+		t.Errorf("expected an empty, non-nil result, got %v", got)
+	}
+}
@@ -0,0 +1,292 @@
+package shoppinglist
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// -----------------------------------------------------------------------------
+// Shopping sessions
+// -----------------------------------------------------------------------------
+
+// shoppingSessionDocID is the document in the meta collection holding the
+// shopping session under way.
+const shoppingSessionDocID = "shopping_session"
+
+// DefaultSessionLength and MaxSessionLength bound how long a shopping session
+// records checks for.
+const (
+	DefaultSessionLength = 2 * time.Hour
+	MaxSessionLength     = 12 * time.Hour
+)
+
+// ShoppingSession is a time-boxed trip to the store. Items checked off before
+// it ends are recorded against it until it is finished.
+type ShoppingSession struct {
+	ID        string                  `json:"id" firestore:"id"`
+	StartedAt time.Time               `json:"started_at" firestore:"started_at"`
+	EndsAt    time.Time               `json:"ends_at" firestore:"ends_at"`
+	Store     string                  `json:"store,omitempty" firestore:"store,omitempty"`
+	Checks    map[string]SessionCheck `json:"-" firestore:"checks"`
+}
+
+// Active reports whether the session still records checks at now.
+func (ss *ShoppingSession) Active(now time.Time) bool {
+	return ss != nil && now.Before(ss.EndsAt)
+}
+
+// SessionCheck is an item checked off during a session, with when and at what
+// expected price.
+type SessionCheck struct {
+	ItemID    string    `json:"item_id" firestore:"item_id"`
+	Name      string    `json:"name" firestore:"name"`
+	CheckedAt time.Time `json:"checked_at" firestore:"checked_at"`
+	Price     *float64  `json:"price,omitempty" firestore:"price,omitempty"`
+	Currency  string    `json:"currency,omitempty" firestore:"currency,omitempty"`
+}
+
+// ShoppingTotals is a session's checks in the order they were made and what
+// they add up to in one currency.
+type ShoppingTotals struct {
+	Session  *ShoppingSession `json:"session"`
+	Checked  []SessionCheck   `json:"checked"`
+	Spent    float64          `json:"spent"`
+	Currency string           `json:"currency"`
+	Unpriced []string         `json:"unpriced,omitempty"`
+	ResponseWarnings
+}
+
+// FinishShoppingResponse reports a finished session: its totals, the trip it
+// was archived as, and the purchase records written for it.
+type FinishShoppingResponse struct {
+	ShoppingTotals
+	TripID     string           `json:"trip_id,omitempty"`
+	FinishedAt time.Time        `json:"finished_at"`
+	Purchases  []PurchaseRecord `json:"purchases,omitempty"`
+}
+
+// ErrNoShoppingSession is returned when no shopping session is under way.
+var ErrNoShoppingSession = errors.New("no shopping session is under way; start one with start_shopping")
+
+// sessionChecks returns a session's checks in the order they were made.
+func sessionChecks(ss *ShoppingSession) []SessionCheck {
+	checks := make([]SessionCheck, 0, len(ss.Checks))
+	for _, c := range ss.Checks {
+		checks = append(checks, c)
+	}
+	slices.SortFunc(checks, func(a, b SessionCheck) int {
+		if c := a.CheckedAt.Compare(b.CheckedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ItemID, b.ItemID)
+	})
+	return checks
+}
+
+// checkOf records it as checked off at now during a session.
+func checkOf(it Item, now time.Time) SessionCheck {
+	c := SessionCheck{ItemID: it.ID, Name: it.Name, CheckedAt: now}
+	if opt, ok := itemPrice(it); ok {
+		price := opt.Price
+		c.Price, c.Currency = &price, opt.Currency
+	}
+	return c
+}
+
+// SessionTotals adds up a session's checks in currency, as a price report
+// would.
+func SessionTotals(ctx context.Context, rates ExchangeRateSource, currency string, ss *ShoppingSession) ShoppingTotals {
+	checks := sessionChecks(ss)
+	items := make([]Item, len(checks))
+	for i, c := range checks {
+		items[i] = Item{ID: c.ItemID, Name: c.Name, Price: c.Price, PriceCurrency: c.Currency}
+	}
+	report := BuildPriceReport(ctx, rates, currency, items)
+	return ShoppingTotals{
+		Session:          ss,
+		Checked:          checks,
+		Spent:            report.Total,
+		Currency:         report.Currency,
+		Unpriced:         report.Unpriced,
+		ResponseWarnings: report.ResponseWarnings,
+	}
+}
+
+// decodeShoppingSession decodes the session document, treating a missing one
+// as no session.
+func decodeShoppingSession(doc *firestore.DocumentSnapshot, err error) (*ShoppingSession, error) {
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read shopping session: %w", err)
+	}
+	var ss ShoppingSession
+	if err := doc.DataTo(&ss); err != nil {
+		return nil, fmt.Errorf("decode shopping session: %w", err)
+	}
+	return &ss, nil
+}
+
+// ShoppingSession returns the session not yet finished, which may have run
+// past its end, or nil when there is none.
+func (s *Service) ShoppingSession(ctx context.Context) (*ShoppingSession, error) {
+	return decodeShoppingSession(s.metaCollection().Doc(shoppingSessionDocID).Get(ctx))
+}
+
+// StartShopping starts a session at the store that records checks for length
+// (DefaultSessionLength when zero). Only one session runs at a time, so one
+// not yet finished must be finished first, even once it has ended.
+func (s *Service) StartShopping(ctx context.Context, length time.Duration, store string) (*ShoppingSession, error) {
+	if length == 0 {
+		length = DefaultSessionLength
+	}
+	if length < 0 || length > MaxSessionLength {
+		return nil, fmt.Errorf("session length must be between 0 and %s", MaxSessionLength)
+	}
+	ref := s.metaCollection().Doc(shoppingSessionDocID)
+	now := s.Now()
+	ss := &ShoppingSession{ID: s.NewID(), StartedAt: now, EndsAt: now.Add(length), Store: store, Checks: map[string]SessionCheck{}}
+	err := s.runTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		current, err := decodeShoppingSession(tx.Get(ref))
+		if err != nil {
+			return err
+		}
+		if current != nil {
+			return fmt.Errorf("a shopping session has been under way since %s; finish it with finish_shopping first", current.StartedAt.UTC().Format(time.RFC3339))
+		}
+		return tx.Create(ref, ss)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("start shopping: %w", err)
+	}
+	return ss, nil
+}
+
+// sessionCheckUpdate records it on a session as checked off at now, or
+// clears its check once it is put back.
+func sessionCheckUpdate(it Item, now time.Time) firestore.Update {
+	var value any = firestore.Delete
+	if it.Purchased {
+		value = checkOf(it, now)
+	}
+	return firestore.Update{FieldPath: firestore.FieldPath{"checks", it.ID}, Value: value}
+}
+
+// FinishShopping ends the session in one transaction: the items checked off
+// during it and still checked are archived as a trip and written to the
+// purchase history at the price expected when they were checked, then
+// removed from the list. Unpurchased children of a removed item become
+// top-level items. Totals are in currency. Writes that need more than one
+// commit follow the transaction, which ends the session.
+func (s *Service) FinishShopping(ctx context.Context, rates ExchangeRateSource, currency string) (FinishShoppingResponse, error) {
+	col := s.client.Collection(s.collection)
+	ref := s.metaCollection().Doc(shoppingSessionDocID)
+	now := s.Now()
+	var (
+		resp    FinishShoppingResponse
+		ss      *ShoppingSession
+		removed []Item
+		rest    *writeBatch
+	)
+	err := s.runTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		resp = FinishShoppingResponse{FinishedAt: now}
+		rest = nil
+		var err error
+		if ss, err = decodeShoppingSession(tx.Get(ref)); err != nil {
+			return err
+		}
+		if ss == nil {
+			return ErrNoShoppingSession
+		}
+		docs, err := tx.Documents(col).GetAll()
+		if err != nil {
+			return err
+		}
+		items := decodeItems(docs)
+		// Drop checks of items put back since, or no longer on the list.
+		checked := map[string]SessionCheck{}
+		var ids []string
+		for _, it := range items {
+			if c, ok := ss.Checks[it.ID]; ok && it.Purchased {
+				checked[it.ID] = c
+				ids = append(ids, it.ID)
+			}
+		}
+		ss.Checks = checked
+		var promote []string
+		_, removed, promote = planBulkRemove(items, ids, false)
+
+		// Ending the session goes first, so it commits with the read. The
+		// trip is over, so any freeze on the list ends with it.
+		b := s.newBatch()
+		b.Delete(ref)
+		b.Delete(s.metaCollection().Doc(freezeDocID))
+		if len(removed) > 0 {
+			resp.TripID = ss.ID
+			trip := TripArchive{ID: resp.TripID, ArchivedAt: now, Items: removed, ExpireAt: s.retention.expireAt("trips", now)}
+			b.Create(s.client.Collection(s.collection+"_trips").Doc(trip.ID), trip)
+		}
+		for _, c := range sessionChecks(ss) {
+			r := PurchaseRecord{
+				ID:          s.NewID(),
+				SessionID:   ss.ID,
+				ItemID:      c.ItemID,
+				Name:        c.Name,
+				Currency:    c.Currency,
+				Store:       ss.Store,
+				PurchasedAt: c.CheckedAt,
+			}
+			if c.Price != nil {
+				r.Price = *c.Price
+			}
+			if r.Currency == "" {
+				r.Currency = currency
+			}
+			if it, ok := itemByID(items, c.ItemID); ok && it.Quantity != nil {
+				r.Quantity = *it.Quantity
+			}
+			r.ExpireAt = s.retention.expireAt("purchases", r.PurchasedAt)
+			b.Create(s.purchasesCollection().Doc(r.ID), r)
+			resp.Purchases = append(resp.Purchases, r)
+		}
+		for _, id := range promote {
+			b.Update(col.Doc(id), withRevision([]firestore.Update{{Path: "parent_id", Value: firestore.Delete}}, s.writeTime()))
+		}
+		for _, it := range removed {
+			b.DeleteItem(col.Doc(it.ID))
+		}
+		rest, err = b.Apply(tx)
+		return err
+	})
+	if err == nil {
+		err = rest.Commit(ctx)
+	}
+	if err != nil {
+		return FinishShoppingResponse{}, fmt.Errorf("finish shopping: %w", err)
+	}
+	resp.ShoppingTotals = SessionTotals(ctx, rates, currency, ss)
+	if len(removed) > 0 {
+		s.observe(ctx, activityDelete, len(removed))
+		s.recordActivity(ctx, ActionRemoved, removed...)
+		s.recordAudit(ctx, itemChanges(ActionRemoved, removed)...)
+	}
+	return resp, nil
+}
+
+// itemByID finds the item with the given ID.
+func itemByID(items []Item, id string) (Item, bool) {
+	i := slices.IndexFunc(items, func(it Item) bool { return it.ID == id })
+	if i < 0 {
+		return Item{}, false
+	}
+	return items[i], true
+}
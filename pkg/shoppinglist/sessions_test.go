@@ -0,0 +1,59 @@
+package shoppinglist
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestShoppingSessionActiveUntilItEnds(t *testing.T) {
+	start := time.Date(2025, 8, 12, 17, 0, 0, 0, time.UTC)
+	ss := &ShoppingSession{StartedAt: start, EndsAt: start.Add(DefaultSessionLength)}
+
+	if !ss.Active(start.Add(time.Hour)) {
+		t.Fatal("expected session to be active before it ends")
+	}
+	if ss.Active(start.Add(DefaultSessionLength)) {
+		t.Fatal("expected session to stop recording checks once it ends")
+	}
+	var none *ShoppingSession
+	if none.Active(start) {
+		t.Fatal("expected nil session to be inactive")
+	}
+}
+
+func TestSessionTotalsAddsChecksInOrder(t *testing.T) {
+	start := time.Date(2025, 8, 12, 17, 0, 0, 0, time.UTC)
+	milk := 2.5
+	eggs := Item{ID: "eggs", Name: "eggs", PackageOptions: []PackageOption{{Size: "12", Price: 4, Currency: "EUR"}}}
+	ss := &ShoppingSession{StartedAt: start, EndsAt: start.Add(time.Hour), Checks: map[string]SessionCheck{
+		"eggs":  checkOf(eggs, start.Add(10*time.Minute)),
+		"milk":  checkOf(Item{ID: "milk", Name: "milk", Price: &milk}, start.Add(5*time.Minute)),
+		"bread": checkOf(Item{ID: "bread", Name: "bread"}, start.Add(20*time.Minute)),
+	}}
+	rates := RateTable{Base: "USD", PerBase: map[string]float64{"USD": 1, "EUR": 0.5}}
+
+	got := SessionTotals(context.Background(), rates, "USD", ss)
+	if len(got.Checked) != 3 || got.Checked[0].ItemID != "milk" || got.Checked[2].ItemID != "bread" {
+		t.Fatalf("expected checks in the order they were made, got %+v", got.Checked)
+	}
+	if got.Spent != 10.5 {
+		t.Fatalf("expected 10.50 spent, got %.2f", got.Spent)
+	}
+	if len(got.Unpriced) != 1 || got.Unpriced[0] != "bread" {
+		t.Fatalf("expected bread unpriced, got %v", got.Unpriced)
+	}
+}
+
+func TestSessionCheckUpdateClearsPutBackItems(t *testing.T) {
+	now := time.Date(2025, 8, 12, 17, 0, 0, 0, time.UTC)
+
+	checked := sessionCheckUpdate(Item{ID: "milk", Name: "milk", Purchased: true}, now)
+	if c, ok := checked.Value.(SessionCheck); !ok || !c.CheckedAt.Equal(now) {
+		t.Fatalf("expected a check at %s, got %+v", now, checked.Value)
+	}
+	cleared := sessionCheckUpdate(Item{ID: "milk", Name: "milk"}, now)
+	if _, ok := cleared.Value.(SessionCheck); ok {
+		t.Fatalf("expected the check cleared, got %+v", cleared.Value)
+	}
+}
@@ -0,0 +1,487 @@
+package shoppinglist
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	pb "cloud.google.com/go/firestore/apiv1/firestorepb"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// -----------------------------------------------------------------------------
+// Shadow writes
+// -----------------------------------------------------------------------------
+
+// ShadowTimeout bounds each mirror or comparison so a slow shadow never holds
+// up the primary.
+const ShadowTimeout = 30 * time.Second
+
+// Discrepancy kinds.
+const (
+	ShadowMissing    = "missing"    // in the primary only
+	ShadowUnexpected = "unexpected" // in the shadow only
+	ShadowDiffers    = "differs"    // in both with different fields
+)
+
+// ShadowTarget is a second Firestore location kept as a copy of every list
+// while a migration is under way, so it can be compared with the primary
+// before clients are switched over to it.
+type ShadowTarget struct {
+	client     *firestore.Client
+	database   string
+	collection string
+	stats      shadowStats
+	writes     shadowQueue
+}
+
+// NewShadowTarget connects to the shadow database. Lists are mirrored under
+// collection in place of the primary's default collection name.
+func NewShadowTarget(ctx context.Context, projectID, database, collection, credentialsPath string) (*ShadowTarget, error) {
+	var opts []option.ClientOption
+	if credentialsPath != "" {
+		opts = append(opts, option.WithCredentialsFile(credentialsPath))
+	}
+	client, err := firestore.NewClientWithDatabase(ctx, projectID, database, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("create shadow firestore client: %w", err)
+	}
+	return &ShadowTarget{client: client, database: database, collection: collection}, nil
+}
+
+// Close releases the shadow's Firestore resources.
+func (t *ShadowTarget) Close() error { return t.client.Close() }
+
+// shadowQueue holds the primary documents written since they were last
+// mirrored, by full name, for a single worker to copy in the order they
+// were first written. The worker runs only while there is something to copy.
+type shadowQueue struct {
+	mu      sync.Mutex
+	pending []string
+	queued  map[string]bool
+	running bool
+	idle    chan struct{}
+}
+
+// add queues names, starting a worker that passes them to mirror if none is
+// running.
+func (q *shadowQueue) add(names []string, mirror func([]string)) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.queued == nil {
+		q.queued = map[string]bool{}
+	}
+	for _, name := range names {
+		if !q.queued[name] {
+			q.queued[name] = true
+			q.pending = append(q.pending, name)
+		}
+	}
+	if q.running || len(q.pending) == 0 {
+		return
+	}
+	q.running, q.idle = true, make(chan struct{})
+	go q.work(mirror)
+}
+
+func (q *shadowQueue) work(mirror func([]string)) {
+	for {
+		q.mu.Lock()
+		names := q.pending
+		q.pending, q.queued = nil, nil
+		if len(names) == 0 {
+			q.running = false
+			close(q.idle)
+			q.mu.Unlock()
+			return
+		}
+		q.mu.Unlock()
+		mirror(names)
+	}
+}
+
+// wait blocks until everything queued has been mirrored, or timeout passes.
+func (q *shadowQueue) wait(timeout time.Duration) {
+	q.mu.Lock()
+	idle, running := q.idle, q.running
+	q.mu.Unlock()
+	if !running {
+		return
+	}
+	select {
+	case <-idle:
+	case <-time.After(timeout):
+	}
+}
+
+// WithShadow mirrors every list to t.
+func WithShadow(t *ShadowTarget) ServiceOption {
+	return func(s *Service) { s.shadow = t }
+}
+
+// ShadowDiscrepancy is one document that differs between primary and shadow.
+type ShadowDiscrepancy struct {
+	Collection string   `json:"collection"`
+	ID         string   `json:"id"`
+	Kind       string   `json:"kind"`
+	Fields     []string `json:"fields,omitempty"`
+}
+
+// ShadowTotals are the shadow's running counts since startup.
+type ShadowTotals struct {
+	Mirrors        int        `json:"mirrors"`
+	DocumentWrites int        `json:"document_writes"`
+	MirrorFailures int        `json:"mirror_failures"`
+	Comparisons    int        `json:"comparisons"`
+	Discrepancies  int        `json:"discrepancies"`
+	LastMismatch   *time.Time `json:"last_mismatch,omitempty"`
+}
+
+type shadowStats struct {
+	mu     sync.Mutex
+	totals ShadowTotals
+}
+
+func (st *shadowStats) update(f func(*ShadowTotals)) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	f(&st.totals)
+}
+
+func (st *shadowStats) snapshot() ShadowTotals {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	return st.totals
+}
+
+// ShadowReport is the result of comparing a list with its shadow.
+type ShadowReport struct {
+	Database      string              `json:"shadow_database"`
+	Compared      int                 `json:"compared"`
+	Discrepancies []ShadowDiscrepancy `json:"discrepancies"`
+	Totals        ShadowTotals        `json:"totals"`
+	ResponseWarnings
+}
+
+// diffDocs compares documents by ID, reporting the fields that differ.
+func diffDocs(collection string, primary, shadow map[string]map[string]any) []ShadowDiscrepancy {
+	var out []ShadowDiscrepancy
+	for id, p := range primary {
+		sh, ok := shadow[id]
+		if !ok {
+			out = append(out, ShadowDiscrepancy{Collection: collection, ID: id, Kind: ShadowMissing})
+			continue
+		}
+		if fields := diffFields(p, sh); len(fields) > 0 {
+			out = append(out, ShadowDiscrepancy{Collection: collection, ID: id, Kind: ShadowDiffers, Fields: fields})
+		}
+	}
+	for id := range shadow {
+		if _, ok := primary[id]; !ok {
+			out = append(out, ShadowDiscrepancy{Collection: collection, ID: id, Kind: ShadowUnexpected})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
+
+// diffFields returns the sorted names of fields set differently in a and b.
+func diffFields(a, b map[string]any) []string {
+	var fields []string
+	for k, v := range a {
+		if w, ok := b[k]; !ok || !reflect.DeepEqual(v, w) {
+			fields = append(fields, k)
+		}
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			fields = append(fields, k)
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// shadowName maps a primary collection to its shadow by swapping the default
+// collection name, so "shopping_list_abc_meta" becomes
+// "shopping_v2_list_abc_meta".
+func shadowName(name, root, shadowRoot string) string {
+	return shadowRoot + strings.TrimPrefix(name, root)
+}
+
+// shadowPairs returns each primary collection making up the list with the
+// shadow collection it is mirrored to.
+func (s *Service) shadowPairs() [][2]*firestore.CollectionRef {
+	r := s.Root()
+	root := r.collection
+	cols := []*firestore.CollectionRef{s.client.Collection(s.collection), s.client.Collection(s.collection + "_meta")}
+	if s.collection == root {
+		cols = append(cols, r.listsCollection())
+	}
+	pairs := make([][2]*firestore.CollectionRef, 0, len(cols))
+	for _, col := range cols {
+		mirrored := shadowName(col.ID, root, s.shadow.collection)
+		pairs = append(pairs, [2]*firestore.CollectionRef{col, s.shadow.client.Collection(mirrored)})
+	}
+	return pairs
+}
+
+// readDocs reads a collection's documents keyed by ID.
+func readDocs(ctx context.Context, col *firestore.CollectionRef) (map[string]map[string]any, error) {
+	docs, err := col.Documents(ctx).GetAll()
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string]map[string]any, len(docs))
+	for _, d := range docs {
+		out[d.Ref.ID] = d.Data()
+	}
+	return out, nil
+}
+
+// diffShadow reads the list from both sides and compares it, returning the
+// primary documents too so they can be mirrored.
+func (s *Service) diffShadow(ctx context.Context) (int, []ShadowDiscrepancy, map[string]map[string]map[string]any, error) {
+	compared := 0
+	var discrepancies []ShadowDiscrepancy
+	primaries := map[string]map[string]map[string]any{}
+	for _, pair := range s.shadowPairs() {
+		primary, err := readDocs(ctx, pair[0])
+		if err != nil {
+			return 0, nil, nil, fmt.Errorf("read %s: %w", pair[0].ID, err)
+		}
+		shadow, err := readDocs(ctx, pair[1])
+		if err != nil {
+			return 0, nil, nil, fmt.Errorf("read shadow %s: %w", pair[1].ID, err)
+		}
+		compared += len(primary)
+		primaries[pair[0].ID] = primary
+		discrepancies = append(discrepancies, diffDocs(pair[0].ID, primary, shadow)...)
+	}
+	return compared, discrepancies, primaries, nil
+}
+
+// MirrorShadow brings the shadow copy of the list in line with the primary,
+// writing only the documents that differ.
+func (s *Service) MirrorShadow(ctx context.Context) (int, error) {
+	_, discrepancies, primaries, err := s.diffShadow(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("mirror to shadow: %w", err)
+	}
+	if len(discrepancies) == 0 {
+		return 0, nil
+	}
+	shadowOf := map[string]*firestore.CollectionRef{}
+	for _, pair := range s.shadowPairs() {
+		shadowOf[pair[0].ID] = pair[1]
+	}
+	bw := s.shadow.client.BulkWriter(ctx)
+	jobs := make([]*firestore.BulkWriterJob, 0, len(discrepancies))
+	for _, d := range discrepancies {
+		ref := shadowOf[d.Collection].Doc(d.ID)
+		var (
+			job *firestore.BulkWriterJob
+			err error
+		)
+		if d.Kind == ShadowUnexpected {
+			job, err = bw.Delete(ref)
+		} else {
+			job, err = bw.Set(ref, primaries[d.Collection][d.ID])
+		}
+		if err != nil {
+			bw.End()
+			return 0, fmt.Errorf("mirror to shadow: %w", err)
+		}
+		jobs = append(jobs, job)
+	}
+	bw.End()
+	for _, job := range jobs {
+		if _, err := job.Results(); err != nil {
+			return 0, fmt.Errorf("mirror to shadow: %w", err)
+		}
+	}
+	return len(jobs), nil
+}
+
+// CompareShadow compares the list with its shadow copy without changing
+// either.
+func (s *Service) CompareShadow(ctx context.Context) (ShadowReport, error) {
+	compared, discrepancies, _, err := s.diffShadow(ctx)
+	if err != nil {
+		return ShadowReport{}, fmt.Errorf("compare shadow: %w", err)
+	}
+	now := s.Now()
+	s.shadow.stats.update(func(t *ShadowTotals) {
+		t.Comparisons++
+		t.Discrepancies += len(discrepancies)
+		if len(discrepancies) > 0 {
+			t.LastMismatch = &now
+		}
+	})
+	return ShadowReport{
+		Database:      s.shadow.database,
+		Compared:      compared,
+		Discrepancies: append([]ShadowDiscrepancy{}, discrepancies...),
+		Totals:        s.shadow.stats.snapshot(),
+	}, nil
+}
+
+// SyncShadow mirrors the whole list, logging rather than failing so the
+// shadow never affects the primary.
+func (s *Service) SyncShadow(ctx context.Context) {
+	if s.shadow == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), ShadowTimeout)
+	defer cancel()
+	n, err := s.MirrorShadow(ctx)
+	s.shadow.stats.update(func(t *ShadowTotals) {
+		t.Mirrors++
+		t.DocumentWrites += n
+		if err != nil {
+			t.MirrorFailures++
+		}
+	})
+	if err != nil {
+		log.Printf("warn: shadow %s: %v", s.collection, err)
+	}
+}
+
+// shadowUnary queues the documents each successful write changed to be
+// copied to the shadow in the background, so mirroring costs the call
+// nothing. Writes explain mode holds back never reach it.
+func (s *Service) shadowUnary(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	if err := invoker(ctx, method, req, reply, cc, opts...); err != nil {
+		return err
+	}
+	var writes []*pb.Write
+	switch r := req.(type) {
+	case *pb.CommitRequest:
+		writes = r.GetWrites()
+	case *pb.BatchWriteRequest:
+		writes = r.GetWrites()
+	}
+	names := make([]string, 0, len(writes))
+	for _, w := range writes {
+		if name := writeDocument(w); name != "" {
+			names = append(names, name)
+		}
+	}
+	if len(names) > 0 {
+		s.shadow.writes.add(names, s.mirrorDocs)
+	}
+	return nil
+}
+
+// shadowCollections maps each primary collection a list is mirrored through,
+// by full path, to its pair. Lists not yet in the registry are left to the
+// sweep.
+func (s *Service) shadowCollections() map[string][2]*firestore.CollectionRef {
+	r := s.Root()
+	out := map[string][2]*firestore.CollectionRef{}
+	for _, pair := range r.shadowPairs() {
+		out[pair[0].Path] = pair
+	}
+	for _, list := range r.registry.get() {
+		svc, err := r.ForList(list)
+		if err != nil {
+			continue
+		}
+		for _, pair := range svc.shadowPairs() {
+			out[pair[0].Path] = pair
+		}
+	}
+	return out
+}
+
+// mirrorDocs copies the named primary documents to the shadow as they are
+// now, deleting those no longer in the primary. Documents in collections
+// that are not mirrored, such as history, are skipped.
+func (s *Service) mirrorDocs(names []string) {
+	ctx, cancel := context.WithTimeout(context.Background(), ShadowTimeout)
+	defer cancel()
+	n, err := s.copyToShadow(ctx, names)
+	s.shadow.stats.update(func(t *ShadowTotals) {
+		t.DocumentWrites += n
+		if err != nil {
+			t.MirrorFailures++
+		}
+	})
+	if err != nil {
+		log.Printf("warn: shadow: %v", err)
+	}
+}
+
+func (s *Service) copyToShadow(ctx context.Context, names []string) (int, error) {
+	pairs := s.shadowCollections()
+	bw := s.shadow.client.BulkWriter(ctx)
+	var jobs []*firestore.BulkWriterJob
+	for _, name := range names {
+		i := strings.LastIndex(name, "/")
+		pair, ok := pairs[name[:i]]
+		if !ok {
+			continue
+		}
+		id := name[i+1:]
+		doc, err := pair[0].Doc(id).Get(ctx)
+		var job *firestore.BulkWriterJob
+		switch {
+		case status.Code(err) == codes.NotFound:
+			job, err = bw.Delete(pair[1].Doc(id))
+		case err == nil:
+			job, err = bw.Set(pair[1].Doc(id), doc.Data())
+		}
+		if err != nil {
+			bw.End()
+			return 0, fmt.Errorf("mirror %s/%s: %w", pair[0].ID, id, err)
+		}
+		jobs = append(jobs, job)
+	}
+	bw.End()
+	for _, job := range jobs {
+		if _, err := job.Results(); err != nil {
+			return 0, fmt.Errorf("mirror to shadow: %w", err)
+		}
+	}
+	return len(jobs), nil
+}
+
+// RunShadowSweep compares every list with its shadow on a schedule and
+// writes the documents that differ, catching copies that failed and lists
+// created since the registry was read, while this instance leads.
+func RunShadowSweep(ctx context.Context, service *Service, every time.Duration) {
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if !service.Leading() {
+			continue
+		}
+		lists, err := service.Lists(ctx)
+		if err != nil {
+			log.Printf("warn: shadow sweep: %v", err)
+			continue
+		}
+		for _, list := range lists {
+			svc, err := service.ForList(list)
+			if err != nil {
+				log.Printf("warn: shadow sweep of list %q: %v", list.Slug, err)
+				continue
+			}
+			svc.SyncShadow(ctx)
+		}
+	}
+}
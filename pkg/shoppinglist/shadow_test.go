@@ -0,0 +1,72 @@
+package shoppinglist
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDiffDocs(t *testing.T) {
+	created := time.Date(2025, 8, 12, 14, 0, 0, 0, time.UTC)
+	primary := map[string]map[string]any{
+		"a": {"name": "milk", "created_at": created, "revision": int64(2)},
+		"b": {"name": "eggs", "quantity": "12"},
+		"c": {"name": "tea"},
+	}
+	shadow := map[string]map[string]any{
+		"a": {"name": "milk", "created_at": created, "revision": int64(2)},
+		"b": {"name": "eggs", "quantity": "6", "staple": true},
+		"d": {"name": "bread"},
+	}
+	got := diffDocs("shopping", primary, shadow)
+	want := []ShadowDiscrepancy{
+		{Collection: "shopping", ID: "b", Kind: ShadowDiffers, Fields: []string{"quantity", "staple"}},
+		{Collection: "shopping", ID: "c", Kind: ShadowMissing},
+		{Collection: "shopping", ID: "d", Kind: ShadowUnexpected},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("diffDocs = %+v, want %+v", got, want)
+	}
+	if got := diffDocs("shopping", primary, primary); len(got) != 0 {
+		t.Errorf("identical collections differ: %+v", got)
+	}
+}
+
+func TestShadowName(t *testing.T) {
+	tests := map[string]string{
+		"shopping":               "shopping_v2",
+		"shopping_meta":          "shopping_v2_meta",
+		"shopping_lists":         "shopping_v2_lists",
+		"shopping_list_abc":      "shopping_v2_list_abc",
+		"shopping_list_abc_meta": "shopping_v2_list_abc_meta",
+	}
+	for name, want := range tests {
+		if got := shadowName(name, "shopping", "shopping_v2"); got != want {
+			t.Errorf("shadowName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestShadowQueueMirrorsEachDocumentOnce(t *testing.T) {
+	var batches [][]string
+	mirror := func(names []string) { batches = append(batches, names) }
+
+	// While a worker is busy, documents written again are queued once, in
+	// the order first written.
+	q := shadowQueue{running: true}
+	q.add([]string{"a", "b"}, mirror)
+	q.add([]string{"b", "c", "c"}, mirror)
+	if want := []string{"a", "b", "c"}; !reflect.DeepEqual(q.pending, want) {
+		t.Fatalf("queued %v, want %v", q.pending, want)
+	}
+
+	q.running = false
+	q.add(nil, mirror)
+	q.wait(time.Second)
+	if want := [][]string{{"a", "b", "c"}}; !reflect.DeepEqual(batches, want) {
+		t.Fatalf("mirrored %v, want %v", batches, want)
+	}
+	if q.running || len(q.pending) != 0 {
+		t.Fatal("expected the worker to stop once the queue is empty")
+	}
+}
@@ -0,0 +1,696 @@
+package shoppinglist
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// -----------------------------------------------------------------------------
+// Domain types
+// -----------------------------------------------------------------------------
+
+// Item is a shopping list entry.
+type Item struct {
+	ID             string             `json:"id" firestore:"id"`
+	Name           string             `json:"name" firestore:"name"`
+	Quantity       *string            `json:"quantity,omitempty" firestore:"quantity,omitempty"`
+	Amount         *float64           `json:"amount,omitempty" firestore:"amount,omitempty"`
+	Unit           string             `json:"unit,omitempty" firestore:"unit,omitempty"`
+	CreatedAt      time.Time          `json:"created_at" firestore:"created_at"`
+	UpdatedAt      *time.Time         `json:"updated_at,omitempty" firestore:"updated_at,omitempty"`
+	PackageSize    *string            `json:"package_size,omitempty" firestore:"package_size,omitempty"`
+	PackageOptions []PackageOption    `json:"package_options,omitempty" firestore:"package_options,omitempty"`
+	Price          *float64           `json:"price,omitempty" firestore:"price,omitempty"`
+	PriceCurrency  string             `json:"price_currency,omitempty" firestore:"price_currency,omitempty"`
+	Attachments    []Attachment       `json:"attachments,omitempty" firestore:"attachments,omitempty"`
+	ParentID       *string            `json:"parent_id,omitempty" firestore:"parent_id,omitempty"`
+	Category       *string            `json:"category,omitempty" firestore:"category,omitempty"`
+	NeededBy       *time.Time         `json:"needed_by,omitempty" firestore:"needed_by,omitempty"`
+	Tags           []string           `json:"tags,omitempty" firestore:"tags,omitempty"`
+	Priority       *string            `json:"priority,omitempty" firestore:"priority,omitempty"`
+	Staple         bool               `json:"staple,omitempty" firestore:"staple,omitempty"`
+	Purchased      bool               `json:"purchased" firestore:"purchased"`
+	PurchasedAt    *time.Time         `json:"purchased_at,omitempty" firestore:"purchased_at,omitempty"`
+	Reservations   map[string]float64 `json:"reservations,omitempty" firestore:"reservations,omitempty"`
+	Position       int64              `json:"position,omitempty" firestore:"position,omitempty"`
+	Revision       int64              `json:"revision" firestore:"revision"`
+	DeletedAt      *time.Time         `json:"deleted_at,omitempty" firestore:"deleted_at,omitempty"` // set on items in the trash
+
+	QuantityFormat int `json:"-" firestore:"quantity_format,omitempty"`
+}
+
+// ItemInput is the user-facing upsert payload.
+type ItemInput struct {
+	ID          *string    `json:"id,omitempty"`
+	Name        string     `json:"name"`
+	Quantity    *string    `json:"quantity,omitempty"`
+	PackageSize *string    `json:"package_size,omitempty"`
+	ParentID    *string    `json:"parent_id,omitempty"`
+	Category    *string    `json:"category,omitempty"`
+	NeededBy    *time.Time `json:"needed_by,omitempty"`
+	Tags        []string   `json:"tags,omitempty"` // nil leaves tags unchanged; empty clears them
+	Priority    *string    `json:"priority,omitempty"`
+	Price       *float64   `json:"price,omitempty"` // 0 clears the price
+	Currency    string     `json:"currency,omitempty"`
+	Staple      *bool      `json:"staple,omitempty"`
+
+	// Precondition guards an update; it needs an ID.
+	Precondition Precondition `json:"-"`
+}
+
+// ListItemsResponse wraps a list response.
+type ListItemsResponse struct {
+	Items          []Item       `json:"items"`
+	EstimatedTotal *float64     `json:"estimated_total,omitempty"`
+	Currency       string       `json:"currency,omitempty"`
+	Unpriced       int          `json:"unpriced,omitempty"`
+	ReadTime       *time.Time   `json:"read_time,omitempty"`
+	FrozenUntil    *time.Time   `json:"frozen_until,omitempty"`
+	StaleAsOf      *time.Time   `json:"stale_as_of,omitempty"`
+	NextCursor     string       `json:"next_cursor,omitempty"`
+	Changes        []ItemChange `json:"changes,omitempty"`
+	Display        *Display     `json:"display,omitempty"`
+	ResponseWarnings
+}
+
+// MutationResponse reports a write without the rest of the list: the item it
+// left, if any, and what it changed.
+type MutationResponse struct {
+	Item    *Item        `json:"item,omitempty"`
+	Changes []ItemChange `json:"changes"`
+	ResponseWarnings
+}
+
+// UpsertItemRequest is the tool request for creating/updating a single item.
+type UpsertItemRequest struct {
+	ID          *string    `json:"id,omitempty"`
+	Name        string     `json:"name"`
+	Quantity    *string    `json:"quantity,omitempty"`
+	PackageSize *string    `json:"package_size,omitempty"`
+	ParentID    *string    `json:"parent_id,omitempty"`
+	Category    *string    `json:"category,omitempty"`
+	NeededBy    *time.Time `json:"needed_by,omitempty"`
+	Tags        []string   `json:"tags,omitempty"` // nil leaves tags unchanged; empty clears them
+	Priority    *string    `json:"priority,omitempty"`
+	Price       *float64   `json:"price,omitempty"` // 0 clears the price
+	Currency    string     `json:"currency,omitempty"`
+	Staple      *bool      `json:"staple,omitempty"`
+}
+
+// -----------------------------------------------------------------------------
+// Firestore service
+// -----------------------------------------------------------------------------
+
+// Service encapsulates Firestore operations.
+type Service struct {
+	client     *firestore.Client
+	database   string
+	collection string
+	anomalies  *AnomalyDetector
+	notifier   Notifier
+	normalizer *NameNormalizer
+	profile    HouseholdProfile
+	retention  RetentionPolicy
+	clock      Clock
+	ids        IDGenerator
+	derivedIDs bool
+	shadow     *ShadowTarget
+	rules      *RuleSet
+	filter     *ContentFilter
+	usage      *UsageMeter
+	flags      *FlagCache
+	registry   *listCache
+
+	retryPolicy RetryPolicy
+
+	snapshot    *listSnapshot
+	staleMaxAge time.Duration
+
+	// caching is shared with list-scoped services; cache is this list's.
+	caching *itemCaching
+	cache   *itemCache
+
+	// migrating is set while legacy quantities read from the list are being
+	// written back.
+	migrating atomic.Bool
+
+	// instanceID and leading are set on the default-list service only; see
+	// Leading.
+	instanceID string
+	leading    *atomic.Bool
+
+	// projectID, credentialsPath, clientOpts, and locations are kept on the
+	// default-list service to open and check the other databases lists are
+	// re-pointed to.
+	projectID       string
+	credentialsPath string
+	clientOpts      []option.ClientOption
+	locations       []string
+
+	// parent is the default-list service a list-scoped service was derived
+	// from; scoped caches those derived services by database and collection,
+	// and databases the clients of databases other than the server's.
+	parent    *Service
+	scopedMu  sync.Mutex
+	scoped    map[string]*Service
+	databases map[string]*firestore.Client
+}
+
+// ServiceOption configures optional Service behavior.
+type ServiceOption func(*Service)
+
+// WithAnomalyDetector reports bursts of mutations as incidents.
+func WithAnomalyDetector(d *AnomalyDetector) ServiceOption {
+	return func(s *Service) { s.anomalies = d }
+}
+
+// WithNotifier sets where alert and summary notifications are delivered.
+func WithNotifier(n Notifier) ServiceOption {
+	return func(s *Service) { s.notifier = n }
+}
+
+// NewService initializes a Firestore client and returns the service.
+func NewService(ctx context.Context, projectID, database, collection string, credentialsPath string, serviceOpts ...ServiceOption) (*Service, error) {
+	if projectID == "" {
+		return nil, errors.New("projectID is required")
+	}
+	if database == "" {
+		return nil, errors.New("database is required")
+	}
+	if collection == "" {
+		return nil, errors.New("collection is required")
+	}
+
+	var opts []option.ClientOption
+	if credentialsPath != "" {
+		if _, err := os.Stat(credentialsPath); err != nil {
+			return nil, fmt.Errorf("credentials file: %w", err)
+		}
+		opts = append(opts, option.WithCredentialsFile(credentialsPath))
+	}
+
+	s := &Service{
+		database:        database,
+		collection:      collection,
+		projectID:       projectID,
+		credentialsPath: credentialsPath,
+		clock:           SystemClock{},
+		ids:             RandomIDs{},
+		snapshot:        &listSnapshot{},
+		flags:           &FlagCache{},
+		registry:        &listCache{},
+
+		retryPolicy: DefaultRetryPolicy,
+	}
+	for _, opt := range serviceOpts {
+		opt(s)
+	}
+	s.cache = newItemCache(s.caching)
+
+	// Every RPC goes through the usage meter, so usage_report can count the
+	// operations the server bills, after explain mode has held back any
+	// write it should not send.
+	s.usage = NewUsageMeter(s.Now)
+	unary := []grpc.UnaryClientInterceptor{explainUnary, s.usage.unaryInterceptor}
+	if s.caching != nil {
+		unary = append(unary, s.caching.unaryInterceptor)
+	}
+	if s.shadow != nil {
+		unary = append(unary, s.shadowUnary)
+	}
+	opts = append(opts,
+		option.WithGRPCDialOption(grpc.WithChainUnaryInterceptor(unary...)),
+		option.WithGRPCDialOption(grpc.WithChainStreamInterceptor(explainStream, s.usage.streamInterceptor)),
+	)
+	s.clientOpts = opts
+	client, err := firestore.NewClientWithDatabase(ctx, projectID, database, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("create firestore client: %w", err)
+	}
+	s.client = client
+	return s, nil
+}
+
+// Close releases Firestore resources. Services scoped to a list share the
+// main list's connections, so closing one does nothing; only the service
+// NewService returned closes them, with those of the other databases lists
+// were re-pointed to.
+func (s *Service) Close() error {
+	if s.parent != nil {
+		return nil
+	}
+	if s.shadow != nil {
+		// Finish copying what was written to the shadow while the primary
+		// can still be read.
+		s.shadow.writes.wait(ShadowTimeout)
+	}
+	s.scopedMu.Lock()
+	defer s.scopedMu.Unlock()
+	err := s.client.Close()
+	for _, client := range s.databases {
+		err = errors.Join(err, client.Close())
+	}
+	return err
+}
+
+// ListItems returns all items in the collection, in list order.
+func (s *Service) ListItems(ctx context.Context) ([]Item, error) {
+	if view, ok := s.cachedView(ctx); ok {
+		s.snapshot.store(view.Items, view.ReadTime)
+		return view.Items, nil
+	}
+	var docs []*firestore.DocumentSnapshot
+	err := s.retry(ctx, func() (err error) {
+		docs, err = s.client.Collection(s.collection).Documents(ctx).GetAll()
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("retrieve items: %w", err)
+	}
+
+	items := decodeItems(docs)
+	sortItems(items)
+	s.snapshot.store(items, s.Now())
+	return items, nil
+}
+
+// itemsAfter returns all items in list order as they were the moment w was
+// written, so the list always reflects that write however soon it is read.
+func (s *Service) itemsAfter(ctx context.Context, w *firestore.WriteResult) ([]Item, error) {
+	if view, ok := s.cachedView(ctx); ok {
+		s.snapshot.store(view.Items, view.ReadTime)
+		return view.Items, nil
+	}
+	items, err := s.ItemsAt(ctx, w.UpdateTime)
+	if err != nil {
+		return nil, err
+	}
+	sortItems(items)
+	s.snapshot.store(items, w.UpdateTime)
+	return items, nil
+}
+
+// decodeItems unmarshals item documents, skipping any that do not decode and
+// those in the trash.
+func decodeItems(docs []*firestore.DocumentSnapshot) []Item {
+	return decodeItemDocs(docs, false)
+}
+
+// decodeItemDocs is decodeItems, keeping the items in the trash when
+// withDeleted is set.
+func decodeItemDocs(docs []*firestore.DocumentSnapshot, withDeleted bool) []Item {
+	items := make([]Item, 0, len(docs))
+	for _, d := range docs {
+		if !withDeleted && trashed(d) {
+			continue
+		}
+		var it Item
+		if err := d.DataTo(&it); err != nil {
+			log.Printf("warn: unmarshal item %q: %v", d.Ref.ID, err)
+			continue
+		}
+		items = append(items, it)
+	}
+	return items
+}
+
+// checkInput runs the content filter and then the rules over input before it
+// is written.
+func (s *Service) checkInput(input *ItemInput) error {
+	if err := s.filter.Apply(input); err != nil {
+		return err
+	}
+	return s.rules.Apply(input)
+}
+
+// newItem builds the document for a new item from its input, stamped at at
+// (see writeTime).
+func (s *Service) newItem(input ItemInput, at time.Time) Item {
+	item := Item{
+		ID:          s.newItemID(input.Name),
+		Name:        input.Name,
+		Quantity:    input.Quantity,
+		CreatedAt:   at,
+		UpdatedAt:   &at,
+		PackageSize: input.PackageSize,
+		ParentID:    input.ParentID,
+		Category:    nonEmpty(input.Category),
+		NeededBy:    nonZeroTime(input.NeededBy),
+		Tags:        input.Tags,
+		Priority:    nonEmpty(input.Priority),
+		Staple:      input.Staple != nil && *input.Staple,
+		Revision:    1,
+	}
+	if input.Price != nil && *input.Price > 0 {
+		item.Price, item.PriceCurrency = input.Price, input.Currency
+	}
+	withAmount(&item, s.profile)
+	return item
+}
+
+// UpsertItem creates a new item (if ID is empty) or updates an existing one,
+// returning the item's ID and the resulting list.
+func (s *Service) UpsertItem(ctx context.Context, input ItemInput) (ItemChange, []Item, error) {
+	change, _, items, _, err := s.upsertItem(ctx, input, true)
+	return change, items, err
+}
+
+// UpsertItemOnly is UpsertItem returning the item as written instead of the
+// list, so the list is not read: a new item costs no read at all, and an
+// update one. When an update lands but the item cannot be read back, the
+// item is returned as the update wrote it, with a read_back_failed warning.
+func (s *Service) UpsertItemOnly(ctx context.Context, input ItemInput) (ItemChange, *Item, ResponseWarnings, error) {
+	change, after, _, warnings, err := s.upsertItem(ctx, input, false)
+	return change, after, warnings, err
+}
+
+// upsertItem is UpsertItem, returning the list only when withList is set.
+func (s *Service) upsertItem(ctx context.Context, input ItemInput, withList bool) (ItemChange, *Item, []Item, ResponseWarnings, error) {
+	if err := s.checkInput(&input); err != nil {
+		return ItemChange{}, nil, nil, ResponseWarnings{}, err
+	}
+	if !input.Precondition.IsZero() && (input.ID == nil || *input.ID == "") {
+		return ItemChange{}, nil, nil, ResponseWarnings{}, fmt.Errorf("a precondition needs the id of the item to update")
+	}
+	var reopen bool
+	if (input.ID == nil || *input.ID == "") && s.derivedIDs {
+		// Adding a name already on the list updates that item, putting it
+		// back if it was checked off.
+		id := s.newItemID(input.Name)
+		existing, err := s.GetItem(ctx, id)
+		switch {
+		case err == nil:
+			input.ID, reopen = &id, existing.Purchased
+		case !errors.Is(err, ErrItemNotFound):
+			return ItemChange{}, nil, nil, ResponseWarnings{}, err
+		}
+	}
+	var (
+		id      string
+		action  string
+		before  *Item
+		created *Item
+		written *firestore.WriteResult
+	)
+	if input.ID == nil || *input.ID == "" {
+		// create
+		if err := s.checkNotFrozen(ctx); err != nil {
+			return ItemChange{}, nil, nil, ResponseWarnings{}, err
+		}
+		if input.ParentID != nil {
+			if err := s.validateParent(ctx, "", *input.ParentID); err != nil {
+				return ItemChange{}, nil, nil, ResponseWarnings{}, err
+			}
+		}
+		item := s.newItem(input, s.writeTime())
+		id, action = item.ID, ActionAdded
+		var err error
+		written, err = s.createItemDoc(ctx, s.client.Collection(s.collection).Doc(id), item)
+		if status.Code(err) == codes.AlreadyExists {
+			return ItemChange{}, nil, nil, ResponseWarnings{}, fmt.Errorf("create item: %q was added at the same time; retry to update it", input.Name)
+		}
+		if err != nil {
+			return ItemChange{}, nil, nil, ResponseWarnings{}, fmt.Errorf("create item: %w", err)
+		}
+		s.observe(ctx, activityAdd, 1)
+		s.recordActivity(ctx, ActionAdded, item)
+		s.stamped(&item, written.UpdateTime)
+		created = &item
+	} else {
+		// update
+		id, action = *input.ID, ActionUpdated
+		var (
+			doc *firestore.DocumentSnapshot
+			err error
+		)
+		if before, doc, err = s.getItemDoc(ctx, id); err != nil {
+			return ItemChange{}, nil, nil, ResponseWarnings{}, err
+		}
+		preconds, err := input.Precondition.preconditions(*before, doc)
+		if err != nil {
+			return ItemChange{}, nil, nil, ResponseWarnings{}, err
+		}
+		updates := []firestore.Update{
+			{Path: "name", Value: input.Name},
+		}
+		if input.Quantity != nil {
+			updates = append(updates, firestore.Update{Path: "quantity", Value: *input.Quantity})
+			updates = append(updates, amountUpdates(*input.Quantity, s.profile)...)
+		}
+		if input.PackageSize != nil {
+			updates = append(updates, firestore.Update{Path: "package_size", Value: *input.PackageSize})
+		}
+		if input.ParentID != nil {
+			if err := s.validateParent(ctx, id, *input.ParentID); err != nil {
+				return ItemChange{}, nil, nil, ResponseWarnings{}, err
+			}
+			updates = append(updates, firestore.Update{Path: "parent_id", Value: *input.ParentID})
+		}
+		if input.Category != nil {
+			var category any = firestore.Delete
+			if *input.Category != "" {
+				category = *input.Category
+			}
+			updates = append(updates, firestore.Update{Path: "category", Value: category})
+		}
+		if input.NeededBy != nil {
+			var neededBy any = firestore.Delete
+			if !input.NeededBy.IsZero() {
+				neededBy = *input.NeededBy
+			}
+			updates = append(updates, firestore.Update{Path: "needed_by", Value: neededBy})
+		}
+		if input.Tags != nil {
+			var tags any = firestore.Delete
+			if len(input.Tags) > 0 {
+				tags = input.Tags
+			}
+			updates = append(updates, firestore.Update{Path: "tags", Value: tags})
+		}
+		if input.Priority != nil {
+			var priority any = firestore.Delete
+			if *input.Priority != "" {
+				priority = *input.Priority
+			}
+			updates = append(updates, firestore.Update{Path: "priority", Value: priority})
+		}
+		if input.Price != nil {
+			var price, currency any = firestore.Delete, firestore.Delete
+			if *input.Price > 0 {
+				price, currency = *input.Price, input.Currency
+			}
+			updates = append(updates, firestore.Update{Path: "price", Value: price}, firestore.Update{Path: "price_currency", Value: currency})
+		}
+		if input.Staple != nil {
+			updates = append(updates, firestore.Update{Path: "staple", Value: *input.Staple})
+		}
+		if reopen {
+			updates = append(updates, uncheckUpdates()...)
+		}
+		if written, err = s.client.Collection(s.collection).Doc(id).Update(ctx, withRevision(updates, s.writeTime()), preconds...); err != nil {
+			return ItemChange{}, nil, nil, ResponseWarnings{}, fmt.Errorf("update item: %w", conflictOnPrecondition(id, err))
+		}
+		s.recordActivity(ctx, ActionUpdated, Item{ID: id, Name: input.Name})
+	}
+
+	// The write is audited with its diff once the item is read back, or
+	// without one when that read fails.
+	change := ItemChange{ID: id, Name: input.Name, Action: action}
+	defer func() { s.recordAudit(ctx, change) }()
+	if !withList {
+		var (
+			after    *Item
+			warnings ResponseWarnings
+		)
+		var landed *Item
+		if before != nil {
+			it := landedItem(*before, input, reopen, s.profile)
+			s.stamped(&it, written.UpdateTime)
+			landed = &it
+		}
+		change, after, warnings = writtenItem(change, before, created, landed, func() (*Item, error) { return s.GetItem(ctx, id) })
+		return change, after, nil, warnings, nil
+	}
+	items, err := s.itemsAfter(ctx, written)
+	if err != nil {
+		return change, nil, nil, ResponseWarnings{}, err
+	}
+	var after *Item
+	if i := slices.IndexFunc(items, func(it Item) bool { return it.ID == id }); i >= 0 {
+		after = &items[i]
+	}
+	change = newItemChange(action, before, after)
+	return change, after, items, ResponseWarnings{}, nil
+}
+
+// writtenItem returns the item a write left, created when the write created
+// it and otherwise read back with get, and change with the fields it
+// changed. The write has landed by then, so a failed read gives the landed
+// item, as the update wrote it, and a warning rather than an error that
+// would have the caller retry it.
+func writtenItem(change ItemChange, before, created, landed *Item, get func() (*Item, error)) (ItemChange, *Item, ResponseWarnings) {
+	var warnings ResponseWarnings
+	after := created
+	if after == nil {
+		var err error
+		if after, err = get(); err != nil {
+			warnings.Warn(WarnReadBackFailed, change.ID, "the item was written but could not be read back: %v", err)
+			after = landed
+		}
+	}
+	if after == nil {
+		return change, nil, warnings
+	}
+	return newItemChange(change.Action, before, after), after, warnings
+}
+
+// landedItem is before with input applied as upsertItem's update writes it,
+// for when the item cannot be read back.
+func landedItem(before Item, input ItemInput, reopen bool, p HouseholdProfile) Item {
+	it := before
+	it.Name = input.Name
+	if input.Quantity != nil {
+		it.Quantity = input.Quantity
+		withAmount(&it, p)
+	}
+	if input.PackageSize != nil {
+		it.PackageSize = input.PackageSize
+	}
+	if input.ParentID != nil {
+		it.ParentID = input.ParentID
+	}
+	if input.Category != nil {
+		it.Category = nil
+		if *input.Category != "" {
+			it.Category = input.Category
+		}
+	}
+	if input.NeededBy != nil {
+		it.NeededBy = nil
+		if !input.NeededBy.IsZero() {
+			it.NeededBy = input.NeededBy
+		}
+	}
+	if input.Tags != nil {
+		it.Tags = nil
+		if len(input.Tags) > 0 {
+			it.Tags = input.Tags
+		}
+	}
+	if input.Priority != nil {
+		it.Priority = nil
+		if *input.Priority != "" {
+			it.Priority = input.Priority
+		}
+	}
+	if input.Price != nil {
+		it.Price, it.PriceCurrency = nil, ""
+		if *input.Price > 0 {
+			it.Price, it.PriceCurrency = input.Price, input.Currency
+		}
+	}
+	if input.Staple != nil {
+		it.Staple = *input.Staple
+	}
+	if reopen {
+		it.Purchased, it.PurchasedAt = false, nil
+	}
+	it.Revision++
+	return it
+}
+
+// RenameItem changes only an item's name and returns the updated item.
+func (s *Service) RenameItem(ctx context.Context, id, name string) (*Item, error) {
+	input := ItemInput{Name: name}
+	if err := s.checkInput(&input); err != nil {
+		return nil, err
+	}
+	name = input.Name
+	before, err := s.GetItem(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	ref := s.client.Collection(s.collection).Doc(id)
+	if _, err := ref.Update(ctx, withRevision([]firestore.Update{{Path: "name", Value: name}}, s.writeTime())); err != nil {
+		return nil, fmt.Errorf("rename item: %w", err)
+	}
+	it, err := s.GetItem(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	s.recordActivity(ctx, ActionUpdated, *it)
+	s.recordAudit(ctx, newItemChange(ActionUpdated, before, it))
+	return it, nil
+}
+
+// RemoveItem deletes a document by ID and returns what changed and the
+// remaining list. Its children are deleted too when cascade is set, and
+// otherwise become top-level. An unknown ID gives an error wrapping
+// ErrItemNotFound.
+func (s *Service) RemoveItem(ctx context.Context, id string, cascade bool) ([]ItemChange, []Item, error) {
+	return s.RemoveItemIf(ctx, id, cascade, Precondition{})
+}
+
+// RemoveItemIf is RemoveItem guarded by pre: nothing is removed, and an
+// error wrapping ErrItemConflict is returned, when the item is gone or was
+// changed since the caller read it.
+func (s *Service) RemoveItemIf(ctx context.Context, id string, cascade bool, pre Precondition) ([]ItemChange, []Item, error) {
+	changes, err := s.RemoveItemOnly(ctx, id, cascade, pre)
+	if err != nil {
+		return nil, nil, err
+	}
+	items, err := s.ListItems(ctx)
+	return changes, items, err
+}
+
+// RemoveItemOnly is RemoveItemIf without reading the remaining list.
+func (s *Service) RemoveItemOnly(ctx context.Context, id string, cascade bool, pre Precondition) ([]ItemChange, error) {
+	removed, promoted, err := s.removeWithChildren(ctx, id, cascade, pre)
+	if err != nil {
+		return nil, fmt.Errorf("delete item: %w", conflictOnPrecondition(id, err))
+	}
+	s.observe(ctx, activityDelete, len(removed))
+	s.recordActivity(ctx, ActionRemoved, removed...)
+
+	changes := itemChanges(ActionRemoved, removed)
+	for _, it := range promoted {
+		after := it
+		after.ParentID = nil
+		changes = append(changes, newItemChange(ActionUpdated, &it, &after))
+	}
+	s.recordAudit(ctx, changes...)
+	return changes, nil
+}
+
+// deref returns the value of p, or "" when p is nil.
+func deref(p *string) string {
+	if p == nil {
+		return ""
+	}
+	return *p
+}
+
+// Collection is the Firestore collection holding the list's items.
+func (s *Service) Collection() string { return s.collection }
+
+// Normalizer is the pipeline item names are matched through.
+func (s *Service) Normalizer() *NameNormalizer { return s.normalizer }
+
+// Profile is the household's locale and measurement system.
+func (s *Service) Profile() HouseholdProfile { return s.profile }
+
+// Shadow is the migration target lists are mirrored to, or nil.
+func (s *Service) Shadow() *ShadowTarget { return s.shadow }
@@ -0,0 +1,117 @@
+package shoppinglist
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// -----------------------------------------------------------------------------
+// Stale read fallback
+// -----------------------------------------------------------------------------
+
+// listSnapshot is the last list successfully read from Firestore.
+type listSnapshot struct {
+	mu    sync.RWMutex
+	items []Item
+	at    time.Time
+
+	// served and missed count failed reads answered from the snapshot and
+	// those it was too old or empty to answer; oldest is the greatest age at
+	// which it was served.
+	served int64
+	missed int64
+	oldest time.Duration
+}
+
+func (c *listSnapshot) store(items []Item, at time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.items = append([]Item(nil), items...)
+	c.at = at
+}
+
+// load returns a copy of the snapshot if it is no older than maxAge.
+func (c *listSnapshot) load(now time.Time, maxAge time.Duration) ([]Item, time.Time, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.at.IsZero() || now.Sub(c.at) > maxAge {
+		return nil, time.Time{}, false
+	}
+	return append([]Item(nil), c.items...), c.at, true
+}
+
+// fellBack counts a failed read, answered from a snapshot of the given age
+// or, if !ok, not answered at all.
+func (c *listSnapshot) fellBack(age time.Duration, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !ok {
+		c.missed++
+		return
+	}
+	c.served++
+	c.oldest = max(c.oldest, age)
+}
+
+// StaleStats reports how the stale read fallback has answered failed reads
+// since the process started, so operators can tell how often and how stale
+// the data it serves is.
+type StaleStats struct {
+	// Served are failed reads answered from the last snapshot; Missed are
+	// failed reads with no snapshot recent enough to serve.
+	Served int64 `json:"served"`
+	Missed int64 `json:"missed"`
+	// OldestMS is the age of the stalest snapshot served.
+	OldestMS int64 `json:"oldest_ms"`
+}
+
+// StaleStats totals the fallback's counts across the main list and every
+// list served so far.
+func (s *Service) StaleStats() StaleStats {
+	r := s.Root()
+	snapshots := []*listSnapshot{r.snapshot}
+	r.scopedMu.Lock()
+	for _, scoped := range r.scoped {
+		snapshots = append(snapshots, scoped.snapshot)
+	}
+	r.scopedMu.Unlock()
+
+	var st StaleStats
+	var oldest time.Duration
+	for _, c := range snapshots {
+		c.mu.RLock()
+		st.Served += c.served
+		st.Missed += c.missed
+		oldest = max(oldest, c.oldest)
+		c.mu.RUnlock()
+	}
+	st.OldestMS = oldest.Milliseconds()
+	return st
+}
+
+// WithStaleFallback serves the last successful read, up to maxAge old, when
+// Firestore reads fail. A zero maxAge disables the fallback.
+func WithStaleFallback(maxAge time.Duration) ServiceOption {
+	return func(s *Service) { s.staleMaxAge = maxAge }
+}
+
+// ViewOrStale behaves like View, but when the read fails and a recent snapshot
+// exists it returns the snapshot's items along with the time it was taken.
+func (s *Service) ViewOrStale(ctx context.Context) (ListView, *time.Time, error) {
+	view, err := s.View(ctx)
+	if err == nil || s.staleMaxAge <= 0 {
+		return view, nil, err
+	}
+
+	now := s.Now()
+	cached, at, ok := s.snapshot.load(now, s.staleMaxAge)
+	s.snapshot.fellBack(now.Sub(at), ok)
+	if !ok {
+		return ListView{}, nil, err
+	}
+	st := s.StaleStats()
+	log.Printf("warn: serving list from %s after read failure (%d served, %d missed so far): %v", at.Format(time.RFC3339), st.Served, st.Missed, err)
+	return ListView{Items: cached, ReadTime: at}, &at, nil
+}
@@ -0,0 +1,54 @@
+package shoppinglist
+
+import (
+	"testing"
+	"time"
+)
+
+func TestListSnapshotRespectsMaxAge(t *testing.T) {
+	var snap listSnapshot
+	now := time.Date(2025, 8, 12, 12, 0, 0, 0, time.UTC)
+
+	if _, _, ok := snap.load(now, time.Hour); ok {
+		t.Fatal("expected empty snapshot to be unavailable")
+	}
+
+	snap.store([]Item{{ID: "1", Name: "milk"}}, now)
+	items, at, ok := snap.load(now.Add(5*time.Minute), 10*time.Minute)
+	if !ok || len(items) != 1 || !at.Equal(now) {
+		t.Fatalf("unexpected snapshot load: %v %v %v", items, at, ok)
+	}
+	if _, _, ok := snap.load(now.Add(11*time.Minute), 10*time.Minute); ok {
+		t.Fatal("expected snapshot older than max age to be unavailable")
+	}
+}
+
+func TestListSnapshotReturnsCopy(t *testing.T) {
+	var snap listSnapshot
+	now := time.Now()
+	snap.store([]Item{{ID: "1", Name: "milk"}}, now)
+
+	items, _, _ := snap.load(now, time.Minute)
+	items[0].Name = "changed"
+
+	again, _, _ := snap.load(now, time.Minute)
+	if again[0].Name != "milk" {
+		t.Fatal("expected snapshot to be isolated from callers")
+	}
+}
+
+func TestStaleStatsTotalsLists(t *testing.T) {
+	root := &Service{snapshot: &listSnapshot{}}
+	scoped := &Service{snapshot: &listSnapshot{}, parent: root}
+	root.scoped = map[string]*Service{"shopping_list_a": scoped}
+
+	root.snapshot.fellBack(2*time.Minute, true)
+	root.snapshot.fellBack(0, false)
+	scoped.snapshot.fellBack(7*time.Minute, true)
+	scoped.snapshot.fellBack(time.Minute, true)
+
+	st := scoped.StaleStats()
+	if st.Served != 3 || st.Missed != 1 || st.OldestMS != (7*time.Minute).Milliseconds() {
+		t.Fatalf("unexpected stats %+v", st)
+	}
+}
@@ -0,0 +1,46 @@
+package shoppinglist
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/firestore"
+)
+
+// -----------------------------------------------------------------------------
+// Stale items
+// -----------------------------------------------------------------------------
+
+// MaxStaleItems is the most stale items found, and so removed, at once; it
+// keeps a removal within one bulk removal.
+const MaxStaleItems = 200
+
+// StaleItemsResponse lists the items added before OlderThan, oldest first,
+// and which were deleted when asked to. More is set when there are further
+// stale items past these.
+type StaleItemsResponse struct {
+	Items     []Item    `json:"items"`
+	OlderThan time.Time `json:"older_than"`
+	More      bool      `json:"more,omitempty"`
+	Deleted   []string  `json:"deleted,omitempty"`
+	ResponseWarnings
+}
+
+// StaleItems returns up to limit items added before cutoff, oldest first,
+// with a Firestore range query on created_at so only they are read, and
+// whether there are more. Items written without created_at are not found.
+func (s *Service) StaleItems(ctx context.Context, cutoff time.Time, limit int) ([]Item, bool, error) {
+	if limit < 1 || limit > MaxStaleItems {
+		return nil, false, fmt.Errorf("limit must be between 1 and %d", MaxStaleItems)
+	}
+	q := s.client.Collection(s.collection).
+		Where("created_at", "<", cutoff).
+		OrderBy("created_at", firestore.Asc)
+	docs, err := liveDocuments(ctx, nil, q, limit+1)
+	if err != nil {
+		return nil, false, fmt.Errorf("retrieve stale items: %w", err)
+	}
+	items, more := pageOf(decodeItems(docs), limit)
+	return items, more, nil
+}
@@ -0,0 +1,60 @@
+package shoppinglist
+
+import (
+	"context"
+	"fmt"
+	"slices"
+)
+
+// -----------------------------------------------------------------------------
+// Purchased status filter
+// -----------------------------------------------------------------------------
+
+// Purchased statuses a listing can be narrowed to.
+const (
+	StatusPending   = "pending"
+	StatusPurchased = "purchased"
+	StatusAll       = "all"
+)
+
+// Statuses are the statuses ViewStatus accepts.
+var Statuses = []string{StatusPending, StatusPurchased, StatusAll}
+
+// FilterStatus keeps the items with status. Items without a purchased field,
+// such as those written before it existed, decode as pending.
+func FilterStatus(items []Item, status string) []Item {
+	if status == StatusAll {
+		return items
+	}
+	out := make([]Item, 0, len(items))
+	for _, it := range items {
+		if it.Purchased == (status == StatusPurchased) {
+			out = append(out, it)
+		}
+	}
+	return out
+}
+
+// ViewStatus reads like View only the items with status: those still to buy
+// (pending), those checked off (purchased), or every item (all). Items
+// without a purchased field count as pending, as in CountItems. Checked-off
+// items are selected by a Firestore where clause on purchased; a where
+// clause cannot match a missing field, so a pending listing reads the whole
+// list and leaves out those checked off.
+func (s *Service) ViewStatus(ctx context.Context, status string) (ListView, error) {
+	if !slices.Contains(Statuses, status) {
+		return ListView{}, fmt.Errorf("unsupported status %q", status)
+	}
+	switch status {
+	case StatusAll:
+		return s.View(ctx)
+	case StatusPurchased:
+		return s.viewOf(ctx, s.client.Collection(s.collection).Where("purchased", "==", true), 0)
+	}
+	view, err := s.View(ctx)
+	if err != nil {
+		return ListView{}, err
+	}
+	view.Items = FilterStatus(view.Items, status)
+	return view, nil
+}
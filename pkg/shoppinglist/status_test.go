@@ -0,0 +1,35 @@
+package shoppinglist
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestFilterStatusCountsMissingAsPending(t *testing.T) {
+	// An item decoded from a document without a purchased field has it unset.
+	items := []Item{{ID: "legacy"}, {ID: "open", Purchased: false}, {ID: "done", Purchased: true}}
+	ids := func(items []Item) []string {
+		out := []string{}
+		for _, it := range items {
+			out = append(out, it.ID)
+		}
+		return out
+	}
+
+	for status, want := range map[string][]string{
+		StatusPending:   {"legacy", "open"},
+		StatusPurchased: {"done"},
+		StatusAll:       {"legacy", "open", "done"},
+	} {
+		if got := ids(FilterStatus(items, status)); !reflect.DeepEqual(got, want) {
+			t.Errorf("FilterStatus(%s) = %v, want %v", status, got, want)
+		}
+	}
+}
+
+func TestViewStatusRejectsUnknownStatus(t *testing.T) {
+	if _, err := (&Service{}).ViewStatus(context.Background(), "bought"); err == nil {
+		t.Fatal("expected an unsupported status to be refused")
+	}
+}
@@ -0,0 +1,137 @@
+package shoppinglist
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// -----------------------------------------------------------------------------
+// List summaries
+// -----------------------------------------------------------------------------
+
+// SummaryCounts breaks the whole list down for a summary.
+type SummaryCounts struct {
+	Total      int            `json:"total"`
+	ToBuy      int            `json:"to_buy"`
+	Purchased  int            `json:"purchased"`
+	ByCategory map[string]int `json:"by_category,omitempty"`
+	ByGroup    map[string]int `json:"by_group,omitempty"`
+}
+
+// ListSummaryResponse is a page of the list in summary order. The first page
+// carries counts for the whole list; NextCursor continues with full items.
+type ListSummaryResponse struct {
+	Items       []Item         `json:"items"`
+	Counts      *SummaryCounts `json:"counts,omitempty"`
+	NextCursor  string         `json:"next_cursor,omitempty"`
+	ReadTime    *time.Time     `json:"read_time,omitempty"`
+	FrozenUntil *time.Time     `json:"frozen_until,omitempty"`
+	StaleAsOf   *time.Time     `json:"stale_as_of,omitempty"`
+	ResponseWarnings
+}
+
+// summaryCursor marks a position in the summary order of the list as read at
+// ReadTime.
+type summaryCursor struct {
+	ReadTime time.Time `json:"t"`
+	Offset   int       `json:"o"`
+	Size     int       `json:"n"`
+	Tags     []string  `json:"g,omitempty"`
+}
+
+func encodeCursor(c summaryCursor) string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func DecodeCursor(s string) (summaryCursor, error) {
+	var c summaryCursor
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err == nil {
+		err = json.Unmarshal(b, &c)
+	}
+	if err != nil || c.ReadTime.IsZero() || c.Offset < 0 || c.Size <= 0 {
+		return summaryCursor{}, fmt.Errorf("invalid cursor %q", s)
+	}
+	return c, nil
+}
+
+// summaryOrder ranks items for a summary: items still to buy first, then the
+// most urgent, then the most recently added.
+func summaryOrder(items []Item) []Item {
+	out := append([]Item(nil), items...)
+	sort.SliceStable(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if a.Purchased != b.Purchased {
+			return !a.Purchased
+		}
+		if c := comparePriority(a, b); c != 0 {
+			return c < 0
+		}
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.After(b.CreatedAt)
+		}
+		return a.ID < b.ID
+	})
+	return out
+}
+
+// countItems tallies items by purchase state, by category, and by the parent
+// they are nested under.
+func countItems(items []Item) SummaryCounts {
+	names := make(map[string]string, len(items))
+	for _, it := range items {
+		names[it.ID] = it.Name
+	}
+	counts := SummaryCounts{Total: len(items), ByCategory: map[string]int{}}
+	for _, it := range items {
+		if it.Purchased {
+			counts.Purchased++
+		} else {
+			counts.ToBuy++
+		}
+		counts.ByCategory[categoryOf(it)]++
+		if it.ParentID == nil {
+			continue
+		}
+		if name, ok := names[*it.ParentID]; ok {
+			if counts.ByGroup == nil {
+				counts.ByGroup = map[string]int{}
+			}
+			counts.ByGroup[name]++
+		}
+	}
+	return counts
+}
+
+// Summarize returns the top n items in compact form, counts for the whole
+// list, and a cursor to the rest. The cursor keeps the tags the items were
+// filtered by, so later pages apply the same filter.
+func Summarize(items []Item, n int, readTime time.Time, tags []string) ListSummaryResponse {
+	ordered := summaryOrder(items)
+	counts := countItems(items)
+	top := ordered[:min(n, len(ordered))]
+	resp := ListSummaryResponse{
+		Items:  ApplyPreferences(top, SessionPreferences{Verbosity: "compact"}),
+		Counts: &counts,
+	}
+	if len(top) < len(ordered) {
+		resp.NextCursor = encodeCursor(summaryCursor{ReadTime: readTime, Offset: len(top), Size: n, Tags: tags})
+	}
+	return resp
+}
+
+// SummaryPage returns the items at the cursor and a cursor to the next page.
+func SummaryPage(items []Item, c summaryCursor) ([]Item, string) {
+	ordered := summaryOrder(items)
+	start := min(c.Offset, len(ordered))
+	end := min(start+c.Size, len(ordered))
+	next := ""
+	if end < len(ordered) {
+		next = encodeCursor(summaryCursor{ReadTime: c.ReadTime, Offset: end, Size: c.Size, Tags: c.Tags})
+	}
+	return ordered[start:end], next
+}
@@ -0,0 +1,82 @@
+package shoppinglist
+
+import (
+	"testing"
+	"time"
+)
+
+func summaryFixture() []Item {
+	base := time.Date(2025, 8, 1, 9, 0, 0, 0, time.UTC)
+	taco := "taco"
+	return []Item{
+		{ID: "taco", Name: "Taco night", CreatedAt: base},
+		{ID: "tortillas", Name: "tortillas", CreatedAt: base.Add(time.Minute), ParentID: &taco},
+		{ID: "salsa", Name: "salsa", CreatedAt: base.Add(2 * time.Minute), ParentID: &taco, Purchased: true},
+		{ID: "milk", Name: "milk", CreatedAt: base.Add(3 * time.Minute)},
+		{ID: "eggs", Name: "eggs", CreatedAt: base.Add(4 * time.Minute), Purchased: true},
+	}
+}
+
+func TestSummarizeRanksAndCounts(t *testing.T) {
+	readTime := time.Date(2025, 8, 1, 10, 0, 0, 0, time.UTC)
+	resp := Summarize(summaryFixture(), 2, readTime, nil)
+
+	if len(resp.Items) != 2 || resp.Items[0].ID != "milk" || resp.Items[1].ID != "tortillas" {
+		t.Fatalf("expected newest items to buy first, got %+v", resp.Items)
+	}
+	if !resp.Items[0].CreatedAt.IsZero() {
+		t.Fatalf("expected compact items, got %+v", resp.Items[0])
+	}
+	c := resp.Counts
+	if c.Total != 5 || c.ToBuy != 3 || c.Purchased != 2 || c.ByGroup["Taco night"] != 2 {
+		t.Fatalf("unexpected counts: %+v", c)
+	}
+	if resp.NextCursor == "" {
+		t.Fatal("expected a cursor to the remaining items")
+	}
+}
+
+func TestSummaryCursorWalksTheRest(t *testing.T) {
+	readTime := time.Date(2025, 8, 1, 10, 0, 0, 0, time.UTC)
+	items := summaryFixture()
+	resp := Summarize(items, 2, readTime, nil)
+
+	var seen []string
+	for raw := resp.NextCursor; raw != ""; {
+		c, err := DecodeCursor(raw)
+		if err != nil {
+			t.Fatalf("decodeCursor returned error: %v", err)
+		}
+		if !c.ReadTime.Equal(readTime) {
+			t.Fatalf("expected the cursor to keep the summary's read time, got %v", c.ReadTime)
+		}
+		var page []Item
+		page, raw = SummaryPage(items, c)
+		for _, it := range page {
+			seen = append(seen, it.ID)
+		}
+	}
+	want := []string{"taco", "eggs", "salsa"}
+	if len(seen) != len(want) {
+		t.Fatalf("expected %v, got %v", want, seen)
+	}
+	for i := range want {
+		if seen[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, seen)
+		}
+	}
+}
+
+func TestSummarizeSmallListHasNoCursor(t *testing.T) {
+	if resp := Summarize(summaryFixture(), 10, time.Now(), nil); resp.NextCursor != "" {
+		t.Fatalf("expected no cursor when everything fits, got %q", resp.NextCursor)
+	}
+}
+
+func TestDecodeCursorRejectsGarbage(t *testing.T) {
+	for _, raw := range []string{"not-base64!", "e30", encodeCursor(summaryCursor{ReadTime: time.Now(), Offset: 1})} {
+		if _, err := DecodeCursor(raw); err == nil {
+			t.Errorf("expected an error for %q", raw)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package shoppinglist
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// -----------------------------------------------------------------------------
+// Tags
+// -----------------------------------------------------------------------------
+
+// normalizeTags lower-cases tags, collapses their whitespace, and drops empty
+// and repeated ones, keeping the first-seen order.
+func normalizeTags(tags []string) []string {
+	out := make([]string, 0, len(tags))
+	for _, t := range tags {
+		t = strings.ToLower(strings.Join(strings.Fields(t), " "))
+		if t != "" && !slices.Contains(out, t) {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// ParseTags reads a tool argument holding an array of tag strings.
+func ParseTags(name string, raw any) ([]string, error) {
+	values, ok := raw.([]any)
+	if !ok {
+		return nil, fmt.Errorf("'%s' must be an array of strings", name)
+	}
+	tags := make([]string, 0, len(values))
+	for _, v := range values {
+		t, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("'%s' must be an array of strings", name)
+		}
+		tags = append(tags, t)
+	}
+	return normalizeTags(tags), nil
+}
+
+// hasTags reports whether the item carries every one of tags.
+func hasTags(it Item, tags []string) bool {
+	for _, t := range tags {
+		if !slices.Contains(it.Tags, t) {
+			return false
+		}
+	}
+	return true
+}
+
+// FilterTagged returns the items carrying every one of tags.
+func FilterTagged(items []Item, tags []string) []Item {
+	out := make([]Item, 0, len(items))
+	for _, it := range items {
+		if hasTags(it, tags) {
+			out = append(out, it)
+		}
+	}
+	return out
+}
+
+// ViewTagged reads the items carrying every one of tags, with the freeze
+// state, at one read time. Firestore allows a single array-contains filter
+// per query, so it selects on the first tag and the rest are checked here.
+func (s *Service) ViewTagged(ctx context.Context, tags []string) (ListView, error) {
+	q := s.client.Collection(s.collection).Where("tags", "array-contains", tags[0])
+	view, err := s.viewOf(ctx, q, 0)
+	if err != nil {
+		return ListView{}, err
+	}
+	view.Items = FilterTagged(view.Items, tags[1:])
+	return view, nil
+}
@@ -0,0 +1,58 @@
+package shoppinglist
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNormalizeTags(t *testing.T) {
+	got := normalizeTags([]string{" Party ", "urgent", "party", "", "  ", "Back  To School"})
+	want := []string{"party", "urgent", "back to school"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("normalizeTags = %v, want %v", got, want)
+	}
+}
+
+func TestParseTags(t *testing.T) {
+	got, err := ParseTags("tags", []any{"Party", "urgent"})
+	if err != nil || !reflect.DeepEqual(got, []string{"party", "urgent"}) {
+		t.Fatalf("parseTags = %v, %v", got, err)
+	}
+	if got, err := ParseTags("tags", []any{}); err != nil || got == nil || len(got) != 0 {
+		t.Fatalf("expected an empty, non-nil slice to clear tags, got %#v, %v", got, err)
+	}
+	for _, raw := range []any{"party", []any{"party", 3}} {
+		if _, err := ParseTags("tags", raw); err == nil {
+			t.Fatalf("expected an error for %#v", raw)
+		}
+	}
+}
+
+func TestFilterTagged(t *testing.T) {
+	items := []Item{
+		{ID: "1", Name: "balloons", Tags: []string{"party", "urgent"}},
+		{ID: "2", Name: "cake", Tags: []string{"party"}},
+		{ID: "3", Name: "milk"},
+	}
+	if got := FilterTagged(items, []string{"party", "urgent"}); len(got) != 1 || got[0].ID != "1" {
+		t.Fatalf("expected only balloons, got %v", got)
+	}
+	if got := FilterTagged(items, nil); len(got) != 3 {
+		t.Fatalf("expected every item without tags to match, got %v", got)
+	}
+}
+
+func TestSummaryCursorKeepsTags(t *testing.T) {
+	items := []Item{{ID: "1", Name: "a"}, {ID: "2", Name: "b"}, {ID: "3", Name: "c"}}
+	resp := Summarize(items, 1, time.Date(2025, 8, 1, 10, 0, 0, 0, time.UTC), []string{"party"})
+	c, err := DecodeCursor(resp.NextCursor)
+	if err != nil {
+		t.Fatalf("decodeCursor returned error: %v", err)
+	}
+	_, raw := SummaryPage(items, c)
+	next, err := DecodeCursor(raw)
+	if err != nil || !reflect.DeepEqual(next.Tags, []string{"party"}) {
+		t.Fatalf("expected the next cursor to keep the tags, got %+v, %v", next, err)
+	}
+}
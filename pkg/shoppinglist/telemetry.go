@@ -0,0 +1,195 @@
+package shoppinglist
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/firestore"
+)
+
+// -----------------------------------------------------------------------------
+// Tool telemetry
+// -----------------------------------------------------------------------------
+
+// defaultLatencyBudget is the LatencyBudgets key applied to tools without
+// their own budget.
+const defaultLatencyBudget = "default"
+
+// MaxTelemetryExecutions is the most executions one telemetry report reads.
+const MaxTelemetryExecutions = 1000
+
+// ToolExecution records one tool call: how long it took, the Firestore
+// operations it made, and how large its result was.
+type ToolExecution struct {
+	ID          string          `json:"id" firestore:"id"`
+	Tool        string          `json:"tool" firestore:"tool"`
+	List        string          `json:"list,omitempty" firestore:"list,omitempty"`
+	StartedAt   time.Time       `json:"started_at" firestore:"started_at"`
+	DurationMS  int64           `json:"duration_ms" firestore:"duration_ms"`
+	Operations  OperationCounts `json:"operations" firestore:"operations"`
+	ResultBytes int             `json:"result_bytes" firestore:"result_bytes"`
+	Error       bool            `json:"error,omitempty" firestore:"error"`
+	BudgetMS    int64           `json:"budget_ms,omitempty" firestore:"budget_ms,omitempty"`
+	OverBudget  bool            `json:"over_budget,omitempty" firestore:"over_budget"`
+
+	ExpireAt *time.Time `json:"-" firestore:"expire_at,omitempty"`
+}
+
+// LatencyBudgets are how long each tool may take before its calls are
+// flagged as over budget; the "default" entry covers tools not listed.
+type LatencyBudgets map[string]time.Duration
+
+// ParseLatencyBudgets parses "list_items=500ms,default=2s". An empty string
+// sets no budgets.
+func ParseLatencyBudgets(spec string) (LatencyBudgets, error) {
+	budgets := LatencyBudgets{}
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		tool, d, ok := strings.Cut(part, "=")
+		tool = strings.TrimSpace(tool)
+		if !ok || tool == "" {
+			return nil, fmt.Errorf("latency budget %q: expected TOOL=DURATION", part)
+		}
+		budget, err := time.ParseDuration(strings.TrimSpace(d))
+		if err != nil || budget <= 0 {
+			return nil, fmt.Errorf("latency budget %q: expected a positive duration such as 500ms", part)
+		}
+		budgets[tool] = budget
+	}
+	return budgets, nil
+}
+
+// Budget is how long tool may take, or zero when it has no budget.
+func (b LatencyBudgets) Budget(tool string) time.Duration {
+	if d, ok := b[tool]; ok {
+		return d
+	}
+	return b[defaultLatencyBudget]
+}
+
+// telemetryCollection holds the executions of every list's tool calls.
+func (s *Service) telemetryCollection() *firestore.CollectionRef {
+	r := s.Root()
+	return r.client.Collection(r.collection + "_telemetry")
+}
+
+// RecordExecution stores e in <collection>_telemetry, stamped with
+// expire_at when the retention policy limits telemetry.
+func (s *Service) RecordExecution(ctx context.Context, e ToolExecution) error {
+	r := s.Root()
+	e.ID = r.NewID()
+	e.ExpireAt = r.retention.expireAt("telemetry", e.StartedAt)
+	if _, err := r.telemetryCollection().Doc(e.ID).Set(ctx, e); err != nil {
+		return fmt.Errorf("record execution of %s: %w", e.Tool, err)
+	}
+	return nil
+}
+
+// ToolExecutions returns up to limit executions started at or after since,
+// newest first.
+func (s *Service) ToolExecutions(ctx context.Context, since time.Time, limit int) ([]ToolExecution, error) {
+	docs, err := s.telemetryCollection().
+		Where("started_at", ">=", since).
+		OrderBy("started_at", firestore.Desc).
+		Limit(limit).
+		Documents(ctx).GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("retrieve tool executions: %w", err)
+	}
+	execs := make([]ToolExecution, 0, len(docs))
+	for _, d := range docs {
+		var e ToolExecution
+		if err := d.DataTo(&e); err != nil {
+			return nil, fmt.Errorf("decode tool execution %s: %w", d.Ref.ID, err)
+		}
+		execs = append(execs, e)
+	}
+	return execs, nil
+}
+
+// ToolStats sums up the recorded calls of one tool.
+type ToolStats struct {
+	Tool           string  `json:"tool"`
+	Calls          int     `json:"calls"`
+	Errors         int     `json:"errors,omitempty"`
+	OverBudget     int     `json:"over_budget,omitempty"`
+	TotalMS        int64   `json:"total_ms"`
+	P50MS          int64   `json:"p50_ms"`
+	P95MS          int64   `json:"p95_ms"`
+	MaxMS          int64   `json:"max_ms"`
+	AvgReads       float64 `json:"avg_reads"`
+	AvgWrites      float64 `json:"avg_writes"`
+	AvgDeletes     float64 `json:"avg_deletes"`
+	AvgResultBytes float64 `json:"avg_result_bytes"`
+}
+
+// TelemetryReport sums up recorded tool calls per tool, most total time
+// first, with the slowest calls.
+type TelemetryReport struct {
+	Since      time.Time       `json:"since"`
+	Executions int             `json:"executions"`
+	Truncated  bool            `json:"truncated,omitempty"`
+	Tools      []ToolStats     `json:"tools"`
+	Slowest    []ToolExecution `json:"slowest,omitempty"`
+	ResponseWarnings
+}
+
+// SummarizeExecutions builds a report from execs, keeping the slowest n.
+func SummarizeExecutions(execs []ToolExecution, since time.Time, slowest int) TelemetryReport {
+	report := TelemetryReport{Since: since, Executions: len(execs), Tools: []ToolStats{}}
+	byTool := map[string][]ToolExecution{}
+	for _, e := range execs {
+		byTool[e.Tool] = append(byTool[e.Tool], e)
+	}
+	for tool, calls := range byTool {
+		st := ToolStats{Tool: tool, Calls: len(calls)}
+		durations := make([]int64, 0, len(calls))
+		var reads, writes, deletes, bytes int64
+		for _, e := range calls {
+			durations = append(durations, e.DurationMS)
+			st.TotalMS += e.DurationMS
+			reads += e.Operations.Reads
+			writes += e.Operations.Writes
+			deletes += e.Operations.Deletes
+			bytes += int64(e.ResultBytes)
+			if e.Error {
+				st.Errors++
+			}
+			if e.OverBudget {
+				st.OverBudget++
+			}
+		}
+		sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+		st.P50MS = percentile(durations, 50)
+		st.P95MS = percentile(durations, 95)
+		st.MaxMS = durations[len(durations)-1]
+		n := float64(len(calls))
+		st.AvgReads, st.AvgWrites, st.AvgDeletes = float64(reads)/n, float64(writes)/n, float64(deletes)/n
+		st.AvgResultBytes = float64(bytes) / n
+		report.Tools = append(report.Tools, st)
+	}
+	sort.Slice(report.Tools, func(i, j int) bool {
+		a, b := report.Tools[i], report.Tools[j]
+		if a.TotalMS != b.TotalMS {
+			return a.TotalMS > b.TotalMS
+		}
+		return a.Tool < b.Tool
+	})
+
+	report.Slowest = append([]ToolExecution(nil), execs...)
+	sort.SliceStable(report.Slowest, func(i, j int) bool { return report.Slowest[i].DurationMS > report.Slowest[j].DurationMS })
+	report.Slowest = report.Slowest[:min(len(report.Slowest), slowest)]
+	return report
+}
+
+// percentile is the nearest-rank pth percentile of sorted durations.
+func percentile(sorted []int64, p int) int64 {
+	rank := (p*len(sorted) + 99) / 100
+	return sorted[max(rank, 1)-1]
+}
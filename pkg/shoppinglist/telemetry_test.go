@@ -0,0 +1,47 @@
+package shoppinglist
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseLatencyBudgetsFallsBackToDefault(t *testing.T) {
+	b, err := ParseLatencyBudgets("list_items=500ms, default=2s")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := b.Budget("list_items"); got != 500*time.Millisecond {
+		t.Fatalf("list_items budget %s, want 500ms", got)
+	}
+	if got := b.Budget("export_list"); got != 2*time.Second {
+		t.Fatalf("export_list budget %s, want the 2s default", got)
+	}
+	if _, err := ParseLatencyBudgets("list_items=fast"); err == nil {
+		t.Fatal("expected an invalid duration to be refused")
+	}
+}
+
+func TestSummarizeExecutionsRanksByTotalTime(t *testing.T) {
+	since := time.Date(2025, 8, 12, 0, 0, 0, 0, time.UTC)
+	execs := []ToolExecution{
+		{Tool: "list_items", DurationMS: 40, Operations: OperationCounts{Reads: 12}, ResultBytes: 900},
+		{Tool: "list_items", DurationMS: 60, Operations: OperationCounts{Reads: 8}, ResultBytes: 1100, OverBudget: true},
+		{Tool: "export_list", DurationMS: 300, Operations: OperationCounts{Reads: 200}, Error: true},
+		{Tool: "upsert_item", DurationMS: 20, Operations: OperationCounts{Reads: 1, Writes: 2}},
+	}
+	report := SummarizeExecutions(execs, since, 2)
+
+	if report.Executions != 4 || len(report.Tools) != 3 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	if report.Tools[0].Tool != "export_list" || report.Tools[1].Tool != "list_items" {
+		t.Fatalf("expected export_list then list_items, got %+v", report.Tools)
+	}
+	li := report.Tools[1]
+	if li.Calls != 2 || li.P50MS != 40 || li.MaxMS != 60 || li.AvgReads != 10 || li.AvgResultBytes != 1000 || li.OverBudget != 1 {
+		t.Fatalf("unexpected list_items stats %+v", li)
+	}
+	if len(report.Slowest) != 2 || report.Slowest[0].Tool != "export_list" || report.Slowest[1].DurationMS != 60 {
+		t.Fatalf("unexpected slowest calls %+v", report.Slowest)
+	}
+}
@@ -0,0 +1,90 @@
+package shoppinglist
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// -----------------------------------------------------------------------------
+// Throttling
+// -----------------------------------------------------------------------------
+
+// defaultRetryAfter is how long to back off from a ResourceExhausted error
+// that neither says how long to wait nor names the daily quota.
+const defaultRetryAfter = time.Second
+
+// ThrottleWatch notes the Firestore calls made on behalf of one context that
+// were refused with ResourceExhausted, and how long to wait before retrying.
+type ThrottleWatch struct {
+	mu         sync.Mutex
+	retryAfter time.Duration
+	throttled  bool
+}
+
+type throttleKey struct{}
+
+// WithThrottleWatch returns a context whose throttled Firestore calls are
+// noted in the returned watch.
+func WithThrottleWatch(ctx context.Context) (context.Context, *ThrottleWatch) {
+	w := &ThrottleWatch{}
+	return context.WithValue(ctx, throttleKey{}, w), w
+}
+
+// noteThrottled records err on the watch of ctx when it is a ResourceExhausted
+// error, keeping the longest wait seen.
+func noteThrottled(ctx context.Context, err error, now time.Time) {
+	w, _ := ctx.Value(throttleKey{}).(*ThrottleWatch)
+	if w == nil {
+		return
+	}
+	d, ok := RetryAfter(err, now)
+	if !ok {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.throttled = true
+	w.retryAfter = max(w.retryAfter, d)
+}
+
+// RetryAfter returns how long to wait before retrying, and whether any call
+// was throttled.
+func (w *ThrottleWatch) RetryAfter() (time.Duration, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.retryAfter, w.throttled
+}
+
+// RetryAfter reports how long to wait before retrying after err, when err is
+// a ResourceExhausted error: the delay Firestore asked for in its RetryInfo,
+// else a minute for a per-minute quota, else until the daily quota resets at
+// midnight Pacific time when the error names a quota, else a second.
+func RetryAfter(err error, now time.Time) (time.Duration, bool) {
+	st, ok := status.FromError(err)
+	if !ok || st.Code() != codes.ResourceExhausted {
+		return 0, false
+	}
+	for _, d := range st.Details() {
+		if info, ok := d.(*errdetails.RetryInfo); ok && info.GetRetryDelay() != nil {
+			return info.GetRetryDelay().AsDuration(), true
+		}
+	}
+	msg := strings.ToLower(st.Message())
+	switch {
+	case strings.Contains(msg, "per minute"):
+		return time.Minute, true
+	case strings.Contains(msg, "per second"):
+		return defaultRetryAfter, true
+	case strings.Contains(msg, "quota"):
+		local := now.In(quotaZone)
+		reset := time.Date(local.Year(), local.Month(), local.Day()+1, 0, 0, 0, 0, quotaZone)
+		return reset.Sub(now), true
+	}
+	return defaultRetryAfter, true
+}
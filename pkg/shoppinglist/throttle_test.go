@@ -0,0 +1,54 @@
+package shoppinglist
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2024, 5, 1, 22, 30, 0, 0, quotaZone)
+	withInfo, err := status.New(codes.ResourceExhausted, "slow down").WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(3 * time.Second)})
+	if err != nil {
+		t.Fatalf("WithDetails returned error: %v", err)
+	}
+	cases := []struct {
+		err  error
+		want time.Duration
+		ok   bool
+	}{
+		{withInfo.Err(), 3 * time.Second, true},
+		{status.Error(codes.ResourceExhausted, "Quota exceeded."), 90 * time.Minute, true},
+		{status.Error(codes.ResourceExhausted, "Quota exceeded for quota metric 'Write requests' per minute"), time.Minute, true},
+		{status.Error(codes.ResourceExhausted, "too much contention"), time.Second, true},
+		{status.Error(codes.Unavailable, "try again"), 0, false},
+		{errors.New("plain"), 0, false},
+	}
+	for _, c := range cases {
+		got, ok := RetryAfter(c.err, now)
+		if got != c.want || ok != c.ok {
+			t.Errorf("RetryAfter(%v) = %s, %v; want %s, %v", c.err, got, ok, c.want, c.ok)
+		}
+	}
+}
+
+func TestThrottleWatchKeepsLongestWait(t *testing.T) {
+	ctx, watch := WithThrottleWatch(context.Background())
+	if _, throttled := watch.RetryAfter(); throttled {
+		t.Fatal("expected a fresh watch not to be throttled")
+	}
+	now := time.Now()
+	noteThrottled(ctx, status.Error(codes.ResourceExhausted, "per minute"), now)
+	noteThrottled(ctx, status.Error(codes.ResourceExhausted, "busy"), now)
+	noteThrottled(ctx, status.Error(codes.NotFound, "missing"), now)
+	if d, throttled := watch.RetryAfter(); !throttled || d != time.Minute {
+		t.Fatalf("got %s, %v; want 1m, true", d, throttled)
+	}
+	noteThrottled(context.Background(), status.Error(codes.ResourceExhausted, "busy"), now)
+}
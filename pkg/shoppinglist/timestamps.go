@@ -0,0 +1,86 @@
+package shoppinglist
+
+import (
+	"time"
+
+	"cloud.google.com/go/firestore"
+)
+
+// -----------------------------------------------------------------------------
+// Server timestamps
+// -----------------------------------------------------------------------------
+
+// createdItemDoc is a new item whose created_at and updated_at Firestore
+// stamps with the commit time. Its fields shadow those of the embedded Item.
+type createdItemDoc struct {
+	Item
+	CreatedAt time.Time `firestore:"created_at,serverTimestamp"`
+	UpdatedAt time.Time `firestore:"updated_at,serverTimestamp"`
+}
+
+// updatedItemDoc is an item rewritten whole, e.g. moved to another list,
+// whose updated_at Firestore stamps with the commit time.
+type updatedItemDoc struct {
+	Item
+	UpdatedAt time.Time `firestore:"updated_at,serverTimestamp"`
+}
+
+// deletedTombstoneDoc is a tombstone whose deleted_at Firestore stamps with
+// the commit time.
+type deletedTombstoneDoc struct {
+	Tombstone
+	DeletedAt time.Time `firestore:"deleted_at,serverTimestamp"`
+}
+
+// writeTime is the time items are stamped with when written. It is zero, so
+// Firestore stamps them with the commit time and they do not depend on the
+// host clock, unless the service runs on a frozen clock, whose time is
+// written instead so runs against the emulator stay repeatable.
+func (s *Service) writeTime() time.Time {
+	if _, frozen := s.clock.(*FrozenClock); frozen {
+		return s.Now()
+	}
+	return time.Time{}
+}
+
+// itemDoc is the document written for it: as it is when its updated_at is
+// set, else with the zero times left for Firestore to stamp.
+func itemDoc(it Item) any {
+	switch {
+	case it.UpdatedAt != nil && !it.UpdatedAt.IsZero():
+		return it
+	case it.CreatedAt.IsZero():
+		return createdItemDoc{Item: it}
+	default:
+		return updatedItemDoc{Item: it}
+	}
+}
+
+// tombstoneDoc is the document written for t, like itemDoc.
+func tombstoneDoc(t Tombstone) any {
+	if t.DeletedAt.IsZero() {
+		return deletedTombstoneDoc{Tombstone: t}
+	}
+	return t
+}
+
+// stampValue is what updated_at is set to by an update stamped at at.
+func stampValue(at time.Time) any {
+	if at.IsZero() {
+		return firestore.ServerTimestamp
+	}
+	return at
+}
+
+// stamped fills in the times Firestore gave it when it was written in a
+// commit at commit, so responses built without reading it back show them.
+func (s *Service) stamped(it *Item, commit time.Time) {
+	at := s.writeTime()
+	if at.IsZero() {
+		at = commit.UTC()
+	}
+	if it.CreatedAt.IsZero() {
+		it.CreatedAt = at
+	}
+	it.UpdatedAt = &at
+}
@@ -0,0 +1,51 @@
+package shoppinglist
+
+import (
+	"testing"
+	"time"
+
+	"cloud.google.com/go/firestore"
+)
+
+func TestItemDocLeavesZeroTimesToFirestore(t *testing.T) {
+	created := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	var zero time.Time
+
+	if _, ok := itemDoc(Item{ID: "a", UpdatedAt: &zero}).(createdItemDoc); !ok {
+		t.Fatal("expected a new item to be stamped by Firestore")
+	}
+	if _, ok := itemDoc(Item{ID: "a", CreatedAt: created, UpdatedAt: &zero}).(updatedItemDoc); !ok {
+		t.Fatal("expected a rewritten item to keep created_at and have updated_at stamped")
+	}
+	if _, ok := itemDoc(Item{ID: "a", CreatedAt: created, UpdatedAt: &created}).(Item); !ok {
+		t.Fatal("expected an item with set times to be written as it is")
+	}
+	if stampValue(zero) != firestore.ServerTimestamp || stampValue(created) != created {
+		t.Fatal("unexpected stamp values")
+	}
+}
+
+func TestStampedFillsCommitTime(t *testing.T) {
+	commit := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	created := commit.Add(-time.Hour)
+	s := &Service{clock: SystemClock{}}
+
+	it := Item{ID: "a"}
+	s.stamped(&it, commit)
+	if !it.CreatedAt.Equal(commit) || it.UpdatedAt == nil || !it.UpdatedAt.Equal(commit) {
+		t.Fatalf("expected the commit time, got %+v", it)
+	}
+	it = Item{ID: "a", CreatedAt: created, UpdatedAt: &created}
+	s.stamped(&it, commit)
+	if !it.CreatedAt.Equal(created) || !it.UpdatedAt.Equal(commit) {
+		t.Fatalf("expected only updated_at to move, got %+v", it)
+	}
+
+	frozen := commit.Add(24 * time.Hour)
+	s = &Service{clock: NewFrozenClock(frozen)}
+	it = Item{ID: "a", CreatedAt: frozen, UpdatedAt: &frozen}
+	s.stamped(&it, commit)
+	if !it.UpdatedAt.Equal(frozen) {
+		t.Fatalf("expected a frozen clock's time to be kept, got %v", it.UpdatedAt)
+	}
+}
@@ -0,0 +1,196 @@
+package shoppinglist
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// -----------------------------------------------------------------------------
+// API tokens
+// -----------------------------------------------------------------------------
+
+// Token scopes, from least to most access. Each includes the ones before it.
+const (
+	// ScopeRead allows only tools that do not change anything.
+	ScopeRead = "read"
+	// ScopeAdd also allows adding new items, but not changing or removing
+	// existing ones.
+	ScopeAdd = "add"
+	// ScopeWrite allows every list operation.
+	ScopeWrite = "write"
+	// ScopeOwner also allows managing tokens.
+	ScopeOwner = "owner"
+)
+
+// TokenScopes lists the scopes in increasing order of access.
+var TokenScopes = []string{ScopeRead, ScopeAdd, ScopeWrite, ScopeOwner}
+
+// tokenPrefix marks issued tokens so they are recognizable in logs and
+// secret scanners.
+const tokenPrefix = "slt_"
+
+// APIToken is an issued token. Only a hash of the secret is stored; the
+// secret itself is shown once, when the token is created.
+type APIToken struct {
+	ID        string     `json:"id" firestore:"id"`
+	Name      string     `json:"name" firestore:"name"`
+	Hash      string     `json:"-" firestore:"hash"`
+	Scope     string     `json:"scope" firestore:"scope"`
+	Lists     []string   `json:"lists,omitempty" firestore:"lists,omitempty"` // list IDs; empty means every list
+	CreatedAt time.Time  `json:"created_at" firestore:"created_at"`
+	CreatedBy string     `json:"created_by,omitempty" firestore:"created_by,omitempty"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty" firestore:"revoked_at,omitempty"`
+}
+
+// Allows reports whether the token's scope includes scope.
+func (t *APIToken) Allows(scope string) bool {
+	return slices.Index(TokenScopes, t.Scope) >= slices.Index(TokenScopes, scope) && slices.Contains(TokenScopes, scope)
+}
+
+// AllowsList reports whether the token may be used on the list with the given
+// ID.
+func (t *APIToken) AllowsList(id string) bool {
+	return len(t.Lists) == 0 || slices.Contains(t.Lists, id)
+}
+
+// TokenResponse wraps a token; Secret is only set when it was just created.
+type TokenResponse struct {
+	Token  APIToken `json:"token"`
+	Secret string   `json:"secret,omitempty"`
+	ResponseWarnings
+}
+
+// TokensResponse lists tokens.
+type TokensResponse struct {
+	Tokens []APIToken `json:"tokens"`
+	ResponseWarnings
+}
+
+// ErrInvalidToken is returned for a token that is unknown or revoked.
+var ErrInvalidToken = errors.New("invalid or revoked token")
+
+// hashToken is the stored form of a token secret. Secrets are random, so an
+// unsalted hash cannot be reversed by guessing.
+func hashToken(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// tokensCollection holds the household's tokens, beside the list registry.
+func (s *Service) tokensCollection() *firestore.CollectionRef {
+	r := s.Root()
+	return r.client.Collection(r.collection + "_tokens")
+}
+
+// CreateToken issues a token with the given scope, limited to the lists with
+// the given IDs when any are given, and returns it with its secret.
+func (s *Service) CreateToken(ctx context.Context, name, scope string, lists []string) (APIToken, string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return APIToken{}, "", errors.New("token name is required")
+	}
+	if !slices.Contains(TokenScopes, scope) {
+		return APIToken{}, "", fmt.Errorf("unsupported scope %q (expected one of %v)", scope, TokenScopes)
+	}
+	if scope == ScopeOwner && len(lists) > 0 {
+		return APIToken{}, "", errors.New("owner tokens cannot be limited to lists")
+	}
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return APIToken{}, "", fmt.Errorf("generate token: %w", err)
+	}
+	secret := tokenPrefix + base64.RawURLEncoding.EncodeToString(b)
+	tok := APIToken{
+		ID:        s.NewID(),
+		Name:      name,
+		Hash:      hashToken(secret),
+		Scope:     scope,
+		Lists:     lists,
+		CreatedAt: s.Now(),
+		CreatedBy: actorFromContext(ctx),
+	}
+	if _, err := s.tokensCollection().Doc(tok.ID).Create(ctx, tok); err != nil {
+		return APIToken{}, "", fmt.Errorf("create token: %w", err)
+	}
+	return tok, secret, nil
+}
+
+// ListTokens returns every token, revoked ones included, oldest first.
+func (s *Service) ListTokens(ctx context.Context) ([]APIToken, error) {
+	docs, err := s.tokensCollection().OrderBy("created_at", firestore.Asc).Documents(ctx).GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("retrieve tokens: %w", err)
+	}
+	tokens := make([]APIToken, 0, len(docs))
+	for _, d := range docs {
+		var t APIToken
+		if err := d.DataTo(&t); err != nil {
+			continue
+		}
+		tokens = append(tokens, t)
+	}
+	return tokens, nil
+}
+
+// RevokeToken stops a token from authenticating and returns it.
+func (s *Service) RevokeToken(ctx context.Context, id string) (APIToken, error) {
+	ref := s.tokensCollection().Doc(id)
+	var tok APIToken
+	err := s.Root().runTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(ref)
+		if err != nil {
+			return err
+		}
+		if err := doc.DataTo(&tok); err != nil {
+			return err
+		}
+		if tok.RevokedAt != nil {
+			return nil
+		}
+		now := s.Now()
+		tok.RevokedAt = &now
+		return tx.Update(ref, []firestore.Update{{Path: "revoked_at", Value: now}})
+	})
+	if status.Code(err) == codes.NotFound {
+		return APIToken{}, fmt.Errorf("token %q not found", id)
+	}
+	if err != nil {
+		return APIToken{}, fmt.Errorf("revoke token: %w", err)
+	}
+	return tok, nil
+}
+
+// Authenticate returns the live token with the given secret, or
+// ErrInvalidToken.
+func (s *Service) Authenticate(ctx context.Context, secret string) (*APIToken, error) {
+	if !strings.HasPrefix(secret, tokenPrefix) {
+		return nil, ErrInvalidToken
+	}
+	docs, err := s.tokensCollection().Where("hash", "==", hashToken(secret)).Limit(1).Documents(ctx).GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("look up token: %w", err)
+	}
+	if len(docs) == 0 {
+		return nil, ErrInvalidToken
+	}
+	var tok APIToken
+	if err := docs[0].DataTo(&tok); err != nil {
+		return nil, fmt.Errorf("decode token: %w", err)
+	}
+	if tok.RevokedAt != nil {
+		return nil, ErrInvalidToken
+	}
+	return &tok, nil
+}
@@ -0,0 +1,48 @@
+package shoppinglist
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestTokenScopesInclude(t *testing.T) {
+	add := &APIToken{Scope: ScopeAdd}
+	if !add.Allows(ScopeRead) || !add.Allows(ScopeAdd) {
+		t.Error("expected an add token to allow reads and adds")
+	}
+	if add.Allows(ScopeWrite) || add.Allows(ScopeOwner) || add.Allows("admin") {
+		t.Error("expected an add token to refuse writes, owner tools, and unknown scopes")
+	}
+	if !(&APIToken{Scope: ScopeOwner}).Allows(ScopeWrite) {
+		t.Error("expected an owner token to allow writes")
+	}
+}
+
+func TestTokenAllowsList(t *testing.T) {
+	if !(&APIToken{}).AllowsList("abc") {
+		t.Error("expected a token without lists to allow every list")
+	}
+	tok := &APIToken{Lists: []string{"abc"}}
+	if !tok.AllowsList("abc") || tok.AllowsList("main") {
+		t.Error("expected a list-limited token to allow only its lists")
+	}
+}
+
+func TestAuthenticateRejectsForeignSecretsWithoutLookup(t *testing.T) {
+	// no client: a lookup would panic
+	_, err := (&Service{}).Authenticate(context.Background(), "not-a-token")
+	if !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("err = %v, want ErrInvalidToken", err)
+	}
+}
+
+func TestHashTokenIsStable(t *testing.T) {
+	if hashToken("slt_a") != hashToken("slt_a") || hashToken("slt_a") == hashToken("slt_b") {
+		t.Error("expected the hash to depend only on the secret")
+	}
+	if strings.Contains(hashToken("slt_secret"), "secret") {
+		t.Error("expected the hash not to contain the secret")
+	}
+}
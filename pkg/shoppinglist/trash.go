@@ -0,0 +1,291 @@
+package shoppinglist
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// -----------------------------------------------------------------------------
+// Soft delete
+// -----------------------------------------------------------------------------
+
+// trashed reports whether d, an item document, is in the trash: removed but
+// kept with its deleted_at set until it is restored or purged.
+func trashed(d *firestore.DocumentSnapshot) bool {
+	v, err := d.DataAt("deleted_at")
+	return err == nil && v != nil
+}
+
+// untrashed passes on what a read of one item document returned, with a
+// NotFound error in place of an item in the trash, so reads by ID treat
+// trashed items as gone.
+func untrashed(doc *firestore.DocumentSnapshot, err error) (*firestore.DocumentSnapshot, error) {
+	if err == nil && trashed(doc) {
+		return doc, status.Errorf(codes.NotFound, "item %q is in the trash", doc.Ref.ID)
+	}
+	return doc, err
+}
+
+// trashedIDs returns the IDs of the items among docs that are in the trash,
+// which derived IDs may name again: adding one writes over it.
+func trashedIDs(docs []*firestore.DocumentSnapshot) map[string]bool {
+	ids := map[string]bool{}
+	for _, d := range docs {
+		if trashed(d) {
+			ids[d.Ref.ID] = true
+		}
+	}
+	return ids
+}
+
+// overTrash reports whether a new item written at ref goes over an item in
+// the trash, which only derived IDs name again.
+func (s *Service) overTrash(ctx context.Context, ref *firestore.DocumentRef) (bool, error) {
+	if !s.derivedIDs {
+		return false, nil
+	}
+	var doc *firestore.DocumentSnapshot
+	err := s.retry(ctx, func() (err error) {
+		doc, err = ref.Get(ctx)
+		return err
+	})
+	if status.Code(err) == codes.NotFound {
+		return false, nil
+	}
+	return err == nil && trashed(doc), err
+}
+
+// createItemDoc writes it, a new item, at ref outside a transaction: over
+// the item in the trash there, else as a create that fails if one exists.
+func (s *Service) createItemDoc(ctx context.Context, ref *firestore.DocumentRef, it Item) (*firestore.WriteResult, error) {
+	replace, err := s.overTrash(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	if replace {
+		return ref.Set(ctx, itemDoc(it))
+	}
+	return ref.Create(ctx, itemDoc(it))
+}
+
+// createItem is createItemDoc in tx, writing over the item in the trash at
+// ref when replace is set.
+func createItem(tx *firestore.Transaction, ref *firestore.DocumentRef, it Item, replace bool) error {
+	if replace {
+		return tx.Set(ref, itemDoc(it))
+	}
+	return tx.Create(ref, itemDoc(it))
+}
+
+// liveDocuments reads q, which must be ordered, in tx or outside a
+// transaction when tx is nil, until it has limit items that are not in the
+// trash or there are no more, so trashed items do not take up the limit. It
+// returns at most limit of them.
+func liveDocuments(ctx context.Context, tx *firestore.Transaction, q firestore.Query, limit int) ([]*firestore.DocumentSnapshot, error) {
+	var live []*firestore.DocumentSnapshot
+	for {
+		var (
+			docs []*firestore.DocumentSnapshot
+			err  error
+		)
+		if tx != nil {
+			docs, err = tx.Documents(q.Limit(limit)).GetAll()
+		} else {
+			docs, err = q.Limit(limit).Documents(ctx).GetAll()
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range docs {
+			if !trashed(d) {
+				live = append(live, d)
+			}
+		}
+		if len(live) >= limit {
+			return live[:limit], nil
+		}
+		if len(docs) < limit {
+			return live, nil
+		}
+		q = q.StartAfter(docs[len(docs)-1])
+	}
+}
+
+// trashItem moves the item at ref to the trash in tx, subject to preconds: it
+// stays where it is with deleted_at set, and a tombstone is left as
+// deleteItem does. Removals a user may want to undo go through it; items
+// archived as a trip or moved to another list are deleted outright.
+func (s *Service) trashItem(tx *firestore.Transaction, ref *firestore.DocumentRef, preconds ...firestore.Precondition) error {
+	updates := []firestore.Update{{Path: "deleted_at", Value: stampValue(s.writeTime())}}
+	if at := s.retention.expireAt("deleted", s.Now()); at != nil {
+		updates = append(updates, firestore.Update{Path: "expire_at", Value: *at})
+	}
+	if err := tx.Update(ref, updates, preconds...); err != nil {
+		return err
+	}
+	return s.leaveTombstone(tx, ref)
+}
+
+// trashQuery matches the list's items that are in the trash.
+func (s *Service) trashQuery() firestore.Query {
+	return s.client.Collection(s.collection).Where("deleted_at", "!=", nil)
+}
+
+// DeletedResponse lists the items in a list's trash, most recently removed
+// first.
+type DeletedResponse struct {
+	Items []Item `json:"items"`
+	ResponseWarnings
+}
+
+// DeletedItems returns the items in the trash, most recently removed first.
+func (s *Service) DeletedItems(ctx context.Context) ([]Item, error) {
+	var docs []*firestore.DocumentSnapshot
+	err := s.retry(ctx, func() (err error) {
+		docs, err = s.trashQuery().OrderBy("deleted_at", firestore.Desc).Documents(ctx).GetAll()
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("retrieve deleted items: %w", err)
+	}
+	return decodeItemDocs(docs, true), nil
+}
+
+// ViewWithDeleted is View with the items in the trash included, each with its
+// deleted_at. It always reads Firestore, as the cache holds only the items on
+// the list.
+func (s *Service) ViewWithDeleted(ctx context.Context) (ListView, error) {
+	var view ListView
+	err := s.readConsistent(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		docs, err := tx.Documents(s.client.Collection(s.collection)).GetAll()
+		if err != nil {
+			return fmt.Errorf("retrieve items: %w", err)
+		}
+		freeze, err := decodeFreeze(tx.Get(s.metaCollection().Doc(freezeDocID)))
+		if err != nil {
+			return err
+		}
+		view = ListView{Items: decodeItemDocs(docs, true), Freeze: freeze, ReadTime: s.readTimeOf(docs)}
+		return nil
+	})
+	if err != nil {
+		return ListView{}, err
+	}
+	if !view.Freeze.Active(view.ReadTime) {
+		view.Freeze = nil
+	}
+	sortItems(view.Items)
+	return view, nil
+}
+
+// RestoreItem puts the item with id back on the list from the trash, as a
+// top-level item if its parent is no longer on the list, and returns it. It
+// fails with an error wrapping ErrItemNotFound when the item is not in the
+// trash.
+func (s *Service) RestoreItem(ctx context.Context, id string) (*Item, error) {
+	if err := s.checkNotFrozen(ctx); err != nil {
+		return nil, err
+	}
+	col := s.client.Collection(s.collection)
+	var (
+		it     Item
+		commit firestore.CommitResponse
+	)
+	err := s.runTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(col.Doc(id))
+		if status.Code(err) == codes.NotFound || err == nil && !trashed(doc) {
+			return fmt.Errorf("%w: %q is not in the trash", ErrItemNotFound, id)
+		}
+		if err != nil {
+			return err
+		}
+		it = Item{}
+		if err := doc.DataTo(&it); err != nil {
+			return err
+		}
+
+		updates := []firestore.Update{
+			{Path: "deleted_at", Value: firestore.Delete},
+			{Path: "expire_at", Value: firestore.Delete},
+		}
+		if it.ParentID != nil {
+			if _, err := untrashed(tx.Get(col.Doc(*it.ParentID))); status.Code(err) == codes.NotFound {
+				it.ParentID = nil
+				updates = append(updates, firestore.Update{Path: "parent_id", Value: firestore.Delete})
+			} else if err != nil {
+				return err
+			}
+		}
+		at := s.writeTime()
+		it.DeletedAt, it.UpdatedAt = nil, &at
+		it.Revision++
+		if err := tx.Update(col.Doc(id), withRevision(updates, at)); err != nil {
+			return err
+		}
+		return tx.Delete(s.tombstonesOf(col).Doc(id))
+	}, firestore.WithCommitResponseTo(&commit))
+	if err != nil {
+		return nil, fmt.Errorf("restore item: %w", err)
+	}
+	s.stamped(&it, commit.CommitTime())
+	s.observe(ctx, activityAdd, 1)
+	s.recordActivity(ctx, ActionRestored, it)
+	s.recordAudit(ctx, newItemChange(ActionRestored, nil, &it))
+	return &it, nil
+}
+
+// PurgeResponse reports the items purge_deleted removed for good.
+type PurgeResponse struct {
+	Purged   []string `json:"purged"`
+	NotFound []string `json:"not_found,omitempty"`
+	ResponseWarnings
+}
+
+// PurgeDeleted deletes items in the trash for good: those with the given IDs,
+// or when ids is empty those removed before olderThan, or every one when
+// olderThan is zero too. Items with the given IDs that are not in the trash,
+// including those still on the list, are reported as not found, and purging
+// fails rather than delete an item restored since it was read.
+func (s *Service) PurgeDeleted(ctx context.Context, ids []string, olderThan time.Time) (PurgeResponse, error) {
+	col := s.client.Collection(s.collection)
+	var docs []*firestore.DocumentSnapshot
+	err := s.retry(ctx, func() (err error) {
+		if len(ids) > 0 {
+			refs := make([]*firestore.DocumentRef, len(ids))
+			for i, id := range ids {
+				refs[i] = col.Doc(id)
+			}
+			docs, err = s.client.GetAll(ctx, refs)
+			return err
+		}
+		q := s.trashQuery()
+		if !olderThan.IsZero() {
+			q = col.Where("deleted_at", "<", olderThan)
+		}
+		docs, err = q.Documents(ctx).GetAll()
+		return err
+	})
+	if err != nil {
+		return PurgeResponse{}, fmt.Errorf("purge deleted items: %w", err)
+	}
+
+	resp := PurgeResponse{Purged: []string{}}
+	b := s.newBatch()
+	for _, d := range docs {
+		if !d.Exists() || !trashed(d) {
+			resp.NotFound = append(resp.NotFound, d.Ref.ID)
+			continue
+		}
+		b.Delete(d.Ref, firestore.LastUpdateTime(d.UpdateTime))
+		resp.Purged = append(resp.Purged, d.Ref.ID)
+	}
+	if err := b.Commit(ctx); err != nil {
+		return PurgeResponse{}, fmt.Errorf("purge deleted items: %w", err)
+	}
+	return resp, nil
+}
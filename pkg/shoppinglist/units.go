@@ -0,0 +1,160 @@
+package shoppinglist
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// -----------------------------------------------------------------------------
+// Household locale and units
+// -----------------------------------------------------------------------------
+
+// HouseholdProfile holds how the household writes numbers and which
+// measurement system it shops in.
+type HouseholdProfile struct {
+	// Locale is a BCP 47 tag such as en-US or de-DE. It decides whether
+	// quantities use a decimal point or a decimal comma. When empty, a comma
+	// is read as a decimal point and point is used for output.
+	Locale string
+	// Units is "metric", "imperial", or empty to keep units as entered.
+	Units string
+}
+
+// commaLanguages write decimals with a comma.
+var commaLanguages = map[string]bool{
+	"cs": true, "da": true, "de": true, "el": true, "es": true, "fi": true, "fr": true, "hu": true,
+	"id": true, "it": true, "nb": true, "nl": true, "nn": true, "no": true, "pl": true, "pt": true,
+	"ro": true, "ru": true, "sk": true, "sv": true, "tr": true, "uk": true, "vi": true,
+}
+
+// pointRegions are locales that use a decimal point although their language
+// usually does not.
+var pointRegions = map[string]bool{"de-ch": true, "fr-ch": true, "it-ch": true, "es-mx": true, "es-us": true}
+
+var (
+	metricUnits   = map[string]bool{"mg": true, "g": true, "gram": true, "grams": true, "kg": true, "ml": true, "cl": true, "dl": true, "l": true, "liter": true, "litre": true}
+	imperialUnits = map[string]bool{"oz": true, "lb": true, "lbs": true, "floz": true, "gal": true}
+)
+
+// ParseHouseholdProfile validates the locale and unit system flags.
+func ParseHouseholdProfile(locale, units string) (HouseholdProfile, error) {
+	locale = strings.ReplaceAll(strings.TrimSpace(locale), "_", "-")
+	if locale != "" {
+		lang, _, _ := strings.Cut(locale, "-")
+		if len(lang) < 2 || len(lang) > 3 || strings.IndexFunc(lang, func(r rune) bool { return !('a' <= r && r <= 'z' || 'A' <= r && r <= 'Z') }) >= 0 {
+			return HouseholdProfile{}, fmt.Errorf("invalid locale %q (expected a BCP 47 tag such as en-US or de-DE)", locale)
+		}
+	}
+	units = strings.ToLower(strings.TrimSpace(units))
+	if units != "" && units != "metric" && units != "imperial" {
+		return HouseholdProfile{}, fmt.Errorf("unknown unit system %q (expected metric or imperial)", units)
+	}
+	return HouseholdProfile{Locale: locale, Units: units}, nil
+}
+
+// decimalComma reports whether the profile's locale writes decimals with a comma.
+func (p HouseholdProfile) decimalComma() bool {
+	tag := strings.ToLower(p.Locale)
+	if pointRegions[tag] {
+		return false
+	}
+	lang, _, _ := strings.Cut(tag, "-")
+	return commaLanguages[lang]
+}
+
+// ParseNumber reads a number written for the profile's locale, e.g. "1.5" or
+// "1,234.5" in en-US and "1,5" or "1.234,5" in de-DE. Spaces and apostrophes
+// are accepted as digit group separators.
+func (p HouseholdProfile) ParseNumber(s string) (float64, error) {
+	n := strings.Map(func(r rune) rune {
+		if r == ' ' || r == '\'' || r == '\u00a0' || r == '\u202f' {
+			return -1
+		}
+		return r
+	}, strings.TrimSpace(s))
+	switch {
+	case p.Locale == "":
+		n = strings.ReplaceAll(n, ",", ".")
+	case p.decimalComma():
+		n = strings.ReplaceAll(strings.ReplaceAll(n, ".", ""), ",", ".")
+	default:
+		n = strings.ReplaceAll(n, ",", "")
+	}
+	v, err := strconv.ParseFloat(n, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number %q", s)
+	}
+	return v, nil
+}
+
+// FormatNumber renders v with up to three decimals in the profile's locale.
+func (p HouseholdProfile) FormatNumber(v float64) string {
+	s := strconv.FormatFloat(math.Round(v*1000)/1000, 'f', -1, 64)
+	if p.decimalComma() {
+		s = strings.Replace(s, ".", ",", 1)
+	}
+	return s
+}
+
+// unitKey is the spelling of a unit used for lookups: lower case without
+// spaces or a trailing period, so "Fl Oz." matches "floz".
+func unitKey(unit string) string {
+	return strings.TrimSuffix(strings.ReplaceAll(strings.ToLower(strings.TrimSpace(unit)), " ", ""), ".")
+}
+
+// convertUnit converts v from one unit to another of the same kind (weight,
+// volume, or count). Units that are not sizes, like "cans", only convert to
+// themselves.
+func convertUnit(v float64, from, to string) (float64, bool) {
+	fk, tk := unitKey(from), unitKey(to)
+	if fk == tk {
+		return v, true
+	}
+	f, fok := sizeUnits[fk]
+	t, tok := sizeUnits[tk]
+	if !fok || !tok || f.base != t.base {
+		return 0, false
+	}
+	return v * f.factor / t.factor, true
+}
+
+// preferredUnit returns the unit a new quantity in unit is kept in: units
+// from the other measurement system are swapped for the household's.
+func (p HouseholdProfile) preferredUnit(unit string) string {
+	k := unitKey(unit)
+	conv, ok := sizeUnits[k]
+	if !ok {
+		return unit
+	}
+	switch {
+	case p.Units == "imperial" && metricUnits[k]:
+		return map[string]string{"g": "oz", "ml": "fl oz"}[conv.base]
+	case p.Units == "metric" && imperialUnits[k]:
+		return conv.base
+	}
+	return unit
+}
+
+// LocalizeOption restates a unit price per ounce or fluid ounce for imperial
+// households. Stored unit prices always use the metric basis so options stay
+// comparable.
+func (p HouseholdProfile) LocalizeOption(o PackageOption) PackageOption {
+	if p.Units != "imperial" {
+		return o
+	}
+	switch o.UnitBasis {
+	case unitBases["g"].label:
+		o.UnitPrice, o.UnitBasis = math.Round(o.UnitPrice*sizeUnits["oz"].factor/unitBases["g"].per*10000)/10000, "1 oz"
+	case unitBases["ml"].label:
+		o.UnitPrice, o.UnitBasis = math.Round(o.UnitPrice*sizeUnits["floz"].factor/unitBases["ml"].per*10000)/10000, "1 fl oz"
+	}
+	return o
+}
+
+// WithHouseholdProfile sets the locale and unit system used to read and write
+// quantities.
+func WithHouseholdProfile(p HouseholdProfile) ServiceOption {
+	return func(s *Service) { s.profile = p }
+}
@@ -0,0 +1,68 @@
+package shoppinglist
+
+import "testing"
+
+func TestParseNumberByLocale(t *testing.T) {
+	cases := []struct {
+		locale string
+		in     string
+		want   float64
+	}{
+		{"", "1.5", 1.5},
+		{"", "1,5", 1.5},
+		{"en-US", "1,234.5", 1234.5},
+		{"de-DE", "1.234,5", 1234.5},
+		{"de-DE", "0,75", 0.75},
+		{"fr-FR", "1 234,5", 1234.5},
+		{"de-CH", "1'234.5", 1234.5},
+	}
+	for _, c := range cases {
+		got, err := HouseholdProfile{Locale: c.locale}.ParseNumber(c.in)
+		if err != nil || got != c.want {
+			t.Errorf("ParseNumber(%q) in %q = %v, %v; want %v", c.in, c.locale, got, err, c.want)
+		}
+	}
+}
+
+func TestFormatNumberByLocale(t *testing.T) {
+	if got := (HouseholdProfile{Locale: "pt-BR"}).FormatNumber(2.5); got != "2,5" {
+		t.Fatalf("expected 2,5, got %q", got)
+	}
+	if got := (HouseholdProfile{Locale: "en-GB"}).FormatNumber(2.5); got != "2.5" {
+		t.Fatalf("expected 2.5, got %q", got)
+	}
+}
+
+func TestParseHouseholdProfile(t *testing.T) {
+	p, err := ParseHouseholdProfile("de_DE", "Imperial")
+	if err != nil || p.Locale != "de-DE" || p.Units != "imperial" {
+		t.Fatalf("unexpected profile %+v, err %v", p, err)
+	}
+	if _, err := ParseHouseholdProfile("", "furlongs"); err == nil {
+		t.Fatal("expected an error for an unknown unit system")
+	}
+	if _, err := ParseHouseholdProfile("1234", ""); err == nil {
+		t.Fatal("expected an error for an invalid locale")
+	}
+}
+
+func TestPackageSizeInLocale(t *testing.T) {
+	amount, base, err := ParsePackageSize("1,5 kg", HouseholdProfile{Locale: "de-DE"})
+	if err != nil || amount != 1500 || base != "g" {
+		t.Fatalf("parsePackageSize = %v %s, %v; want 1500 g", amount, base, err)
+	}
+}
+
+func TestLocalizeOptionForImperialHouseholds(t *testing.T) {
+	option, err := NewPackageOption("1 kg", 10, "USD", "", HouseholdProfile{})
+	if err != nil {
+		t.Fatalf("newPackageOption returned error: %v", err)
+	}
+	local := HouseholdProfile{Units: "imperial"}.LocalizeOption(option)
+	if local.UnitBasis != "1 oz" || local.UnitPrice != 0.2835 {
+		t.Fatalf("expected 0.2835 per oz, got %v per %s", local.UnitPrice, local.UnitBasis)
+	}
+	if same := (HouseholdProfile{Units: "metric"}).LocalizeOption(option); same != option {
+		t.Fatalf("expected metric options unchanged, got %+v", same)
+	}
+}
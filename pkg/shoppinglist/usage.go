@@ -0,0 +1,404 @@
+package shoppinglist
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	pb "cloud.google.com/go/firestore/apiv1/firestorepb"
+	"google.golang.org/grpc"
+)
+
+// -----------------------------------------------------------------------------
+// Usage
+// -----------------------------------------------------------------------------
+
+// FreeTier is the Firestore no-cost quota: operations per day, reset at
+// midnight Pacific time, and stored bytes.
+var FreeTier = FreeTierQuota{Reads: 50000, Writes: 20000, Deletes: 20000, StoredBytes: 1 << 30}
+
+// FreeTierQuota is a daily operations quota and a storage quota.
+type FreeTierQuota struct {
+	Reads       int64 `json:"reads"`
+	Writes      int64 `json:"writes"`
+	Deletes     int64 `json:"deletes"`
+	StoredBytes int64 `json:"stored_bytes"`
+}
+
+// OperationCounts are billed document operations.
+type OperationCounts struct {
+	Reads   int64 `json:"reads" firestore:"reads"`
+	Writes  int64 `json:"writes" firestore:"writes"`
+	Deletes int64 `json:"deletes" firestore:"deletes"`
+}
+
+// quotaZone is the time zone Firestore's daily quota resets in.
+var quotaZone = func() *time.Location {
+	if loc, err := time.LoadLocation("America/Los_Angeles"); err == nil {
+		return loc
+	}
+	return time.FixedZone("PST", -8*60*60)
+}()
+
+// UsageMeter counts the document reads, writes, and deletes this process
+// bills, from the Firestore RPCs it makes, since it started and since the
+// daily quota last reset.
+type UsageMeter struct {
+	mu    sync.Mutex
+	now   func() time.Time
+	since time.Time
+	total OperationCounts
+	day   string
+	today OperationCounts
+}
+
+// NewUsageMeter returns a meter reading the time from now.
+func NewUsageMeter(now func() time.Time) *UsageMeter {
+	return &UsageMeter{now: now, since: now().UTC()}
+}
+
+// add records operations, starting a new day's count when the quota has reset.
+func (m *UsageMeter) add(c OperationCounts) {
+	if c == (OperationCounts{}) {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.rollDay()
+	for _, t := range []*OperationCounts{&m.total, &m.today} {
+		t.Reads += c.Reads
+		t.Writes += c.Writes
+		t.Deletes += c.Deletes
+	}
+}
+
+// rollDay clears today's counts once the quota day has changed. m.mu must be
+// held.
+func (m *UsageMeter) rollDay() {
+	if day := m.now().In(quotaZone).Format(time.DateOnly); day != m.day {
+		m.day, m.today = day, OperationCounts{}
+	}
+}
+
+// Counts returns the operations since the meter started, since the quota last
+// reset, and when the meter started.
+func (m *UsageMeter) Counts() (total, today OperationCounts, since time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.rollDay()
+	return m.total, m.today, m.since
+}
+
+// writeCounts counts the writes and deletes in a commit.
+func writeCounts(writes []*pb.Write) OperationCounts {
+	var c OperationCounts
+	for _, w := range writes {
+		if w.GetDelete() != "" {
+			c.Deletes++
+		} else {
+			c.Writes++
+		}
+	}
+	return c
+}
+
+// OperationTally counts the operations made on behalf of one context, such
+// as a single tool call, alongside the meter's process-wide counts. A tally
+// taken within another also counts toward it.
+type OperationTally struct {
+	mu     sync.Mutex
+	c      OperationCounts
+	parent *OperationTally
+}
+
+type tallyKey struct{}
+
+// WithOperationTally returns a context whose Firestore operations are also
+// counted in the returned tally.
+func WithOperationTally(ctx context.Context) (context.Context, *OperationTally) {
+	t := &OperationTally{parent: tallyFrom(ctx)}
+	return context.WithValue(ctx, tallyKey{}, t), t
+}
+
+// tallyFrom is the tally of ctx, or nil when it has none.
+func tallyFrom(ctx context.Context) *OperationTally {
+	t, _ := ctx.Value(tallyKey{}).(*OperationTally)
+	return t
+}
+
+func (t *OperationTally) add(c OperationCounts) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	t.c.Reads += c.Reads
+	t.c.Writes += c.Writes
+	t.c.Deletes += c.Deletes
+	t.mu.Unlock()
+	t.parent.add(c)
+}
+
+// Counts returns the operations counted so far.
+func (t *OperationTally) Counts() OperationCounts {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.c
+}
+
+// unaryInterceptor counts the operations of successful unary Firestore calls,
+// and notes throttled ones.
+func (m *UsageMeter) unaryInterceptor(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	err := invoker(ctx, method, req, reply, cc, opts...)
+	if err != nil {
+		noteThrottled(ctx, err, m.now())
+		return err
+	}
+	var c OperationCounts
+	switch r := req.(type) {
+	case *pb.CommitRequest:
+		c = writeCounts(r.GetWrites())
+	case *pb.BatchWriteRequest:
+		c = writeCounts(r.GetWrites())
+	case *pb.GetDocumentRequest:
+		c = OperationCounts{Reads: 1}
+	}
+	if r, ok := reply.(*pb.ListDocumentsResponse); ok {
+		c = OperationCounts{Reads: int64(max(len(r.GetDocuments()), 1))}
+	}
+	m.add(c)
+	tallyFrom(ctx).add(c)
+	return nil
+}
+
+// streamInterceptor counts the documents streamed back by queries and gets.
+func (m *UsageMeter) streamInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	s, err := streamer(ctx, desc, cc, method, opts...)
+	if err != nil {
+		noteThrottled(ctx, err, m.now())
+		return nil, err
+	}
+	return &meteredStream{ClientStream: s, ctx: ctx, meter: m, tally: tallyFrom(ctx)}, nil
+}
+
+// meteredStream counts reads as responses arrive. A query is billed at least
+// one read even when it matches nothing.
+type meteredStream struct {
+	grpc.ClientStream
+	ctx   context.Context
+	meter *UsageMeter
+	tally *OperationTally
+	query bool
+	reads int64
+}
+
+func (s *meteredStream) RecvMsg(msg any) error {
+	err := s.ClientStream.RecvMsg(msg)
+	if err != nil {
+		if errors.Is(err, io.EOF) && s.query && s.reads == 0 {
+			s.meter.add(OperationCounts{Reads: 1})
+			s.tally.add(OperationCounts{Reads: 1})
+		}
+		noteThrottled(s.ctx, err, s.meter.now())
+		return err
+	}
+	var n int64
+	switch r := msg.(type) {
+	case *pb.RunQueryResponse:
+		s.query = true
+		if r.GetDocument() != nil {
+			n = 1
+		}
+	case *pb.BatchGetDocumentsResponse:
+		if r.GetFound() != nil || r.GetMissing() != "" {
+			n = 1
+		}
+	case *pb.RunAggregationQueryResponse:
+		if r.GetResult() != nil {
+			n = 1
+		}
+	}
+	s.reads += n
+	s.meter.add(OperationCounts{Reads: n})
+	s.tally.add(OperationCounts{Reads: n})
+	return nil
+}
+
+// CollectionUsage is the size of one collection.
+type CollectionUsage struct {
+	Collection     string `json:"collection"`
+	Documents      int64  `json:"documents"`
+	EstimatedBytes int64  `json:"estimated_bytes"`
+}
+
+// ListUsage is the storage a list takes up, across its items and history.
+type ListUsage struct {
+	List              string             `json:"list"`
+	Documents         int64              `json:"documents"`
+	EstimatedBytes    int64              `json:"estimated_bytes"`
+	Collections       []CollectionUsage  `json:"collections"`
+	QuantityMigration *QuantityMigration `json:"quantity_migration,omitempty"`
+}
+
+// UsageReport estimates the Firestore consumption attributable to the server.
+type UsageReport struct {
+	Lists          []ListUsage       `json:"lists"`
+	Shared         []CollectionUsage `json:"shared"`
+	Documents      int64             `json:"documents"`
+	EstimatedBytes int64             `json:"estimated_bytes"`
+	Operations     OperationCounts   `json:"operations"`
+	Today          OperationCounts   `json:"today"`
+	Since          time.Time         `json:"since"`
+	FreeTier       FreeTierQuota     `json:"free_tier"`
+	// Cache is set with WithItemCache, StaleFallback with WithStaleFallback.
+	Cache         *CacheStats `json:"cache,omitempty"`
+	StaleFallback *StaleStats `json:"stale_fallback,omitempty"`
+	ResponseWarnings
+}
+
+// usageSample is how many documents of a collection are read to estimate
+// their average size.
+const usageSample = 20
+
+// documentOverhead is the fixed size Firestore adds to every document.
+const documentOverhead = 32
+
+// valueSize is the storage size of a field value, as Firestore bills it.
+func valueSize(v any) int64 {
+	switch v := v.(type) {
+	case nil, bool:
+		return 1
+	case string:
+		return int64(len(v)) + 1
+	case []byte:
+		return int64(len(v))
+	case []any:
+		var n int64
+		for _, e := range v {
+			n += valueSize(e)
+		}
+		return n
+	case map[string]any:
+		return fieldsSize(v)
+	case *firestore.DocumentRef:
+		return int64(len(v.Path)) + 1
+	default:
+		// Integers, floats, timestamps, and geopoints.
+		return 8
+	}
+}
+
+// fieldsSize is the storage size of a document's or map's fields.
+func fieldsSize(fields map[string]any) int64 {
+	var n int64
+	for k, v := range fields {
+		n += int64(len(k)) + 1 + valueSize(v)
+	}
+	return n
+}
+
+// documentSize is the storage size of a document: its name, fields, and
+// fixed overhead.
+func documentSize(name string, fields map[string]any) int64 {
+	return int64(len(name)) + 1 + fieldsSize(fields) + documentOverhead
+}
+
+// collectionUsage counts a collection's documents with an aggregation query
+// and estimates its size from a sample of them.
+func collectionUsage(ctx context.Context, col *firestore.CollectionRef) (CollectionUsage, error) {
+	u := CollectionUsage{Collection: col.ID}
+	res, err := col.NewAggregationQuery().WithCount("n").Get(ctx)
+	if err != nil {
+		return u, fmt.Errorf("count %s: %w", col.ID, err)
+	}
+	if v, ok := res["n"].(*pb.Value); ok {
+		u.Documents = v.GetIntegerValue()
+	}
+	if u.Documents == 0 {
+		return u, nil
+	}
+	docs, err := col.Limit(usageSample).Documents(ctx).GetAll()
+	if err != nil {
+		return u, fmt.Errorf("sample %s: %w", col.ID, err)
+	}
+	var sampled int64
+	for _, d := range docs {
+		sampled += documentSize(d.Ref.Path, d.Data())
+	}
+	if len(docs) > 0 {
+		u.EstimatedBytes = sampled * u.Documents / int64(len(docs))
+	}
+	return u, nil
+}
+
+// listCollectionSuffixes are the collections kept for each list besides its
+// items.
+var listCollectionSuffixes = []string{"_meta", "_activity", "_purchases", "_trips", "_incidents", "_tombstones", "_audit"}
+
+// UsageReport counts the documents of every list and the shared collections,
+// estimates their size, and adds the operations this process has billed.
+// Collections that cannot be read are reported as warnings.
+func (s *Service) UsageReport(ctx context.Context) (UsageReport, error) {
+	r := s.Root()
+	lists, err := r.Lists(ctx)
+	if err != nil {
+		return UsageReport{}, err
+	}
+	var report UsageReport
+	measure := func(client *firestore.Client, name string) (CollectionUsage, bool) {
+		u, err := collectionUsage(ctx, client.Collection(name))
+		if err != nil {
+			report.Warn(WarnUsageIncomplete, "", "%v", err)
+			return u, false
+		}
+		return u, true
+	}
+	for _, l := range lists {
+		lu := ListUsage{List: l.Slug}
+		client, err := r.databaseClient(l.Database)
+		if err != nil {
+			report.Warn(WarnUsageIncomplete, "", "%v", err)
+			continue
+		}
+		for _, suffix := range append([]string{""}, listCollectionSuffixes...) {
+			u, ok := measure(client, l.Collection+suffix)
+			if !ok || u.Documents == 0 {
+				continue
+			}
+			if suffix == "" {
+				m, err := quantityMigration(ctx, client.Collection(l.Collection), u.Documents)
+				if err != nil {
+					report.Warn(WarnUsageIncomplete, "", "count migrated items of %s: %v", l.Slug, err)
+				} else if m.Pending > 0 {
+					lu.QuantityMigration = &m
+				}
+			}
+			lu.Collections = append(lu.Collections, u)
+			lu.Documents += u.Documents
+			lu.EstimatedBytes += u.EstimatedBytes
+		}
+		report.Lists = append(report.Lists, lu)
+		report.Documents += lu.Documents
+		report.EstimatedBytes += lu.EstimatedBytes
+	}
+	for _, suffix := range []string{"_lists", "_tokens", "_leases", "_telemetry"} {
+		if u, ok := measure(r.client, r.collection+suffix); ok && u.Documents > 0 {
+			report.Shared = append(report.Shared, u)
+			report.Documents += u.Documents
+			report.EstimatedBytes += u.EstimatedBytes
+		}
+	}
+	report.FreeTier = FreeTier
+	report.Cache = r.CacheStats()
+	if r.staleMaxAge > 0 {
+		stale := r.StaleStats()
+		report.StaleFallback = &stale
+	}
+	if r.usage != nil {
+		report.Operations, report.Today, report.Since = r.usage.Counts()
+	}
+	return report, nil
+}
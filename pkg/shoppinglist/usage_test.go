@@ -0,0 +1,54 @@
+package shoppinglist
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pb "cloud.google.com/go/firestore/apiv1/firestorepb"
+)
+
+func TestUsageMeterResetsTodayAtPacificMidnight(t *testing.T) {
+	clock := NewFrozenClock(time.Date(2025, 8, 12, 6, 30, 0, 0, time.UTC)) // 23:30 PDT
+	m := NewUsageMeter(clock.Now)
+	m.add(OperationCounts{Reads: 3, Writes: 1})
+
+	clock.Advance(time.Hour) // 00:30 PDT the next day
+	m.add(OperationCounts{Reads: 2, Deletes: 1})
+	total, today, _ := m.Counts()
+	if total != (OperationCounts{Reads: 5, Writes: 1, Deletes: 1}) {
+		t.Fatalf("unexpected total %+v", total)
+	}
+	if today != (OperationCounts{Reads: 2, Deletes: 1}) {
+		t.Fatalf("unexpected today %+v", today)
+	}
+}
+
+func TestWriteCountsSplitsDeletes(t *testing.T) {
+	writes := []*pb.Write{
+		{Operation: &pb.Write_Update{Update: &pb.Document{Name: "a"}}},
+		{Operation: &pb.Write_Delete{Delete: "b"}},
+		{Operation: &pb.Write_Update{Update: &pb.Document{Name: "c"}}},
+	}
+	if got := writeCounts(writes); got != (OperationCounts{Writes: 2, Deletes: 1}) {
+		t.Fatalf("unexpected counts %+v", got)
+	}
+}
+
+func TestDocumentSize(t *testing.T) {
+	// "milk" is 5 bytes as a string; "name" is 5 as a field name; the
+	// integer is 8 and "revision" 9.
+	fields := map[string]any{"name": "milk", "revision": int64(1)}
+	if got, want := documentSize("items/1", fields), int64(8+5+5+9+8+documentOverhead); got != want {
+		t.Fatalf("documentSize = %d, want %d", got, want)
+	}
+}
+
+func TestOperationTallyCountsOnlyItsContext(t *testing.T) {
+	ctx, tally := WithOperationTally(context.Background())
+	tallyFrom(ctx).add(OperationCounts{Reads: 2})
+	tallyFrom(context.Background()).add(OperationCounts{Writes: 1})
+	if got := tally.Counts(); got != (OperationCounts{Reads: 2}) {
+		t.Fatalf("unexpected tally %+v", got)
+	}
+}
@@ -0,0 +1,97 @@
+package shoppinglist
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+)
+
+// -----------------------------------------------------------------------------
+// Response warnings
+// -----------------------------------------------------------------------------
+
+// Warning codes reported alongside successful tool results.
+const (
+	WarnNearDuplicate     = "near_duplicate"
+	WarnQuantityAmbiguous = "quantity_ambiguous"
+	WarnBudgetExceeded    = "budget_exceeded"
+	WarnValidationCoerced = "validation_coerced"
+	WarnConversionFailed  = "conversion_failed"
+	WarnIncomparable      = "incomparable"
+	WarnStaleData         = "stale_data"
+	WarnSigningFailed     = "signing_failed"
+	WarnStorageCleanup    = "storage_cleanup_failed"
+	WarnFuzzyMatch        = "fuzzy_match"
+	WarnUsageIncomplete   = "usage_incomplete"
+	WarnSessionEnded      = "session_ended"
+	WarnSamplingFailed    = "sampling_failed"
+	WarnReadBackFailed    = "read_back_failed"
+)
+
+// Warning is non-fatal nuance about a tool call that an agent may act on.
+type Warning struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	ItemID  string `json:"item_id,omitempty"`
+}
+
+// ResponseWarnings is embedded in every structured tool response.
+type ResponseWarnings struct {
+	Warnings []Warning `json:"warnings,omitempty"`
+}
+
+// Warn appends a warning to the response.
+func (r *ResponseWarnings) Warn(code, itemID, format string, a ...any) {
+	r.Warnings = append(r.Warnings, Warning{Code: code, ItemID: itemID, Message: fmt.Sprintf(format, a...)})
+}
+
+// NormalizeItemName collapses runs of whitespace and trims the name,
+// reporting whether anything changed.
+func NormalizeItemName(name string) (string, bool) {
+	normalized := strings.Join(strings.Fields(name), " ")
+	return normalized, normalized != name
+}
+
+var (
+	quantityDigitRe = regexp.MustCompile(`\d`)
+	quantityRangeRe = regexp.MustCompile(`\d\s*(?:-|–|to|or)\s*\d`)
+)
+
+// QuantityAmbiguity explains why a quantity string cannot be read as a single
+// amount, or returns "" when it can.
+func QuantityAmbiguity(q string) string {
+	switch {
+	case q == "":
+		return ""
+	case quantityRangeRe.MatchString(q):
+		return fmt.Sprintf("quantity %q is a range; the upper bound may be bought", q)
+	case !quantityDigitRe.MatchString(q):
+		return fmt.Sprintf("quantity %q has no numeric amount", q)
+	default:
+		return ""
+	}
+}
+
+// nearDuplicateThreshold is the similarity score above which another item is
+// reported as a likely duplicate.
+const nearDuplicateThreshold = 0.9
+
+// WarnNearDuplicates reports items other than id whose names closely match name.
+func WarnNearDuplicates(ctx context.Context, w *ResponseWarnings, embedder Embedder, id, name string, items []Item) {
+	others := make([]Item, 0, len(items))
+	for _, it := range items {
+		if it.ID != id {
+			others = append(others, it)
+		}
+	}
+	matches, err := FindSimilarItems(ctx, embedder, name, others, nearDuplicateThreshold)
+	if err != nil {
+		log.Printf("warn: near-duplicate check: %v", err)
+		return
+	}
+	for _, m := range matches {
+		w.Warn(WarnNearDuplicate, m.Item.ID, "%q looks like existing item %q (similarity %.2f)", name, m.Item.Name, m.Score)
+	}
+}
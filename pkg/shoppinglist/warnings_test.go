@@ -0,0 +1,52 @@
+package shoppinglist
+
+import (
+	"context"
+	"testing"
+)
+
+func TestNormalizeItemName(t *testing.T) {
+	got, coerced := NormalizeItemName("  whole   milk ")
+	if got != "whole milk" || !coerced {
+		t.Fatalf("normalizeItemName = %q, %v", got, coerced)
+	}
+	if _, coerced := NormalizeItemName("eggs"); coerced {
+		t.Fatal("expected clean name to be left alone")
+	}
+}
+
+func TestQuantityAmbiguity(t *testing.T) {
+	for _, q := range []string{"2-3", "1 or 2", "a few", "some"} {
+		if QuantityAmbiguity(q) == "" {
+			t.Fatalf("expected %q to be ambiguous", q)
+		}
+	}
+	for _, q := range []string{"", "4", "500 g", "2 dozen"} {
+		if msg := QuantityAmbiguity(q); msg != "" {
+			t.Fatalf("expected %q to be unambiguous, got %q", q, msg)
+		}
+	}
+}
+
+func TestWarnNearDuplicatesSkipsSelf(t *testing.T) {
+	items := []Item{{ID: "new", Name: "tomatoes"}, {ID: "old", Name: "tomatoes"}, {ID: "other", Name: "rice"}}
+
+	var w ResponseWarnings
+	WarnNearDuplicates(context.Background(), &w, LocalEmbedder{Dims: 256}, "new", "tomatoes", items)
+	if len(w.Warnings) != 1 || w.Warnings[0].ItemID != "old" || w.Warnings[0].Code != WarnNearDuplicate {
+		t.Fatalf("unexpected warnings %+v", w.Warnings)
+	}
+}
+
+func TestCheckBudgetWarnsWhenExceeded(t *testing.T) {
+	rates, _ := NewExchangeRateSource("", "USD", SystemClock{})
+	report := PriceReportResponse{Currency: "USD", Total: 120}
+
+	CheckBudget(context.Background(), &report, rates, 100, "USD")
+	if len(report.Warnings) != 1 || report.Warnings[0].Code != WarnBudgetExceeded {
+		t.Fatalf("unexpected warnings %+v", report.Warnings)
+	}
+	if report.Budget != 100 {
+		t.Fatalf("unexpected budget %v", report.Budget)
+	}
+}
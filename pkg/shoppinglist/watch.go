@@ -0,0 +1,52 @@
+package shoppinglist
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"cloud.google.com/go/firestore"
+)
+
+// -----------------------------------------------------------------------------
+// Change watches
+// -----------------------------------------------------------------------------
+
+// WatchItems calls changed with the IDs of the items added, changed, or
+// removed on the list, from any instance or client, until ctx is done. It
+// listens with a Firestore snapshot listener: the first snapshot only sets
+// the baseline, and after the listener fails and listens again, the items
+// that changed meanwhile are reported together.
+func (s *Service) WatchItems(ctx context.Context, changed func(ids []string)) {
+	var known map[string]time.Time
+	listen(ctx, s.client.Collection(s.collection).Query, "item watch", func(docs []*firestore.DocumentSnapshot, _ time.Time) {
+		current := make(map[string]time.Time, len(docs))
+		for _, d := range docs {
+			current[d.Ref.ID] = d.UpdateTime
+		}
+		if known != nil {
+			if ids := changedIDs(known, current); len(ids) > 0 {
+				changed(ids)
+			}
+		}
+		known = current
+	}, nil)
+}
+
+// changedIDs lists, in order, the IDs whose update times differ between
+// before and after, including those only in one of them.
+func changedIDs(before, after map[string]time.Time) []string {
+	var ids []string
+	for id, at := range after {
+		if was, ok := before[id]; !ok || !was.Equal(at) {
+			ids = append(ids, id)
+		}
+	}
+	for id := range before {
+		if _, ok := after[id]; !ok {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+	return ids
+}
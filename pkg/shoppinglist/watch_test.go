@@ -0,0 +1,21 @@
+package shoppinglist
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestChangedIDs(t *testing.T) {
+	at := time.Date(2025, 8, 12, 9, 0, 0, 0, time.UTC)
+	before := map[string]time.Time{"kept": at, "edited": at, "removed": at}
+	after := map[string]time.Time{"kept": at, "edited": at.Add(time.Second), "added": at}
+
+	got := changedIDs(before, after)
+	if want := []string{"added", "edited", "removed"}; !slices.Equal(got, want) {
+		t.Fatalf("changedIDs = %v, want %v", got, want)
+	}
+	if got := changedIDs(after, after); len(got) != 0 {
+		t.Fatalf("expected no changes, got %v", got)
+	}
+}
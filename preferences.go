@@ -0,0 +1,180 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/UnitVectorY-Labs/mcp-shopping-list-firestore/pkg/shoppinglist"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// -----------------------------------------------------------------------------
+// Session preferences
+// -----------------------------------------------------------------------------
+
+// preferenceStore keeps preferences for each connected session.
+type preferenceStore struct {
+	mu    sync.RWMutex
+	prefs map[string]shoppinglist.SessionPreferences
+}
+
+var sessionPrefs = &preferenceStore{prefs: map[string]shoppinglist.SessionPreferences{}}
+
+// sessionKey identifies the session behind ctx; stdio and in-process callers
+// without a session share the empty key.
+func sessionKey(ctx context.Context) string {
+	if session := server.ClientSessionFromContext(ctx); session != nil {
+		return session.SessionID()
+	}
+	return ""
+}
+
+// Get returns the preferences for the session behind ctx.
+func (p *preferenceStore) Get(ctx context.Context) shoppinglist.SessionPreferences {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.prefs[sessionKey(ctx)]
+}
+
+// Set replaces the preferences for the session behind ctx.
+func (p *preferenceStore) Set(ctx context.Context, prefs shoppinglist.SessionPreferences) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.prefs[sessionKey(ctx)] = prefs
+}
+
+// Forget drops the preferences of a closed session.
+func (p *preferenceStore) Forget(sessionID string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.prefs, sessionID)
+}
+
+// presentItems applies the calling session's preferences to items.
+func presentItems(ctx context.Context, items []shoppinglist.Item) []shoppinglist.Item {
+	return shoppinglist.ApplyPreferences(items, sessionPrefs.Get(ctx))
+}
+
+// displayFor returns display metadata for items when the calling session
+// asked for it, else nil.
+func displayFor(ctx context.Context, items []shoppinglist.Item) *shoppinglist.Display {
+	prefs := sessionPrefs.Get(ctx)
+	if !prefs.Display {
+		return nil
+	}
+	return shoppinglist.NewDisplay(items, prefs)
+}
+
+// sessionActor names who is behind the session: the member set in its
+// preferences, else the MCP client's name.
+func sessionActor(ctx context.Context) string {
+	if member := sessionPrefs.Get(ctx).Member; member != "" {
+		return member
+	}
+	if session, ok := server.ClientSessionFromContext(ctx).(server.SessionWithClientInfo); ok {
+		return session.GetClientInfo().Name
+	}
+	return ""
+}
+
+// sessionClient names the MCP client behind the session and its version.
+func sessionClient(ctx context.Context) string {
+	session, ok := server.ClientSessionFromContext(ctx).(server.SessionWithClientInfo)
+	if !ok {
+		return ""
+	}
+	info := session.GetClientInfo()
+	return strings.TrimSpace(info.Name + " " + info.Version)
+}
+
+// withSessionActor attributes the changes a tool call makes to the session's
+// actor and client.
+func withSessionActor(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx = shoppinglist.WithClient(shoppinglist.WithActor(ctx, sessionActor(ctx)), sessionClient(ctx))
+		return next(ctx, req)
+	}
+}
+
+func registerPreferenceTools(srv *server.MCPServer, hooks *server.Hooks) {
+	hooks.AddOnUnregisterSession(func(ctx context.Context, session server.ClientSession) {
+		sessionPrefs.Forget(session.SessionID())
+	})
+
+	// set_preferences
+	setPreferencesTool := mcp.NewTool(
+		"set_preferences",
+		mcp.WithDescription("Set defaults for the rest of this session (sort order, verbosity, whether checked-off items are included, locale, who is making changes, display metadata) so they don't need restating in each call. Omitted fields keep their current value."),
+		mcp.WithTitleAnnotation("Set Session Preferences"),
+		mcp.WithIdempotentHintAnnotation(true),
+		mcp.WithString("sort_by", mcp.Description("Sort items by this field (optional)"), mcp.Enum("none", "name", "created_at", "priority", "category")),
+		mcp.WithString("direction", mcp.Description("Sort direction (optional)"), mcp.Enum("asc", "desc")),
+		mcp.WithString("verbosity", mcp.Description("'compact' returns only id, name, quantity, parent_id, and purchased (optional)"), mcp.Enum("normal", "compact")),
+		mcp.WithBoolean("include_checked", mcp.Description("Whether checked-off items are included in listings (optional)")),
+		mcp.WithString("locale", mcp.Description("BCP 47 locale for formatting, e.g. en-US or de-DE (optional)")),
+		mcp.WithString("member", mcp.Description("Name of the household member using this session, recorded with their changes in recent_activity (optional)")),
+		mcp.WithBoolean("display", mcp.Description("Add a 'display' object to list responses with a color and emoji per category, a color per priority, and sort hints, for clients rendering the list as UI (optional)")),
+		mcp.WithBoolean("reset", mcp.Description("Clear all preferences before applying the other fields (optional)")),
+	)
+	srv.AddTool(setPreferencesTool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := req.GetArguments()
+
+		prefs := sessionPrefs.Get(ctx)
+		if reset, ok := args["reset"].(bool); ok && reset {
+			prefs = shoppinglist.SessionPreferences{}
+		}
+
+		// Extract optional fields
+		if v, ok := args["sort_by"].(string); ok && v != "" {
+			switch v {
+			case "none":
+				prefs.SortBy = ""
+			case "name", "created_at", "priority", "category":
+				prefs.SortBy = v
+			default:
+				return mcp.NewToolResultError(fmt.Sprintf("unsupported sort_by %q", v)), nil
+			}
+		}
+		if v, ok := args["direction"].(string); ok && v != "" {
+			if v != "asc" && v != "desc" {
+				return mcp.NewToolResultError(fmt.Sprintf("unsupported direction %q", v)), nil
+			}
+			prefs.Direction = v
+		}
+		if v, ok := args["verbosity"].(string); ok && v != "" {
+			if v != "normal" && v != "compact" {
+				return mcp.NewToolResultError(fmt.Sprintf("unsupported verbosity %q", v)), nil
+			}
+			prefs.Verbosity = v
+		}
+		if v, ok := args["include_checked"].(bool); ok {
+			prefs.IncludeChecked = &v
+		}
+		if v, ok := args["locale"].(string); ok && v != "" {
+			prefs.Locale = v
+		}
+		if v, ok := args["member"].(string); ok && v != "" {
+			prefs.Member = strings.TrimSpace(v)
+		}
+		if v, ok := args["display"].(bool); ok {
+			prefs.Display = v
+		}
+
+		sessionPrefs.Set(ctx, prefs)
+		return jsonResult(shoppinglist.PreferencesResponse{Preferences: prefs})
+	})
+
+	// get_preferences
+	getPreferencesTool := mcp.NewTool(
+		"get_preferences",
+		mcp.WithDescription("Show the preferences currently applied to this session."),
+		mcp.WithTitleAnnotation("Get Session Preferences"),
+		mcp.WithReadOnlyHintAnnotation(true),
+	)
+	srv.AddTool(getPreferencesTool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return jsonResult(shoppinglist.PreferencesResponse{Preferences: sessionPrefs.Get(ctx)})
+	})
+}
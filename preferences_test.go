@@ -0,0 +1,22 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/UnitVectorY-Labs/mcp-shopping-list-firestore/pkg/shoppinglist"
+)
+
+func TestPreferenceStoreIsolatesSessions(t *testing.T) {
+	store := &preferenceStore{prefs: map[string]shoppinglist.SessionPreferences{}}
+	ctx := context.Background()
+
+	store.Set(ctx, shoppinglist.SessionPreferences{SortBy: "name"})
+	if store.Get(ctx).SortBy != "name" {
+		t.Fatal("expected preferences to be stored")
+	}
+	store.Forget("")
+	if store.Get(ctx).SortBy != "" {
+		t.Fatal("expected preferences to be forgotten")
+	}
+}
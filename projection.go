@@ -0,0 +1,61 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+
+	"github.com/UnitVectorY-Labs/mcp-shopping-list-firestore/pkg/shoppinglist"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// -----------------------------------------------------------------------------
+// Projections
+// -----------------------------------------------------------------------------
+
+// projectionExclusive are the list_items arguments a projection cannot be
+// combined with, as they need fields it may not read.
+var projectionExclusive = []string{"summary", "nested", "group_by", "tags", "order_by", "status", "category", "format"}
+
+// listProjected answers list_items with only the 'fields' of each item, paged
+// when limit or after is set.
+func listProjected(ctx context.Context, svc *shoppinglist.Service, args map[string]any, raw any, limit int, after *shoppinglist.PageCursor) (*mcp.CallToolResult, error) {
+	for _, name := range projectionExclusive {
+		if v, ok := args[name]; ok && v != nil && v != false && v != "" && !(name == "format" && v == "json") {
+			return mcp.NewToolResultError(fmt.Sprintf("'fields' cannot be combined with '%s'", name)), nil
+		}
+	}
+	values, ok := raw.([]any)
+	if !ok {
+		return mcp.NewToolResultError("'fields' must be an array of strings"), nil
+	}
+	names := make([]string, 0, len(values))
+	for _, v := range values {
+		name, ok := v.(string)
+		if !ok {
+			return mcp.NewToolResultError("'fields' must be an array of strings"), nil
+		}
+		names = append(names, name)
+	}
+	fields, err := shoppinglist.ParseProjection(names)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	if after != nil {
+		limit = cmp.Or(limit, defaultPageSize)
+	}
+	view, next, err := svc.ViewFields(ctx, fields, limit, after)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to list items: %v", err)), nil
+	}
+	items, err := shoppinglist.ProjectItems(view.Items, fields)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("encode response: %v", err)), nil
+	}
+	resp := shoppinglist.ProjectedItemsResponse{Fields: fields, Items: items, ReadTime: &view.ReadTime, NextCursor: next}
+	if view.Freeze != nil {
+		resp.FrozenUntil = &view.Freeze.Until
+	}
+	return structuredResult(resp)
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/UnitVectorY-Labs/mcp-shopping-list-firestore/pkg/shoppinglist"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// -----------------------------------------------------------------------------
+// Checking items off
+// -----------------------------------------------------------------------------
+
+// itemIDByName finds the one item name refers to, matched as by
+// remove_item_by_name.
+func itemIDByName(ctx context.Context, svc *shoppinglist.Service, embedder shoppinglist.Embedder, name string) (string, error) {
+	items, err := svc.ListItems(ctx)
+	if err != nil {
+		return "", err
+	}
+	matches, err := shoppinglist.MatchItemName(ctx, svc.Normalizer(), embedder, name, items, 0.8)
+	if err != nil {
+		return "", err
+	}
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("no item matches %q", name)
+	case 1:
+		return matches[0].Item.ID, nil
+	}
+	candidates := make([]string, len(matches))
+	for i, m := range matches {
+		candidates[i] = fmt.Sprintf("%q (id %s)", m.Item.Name, m.Item.ID)
+	}
+	return "", fmt.Errorf("several items match %q, pass an id instead: %s", name, strings.Join(candidates, ", "))
+}
+
+func registerPurchasedTools(srv *server.MCPServer, service *shoppinglist.Service, embedder shoppinglist.Embedder, rates shoppinglist.ExchangeRateSource, currency string) {
+	// mark_purchased
+	markPurchasedTool := mcp.NewTool(
+		"mark_purchased",
+		mcp.WithDescription("Check an item off as purchased while shopping, or uncheck it. Without 'purchased' the current status is toggled."),
+		mcp.WithTitleAnnotation("Mark Item Purchased"),
+		mcp.WithString("id", mcp.Description("ID of the item"), mcp.Required()),
+		mcp.WithBoolean("purchased", mcp.Description("true to check the item off, false to put it back on the list (optional, toggles when omitted)")),
+		mcp.WithNumber("count", mcp.Description("On a count-mode list, how many were bought, or put back when unchecking; the item is checked off once none remain (optional, defaults to all)")),
+		listArg,
+	)
+	srv.AddTool(markPurchasedTool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := req.GetArguments()
+
+		// Extract required id field
+		id, ok := args["id"].(string)
+		if !ok || id == "" {
+			return mcp.NewToolResultError("invalid or missing 'id'"), nil
+		}
+
+		// Extract optional purchased field
+		var purchased *bool
+		if v, ok := args["purchased"].(bool); ok {
+			purchased = &v
+		}
+
+		// Extract optional count field
+		var count *float64
+		if v, ok := args["count"].(float64); ok {
+			count = &v
+		}
+
+		toolCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
+		defer cancel()
+
+		svc, err := listFromArgs(toolCtx, service, args)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to resolve list: %v", err)), nil
+		}
+
+		item, err := svc.MarkPurchased(toolCtx, id, purchased, count)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to mark item: %v", err)), nil
+		}
+		resp := shoppinglist.ItemResponse{Item: item}
+		resp.Shopping = shoppingTotals(toolCtx, svc, rates, currency, &resp.ResponseWarnings)
+		return jsonResult(resp)
+	})
+
+	// toggle_purchased
+	togglePurchasedTool := mcp.NewTool(
+		"toggle_purchased",
+		mcp.WithDescription("Flip an item between purchased and not purchased in one call, e.g. for \"check off eggs\", and return its new state. Name the item by 'id', or by 'name' when the ID is not known."),
+		mcp.WithTitleAnnotation("Toggle Item Purchased"),
+		mcp.WithString("id", mcp.Description("ID of the item (optional when 'name' is given)")),
+		mcp.WithString("name", mcp.Description("Name of the item, matched ignoring case or by similarity; refused when several items match (optional when 'id' is given)")),
+		listArg,
+	)
+	srv.AddTool(togglePurchasedTool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := req.GetArguments()
+
+		// Extract id or name field
+		id, _ := args["id"].(string)
+		name, _ := args["name"].(string)
+		if id == "" && strings.TrimSpace(name) == "" {
+			return mcp.NewToolResultError("either 'id' or 'name' is required"), nil
+		}
+
+		toolCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
+		defer cancel()
+
+		svc, err := listFromArgs(toolCtx, service, args)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to resolve list: %v", err)), nil
+		}
+		if id == "" {
+			if id, err = itemIDByName(toolCtx, svc, embedder, name); err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("failed to find item: %v", err)), nil
+			}
+		}
+
+		resp, err := svc.TogglePurchased(toolCtx, id)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to toggle item: %v", err)), nil
+		}
+		resp.Shopping = shoppingTotals(toolCtx, svc, rates, currency, &resp.ResponseWarnings)
+		return jsonResult(resp)
+	})
+
+	// clear_purchased
+	clearPurchasedTool := mcp.NewTool(
+		"clear_purchased",
+		mcp.WithDescription("Clean up after a shopping trip: delete every item checked off as purchased in one operation, optionally archiving them as a trip first."),
+		mcp.WithTitleAnnotation("Clear Purchased Items"),
+		mcp.WithBoolean("archive", mcp.Description("Keep the cleared items as an archived trip in the purchase history (optional, defaults to false)")),
+		listArg,
+	)
+	srv.AddTool(clearPurchasedTool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := req.GetArguments()
+
+		// Extract optional archive field
+		archive, _ := args["archive"].(bool)
+
+		toolCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
+		defer cancel()
+
+		svc, err := listFromArgs(toolCtx, service, args)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to resolve list: %v", err)), nil
+		}
+
+		resp, err := svc.ClearPurchased(toolCtx, archive)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to clear purchased items: %v", err)), nil
+		}
+		return jsonResult(resp)
+	})
+}
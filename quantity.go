@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/UnitVectorY-Labs/mcp-shopping-list-firestore/pkg/shoppinglist"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// -----------------------------------------------------------------------------
+// Structured quantities
+// -----------------------------------------------------------------------------
+
+func registerQuantityTools(srv *server.MCPServer, service *shoppinglist.Service) {
+	// adjust_quantity
+	adjustQuantityTool := mcp.NewTool(
+		"adjust_quantity",
+		mcp.WithDescription("Atomically add to or take from an item's numeric quantity, e.g. delta 2 turns '4 apples' into '6 apples' and delta -1 turns '500 g' into '499 g'. Safe when several people adjust the same item at once."),
+		mcp.WithTitleAnnotation("Adjust Item Quantity"),
+		mcp.WithString("id", mcp.Description("ID of the item"), mcp.Required()),
+		mcp.WithNumber("delta", mcp.Description("Amount to add; negative to subtract"), mcp.Required()),
+		listArg,
+	)
+	srv.AddTool(adjustQuantityTool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := req.GetArguments()
+
+		// Extract required fields
+		id, ok := args["id"].(string)
+		if !ok || id == "" {
+			return mcp.NewToolResultError("invalid or missing 'id'"), nil
+		}
+		delta, ok := args["delta"].(float64)
+		if !ok || delta == 0 {
+			return mcp.NewToolResultError("invalid or missing 'delta' (expected a non-zero number)"), nil
+		}
+
+		toolCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
+		defer cancel()
+
+		svc, err := listFromArgs(toolCtx, service, args)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to resolve list: %v", err)), nil
+		}
+
+		item, err := svc.AdjustQuantity(toolCtx, id, delta)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to adjust quantity: %v", err)), nil
+		}
+		return jsonResult(shoppinglist.ItemResponse{Item: item})
+	})
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/UnitVectorY-Labs/mcp-shopping-list-firestore/pkg/shoppinglist"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// -----------------------------------------------------------------------------
+// Receipt reconciliation
+// -----------------------------------------------------------------------------
+
+// receiptMatchThreshold is the default similarity needed to match a receipt
+// line to an item whose normalized name differs.
+const receiptMatchThreshold = 0.8
+
+// receiptPrompt asks the client's model to turn OCR text into receipt lines.
+const receiptPrompt = `Extract the purchased line items from this shopping receipt. Reply with only a JSON array of objects with "name" (the product, without codes or abbreviations where you can expand them), "price" (the line total as a number), and optional "quantity". Skip totals, taxes, discounts, and payment lines.`
+
+// samplingText returns the text of a sampling reply.
+func samplingText(result *mcp.CreateMessageResult) string {
+	switch c := result.Content.(type) {
+	case mcp.TextContent:
+		return c.Text
+	case map[string]any:
+		text, _ := c["text"].(string)
+		return text
+	}
+	return ""
+}
+
+// parseReceiptText asks the client, via sampling, to extract lines from OCR text.
+func parseReceiptText(ctx context.Context, srv *server.MCPServer, text string) ([]shoppinglist.ReceiptLine, error) {
+	if !sessionFeatures.Get(ctx).Sampling {
+		return nil, fmt.Errorf("the client does not support sampling; pass structured 'lines' instead")
+	}
+	result, err := srv.RequestSampling(ctx, mcp.CreateMessageRequest{
+		CreateMessageParams: mcp.CreateMessageParams{
+			SystemPrompt: receiptPrompt,
+			Messages: []mcp.SamplingMessage{{
+				Role:    mcp.RoleUser,
+				Content: mcp.NewTextContent(text),
+			}},
+			MaxTokens:   2000,
+			Temperature: 0,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("sampling: %w", err)
+	}
+
+	reply := strings.TrimSpace(samplingText(result))
+	reply = strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(reply, "```json"), "```"), "```")
+
+	var lines []shoppinglist.ReceiptLine
+	if err := json.Unmarshal([]byte(strings.TrimSpace(reply)), &lines); err != nil {
+		return nil, fmt.Errorf("decode receipt lines from model reply: %w", err)
+	}
+	return lines, nil
+}
+
+// receiptLinesFromArgs decodes the 'lines' array argument.
+func receiptLinesFromArgs(args map[string]any) ([]shoppinglist.ReceiptLine, error) {
+	raw, _ := args["lines"].([]any)
+	lines := make([]shoppinglist.ReceiptLine, 0, len(raw))
+	for i, r := range raw {
+		m, ok := r.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected an object", i)
+		}
+		name, _ := m["name"].(string)
+		if strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("line %d: invalid or missing 'name'", i)
+		}
+		price, ok := m["price"].(float64)
+		if !ok {
+			return nil, fmt.Errorf("line %d: invalid or missing 'price'", i)
+		}
+		quantity, _ := m["quantity"].(string)
+		lines = append(lines, shoppinglist.ReceiptLine{Name: name, Price: price, Quantity: quantity})
+	}
+	return lines, nil
+}
+
+func registerReceiptTools(srv *server.MCPServer, service *shoppinglist.Service, embedder shoppinglist.Embedder, currency string) {
+	srv.EnableSampling()
+
+	// reconcile_receipt
+	reconcileReceiptTool := mcp.NewTool(
+		"reconcile_receipt",
+		mcp.WithDescription("Reconcile a shopping receipt with the list: match its lines to items, record the prices paid to the purchase history, and check the matched items off. Pass structured 'lines', or raw OCR 'text' to have the client's model extract them."),
+		mcp.WithTitleAnnotation("Reconcile Receipt"),
+		mcp.WithArray("lines",
+			mcp.Description("Receipt line items (optional if 'text' is given)"),
+			mcp.Items(map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"name":     map[string]any{"type": "string", "description": "Product name"},
+					"price":    map[string]any{"type": "number", "description": "Price paid for the line"},
+					"quantity": map[string]any{"type": "string", "description": "Quantity bought (optional)"},
+				},
+				"required": []string{"name", "price"},
+			}),
+		),
+		mcp.WithString("text", mcp.Description("OCR text of the receipt, parsed through sampling when 'lines' is omitted (optional)")),
+		mcp.WithString("store", mcp.Description("Store the receipt is from (optional)")),
+		mcp.WithString("currency", mcp.Description(fmt.Sprintf("ISO 4217 currency of the prices (optional, defaults to %s)", currency))),
+		mcp.WithString("purchased_at", mcp.Description("RFC 3339 time of purchase (optional, defaults to now)")),
+		mcp.WithNumber("threshold", mcp.Description(fmt.Sprintf("Minimum similarity for fuzzy matches between 0 and 1 (optional, defaults to %.1f)", receiptMatchThreshold))),
+		listArg,
+	)
+	srv.AddTool(reconcileReceiptTool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := req.GetArguments()
+
+		// Extract lines, or OCR text to parse into lines
+		lines, err := receiptLinesFromArgs(args)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		text, _ := args["text"].(string)
+		if len(lines) == 0 && strings.TrimSpace(text) == "" {
+			return mcp.NewToolResultError("provide receipt 'lines' or OCR 'text'"), nil
+		}
+
+		// Extract optional fields
+		store, _ := args["store"].(string)
+		lineCurrency := currency
+		if c, ok := args["currency"].(string); ok && c != "" {
+			normalized, err := shoppinglist.NormalizeCurrency(c)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			lineCurrency = normalized
+		}
+		purchasedAt := service.Now()
+		if v, ok := args["purchased_at"].(string); ok && v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("invalid 'purchased_at': %v", err)), nil
+			}
+			purchasedAt = t.UTC()
+		}
+		threshold := receiptMatchThreshold
+		if v, ok := args["threshold"].(float64); ok {
+			if v < 0 || v > 1 {
+				return mcp.NewToolResultError("'threshold' must be between 0 and 1"), nil
+			}
+			threshold = v
+		}
+
+		toolCtx, cancel := context.WithTimeout(ctx, 60*time.Second)
+		defer cancel()
+
+		if len(lines) == 0 {
+			lines, err = parseReceiptText(toolCtx, srv, text)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("failed to parse receipt text (clients without sampling support must pass 'lines'): %v", err)), nil
+			}
+		}
+
+		svc, err := listFromArgs(toolCtx, service, args)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to resolve list: %v", err)), nil
+		}
+
+		items, err := svc.ListItems(toolCtx)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to list items: %v", err)), nil
+		}
+		matched, unmatched, err := shoppinglist.MatchReceipt(toolCtx, svc.Normalizer(), embedder, lines, items, threshold)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to match receipt: %v", err)), nil
+		}
+
+		receiptID := svc.NewID()
+		record := func(line shoppinglist.ReceiptLine, itemID string) shoppinglist.PurchaseRecord {
+			return shoppinglist.PurchaseRecord{
+				ID:          svc.NewID(),
+				ReceiptID:   receiptID,
+				ItemID:      itemID,
+				Name:        line.Name,
+				Quantity:    line.Quantity,
+				Price:       line.Price,
+				Currency:    lineCurrency,
+				Store:       store,
+				PurchasedAt: purchasedAt,
+			}
+		}
+		records := make([]shoppinglist.PurchaseRecord, 0, len(lines))
+		for _, m := range matched {
+			records = append(records, record(m.Line, m.ItemID))
+		}
+		for _, line := range unmatched {
+			records = append(records, record(line, ""))
+		}
+		if err := svc.RecordPurchases(toolCtx, records); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to reconcile receipt: %v", err)), nil
+		}
+
+		items, err = svc.ListItems(toolCtx)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to list items: %v", err)), nil
+		}
+		resp := shoppinglist.ReconcileResponse{ReceiptID: receiptID, Matched: matched, Unmatched: unmatched, Remaining: []shoppinglist.Item{}}
+		if resp.Matched == nil {
+			resp.Matched = []shoppinglist.ReceiptMatch{}
+		}
+		for _, it := range items {
+			if !it.Purchased {
+				resp.Remaining = append(resp.Remaining, it)
+			}
+		}
+		for _, m := range matched {
+			if m.Score < 1 {
+				resp.Warn(shoppinglist.WarnFuzzyMatch, m.ItemID, "matched receipt line %q to %q with similarity %.2f", m.Line.Name, m.ItemName, m.Score)
+			}
+		}
+		resp.Remaining = presentItems(ctx, resp.Remaining)
+		return jsonResult(resp)
+	})
+}
@@ -0,0 +1,15 @@
+package main
+
+import "testing"
+
+func TestReceiptLinesFromArgs(t *testing.T) {
+	lines, err := receiptLinesFromArgs(map[string]any{"lines": []any{
+		map[string]any{"name": "milk", "price": 1.29, "quantity": "2"},
+	}})
+	if err != nil || len(lines) != 1 || lines[0].Quantity != "2" {
+		t.Fatalf("unexpected lines %+v (%v)", lines, err)
+	}
+	if _, err := receiptLinesFromArgs(map[string]any{"lines": []any{map[string]any{"name": "milk"}}}); err == nil {
+		t.Fatal("expected error for a line without a price")
+	}
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/UnitVectorY-Labs/mcp-shopping-list-firestore/pkg/shoppinglist"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// -----------------------------------------------------------------------------
+// Recipe reservations
+// -----------------------------------------------------------------------------
+
+// ingredientsFromArgs decodes the 'ingredients' array argument.
+func ingredientsFromArgs(args map[string]any) ([]shoppinglist.RecipeIngredient, error) {
+	raw, ok := args["ingredients"].([]any)
+	if !ok || len(raw) == 0 {
+		return nil, errors.New("invalid or missing 'ingredients'")
+	}
+	ingredients := make([]shoppinglist.RecipeIngredient, 0, len(raw))
+	for i, r := range raw {
+		m, ok := r.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("ingredient %d: expected an object", i)
+		}
+		name, _ := m["name"].(string)
+		if strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("ingredient %d: invalid or missing 'name'", i)
+		}
+		quantity, ok := m["quantity"].(float64)
+		if !ok || quantity <= 0 {
+			return nil, fmt.Errorf("ingredient %d: 'quantity' must be a positive number", i)
+		}
+		unit, _ := m["unit"].(string)
+		ingredients = append(ingredients, shoppinglist.RecipeIngredient{Name: name, Quantity: quantity, Unit: unit})
+	}
+	return ingredients, nil
+}
+
+func registerRecipeTools(srv *server.MCPServer, service *shoppinglist.Service) {
+	// add_recipe
+	addRecipeTool := mcp.NewTool(
+		"add_recipe",
+		mcp.WithDescription("Add a planned recipe's ingredients to the list. Quantities are merged into existing items of the same name and remembered per recipe so remove_recipe can take them back out exactly."),
+		mcp.WithTitleAnnotation("Add Recipe Ingredients"),
+		mcp.WithString("recipe", mcp.Description("Recipe reference, e.g. 'Taco night'"), mcp.Required()),
+		mcp.WithArray("ingredients",
+			mcp.Description("Ingredients the recipe needs"),
+			mcp.Required(),
+			mcp.Items(map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"name":     map[string]any{"type": "string", "description": "Item name"},
+					"quantity": map[string]any{"type": "number", "description": "Amount needed"},
+					"unit":     map[string]any{"type": "string", "description": "Unit of the amount, e.g. 'g' (optional)"},
+				},
+				"required": []string{"name", "quantity"},
+			}),
+		),
+		listArg,
+	)
+	srv.AddTool(addRecipeTool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := req.GetArguments()
+
+		// Extract required fields
+		recipe, ok := args["recipe"].(string)
+		if !ok || strings.TrimSpace(recipe) == "" {
+			return mcp.NewToolResultError("invalid or missing 'recipe'"), nil
+		}
+		ingredients, err := ingredientsFromArgs(args)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		toolCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
+		defer cancel()
+
+		svc, err := listFromArgs(toolCtx, service, args)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to resolve list: %v", err)), nil
+		}
+
+		resp, err := svc.AddRecipe(toolCtx, recipe, ingredients)
+		if err != nil {
+			var frozen *shoppinglist.ListFrozenError
+			if errors.As(err, &frozen) {
+				return mcp.NewToolResultError(frozen.Error()), nil
+			}
+			return mcp.NewToolResultError(fmt.Sprintf("failed to add recipe: %v", err)), nil
+		}
+		return jsonResult(resp)
+	})
+
+	// remove_recipe
+	removeRecipeTool := mcp.NewTool(
+		"remove_recipe",
+		mcp.WithDescription("Cancel a planned recipe, subtracting exactly the quantities it contributed. Items only the recipe needed are removed; others keep their remaining amount."),
+		mcp.WithTitleAnnotation("Remove Recipe Ingredients"),
+		mcp.WithString("recipe", mcp.Description("Recipe reference used when it was added"), mcp.Required()),
+		listArg,
+	)
+	srv.AddTool(removeRecipeTool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := req.GetArguments()
+
+		// Extract required recipe field
+		recipe, ok := args["recipe"].(string)
+		if !ok || strings.TrimSpace(recipe) == "" {
+			return mcp.NewToolResultError("invalid or missing 'recipe'"), nil
+		}
+
+		toolCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
+		defer cancel()
+
+		svc, err := listFromArgs(toolCtx, service, args)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to resolve list: %v", err)), nil
+		}
+
+		resp, err := svc.RemoveRecipe(toolCtx, recipe)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to remove recipe: %v", err)), nil
+		}
+		return jsonResult(resp)
+	})
+}
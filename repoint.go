@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/UnitVectorY-Labs/mcp-shopping-list-firestore/pkg/shoppinglist"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// -----------------------------------------------------------------------------
+// Collection re-pointing
+// -----------------------------------------------------------------------------
+
+// listMovingCode is the tool error code of a write to a list whose writes are
+// paused while it is re-pointed.
+const listMovingCode = "list_moving"
+
+// listMovingRetryAfter is how many seconds clients are asked to wait before
+// retrying a write paused by a move.
+const listMovingRetryAfter = 5
+
+// withListMoves refuses calls that may write to a list while it is being
+// re-pointed, with a retry hint, so no write lands in the old collection
+// after the last copy. Read-only tools keep working throughout.
+func withListMoves(service *shoppinglist.Service) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			srv := server.ServerFromContext(ctx)
+			if srv == nil || collectionFromContext(ctx) != nil {
+				return next(ctx, req)
+			}
+			tool := srv.GetTool(req.Params.Name)
+			if tool == nil || (tool.Tool.Annotations.ReadOnlyHint != nil && *tool.Tool.Annotations.ReadOnlyHint) {
+				return next(ctx, req)
+			}
+			args := req.GetArguments()
+			refs := []string{""}
+			if ref, ok := args["list"].(string); ok {
+				refs[0] = ref
+			}
+			if to, ok := args["to"].(string); ok && req.Params.Name == "move_item" {
+				refs = append(refs, to)
+			}
+			for _, ref := range refs {
+				if service.Moving(ref) {
+					msg := "the list is being moved to another collection and writes are paused for a few seconds; retry after retry_after_ms"
+					return errorResult(toolError{Code: listMovingCode, Error: msg, RetryAfterMS: (listMovingRetryAfter * time.Second).Milliseconds()}), nil
+				}
+			}
+			return next(ctx, req)
+		}
+	}
+}
+
+func registerRepointTools(srv *server.MCPServer, service *shoppinglist.Service) {
+	// repoint_list
+	repointTool := mcp.NewTool(
+		"repoint_list",
+		mcp.WithDescription("Move a list's items and history to another Firestore collection, optionally in another database of the project, without stopping the server: the list is copied while it stays in use, writes to it are paused for a few seconds while the last changes are copied and the copy is verified, and then every instance switches to the new collection. The old collections are kept and no longer written. Another database must be in a location --require-location allows. Requires an owner token."),
+		mcp.WithTitleAnnotation("Re-point List"),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithString("list", mcp.Description("ID, slug, or former slug of the list (optional, defaults to the main list)")),
+		mcp.WithString("collection", mcp.Description("Empty collection to move the list to, e.g. 'shopping_v2'"), mcp.Required()),
+		mcp.WithString("database", mcp.Description("Firestore database of the project to move the list to (optional, defaults to the database the list is in)")),
+	)
+	srv.AddTool(repointTool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := req.GetArguments()
+
+		// Extract required collection field
+		collection, ok := args["collection"].(string)
+		if !ok || collection == "" {
+			return mcp.NewToolResultError("invalid or missing 'collection'"), nil
+		}
+		// Extract optional list field
+		ref, _ := args["list"].(string)
+		// Extract optional database field
+		database, _ := args["database"].(string)
+
+		toolCtx, cancel := context.WithTimeout(ctx, 10*time.Minute)
+		defer cancel()
+
+		report, err := service.RepointList(toolCtx, ref, collection, database)
+		if errors.Is(err, shoppinglist.ErrListNotFound) {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to re-point list: %v", err)), nil
+		}
+		return jsonResult(report)
+	})
+}
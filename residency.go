@@ -0,0 +1,19 @@
+package main
+
+import "strings"
+
+// -----------------------------------------------------------------------------
+// Data residency
+// -----------------------------------------------------------------------------
+
+// parseLocations splits a comma-separated list of allowed Firestore locations,
+// e.g. "europe-west1,eur3".
+func parseLocations(spec string) []string {
+	var locations []string
+	for _, l := range strings.Split(spec, ",") {
+		if l = strings.ToLower(strings.TrimSpace(l)); l != "" {
+			locations = append(locations, l)
+		}
+	}
+	return locations
+}
@@ -0,0 +1,10 @@
+package main
+
+import "testing"
+
+func TestParseLocations(t *testing.T) {
+	got := parseLocations(" Europe-West1, eur3 ,,")
+	if len(got) != 2 || got[0] != "europe-west1" || got[1] != "eur3" {
+		t.Fatalf("unexpected locations: %q", got)
+	}
+}
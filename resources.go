@@ -0,0 +1,263 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/UnitVectorY-Labs/mcp-shopping-list-firestore/pkg/shoppinglist"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// -----------------------------------------------------------------------------
+// List resources
+// -----------------------------------------------------------------------------
+
+// listURI is the resource holding the main list as JSON.
+const listURI = "shoppinglist://list"
+
+// itemURIPrefix begins the URI of the resource holding one item of the main
+// list, followed by its ID.
+const itemURIPrefix = "shoppinglist://item/"
+
+// listResource is one format the main list is offered in as a resource.
+type listResource struct {
+	format, uri, mimeType, name, description string
+}
+
+// listResources are the formats of the main list, the JSON one first.
+var listResources = []listResource{
+	{"json", listURI, "application/json", "Shopping list",
+		"The shopping list as list_items returns it: every item with its quantity, category, and purchased state, and the time it was read."},
+	{"markdown", listURI + "/markdown", "text/markdown", "Shopping list checklist",
+		"The shopping list as a markdown checklist grouped by store section, ready to show to the user."},
+	{"csv", listURI + "/csv", "text/csv", "Shopping list (CSV)",
+		"The shopping list as CSV, one row per item, as export_list writes it."},
+}
+
+// renderList encodes resp in format for a list resource.
+func renderList(format string, resp shoppinglist.ListItemsResponse) (string, error) {
+	switch format {
+	case "json":
+		b, err := json.MarshalIndent(resp, "", "  ")
+		return string(b), err
+	case "markdown":
+		return shoppinglist.RenderChecklist(resp.Items), nil
+	default:
+		return shoppinglist.RenderExport(format, resp.Items)
+	}
+}
+
+// registerListResources offers the main list, in each of its formats, as a
+// resource clients can attach as context without calling list_items.
+func registerListResources(srv *server.MCPServer, hooks *server.Hooks, service *shoppinglist.Service) {
+	watch := &listWatch{srv: srv, items: service, subscribers: map[string]map[string]bool{}}
+	refuseSubscriptions(hooks, func(ctx context.Context, uri string) error {
+		if !watchable(uri) {
+			return nil
+		}
+		return checkAccess(tokenFromContext(ctx), uri, shoppinglist.ScopeRead, service.DefaultList())
+	})
+	hooks.AddAfterSubscribe(func(ctx context.Context, id any, req *mcp.SubscribeRequest, result *mcp.EmptyResult) {
+		if watchable(req.Params.URI) {
+			watch.subscribe(sessionKey(ctx), req.Params.URI)
+		}
+	})
+	hooks.AddAfterUnsubscribe(func(ctx context.Context, id any, req *mcp.UnsubscribeRequest, result *mcp.EmptyResult) {
+		watch.unsubscribe(sessionKey(ctx), req.Params.URI)
+	})
+	hooks.AddOnUnregisterSession(func(ctx context.Context, session server.ClientSession) {
+		watch.forget(session.SessionID())
+	})
+
+	for _, r := range listResources {
+		srv.AddResource(
+			mcp.NewResource(
+				r.uri,
+				r.name,
+				mcp.WithResourceDescription(r.description),
+				mcp.WithMIMEType(r.mimeType),
+			),
+			func(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+				if err := checkAccess(tokenFromContext(ctx), r.uri, shoppinglist.ScopeRead, service.DefaultList()); err != nil {
+					return nil, err
+				}
+
+				readCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+				defer cancel()
+
+				view, staleAsOf, err := service.ViewOrStale(readCtx)
+				if err != nil {
+					return nil, fmt.Errorf("read list: %w", err)
+				}
+				resp := shoppinglist.ListItemsResponse{Items: view.Items, StaleAsOf: staleAsOf}
+				if staleAsOf != nil {
+					resp.Warn(shoppinglist.WarnStaleData, "", "Firestore is unavailable; showing the list as of %s", staleAsOf.Format(time.RFC3339))
+				} else {
+					resp.ReadTime = &view.ReadTime
+				}
+				if view.Freeze != nil {
+					resp.FrozenUntil = &view.Freeze.Until
+				}
+				text, err := renderList(r.format, resp)
+				if err != nil {
+					return nil, err
+				}
+				return []mcp.ResourceContents{
+					mcp.TextResourceContents{URI: r.uri, MIMEType: r.mimeType, Text: text},
+				}, nil
+			},
+		)
+	}
+}
+
+// itemIDFromURI is the ID of the item a shoppinglist://item/{id} URI names.
+func itemIDFromURI(uri string) (string, error) {
+	id, err := url.PathUnescape(strings.TrimPrefix(uri, itemURIPrefix))
+	if err != nil || id == "" || !strings.HasPrefix(uri, itemURIPrefix) || strings.Contains(id, "/") {
+		return "", fmt.Errorf("invalid item URI %q", uri)
+	}
+	return id, nil
+}
+
+// watchable reports whether uri is a list or item resource that sessions can
+// subscribe to.
+func watchable(uri string) bool {
+	if slices.ContainsFunc(listResources, func(r listResource) bool { return r.uri == uri }) {
+		return true
+	}
+	_, err := itemIDFromURI(uri)
+	return err == nil
+}
+
+// affected reports whether a change to the items with ids changes the
+// resource at uri: every list resource, or the resource of one of the items.
+func affected(uri string, ids []string) bool {
+	if id, err := itemIDFromURI(uri); err == nil {
+		return slices.Contains(ids, id)
+	}
+	return watchable(uri)
+}
+
+// itemWatcher is the part of the service listWatch listens to.
+type itemWatcher interface {
+	WatchItems(ctx context.Context, changed func(ids []string))
+}
+
+// listWatch listens to the main list while sessions are subscribed to its
+// resources and tells them when the resources they subscribed to change,
+// whichever instance or device made the change.
+type listWatch struct {
+	srv   *server.MCPServer
+	items itemWatcher
+
+	mu          sync.Mutex
+	subscribers map[string]map[string]bool
+	cancel      context.CancelFunc
+}
+
+func (w *listWatch) subscribe(sessionID, uri string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.subscribers[sessionID] == nil {
+		w.subscribers[sessionID] = map[string]bool{}
+	}
+	w.subscribers[sessionID][uri] = true
+	if w.cancel == nil {
+		var ctx context.Context
+		ctx, w.cancel = context.WithCancel(context.Background())
+		go w.items.WatchItems(ctx, w.changed)
+	}
+}
+
+func (w *listWatch) unsubscribe(sessionID, uri string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	delete(w.subscribers[sessionID], uri)
+	if len(w.subscribers[sessionID]) == 0 {
+		delete(w.subscribers, sessionID)
+	}
+	w.stopIfIdle()
+}
+
+func (w *listWatch) forget(sessionID string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	delete(w.subscribers, sessionID)
+	w.stopIfIdle()
+}
+
+// stopIfIdle stops listening once the last subscriber leaves. w.mu must be
+// held.
+func (w *listWatch) stopIfIdle() {
+	if len(w.subscribers) == 0 && w.cancel != nil {
+		w.cancel()
+		w.cancel = nil
+	}
+}
+
+// changed notifies the sessions subscribed to resources the items with ids
+// are part of.
+func (w *listWatch) changed(ids []string) {
+	type notice struct{ sessionID, uri string }
+	var notices []notice
+	w.mu.Lock()
+	for sessionID, uris := range w.subscribers {
+		for uri := range uris {
+			if affected(uri, ids) {
+				notices = append(notices, notice{sessionID, uri})
+			}
+		}
+	}
+	w.mu.Unlock()
+
+	for _, n := range notices {
+		err := w.srv.SendNotificationToSpecificClient(n.sessionID, "notifications/resources/updated", map[string]any{"uri": n.uri})
+		if err != nil {
+			w.forget(n.sessionID)
+		}
+	}
+}
+
+// registerItemResources offers each item of the main list as a resource, so
+// clients can reference one item as context.
+func registerItemResources(srv *server.MCPServer, service *shoppinglist.Service) {
+	srv.AddResourceTemplate(
+		mcp.NewResourceTemplate(
+			itemURIPrefix+"{id}",
+			"Shopping item",
+			mcp.WithTemplateDescription("One item of the shopping list by its ID, as get_item returns it."),
+			mcp.WithTemplateMIMEType("application/json"),
+		),
+		func(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+			id, err := itemIDFromURI(req.Params.URI)
+			if err != nil {
+				return nil, err
+			}
+			if err := checkAccess(tokenFromContext(ctx), req.Params.URI, shoppinglist.ScopeRead, service.DefaultList()); err != nil {
+				return nil, err
+			}
+
+			readCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+			defer cancel()
+
+			it, err := service.GetItem(readCtx, id)
+			if err != nil {
+				return nil, err
+			}
+			b, err := json.MarshalIndent(shoppinglist.ItemResponse{Item: it}, "", "  ")
+			if err != nil {
+				return nil, err
+			}
+			return []mcp.ResourceContents{
+				mcp.TextResourceContents{URI: req.Params.URI, MIMEType: "application/json", Text: string(b)},
+			}, nil
+		},
+	)
+}
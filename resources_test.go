@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/UnitVectorY-Labs/mcp-shopping-list-firestore/pkg/shoppinglist"
+)
+
+func TestRenderList(t *testing.T) {
+	produce := "produce"
+	resp := shoppinglist.ListItemsResponse{Items: []shoppinglist.Item{{ID: "a", Name: "Apples", Category: &produce}, {ID: "b", Name: "Milk", Purchased: true}}}
+
+	text, err := renderList("json", resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded shoppinglist.ListItemsResponse
+	if err := json.Unmarshal([]byte(text), &decoded); err != nil || len(decoded.Items) != 2 {
+		t.Fatalf("expected the list as JSON, got %q (%v)", text, err)
+	}
+
+	text, err = renderList("markdown", resp)
+	if err != nil || !strings.Contains(text, "- [ ] Apples") || !strings.Contains(text, "- [x] Milk") {
+		t.Fatalf("expected a checklist, got %q (%v)", text, err)
+	}
+
+	text, err = renderList("csv", resp)
+	if err != nil || !strings.Contains(text, "Apples") || strings.Count(text, "\n") != 3 {
+		t.Fatalf("expected a header and a row per item, got %q (%v)", text, err)
+	}
+}
+
+func TestListResourcesHaveDistinctURIs(t *testing.T) {
+	seen := map[string]bool{}
+	for _, r := range listResources {
+		if seen[r.uri] || !strings.HasPrefix(r.uri, listURI) {
+			t.Fatalf("unexpected resource URI %q", r.uri)
+		}
+		seen[r.uri] = true
+		if _, ok := exportFormats[r.format]; !ok {
+			t.Fatalf("format %q has no MIME type", r.format)
+		}
+		if exportFormats[r.format] != r.mimeType {
+			t.Fatalf("%s is %s, export_list says %s", r.uri, r.mimeType, exportFormats[r.format])
+		}
+	}
+}
+
+func TestItemIDFromURI(t *testing.T) {
+	if id, err := itemIDFromURI("shoppinglist://item/abc123"); err != nil || id != "abc123" {
+		t.Fatalf("itemIDFromURI = %q, %v", id, err)
+	}
+	if id, err := itemIDFromURI("shoppinglist://item/oat%20milk"); err != nil || id != "oat milk" {
+		t.Fatalf("expected the ID unescaped, got %q, %v", id, err)
+	}
+	for _, uri := range []string{"shoppinglist://item/", "shoppinglist://item/a/b", "shoppinglist://list", "shoppinglist://item/%zz"} {
+		if _, err := itemIDFromURI(uri); err == nil {
+			t.Fatalf("expected %q to be rejected", uri)
+		}
+	}
+}
+
+func TestAffectedResources(t *testing.T) {
+	ids := []string{"a", "b"}
+	for uri, want := range map[string]bool{
+		"shoppinglist://list":          true,
+		"shoppinglist://list/markdown": true,
+		"shoppinglist://item/a":        true,
+		"shoppinglist://item/c":        false,
+		"shoppinglist://dashboard":     false,
+	} {
+		if got := affected(uri, ids); got != want {
+			t.Errorf("affected(%q) = %v, want %v", uri, got, want)
+		}
+	}
+	if watchable("shoppinglist://dashboard") || !watchable("shoppinglist://item/c") {
+		t.Fatal("expected only list and item resources to be watched")
+	}
+}
+
+func TestListWatchStopsWithLastSubscriber(t *testing.T) {
+	stopped := false
+	w := &listWatch{subscribers: map[string]map[string]bool{
+		"s1": {listURI: true, "shoppinglist://item/a": true},
+		"s2": {listURI: true},
+	}, cancel: func() { stopped = true }}
+
+	w.unsubscribe("s1", listURI)
+	w.forget("s2")
+	if stopped {
+		t.Fatal("stopped listening with a subscriber left")
+	}
+	w.unsubscribe("s1", "shoppinglist://item/a")
+	if !stopped || w.cancel != nil || len(w.subscribers) != 0 {
+		t.Fatalf("expected the watch to stop, got %+v", w.subscribers)
+	}
+}
+
+// fakeWatcher records the listeners it runs and reports when each stops.
+type fakeWatcher struct {
+	started chan struct{}
+	stopped chan struct{}
+}
+
+func (f *fakeWatcher) WatchItems(ctx context.Context, changed func(ids []string)) {
+	f.started <- struct{}{}
+	<-ctx.Done()
+	f.stopped <- struct{}{}
+}
+
+func TestListWatchListensWhileSubscribed(t *testing.T) {
+	items := &fakeWatcher{started: make(chan struct{}, 2), stopped: make(chan struct{}, 2)}
+	w := &listWatch{items: items, subscribers: map[string]map[string]bool{}}
+
+	w.subscribe("s1", listURI)
+	w.subscribe("s2", "shoppinglist://item/a")
+	select {
+	case <-items.started:
+	case <-time.After(time.Second):
+		t.Fatal("expected a listener once a session subscribed")
+	}
+
+	w.unsubscribe("s1", listURI)
+	select {
+	case <-items.stopped:
+		t.Fatal("expected the listener to keep running for the other session")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	w.forget("s2")
+	select {
+	case <-items.stopped:
+	case <-time.After(time.Second):
+		t.Fatal("expected the listener to stop after the last session left")
+	}
+	if len(items.started) != 0 {
+		t.Fatal("expected a single listener for both sessions")
+	}
+}
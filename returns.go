@@ -0,0 +1,36 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// -----------------------------------------------------------------------------
+// What writes return
+// -----------------------------------------------------------------------------
+
+// The values of a write's return argument and of -return: the whole list
+// after the write, or only the item it changed.
+const (
+	returnList = "list"
+	returnItem = "item"
+)
+
+// returnArg is the return argument of upsert_item and remove_item, with the
+// server's default def.
+func returnArg(def string) mcp.ToolOption {
+	return mcp.WithString("return", mcp.Description(fmt.Sprintf("Return the whole 'list' after the change, or only the 'item' changed, which saves reading the list on large lists (optional, defaults to %s)", def)), mcp.Enum(returnList, returnItem))
+}
+
+// returnFromArgs reads a write's optional return argument, defaulting to def.
+func returnFromArgs(args map[string]any, def string) (string, error) {
+	v, _ := args["return"].(string)
+	switch v {
+	case "":
+		return def, nil
+	case returnList, returnItem:
+		return v, nil
+	}
+	return "", fmt.Errorf("unsupported return %q (expected %s or %s)", v, returnList, returnItem)
+}
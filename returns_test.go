@@ -0,0 +1,23 @@
+package main
+
+import "testing"
+
+func TestReturnFromArgs(t *testing.T) {
+	for _, tc := range []struct {
+		args map[string]any
+		def  string
+		want string
+	}{
+		{map[string]any{}, returnList, returnList},
+		{map[string]any{}, returnItem, returnItem},
+		{map[string]any{"return": "item"}, returnList, returnItem},
+		{map[string]any{"return": "list"}, returnItem, returnList},
+	} {
+		if got, err := returnFromArgs(tc.args, tc.def); err != nil || got != tc.want {
+			t.Fatalf("returnFromArgs(%v, %q) = %q, %v, want %q", tc.args, tc.def, got, err, tc.want)
+		}
+	}
+	if _, err := returnFromArgs(map[string]any{"return": "diff"}, returnList); err == nil {
+		t.Fatal("expected an unknown return to be rejected")
+	}
+}
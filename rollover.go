@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/UnitVectorY-Labs/mcp-shopping-list-firestore/pkg/shoppinglist"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// -----------------------------------------------------------------------------
+// Weekly rollover
+// -----------------------------------------------------------------------------
+
+func registerRolloverTools(srv *server.MCPServer, service *shoppinglist.Service, template []shoppinglist.TemplateItem) {
+	// rollover_list
+	rolloverListTool := mcp.NewTool(
+		"rollover_list",
+		mcp.WithDescription("Start a new shopping week now: archive purchased items as a completed trip, put purchased staples back on the list, add template items, and keep unpurchased items."),
+		mcp.WithTitleAnnotation("Roll Over Shopping List"),
+		listArg,
+	)
+	srv.AddTool(rolloverListTool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := req.GetArguments()
+
+		toolCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		defer cancel()
+
+		svc, err := listFromArgs(toolCtx, service, args)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to resolve list: %v", err)), nil
+		}
+
+		summary, err := svc.Rollover(toolCtx, template, time.Time{})
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to roll over list: %v", err)), nil
+		}
+		return jsonResult(summary)
+	})
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// -----------------------------------------------------------------------------
+// Tool contract schemas
+// -----------------------------------------------------------------------------
+
+// toolResultSchema describes the MCP result returned by tools that do not
+// declare a structured output schema.
+var toolResultSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"content": map[string]any{
+			"type": "array",
+			"items": map[string]any{
+				"type":     "object",
+				"required": []string{"type"},
+				"properties": map[string]any{
+					"type": map[string]any{"type": "string"},
+					"text": map[string]any{"type": "string"},
+				},
+			},
+		},
+		"isError": map[string]any{"type": "boolean"},
+	},
+	"required": []string{"content"},
+}
+
+// buildSchemaBundle describes every registered tool as an OpenAPI 3.1
+// document: each tool is a POST operation on /tools/{name} whose request body
+// is the tool's input schema. The raw MCP tool definitions are kept under
+// x-mcp-tools for clients that consume them directly.
+func buildSchemaBundle(srv *server.MCPServer, version string) (map[string]any, error) {
+	registered := srv.ListTools()
+	names := make([]string, 0, len(registered))
+	for name := range registered {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	paths := map[string]any{}
+	schemas := map[string]any{"ToolResult": toolResultSchema}
+	tools := make([]json.RawMessage, 0, len(names))
+	for _, name := range names {
+		raw, err := json.Marshal(registered[name].Tool)
+		if err != nil {
+			return nil, fmt.Errorf("encode tool %q: %w", name, err)
+		}
+		tools = append(tools, raw)
+
+		var def struct {
+			Description  string          `json:"description"`
+			InputSchema  json.RawMessage `json:"inputSchema"`
+			OutputSchema json.RawMessage `json:"outputSchema"`
+			Annotations  struct {
+				Title        string `json:"title"`
+				ReadOnlyHint *bool  `json:"readOnlyHint"`
+			} `json:"annotations"`
+		}
+		if err := json.Unmarshal(raw, &def); err != nil {
+			return nil, fmt.Errorf("decode tool %q: %w", name, err)
+		}
+
+		schemas[name+"Input"] = def.InputSchema
+		output := "ToolResult"
+		if len(def.OutputSchema) > 0 {
+			output = name + "Output"
+			schemas[output] = def.OutputSchema
+		}
+
+		op := map[string]any{
+			"operationId": name,
+			"summary":     def.Annotations.Title,
+			"description": def.Description,
+			"requestBody": map[string]any{
+				"required": true,
+				"content": map[string]any{
+					"application/json": map[string]any{"schema": map[string]any{"$ref": "#/components/schemas/" + name + "Input"}},
+				},
+			},
+			"responses": map[string]any{
+				"200": map[string]any{
+					"description": "Tool result",
+					"content": map[string]any{
+						"application/json": map[string]any{"schema": map[string]any{"$ref": "#/components/schemas/" + output}},
+					},
+				},
+			},
+		}
+		if def.Annotations.ReadOnlyHint != nil {
+			op["x-read-only"] = *def.Annotations.ReadOnlyHint
+		}
+		paths["/tools/"+name] = map[string]any{"post": op}
+	}
+
+	return map[string]any{
+		"openapi": "3.1.0",
+		"info": map[string]any{
+			"title":   "mcp-shopping-list-firestore",
+			"version": version,
+		},
+		"paths":       paths,
+		"components":  map[string]any{"schemas": schemas},
+		"x-mcp-tools": tools,
+	}, nil
+}
+
+// writeSchemaBundle writes the indented schema bundle to w.
+func writeSchemaBundle(w io.Writer, srv *server.MCPServer, version string) error {
+	bundle, err := buildSchemaBundle(srv, version)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(bundle)
+}
+
+func registerSchemaTools(srv *server.MCPServer) {
+	// schema
+	schemaTool := mcp.NewTool(
+		"schema",
+		mcp.WithDescription("Describe every tool's input and output schema as an OpenAPI 3.1 / JSON Schema bundle, for client generators and contract tests."),
+		mcp.WithTitleAnnotation("Tool Contract Schemas"),
+		mcp.WithReadOnlyHintAnnotation(true),
+	)
+	srv.AddTool(schemaTool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		bundle, err := buildSchemaBundle(srv, Version)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to build schema: %v", err)), nil
+		}
+		return jsonResult(bundle)
+	})
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/UnitVectorY-Labs/mcp-shopping-list-firestore/pkg/shoppinglist"
+)
+
+func TestBuildSchemaBundleDescribesEveryTool(t *testing.T) {
+	srv := newMCPServer(nil, shoppinglist.LocalEmbedder{Dims: 256}, serverConfig{currency: "USD", rates: shoppinglist.RateTable{Base: "USD", PerBase: map[string]float64{"USD": 1}}})
+
+	bundle, err := buildSchemaBundle(srv, "test")
+	if err != nil {
+		t.Fatalf("buildSchemaBundle returned error: %v", err)
+	}
+	paths := bundle["paths"].(map[string]any)
+	if len(paths) != len(srv.ListTools()) {
+		t.Fatalf("expected %d paths, got %d", len(srv.ListTools()), len(paths))
+	}
+	if _, ok := paths["/tools/upsert_item"]; !ok {
+		t.Fatal("expected upsert_item to be described")
+	}
+	schemas := bundle["components"].(map[string]any)["schemas"].(map[string]any)
+	input, ok := schemas["upsert_itemInput"].(json.RawMessage)
+	if !ok || !bytes.Contains(input, []byte(`"name"`)) {
+		t.Fatalf("expected upsert_item input schema with a name property, got %s", input)
+	}
+}
+
+func TestWriteSchemaBundleIsValidJSON(t *testing.T) {
+	srv := newMCPServer(nil, shoppinglist.LocalEmbedder{Dims: 256}, serverConfig{currency: "USD"})
+
+	var buf bytes.Buffer
+	if err := writeSchemaBundle(&buf, srv, "test"); err != nil {
+		t.Fatalf("writeSchemaBundle returned error: %v", err)
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("expected valid JSON: %v", err)
+	}
+	if doc["openapi"] != "3.1.0" {
+		t.Fatalf("unexpected openapi version %v", doc["openapi"])
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/UnitVectorY-Labs/mcp-shopping-list-firestore/pkg/shoppinglist"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// -----------------------------------------------------------------------------
+// Name search
+// -----------------------------------------------------------------------------
+
+// searchMaxLimit caps how many matches one search_items call returns.
+const searchMaxLimit = 100
+
+func registerSearchTools(srv *server.MCPServer, service *shoppinglist.Service) {
+	// search_items
+	searchItemsTool := mcp.NewTool(
+		"search_items",
+		mcp.WithDescription("Search the list by item name, e.g. to check whether milk is already on it without listing everything. Names starting with the query match first; if none do, names containing it anywhere match, ignoring case. Returns the matching items with their IDs."),
+		mcp.WithTitleAnnotation("Search Shopping Items"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("name", mcp.Description("Name, or the start or part of one, to search for"), mcp.Required()),
+		mcp.WithNumber("limit", mcp.Description(fmt.Sprintf("Maximum number of matches (optional, defaults to 20, at most %d)", searchMaxLimit))),
+		listArg,
+	)
+	srv.AddTool(searchItemsTool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := req.GetArguments()
+
+		// Extract required name field
+		name, ok := args["name"].(string)
+		if !ok || name == "" {
+			return mcp.NewToolResultError("invalid or missing 'name'"), nil
+		}
+
+		// Extract optional limit field
+		limit := 20
+		if v, ok := args["limit"].(float64); ok {
+			if v < 1 || v > searchMaxLimit {
+				return mcp.NewToolResultError(fmt.Sprintf("'limit' must be between 1 and %d", searchMaxLimit)), nil
+			}
+			limit = int(v)
+		}
+
+		toolCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+
+		svc, err := listFromArgs(toolCtx, service, args)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to resolve list: %v", err)), nil
+		}
+
+		resp, err := svc.SearchItems(toolCtx, name, limit)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to search items: %v", err)), nil
+		}
+		return jsonResult(resp)
+	})
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/api/option"
+	"google.golang.org/api/secretmanager/v1"
+)
+
+// secretScheme prefixes config values naming a Secret Manager secret rather
+// than holding the value itself.
+const secretScheme = "sm://"
+
+// secret is a config value such as OWNER_TOKEN that may be given as a Secret
+// Manager reference, resolved at startup and optionally refreshed so rotated
+// secrets are picked up without a restart.
+type secret struct {
+	// name is the secret version's resource name, empty for a plain value.
+	name  string
+	value atomic.Pointer[string]
+}
+
+// newSecret returns the secret for the config value raw. A value starting
+// with sm:// names a secret version as sm://projects/P/secrets/S/versions/V;
+// the version defaults to latest, and sm://S names secret S of project.
+func newSecret(raw, project string) (*secret, error) {
+	s := &secret{}
+	ref, ok := strings.CutPrefix(raw, secretScheme)
+	if !ok {
+		s.value.Store(&raw)
+		return s, nil
+	}
+	name, err := secretVersionName(ref, project)
+	if err != nil {
+		return nil, fmt.Errorf("secret %q: %w", raw, err)
+	}
+	s.name = name
+	return s, nil
+}
+
+// secretVersionName expands a reference without its sm:// prefix to the
+// resource name of a secret version.
+func secretVersionName(ref, project string) (string, error) {
+	parts := strings.Split(ref, "/")
+	switch {
+	case len(parts) == 1 && parts[0] != "":
+		if project == "" {
+			return "", fmt.Errorf("no project to look the secret up in")
+		}
+		return "projects/" + project + "/secrets/" + parts[0] + "/versions/latest", nil
+	case len(parts) == 4 && parts[0] == "projects" && parts[2] == "secrets":
+		parts = append(parts, "versions", "latest")
+	case len(parts) == 6 && parts[0] == "projects" && parts[2] == "secrets" && parts[4] == "versions":
+	default:
+		return "", fmt.Errorf("expected sm://SECRET or sm://projects/PROJECT/secrets/SECRET[/versions/VERSION]")
+	}
+	for _, p := range parts {
+		if p == "" {
+			return "", fmt.Errorf("expected sm://SECRET or sm://projects/PROJECT/secrets/SECRET[/versions/VERSION]")
+		}
+	}
+	return strings.Join(parts, "/"), nil
+}
+
+// Value returns the secret's current value, empty until it is resolved.
+func (s *secret) Value() string {
+	if v := s.value.Load(); v != nil {
+		return *v
+	}
+	return ""
+}
+
+// secretManager reads secret versions from Secret Manager.
+type secretManager struct {
+	svc *secretmanager.Service
+}
+
+// newSecretManager connects to Secret Manager only when one of secrets is a
+// reference, so servers configured with plain values need no access to it.
+func newSecretManager(ctx context.Context, credentialsPath string, secrets ...*secret) (*secretManager, error) {
+	if !hasSecretRefs(secrets) {
+		return nil, nil
+	}
+	var opts []option.ClientOption
+	if credentialsPath != "" {
+		opts = append(opts, option.WithCredentialsFile(credentialsPath))
+	}
+	svc, err := secretmanager.NewService(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("create Secret Manager client: %w", err)
+	}
+	return &secretManager{svc: svc}, nil
+}
+
+func hasSecretRefs(secrets []*secret) bool {
+	for _, s := range secrets {
+		if s.name != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// Resolve reads every secret reference, failing on the first that cannot be
+// read.
+func (m *secretManager) Resolve(ctx context.Context, secrets ...*secret) error {
+	for _, s := range secrets {
+		if s.name == "" {
+			continue
+		}
+		v, err := m.access(ctx, s.name)
+		if err != nil {
+			return err
+		}
+		s.value.Store(&v)
+	}
+	return nil
+}
+
+func (m *secretManager) access(ctx context.Context, name string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	resp, err := m.svc.Projects.Secrets.Versions.Access(name).Context(ctx).Do()
+	if err != nil {
+		return "", fmt.Errorf("access secret %s: %w", name, err)
+	}
+	if resp.Payload == nil {
+		return "", fmt.Errorf("access secret %s: no payload", name)
+	}
+	data, err := base64.StdEncoding.DecodeString(resp.Payload.Data)
+	if err != nil {
+		return "", fmt.Errorf("decode secret %s: %w", name, err)
+	}
+	// Secrets added with `echo ... | gcloud secrets versions add` end in a
+	// newline that is not part of the token.
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// RunRefresh re-reads the secret references every interval until ctx is
+// done. A failed read keeps the previous value, so an outage of Secret
+// Manager does not lock clients out.
+func (m *secretManager) RunRefresh(ctx context.Context, interval time.Duration, secrets ...*secret) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		for _, s := range secrets {
+			if s.name == "" {
+				continue
+			}
+			v, err := m.access(ctx, s.name)
+			if err != nil {
+				log.Printf("warn: refresh secret: %v", err)
+				continue
+			}
+			if v != s.Value() {
+				log.Printf("secret %s changed", s.name)
+				s.value.Store(&v)
+			}
+		}
+	}
+}
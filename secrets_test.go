@@ -0,0 +1,42 @@
+package main
+
+import "testing"
+
+func plainSecret(v string) *secret {
+	s := &secret{}
+	s.value.Store(&v)
+	return s
+}
+
+func TestNewSecret(t *testing.T) {
+	for raw, want := range map[string]string{
+		"sm://owner-token":                                   "projects/groceries/secrets/owner-token/versions/latest",
+		"sm://projects/vault/secrets/owner-token":            "projects/vault/secrets/owner-token/versions/latest",
+		"sm://projects/vault/secrets/owner-token/versions/3": "projects/vault/secrets/owner-token/versions/3",
+	} {
+		s, err := newSecret(raw, "groceries")
+		if err != nil {
+			t.Fatalf("%s: %v", raw, err)
+		}
+		if s.name != want || s.Value() != "" {
+			t.Fatalf("%s: got name %q value %q, want %q unresolved", raw, s.name, s.Value(), want)
+		}
+	}
+
+	s, err := newSecret("plain-token", "groceries")
+	if err != nil || s.name != "" || s.Value() != "plain-token" {
+		t.Fatalf("expected a plain value to be kept, got %+v, %v", s, err)
+	}
+	if hasSecretRefs([]*secret{s}) {
+		t.Fatal("expected no Secret Manager references")
+	}
+
+	for _, raw := range []string{"sm://", "sm://projects/vault/secrets", "sm://projects/vault/keys/owner-token", "sm://projects//secrets/owner-token"} {
+		if _, err := newSecret(raw, "groceries"); err == nil {
+			t.Fatalf("expected %q to be rejected", raw)
+		}
+	}
+	if _, err := newSecret("sm://owner-token", ""); err == nil {
+		t.Fatal("expected a short reference without a project to be rejected")
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/UnitVectorY-Labs/mcp-shopping-list-firestore/pkg/shoppinglist"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// -----------------------------------------------------------------------------
+// Shopping sessions
+// -----------------------------------------------------------------------------
+
+// shoppingTotals returns the running totals of the list's shopping session,
+// or nil when there is none, and warns once the session has ended.
+func shoppingTotals(ctx context.Context, svc *shoppinglist.Service, rates shoppinglist.ExchangeRateSource, currency string, warnings *shoppinglist.ResponseWarnings) *shoppinglist.ShoppingTotals {
+	session, err := svc.ShoppingSession(ctx)
+	if err != nil || session == nil {
+		return nil
+	}
+	totals := shoppinglist.SessionTotals(ctx, rates, currency, session)
+	warnings.Warnings = append(warnings.Warnings, totals.Warnings...)
+	totals.Warnings = nil
+	if !session.Active(svc.Now()) {
+		warnings.Warn(shoppinglist.WarnSessionEnded, "", "the shopping session ended at %s, so checks are no longer recorded against it; finish it with finish_shopping", session.EndsAt.UTC().Format(time.RFC3339))
+	}
+	return &totals
+}
+
+func registerShoppingSessionTools(srv *server.MCPServer, service *shoppinglist.Service, rates shoppinglist.ExchangeRateSource, currency string) {
+	// start_shopping
+	startShoppingTool := mcp.NewTool(
+		"start_shopping",
+		mcp.WithDescription("Start an in-store shopping session on a list: until it ends, items checked off are timestamped against the session and its total accumulates with each check. Finish it with finish_shopping."),
+		mcp.WithTitleAnnotation("Start Shopping"),
+		mcp.WithNumber("minutes", mcp.Description(fmt.Sprintf("How long the session records checks for, at most %d (optional, defaults to %d)", int(shoppinglist.MaxSessionLength.Minutes()), int(shoppinglist.DefaultSessionLength.Minutes())))),
+		mcp.WithString("store", mcp.Description("Store being shopped at, kept on the purchase records (optional)")),
+		listArg,
+	)
+	srv.AddTool(startShoppingTool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := req.GetArguments()
+
+		// Extract optional minutes field
+		var length time.Duration
+		if v, ok := args["minutes"].(float64); ok {
+			if v <= 0 {
+				return mcp.NewToolResultError("'minutes' must be positive"), nil
+			}
+			length = time.Duration(v * float64(time.Minute))
+		}
+
+		// Extract optional store field
+		store, _ := args["store"].(string)
+
+		toolCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
+		defer cancel()
+
+		svc, err := listFromArgs(toolCtx, service, args)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to resolve list: %v", err)), nil
+		}
+
+		session, err := svc.StartShopping(toolCtx, length, store)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to start shopping: %v", err)), nil
+		}
+		return jsonResult(shoppinglist.SessionTotals(toolCtx, rates, currency, session))
+	})
+
+	// finish_shopping
+	finishShoppingTool := mcp.NewTool(
+		"finish_shopping",
+		mcp.WithDescription("Finish the list's shopping session in one transaction: archive the items checked off during it as a trip, record them in the purchase history at their expected prices, and remove them from the list."),
+		mcp.WithTitleAnnotation("Finish Shopping"),
+		listArg,
+	)
+	srv.AddTool(finishShoppingTool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := req.GetArguments()
+
+		toolCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		defer cancel()
+
+		svc, err := listFromArgs(toolCtx, service, args)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to resolve list: %v", err)), nil
+		}
+
+		resp, err := svc.FinishShopping(toolCtx, rates, currency)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to finish shopping: %v", err)), nil
+		}
+		return jsonResult(resp)
+	})
+}
@@ -0,0 +1,354 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"cloud.google.com/go/firestore"
+	admin "cloud.google.com/go/firestore/apiv1/admin"
+	"cloud.google.com/go/firestore/apiv1/admin/adminpb"
+	"github.com/UnitVectorY-Labs/mcp-shopping-list-firestore/pkg/shoppinglist"
+	"google.golang.org/api/cloudresourcemanager/v3"
+	"google.golang.org/api/option"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// -----------------------------------------------------------------------------
+// Config file and setup wizard
+// -----------------------------------------------------------------------------
+
+// FileConfig is the connection settings saved by `init`, and any extra
+// collections and instructions for agents added by hand. Environment
+// variables and flags take precedence over it.
+type FileConfig struct {
+	Project      string             `json:"project"`
+	Database     string             `json:"database"`
+	Collection   string             `json:"collection,omitempty"`
+	Credentials  string             `json:"credentials,omitempty"`
+	Collections  []CollectionConfig `json:"collections,omitempty"`
+	Instructions string             `json:"instructions,omitempty"`
+}
+
+// defaultConfigPath is where `init` writes the config file and the server
+// looks for it, e.g. ~/.config/mcp-shopping-list-firestore/config.json.
+func defaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "mcp-shopping-list-firestore", "config.json")
+}
+
+// LoadConfig reads the config file at path. A missing file yields an empty
+// config unless required is set.
+func LoadConfig(path string, required bool) (FileConfig, error) {
+	var cfg FileConfig
+	if path == "" {
+		return cfg, nil
+	}
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) && !required {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, fmt.Errorf("read config: %w", err)
+	}
+	if err := json.Unmarshal(b, &cfg); err != nil {
+		return cfg, fmt.Errorf("parse config %s: %w", path, err)
+	}
+	if err := validateCollections(cfg.Collections); err != nil {
+		return cfg, fmt.Errorf("config %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// WriteConfig saves cfg to path, readable only by the current user.
+func WriteConfig(path string, cfg FileConfig) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("create config directory: %w", err)
+	}
+	b, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, append(b, '\n'), 0o600); err != nil {
+		return fmt.Errorf("write config: %w", err)
+	}
+	return nil
+}
+
+// firstNonEmpty returns the first of values that is not empty.
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// auxiliarySuffixes mark the collections the server keeps beside a list's
+// items, which are not offered as item collections.
+var auxiliarySuffixes = []string{"_meta", "_activity", "_purchases", "_trips", "_incidents", "_telemetry", "_tombstones", "_audit", "_lists", "_flags"}
+
+// isAuxiliaryCollection reports whether name holds server bookkeeping or
+// another list's items rather than a default list.
+func isAuxiliaryCollection(name string) bool {
+	if strings.Contains(name, "_list_") {
+		return true
+	}
+	return slices.ContainsFunc(auxiliarySuffixes, func(s string) bool { return strings.HasSuffix(name, s) })
+}
+
+// setupCloud is what the wizard looks up and changes in Google Cloud.
+type setupCloud interface {
+	Projects(ctx context.Context) ([]string, error)
+	Databases(ctx context.Context, project string) ([]string, error)
+	Collections(ctx context.Context, project, database string) ([]string, error)
+	EnableTTL(ctx context.Context, project, database string, collectionGroups []string) error
+	Verify(ctx context.Context, cfg FileConfig) (int, error)
+}
+
+// gcpSetup implements setupCloud with the caller's credentials.
+type gcpSetup struct {
+	opts []option.ClientOption
+}
+
+func (g gcpSetup) Projects(ctx context.Context) ([]string, error) {
+	crm, err := cloudresourcemanager.NewService(ctx, g.opts...)
+	if err != nil {
+		return nil, fmt.Errorf("create resource manager client: %w", err)
+	}
+	var projects []string
+	err = crm.Projects.Search().Query("state:ACTIVE").Pages(ctx, func(resp *cloudresourcemanager.SearchProjectsResponse) error {
+		for _, p := range resp.Projects {
+			projects = append(projects, p.ProjectId)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list projects: %w", err)
+	}
+	slices.Sort(projects)
+	return projects, nil
+}
+
+func (g gcpSetup) Databases(ctx context.Context, project string) ([]string, error) {
+	client, err := admin.NewFirestoreAdminClient(ctx, g.opts...)
+	if err != nil {
+		return nil, fmt.Errorf("create firestore admin client: %w", err)
+	}
+	defer client.Close()
+
+	resp, err := client.ListDatabases(ctx, &adminpb.ListDatabasesRequest{Parent: "projects/" + project})
+	if err != nil {
+		return nil, fmt.Errorf("list databases: %w", err)
+	}
+	databases := make([]string, 0, len(resp.GetDatabases()))
+	for _, db := range resp.GetDatabases() {
+		databases = append(databases, path.Base(db.GetName()))
+	}
+	slices.Sort(databases)
+	return databases, nil
+}
+
+func (g gcpSetup) Collections(ctx context.Context, project, database string) ([]string, error) {
+	client, err := firestore.NewClientWithDatabase(ctx, project, database, g.opts...)
+	if err != nil {
+		return nil, fmt.Errorf("create firestore client: %w", err)
+	}
+	defer client.Close()
+
+	refs, err := client.Collections(ctx).GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("list collections: %w", err)
+	}
+	var collections []string
+	for _, ref := range refs {
+		if !isAuxiliaryCollection(ref.ID) {
+			collections = append(collections, ref.ID)
+		}
+	}
+	return collections, nil
+}
+
+// EnableTTL starts a TTL policy on expire_at for each collection group. The
+// policies take effect in the background, typically within minutes.
+func (g gcpSetup) EnableTTL(ctx context.Context, project, database string, collectionGroups []string) error {
+	client, err := admin.NewFirestoreAdminClient(ctx, g.opts...)
+	if err != nil {
+		return fmt.Errorf("create firestore admin client: %w", err)
+	}
+	defer client.Close()
+
+	for _, group := range collectionGroups {
+		_, err := client.UpdateField(ctx, &adminpb.UpdateFieldRequest{
+			Field: &adminpb.Field{
+				Name:      fmt.Sprintf("projects/%s/databases/%s/collectionGroups/%s/fields/expire_at", project, database, group),
+				TtlConfig: &adminpb.Field_TtlConfig{},
+			},
+			UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"ttl_config"}},
+		})
+		if err != nil {
+			return fmt.Errorf("enable TTL on %s: %w", group, err)
+		}
+	}
+	return nil
+}
+
+// Verify connects the way the server will and reads the list.
+func (g gcpSetup) Verify(ctx context.Context, cfg FileConfig) (int, error) {
+	service, err := shoppinglist.NewService(ctx, cfg.Project, cfg.Database, cfg.Collection, cfg.Credentials)
+	if err != nil {
+		return 0, err
+	}
+	defer service.Close()
+	items, err := service.ListItems(ctx)
+	return len(items), err
+}
+
+// wizard asks for the settings on in and reports on out.
+type wizard struct {
+	in    *bufio.Reader
+	out   io.Writer
+	cloud setupCloud
+}
+
+// ask prints prompt and returns the trimmed answer, or def when it is empty.
+func (w *wizard) ask(prompt, def string) (string, error) {
+	if def != "" {
+		fmt.Fprintf(w.out, "%s [%s]: ", prompt, def)
+	} else {
+		fmt.Fprintf(w.out, "%s: ", prompt)
+	}
+	line, err := w.in.ReadString('\n')
+	if err != nil && (!errors.Is(err, io.EOF) || line == "") {
+		return "", fmt.Errorf("read answer: %w", err)
+	}
+	if answer := strings.TrimSpace(line); answer != "" {
+		return answer, nil
+	}
+	return def, nil
+}
+
+// choose offers options by number; the answer may also be typed out, e.g. a
+// project the credentials cannot list.
+func (w *wizard) choose(prompt string, options []string, def string) (string, error) {
+	for i, o := range options {
+		fmt.Fprintf(w.out, "  %d) %s\n", i+1, o)
+	}
+	for {
+		answer, err := w.ask(prompt, def)
+		if err != nil {
+			return "", err
+		}
+		if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(options) {
+			return options[n-1], nil
+		}
+		if answer != "" {
+			return answer, nil
+		}
+	}
+}
+
+// confirm asks a yes/no question.
+func (w *wizard) confirm(prompt string, def bool) (bool, error) {
+	hint := "y/N"
+	if def {
+		hint = "Y/n"
+	}
+	answer, err := w.ask(prompt+" ("+hint+")", "")
+	if err != nil {
+		return false, err
+	}
+	switch strings.ToLower(answer) {
+	case "":
+		return def, nil
+	case "y", "yes":
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
+// lookup runs a listing and reports, rather than fails on, errors so the
+// user can still type a value.
+func (w *wizard) lookup(what string, list func() ([]string, error)) []string {
+	values, err := list()
+	if err != nil {
+		fmt.Fprintf(w.out, "Could not list %s (%v); type one instead.\n", what, err)
+		return nil
+	}
+	if len(values) == 0 {
+		fmt.Fprintf(w.out, "No %s found; type one instead.\n", what)
+	}
+	return values
+}
+
+// Run walks through project, database, and collection selection, writes the
+// config to path, and optionally enables TTL policies and verifies access.
+func (w *wizard) Run(ctx context.Context, path string, current FileConfig) error {
+	cfg := current
+
+	fmt.Fprintln(w.out, "Projects your credentials can see:")
+	projects := w.lookup("projects", func() ([]string, error) { return w.cloud.Projects(ctx) })
+	project, err := w.choose("Project", projects, cfg.Project)
+	if err != nil {
+		return err
+	}
+	cfg.Project = project
+
+	fmt.Fprintf(w.out, "Firestore databases in %s:\n", cfg.Project)
+	databases := w.lookup("databases", func() ([]string, error) { return w.cloud.Databases(ctx, cfg.Project) })
+	database, err := w.choose("Database", databases, firstNonEmpty(cfg.Database, "(default)"))
+	if err != nil {
+		return err
+	}
+	cfg.Database = database
+
+	fmt.Fprintf(w.out, "Collections in %s:\n", cfg.Database)
+	collections := w.lookup("collections", func() ([]string, error) { return w.cloud.Collections(ctx, cfg.Project, cfg.Database) })
+	collection, err := w.choose("Collection for the default list", collections, firstNonEmpty(cfg.Collection, "shopping"))
+	if err != nil {
+		return err
+	}
+	cfg.Collection = collection
+
+	if err := WriteConfig(path, cfg); err != nil {
+		return err
+	}
+	fmt.Fprintf(w.out, "Wrote %s\n", path)
+
+	ttl, err := w.confirm("Enable Firestore TTL policies on expire_at so history past --retention is deleted automatically?", false)
+	if err != nil {
+		return err
+	}
+	if ttl {
+		groups := make([]string, 0, len(shoppinglist.RetentionKinds))
+		for _, kind := range shoppinglist.RetentionKindNames() {
+			groups = append(groups, cfg.Collection+shoppinglist.RetentionKinds[kind].Suffix)
+		}
+		if err := w.cloud.EnableTTL(ctx, cfg.Project, cfg.Database, groups); err != nil {
+			return err
+		}
+		fmt.Fprintf(w.out, "Requested TTL policies on %s; they take effect within a few minutes.\n", strings.Join(groups, ", "))
+	}
+
+	fmt.Fprintln(w.out, "Verifying access...")
+	n, err := w.cloud.Verify(ctx, cfg)
+	if err != nil {
+		return fmt.Errorf("verify: %w", err)
+	}
+	fmt.Fprintf(w.out, "OK: read %d items from %s/%s. The server will use these settings.\n", n, cfg.Database, cfg.Collection)
+	return nil
+}
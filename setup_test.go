@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type fakeSetup struct {
+	projects    []string
+	databases   []string
+	collections []string
+	listErr     error
+	ttl         []string
+	verified    FileConfig
+}
+
+func (f *fakeSetup) Projects(context.Context) ([]string, error) { return f.projects, f.listErr }
+func (f *fakeSetup) Databases(context.Context, string) ([]string, error) {
+	return f.databases, nil
+}
+func (f *fakeSetup) Collections(context.Context, string, string) ([]string, error) {
+	return f.collections, nil
+}
+func (f *fakeSetup) EnableTTL(_ context.Context, _, _ string, groups []string) error {
+	f.ttl = groups
+	return nil
+}
+func (f *fakeSetup) Verify(_ context.Context, cfg FileConfig) (int, error) {
+	f.verified = cfg
+	return 3, nil
+}
+
+func TestConfigRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "config.json")
+	if cfg, err := LoadConfig(path, false); err != nil || !reflect.DeepEqual(cfg, FileConfig{}) {
+		t.Fatalf("expected a missing optional config to be empty, got %+v, %v", cfg, err)
+	}
+	if _, err := LoadConfig(path, true); err == nil {
+		t.Fatal("expected an error for a missing required config")
+	}
+
+	want := FileConfig{Project: "household", Database: "(default)", Collection: "groceries", Instructions: "Always set a category."}
+	if err := WriteConfig(path, want); err != nil {
+		t.Fatalf("WriteConfig returned error: %v", err)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0o600 {
+		t.Fatalf("expected a private config file, got %v, %v", info.Mode(), err)
+	}
+	if got, err := LoadConfig(path, true); err != nil || !reflect.DeepEqual(got, want) {
+		t.Fatalf("LoadConfig = %+v, %v, want %+v", got, err, want)
+	}
+}
+
+func TestIsAuxiliaryCollection(t *testing.T) {
+	for name, want := range map[string]bool{
+		"shopping":               false,
+		"shopping_meta":          true,
+		"shopping_activity":      true,
+		"shopping_lists":         true,
+		"shopping_list_abc":      true,
+		"shopping_list_abc_meta": true,
+		"pantry":                 false,
+	} {
+		if got := isAuxiliaryCollection(name); got != want {
+			t.Errorf("isAuxiliaryCollection(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestWizardRun(t *testing.T) {
+	cloud := &fakeSetup{
+		projects:    []string{"household", "work"},
+		databases:   []string{"(default)", "lists"},
+		collections: []string{"shopping"},
+	}
+	var out strings.Builder
+	w := &wizard{in: bufio.NewReader(strings.NewReader("1\n2\ngroceries\ny\n")), out: &out, cloud: cloud}
+	path := filepath.Join(t.TempDir(), "config.json")
+
+	if err := w.Run(context.Background(), path, FileConfig{Credentials: "key.json"}); err != nil {
+		t.Fatalf("Run returned error: %v\n%s", err, out.String())
+	}
+	want := FileConfig{Project: "household", Database: "lists", Collection: "groceries", Credentials: "key.json"}
+	if got, _ := LoadConfig(path, true); !reflect.DeepEqual(got, want) {
+		t.Fatalf("wrote %+v, want %+v", got, want)
+	}
+	if !reflect.DeepEqual(cloud.verified, want) {
+		t.Fatalf("verified with %+v, want %+v", cloud.verified, want)
+	}
+	wantTTL := []string{"groceries_activity", "groceries_audit", "groceries", "groceries_incidents", "groceries_purchases", "groceries_telemetry", "groceries_tombstones", "groceries_trips"}
+	if !reflect.DeepEqual(cloud.ttl, wantTTL) {
+		t.Fatalf("TTL on %v, want %v", cloud.ttl, wantTTL)
+	}
+	if !strings.Contains(out.String(), "read 3 items") {
+		t.Fatalf("expected the verification result, got:\n%s", out.String())
+	}
+}
+
+func TestWizardFallsBackToTypedAnswersAndDefaults(t *testing.T) {
+	cloud := &fakeSetup{listErr: errors.New("permission denied")}
+	var out strings.Builder
+	// A typed project, then defaults for the database and collection, and no TTL.
+	w := &wizard{in: bufio.NewReader(strings.NewReader("my-project\n\n\n\n")), out: &out, cloud: cloud}
+	path := filepath.Join(t.TempDir(), "config.json")
+
+	if err := w.Run(context.Background(), path, FileConfig{}); err != nil {
+		t.Fatalf("Run returned error: %v\n%s", err, out.String())
+	}
+	want := FileConfig{Project: "my-project", Database: "(default)", Collection: "shopping"}
+	if got, _ := LoadConfig(path, true); !reflect.DeepEqual(got, want) {
+		t.Fatalf("wrote %+v, want %+v", got, want)
+	}
+	if cloud.ttl != nil {
+		t.Fatalf("expected no TTL policies, got %v", cloud.ttl)
+	}
+	if !strings.Contains(out.String(), "Could not list projects") {
+		t.Fatalf("expected the listing error to be reported, got:\n%s", out.String())
+	}
+}
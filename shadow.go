@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/UnitVectorY-Labs/mcp-shopping-list-firestore/pkg/shoppinglist"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// -----------------------------------------------------------------------------
+// Shadow writes
+// -----------------------------------------------------------------------------
+
+// registerShadowTools adds compare_shadow. Writes are mirrored by the
+// service itself, in the background, and every list is compared in full by
+// the sweep.
+func registerShadowTools(srv *server.MCPServer, service *shoppinglist.Service) {
+	// compare_shadow
+	compareShadowTool := mcp.NewTool(
+		"compare_shadow",
+		mcp.WithDescription("Compare a list with its shadow copy in the migration target and report documents that are missing, unexpected, or different there, with running totals since startup. Use before switching clients over to the new backend."),
+		mcp.WithTitleAnnotation("Compare Shadow Copy"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		listArg,
+	)
+	srv.AddTool(compareShadowTool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := req.GetArguments()
+
+		toolCtx, cancel := context.WithTimeout(ctx, shoppinglist.ShadowTimeout)
+		defer cancel()
+
+		svc, err := listFromArgs(toolCtx, service, args)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to resolve list: %v", err)), nil
+		}
+
+		report, err := svc.CompareShadow(toolCtx)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to compare shadow: %v", err)), nil
+		}
+		return jsonResult(report)
+	})
+}
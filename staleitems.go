@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/UnitVectorY-Labs/mcp-shopping-list-firestore/pkg/shoppinglist"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// -----------------------------------------------------------------------------
+// Stale items
+// -----------------------------------------------------------------------------
+
+const defaultStaleLimit = 50
+
+func registerStaleItemTools(srv *server.MCPServer, service *shoppinglist.Service) {
+	// find_stale_items
+	findStaleItemsTool := mcp.NewTool(
+		"find_stale_items",
+		mcp.WithDescription("Find items that were added more than 'days' days ago and are still on the list, oldest first, to clean up things that have lingered for months. Offer to delete them; with 'delete' set the items found are removed, so ask the user first."),
+		mcp.WithTitleAnnotation("Find Stale Shopping Items"),
+		mcp.WithDestructiveHintAnnotation(true),
+		mcp.WithNumber("days", mcp.Description("How many days ago an item must have been added to count as stale, e.g. 90"), mcp.Required()),
+		mcp.WithNumber("limit", mcp.Description(fmt.Sprintf("Most items to find, and delete (optional, default %d, at most %d)", defaultStaleLimit, shoppinglist.MaxStaleItems))),
+		mcp.WithBoolean("delete", mcp.Description("Remove the stale items found (optional, defaults to false, which only lists them)")),
+		mcp.WithBoolean("cascade", mcp.Description("With 'delete', also remove the items' nested children; otherwise they become top-level items (optional)")),
+		listArg,
+	)
+	srv.AddTool(findStaleItemsTool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := req.GetArguments()
+
+		// Extract required days field
+		days, ok := args["days"].(float64)
+		if !ok || days < 1 || days != float64(int(days)) {
+			return mcp.NewToolResultError("'days' must be a whole number of at least 1"), nil
+		}
+
+		// Extract optional fields
+		limit := defaultStaleLimit
+		if v, ok := args["limit"].(float64); ok {
+			if v < 1 || v > shoppinglist.MaxStaleItems {
+				return mcp.NewToolResultError(fmt.Sprintf("'limit' must be between 1 and %d", shoppinglist.MaxStaleItems)), nil
+			}
+			limit = int(v)
+		}
+		remove, _ := args["delete"].(bool)
+		cascade, _ := args["cascade"].(bool)
+		if cascade && !remove {
+			return mcp.NewToolResultError("'cascade' needs 'delete'"), nil
+		}
+
+		toolCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
+		defer cancel()
+
+		svc, err := listFromArgs(toolCtx, service, args)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to resolve list: %v", err)), nil
+		}
+
+		cutoff := svc.Now().AddDate(0, 0, -int(days))
+		items, more, err := svc.StaleItems(toolCtx, cutoff, limit)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to find stale items: %v", err)), nil
+		}
+		resp := shoppinglist.StaleItemsResponse{Items: presentItems(ctx, items), OlderThan: cutoff, More: more}
+		if remove && len(items) > 0 {
+			ids := make([]string, len(items))
+			for i, it := range items {
+				ids[i] = it.ID
+			}
+			removed, err := svc.BulkRemoveItems(toolCtx, ids, cascade)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("failed to remove stale items: %v", err)), nil
+			}
+			resp.Deleted = removed.Deleted
+		}
+		return jsonResult(resp)
+	})
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/UnitVectorY-Labs/mcp-shopping-list-firestore/pkg/shoppinglist"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// -----------------------------------------------------------------------------
+// List summarization
+// -----------------------------------------------------------------------------
+
+// summaryPrompt asks the client's model for a paragraph about the list.
+const summaryPrompt = `Summarize this shopping list for the household in one short paragraph of plain prose, without lists or headings. Lead with anything overdue or urgent, mention the main store sections, and give the estimated cost of what is left, noting unpriced items and the budget when over it. Use only the facts given.`
+
+// summarizeList asks the client, via sampling, to write the highlights and the
+// names still to buy up as a paragraph.
+func summarizeList(ctx context.Context, srv *server.MCPServer, h shoppinglist.ListHighlights, toBuy []string) (string, error) {
+	facts, err := json.Marshal(map[string]any{"highlights": h, "still_to_buy": toBuy})
+	if err != nil {
+		return "", err
+	}
+	result, err := srv.RequestSampling(ctx, mcp.CreateMessageRequest{
+		CreateMessageParams: mcp.CreateMessageParams{
+			SystemPrompt: summaryPrompt,
+			Messages: []mcp.SamplingMessage{{
+				Role:    mcp.RoleUser,
+				Content: mcp.NewTextContent(string(facts)),
+			}},
+			MaxTokens:   400,
+			Temperature: 0.3,
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("sampling: %w", err)
+	}
+	summary := strings.TrimSpace(samplingText(result))
+	if summary == "" {
+		return "", fmt.Errorf("sampling: the model replied without text")
+	}
+	return summary, nil
+}
+
+func registerSummarizeTools(srv *server.MCPServer, service *shoppinglist.Service, cfg serverConfig) {
+	srv.EnableSampling()
+
+	// summarize_list
+	summarizeListTool := mcp.NewTool(
+		"summarize_list",
+		mcp.WithDescription("Summarize the current list in one paragraph, written by the client's model through sampling: overdue and urgent items, the main store sections, and the estimated cost of what is left. Returns the paragraph as text and the structured highlights it was written from."),
+		mcp.WithTitleAnnotation("Summarize Shopping List"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		listArg,
+	)
+	srv.AddTool(summarizeListTool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := req.GetArguments()
+
+		toolCtx, cancel := context.WithTimeout(ctx, 60*time.Second)
+		defer cancel()
+
+		svc, err := listFromArgs(toolCtx, service, args)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to resolve list: %v", err)), nil
+		}
+
+		view, err := svc.View(toolCtx)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to list items: %v", err)), nil
+		}
+		highlights, warnings := shoppinglist.BuildHighlights(toolCtx, cfg.rates, cfg.currency, cfg.budget, view.Items, svc.Now())
+		resp := shoppinglist.ListSummaryText{Highlights: highlights, ResponseWarnings: warnings}
+
+		var toBuy []string
+		for _, it := range view.Items {
+			if !it.Purchased {
+				toBuy = append(toBuy, it.Name)
+			}
+		}
+		if highlights.Counts.Total == 0 {
+			resp.Summary = shoppinglist.PlainSummary(highlights)
+		} else if !sessionFeatures.Get(ctx).Sampling {
+			resp.Summary = shoppinglist.PlainSummary(highlights)
+			resp.Warn(shoppinglist.WarnSamplingFailed, "", "the summary was written without the client's model, as the client does not support sampling")
+		} else if resp.Summary, err = summarizeList(toolCtx, srv, highlights, toBuy); err != nil {
+			resp.Summary = shoppinglist.PlainSummary(highlights)
+			resp.Warn(shoppinglist.WarnSamplingFailed, "", "the summary was written without the client's model: %v", err)
+		}
+		return mcp.NewToolResultStructured(resp, resp.Summary), nil
+	})
+}
@@ -0,0 +1,12 @@
+package main
+
+// -----------------------------------------------------------------------------
+// List summaries
+// -----------------------------------------------------------------------------
+
+// defaultSummaryItems is how many items a summary shows when not told otherwise.
+const defaultSummaryItems = 10
+
+// defaultPageSize is how many items a page of list_items holds when a cursor
+// is given without a limit.
+const defaultPageSize = 100
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/UnitVectorY-Labs/mcp-shopping-list-firestore/pkg/shoppinglist"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// -----------------------------------------------------------------------------
+// Tool telemetry
+// -----------------------------------------------------------------------------
+
+const (
+	defaultTelemetrySince = 24 * time.Hour
+	defaultSlowest        = 10
+	maxSlowest            = 100
+)
+
+// withTelemetry records every tool call's duration, Firestore operations, and
+// result size, flagging calls that take longer than their budget. Recording
+// happens after the call is timed, and a failed write is logged rather than
+// failing the call.
+func withTelemetry(service *shoppinglist.Service, budgets shoppinglist.LatencyBudgets) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			ctx, tally := shoppinglist.WithOperationTally(ctx)
+			// Latency is measured on the wall clock, even when the service's
+			// clock is frozen.
+			startedAt, start := service.Now(), time.Now()
+			res, err := next(ctx, req)
+			elapsed := time.Since(start)
+
+			exec := shoppinglist.ToolExecution{
+				Tool:       req.Params.Name,
+				StartedAt:  startedAt,
+				DurationMS: elapsed.Milliseconds(),
+				Operations: tally.Counts(),
+				Error:      err != nil || (res != nil && res.IsError),
+			}
+			exec.List, _ = req.GetArguments()["list"].(string)
+			if res != nil {
+				if b, err := json.Marshal(res); err == nil {
+					exec.ResultBytes = len(b)
+				}
+			}
+			if budget := budgets.Budget(exec.Tool); budget > 0 {
+				exec.BudgetMS = budget.Milliseconds()
+				if exec.OverBudget = elapsed > budget; exec.OverBudget {
+					log.Printf("warn: %s took %s, over its %s latency budget", exec.Tool, elapsed.Round(time.Millisecond), budget)
+				}
+			}
+
+			recordCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+			if err := service.RecordExecution(recordCtx, exec); err != nil {
+				log.Printf("warn: %v", err)
+			}
+			cancel()
+			return res, err
+		}
+	}
+}
+
+func registerTelemetryTools(srv *server.MCPServer, service *shoppinglist.Service) {
+	// tool_telemetry
+	toolTelemetryTool := mcp.NewTool(
+		"tool_telemetry",
+		mcp.WithDescription("Report how slow and expensive recent tool calls were, per tool: calls, errors, calls over their latency budget, p50/p95/max duration, and average Firestore reads, writes, and deletes and result size, with the slowest calls. Calls are recorded only when the server runs with --telemetry. Requires an owner token."),
+		mcp.WithTitleAnnotation("Tool Telemetry"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("since", mcp.Description("Only calls at or after this RFC 3339 time, or within this duration, e.g. '1h' (optional, defaults to 24h)")),
+		mcp.WithString("tool", mcp.Description("Only calls of this tool, e.g. 'list_items' (optional)")),
+		mcp.WithNumber("slowest", mcp.Description(fmt.Sprintf("How many of the slowest calls to include (optional, default %d, at most %d)", defaultSlowest, maxSlowest))),
+	)
+	srv.AddTool(toolTelemetryTool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := req.GetArguments()
+
+		// Extract optional fields
+		since := service.Now().Add(-defaultTelemetrySince)
+		if v, ok := args["since"].(string); ok && v != "" {
+			t, err := parseSince(v, service.Now())
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			since = t
+		}
+		tool, _ := args["tool"].(string)
+		slowest := defaultSlowest
+		if v, ok := args["slowest"].(float64); ok {
+			if v < 0 || v > maxSlowest {
+				return mcp.NewToolResultError(fmt.Sprintf("'slowest' must be between 0 and %d", maxSlowest)), nil
+			}
+			slowest = int(v)
+		}
+
+		toolCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		defer cancel()
+
+		execs, err := service.ToolExecutions(toolCtx, since, shoppinglist.MaxTelemetryExecutions)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to read telemetry: %v", err)), nil
+		}
+		truncated := len(execs) == shoppinglist.MaxTelemetryExecutions
+		if tool != "" {
+			// Filtered after the read, as an equality on tool with the range on
+			// started_at needs a composite index.
+			kept := execs[:0]
+			for _, e := range execs {
+				if e.Tool == tool {
+					kept = append(kept, e)
+				}
+			}
+			execs = kept
+		}
+		report := shoppinglist.SummarizeExecutions(execs, since, slowest)
+		report.Truncated = truncated
+		return jsonResult(report)
+	})
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"context"
+	"strings"
+
+	"github.com/UnitVectorY-Labs/mcp-shopping-list-firestore/pkg/shoppinglist"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// -----------------------------------------------------------------------------
+// Retry hints
+// -----------------------------------------------------------------------------
+
+// throttledCode identifies a tool error caused by Firestore refusing a call
+// with ResourceExhausted.
+const throttledCode = "resource_exhausted"
+
+// withRetryHints turns a failed tool call whose Firestore calls were throttled
+// into a resource_exhausted error carrying retry_after_ms, so clients can back
+// off for as long as Firestore asks instead of retrying at once. Calls that
+// succeeded once the client library retried are left alone.
+func withRetryHints(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx, watch := shoppinglist.WithThrottleWatch(ctx)
+		res, err := next(ctx, req)
+		d, throttled := watch.RetryAfter()
+		if !throttled || (err == nil && (res == nil || !res.IsError)) {
+			return res, err
+		}
+		msg := "Firestore is refusing requests for now; retry after retry_after_ms"
+		if err != nil {
+			msg = err.Error()
+		} else if text := resultText(res); text != "" {
+			msg = text
+		}
+		return errorResult(toolError{Code: throttledCode, Error: strings.TrimSpace(msg), RetryAfterMS: d.Milliseconds()}), nil
+	}
+}
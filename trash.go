@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/UnitVectorY-Labs/mcp-shopping-list-firestore/pkg/shoppinglist"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// -----------------------------------------------------------------------------
+// Trash
+// -----------------------------------------------------------------------------
+
+func registerTrashTools(srv *server.MCPServer, service *shoppinglist.Service) {
+	// list_deleted
+	listDeletedTool := mcp.NewTool(
+		"list_deleted",
+		mcp.WithDescription("List the items removed from the list that are still in its trash, most recently removed first, each with its deleted_at. list_items leaves them out unless 'include_deleted' is set."),
+		mcp.WithTitleAnnotation("List Deleted Shopping Items"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		listArg,
+	)
+	srv.AddTool(listDeletedTool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := req.GetArguments()
+
+		toolCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+
+		svc, err := listFromArgs(toolCtx, service, args)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to resolve list: %v", err)), nil
+		}
+
+		items, err := svc.DeletedItems(toolCtx)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to list deleted items: %v", err)), nil
+		}
+		return jsonResult(shoppinglist.DeletedResponse{Items: items})
+	})
+
+	// restore_item
+	restoreItemTool := mcp.NewTool(
+		"restore_item",
+		mcp.WithDescription("Put an item removed by remove_item, bulk_remove_items, clear_list, or a recipe back on the list from the trash, e.g. after removing the wrong one. It comes back as a top-level item if its parent is gone. An ID not in the trash gives an error with code 'not_found'."),
+		mcp.WithTitleAnnotation("Restore Shopping Item"),
+		mcp.WithString("id", mcp.Description("ID of the removed item, as listed by list_deleted"), mcp.Required()),
+		listArg,
+	)
+	srv.AddTool(restoreItemTool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := req.GetArguments()
+
+		// Extract required id field
+		id, ok := args["id"].(string)
+		if !ok || id == "" {
+			return mcp.NewToolResultError("invalid or missing 'id'"), nil
+		}
+
+		toolCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+
+		svc, err := listFromArgs(toolCtx, service, args)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to resolve list: %v", err)), nil
+		}
+
+		it, err := svc.RestoreItem(toolCtx, id)
+		if errors.Is(err, shoppinglist.ErrItemNotFound) {
+			return errorResult(toolError{Code: "not_found", Error: err.Error(), ID: id}), nil
+		}
+		if err != nil {
+			var frozen *shoppinglist.ListFrozenError
+			if errors.As(err, &frozen) {
+				return mcp.NewToolResultError(frozen.Error()), nil
+			}
+			return mcp.NewToolResultError(fmt.Sprintf("failed to restore item: %v", err)), nil
+		}
+		return jsonResult(shoppinglist.ItemResponse{Item: it})
+	})
+
+	// purge_deleted
+	purgeDeletedTool := mcp.NewTool(
+		"purge_deleted",
+		mcp.WithDescription("Permanently delete items from the trash so they can no longer be restored: those with the given 'ids', or those removed more than 'older_than' ago, or with 'confirm' the whole trash. Ask the user before emptying the trash."),
+		mcp.WithTitleAnnotation("Purge Deleted Shopping Items"),
+		mcp.WithDestructiveHintAnnotation(true),
+		mcp.WithArray("ids", mcp.Description("IDs of the removed items to purge (optional)"), mcp.WithStringItems()),
+		mcp.WithString("older_than", mcp.Description("Purge items removed longer ago than this duration, e.g. '720h' (optional)")),
+		mcp.WithBoolean("confirm", mcp.Description("Must be true to purge the whole trash when neither 'ids' nor 'older_than' is given (optional)")),
+		listArg,
+	)
+	srv.AddTool(purgeDeletedTool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := req.GetArguments()
+
+		// Extract optional ids field
+		var ids []string
+		if raw, ok := args["ids"]; ok && raw != nil {
+			var err error
+			if ids, err = idsFromArgs(args); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+		}
+
+		// Extract optional older_than field
+		var olderThan time.Duration
+		if v, ok := args["older_than"].(string); ok && v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil || d <= 0 {
+				return mcp.NewToolResultError("'older_than' must be a positive duration such as 720h"), nil
+			}
+			olderThan = d
+		}
+		if len(ids) > 0 && olderThan > 0 {
+			return mcp.NewToolResultError("'ids' cannot be combined with 'older_than'"), nil
+		}
+		if confirm, _ := args["confirm"].(bool); len(ids) == 0 && olderThan == 0 && !confirm {
+			return mcp.NewToolResultError("refusing to empty the trash without 'ids', 'older_than', or 'confirm': true"), nil
+		}
+
+		toolCtx, cancel := context.WithTimeout(ctx, clearListTimeout)
+		defer cancel()
+
+		svc, err := listFromArgs(toolCtx, service, args)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to resolve list: %v", err)), nil
+		}
+
+		var before time.Time
+		if olderThan > 0 {
+			before = svc.Now().Add(-olderThan)
+		}
+		resp, err := svc.PurgeDeleted(toolCtx, ids, before)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to purge deleted items: %v", err)), nil
+		}
+		return jsonResult(resp)
+	})
+}
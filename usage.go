@@ -0,0 +1,35 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/UnitVectorY-Labs/mcp-shopping-list-firestore/pkg/shoppinglist"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// -----------------------------------------------------------------------------
+// Usage
+// -----------------------------------------------------------------------------
+
+func registerUsageTools(srv *server.MCPServer, service *shoppinglist.Service) {
+	// usage_report
+	usageReportTool := mcp.NewTool(
+		"usage_report",
+		mcp.WithDescription("Estimate this server's Firestore consumption against the free tier: documents and approximate storage per list, and the reads, writes, and deletes the server has made today (the quota resets at midnight Pacific time) and since it started, plus the progress of any list whose items are still being moved to structured quantities, with the item cache on, its hit ratio, listener reconnects, and snapshot lag, and with the stale fallback on, how often it has served old data. Building the report costs a few reads per collection."),
+		mcp.WithTitleAnnotation("Firestore Usage Report"),
+		mcp.WithReadOnlyHintAnnotation(true),
+	)
+	srv.AddTool(usageReportTool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		toolCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		defer cancel()
+
+		report, err := service.UsageReport(toolCtx)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to build usage report: %v", err)), nil
+		}
+		return jsonResult(report)
+	})
+}